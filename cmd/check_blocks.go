@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkBlocksCmd = &cobra.Command{
+		Use:   "check:blocks",
+		Short: "Check structural invariants across blocks synced by check:data",
+		Long: `This command asserts structural invariants across all blocks
+stored by check:data (pruned blocks are not checked):
+
+1. Block timestamps do not decrease (by more than --timestamp-tolerance)
+2. Each block references the block before it as its parent
+3. No transaction hash appears more than once in a block
+4. The operation indexes in each transaction are contiguous and start at 0
+
+All violations are printed along with a summary of the number of violations
+of each invariant. If any invariant is violated, the command exits with an
+error.
+
+The check:data database must not be in use while checking (and the
+configuration file must be provided if compression was disabled).
+
+The arguments for this command are:
+<database path>`,
+		RunE: runCheckBlocksCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	timestampTolerance int64
+)
+
+func runCheckBlocksCmd(cmd *cobra.Command, args []string) error {
+	databasePath := path.Clean(args[0])
+
+	lock, err := tester.AcquireDataDirectoryLock(databasePath, false)
+	if err != nil {
+		return fmt.Errorf("%w: unable to lock database", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if Config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(Context, databasePath, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to open database", err)
+	}
+	defer localStore.Close(Context)
+
+	blockStorage := modules.NewBlockStorage(localStore, Config.SerialBlockWorkers)
+	integrity, err := processor.CheckBlockIntegrity(Context, blockStorage, timestampTolerance)
+	if err != nil {
+		return fmt.Errorf("%w: unable to check blocks", err)
+	}
+
+	integrity.Print()
+	if len(integrity.Violations) > 0 {
+		return fmt.Errorf(
+			"%w: found %d violation(s) across %d checked block(s)",
+			results.ErrBlockIntegrity,
+			len(integrity.Violations),
+			integrity.BlocksChecked,
+		)
+	}
+
+	color.Green("Checked %d blocks without violations", integrity.BlocksChecked)
+	return nil
+}
@@ -16,14 +16,19 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/processor"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 	"github.com/coinbase/rosetta-cli/pkg/tester"
 
+	"github.com/coinbase/rosetta-sdk-go/constructor/job"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
@@ -46,6 +51,12 @@ Check out the https://github.com/coinbase/rosetta-cli/tree/master/examples
 directory for examples of how to configure this test for Bitcoin and
 Ethereum.
 
+All in-flight state (jobs, broadcasts, and counters) is persisted in
+the data directory and is picked up when check:construction is run again.
+Run it with --resume to continue the unfinished run (which requires the
+same configuration), or with --fresh to discard in-flight jobs and
+broadcasts and only count jobs completed from now on towards end conditions.
+
 Right now, this tool only supports transfer testing (for both account-based
 and UTXO-based blockchains). However, we plan to add support for testing
 arbitrary scenarios (i.e. staking, governance).`,
@@ -53,7 +64,7 @@ arbitrary scenarios (i.e. staking, governance).`,
 	}
 )
 
-func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
+func runCheckConstructionCmd(cmd *cobra.Command, _ []string) error {
 	if Config.Construction == nil {
 		return results.ExitConstruction(
 			Config,
@@ -63,6 +74,30 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
+	if len(endReturnFundsAddress) > 0 {
+		if err := setReturnFundsAddress(endReturnFundsAddress); err != nil {
+			return results.ExitConstruction(Config, nil, nil, err)
+		}
+	}
+
+	if resume && fresh {
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			fmt.Errorf("%w: --resume and --fresh cannot be used together", results.ErrCannotResume),
+		)
+	}
+
+	if resume && len(Config.DataDirectory) == 0 {
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			fmt.Errorf("%w: --resume requires data_directory", results.ErrCannotResume),
+		)
+	}
+
 	ensureDataDirectoryExists()
 	ctx, cancel := context.WithCancel(Context)
 
@@ -75,6 +110,15 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 	if Config.ForceRetry {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewAPIClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		nil,
+		clientOptions,
+	)))
 
 	fetcher := fetcher.New(
 		Config.OnlineURL,
@@ -92,7 +136,41 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
-	_, err := utils.CheckNetworkSupported(ctx, Config.Network, fetcher)
+	// The /network/options response is fetched once and shared
+	// by the run metadata, spec compatibility, and asserter
+	// configuration checks.
+	networkOptions, fetchErr := fetcher.NetworkOptionsRetry(ctx, Config.Network, nil)
+	if fetchErr != nil {
+		cancel()
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			fmt.Errorf("%w: unable to fetch network options", fetchErr.Err),
+		)
+	}
+
+	if err := stampRun(networkOptions); err != nil {
+		cancel()
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			err,
+		)
+	}
+
+	if err := reportSpecCompatibility(networkOptions); err != nil {
+		cancel()
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			err,
+		)
+	}
+
+	networkStatus, err := utils.CheckNetworkSupported(ctx, Config.Network, fetcher)
 	if err != nil {
 		cancel()
 		return results.ExitConstruction(
@@ -103,9 +181,34 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
+	prefundedCurrencies := []*types.Currency{}
+	for _, account := range Config.Construction.PrefundedAccounts {
+		prefundedCurrencies = append(prefundedCurrencies, account.Currency)
+	}
+	if remoteSigner := Config.Construction.RemoteSigner; remoteSigner != nil {
+		for _, account := range remoteSigner.Accounts {
+			prefundedCurrencies = append(prefundedCurrencies, account.Currency)
+		}
+	}
+
+	if err := probeDecimals(
+		ctx,
+		fetcher,
+		networkStatus.CurrentBlockIdentifier,
+		prefundedCurrencies,
+	); err != nil {
+		cancel()
+		return results.ExitConstruction(
+			Config,
+			nil,
+			nil,
+			err,
+		)
+	}
+
 	if asserterConfigurationFile != "" {
 		if err := validateNetworkOptionsMatchesAsserterConfiguration(
-			ctx, fetcher, Config.Network, asserterConfigurationFile,
+			networkOptions, asserterConfigurationFile,
 		); err != nil {
 			cancel()
 			return results.ExitConstruction(
@@ -124,6 +227,10 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		fetcher,
 		cancel,
 		&SignalReceived,
+		len(endReturnFundsAddress) > 0,
+		resume,
+		fresh,
+		forceTakeover,
 	)
 	if err != nil {
 		return results.ExitConstruction(
@@ -158,10 +265,38 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 		return constructionTester.StartConstructor(ctx)
 	})
 
+	g.Go(func() error {
+		return constructionTester.StartMempoolVerifier(ctx)
+	})
+
+	g.Go(func() error {
+		return constructionTester.StartDustConsolidator(ctx)
+	})
+
+	g.Go(func() error {
+		return constructionTester.StartMultiSenderSpender(ctx)
+	})
+
+	g.Go(func() error {
+		return constructionTester.StartCPFPRunner(ctx)
+	})
+
+	g.Go(func() error {
+		return constructionTester.StartReplayer(ctx)
+	})
+
+	g.Go(func() error {
+		return constructionTester.StartSenderPipelines(ctx)
+	})
+
 	g.Go(func() error {
 		return constructionTester.WatchEndConditions(ctx)
 	})
 
+	g.Go(func() error {
+		return constructionTester.StartMetricsServer(ctx, metricsAddr)
+	})
+
 	g.Go(func() error {
 		return tester.LogMemoryLoop(ctx)
 	})
@@ -177,6 +312,38 @@ func runCheckConstructionCmd(_ *cobra.Command, _ []string) error {
 
 	sigListeners := []context.CancelFunc{cancel}
 	go handleSignals(&sigListeners)
+	handlePauseSignals(ctx, constructionTester)
 
 	return constructionTester.HandleErr(g.Wait(), &sigListeners)
 }
+
+// setReturnFundsAddress exposes the --end-return-funds address to
+// the return_funds workflow (which must be defined) as a JSON string
+// that can be loaded with load_env.
+func setReturnFundsAddress(address string) error {
+	found := false
+	for _, workflow := range Config.Construction.Workflows {
+		if workflow.Name == string(job.ReturnFunds) {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf(
+			"--end-return-funds requires a %s workflow",
+			job.ReturnFunds,
+		)
+	}
+
+	encoded, err := json.Marshal(address)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode return funds address", err)
+	}
+
+	if err := os.Setenv(tester.ReturnFundsAddressEnv, string(encoded)); err != nil {
+		return fmt.Errorf("%w: unable to set %s", err, tester.ReturnFundsAddressEnv)
+	}
+
+	return nil
+}
@@ -16,14 +16,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/processor"
 	"github.com/coinbase/rosetta-cli/pkg/results"
 	"github.com/coinbase/rosetta-cli/pkg/tester"
 
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 )
@@ -45,6 +48,10 @@ Starting from a given index can be useful to debug a small range of blocks for
 issues but it is highly recommended you sync from start to finish to ensure
 all correctness checks are performed.
 
+Run it with --resume to explicitly continue the run persisted in the data
+directory from its last processed block (ignoring start_index) after verifying
+that the stored tip is still on the node's chain.
+
 By default, account balances are looked up at specific heights (instead of
 only at the current block). If your node does not support this functionality,
 you can disable historical balance lookups in your configuration file. This will
@@ -65,12 +72,35 @@ If your blockchain has a genesis allocation of funds and you set
 historical balance disabled to true, you must provide an
 absolute path to a JSON file containing initial balances with the
 bootstrap balance config. You can look at the examples folder for an example
-of what one of these files looks like.`,
+of what one of these files looks like.
+
+If your configuration file defines multiple networks, --network selects the
+network to check. With --all-networks, all of them are checked (one after
+another, or at the same time with --parallel) and their results are aggregated.`,
 		RunE: runCheckDataCmd,
 	}
 )
 
 func runCheckDataCmd(_ *cobra.Command, _ []string) error {
+	if parallelNetworks && !allNetworks {
+		return errors.New("--parallel can only be used with --all-networks")
+	}
+
+	if allNetworks {
+		return runCheckDataNetworks()
+	}
+
+	if resume && len(Config.DataDirectory) == 0 {
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			fmt.Errorf("%w: --resume requires data_directory", results.ErrCannotResume),
+			"",
+			"",
+		)
+	}
+
 	ensureDataDirectoryExists()
 	ctx, cancel := context.WithCancel(Context)
 
@@ -83,6 +113,31 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 	if Config.ForceRetry {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+
+	var replayFraction float64
+	if Config.Paranoid != nil {
+		replayFraction = Config.Paranoid.ReplayFraction
+	}
+
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewAPIClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		&processor.APIClientOptions{
+			ReplayFraction: replayFraction,
+			OnMismatch: func(mismatch *processor.ResponseMismatch) {
+				color.Yellow(
+					"inconsistent %s responses over distinct connections: %s",
+					mismatch.Path,
+					mismatch.Reason,
+				)
+				results.RecordReplayMismatch(mismatch.Path, mismatch.Reason)
+			},
+			OperationTypeAliases: Config.Data.OperationTypeAliases,
+		},
+		clientOptions,
+	)))
 
 	fetcher := fetcher.New(
 		Config.OnlineURL,
@@ -102,6 +157,46 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
+	// The /network/options response is fetched once and shared
+	// by the run metadata, spec compatibility, and asserter
+	// configuration checks.
+	networkOptions, fetchErr := fetcher.NetworkOptionsRetry(ctx, Config.Network, nil)
+	if fetchErr != nil {
+		cancel()
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			fmt.Errorf("%w: unable to fetch network options", fetchErr.Err),
+			"",
+			"",
+		)
+	}
+
+	if err := stampRun(networkOptions); err != nil {
+		cancel()
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			err,
+			"",
+			"",
+		)
+	}
+
+	if err := reportSpecCompatibility(networkOptions); err != nil {
+		cancel()
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			err,
+			"",
+			"",
+		)
+	}
+
 	networkStatus, err := utils.CheckNetworkSupported(ctx, Config.Network, fetcher)
 	if err != nil {
 		cancel()
@@ -115,9 +210,21 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		)
 	}
 
+	if err := probeDecimals(ctx, fetcher, networkStatus.CurrentBlockIdentifier, nil); err != nil {
+		cancel()
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			err,
+			"",
+			"",
+		)
+	}
+
 	if asserterConfigurationFile != "" {
 		if err := validateNetworkOptionsMatchesAsserterConfiguration(
-			ctx, fetcher, Config.Network, asserterConfigurationFile,
+			networkOptions, asserterConfigurationFile,
 		); err != nil {
 			cancel()
 			return results.ExitData(
@@ -140,10 +247,23 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		networkStatus.GenesisBlockIdentifier,
 		nil, // only populated when doing recursive search
 		&SignalReceived,
+		forceTakeover,
 	)
 
 	defer dataTester.CloseDatabase(ctx)
 
+	if err := dataTester.PrepareResume(ctx, resume); err != nil {
+		cancel()
+		return results.ExitData(
+			Config,
+			nil,
+			nil,
+			err,
+			"",
+			"",
+		)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		return dataTester.StartPeriodicLogger(ctx)
@@ -161,6 +281,10 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		return dataTester.StartPruning(ctx)
 	})
 
+	g.Go(func() error {
+		return dataTester.StartCheckpointing(ctx)
+	})
+
 	g.Go(func() error {
 		return dataTester.WatchEndConditions(ctx)
 	})
@@ -169,6 +293,22 @@ func runCheckDataCmd(_ *cobra.Command, _ []string) error {
 		return dataTester.StartReconcilerCountUpdater(ctx)
 	})
 
+	g.Go(func() error {
+		return dataTester.StartExplorerComparison(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartHistoricalReconciliation(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartStateCommitmentVerification(ctx)
+	})
+
+	g.Go(func() error {
+		return dataTester.StartMetricsServer(ctx, metricsAddr)
+	})
+
 	g.Go(func() error {
 		return tester.LogMemoryLoop(ctx)
 	})
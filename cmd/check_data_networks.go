@@ -0,0 +1,311 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"golang.org/x/sync/errgroup"
+)
+
+// runCheckDataNetworks runs check:data against each of the
+// configured networks (sequentially or in parallel) and aggregates
+// their results. Each network is checked in its own rosetta-cli
+// process because the configuration and results of a check are
+// global to a process.
+func runCheckDataNetworks() error {
+	if len(configuredNetworks) == 0 {
+		return errors.New("no networks are configured")
+	}
+
+	if parallelNetworks {
+		config, err := configuration.LoadConfiguration(Context, configurationFile)
+		if err != nil {
+			return fmt.Errorf("%w: unable to load configuration", err)
+		}
+
+		if err := assertDistinctStatusPorts(Context, config); err != nil {
+			return err
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("%w: unable to find rosetta-cli executable", err)
+	}
+
+	resultsDir, err := ioutil.TempDir("", "rosetta-cli-networks")
+	if err != nil {
+		return fmt.Errorf("%w: unable to create results directory", err)
+	}
+	defer os.RemoveAll(resultsDir)
+
+	processes := &networkProcesses{}
+	sigListeners := []context.CancelFunc{processes.interrupt}
+	go handleSignals(&sigListeners)
+
+	start := time.Now()
+	networkResults := make([]*results.NetworkResults, len(configuredNetworks))
+	var outputLock sync.Mutex
+	g := &errgroup.Group{}
+	for i, network := range configuredNetworks {
+		i, network := i, network
+		check := func() error {
+			color.Cyan("Starting check:data on network %s", network.Name)
+			networkResults[i] = runCheckDataNetwork(
+				executable,
+				network,
+				path.Join(resultsDir, fmt.Sprintf("%d.json", i)),
+				processes,
+				&outputLock,
+			)
+			return nil
+		}
+
+		if parallelNetworks {
+			g.Go(check)
+			continue
+		}
+
+		_ = check()
+		if SignalReceived {
+			networkResults = networkResults[:i+1]
+			break
+		}
+	}
+	_ = g.Wait()
+
+	return results.ExitNetworks(networkResults, start, Config.Data.ResultsOutputFile)
+}
+
+// assertDistinctStatusPorts ensures each network of config
+// serves its check:data status on a different data.status_port
+// (so that networks can be checked at the same time).
+func assertDistinctStatusPorts(
+	ctx context.Context,
+	config *configuration.Configuration,
+) error {
+	ports := map[uint]string{}
+	for _, network := range config.Networks {
+		networkConfig, err := configuration.ApplyNetwork(ctx, config, network.Name)
+		if err != nil {
+			return fmt.Errorf("%w: unable to select network %s", err, network.Name)
+		}
+
+		port := networkConfig.Data.StatusPort
+		if other, ok := ports[port]; ok {
+			return fmt.Errorf(
+				"networks %s and %s both use data.status_port %d "+
+					"(override it in the data of each network to use --parallel)",
+				other,
+				network.Name,
+				port,
+			)
+		}
+		ports[port] = network.Name
+	}
+
+	return nil
+}
+
+// runCheckDataNetwork runs check:data against network in a
+// new process of executable and returns its results.
+func runCheckDataNetwork(
+	executable string,
+	network *configuration.NetworkConfiguration,
+	resultsPath string,
+	processes *networkProcesses,
+	outputLock *sync.Mutex,
+) *results.NetworkResults {
+	networkResults := &results.NetworkResults{
+		Name:    network.Name,
+		Network: network.Network,
+	}
+
+	prefix := fmt.Sprintf("[%s] ", network.Name)
+	stdout := newPrefixWriter(os.Stdout, prefix, outputLock)
+	stderr := newPrefixWriter(os.Stderr, prefix, outputLock)
+
+	process := exec.Command(executable, checkDataNetworkArgs(network.Name, resultsPath)...) // #nosec G204
+	process.Stdout = stdout
+	process.Stderr = stderr
+
+	start := time.Now()
+	err := processes.run(process)
+	networkResults.Elapsed = int64(time.Since(start).Seconds())
+	stdout.Flush()
+	stderr.Flush()
+
+	var data results.CheckDataResults
+	if loadErr := utils.LoadAndParse(resultsPath, &data); loadErr == nil {
+		networkResults.Data = &data
+	} else if err == nil {
+		networkResults.Error = fmt.Sprintf("%s: unable to load results", loadErr.Error())
+	}
+
+	if err != nil && networkResults.Data == nil {
+		networkResults.Error = err.Error()
+	}
+
+	return networkResults
+}
+
+// checkDataNetworkArgs returns the arguments of the
+// check:data process run against the network called name.
+func checkDataNetworkArgs(name string, resultsPath string) []string {
+	args := []string{
+		"check:data",
+		"--configuration-file", configurationFile,
+		"--network", name,
+		"--results-output", resultsPath,
+	}
+
+	if len(asserterConfigurationFile) > 0 {
+		args = append(args, "--asserter-configuration-file", asserterConfigurationFile)
+	}
+
+	if len(specVersion) > 0 {
+		args = append(args, "--spec-version", specVersion)
+	}
+
+	if forceTakeover {
+		args = append(args, "--force-takeover")
+	}
+
+	if resume {
+		args = append(args, "--resume")
+	}
+
+	return args
+}
+
+// networkProcesses tracks the check:data processes
+// that are running so they can be interrupted.
+type networkProcesses struct {
+	lock    sync.Mutex
+	running map[*exec.Cmd]struct{}
+}
+
+// run starts process and waits for it to exit.
+func (n *networkProcesses) run(process *exec.Cmd) error {
+	n.lock.Lock()
+	if SignalReceived {
+		n.lock.Unlock()
+		return errors.New("interrupted before starting")
+	}
+
+	if err := process.Start(); err != nil {
+		n.lock.Unlock()
+		return err
+	}
+
+	if n.running == nil {
+		n.running = map[*exec.Cmd]struct{}{}
+	}
+	n.running[process] = struct{}{}
+	n.lock.Unlock()
+
+	err := process.Wait()
+
+	n.lock.Lock()
+	delete(n.running, process)
+	n.lock.Unlock()
+
+	return err
+}
+
+// interrupt forwards an interrupt to all running
+// processes so they exit (and save their results).
+func (n *networkProcesses) interrupt() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for process := range n.running {
+		_ = process.Process.Signal(os.Interrupt)
+	}
+}
+
+// prefixWriter prefixes every line written to it
+// (so the output of concurrent processes can be
+// told apart).
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	lock   *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(w io.Writer, prefix string, lock *sync.Mutex) *prefixWriter {
+	return &prefixWriter{
+		w:      w,
+		prefix: []byte(prefix),
+		lock:   lock,
+	}
+}
+
+// Write writes all complete lines in b (and any
+// partial line buffered by a previous call).
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+
+		line := p.buf.Next(i + 1)
+		if err := p.writeLine(line); err != nil {
+			return len(b), err
+		}
+	}
+}
+
+// Flush writes any partial line that is buffered.
+func (p *prefixWriter) Flush() {
+	if p.buf.Len() == 0 {
+		return
+	}
+
+	_ = p.writeLine(append(p.buf.Bytes(), '\n'))
+	p.buf.Reset()
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, err := p.w.Write(p.prefix); err != nil {
+		return err
+	}
+
+	_, err := p.w.Write(line)
+	return err
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertDistinctStatusPorts(t *testing.T) {
+	var tests = map[string]struct {
+		testnetData json.RawMessage
+
+		err bool
+	}{
+		"same status port": {
+			err: true,
+		},
+		"distinct status ports": {
+			testnetData: json.RawMessage(`{"status_port": 9091}`),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := configuration.DefaultConfiguration()
+			config.Networks = []*configuration.NetworkConfiguration{
+				{
+					Name:    "mainnet",
+					Network: &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"},
+				},
+				{
+					Name:    "testnet",
+					Network: &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Testnet3"},
+					Data:    test.testnetData,
+				},
+			}
+
+			err := assertDistinctStatusPorts(context.Background(), config)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var lock sync.Mutex
+	w := newPrefixWriter(&out, "[testnet] ", &lock)
+
+	n, err := w.Write([]byte("syncing block 1\nsyncing "))
+	assert.NoError(t, err)
+	assert.Equal(t, 24, n)
+	assert.Equal(t, "[testnet] syncing block 1\n", out.String())
+
+	_, err = w.Write([]byte("block 2\n\nreconciling"))
+	assert.NoError(t, err)
+	w.Flush()
+	w.Flush()
+	assert.Equal(
+		t,
+		"[testnet] syncing block 1\n[testnet] syncing block 2\n[testnet] \n[testnet] reconciling\n",
+		out.String(),
+	)
+}
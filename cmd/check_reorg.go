@@ -0,0 +1,196 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkReorgCmd = &cobra.Command{
+		Use:   "check:reorg",
+		Short: "Check that balances are consistent across a simulated reorg",
+		Long: `Reorgs are rare on most test networks, so the code paths that
+revert balance changes when blocks are orphaned are rarely exercised by
+check:data. This command simulates a reorg using blocks from your
+implementation to verify that balances are correctly reverted and re-applied.
+
+The fork is loaded from a JSON file (provided with --fork) containing an
+array of blocks (in the same format as the block field of /block responses).
+The fork must be a contiguous chain of blocks that extends the block at
+fork[0].index-1 on the node. The canonical blocks at the same indexes are
+fetched from the node (up to its current head).
+
+The canonical blocks are added to an empty block storage and then removed
+(in reverse order) before the fork is added. The balance of every account
+changed in either segment is then compared to its balance when the fork is
+added directly. Because no account balances are fetched, balances are
+compared relative to their value before the fork. Any account with an
+inconsistent balance is printed and the command exits with an error.`,
+		RunE: runCheckReorgCmd,
+		Args: cobra.NoArgs,
+	}
+
+	checkReorgFork string
+)
+
+func runCheckReorgCmd(_ *cobra.Command, _ []string) error {
+	if len(checkReorgFork) == 0 {
+		return errors.New("--fork must be provided")
+	}
+
+	var fork []*types.Block
+	if err := utils.LoadAndParse(checkReorgFork, &fork); err != nil {
+		return fmt.Errorf("%w: unable to load fork", err)
+	}
+
+	if len(fork) == 0 {
+		return errors.New("fork does not contain any blocks")
+	}
+
+	// Create a new fetcher
+	fetcherOpts := []fetcher.Option{
+		fetcher.WithMaxConnections(Config.MaxOnlineConnections),
+		fetcher.WithRetryElapsedTime(time.Duration(Config.RetryElapsedTime) * time.Second),
+		fetcher.WithTimeout(time.Duration(Config.HTTPTimeout) * time.Second),
+		fetcher.WithMaxRetries(Config.MaxRetries),
+	}
+	if Config.ForceRetry {
+		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
+	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		clientOptions,
+	)))
+
+	newFetcher := fetcher.New(
+		Config.OnlineURL,
+		fetcherOpts...,
+	)
+
+	_, _, fetchErr := newFetcher.InitializeAsserter(Context, Config.Network, Config.ValidationFile)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to initialize asserter", fetchErr.Err)
+	}
+
+	status, err := utils.CheckNetworkSupported(Context, Config.Network, newFetcher)
+	if err != nil {
+		return fmt.Errorf("%w: unable to confirm network is supported", err)
+	}
+
+	for _, block := range fork {
+		if err := newFetcher.Asserter.Block(block); err != nil {
+			return fmt.Errorf("%w: fork block is invalid", err)
+		}
+	}
+
+	forkStart := fork[0].BlockIdentifier.Index
+	if forkStart <= status.GenesisBlockIdentifier.Index {
+		return errors.New("fork must not replace the genesis block")
+	}
+
+	parent, err := fetchReorgBlock(newFetcher, forkStart-1)
+	if err != nil {
+		return err
+	}
+
+	canonical := []*types.Block{}
+	forkEnd := fork[len(fork)-1].BlockIdentifier.Index
+	for index := forkStart; index <= forkEnd && index <= status.CurrentBlockIdentifier.Index; index++ {
+		block, err := fetchReorgBlock(newFetcher, index)
+		if err != nil {
+			return err
+		}
+
+		canonical = append(canonical, block)
+	}
+
+	if len(canonical) == 0 {
+		return fmt.Errorf("node has no blocks at or after index %d", forkStart)
+	}
+
+	if types.Hash(canonical[0].BlockIdentifier) == types.Hash(fork[0].BlockIdentifier) {
+		return fmt.Errorf(
+			"fork block %s is already canonical",
+			types.PrintStruct(fork[0].BlockIdentifier),
+		)
+	}
+
+	color.Cyan(
+		"Simulating reorg of %d canonical block(s) to %d fork block(s) after %s",
+		len(canonical),
+		len(fork),
+		types.PrintStruct(parent.BlockIdentifier),
+	)
+
+	inconsistencies, err := processor.SimulateReorg(
+		Context,
+		newFetcher.Asserter,
+		parent,
+		canonical,
+		fork,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to simulate reorg", err)
+	}
+
+	if len(inconsistencies) > 0 {
+		results.PrintReorgInconsistencies(inconsistencies)
+		return fmt.Errorf(
+			"%w: %d account(s) differ",
+			results.ErrReorgInconsistent,
+			len(inconsistencies),
+		)
+	}
+
+	color.Green("Balances are consistent after reorg")
+	return nil
+}
+
+// fetchReorgBlock fetches the block at index, returning an
+// error if the block is omitted (a reorg cannot be simulated
+// across omitted blocks).
+func fetchReorgBlock(f *fetcher.Fetcher, index int64) (*types.Block, error) {
+	block, fetchErr := f.BlockRetry(
+		Context,
+		Config.Network,
+		&types.PartialBlockIdentifier{
+			Index: &index,
+		},
+	)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, index)
+	}
+
+	if block == nil {
+		return nil, fmt.Errorf("block %d is omitted", index)
+	}
+
+	return block, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkScheduleCmd = &cobra.Command{
+		Use:   "check:schedule",
+		Short: "Run a sequence of time-boxed check:data and check:construction phases",
+		Long: `The check:schedule command runs the phases configured in schedule.phases
+in order, so that an endurance test plan (ex: sync for 2 hours, then construct
+transactions at a high rate for 1 hour, then follow tip with reconciliation for
+4 hours) can be encoded in the configuration file instead of external scripts.
+
+Each phase runs check:data or check:construction with the top-level configuration
+(merged with any overrides provided for the phase) until its duration elapses
+or another end condition is met. All phases share the same data directory, so
+later phases continue from the state left by earlier phases. If a phase fails,
+no later phases are run.
+
+The results of each phase are printed when it ends and a summary of all phases
+is printed (and saved to schedule.results_output_file) when the schedule ends.`,
+		RunE: runCheckScheduleCmd,
+	}
+)
+
+func runCheckScheduleCmd(cmd *cobra.Command, _ []string) error {
+	if Config.Schedule == nil {
+		return errors.New("schedule configuration is missing")
+	}
+
+	// All phases must share a data directory (even
+	// if a temporary one is used).
+	ensureDataDirectoryExists()
+	schedule := Config.Schedule
+	baseConfig := Config
+	defer func() {
+		Config = baseConfig
+	}()
+
+	var scheduleErr error
+	for _, phase := range schedule.Phases {
+		phaseConfig, err := configuration.ApplyPhase(Context, baseConfig, phase)
+		if err != nil {
+			scheduleErr = fmt.Errorf("%w: unable to configure phase %s", err, phase.Name)
+			break
+		}
+
+		Config = phaseConfig
+		color.Cyan(
+			"Starting phase %s (check:%s for at most %ds)",
+			phase.Name,
+			phase.Check,
+			phase.Duration,
+		)
+
+		results.StartPhase(phase.Name, phase.Check)
+		if phase.Check == configuration.ConstructionPhaseCheck {
+			err = runCheckConstructionCmd(cmd, nil)
+		} else {
+			err = runCheckDataCmd(cmd, nil)
+		}
+		results.EndPhase(err)
+
+		if err != nil {
+			scheduleErr = fmt.Errorf("%w: phase %s failed", err, phase.Name)
+			break
+		}
+
+		if SignalReceived {
+			scheduleErr = fmt.Errorf("%w: schedule halted", results.ErrCheckHalted)
+			break
+		}
+	}
+
+	scheduleResults := results.ComputeScheduleResults(scheduleErr)
+	scheduleResults.Print()
+	scheduleResults.Output(schedule.ResultsOutputFile)
+
+	return scheduleErr
+}
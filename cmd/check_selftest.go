@@ -0,0 +1,148 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// selftestSeed seeds the synthetic chain served
+	// by the reference server.
+	selftestSeed = 1
+
+	// selftestConfigurationFile is the name of the file the
+	// selftest configuration is written to (and loaded from
+	// so that it is populated and validated like any other
+	// configuration file).
+	selftestConfigurationFile = "selftest_configuration.json"
+)
+
+var (
+	checkSelftestCmd = &cobra.Command{
+		Use:   "check:selftest",
+		Short: "Run check:data against an in-process reference implementation",
+		Long: `Verify the rosetta-cli binary and your configuration work before
+pointing them at a real implementation. This command starts a small reference
+Data API implementation in-process (serving a deterministic synthetic chain
+where each block rewards a random account and contains random transfers) and
+runs check:data against it until --blocks blocks are synced and reconciled.
+
+The general settings in your configuration file (ex: concurrency, timeouts,
+retries, and compression) are used as-is. The network, online_url, and
+data_directory are replaced to point at the reference implementation (using a
+temporary data directory), and the data section is replaced with the default
+data configuration (ending at the last block). The construction and schedule
+sections are ignored.`,
+		RunE: runCheckSelftestCmd,
+	}
+
+	// selftestBlocks is the number of blocks (including
+	// genesis) on the synthetic chain.
+	selftestBlocks int64
+)
+
+// selftestConfiguration returns a copy of config that runs
+// check:data against the reference implementation at url.
+func selftestConfiguration(
+	config *configuration.Configuration,
+	url string,
+	dataDirectory string,
+) *configuration.Configuration {
+	selftestConfig := *config
+	selftestConfig.Network = tester.ReferenceNetwork
+	selftestConfig.OnlineURL = url
+	selftestConfig.DataDirectory = dataDirectory
+	selftestConfig.ValidationFile = ""
+	selftestConfig.Construction = nil
+	selftestConfig.Schedule = nil
+
+	tip := selftestBlocks - 1
+	selftestConfig.Data = configuration.DefaultDataConfiguration()
+	selftestConfig.Data.EndConditions = &configuration.DataEndConditions{
+		Index: &tip,
+	}
+
+	return &selftestConfig
+}
+
+func runCheckSelftestCmd(cmd *cobra.Command, _ []string) error {
+	handler, err := tester.NewReferenceServer(selftestBlocks, selftestSeed)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize reference implementation", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("%w: unable to listen for reference implementation", err)
+	}
+
+	server := &http.Server{Handler: handler}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			color.Red("%s: reference implementation stopped", err.Error())
+		}
+	}()
+	defer server.Close()
+
+	dataDirectory, err := utils.CreateTempDir()
+	if err != nil {
+		return fmt.Errorf("%w: unable to create temporary directory", err)
+	}
+	defer utils.RemoveTempDir(dataDirectory)
+
+	configPath := path.Join(dataDirectory, selftestConfigurationFile)
+	if err := utils.SerializeAndWrite(configPath, selftestConfiguration(
+		Config,
+		fmt.Sprintf("http://%s", listener.Addr().String()),
+		dataDirectory,
+	)); err != nil {
+		return fmt.Errorf("%w: unable to write selftest configuration", err)
+	}
+
+	Config, err = configuration.LoadConfiguration(Context, configPath)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load selftest configuration", err)
+	}
+
+	configFingerprint, err = results.ConfigFingerprint(Config)
+	if err != nil {
+		return fmt.Errorf("%w: unable to fingerprint configuration", err)
+	}
+
+	color.Cyan(
+		"running check:data against reference implementation at %s (%d blocks)",
+		Config.OnlineURL,
+		selftestBlocks,
+	)
+	if err := runCheckDataCmd(cmd, nil); err != nil {
+		return fmt.Errorf("%w: selftest failed", err)
+	}
+
+	color.Green("Selftest passed")
+	return nil
+}
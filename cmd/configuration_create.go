@@ -15,27 +15,276 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
 
-	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+const (
+	// createDecimalsProbeBlocks is the number of blocks fetched by
+	// the decimals probe of a probed configuration.
+	createDecimalsProbeBlocks = 10
+
+	// createCounterSuffix is appended to the name of the custom
+	// counter created for each supported operation type.
+	createCounterSuffix = "_operations"
+)
+
 var (
 	configurationCreateCmd = &cobra.Command{
 		Use:   "configuration:create",
 		Short: "Create a default configuration file at the provided path",
-		RunE:  runConfigurationCreateCmd,
-		Args:  cobra.ExactArgs(1),
+		Long: `Create a default configuration file at the provided path. If
+--comments is provided, each field in the file is preceded by a comment
+describing it (lines starting with // are ignored when a configuration
+file is loaded by the rosetta-cli, but the file is no longer valid JSON).
+
+If --url is provided, the network identifier, supported operation types
+(as custom counters), and currencies observed in recent blocks (as the
+currencies of the decimals probe) are populated by probing the Rosetta
+implementation at that URL. If the implementation supports multiple
+networks, --network selects one by name.`,
+		RunE: runConfigurationCreateCmd,
+		Args: cobra.ExactArgs(1),
 	}
+
+	createURL      string
+	createComments bool
+
+	// counterNameRegex matches the characters of an operation
+	// type that are replaced in the name of its custom counter.
+	counterNameRegex = regexp.MustCompile(`[^a-z0-9]+`)
 )
 
 func runConfigurationCreateCmd(cmd *cobra.Command, args []string) error {
-	if err := utils.SerializeAndWrite(args[0], configuration.DefaultConfiguration()); err != nil {
+	config := configuration.DefaultConfiguration()
+	if len(createURL) > 0 {
+		var err error
+		config, err = probeConfiguration(Context, createURL, networkName)
+		if err != nil {
+			return fmt.Errorf("%w: unable to probe %s", err, createURL)
+		}
+	}
+
+	b := []byte(types.PrettyPrintStruct(config))
+	if createComments {
+		var err error
+		b, err = configuration.MarshalCommented(config)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(path.Clean(args[0]), b, 0600); err != nil {
 		return fmt.Errorf("%w: unable to save configuration file to %s", err, args[0])
 	}
 
+	color.Green("Configuration file saved to %s!", args[0])
 	return nil
 }
+
+// probeConfiguration returns the default *configuration.Configuration
+// populated with the network identifier, supported operation types,
+// and currencies of the implementation at onlineURL.
+func probeConfiguration(
+	ctx context.Context,
+	onlineURL string,
+	networkName string,
+) (*configuration.Configuration, error) {
+	config := configuration.DefaultConfiguration()
+	config.OnlineURL = onlineURL
+
+	f := fetcher.New(
+		onlineURL,
+		fetcher.WithTimeout(time.Duration(config.HTTPTimeout)*time.Second),
+		fetcher.WithMaxRetries(config.MaxRetries),
+		fetcher.WithClient(processor.NewClient(
+			onlineURL,
+			time.Duration(config.HTTPTimeout)*time.Second,
+			config.MaxOnlineConnections,
+			clientOptions,
+		)),
+	)
+
+	networks, fetchErr := f.NetworkListRetry(ctx, nil)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to fetch network list", fetchErr.Err)
+	}
+
+	network, err := selectNetwork(networks.NetworkIdentifiers, networkName)
+	if err != nil {
+		return nil, err
+	}
+	config.Network = network
+
+	// The asserter must be initialized to fetch blocks.
+	_, status, fetchErr := f.InitializeAsserter(ctx, network, "")
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to initialize asserter", fetchErr.Err)
+	}
+
+	options, fetchErr := f.NetworkOptionsRetry(ctx, network, nil)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to fetch network options", fetchErr.Err)
+	}
+	config.Data.CustomCounters = operationTypeCounters(options.Allow.OperationTypes)
+
+	currencies, err := observedCurrencies(ctx, f, network, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(currencies) > 0 {
+		config.DecimalsProbe = &configuration.DecimalsProbeConfiguration{
+			Blocks:     createDecimalsProbeBlocks,
+			Currencies: currencies,
+		}
+	} else {
+		color.Yellow("no currencies observed in the current or genesis block")
+	}
+
+	return config, nil
+}
+
+// selectNetwork returns the network named networkName (or the only
+// network if networkName is empty).
+func selectNetwork(
+	networks []*types.NetworkIdentifier,
+	networkName string,
+) (*types.NetworkIdentifier, error) {
+	if len(networkName) == 0 {
+		if len(networks) != 1 {
+			return nil, fmt.Errorf(
+				"%d networks supported (%s), select one with --network",
+				len(networks),
+				networkNames(networks),
+			)
+		}
+
+		return networks[0], nil
+	}
+
+	for _, network := range networks {
+		if network.Network == networkName {
+			return network, nil
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"network %s is not supported (%s)",
+		networkName,
+		networkNames(networks),
+	)
+}
+
+// networkNames returns a comma-separated list
+// of the names of networks.
+func networkNames(networks []*types.NetworkIdentifier) string {
+	names := make([]string, len(networks))
+	for i, network := range networks {
+		names[i] = network.Network
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// operationTypeCounters returns a custom counter
+// for each of operationTypes.
+func operationTypeCounters(operationTypes []string) []*configuration.CustomCounter {
+	counters := []*configuration.CustomCounter{}
+	seen := map[string]struct{}{}
+	for _, operationType := range operationTypes {
+		name := strings.Trim(counterNameRegex.ReplaceAllString(
+			strings.ToLower(operationType),
+			"_",
+		), "_") + createCounterSuffix
+
+		// Distinct operation types may have the same counter name
+		// (ex: "Fee" and "FEE"), so we number any duplicates.
+		unique := name
+		for i := 2; ; i++ {
+			if _, ok := seen[unique]; !ok {
+				break
+			}
+			unique = fmt.Sprintf("%s_%d", name, i)
+		}
+		seen[unique] = struct{}{}
+
+		counters = append(counters, &configuration.CustomCounter{
+			Name: unique,
+			Type: operationType,
+		})
+	}
+
+	return counters
+}
+
+// observedCurrencies returns the currencies of operation amounts
+// in the current block (or in the genesis block if there are none)
+// sorted by symbol.
+func observedCurrencies(
+	ctx context.Context,
+	f *fetcher.Fetcher,
+	network *types.NetworkIdentifier,
+	status *types.NetworkStatusResponse,
+) ([]*types.Currency, error) {
+	for _, identifier := range []*types.BlockIdentifier{
+		status.CurrentBlockIdentifier,
+		status.GenesisBlockIdentifier,
+	} {
+		block, fetchErr := f.BlockRetry(
+			ctx,
+			network,
+			types.ConstructPartialBlockIdentifier(identifier),
+		)
+		if fetchErr != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to fetch block %d",
+				fetchErr.Err,
+				identifier.Index,
+			)
+		}
+
+		currencies := map[string]*types.Currency{}
+		for _, tx := range block.Transactions {
+			for _, op := range tx.Operations {
+				if op.Amount != nil {
+					currencies[types.Hash(op.Amount.Currency)] = op.Amount.Currency
+				}
+			}
+		}
+
+		if len(currencies) == 0 {
+			continue
+		}
+
+		sorted := make([]*types.Currency, 0, len(currencies))
+		for _, currency := range currencies {
+			sorted = append(sorted, currency)
+		}
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].Symbol != sorted[j].Symbol {
+				return sorted[i].Symbol < sorted[j].Symbol
+			}
+
+			return types.Hash(sorted[i]) < types.Hash(sorted[j])
+		})
+
+		return sorted, nil
+	}
+
+	return nil, nil
+}
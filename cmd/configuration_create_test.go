@@ -0,0 +1,110 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConfigurationCreateCmd(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+	defer func() { createComments = false }()
+
+	// Configuration files are plain JSON by default
+	plainPath := path.Join(dir, "plain.json")
+	assert.NoError(t, runConfigurationCreateCmd(configurationCreateCmd, []string{plainPath}))
+	b, err := ioutil.ReadFile(plainPath)
+	assert.NoError(t, err)
+	var plain configuration.Configuration
+	assert.NoError(t, json.Unmarshal(b, &plain))
+	assert.NotContains(t, string(b), "// ")
+
+	// Comments are only written when requested
+	createComments = true
+	commentedPath := path.Join(dir, "commented.json")
+	assert.NoError(t, runConfigurationCreateCmd(configurationCreateCmd, []string{commentedPath}))
+	b, err = ioutil.ReadFile(commentedPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "// ")
+
+	commented, err := configuration.LoadConfiguration(context.Background(), commentedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.Network, commented.Network)
+}
+
+func TestProbeConfiguration(t *testing.T) {
+	handler, err := tester.NewReferenceServer(10, 1)
+	assert.NoError(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx := context.Background()
+	config, err := probeConfiguration(ctx, server.URL, "")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, config.OnlineURL)
+	assert.Equal(t, tester.ReferenceNetwork, config.Network)
+	assert.Equal(t, []*configuration.CustomCounter{
+		{Name: "reward_operations", Type: "REWARD"},
+		{Name: "transfer_operations", Type: "TRANSFER"},
+	}, config.Data.CustomCounters)
+	assert.Equal(t, &configuration.DecimalsProbeConfiguration{
+		Blocks:     createDecimalsProbeBlocks,
+		Currencies: []*types.Currency{{Symbol: "SELF", Decimals: 8}},
+	}, config.DecimalsProbe)
+
+	_, err = probeConfiguration(ctx, server.URL, "Mainnet")
+	assert.Error(t, err)
+}
+
+func TestSelectNetwork(t *testing.T) {
+	mainnet := &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"}
+	testnet := &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Testnet3"}
+
+	network, err := selectNetwork([]*types.NetworkIdentifier{mainnet}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, mainnet, network)
+
+	_, err = selectNetwork([]*types.NetworkIdentifier{mainnet, testnet}, "")
+	assert.Error(t, err)
+
+	network, err = selectNetwork([]*types.NetworkIdentifier{mainnet, testnet}, "Testnet3")
+	assert.NoError(t, err)
+	assert.Equal(t, testnet, network)
+
+	_, err = selectNetwork([]*types.NetworkIdentifier{mainnet, testnet}, "Regtest")
+	assert.Error(t, err)
+}
+
+func TestOperationTypeCounters(t *testing.T) {
+	assert.Equal(t, []*configuration.CustomCounter{
+		{Name: "transfer_operations", Type: "Transfer"},
+		{Name: "fee_payment_operations", Type: "Fee Payment"},
+		{Name: "transfer_operations_2", Type: "TRANSFER"},
+	}, operationTypeCounters([]string{"Transfer", "Fee Payment", "TRANSFER"}))
+}
@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/coinbase/rosetta-cli/configuration"
@@ -27,14 +28,29 @@ var (
 	configurationValidateCmd = &cobra.Command{
 		Use:   "configuration:validate",
 		Short: "Ensure a configuration file at the provided path is formatted correctly",
-		RunE:  runConfigurationValidateCmd,
-		Args:  cobra.ExactArgs(1),
+		Long: `Validate the configuration file at the provided path. The file is
+first checked against the configuration schema, reporting every unknown
+key (with a suggestion if it looks like a misspelled key) and every value
+of the wrong type along with the line and column where it appears. The
+populated configuration is then checked for missing required fields and
+conflicting settings (ex: construction.nonce_tracking with an
+accounting_model of utxo).
+
+The same validation is performed when a configuration file is provided
+to any other command.`,
+		RunE: runConfigurationValidateCmd,
+		Args: cobra.ExactArgs(1),
 	}
 )
 
 func runConfigurationValidateCmd(cmd *cobra.Command, args []string) error {
 	_, err := configuration.LoadConfiguration(Context, args[0])
 	if err != nil {
+		var schemaErrs configuration.SchemaErrors
+		if errors.As(err, &schemaErrs) {
+			color.Red(schemaErrs.Report())
+		}
+
 		return fmt.Errorf("%w: configuration validation failed %s", err, args[0])
 	}
 
@@ -0,0 +1,112 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// exampleConfigurationFile is the name of the configuration
+	// file in each example directory.
+	exampleConfigurationFile = "config.json"
+
+	// dataCheck runs check:data against an example.
+	dataCheck = "data"
+
+	// constructionCheck runs check:construction against an example.
+	constructionCheck = "construction"
+)
+
+var (
+	examplesRunCmd = &cobra.Command{
+		Use:   "examples:run",
+		Short: "Run checks against a locally running reference implementation",
+		Long: `Run check:data or check:construction using a ready-made configuration
+for a reference implementation (ex: bitcoin or ethereum) running locally.
+
+Example configurations are loaded from <examples-directory>/<chain>/config.json
+and assume the implementation is serving the Data API on port 8080 and the
+Construction API (in offline mode) on port 8081. Any --configuration-file
+provided is ignored: --network, --spec-version, and --results-output are
+applied to the example configuration instead. Flags of check:data and
+check:construction that only apply to your own runs (--resume, --fresh,
+--end-return-funds, --all-networks, and --parallel) are not supported.
+
+These examples serve both as living documentation of how to configure the
+rosetta-cli and as an integration test bed for the rosetta-cli itself.`,
+		RunE: runExamplesRunCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	examplesDirectory string
+	examplesCheck     string
+)
+
+func runExamplesRunCmd(cmd *cobra.Command, args []string) error {
+	if examplesCheck != dataCheck && examplesCheck != constructionCheck {
+		return fmt.Errorf(
+			"check %s is not supported (must be %s or %s)",
+			examplesCheck,
+			dataCheck,
+			constructionCheck,
+		)
+	}
+
+	if err := loadExample(args[0]); err != nil {
+		return err
+	}
+
+	color.Cyan("running check:%s against %s example", examplesCheck, args[0])
+	if examplesCheck == constructionCheck {
+		return runCheckConstructionCmd(cmd, nil)
+	}
+
+	return runCheckDataCmd(cmd, nil)
+}
+
+// loadExample replaces Config (and everything initConfig
+// derives from it) with the configuration of the example
+// for chain.
+func loadExample(chain string) error {
+	configPath := path.Join(examplesDirectory, chain, exampleConfigurationFile)
+	if _, err := os.Stat(configPath); err != nil {
+		return fmt.Errorf("%w: no example configuration found for %s", err, chain)
+	}
+
+	config, err := configuration.LoadConfiguration(Context, configPath)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load example configuration %s", err, configPath)
+	}
+
+	if err := prepareConfiguration(config, true); err != nil {
+		return err
+	}
+
+	if examplesCheck == constructionCheck {
+		applyResultsOutput(checkConstructionCmd)
+	} else {
+		applyResultsOutput(checkDataCmd)
+	}
+
+	return nil
+}
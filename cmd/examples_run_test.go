@@ -0,0 +1,123 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const testExampleConfiguration = `{
+	"network": {"blockchain": "Bitcoin", "network": "Mainnet"},
+	"online_url": "http://localhost:8080",
+	"headers": {"X-Api-Key": "secret"},
+	"networks": [
+		{
+			"name": "mainnet",
+			"network": {"blockchain": "Bitcoin", "network": "Mainnet"}
+		},
+		{
+			"name": "testnet",
+			"network": {"blockchain": "Bitcoin", "network": "Testnet3"},
+			"online_url": "http://localhost:8090"
+		}
+	]
+}`
+
+func TestLoadExample(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	assert.NoError(t, os.MkdirAll(path.Join(dir, "bitcoin"), os.ModePerm))
+	assert.NoError(t, os.WriteFile(
+		path.Join(dir, "bitcoin", exampleConfigurationFile),
+		[]byte(testExampleConfiguration),
+		os.ModePerm,
+	))
+
+	defer func(config *configuration.Configuration) {
+		Context = nil
+		Config = config
+		clientOptions = nil
+		configFingerprint = ""
+		configuredNetworks = nil
+		examplesDirectory = ""
+		examplesCheck = ""
+		networkName = ""
+		specVersion = ""
+		resultsOutput = ""
+	}(Config)
+
+	Context = context.Background()
+	Config = configuration.DefaultConfiguration()
+	examplesDirectory = dir
+	examplesCheck = dataCheck
+	networkName = "testnet"
+	specVersion = "1.4.10"
+	resultsOutput = path.Join(dir, "results.json")
+
+	assert.Error(t, loadExample("ethereum"))
+	assert.NoError(t, loadExample("bitcoin"))
+
+	// Network selection, the spec version, and --results-output are
+	// applied to the example configuration
+	assert.Equal(t, &types.NetworkIdentifier{
+		Blockchain: "Bitcoin",
+		Network:    "Testnet3",
+	}, Config.Network)
+	assert.Equal(t, "http://localhost:8090", Config.OnlineURL)
+	assert.Equal(t, "1.4.10", Config.SpecVersion)
+	assert.Equal(t, resultsOutput, Config.Data.ResultsOutputFile)
+	assert.Len(t, configuredNetworks, 2)
+
+	// Request options and the fingerprint are derived from it
+	assert.NotNil(t, clientOptions.Headers)
+	assert.NotEmpty(t, configFingerprint)
+
+	networkName = "regtest"
+	assert.Error(t, loadExample("bitcoin"))
+}
+
+func TestExamplesRunFlags(t *testing.T) {
+	for _, flag := range []string{
+		"asserter-configuration-file",
+		"spec-version",
+		"metrics-addr",
+		"results-output",
+		"force-takeover",
+	} {
+		assert.NotNil(t, examplesRunCmd.Flags().Lookup(flag), flag)
+	}
+
+	// Flags that only apply to your own runs are rejected
+	for _, flag := range []string{
+		"resume",
+		"fresh",
+		"end-return-funds",
+		"all-networks",
+		"parallel",
+	} {
+		assert.Nil(t, examplesRunCmd.Flags().Lookup(flag), flag)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keysImportCmd = &cobra.Command{
+		Use:   "keys:import",
+		Short: "Import keys (and their tags) into check:construction",
+		Long: `This command imports keys from a CSV file into the check:construction
+database in data_directory (so data_directory must be populated in the
+configuration file). Each row of the CSV contains an address, a curve type,
+a hex-encoded private key, and (optionally) tags separated by semicolons:
+
+address,curve_type,private_key,tags
+addr1,secp256k1,<private key>,faucet;hot
+
+Tags can be used to restrict the keys used by sender pipelines
+(construction.sender_pipelines.sender_tags and recipient_tags). Keys that
+already exist are not overwritten but their tags are replaced. If any row is
+invalid, no keys are imported.
+
+The check:construction database must not be in use while importing.`,
+		RunE: runKeysImportCmd,
+		Args: cobra.NoArgs,
+	}
+
+	keysImportCSV string
+)
+
+func runKeysImportCmd(cmd *cobra.Command, args []string) error {
+	if len(keysImportCSV) == 0 {
+		return errors.New("--csv must be provided")
+	}
+
+	if len(Config.DataDirectory) == 0 {
+		return errors.New("data_directory must be populated to import keys")
+	}
+
+	f, err := os.Open(path.Clean(keysImportCSV))
+	if err != nil {
+		return fmt.Errorf("%w: unable to open key file", err)
+	}
+	defer f.Close()
+
+	dataPath, err := tester.ConstructionDataPath(Config, Config.Network)
+	if err != nil {
+		return fmt.Errorf("%w: cannot create command path", err)
+	}
+
+	lock, err := tester.AcquireDataDirectoryLock(dataPath, false)
+	if err != nil {
+		return fmt.Errorf("%w: unable to lock data directory", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if Config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(Context, dataPath, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize database", err)
+	}
+	defer localStore.Close(Context)
+
+	imported, err := processor.ImportKeysCSV(
+		Context,
+		f,
+		localStore,
+		modules.NewKeyStorage(localStore),
+		processor.NewKeyTagStorage(localStore),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to import keys", err)
+	}
+
+	color.Green("Imported %d keys into %s", imported, dataPath)
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// offlineAgentCmdName is used as the prefix on the data
+	// directory for the keys stored by the offline-agent.
+	offlineAgentCmdName = "offline-agent"
+)
+
+var (
+	offlineAgentCmd = &cobra.Command{
+		Use:   "offline-agent",
+		Short: "Run offline construction and signing for an air-gapped check:construction",
+		Long: `When construction.air_gap is populated, check:construction does not
+call any offline Construction API endpoints or sign any payloads. Instead,
+it writes requests to construction.air_gap.exchange_directory and waits
+for the offline-agent to write the responses. The offline-agent only
+connects to construction.offline_url, so running it on a host that can't
+reach the network (moving the files between hosts, ex: as QR codes when
+using the base32 encoding) validates that the offline flow of an
+implementation truly needs no network.
+
+The offline-agent generates the keys of the address pool and signs with the
+keys it stores in data_directory (prefunded accounts are imported from the
+configuration file). Workflows with a generate_key action can't be used
+with an air gap (their keys would be created by check:construction), so new
+accounts must come from the address pool. Private keys of prefunded accounts
+are also imported by check:construction, so only fund them with amounts you
+are willing to expose on the host with network access.
+
+The argument for this command is the path of an asserter configuration
+file (generated on a host with network access by running
+utils:asserter-configuration) used to validate /construction/parse
+responses.`,
+		RunE: runOfflineAgentCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runOfflineAgentCmd(cmd *cobra.Command, args []string) error {
+	if Config.Construction == nil || Config.Construction.AirGap == nil {
+		return errors.New("construction.air_gap configuration is missing")
+	}
+
+	if Config.Construction.OfflineURL == Config.OnlineURL {
+		return errors.New("offline_url must be different than online_url when using an air gap")
+	}
+
+	offlineAsserter, err := asserter.NewClientWithFile(path.Clean(args[0]))
+	if err != nil {
+		return fmt.Errorf("%w: unable to load asserter configuration", err)
+	}
+
+	ensureDataDirectoryExists()
+	dataPath, err := utils.CreateCommandPath(Config.DataDirectory, offlineAgentCmdName, Config.Network)
+	if err != nil {
+		return fmt.Errorf("%w: cannot create command path", err)
+	}
+
+	lock, err := tester.AcquireDataDirectoryLock(dataPath, false)
+	if err != nil {
+		return fmt.Errorf("%w: unable to lock data directory", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if Config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(Context, dataPath, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize database", err)
+	}
+	defer localStore.Close(Context)
+
+	keyStorage := modules.NewKeyStorage(localStore)
+	if err := keyStorage.ImportAccounts(Context, Config.Construction.PrefundedAccounts); err != nil {
+		return fmt.Errorf("%w: unable to import prefunded accounts", err)
+	}
+
+	fetcherOpts := []fetcher.Option{
+		fetcher.WithMaxConnections(Config.Construction.MaxOfflineConnections),
+		fetcher.WithAsserter(offlineAsserter),
+		fetcher.WithTimeout(time.Duration(Config.HTTPTimeout) * time.Second),
+		fetcher.WithMaxRetries(Config.MaxRetries),
+	}
+	if Config.Construction.ForceRetry {
+		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
+	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewClient(
+		Config.Construction.OfflineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.Construction.MaxOfflineConnections,
+		clientOptions,
+	)))
+
+	agent := processor.NewAirGapAgent(
+		Config.Construction.AirGap,
+		fetcher.New(Config.Construction.OfflineURL, fetcherOpts...),
+		keyStorage,
+	)
+
+	ctx, cancel := context.WithCancel(Context)
+	defer cancel()
+
+	sigListeners := []context.CancelFunc{cancel}
+	go handleSignals(&sigListeners)
+
+	color.Cyan(
+		"offline-agent responding to requests in %s (encoding: %s)",
+		Config.Construction.AirGap.ExchangeDirectory,
+		Config.Construction.AirGap.Encoding,
+	)
+	if err := agent.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
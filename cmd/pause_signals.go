@@ -0,0 +1,47 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+)
+
+// handlePauseSignals toggles transaction creation in
+// check:construction each time a pause signal is received
+// (SIGUSR2 on platforms that support it).
+func handlePauseSignals(ctx context.Context, constructionTester *tester.ConstructionTester) {
+	if len(pauseSignals) == 0 {
+		return
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, pauseSignals...)
+	go func() {
+		defer signal.Stop(sigs)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigs:
+				constructionTester.TogglePause()
+			}
+		}
+	}()
+}
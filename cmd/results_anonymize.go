@@ -0,0 +1,119 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	anonymizeMinArgs = 2
+)
+
+var (
+	resultsAnonymizeCmd = &cobra.Command{
+		Use:   "results:anonymize",
+		Short: "Anonymize reports so they can be shared publicly",
+		Long: `results:anonymize replaces the addresses and transaction hashes in
+JSON reports (ex: results files and construction failure records) with salted
+hashes, so that reports can be shared publicly without exposing production
+account identifiers.
+
+Identifiers are collected from the address field of any object and the hash
+field of any transaction_identifier in all provided reports. Each is then
+replaced wherever it appears (including within error messages). A random
+salt is generated for each invocation (and never saved), so an identifier has
+the same pseudonym in all reports anonymized together but pseudonyms can't
+be linked across invocations. Identifiers that only appear within free-form
+text (and never in one of these fields) are not replaced, so review reports
+before sharing them.
+
+Each anonymized report is written to the output directory with the same
+file name as the original report.
+
+The arguments for this command are:
+<output directory> <report path> [<report path>...]`,
+		RunE: runResultsAnonymizeCmd,
+		Args: cobra.MinimumNArgs(anonymizeMinArgs),
+	}
+)
+
+func runResultsAnonymizeCmd(cmd *cobra.Command, args []string) error {
+	outputDirectory := path.Clean(args[0])
+	reportPaths := args[1:]
+
+	anonymizer, err := results.NewAnonymizer()
+	if err != nil {
+		return err
+	}
+
+	reports := make([][]byte, len(reportPaths))
+	outputPaths := make([]string, len(reportPaths))
+	seen := map[string]struct{}{}
+	for i, reportPath := range reportPaths {
+		outputPaths[i] = path.Join(outputDirectory, path.Base(reportPath))
+		if outputPaths[i] == path.Clean(reportPath) {
+			return fmt.Errorf("anonymized report would overwrite %s", reportPath)
+		}
+
+		if _, ok := seen[outputPaths[i]]; ok {
+			return fmt.Errorf("multiple reports are named %s", path.Base(reportPath))
+		}
+		seen[outputPaths[i]] = struct{}{}
+
+		reports[i], err = ioutil.ReadFile(path.Clean(reportPath))
+		if err != nil {
+			return fmt.Errorf("%w: unable to read %s", err, reportPath)
+		}
+
+		// Identifiers are collected from all reports first
+		// so that they are replaced consistently in every
+		// report.
+		if err := anonymizer.Collect(reports[i]); err != nil {
+			return fmt.Errorf("%w: unable to parse %s", err, reportPath)
+		}
+	}
+
+	if err := utils.EnsurePathExists(outputDirectory); err != nil {
+		return fmt.Errorf("%w: unable to create output directory", err)
+	}
+
+	for i, reportPath := range reportPaths {
+		anonymized, err := anonymizer.Anonymize(reports[i])
+		if err != nil {
+			return fmt.Errorf("%w: unable to anonymize %s", err, reportPath)
+		}
+
+		if err := ioutil.WriteFile(
+			outputPaths[i],
+			anonymized,
+			os.FileMode(utils.DefaultFilePermissions),
+		); err != nil {
+			return fmt.Errorf("%w: unable to write %s", err, outputPaths[i])
+		}
+	}
+
+	color.Green("Anonymized %d reports to %s", len(reportPaths), outputDirectory)
+	return nil
+}
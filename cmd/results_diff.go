@@ -0,0 +1,83 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resultsDiffCmd = &cobra.Command{
+		Use:   "results:diff",
+		Short: "Compare two results files",
+		Long: `results:diff compares two results files written by check:data
+or check:construction (with results_output_file) and prints each field
+that changed (tests, stats, and Rosetta errors), classified as a
+regression, an improvement, or neither. This can be used to track the
+quality of an implementation across releases.
+
+The arguments for this command are:
+<old results path> <new results path>
+
+If any regressions are found, the command exits with status 1.`,
+		RunE: runResultsDiffCmd,
+		Args: cobra.ExactArgs(2),
+	}
+)
+
+func runResultsDiffCmd(cmd *cobra.Command, args []string) error {
+	oldResults, err := ioutil.ReadFile(path.Clean(args[0]))
+	if err != nil {
+		return fmt.Errorf("%w: unable to read %s", err, args[0])
+	}
+
+	newResults, err := ioutil.ReadFile(path.Clean(args[1]))
+	if err != nil {
+		return fmt.Errorf("%w: unable to read %s", err, args[1])
+	}
+
+	changes, err := results.DiffResults(oldResults, newResults)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		color.Green("No changes")
+		return nil
+	}
+
+	results.PrintResultsDiff(changes)
+
+	regressions := 0
+	for _, change := range changes {
+		if change.Kind == results.Regression {
+			regressions++
+		}
+	}
+
+	if regressions > 0 {
+		return fmt.Errorf("found %d regressions", regressions)
+	}
+
+	color.Green("No regressions")
+	return nil
+}
@@ -16,16 +16,24 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path"
 	"runtime"
 	"runtime/pprof"
 	"syscall"
+	"time"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
 
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -34,6 +42,23 @@ import (
 const (
 	// configEnvKey is an env variable name that sets a config file location
 	configEnvKey = "ROSETTA_CONFIGURATION_FILE"
+
+	// runFile is the name of the file in the data directory
+	// that the *results.RunMetadata of the latest run is
+	// written to.
+	runFile = "run.json"
+
+	// exportRunSuffix is appended to the path of an
+	// exported file to get the path its
+	// *results.RunMetadata is written to.
+	exportRunSuffix = ".run.json"
+
+	// leakCheckTimeout is how long goroutines are given
+	// to exit before they are reported as leaked.
+	leakCheckTimeout = 5 * time.Second
+
+	// Version is the version of the rosetta-cli.
+	Version = "v0.7.3"
 )
 
 var (
@@ -47,6 +72,8 @@ var (
 	cpuProfile        string
 	memProfile        string
 	blockProfile      string
+	checkLeaks        bool
+	specVersion       string
 
 	// Config is the populated *configuration.Configuration from
 	// the configurationFile. If none is provided, this is set
@@ -68,10 +95,66 @@ var (
 	// cleanup a running block profile.
 	blockProfileCleanup func()
 
+	// leakBaseline is the snapshot of resources in use before
+	// the command runs (only populated if checkLeaks is true).
+	leakBaseline *tester.ResourceSnapshot
+
+	// configFingerprint is the fingerprint of the loaded
+	// configuration (before any defaults are populated
+	// at runtime, like a temporary data directory).
+	configFingerprint string
+
 	// OnlyChanges is a boolean indicating if only the balance changes should be
 	// logged to the console.
 	OnlyChanges bool
 
+	// endReturnFundsAddress is the address all remaining funds
+	// are returned to when check:construction completes or is
+	// interrupted (using the return_funds workflow).
+	endReturnFundsAddress string
+
+	// resume continues the check:construction or check:data
+	// run persisted in the data directory instead of starting
+	// from scratch.
+	resume bool
+
+	// fresh discards the in-flight state of previous
+	// check:construction runs before starting.
+	fresh bool
+
+	// metricsAddr is the address Prometheus metrics are
+	// served on by check:data and check:construction.
+	metricsAddr string
+
+	// resultsOutput is the path the results of check:data,
+	// check:construction, or check:schedule are written to
+	// as JSON (overrides results_output_file).
+	resultsOutput string
+
+	// clientOptions configure all requests made to the Rosetta
+	// implementation (TLS and headers).
+	clientOptions *processor.ClientOptions
+
+	// networkName selects a network by name: one of the networks
+	// in the configuration file or, when probing an implementation
+	// with configuration:create, one of the networks it supports.
+	networkName string
+
+	// configuredNetworks are the networks in the configuration
+	// file (Config only contains the selected network).
+	configuredNetworks []*configuration.NetworkConfiguration
+
+	// allNetworks runs check:data against all configured networks.
+	allNetworks bool
+
+	// parallelNetworks runs check:data against all configured
+	// networks at the same time (instead of one after another).
+	parallelNetworks bool
+
+	// forceTakeover uses the data directory even if
+	// it is locked by another process.
+	forceTakeover bool
+
 	// If non-empty, used to validate that /network/options matches the contents of the file
 	// located at this path. The intended use case is someone previously ran
 	// utils:asserter-configuration `asserterConfigurationFile`, so the validation is being done
@@ -90,7 +173,13 @@ var (
 // profiling.
 //
 // Bassed on https://golang.org/pkg/runtime/pprof/#hdr-Profiling_a_Go_program
-func rootPreRun(*cobra.Command, []string) error {
+func rootPreRun(cmd *cobra.Command, _ []string) error {
+	applyResultsOutput(cmd)
+
+	if checkLeaks {
+		leakBaseline = tester.TakeResourceSnapshot()
+	}
+
 	if cpuProfile != "" {
 		f, err := os.Create(cpuProfile)
 		if err != nil {
@@ -133,8 +222,17 @@ func rootPreRun(*cobra.Command, []string) error {
 }
 
 // rootPostRun is executed after the root command runs and performs memory
-// profiling.
+// profiling and leak checking.
 func rootPostRun() {
+	if leakBaseline != nil {
+		report := tester.CheckLeaks(leakBaseline, leakCheckTimeout)
+		if report.Empty() {
+			color.Green("No leaked resources found")
+		} else {
+			color.Red("Leaked resources found on shutdown:\n%s", report.String())
+		}
+	}
+
 	if cpuProfileCleanup != nil {
 		cpuProfileCleanup()
 	}
@@ -203,9 +301,36 @@ default values.`,
 		"",
 		`Save the pprof block profile in the specified file`,
 	)
+	rootFlags.BoolVar(
+		&checkLeaks,
+		"check-leaks",
+		false,
+		`On exit, report any goroutines, file descriptors, or databases
+that are still in use (goroutines are given a few seconds to exit)`,
+	)
+	rootFlags.StringVar(
+		&networkName,
+		"network",
+		"",
+		`Name of the network to test if the configuration file defines
+multiple networks (defaults to the first one)`,
+	)
 	rootCmd.AddCommand(versionCmd)
 
 	// Configuration Commands
+	configurationCreateCmd.Flags().StringVar(
+		&createURL,
+		"url",
+		"",
+		"URL of a Rosetta implementation to probe for the network, operation types, and currencies",
+	)
+	configurationCreateCmd.Flags().BoolVar(
+		&createComments,
+		"comments",
+		false,
+		`Precede each field with a // comment describing it (the file can still be
+loaded by the rosetta-cli, but it is no longer valid JSON)`,
+	)
 	rootCmd.AddCommand(configurationCreateCmd)
 	rootCmd.AddCommand(configurationValidateCmd)
 
@@ -216,6 +341,55 @@ default values.`,
 		"", // Default to skip validation
 		`Check that /network/options matches contents of file at this path`,
 	)
+	checkDataCmd.Flags().StringVar(
+		&specVersion,
+		"spec-version",
+		"",
+		`Version of the Rosetta API the implementation was written against
+(overrides spec_version)`,
+	)
+	checkDataCmd.Flags().StringVar(
+		&metricsAddr,
+		"metrics-addr",
+		"",
+		`Serve Prometheus metrics at /metrics on this address (ex: :9090)`,
+	)
+	checkDataCmd.Flags().StringVar(
+		&resultsOutput,
+		"results-output",
+		"",
+		`Write the results (pass/fail status, errors, stats, and timing)
+as JSON to this path (overrides results_output_file)`,
+	)
+	checkDataCmd.Flags().BoolVar(
+		&forceTakeover,
+		"force-takeover",
+		false,
+		`Use the data directory even if it is locked by another
+rosetta-cli process (without taking the lock)`,
+	)
+	checkDataCmd.Flags().BoolVar(
+		&resume,
+		"resume",
+		false,
+		`Continue the run persisted in data_directory from its last processed
+block (ignoring start_index) after verifying that the stored tip is still
+on the node's chain`,
+	)
+	checkDataCmd.Flags().BoolVar(
+		&allNetworks,
+		"all-networks",
+		false,
+		`Run check:data against all networks in the configuration file
+and aggregate their results`,
+	)
+	checkDataCmd.Flags().BoolVar(
+		&parallelNetworks,
+		"parallel",
+		false,
+		`With --all-networks, check all networks at the same time
+(instead of one after another)`,
+	)
 	rootCmd.AddCommand(checkDataCmd)
 	checkConstructionCmd.Flags().StringVar(
 		&asserterConfigurationFile,
@@ -223,7 +397,94 @@ default values.`,
 		"", // Default to skip validation
 		`Check that /network/options matches contents of file at this path`,
 	)
+	checkConstructionCmd.Flags().StringVar(
+		&specVersion,
+		"spec-version",
+		"",
+		`Version of the Rosetta API the implementation was written against
+(overrides spec_version)`,
+	)
+	checkConstructionCmd.Flags().StringVar(
+		&endReturnFundsAddress,
+		"end-return-funds",
+		"",
+		`Return all remaining funds to this address (with the return_funds workflow)
+when the check completes or is interrupted`,
+	)
+	checkConstructionCmd.Flags().BoolVar(
+		&resume,
+		"resume",
+		false,
+		`Continue the unfinished run persisted in data_directory (which requires
+the same configuration)`,
+	)
+	checkConstructionCmd.Flags().BoolVar(
+		&fresh,
+		"fresh",
+		false,
+		`Discard the jobs and broadcasts left in-flight by previous runs and only
+count jobs completed from now on towards end conditions`,
+	)
+	checkConstructionCmd.Flags().StringVar(
+		&metricsAddr,
+		"metrics-addr",
+		"",
+		`Serve Prometheus metrics at /metrics on this address (ex: :9090)`,
+	)
+	checkConstructionCmd.Flags().StringVar(
+		&resultsOutput,
+		"results-output",
+		"",
+		`Write the results (pass/fail status, errors, stats, and timing)
+as JSON to this path (overrides results_output_file)`,
+	)
+	checkConstructionCmd.Flags().BoolVar(
+		&forceTakeover,
+		"force-takeover",
+		false,
+		`Use the data directory even if it is locked by another
+rosetta-cli process (without taking the lock)`,
+	)
 	rootCmd.AddCommand(checkConstructionCmd)
+	checkScheduleCmd.Flags().StringVar(
+		&resultsOutput,
+		"results-output",
+		"",
+		`Write the results (pass/fail status, errors, stats, and timing)
+as JSON to this path (overrides results_output_file)`,
+	)
+	checkScheduleCmd.Flags().BoolVar(
+		&forceTakeover,
+		"force-takeover",
+		false,
+		`Use the data directory even if it is locked by another
+rosetta-cli process (without taking the lock)`,
+	)
+	rootCmd.AddCommand(checkScheduleCmd)
+	checkReorgCmd.Flags().StringVar(
+		&checkReorgFork,
+		"fork",
+		"",
+		`Path of a JSON file containing the fork (an array of blocks) to reorg to`,
+	)
+	rootCmd.AddCommand(checkReorgCmd)
+	checkBlocksCmd.Flags().Int64Var(
+		&timestampTolerance,
+		"timestamp-tolerance",
+		0,
+		`Milliseconds a block timestamp may be before the timestamp of its parent`,
+	)
+	rootCmd.AddCommand(checkBlocksCmd)
+	rootCmd.AddCommand(offlineAgentCmd)
+
+	// Key Commands
+	keysImportCmd.Flags().StringVar(
+		&keysImportCSV,
+		"csv",
+		"",
+		`Path of a CSV file of keys to import (address, curve type, private key, tags)`,
+	)
+	rootCmd.AddCommand(keysImportCmd)
 
 	// View Commands
 	viewBlockCmd.Flags().BoolVar(
@@ -232,13 +493,152 @@ default values.`,
 		false,
 		`Only print balance changes for accounts in the block`,
 	)
+	viewBlockCmd.Flags().BoolVar(
+		&viewBlockFetchTransactions,
+		"fetch-transactions",
+		false,
+		`Fetch each transaction in the block with /block/transaction and
+compare it to the transaction returned in /block`,
+	)
+	viewBlockCmd.Flags().BoolVar(
+		&viewBlockJSON,
+		"json",
+		false,
+		`Print the block, its balance changes, and any fetched transactions
+as JSON`,
+	)
 	rootCmd.AddCommand(viewBlockCmd)
 	rootCmd.AddCommand(viewAccountCmd)
+	viewAccountDetailsCmd.Flags().Int64Var(
+		&viewAccountBlock,
+		"block",
+		-1,
+		`Index of the block to look up balances at (-1 for the current block)`,
+	)
+	rootCmd.AddCommand(viewAccountDetailsCmd)
 	rootCmd.AddCommand(viewNetworksCmd)
 
+	// Examples
+	examplesRunCmd.Flags().StringVar(
+		&examplesDirectory,
+		"examples-directory",
+		"examples",
+		`Directory containing example configurations (one directory per chain)`,
+	)
+	examplesRunCmd.Flags().StringVar(
+		&examplesCheck,
+		"check",
+		dataCheck,
+		`Check to run against the example (data or construction)`,
+	)
+	examplesRunCmd.Flags().StringVar(
+		&asserterConfigurationFile,
+		"asserter-configuration-file",
+		"", // Default to skip validation
+		`Check that /network/options matches contents of file at this path`,
+	)
+	examplesRunCmd.Flags().StringVar(
+		&specVersion,
+		"spec-version",
+		"",
+		`Version of the Rosetta API the implementation was written against
+(overrides spec_version)`,
+	)
+	examplesRunCmd.Flags().StringVar(
+		&metricsAddr,
+		"metrics-addr",
+		"",
+		`Serve Prometheus metrics at /metrics on this address (ex: :9090)`,
+	)
+	examplesRunCmd.Flags().StringVar(
+		&resultsOutput,
+		"results-output",
+		"",
+		`Write the results (pass/fail status, errors, stats, and timing)
+as JSON to this path (overrides results_output_file)`,
+	)
+	examplesRunCmd.Flags().BoolVar(
+		&forceTakeover,
+		"force-takeover",
+		false,
+		`Use the data directory even if it is locked by another
+rosetta-cli process (without taking the lock)`,
+	)
+	rootCmd.AddCommand(examplesRunCmd)
+	checkSelftestCmd.Flags().Int64Var(
+		&selftestBlocks,
+		"blocks",
+		100,
+		`Number of blocks (including genesis) on the synthetic chain`,
+	)
+	rootCmd.AddCommand(checkSelftestCmd)
+
+	// Results
+	rootCmd.AddCommand(resultsDiffCmd)
+	rootCmd.AddCommand(resultsAnonymizeCmd)
+
 	// Utils
 	rootCmd.AddCommand(utilsAsserterConfigurationCmd)
 	rootCmd.AddCommand(utilsTrainZstdCmd)
+	rootCmd.AddCommand(utilsExportCheckpointsCmd)
+	rootCmd.AddCommand(utilsExportAddressBookCmd)
+	rootCmd.AddCommand(utilsExportTransferGraphCmd)
+	utilsSkipReconciliationCmd.Flags().StringVar(
+		&skipAccount,
+		"account",
+		"",
+		`JSON-encoded account identifier of the reconciliation failure to skip`,
+	)
+	utilsSkipReconciliationCmd.Flags().StringVar(
+		&skipCurrency,
+		"currency",
+		"",
+		`JSON-encoded currency of the reconciliation failure to skip`,
+	)
+	utilsSkipReconciliationCmd.Flags().Int64Var(
+		&skipBlock,
+		"block",
+		-1,
+		`Only skip the reconciliation failure at this block index (by default,
+failures at any block are skipped)`,
+	)
+	utilsSkipReconciliationCmd.Flags().StringVar(
+		&skipReason,
+		"reason",
+		"",
+		`Why the reconciliation failure is known (ex: a link to an issue)`,
+	)
+	utilsSkipReconciliationCmd.Flags().DurationVar(
+		&skipExpiresIn,
+		"expires-in",
+		7*24*time.Hour,
+		`How long the skip is active for`,
+	)
+	rootCmd.AddCommand(utilsSkipReconciliationCmd)
+}
+
+// applyResultsOutput overrides the results_output_file of
+// the check being run with --results-output. Within
+// check:schedule, only the results of the schedule are
+// written to this path (not those of each phase). A
+// missing configuration is reported by the check itself.
+func applyResultsOutput(cmd *cobra.Command) {
+	if len(resultsOutput) == 0 {
+		return
+	}
+
+	switch cmd {
+	case checkDataCmd:
+		Config.Data.ResultsOutputFile = resultsOutput
+	case checkConstructionCmd:
+		if Config.Construction != nil {
+			Config.Construction.ResultsOutputFile = resultsOutput
+		}
+	case checkScheduleCmd:
+		if Config.Schedule != nil {
+			Config.Schedule.ResultsOutputFile = resultsOutput
+		}
+	}
 }
 
 func initConfig() {
@@ -257,8 +657,213 @@ func initConfig() {
 		Config, err = configuration.LoadConfiguration(Context, configurationFile)
 	}
 	if err != nil {
+		var schemaErrs configuration.SchemaErrors
+		if errors.As(err, &schemaErrs) {
+			color.Red(schemaErrs.Report())
+		}
+
 		log.Fatalf("%s: unable to load configuration", err.Error())
 	}
+
+	if err := prepareConfiguration(Config, len(configurationFile) > 0); err != nil {
+		log.Fatalf("%s", err.Error())
+	}
+}
+
+// prepareConfiguration selects the --network of config and applies
+// --spec-version before setting Config, clientOptions, and
+// configFingerprint from the result. loaded indicates if config
+// was loaded from a file (instead of being the default
+// configuration). It is used for the --configuration-file in
+// initConfig and by commands that load their own configuration
+// (ex: examples:run).
+func prepareConfiguration(config *configuration.Configuration, loaded bool) error {
+	var err error
+	configuredNetworks = config.Networks
+	if len(configuredNetworks) > 0 {
+		config, err = configuration.ApplyNetwork(Context, config, networkName)
+		if err != nil {
+			return fmt.Errorf("%w: unable to select network", err)
+		}
+	} else if loaded &&
+		len(networkName) > 0 &&
+		networkName != config.Network.Network {
+		return fmt.Errorf(
+			"network %s is not configured (%s)",
+			networkName,
+			config.Network.Network,
+		)
+	}
+
+	if len(specVersion) > 0 {
+		config.SpecVersion = specVersion
+	}
+
+	options, err := processor.NewClientOptions(config)
+	if err != nil {
+		return fmt.Errorf("%w: unable to configure requests", err)
+	}
+	if options.TLS != nil && options.TLS.InsecureSkipVerify {
+		color.Red(
+			"WARNING: TLS certificate verification is disabled " +
+				"(tls.insecure_skip_verify), responses may come from any server",
+		)
+	}
+	if options.Proxy != nil {
+		color.Cyan("routing requests through proxy %s", options.Proxy)
+	}
+
+	fingerprint, err := results.ConfigFingerprint(config)
+	if err != nil {
+		return fmt.Errorf("%w: unable to fingerprint configuration", err)
+	}
+
+	Config = config
+	clientOptions = options
+	configFingerprint = fingerprint
+	return nil
+}
+
+// stampRun generates the *results.RunMetadata for this
+// invocation (with the node version in networkOptions) and
+// writes it to the data directory. All results, status
+// responses, and construction failures are stamped with
+// this metadata. An invocation running multiple checks
+// (ex: check:schedule) is only stamped once.
+func stampRun(networkOptions *types.NetworkOptionsResponse) error {
+	if results.CurrentRunMetadata() != nil {
+		return nil
+	}
+
+	nodeVersion := ""
+	if networkOptions.Version != nil {
+		nodeVersion = networkOptions.Version.NodeVersion
+	}
+
+	run, err := results.NewRunMetadata(configFingerprint, Version, nodeVersion)
+	if err != nil {
+		return err
+	}
+	results.SetRunMetadata(run)
+
+	if err := utils.SerializeAndWrite(path.Join(Config.DataDirectory, runFile), run); err != nil {
+		return fmt.Errorf("%w: unable to write run metadata", err)
+	}
+
+	color.Cyan("Run ID: %s", run.RunID)
+	return nil
+}
+
+// stampExport writes the *results.RunMetadata of this
+// invocation to <exportPath>.run.json so that exported
+// files (which have formats of their own) can be traced
+// back to the run that exported them. Exports don't
+// contact a node, so no node version is stamped.
+func stampExport(exportPath string) error {
+	run := results.CurrentRunMetadata()
+	if run == nil {
+		var err error
+		run, err = results.NewRunMetadata(configFingerprint, Version, "")
+		if err != nil {
+			return err
+		}
+		results.SetRunMetadata(run)
+	}
+
+	if err := utils.SerializeAndWrite(exportPath+exportRunSuffix, run); err != nil {
+		return fmt.Errorf("%w: unable to write run metadata", err)
+	}
+
+	return nil
+}
+
+// reportSpecCompatibility prints the version-specific features
+// that are translated or unsupported when testing an implementation
+// written against Config.SpecVersion (if populated) and warns if the
+// Rosetta version in networkOptions differs.
+func reportSpecCompatibility(networkOptions *types.NetworkOptionsResponse) error {
+	if len(Config.SpecVersion) == 0 {
+		return nil
+	}
+
+	translated, unsupported, err := processor.SpecCompatibility(Config.SpecVersion)
+	if err != nil {
+		return fmt.Errorf("%w: invalid spec version", err)
+	}
+
+	if networkOptions.Version != nil &&
+		networkOptions.Version.RosettaVersion != Config.SpecVersion {
+		color.Yellow(
+			"implementation reports Rosetta API version %s but spec version is %s",
+			networkOptions.Version.RosettaVersion,
+			Config.SpecVersion,
+		)
+	}
+
+	color.Cyan("Spec Version: %s", Config.SpecVersion)
+	for _, feature := range translated {
+		color.Cyan(
+			"translated %s (introduced in %s): %s",
+			feature.Name,
+			feature.Introduced,
+			feature.Description,
+		)
+	}
+
+	for _, feature := range unsupported {
+		color.Yellow(
+			"unsupported %s (introduced in %s): %s",
+			feature.Name,
+			feature.Introduced,
+			feature.Description,
+		)
+	}
+
+	return nil
+}
+
+// probeDecimals checks that amounts in recent blocks are
+// consistent with currency decimals (if configured). Any
+// mismatch is logged and returned as an error so that a run
+// isn't invalidated by a wrong decimals setting.
+func probeDecimals(
+	ctx context.Context,
+	f *fetcher.Fetcher,
+	tip *types.BlockIdentifier,
+	currencies []*types.Currency,
+) error {
+	if Config.DecimalsProbe == nil {
+		return nil
+	}
+
+	expected := []*types.Currency{}
+	expected = append(expected, Config.DecimalsProbe.Currencies...)
+	expected = append(expected, currencies...)
+	mismatches, err := processor.ProbeCurrencyDecimals(
+		ctx,
+		f,
+		Config.Network,
+		tip,
+		Config.DecimalsProbe.Blocks,
+		expected,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to probe currency decimals", err)
+	}
+
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	for _, mismatch := range mismatches {
+		color.Red("%s: %s", types.PrintStruct(mismatch.Currency), mismatch.Reason)
+	}
+
+	return fmt.Errorf(
+		"%w: %d currencies have amounts inconsistent with their decimals",
+		results.ErrCurrencyDecimalsMismatch,
+		len(mismatches),
+	)
 }
 
 func ensureDataDirectoryExists() {
@@ -281,11 +886,15 @@ func handleSignals(listeners *[]context.CancelFunc) {
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
-		sig := <-sigs
-		color.Red("Received signal: %s", sig)
-		SignalReceived = true
-		for _, listener := range *listeners {
-			listener()
+		// We keep handling signals so that any cleanup
+		// started after the first signal (ex: returning
+		// funds) can also be halted.
+		for sig := range sigs {
+			color.Red("Received signal: %s", sig)
+			SignalReceived = true
+			for _, listener := range *listeners {
+				listener()
+			}
 		}
 	}()
 }
@@ -294,6 +903,6 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print rosetta-cli version",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("v0.7.3")
+		fmt.Println(Version)
 	},
 }
@@ -0,0 +1,82 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampRun(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	previousConfig := Config
+	defer func() {
+		Config = previousConfig
+		results.SetRunMetadata(nil)
+	}()
+	Config = configuration.DefaultConfiguration()
+	Config.DataDirectory = dir
+
+	assert.NoError(t, stampRun(&types.NetworkOptionsResponse{
+		Version: &types.Version{NodeVersion: "1.0.2"},
+	}))
+	run := results.CurrentRunMetadata()
+	assert.NotNil(t, run)
+	assert.Equal(t, "1.0.2", run.NodeVersion)
+	assert.Equal(t, Version, run.CLIVersion)
+
+	var written results.RunMetadata
+	assert.NoError(t, utils.LoadAndParse(path.Join(dir, runFile), &written))
+	assert.Equal(t, run, &written)
+
+	// An invocation is only stamped once
+	assert.NoError(t, stampRun(&types.NetworkOptionsResponse{}))
+	assert.Equal(t, run, results.CurrentRunMetadata())
+
+	exportPath := path.Join(dir, "checkpoints.json")
+	assert.NoError(t, stampExport(exportPath))
+
+	var exported results.RunMetadata
+	assert.NoError(t, utils.LoadAndParse(exportPath+exportRunSuffix, &exported))
+	assert.Equal(t, run, &exported)
+}
+
+func TestStampExport(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+	defer results.SetRunMetadata(nil)
+
+	exportPath := path.Join(dir, "graph.dot")
+	assert.NoError(t, stampExport(exportPath))
+
+	run := results.CurrentRunMetadata()
+	assert.NotNil(t, run)
+	assert.Empty(t, run.NodeVersion)
+
+	var exported results.RunMetadata
+	assert.NoError(t, utils.LoadAndParse(exportPath+exportRunSuffix, &exported))
+	assert.Equal(t, run, &exported)
+}
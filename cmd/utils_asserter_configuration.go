@@ -19,6 +19,8 @@ import (
 	"sort"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/utils"
@@ -45,12 +47,19 @@ the configuration file should be saved (in JSON).`,
 
 func runCreateConfigurationCmd(cmd *cobra.Command, args []string) error {
 	// Create a new fetcher
-	newFetcher := fetcher.New(
-		Config.OnlineURL,
-		fetcher.WithRetryElapsedTime(time.Duration(Config.RetryElapsedTime)*time.Second),
-		fetcher.WithTimeout(time.Duration(Config.HTTPTimeout)*time.Second),
+	fetcherOpts := []fetcher.Option{
+		fetcher.WithRetryElapsedTime(time.Duration(Config.RetryElapsedTime) * time.Second),
+		fetcher.WithTimeout(time.Duration(Config.HTTPTimeout) * time.Second),
 		fetcher.WithMaxRetries(Config.MaxRetries),
-	)
+		fetcher.WithClient(processor.NewClient(
+			Config.OnlineURL,
+			time.Duration(Config.HTTPTimeout)*time.Second,
+			fetcher.DefaultMaxConnections,
+			clientOptions,
+		)),
+	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	newFetcher := fetcher.New(Config.OnlineURL, fetcherOpts...)
 
 	// Initialize the fetcher's asserter
 	_, _, fetchErr := newFetcher.InitializeAsserter(Context, Config.Network, Config.ValidationFile)
@@ -0,0 +1,107 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsExportAddressBookCmd = &cobra.Command{
+		Use:   "utils:export-address-book <address book path>",
+		Short: "Export the addresses created by check:construction",
+		Long: `This command exports every address in the check:construction
+database in data_directory (so data_directory must be populated in the
+configuration file) to an address book. Each entry contains the address,
+its curve type, when it was created, the hash of the first confirmed
+transaction that funded it, and its tags (imported with keys:import).
+Private keys are never exported.
+
+If the address book path ends in .csv, the address book is written as
+a CSV (with tags separated by semicolons):
+
+address,curve_type,created_at,first_funding_transaction,tags
+addr1,secp256k1,2020-09-13T12:26:40Z,tx1,faucet;hot
+
+Otherwise, it is written as a JSON array. Subsequent runs (ex: by other
+teams sharing testnet accounts) can choose recipients from the address
+book with construction.sender_pipelines.address_book.
+
+Imported and prefunded addresses have no creation time. The run
+metadata of the export is written to <address book path>.run.json. The
+check:construction database must not be in use while exporting.`,
+		RunE: runExportAddressBookCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runExportAddressBookCmd(cmd *cobra.Command, args []string) error {
+	if len(Config.DataDirectory) == 0 {
+		return errors.New("data_directory must be populated to export addresses")
+	}
+
+	addressBookPath := path.Clean(args[0])
+	dataPath, err := tester.ConstructionDataPath(Config, Config.Network)
+	if err != nil {
+		return fmt.Errorf("%w: cannot create command path", err)
+	}
+
+	lock, err := tester.AcquireDataDirectoryLock(dataPath, false)
+	if err != nil {
+		return fmt.Errorf("%w: unable to lock data directory", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if Config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(Context, dataPath, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize database", err)
+	}
+	defer localStore.Close(Context)
+
+	entries, err := processor.NewAddressBookStorage(localStore).Entries(
+		Context,
+		modules.NewKeyStorage(localStore),
+		processor.NewKeyTagStorage(localStore),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load addresses", err)
+	}
+
+	if err := processor.WriteAddressBook(addressBookPath, entries); err != nil {
+		return fmt.Errorf("%w: unable to write address book", err)
+	}
+
+	if err := stampExport(addressBookPath); err != nil {
+		return err
+	}
+
+	color.Green("Exported %d addresses to %s", len(entries), addressBookPath)
+	return nil
+}
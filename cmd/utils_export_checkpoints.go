@@ -0,0 +1,95 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exportCheckpointsArgs = 2
+)
+
+var (
+	utilsExportCheckpointsCmd = &cobra.Command{
+		Use:   "utils:export-checkpoints",
+		Short: "Export the headers of validated blocks as checkpoints",
+		Long: `This command exports the hash chain (index, hash, parent hash,
+and timestamp) of all blocks stored by check:data as newline-delimited
+JSON. Downstream tools can use this file as a set of trusted checkpoints
+and verify it independently against the node. Pruned blocks are not
+included. The run metadata of the export is written to
+<checkpoint path>.run.json.
+
+The check:data database must not be in use while exporting (and the
+configuration file must be provided if compression was disabled).
+
+The arguments for this command are:
+<database path> <checkpoint path>`,
+		RunE: runExportCheckpointsCmd,
+		Args: cobra.ExactArgs(exportCheckpointsArgs),
+	}
+)
+
+func runExportCheckpointsCmd(cmd *cobra.Command, args []string) error {
+	databasePath := path.Clean(args[0])
+	checkpointPath := path.Clean(args[1])
+
+	lock, err := tester.AcquireDataDirectoryLock(databasePath, false)
+	if err != nil {
+		return fmt.Errorf("%w: unable to lock database", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if Config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(Context, databasePath, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to open database", err)
+	}
+	defer localStore.Close(Context)
+
+	f, err := os.Create(checkpointPath)
+	if err != nil {
+		return fmt.Errorf("%w: unable to create checkpoint file", err)
+	}
+	defer f.Close()
+
+	blockStorage := modules.NewBlockStorage(localStore, Config.SerialBlockWorkers)
+	exported, err := processor.ExportCheckpoints(Context, blockStorage, f)
+	if err != nil {
+		return fmt.Errorf("%w: unable to export checkpoints", err)
+	}
+
+	if err := stampExport(checkpointPath); err != nil {
+		return err
+	}
+
+	color.Green("Exported %d checkpoints to %s", exported, checkpointPath)
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsExportTransferGraphCmd = &cobra.Command{
+		Use:   "utils:export-transfer-graph <graph path>",
+		Short: "Export the transfers confirmed by check:construction as a graph",
+		Long: `This command exports the graph of transfers made by check:construction
+from the database in data_directory (so data_directory must be populated
+in the configuration file). Each node is an address and each edge is a
+confirmed transfer from a sender to a recipient (with its transaction
+hash, block index, value, currency, and the fee paid by its transaction).
+Nodes are annotated with the number of transfers they sent and received,
+so runs that concentrate on a few accounts are easy to spot.
+
+Only transactions broadcast by check:construction are included. Accounts
+that are both debited and credited by a transaction (ex: change outputs)
+are only a sender or a recipient (by their net balance change) and, when
+a transaction has several senders, each recipient has an edge from each
+sender with the full value it received.
+
+If the graph path ends in .dot, the graph is written in the DOT language
+(ex: render it with "dot -Tsvg graph.dot -o graph.svg"). If it ends in
+.graphml, it is written as GraphML (ex: for Gephi or yEd). The run
+metadata of the export is written to <graph path>.run.json.
+
+The check:construction database must not be in use while exporting.`,
+		RunE: runExportTransferGraphCmd,
+		Args: cobra.ExactArgs(1),
+	}
+)
+
+func runExportTransferGraphCmd(cmd *cobra.Command, args []string) error {
+	if len(Config.DataDirectory) == 0 {
+		return errors.New("data_directory must be populated to export the transfer graph")
+	}
+
+	graphPath := path.Clean(args[0])
+	dataPath, err := tester.ConstructionDataPath(Config, Config.Network)
+	if err != nil {
+		return fmt.Errorf("%w: cannot create command path", err)
+	}
+
+	lock, err := tester.AcquireDataDirectoryLock(dataPath, false)
+	if err != nil {
+		return fmt.Errorf("%w: unable to lock data directory", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if Config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(Context, dataPath, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: unable to initialize database", err)
+	}
+	defer localStore.Close(Context)
+
+	graph, err := processor.NewTransferGraphStorage(localStore).Graph(Context)
+	if err != nil {
+		return fmt.Errorf("%w: unable to load transfer graph", err)
+	}
+
+	if err := processor.WriteTransferGraph(graphPath, graph); err != nil {
+		return err
+	}
+
+	if err := stampExport(graphPath); err != nil {
+		return err
+	}
+
+	color.Green(
+		"Exported %d transfers between %d addresses to %s",
+		len(graph.Edges),
+		len(graph.Nodes),
+		graphPath,
+	)
+	return nil
+}
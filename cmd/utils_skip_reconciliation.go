@@ -0,0 +1,140 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	utilsSkipReconciliationCmd = &cobra.Command{
+		Use:   "utils:skip-reconciliation",
+		Short: "Mark a reconciliation failure as known until it expires",
+		Long: `While triaging reconciliation failures, it can be useful to
+continue running check:data without re-failing on issues that are already
+known. This command persists a skip for the reconciliation failures of an
+account and currency (optionally only at a single block) to the file at
+data.reconciliation_skips_file (which must be populated in the configuration
+file).
+
+Until it expires, check:data logs reconciliation failures matching a skip but
+does not consider them failures. Active skips (and the number of failures each
+suppressed) are printed when check:data starts and in its results, and expired
+skips are removed from the file whenever a skip is added.
+
+If --account is not provided, the active skips are printed without adding a
+skip.`,
+		RunE: runUtilsSkipReconciliationCmd,
+		Args: cobra.NoArgs,
+	}
+
+	skipAccount   string
+	skipCurrency  string
+	skipBlock     int64
+	skipReason    string
+	skipExpiresIn time.Duration
+)
+
+func runUtilsSkipReconciliationCmd(cmd *cobra.Command, args []string) error {
+	if Config.Data == nil || len(Config.Data.ReconciliationSkipsFile) == 0 {
+		return errors.New("data.reconciliation_skips_file must be populated")
+	}
+
+	skipsFile := Config.Data.ReconciliationSkipsFile
+	skips, err := processor.LoadReconciliationSkips(skipsFile)
+	if err != nil {
+		return err
+	}
+
+	active, expired := processor.PartitionReconciliationSkips(skips, time.Now())
+	if len(skipAccount) > 0 {
+		skip, err := parseReconciliationSkip()
+		if err != nil {
+			return err
+		}
+
+		active = append(active, skip)
+		if err := processor.SaveReconciliationSkips(skipsFile, active); err != nil {
+			return err
+		}
+
+		color.Green(
+			"Added skip for %s %s (removed %d expired skips)",
+			types.AccountString(skip.Account),
+			skip.Currency.Symbol,
+			len(expired),
+		)
+	}
+
+	color.Cyan("%d active reconciliation skips at %s", len(active), skipsFile)
+	for _, skip := range active {
+		block := "any block"
+		if skip.BlockIndex != nil {
+			block = fmt.Sprintf("block %d", *skip.BlockIndex)
+		}
+
+		fmt.Printf(
+			"%s %s at %s until %s: %s\n",
+			types.AccountString(skip.Account),
+			skip.Currency.Symbol,
+			block,
+			skip.Expires.Format(time.RFC3339),
+			skip.Reason,
+		)
+	}
+
+	return nil
+}
+
+// parseReconciliationSkip creates a *results.ReconciliationSkip
+// from the provided flags.
+func parseReconciliationSkip() (*results.ReconciliationSkip, error) {
+	if skipExpiresIn <= 0 {
+		return nil, errors.New("--expires-in must be positive")
+	}
+
+	skip := &results.ReconciliationSkip{
+		Reason:  skipReason,
+		Expires: time.Now().Add(skipExpiresIn).UTC().Truncate(time.Second),
+	}
+
+	if err := json.Unmarshal([]byte(skipAccount), &skip.Account); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse --account", err)
+	}
+
+	if err := json.Unmarshal([]byte(skipCurrency), &skip.Currency); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse --currency", err)
+	}
+
+	if skipBlock >= 0 {
+		skip.BlockIndex = &skipBlock
+	}
+
+	if err := processor.AssertReconciliationSkip(skip); err != nil {
+		return nil, fmt.Errorf("%w: invalid skip", err)
+	}
+
+	return skip, nil
+}
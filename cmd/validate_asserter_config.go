@@ -15,11 +15,9 @@
 package cmd
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"github.com/coinbase/rosetta-sdk-go/asserter"
-	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"reflect"
@@ -28,11 +26,11 @@ import (
 )
 
 // Common helper across Construction and Data
-// Issues an RPC to fetch /network/options, and extracts the `Allow`
+// Extracts the `Allow` from the /network/options response fetched at startup
 // Reads the JSON file at `asserterConfigurationFile` and loads into a Go object
 // Validates the `Allow`s across both objects match
 func validateNetworkOptionsMatchesAsserterConfiguration(
-	ctx context.Context, f *fetcher.Fetcher, network *types.NetworkIdentifier,
+	networkOptions *types.NetworkOptionsResponse,
 	asserterConfigurationFile string,
 ) error {
 	var asserterConfiguration asserter.Configuration
@@ -40,12 +38,7 @@ func validateNetworkOptionsMatchesAsserterConfiguration(
 		return fmt.Errorf("%w: failure loading / parsing asserter-configuration-file", err)
 	}
 
-	resp, fetchErr := f.NetworkOptions(ctx, network, nil)
-	if fetchErr != nil {
-		return fmt.Errorf("%w: failure getting /network/options", fetchErr.Err)
-	}
-
-	return validateNetworkAndAsserterAllowMatch(resp.Allow, &asserterConfiguration)
+	return validateNetworkAndAsserterAllowMatch(networkOptions.Allow, &asserterConfiguration)
 }
 
 func validateNetworkAndAsserterAllowMatch(
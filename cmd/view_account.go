@@ -0,0 +1,261 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+	"github.com/coinbase/rosetta-cli/pkg/tester"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	viewAccountDetailsCmd = &cobra.Command{
+		Use:   "view:account <address>",
+		Short: "View all balances and coins of an account",
+		Long: `While debugging, it is often useful to inspect everything
+an implementation returns for an account. This command fetches the balance
+of an account in all currencies with /account/balance (at the current block
+or at --block) and, for UTXO chains, its coins with /account/coins (only at
+the current block, as /account/coins does not support historical lookups).
+
+The account can be provided as an address or as a JSON representation of a
+types.AccountIdentifier (to look up a SubAccountIdentifier).
+
+If the data directory contains the database of a previous check:data run,
+each balance is also compared to the balance computed by check:data at the
+block the balance was returned at (an error is returned if they differ).`,
+		RunE: runViewAccountCmd,
+		Args: cobra.ExactArgs(1),
+	}
+
+	// viewAccountBlock is the index of the block to look
+	// up the balance at (-1 for the current block).
+	viewAccountBlock int64
+)
+
+// parseAccountIdentifier returns the *types.AccountIdentifier of
+// arg (JSON if arg is an object, otherwise an address).
+func parseAccountIdentifier(arg string) (*types.AccountIdentifier, error) {
+	account := &types.AccountIdentifier{Address: arg}
+	if strings.HasPrefix(strings.TrimSpace(arg), "{") {
+		account = &types.AccountIdentifier{}
+		if err := json.Unmarshal([]byte(arg), account); err != nil {
+			return nil, fmt.Errorf("%w: unable to unmarshal account %s", err, arg)
+		}
+	}
+
+	if err := asserter.AccountIdentifier(account); err != nil {
+		return nil, fmt.Errorf("%w: invalid account identifier %s", err, types.PrintStruct(account))
+	}
+
+	return account, nil
+}
+
+func runViewAccountCmd(_ *cobra.Command, args []string) error {
+	account, err := parseAccountIdentifier(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Create a new fetcher
+	fetcherOpts := []fetcher.Option{
+		fetcher.WithMaxConnections(Config.MaxOnlineConnections),
+		fetcher.WithRetryElapsedTime(time.Duration(Config.RetryElapsedTime) * time.Second),
+		fetcher.WithTimeout(time.Duration(Config.HTTPTimeout) * time.Second),
+		fetcher.WithMaxRetries(Config.MaxRetries),
+	}
+	if Config.ForceRetry {
+		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
+	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		clientOptions,
+	)))
+
+	newFetcher := fetcher.New(
+		Config.OnlineURL,
+		fetcherOpts...,
+	)
+
+	// Initialize the fetcher's asserter
+	_, _, fetchErr := newFetcher.InitializeAsserter(Context, Config.Network, Config.ValidationFile)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to initialize asserter", fetchErr.Err)
+	}
+
+	_, err = utils.CheckNetworkSupported(Context, Config.Network, newFetcher)
+	if err != nil {
+		return fmt.Errorf("%w: unable to confirm network is supported", err)
+	}
+
+	var lookupBlock *types.PartialBlockIdentifier
+	if viewAccountBlock >= 0 {
+		lookupBlock = &types.PartialBlockIdentifier{Index: &viewAccountBlock}
+	}
+
+	block, amounts, metadata, fetchErr := newFetcher.AccountBalanceRetry(
+		Context,
+		Config.Network,
+		account,
+		lookupBlock,
+		nil,
+	)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to fetch account %s", fetchErr.Err, types.PrintStruct(account))
+	}
+
+	color.Cyan("Account: %s", types.PrintStruct(account))
+	color.Cyan("Balance Fetched At: %s", types.PrintStruct(block))
+	if len(metadata) > 0 {
+		fmt.Printf("Metadata: %s\n", types.PrettyPrintStruct(metadata))
+	}
+
+	var localAmounts []*types.Amount
+	if tester.DataPathExists(Config, Config.Network) {
+		currencies := make([]*types.Currency, len(amounts))
+		for i, amount := range amounts {
+			currencies[i] = amount.Currency
+		}
+
+		localAmounts, err = tester.LocalBalances(
+			Context,
+			Config,
+			Config.Network,
+			account,
+			currencies,
+			block.Index,
+		)
+		if err != nil {
+			color.Yellow("%s: skipping comparison to check:data balances", err.Error())
+		}
+	}
+
+	mismatches := printAccountBalances(amounts, localAmounts)
+
+	// /account/coins does not support historical lookups
+	if lookupBlock == nil {
+		coinsBlock, coins, _, fetchErr := newFetcher.AccountCoins(
+			Context,
+			Config.Network,
+			account,
+			false,
+			nil,
+		)
+		if fetchErr != nil {
+			color.Yellow("%s: skipping /account/coins", fetchErr.Err.Error())
+		} else {
+			color.Cyan("Coins Fetched At: %s", types.PrintStruct(coinsBlock))
+			printAccountCoins(coins)
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf(
+			"%w: %d balances differ from balances computed by check:data",
+			results.ErrReconciliationFailure,
+			mismatches,
+		)
+	}
+
+	return nil
+}
+
+// printAccountBalances prints amounts (and the corresponding
+// localAmounts, if populated) and returns the number of amounts
+// that differ from localAmounts.
+func printAccountBalances(amounts []*types.Amount, localAmounts []*types.Amount) int {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	header := []string{"Currency", "Balance"}
+	if localAmounts != nil {
+		header = append(header, "check:data Balance")
+	}
+	table.SetHeader(header)
+
+	mismatches := 0
+	for i, amount := range amounts {
+		row := []string{
+			types.PrintStruct(amount.Currency),
+			prettyAmount(amount),
+		}
+
+		if localAmounts != nil {
+			local := "not seen"
+			switch localAmount := localAmounts[i]; {
+			case localAmount == nil:
+			case localAmount.Value == amount.Value:
+				local = prettyAmount(localAmount)
+			default:
+				local = color.RedString(prettyAmount(localAmount))
+				mismatches++
+			}
+
+			row = append(row, local)
+		}
+
+		table.Append(row)
+	}
+
+	color.Cyan("Balances:")
+	table.Render()
+
+	return mismatches
+}
+
+// printAccountCoins prints the identifier and amount of coins.
+func printAccountCoins(coins []*types.Coin) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Coin", "Amount"})
+	for _, coin := range coins {
+		table.Append([]string{
+			coin.CoinIdentifier.Identifier,
+			prettyAmount(coin.Amount),
+		})
+	}
+
+	color.Cyan("Coins (%d):", len(coins))
+	table.Render()
+}
+
+// prettyAmount returns amount in the units of its currency
+// (or its raw value if it cannot be parsed).
+func prettyAmount(amount *types.Amount) string {
+	value, err := types.BigInt(amount.Value)
+	if err != nil {
+		return amount.Value
+	}
+
+	return utils.PrettyAmount(value, amount.Currency)
+}
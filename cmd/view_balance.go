@@ -21,6 +21,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -65,6 +67,13 @@ func runViewBalanceCmd(cmd *cobra.Command, args []string) error {
 	if Config.ForceRetry {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		clientOptions,
+	)))
 
 	newFetcher := fetcher.New(
 		Config.OnlineURL,
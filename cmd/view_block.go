@@ -20,6 +20,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/types"
@@ -30,22 +32,93 @@ import (
 
 var (
 	viewBlockCmd = &cobra.Command{
-		Use:   "view:block",
+		Use:   "view:block <index|hash>",
 		Short: "View a block",
 		Long: `While debugging a Data API implementation, it can be very
 useful to inspect block contents. This command allows you to fetch any
-block by index to inspect its contents. It uses the
+block by index or hash to inspect its contents. It uses the
 fetcher (https://github.com/coinbase/rosetta-sdk-go/tree/master/fetcher) package
 to automatically get all transactions in the block and assert the format
 of the block is correct before printing.
 
+With --fetch-transactions, each transaction in the block is also fetched
+individually with /block/transaction, asserted, and compared to the
+transaction returned in /block.
+
+With --json, the block, its balance changes, and any individually fetched
+transactions are printed as JSON (ex: to save for later comparison).
+
 If this command errors, it is likely because the block you are trying to
 fetch is formatted incorrectly.`,
 		RunE: runViewBlockCmd,
 		Args: cobra.ExactArgs(1),
 	}
+
+	// viewBlockFetchTransactions fetches each transaction
+	// in the block with /block/transaction.
+	viewBlockFetchTransactions bool
+
+	// viewBlockJSON prints the block as JSON.
+	viewBlockJSON bool
 )
 
+// viewBlockResult is the JSON output of view:block.
+type viewBlockResult struct {
+	Block               *types.Block            `json:"block"`
+	BalanceChanges      []*parser.BalanceChange `json:"balance_changes"`
+	FetchedTransactions []*types.Transaction    `json:"fetched_transactions,omitempty"`
+	TransactionMismatch string                  `json:"transaction_mismatch,omitempty"`
+}
+
+// parseBlockIdentifier returns the *types.PartialBlockIdentifier
+// of arg (an index if arg is an integer, otherwise a hash).
+func parseBlockIdentifier(arg string) *types.PartialBlockIdentifier {
+	index, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return &types.PartialBlockIdentifier{
+			Hash: &arg,
+		}
+	}
+
+	return &types.PartialBlockIdentifier{
+		Index: &index,
+	}
+}
+
+// fetchBlockTransactions fetches each transaction in block
+// with /block/transaction and asserts it.
+func fetchBlockTransactions(
+	f *fetcher.Fetcher,
+	block *types.Block,
+) ([]*types.Transaction, error) {
+	identifiers := make([]*types.TransactionIdentifier, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		identifiers[i] = tx.TransactionIdentifier
+	}
+
+	fetched, fetchErr := f.UnsafeTransactions(
+		Context,
+		Config.Network,
+		block.BlockIdentifier,
+		identifiers,
+	)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to fetch transactions", fetchErr.Err)
+	}
+
+	for _, tx := range fetched {
+		if err := f.Asserter.Transaction(tx); err != nil {
+			return nil, fmt.Errorf(
+				"%w: transaction %s is invalid",
+				err,
+				tx.TransactionIdentifier.Hash,
+			)
+		}
+	}
+
+	return fetched, nil
+}
+
 func printChanges(balanceChanges []*parser.BalanceChange) error {
 	for _, balanceChange := range balanceChanges {
 		parsedDiff, err := types.BigInt(balanceChange.Difference)
@@ -68,11 +141,6 @@ func printChanges(balanceChanges []*parser.BalanceChange) error {
 }
 
 func runViewBlockCmd(_ *cobra.Command, args []string) error {
-	index, err := strconv.ParseInt(args[0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("%w: unable to parse index %s", err, args[0])
-	}
-
 	// Create a new fetcher
 	fetcherOpts := []fetcher.Option{
 		fetcher.WithMaxConnections(Config.MaxOnlineConnections),
@@ -83,6 +151,13 @@ func runViewBlockCmd(_ *cobra.Command, args []string) error {
 	if Config.ForceRetry {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		clientOptions,
+	)))
 
 	newFetcher := fetcher.New(
 		Config.OnlineURL,
@@ -99,7 +174,7 @@ func runViewBlockCmd(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("%w: unable to initialize asserter", fetchErr.Err)
 	}
 
-	_, err = utils.CheckNetworkSupported(Context, Config.Network, newFetcher)
+	_, err := utils.CheckNetworkSupported(Context, Config.Network, newFetcher)
 	if err != nil {
 		return fmt.Errorf("%w: unable to confirm network is supported", err)
 	}
@@ -116,9 +191,7 @@ func runViewBlockCmd(_ *cobra.Command, args []string) error {
 	block, fetchErr := newFetcher.BlockRetry(
 		Context,
 		Config.Network,
-		&types.PartialBlockIdentifier{
-			Index: &index,
-		},
+		parseBlockIdentifier(args[0]),
 	)
 	if fetchErr != nil {
 		return fmt.Errorf("%w: unable to fetch block", fetchErr.Err)
@@ -128,21 +201,48 @@ func runViewBlockCmd(_ *cobra.Command, args []string) error {
 		return errors.New("block not found, it might be omitted")
 	}
 
-	fmt.Printf("\n")
-	if !OnlyChanges {
-		color.Cyan("Current Block:")
-		fmt.Println(types.PrettyPrintStruct(block))
+	var fetchedTransactions []*types.Transaction
+	var mismatchErr error
+	if viewBlockFetchTransactions {
+		fetchedTransactions, err = fetchBlockTransactions(newFetcher, block)
+		if err != nil {
+			return err
+		}
+
+		mismatchErr = processor.CompareBlockTransactions(block.Transactions, fetchedTransactions)
 	}
 
-	// Print out all balance changes in a given block. This does NOT exempt
+	// Calculate all balance changes in a given block. This does NOT exempt
 	// any operations/accounts from parsing.
-	color.Cyan("Balance Changes:")
 	p := parser.New(newFetcher.Asserter, func(*types.Operation) bool { return false }, nil)
 	balanceChanges, err := p.BalanceChanges(Context, block, false)
 	if err != nil {
 		return fmt.Errorf("%w: unable to calculate balance changes", err)
 	}
 
+	if viewBlockJSON {
+		result := &viewBlockResult{
+			Block:               block,
+			BalanceChanges:      balanceChanges,
+			FetchedTransactions: fetchedTransactions,
+		}
+		if mismatchErr != nil {
+			result.TransactionMismatch = mismatchErr.Error()
+		}
+		fmt.Println(types.PrettyPrintStruct(result))
+
+		return mismatchErr
+	}
+
+	fmt.Printf("\n")
+	if !OnlyChanges {
+		color.Cyan("Current Block:")
+		fmt.Println(types.PrettyPrintStruct(block))
+	}
+
+	// Print out all balance changes in a given block.
+	color.Cyan("Balance Changes:")
+
 	fmt.Println("Cummulative:", block.BlockIdentifier.Hash)
 
 	if err := printChanges(balanceChanges); err != nil {
@@ -185,5 +285,17 @@ func runViewBlockCmd(_ *cobra.Command, args []string) error {
 		}
 	}
 
-	return nil
+	if viewBlockFetchTransactions {
+		color.Cyan("Fetched Transactions:")
+		if mismatchErr != nil {
+			color.Red(mismatchErr.Error())
+		} else {
+			fmt.Printf(
+				"All %d transactions match /block/transaction\n",
+				len(block.Transactions),
+			)
+		}
+	}
+
+	return mismatchErr
 }
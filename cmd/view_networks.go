@@ -20,6 +20,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/coinbase/rosetta-cli/pkg/processor"
+
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/fatih/color"
@@ -50,6 +52,13 @@ func runViewNetworksCmd(cmd *cobra.Command, args []string) error {
 	if Config.ForceRetry {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(Config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewClient(
+		Config.OnlineURL,
+		time.Duration(Config.HTTPTimeout)*time.Second,
+		Config.MaxOnlineConnections,
+		clientOptions,
+	)))
 
 	f := fetcher.New(
 		Config.OnlineURL,
@@ -0,0 +1,177 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// commentIndent is the indent of each level of a
+	// configuration file written by MarshalCommented.
+	commentIndent = " "
+)
+
+// configurationComments are the comments written by MarshalCommented
+// above each field (keyed by path). Fields without a comment (and all
+// fields in arrays) are written without one.
+var configurationComments = map[string]string{
+	"network":                                "Network to test (as returned by /network/list).",
+	"online_url":                             "URL of the online Rosetta implementation.",
+	"data_directory":                         "Folder used to store logs and data (a temporary directory is used if empty).",
+	"http_timeout":                           "Seconds to wait for a response to any HTTP request.",
+	"max_retries":                            "Number of times to retry a failed HTTP request.",
+	"retry_elapsed_time":                     "Total seconds to spend retrying an HTTP request (0 to only use max_retries).",
+	"max_online_connections":                 "Maximum number of open connections to the online implementation.",
+	"max_sync_concurrency":                   "Maximum number of blocks fetched concurrently while syncing.",
+	"tip_delay":                              "Seconds behind the current time a block may be and still be considered at tip.",
+	"max_reorg_depth":                        "Maximum depth of a reorg (older blocks are pruned).",
+	"log_configuration":                      "Print the loaded configuration on startup.",
+	"compression_disabled":                   "Store data without compression (faster, but uses more disk).",
+	"memory_limit_disabled":                  "Use much more memory (10s of GBs) for much faster storage.",
+	"error_stack_trace_disabled":             "Omit stack traces from errors.",
+	"polling":                                "How often the implementation is polled for new blocks once at tip.",
+	"polling.interval_ms":                    "Milliseconds between polls.",
+	"construction":                           "Settings for check:construction (null if check:construction is not run).",
+	"decimals_probe":                         "Checks on startup that amounts in recent blocks match the decimals of these currencies.",
+	"decimals_probe.blocks":                  "Number of most recent blocks fetched.",
+	"decimals_probe.currencies":              "Currencies (with the expected decimals) of the implementation.",
+	"data":                                   "Settings for check:data.",
+	"data.active_reconciliation_concurrency": "Concurrent reconciliations of accounts changed in synced blocks.",
+	"data.inactive_reconciliation_concurrency":  "Concurrent reconciliations of accounts not changed recently.",
+	"data.inactive_reconciliation_frequency":    "Blocks to wait before reconciling an inactive account again.",
+	"data.log_blocks":                           "Log each synced block.",
+	"data.log_transactions":                     "Log each synced transaction.",
+	"data.log_balance_changes":                  "Log each balance change.",
+	"data.log_reconciliations":                  "Log each reconciliation.",
+	"data.ignore_reconciliation_error":          "Continue syncing when a reconciliation fails.",
+	"data.exempt_accounts":                      "Path of a JSON file of accounts exempt from balance tracking.",
+	"data.bootstrap_balances":                   "Path of a JSON file of genesis balances.",
+	"data.interesting_accounts":                 "Path of a JSON file of accounts to check on each block.",
+	"data.reconciliation_disabled":              "Skip reconciling balances.",
+	"data.reconciliation_drain_disabled":        "Stop without draining pending reconciliations.",
+	"data.inactive_discrepancy_search_disabled": "Skip searching for the block where an inactive reconciliation first failed.",
+	"data.balance_tracking_disabled":            "Skip tracking balances (also disables reconciliation).",
+	"data.coin_tracking_disabled":               "Skip tracking coins (for account-based blockchains).",
+	"data.status_port":                          "Port of the status server (queried for progress while running).",
+	"data.results_output_file":                  "Path to write the results of check:data to.",
+	"data.pruning_disabled":                     "Keep all blocks in storage.",
+	"data.initial_balance_fetch_disabled":       "Skip fetching the balance of each account when first seen.",
+	"data.verify_currency_filter":               "Check that /account/balance respects the currencies filter.",
+	"data.verify_account_metadata":              "Retry failed balance lookups of accounts with metadata without the metadata.",
+	"data.validate_operation_ordering":          "Check that the operations in each block have a deterministic application order.",
+	"data.custom_counters":                      "Counters of synced operations (one per supported operation type).",
+}
+
+// MarshalCommented serializes config as indented JSON with a
+// comment (from configurationComments) above each known field.
+// Files written by MarshalCommented can be loaded with
+// LoadConfiguration.
+func MarshalCommented(config *Configuration) ([]byte, error) {
+	b, err := json.MarshalIndent(config, "", commentIndent)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to marshal configuration", err)
+	}
+
+	// The path of each line is tracked by its indentation (the
+	// fields of the root object are at level 1). Arrays and array
+	// elements are tracked as "[]" so that inArray can skip them.
+	var (
+		out     bytes.Buffer
+		scanner = bufio.NewScanner(bytes.NewReader(b))
+		stack   []string
+	)
+	out.WriteString("// rosetta-cli configuration file. Lines starting with // are comments.\n")
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(trimmed)]
+		level := len(indent) / len(commentIndent)
+		if level > 0 && len(stack) >= level {
+			stack = stack[:level-1]
+		}
+
+		key := ""
+		if i := strings.Index(trimmed, "\": "); strings.HasPrefix(trimmed, "\"") && i > 0 {
+			key = trimmed[1:i]
+		}
+
+		if len(key) > 0 && !inArray(stack) {
+			comment := configurationComments[strings.Join(append(stack, key), ".")]
+			if len(comment) > 0 {
+				fmt.Fprintf(&out, "%s// %s\n", indent, comment)
+			}
+		}
+		out.WriteString(line + "\n")
+
+		switch {
+		case level == 0:
+		case strings.HasSuffix(trimmed, "["):
+			stack = append(stack, "[]")
+		case strings.HasSuffix(trimmed, "{") && len(key) > 0:
+			stack = append(stack, key)
+		case strings.HasSuffix(trimmed, "{"):
+			stack = append(stack, "[]")
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// inArray returns a boolean indicating if the
+// current path is inside of an array.
+func inArray(stack []string) bool {
+	for _, key := range stack {
+		if key == "[]" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StripComments replaces each // comment (outside of a string)
+// in data with spaces so that data can be parsed as JSON without
+// changing the position of any other character.
+func StripComments(data []byte) []byte {
+	stripped := make([]byte, len(data))
+	copy(stripped, data)
+
+	inString := false
+	escaped := false
+	for i := 0; i < len(stripped); i++ {
+		c := stripped[i]
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && c == '/' && i+1 < len(stripped) && stripped[i+1] == '/':
+			for ; i < len(stripped) && stripped[i] != '\n'; i++ {
+				if stripped[i] != '\r' {
+					stripped[i] = ' '
+				}
+			}
+		}
+	}
+
+	return stripped
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripComments(t *testing.T) {
+	var tests = map[string]struct {
+		data     string
+		expected string
+	}{
+		"no comments": {
+			data:     `{"online_url": "http://localhost:8080"}`,
+			expected: `{"online_url": "http://localhost:8080"}`,
+		},
+		"comment lines": {
+			data:     "// header\n{\n // field\n \"http_timeout\": 10 // trailing\n}",
+			expected: "         \n{\n         \n \"http_timeout\": 10            \n}",
+		},
+		"comment in string": {
+			data:     `{"online_url": "http://localhost:8080"} // "quoted"`,
+			expected: `{"online_url": "http://localhost:8080"}` + strings.Repeat(" ", 12),
+		},
+		"escaped quote": {
+			data:     `{"data_directory": "a\"//b"}`,
+			expected: `{"data_directory": "a\"//b"}`,
+		},
+		"carriage return": {
+			data:     "// header\r\n{}",
+			expected: "         \r\n{}",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, string(StripComments([]byte(test.data))))
+		})
+	}
+}
+
+func TestMarshalCommented(t *testing.T) {
+	config := DefaultConfiguration()
+	config.Data.CustomCounters = []*CustomCounter{
+		{Name: "transfer_operations", Type: "Transfer"},
+	}
+	config.DecimalsProbe = &DecimalsProbeConfiguration{
+		Blocks:     10,
+		Currencies: []*types.Currency{{Symbol: "BTC", Decimals: 8}},
+	}
+
+	b, err := MarshalCommented(config)
+	assert.NoError(t, err)
+
+	commented := string(b)
+	assert.Contains(t, commented, "\n // URL of the online Rosetta implementation.\n \"online_url\"")
+	assert.Contains(t, commented, "\n  // Milliseconds between polls.\n  \"interval_ms\"")
+	assert.Contains(t, commented, "\n  // Number of most recent blocks fetched.\n  \"blocks\"")
+
+	// Fields in arrays are not commented
+	assert.Contains(t, commented, "\n   {\n    \"name\": \"transfer_operations\"")
+	assert.Contains(t, commented, "\n   {\n    \"symbol\": \"BTC\"")
+
+	dir, err := ioutil.TempDir("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filePath := path.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(filePath, b, 0600))
+
+	loaded, err := LoadConfiguration(context.Background(), filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, config.Network, loaded.Network)
+	assert.Equal(t, config.Data.CustomCounters, loaded.Data.CustomCounters)
+	assert.Equal(t, config.DecimalsProbe, loaded.DecimalsProbe)
+}
@@ -15,18 +15,27 @@
 package configuration
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"math/big"
+	"net/http"
+	"net/url"
 	"path"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/coinbase/rosetta-sdk-go/asserter"
 	"github.com/coinbase/rosetta-sdk-go/constructor/dsl"
 	"github.com/coinbase/rosetta-sdk-go/constructor/job"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
 	"github.com/coinbase/rosetta-sdk-go/utils"
 	"github.com/fatih/color"
@@ -56,7 +65,10 @@ func DefaultConfiguration() *Configuration {
 		MaxSyncConcurrency:   DefaultMaxSyncConcurrency,
 		TipDelay:             DefaultTipDelay,
 		MaxReorgDepth:        DefaultMaxReorgDepth,
-		Data:                 DefaultDataConfiguration(),
+		Polling: &PollingConfiguration{
+			IntervalMS: DefaultPollingIntervalMS,
+		},
+		Data: DefaultDataConfiguration(),
 	}
 }
 
@@ -91,6 +103,53 @@ func populateConstructionMissingFields(
 		constructionConfig.StatusPort = DefaultStatusPort
 	}
 
+	if constructionConfig.RemoteSigner != nil && constructionConfig.RemoteSigner.Timeout == 0 {
+		constructionConfig.RemoteSigner.Timeout = DefaultTimeout
+	}
+
+	if constructionConfig.MempoolVerification != nil &&
+		constructionConfig.MempoolVerification.Timeout == 0 {
+		constructionConfig.MempoolVerification.Timeout = DefaultMempoolTimeout
+	}
+
+	if constructionConfig.CPFP != nil && constructionConfig.CPFP.Timeout == 0 {
+		constructionConfig.CPFP.Timeout = DefaultCPFPTimeout
+	}
+
+	if constructionConfig.FeeEstimation != nil &&
+		constructionConfig.FeeEstimation.Percentile == 0 {
+		constructionConfig.FeeEstimation.Percentile = DefaultFeeEstimationPercentile
+	}
+
+	if pipelines := constructionConfig.SenderPipelines; pipelines != nil &&
+		len(pipelines.SenderSelection) == 0 {
+		pipelines.SenderSelection = HighestBalanceSenderSelection
+	}
+
+	if backend := constructionConfig.BroadcastBackend; backend != nil {
+		if len(backend.Type) == 0 {
+			backend.Type = RosettaBroadcastBackend
+		}
+
+		if backend.Timeout == 0 {
+			backend.Timeout = DefaultTimeout
+		}
+	}
+
+	if airGap := constructionConfig.AirGap; airGap != nil {
+		if len(airGap.Encoding) == 0 {
+			airGap.Encoding = JSONAirGapEncoding
+		}
+
+		if airGap.Timeout == 0 {
+			airGap.Timeout = DefaultAirGapTimeout
+		}
+	}
+
+	if hdWallet := constructionConfig.HDWallet; hdWallet != nil && len(hdWallet.Path) == 0 {
+		hdWallet.Path = DefaultHDWalletPath
+	}
+
 	return constructionConfig
 }
 
@@ -115,6 +174,32 @@ func populateDataMissingFields(dataConfig *DataConfiguration) *DataConfiguration
 		dataConfig.StatusPort = DefaultStatusPort
 	}
 
+	if explorer := dataConfig.Explorer; explorer != nil {
+		if explorer.Interval == 0 {
+			explorer.Interval = DefaultExplorerInterval
+		}
+
+		if explorer.Timeout == 0 {
+			explorer.Timeout = DefaultTimeout
+		}
+	}
+
+	if historical := dataConfig.HistoricalReconciliation; historical != nil {
+		if historical.Interval == 0 {
+			historical.Interval = DefaultHistoricalReconciliationInterval
+		}
+
+		if historical.MinDepth == 0 {
+			historical.MinDepth = DefaultHistoricalReconciliationMinDepth
+		}
+	}
+
+	if commitment := dataConfig.StateCommitment; commitment != nil {
+		if commitment.Interval == 0 {
+			commitment.Interval = DefaultStateCommitmentInterval
+		}
+	}
+
 	return dataConfig
 }
 
@@ -164,6 +249,24 @@ func populateMissingFields(config *Configuration) *Configuration {
 		config.SerialBlockWorkers = numCPU
 	}
 
+	if config.Polling == nil {
+		config.Polling = &PollingConfiguration{
+			IntervalMS: DefaultPollingIntervalMS,
+		}
+	}
+
+	if config.Polling.IntervalMS == 0 {
+		config.Polling.IntervalMS = DefaultPollingIntervalMS
+	}
+
+	if config.Retry != nil {
+		config.Retry = populateRetryMissingFields(config.Retry)
+	}
+
+	if config.RateLimit != nil && config.RateLimit.Burst == 0 {
+		config.RateLimit.Burst = DefaultRateLimitBurst
+	}
+
 	if len(strings.TrimSpace(config.ValidationFile)) == 0 {
 		config.ValidationFile = ""
 	}
@@ -174,6 +277,26 @@ func populateMissingFields(config *Configuration) *Configuration {
 	return config
 }
 
+func populateRetryMissingFields(retry *RetryConfiguration) *RetryConfiguration {
+	if retry.IntervalMS == 0 {
+		retry.IntervalMS = DefaultRetryIntervalMS
+	}
+
+	if retry.Multiplier == 0 {
+		retry.Multiplier = DefaultRetryMultiplier
+	}
+
+	if retry.MaxIntervalMS == 0 {
+		retry.MaxIntervalMS = DefaultRetryMaxIntervalMS
+	}
+
+	if retry.RetriableStatusCodes == nil {
+		retry.RetriableStatusCodes = append([]int{}, DefaultRetriableStatusCodes...)
+	}
+
+	return retry
+}
+
 func assertConstructionConfiguration(ctx context.Context, config *ConstructionConfiguration) error {
 	if config == nil {
 		return nil
@@ -211,6 +334,176 @@ func assertConstructionConfiguration(ctx context.Context, config *ConstructionCo
 		}
 	}
 
+	if err := assertConstructionEndConditions(config); err != nil {
+		return fmt.Errorf("%w: invalid end conditions", err)
+	}
+
+	if config.MultisigThreshold < 0 {
+		return fmt.Errorf("multisig threshold %d must be >= 0", config.MultisigThreshold)
+	}
+
+	if config.MaxAddressReuse < 0 {
+		return fmt.Errorf("max address reuse %d must be >= 0", config.MaxAddressReuse)
+	}
+
+	if config.FeeEstimation != nil {
+		if config.FeeEstimation.Window <= 0 {
+			return fmt.Errorf("fee estimation window %d must be > 0", config.FeeEstimation.Window)
+		}
+
+		if config.FeeEstimation.Percentile <= 0 || config.FeeEstimation.Percentile > 1 {
+			return fmt.Errorf(
+				"fee estimation percentile %f must be in (0, 1]",
+				config.FeeEstimation.Percentile,
+			)
+		}
+
+		maxFee, err := types.BigInt(config.FeeEstimation.MaxFee)
+		if err != nil {
+			return fmt.Errorf("%w: invalid fee estimation max fee", err)
+		}
+
+		if maxFee.Sign() < 0 {
+			return fmt.Errorf("fee estimation max fee %s must be >= 0", maxFee.String())
+		}
+	}
+
+	switch config.CoinSelection {
+	case "", LargestFirstCoinSelection, SmallestFirstCoinSelection, RandomCoinSelection:
+	default:
+		return fmt.Errorf("coin selection strategy %s is not supported", config.CoinSelection)
+	}
+
+	for _, step := range config.SkippedSteps {
+		if step != ParseConstructionStep {
+			return fmt.Errorf("construction step %s cannot be skipped", step)
+		}
+	}
+
+	if err := assertStepDelays(config.StepDelays); err != nil {
+		return fmt.Errorf("%w: invalid step delays", err)
+	}
+
+	if config.NonceTracking != nil {
+		if len(config.NonceTracking.MetadataKey) == 0 {
+			return errors.New("nonce tracking metadata key must be populated")
+		}
+
+		if config.NonceTracking.MaxPending <= 0 {
+			return fmt.Errorf(
+				"nonce tracking max pending %d must be > 0",
+				config.NonceTracking.MaxPending,
+			)
+		}
+	}
+
+	if config.LoadTest != nil {
+		if config.LoadTest.TargetTPS <= 0 {
+			return fmt.Errorf("load test target tps %f must be > 0", config.LoadTest.TargetTPS)
+		}
+
+		if config.LoadTest.Concurrency <= 0 {
+			return fmt.Errorf(
+				"load test concurrency %d must be > 0",
+				config.LoadTest.Concurrency,
+			)
+		}
+
+		if config.LoadTest.Duration == 0 {
+			return errors.New("load test duration must be > 0")
+		}
+
+		// The coordinator creates transactions one at a time, so
+		// load is driven by the sender pipeline workers.
+		if config.SenderPipelines == nil {
+			return errors.New("load test requires sender pipelines")
+		}
+	}
+
+	if err := assertInjectedOperations(config.InjectedOperations); err != nil {
+		return fmt.Errorf("%w: invalid injected operations", err)
+	}
+
+	if config.TransactionAssertions != nil {
+		if err := assertTransactionAssertions(config.TransactionAssertions); err != nil {
+			return fmt.Errorf("%w: invalid transaction assertions", err)
+		}
+	}
+
+	if config.BalanceVerification != nil {
+		if err := assertBalanceVerification(config.BalanceVerification); err != nil {
+			return fmt.Errorf("%w: invalid balance verification", err)
+		}
+	}
+
+	if config.DustConsolidation != nil {
+		if err := assertDustConsolidation(config.DustConsolidation); err != nil {
+			return fmt.Errorf("%w: invalid dust consolidation", err)
+		}
+	}
+
+	if config.MultiSenderSpend != nil {
+		if err := assertMultiSenderSpend(config.MultiSenderSpend); err != nil {
+			return fmt.Errorf("%w: invalid multi-sender spend", err)
+		}
+	}
+
+	if config.CPFP != nil {
+		if err := assertCPFP(config.CPFP); err != nil {
+			return fmt.Errorf("%w: invalid cpfp", err)
+		}
+	}
+
+	if config.Replay != nil {
+		if err := assertReplay(config.Replay); err != nil {
+			return fmt.Errorf("%w: invalid replay", err)
+		}
+	}
+
+	if config.SenderPipelines != nil {
+		if err := assertSenderPipelines(config.SenderPipelines); err != nil {
+			return fmt.Errorf("%w: invalid sender pipelines", err)
+		}
+	}
+
+	if err := assertOperationMatching(config.OperationMatching); err != nil {
+		return fmt.Errorf("%w: invalid operation matching", err)
+	}
+
+	if config.RemoteSigner != nil {
+		if err := assertRemoteSigner(config); err != nil {
+			return fmt.Errorf("%w: invalid remote signer", err)
+		}
+	}
+
+	if config.BroadcastBackend != nil {
+		if err := assertBroadcastBackend(config.BroadcastBackend); err != nil {
+			return fmt.Errorf("%w: invalid broadcast backend", err)
+		}
+	}
+
+	if config.AirGap != nil {
+		if err := assertAirGap(config); err != nil {
+			return fmt.Errorf("%w: invalid air gap", err)
+		}
+	}
+
+	if config.HDWallet != nil {
+		if err := assertHDWallet(config); err != nil {
+			return fmt.Errorf("%w: invalid hd wallet", err)
+		}
+	}
+
+	if config.AddressPool != nil {
+		if config.AddressPool.Size <= 0 {
+			return fmt.Errorf("address pool size %d must be > 0", config.AddressPool.Size)
+		}
+
+		if err := assertAddressPoolCurveTypes(config.AddressPool); err != nil {
+			return err
+		}
+	}
+
 	for _, account := range config.PrefundedAccounts {
 		// Checks that privkey is hex encoded
 		_, err := hex.DecodeString(account.PrivateKeyHex)
@@ -242,15 +535,255 @@ func assertConstructionConfiguration(ctx context.Context, config *ConstructionCo
 	return nil
 }
 
+func assertBalanceRules(rules []*BalanceRule) error {
+	operationTypes := map[string]struct{}{}
+	for _, rule := range rules {
+		if len(rule.OperationType) == 0 {
+			return errors.New("operation type must be populated")
+		}
+
+		if _, ok := operationTypes[rule.OperationType]; ok {
+			return fmt.Errorf("duplicate rule for operation type %s", rule.OperationType)
+		}
+		operationTypes[rule.OperationType] = struct{}{}
+
+		switch rule.Action {
+		case AddBalanceRule, SubtractBalanceRule, IgnoreBalanceRule:
+			if len(rule.Expression) > 0 {
+				return fmt.Errorf(
+					"expression cannot be populated for %s action of operation type %s",
+					rule.Action,
+					rule.OperationType,
+				)
+			}
+		case ExpressionBalanceRule:
+			if len(rule.Expression) == 0 {
+				return fmt.Errorf(
+					"expression must be populated for operation type %s",
+					rule.OperationType,
+				)
+			}
+		default:
+			return fmt.Errorf(
+				"action %s for operation type %s is not supported",
+				rule.Action,
+				rule.OperationType,
+			)
+		}
+	}
+
+	return nil
+}
+
+func assertExplorer(explorer *ExplorerConfiguration) error {
+	switch explorer.Type {
+	case EsploraExplorer:
+	default:
+		return fmt.Errorf("explorer type %s is not supported", explorer.Type)
+	}
+
+	if _, err := url.ParseRequestURI(explorer.URL); err != nil {
+		return fmt.Errorf("%w: invalid url %s", err, explorer.URL)
+	}
+
+	if err := asserter.Currency(explorer.Currency); err != nil {
+		return fmt.Errorf("%w: invalid currency", err)
+	}
+
+	return nil
+}
+
+func assertHistoricalReconciliation(config *DataConfiguration) error {
+	if config.HistoricalReconciliation == nil {
+		return nil
+	}
+
+	if config.HistoricalReconciliation.MinDepth < 0 {
+		return fmt.Errorf(
+			"min depth %d must be >= 0",
+			config.HistoricalReconciliation.MinDepth,
+		)
+	}
+
+	if config.HistoricalBalanceDisabled != nil && *config.HistoricalBalanceDisabled {
+		return errors.New("historical balance lookup must be enabled")
+	}
+
+	if config.BalanceTrackingDisabled {
+		return errors.New("balance tracking must be enabled")
+	}
+
+	return nil
+}
+
+func assertStateCommitment(config *DataConfiguration) error {
+	if config.StateCommitment == nil {
+		return nil
+	}
+
+	switch config.StateCommitment.Type {
+	case SparseMerkleStateCommitment:
+	default:
+		return fmt.Errorf("state commitment type %s is not supported", config.StateCommitment.Type)
+	}
+
+	if len(config.StateCommitment.MetadataKey) == 0 {
+		return errors.New("metadata key must be populated")
+	}
+
+	if config.StateCommitment.Interval <= 0 {
+		return fmt.Errorf("interval %d must be > 0", config.StateCommitment.Interval)
+	}
+
+	if config.BalanceTrackingDisabled {
+		return errors.New("balance tracking must be enabled")
+	}
+
+	return nil
+}
+
+func assertCheckpointSync(config *DataConfiguration) error {
+	if config.CheckpointSync == nil {
+		return nil
+	}
+
+	if len(config.CheckpointSync.File) == 0 {
+		return errors.New("file must be populated")
+	}
+
+	if config.StartIndex != nil {
+		return errors.New("start index cannot be populated (syncing starts at the last checkpoint)")
+	}
+
+	if config.PartialSync != nil {
+		return errors.New("partial sync cannot be used")
+	}
+
+	if config.HistoricalBalanceDisabled != nil && *config.HistoricalBalanceDisabled {
+		return errors.New("historical balance lookup must be enabled")
+	}
+
+	if config.InitialBalanceFetchDisabled {
+		return errors.New("initial balance fetch must be enabled")
+	}
+
+	if len(config.BootstrapBalances) > 0 {
+		return errors.New("bootstrap balances cannot be used")
+	}
+
+	return nil
+}
+
+func assertPartialSync(config *DataConfiguration) error {
+	if config.PartialSync == nil {
+		return nil
+	}
+
+	if config.StartIndex == nil {
+		return errors.New("start index must be populated")
+	}
+
+	if config.EndConditions == nil || config.EndConditions.Index == nil {
+		return errors.New("end index must be populated")
+	}
+
+	if *config.EndConditions.Index < *config.StartIndex {
+		return fmt.Errorf(
+			"end index %d cannot be less than start index %d",
+			*config.EndConditions.Index,
+			*config.StartIndex,
+		)
+	}
+
+	if config.HistoricalBalanceDisabled != nil && *config.HistoricalBalanceDisabled {
+		return errors.New("historical balance lookup must be enabled")
+	}
+
+	if config.InitialBalanceFetchDisabled {
+		return errors.New("initial balance fetch must be enabled")
+	}
+
+	if len(config.BootstrapBalances) > 0 {
+		return errors.New("bootstrap balances cannot be used")
+	}
+
+	return nil
+}
+
 func assertDataConfiguration(config *DataConfiguration) error { // nolint:gocognit
 	if config.StartIndex != nil && *config.StartIndex < 0 {
 		return fmt.Errorf("start index %d cannot be negative", *config.StartIndex)
 	}
 
+	for oldType, newType := range config.OperationTypeAliases {
+		if len(oldType) == 0 || len(newType) == 0 || oldType == newType {
+			return fmt.Errorf("invalid operation type alias %s -> %s", oldType, newType)
+		}
+	}
+
+	if err := assertBalanceRules(config.BalanceRules); err != nil {
+		return fmt.Errorf("%w: invalid balance rules", err)
+	}
+
+	switch config.Strictness {
+	case "", StrictStrictness, StandardStrictness, LenientStrictness:
+	default:
+		return fmt.Errorf("strictness level %s is not supported", config.Strictness)
+	}
+
+	if config.EscalationThreshold < 0 {
+		return fmt.Errorf("escalation threshold %d cannot be negative", config.EscalationThreshold)
+	}
+
+	if config.ReconciliationMaxHeadLag != nil && *config.ReconciliationMaxHeadLag < 0 {
+		return fmt.Errorf(
+			"reconciliation max head lag %d cannot be negative",
+			*config.ReconciliationMaxHeadLag,
+		)
+	}
+
+	if config.ActiveReconciliationRateLimit < 0 || config.InactiveReconciliationRateLimit < 0 {
+		return errors.New("reconciliation rate limits cannot be negative")
+	}
+
+	if config.BlockTransactionComparisonFrequency != nil &&
+		*config.BlockTransactionComparisonFrequency <= 0 {
+		return fmt.Errorf(
+			"block transaction comparison frequency %d must be > 0",
+			*config.BlockTransactionComparisonFrequency,
+		)
+	}
+
 	if !config.ReconciliationDisabled && config.BalanceTrackingDisabled {
 		return errors.New("balance tracking must be enabled to perform reconciliation")
 	}
 
+	if err := assertCustomCounters(config.CustomCounters); err != nil {
+		return fmt.Errorf("%w: invalid custom counters", err)
+	}
+
+	if err := assertPartialSync(config); err != nil {
+		return fmt.Errorf("%w: invalid partial sync", err)
+	}
+
+	if err := assertCheckpointSync(config); err != nil {
+		return fmt.Errorf("%w: invalid checkpoint sync", err)
+	}
+
+	if config.Explorer != nil {
+		if err := assertExplorer(config.Explorer); err != nil {
+			return fmt.Errorf("%w: invalid explorer", err)
+		}
+	}
+
+	if err := assertHistoricalReconciliation(config); err != nil {
+		return fmt.Errorf("%w: invalid historical reconciliation", err)
+	}
+
+	if err := assertStateCommitment(config); err != nil {
+		return fmt.Errorf("%w: invalid state commitment", err)
+	}
+
 	if config.EndConditions == nil {
 		return nil
 	}
@@ -302,6 +835,602 @@ func assertDataConfiguration(config *DataConfiguration) error { // nolint:gocogn
 	return nil
 }
 
+func assertAddressPoolCurveTypes(pool *AddressPoolConfiguration) error {
+	if len(pool.CurveTypes) == 0 {
+		if err := asserter.CurveType(pool.CurveType); err != nil {
+			return fmt.Errorf("%w: invalid CurveType for address pool", err)
+		}
+
+		return nil
+	}
+
+	if len(pool.CurveType) > 0 {
+		return errors.New("address pool curve type and curve types cannot both be populated")
+	}
+
+	for _, curve := range pool.CurveTypes {
+		if err := asserter.CurveType(curve.CurveType); err != nil {
+			return fmt.Errorf("%w: invalid CurveType for address pool", err)
+		}
+
+		if curve.Weight <= 0 {
+			return fmt.Errorf(
+				"address pool weight %d for %s must be > 0",
+				curve.Weight,
+				curve.CurveType,
+			)
+		}
+	}
+
+	return nil
+}
+
+func assertTransactionAssertions(assertions *TransactionAssertionConfiguration) error {
+	if assertions.MaxSize < 0 {
+		return fmt.Errorf("max size %d must be >= 0", assertions.MaxSize)
+	}
+
+	if assertions.MaxOperations < 0 {
+		return fmt.Errorf("max operations %d must be >= 0", assertions.MaxOperations)
+	}
+
+	if assertions.MaxFee != nil {
+		if err := asserter.Amount(assertions.MaxFee); err != nil {
+			return fmt.Errorf("%w: invalid max fee", err)
+		}
+
+		value, _ := types.AmountValue(assertions.MaxFee)
+		if value.Sign() < 0 {
+			return fmt.Errorf("max fee %s must be >= 0", assertions.MaxFee.Value)
+		}
+	}
+
+	return nil
+}
+
+func assertSenderPipelines(pipelines *SenderPipelineConfiguration) error {
+	if pipelines.Workers <= 0 {
+		return fmt.Errorf("workers %d must be > 0", pipelines.Workers)
+	}
+
+	if len(pipelines.OperationType) == 0 {
+		return errors.New("operation type must be populated")
+	}
+
+	if err := asserter.Currency(pipelines.Currency); err != nil {
+		return fmt.Errorf("%w: invalid currency", err)
+	}
+
+	minAmount, err := types.BigInt(pipelines.MinAmount)
+	if err != nil {
+		return fmt.Errorf("%w: invalid min amount", err)
+	}
+
+	if minAmount.Sign() <= 0 {
+		return fmt.Errorf("min amount %s must be > 0", pipelines.MinAmount)
+	}
+
+	maxAmount, err := types.BigInt(pipelines.MaxAmount)
+	if err != nil {
+		return fmt.Errorf("%w: invalid max amount", err)
+	}
+
+	if maxAmount.Cmp(minAmount) < 0 {
+		return fmt.Errorf(
+			"max amount %s must be >= min amount %s",
+			pipelines.MaxAmount,
+			pipelines.MinAmount,
+		)
+	}
+
+	if len(pipelines.Reserve) > 0 {
+		reserve, err := types.BigInt(pipelines.Reserve)
+		if err != nil {
+			return fmt.Errorf("%w: invalid reserve", err)
+		}
+
+		if reserve.Sign() < 0 {
+			return fmt.Errorf("reserve %s must be >= 0", pipelines.Reserve)
+		}
+	}
+
+	switch pipelines.SenderSelection {
+	case HighestBalanceSenderSelection, WeightedSenderSelection,
+		RoundRobinSenderSelection, LeastRecentlyUsedSenderSelection:
+	default:
+		return fmt.Errorf("sender selection %s is not supported", pipelines.SenderSelection)
+	}
+
+	return nil
+}
+
+func assertStepDelays(delays []*StepDelay) error {
+	seen := map[ConstructionStep]struct{}{}
+	for _, delay := range delays {
+		switch delay.Step {
+		case PreprocessConstructionStep, MetadataConstructionStep, PayloadsConstructionStep,
+			ParseConstructionStep, SignConstructionStep, CombineConstructionStep,
+			HashConstructionStep, SubmitConstructionStep:
+		default:
+			return fmt.Errorf("construction step %s cannot be delayed", delay.Step)
+		}
+
+		if _, ok := seen[delay.Step]; ok {
+			return fmt.Errorf("duplicate delay for construction step %s", delay.Step)
+		}
+		seen[delay.Step] = struct{}{}
+
+		if delay.Max != 0 && delay.Max < delay.Min {
+			return fmt.Errorf(
+				"max delay %d of construction step %s must be >= min delay %d",
+				delay.Max,
+				delay.Step,
+				delay.Min,
+			)
+		}
+	}
+
+	return nil
+}
+
+func assertBroadcastBackend(backend *BroadcastBackendConfiguration) error {
+	switch backend.Type {
+	case RosettaBroadcastBackend:
+		return nil
+	case JSONRPCBroadcastBackend:
+	default:
+		return fmt.Errorf("broadcast backend %s is not supported", backend.Type)
+	}
+
+	if _, err := url.ParseRequestURI(backend.URL); err != nil {
+		return fmt.Errorf("%w: invalid url %s", err, backend.URL)
+	}
+
+	if len(backend.Method) == 0 {
+		return errors.New("method must be populated")
+	}
+
+	return nil
+}
+
+func assertAirGap(config *ConstructionConfiguration) error {
+	if len(config.AirGap.ExchangeDirectory) == 0 {
+		return errors.New("exchange directory must be populated")
+	}
+
+	switch config.AirGap.Encoding {
+	case JSONAirGapEncoding, Base32AirGapEncoding:
+	default:
+		return fmt.Errorf("encoding %s is not supported", config.AirGap.Encoding)
+	}
+
+	// Payloads must be signed by the offline-agent.
+	if config.RemoteSigner != nil {
+		return errors.New("remote signer cannot be used with an air gap")
+	}
+
+	// Keys generated by a generate_key action are created by
+	// check:construction, so their private keys would have to
+	// be sent to the offline-agent.
+	if name, ok := generateKeyWorkflow(config); ok {
+		return fmt.Errorf(
+			"workflow %s generates keys, which cannot be used with an air gap (use the address pool)",
+			name,
+		)
+	}
+
+	return nil
+}
+
+// generateKeyWorkflow returns the name of the first
+// workflow with a generate_key action (if any).
+func generateKeyWorkflow(config *ConstructionConfiguration) (string, bool) {
+	for _, workflow := range config.Workflows {
+		for _, scenario := range workflow.Scenarios {
+			for _, action := range scenario.Actions {
+				if action.Type == job.GenerateKey {
+					return workflow.Name, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+func assertRemoteSigner(config *ConstructionConfiguration) error {
+	if _, err := url.ParseRequestURI(config.RemoteSigner.URL); err != nil {
+		return fmt.Errorf("%w: invalid url %s", err, config.RemoteSigner.URL)
+	}
+
+	// Generated keys are stored locally, so the
+	// remote signer could not sign for them.
+	if name, ok := generateKeyWorkflow(config); ok {
+		return fmt.Errorf("workflow %s generates keys, which cannot be used with a remote signer", name)
+	}
+
+	if config.AddressPool != nil {
+		return errors.New("address pool cannot be used with a remote signer")
+	}
+
+	for _, account := range config.RemoteSigner.Accounts {
+		if err := asserter.AccountIdentifier(account.AccountIdentifier); err != nil {
+			return fmt.Errorf("%w: invalid account identifier for remote signer account", err)
+		}
+
+		if err := asserter.PublicKey(account.PublicKey); err != nil {
+			return fmt.Errorf(
+				"%w: invalid public key for remote signer account %s",
+				err,
+				types.AccountString(account.AccountIdentifier),
+			)
+		}
+
+		if err := asserter.Currency(account.Currency); err != nil {
+			return fmt.Errorf(
+				"%w: invalid currency for remote signer account %s",
+				err,
+				types.AccountString(account.AccountIdentifier),
+			)
+		}
+	}
+
+	return nil
+}
+
+func assertHDWallet(config *ConstructionConfiguration) error {
+	if len(strings.TrimSpace(config.HDWallet.Mnemonic)) == 0 {
+		return errors.New("mnemonic must be populated")
+	}
+
+	for _, value := range []string{config.HDWallet.Mnemonic, config.HDWallet.Passphrase} {
+		for _, match := range HeaderPlaceholderRegex.FindAllStringSubmatch(value, -1) {
+			if HeaderPlaceholder(match[1]) != EnvHeaderPlaceholder || len(match[2]) == 0 {
+				return fmt.Errorf("placeholder %s is not supported", match[0])
+			}
+		}
+	}
+
+	if _, err := ParseDerivationPath(config.HDWallet.Path); err != nil {
+		return fmt.Errorf("%w: invalid path %s", err, config.HDWallet.Path)
+	}
+
+	// Derived keys are stored (and used to sign) locally.
+	if config.RemoteSigner != nil {
+		return errors.New("remote signer cannot be used with an hd wallet")
+	}
+
+	if config.AirGap != nil {
+		return errors.New("air gap cannot be used with an hd wallet")
+	}
+
+	return nil
+}
+
+func assertBalanceVerification(verification *BalanceVerificationConfiguration) error {
+	seen := map[string]struct{}{}
+	for _, tolerance := range verification.FeeTolerance {
+		if err := asserter.Amount(tolerance); err != nil {
+			return fmt.Errorf("%w: invalid fee tolerance", err)
+		}
+
+		value, _ := types.AmountValue(tolerance)
+		if value.Sign() < 0 {
+			return fmt.Errorf("fee tolerance %s must be >= 0", tolerance.Value)
+		}
+
+		key := types.Hash(tolerance.Currency)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf(
+				"duplicate fee tolerance for currency %s",
+				types.PrintStruct(tolerance.Currency),
+			)
+		}
+		seen[key] = struct{}{}
+	}
+
+	return nil
+}
+
+func assertInjectedOperations(operations []*InjectedOperation) error {
+	for i, op := range operations {
+		if len(op.Type) == 0 {
+			return fmt.Errorf("injected operation %d is missing a type", i)
+		}
+
+		if op.Account != nil && op.AccountFromOperation != nil {
+			return fmt.Errorf(
+				"injected operation %d cannot populate both account and account from operation",
+				i,
+			)
+		}
+
+		if op.Account != nil {
+			if err := asserter.AccountIdentifier(op.Account); err != nil {
+				return fmt.Errorf("%w: injected operation %d has an invalid account", err, i)
+			}
+		}
+
+		if op.AccountFromOperation != nil && *op.AccountFromOperation < 0 {
+			return fmt.Errorf(
+				"injected operation %d account from operation %d cannot be negative",
+				i,
+				*op.AccountFromOperation,
+			)
+		}
+
+		if op.Amount == nil {
+			continue
+		}
+
+		if op.Account == nil && op.AccountFromOperation == nil {
+			return fmt.Errorf("injected operation %d has an amount but no account", i)
+		}
+
+		if err := asserter.Amount(op.Amount); err != nil {
+			return fmt.Errorf("%w: injected operation %d has an invalid amount", err, i)
+		}
+	}
+
+	return nil
+}
+
+func assertDustConsolidation(consolidation *DustConsolidationConfiguration) error {
+	threshold := &types.Amount{
+		Value:    consolidation.Threshold,
+		Currency: consolidation.Currency,
+	}
+	if err := asserter.Amount(threshold); err != nil {
+		return fmt.Errorf("%w: invalid threshold", err)
+	}
+
+	if value, _ := types.AmountValue(threshold); value.Sign() <= 0 {
+		return fmt.Errorf("threshold %s must be > 0", consolidation.Threshold)
+	}
+
+	if consolidation.MinCoins < 2 {
+		return fmt.Errorf("min coins %d must be >= 2", consolidation.MinCoins)
+	}
+
+	if consolidation.MaxCoins != 0 && consolidation.MaxCoins < consolidation.MinCoins {
+		return fmt.Errorf(
+			"max coins %d must be >= min coins %d",
+			consolidation.MaxCoins,
+			consolidation.MinCoins,
+		)
+	}
+
+	if consolidation.Interval == 0 {
+		return errors.New("interval must be > 0")
+	}
+
+	if len(consolidation.InputOperationType) == 0 ||
+		len(consolidation.OutputOperationType) == 0 {
+		return errors.New("input and output operation types must be populated")
+	}
+
+	return nil
+}
+
+func assertMultiSenderSpend(spend *MultiSenderSpendConfiguration) error {
+	if err := asserter.Currency(spend.Currency); err != nil {
+		return fmt.Errorf("%w: invalid currency", err)
+	}
+
+	if spend.MinSenders < 2 {
+		return fmt.Errorf("min senders %d must be >= 2", spend.MinSenders)
+	}
+
+	if spend.MaxSenders != 0 && spend.MaxSenders < spend.MinSenders {
+		return fmt.Errorf(
+			"max senders %d must be >= min senders %d",
+			spend.MaxSenders,
+			spend.MinSenders,
+		)
+	}
+
+	if spend.Interval == 0 {
+		return errors.New("interval must be > 0")
+	}
+
+	if len(spend.InputOperationType) == 0 ||
+		len(spend.OutputOperationType) == 0 {
+		return errors.New("input and output operation types must be populated")
+	}
+
+	return nil
+}
+
+func assertCPFP(cpfp *CPFPConfiguration) error {
+	if err := asserter.Currency(cpfp.Currency); err != nil {
+		return fmt.Errorf("%w: invalid currency", err)
+	}
+
+	if cpfp.Interval == 0 {
+		return errors.New("interval must be > 0")
+	}
+
+	if cpfp.ParentFeeMultiplier <= 0 || cpfp.ParentFeeMultiplier >= 1 {
+		return fmt.Errorf(
+			"parent fee multiplier %f must be in (0, 1)",
+			cpfp.ParentFeeMultiplier,
+		)
+	}
+
+	if cpfp.ChildFeeMultiplier < 1 {
+		return fmt.Errorf("child fee multiplier %f must be >= 1", cpfp.ChildFeeMultiplier)
+	}
+
+	if len(cpfp.InputOperationType) == 0 ||
+		len(cpfp.OutputOperationType) == 0 {
+		return errors.New("input and output operation types must be populated")
+	}
+
+	return nil
+}
+
+func assertReplay(replay *ReplayConfiguration) error {
+	if len(replay.DatabasePath) == 0 {
+		return errors.New("database path must be populated")
+	}
+
+	if replay.Blocks <= 0 {
+		return fmt.Errorf("blocks %d must be > 0", replay.Blocks)
+	}
+
+	if len(replay.OperationTypes) == 0 {
+		return errors.New("operation types must be populated")
+	}
+
+	scale, ok := new(big.Int).SetString(replay.Scale, 10)
+	if !ok || scale.Sign() <= 0 {
+		return fmt.Errorf("scale %s must be an integer > 0", replay.Scale)
+	}
+
+	if replay.Interval == 0 {
+		return errors.New("interval must be > 0")
+	}
+
+	return nil
+}
+
+func assertConstructionEndConditions(config *ConstructionConfiguration) error {
+	for condition, count := range config.EndConditions {
+		if count < 0 {
+			return fmt.Errorf("%s count %d must be >= 0", condition, count)
+		}
+
+		if condition == TransactionsConfirmedEndCondition {
+			continue
+		}
+
+		found := false
+		for _, workflow := range config.Workflows {
+			if workflow.Name == condition {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%s is not a workflow or %s", condition, TransactionsConfirmedEndCondition)
+		}
+	}
+
+	return nil
+}
+
+// builtInDataCounters are the counters updated by check:data.
+// "time_elapsed" and "ambiguous_conditions" are defined in
+// pkg/results (which imports this package).
+// HeaderPlaceholderRegex matches the placeholders in the value
+// of a configured header (the name is the first submatch and
+// the argument, if any, is the second).
+var HeaderPlaceholderRegex = regexp.MustCompile(`\{\{\s*([a-z_]+)(?::([^}\s]*))?\s*\}\}`)
+
+// headerNameRegex matches valid HTTP header names.
+var headerNameRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// specVersionRegex matches the versions of the
+// Rosetta API supported by the rosetta-cli.
+var specVersionRegex = regexp.MustCompile(`^1\.4\.[0-9]+$`)
+
+var builtInDataCounters = []string{
+	modules.BlockCounter,
+	modules.OrphanCounter,
+	modules.TransactionCounter,
+	modules.OperationCounter,
+	modules.ActiveReconciliationCounter,
+	modules.InactiveReconciliationCounter,
+	modules.ExemptReconciliationCounter,
+	modules.FailedReconciliationCounter,
+	modules.SkippedReconciliationsCounter,
+	modules.SeenAccounts,
+	modules.ReconciledAccounts,
+	"time_elapsed",
+	"ambiguous_conditions",
+}
+
+func assertCustomCounters(counters []*CustomCounter) error {
+	names := map[string]struct{}{}
+	for _, counter := range counters {
+		if len(counter.Name) == 0 {
+			return errors.New("name must be populated")
+		}
+
+		if utils.ContainsString(builtInDataCounters, counter.Name) {
+			return fmt.Errorf("%s is a built-in counter", counter.Name)
+		}
+
+		if _, ok := names[counter.Name]; ok {
+			return fmt.Errorf("duplicate counter %s", counter.Name)
+		}
+		names[counter.Name] = struct{}{}
+
+		for _, bound := range []string{counter.MinAmount, counter.MaxAmount} {
+			if len(bound) == 0 {
+				continue
+			}
+
+			if _, err := types.BigInt(bound); err != nil {
+				return fmt.Errorf("%w: invalid amount bound for %s", err, counter.Name)
+			}
+		}
+
+		if _, err := regexp.Compile(counter.Account); err != nil {
+			return fmt.Errorf("%w: invalid account pattern for %s", err, counter.Name)
+		}
+	}
+
+	return nil
+}
+
+func assertOperationMatching(rules []*OperationMatchingRule) error {
+	seen := map[string]struct{}{}
+	for _, rule := range rules {
+		if len(rule.Type) == 0 {
+			return errors.New("type must be populated")
+		}
+
+		if _, ok := seen[rule.Type]; ok {
+			return fmt.Errorf("duplicate rule for type %s", rule.Type)
+		}
+		seen[rule.Type] = struct{}{}
+
+		switch rule.Amount {
+		case "", ExactAmountMatch, SignAmountMatch, AnyAmountMatch:
+		default:
+			return fmt.Errorf("amount match %s is not supported", rule.Amount)
+		}
+
+		switch rule.Account {
+		case "", ExactAccountMatch, AddressAccountMatch, AnyAccountMatch:
+		default:
+			return fmt.Errorf("account match %s is not supported", rule.Account)
+		}
+	}
+
+	return nil
+}
+
+func assertCounterThreshold(threshold *CounterThreshold) error {
+	if len(threshold.Counter) == 0 {
+		return errors.New("counter must be populated")
+	}
+
+	if threshold.Max < 0 {
+		return fmt.Errorf("max %f must be >= 0", threshold.Max)
+	}
+
+	switch threshold.Action {
+	case WarnThresholdAction, FailThresholdAction:
+	default:
+		return fmt.Errorf("action %s is not supported", threshold.Action)
+	}
+
+	return nil
+}
+
 func assertConfiguration(ctx context.Context, config *Configuration) error {
 	if err := asserter.NetworkIdentifier(config.Network); err != nil {
 		return fmt.Errorf("%w: invalid network identifier", err)
@@ -315,6 +1444,79 @@ func assertConfiguration(ctx context.Context, config *Configuration) error {
 		return errors.New("serial_block_workers must be > 0")
 	}
 
+	if config.Polling.Multiplier != 0 && config.Polling.Multiplier < 1 {
+		return fmt.Errorf("polling multiplier %f must be >= 1", config.Polling.Multiplier)
+	}
+
+	if config.Polling.Multiplier > 1 && config.Polling.MaxIntervalMS < config.Polling.IntervalMS {
+		return fmt.Errorf(
+			"polling max_interval_ms %d must be >= interval_ms %d when multiplier is > 1",
+			config.Polling.MaxIntervalMS,
+			config.Polling.IntervalMS,
+		)
+	}
+
+	if config.Polling.Jitter < 0 || config.Polling.Jitter >= 1 {
+		return fmt.Errorf("polling jitter %f must be in [0, 1)", config.Polling.Jitter)
+	}
+
+	for _, threshold := range config.CounterThresholds {
+		if err := assertCounterThreshold(threshold); err != nil {
+			return fmt.Errorf("%w: invalid counter threshold", err)
+		}
+	}
+
+	if config.DecimalsProbe != nil && config.DecimalsProbe.Blocks <= 0 {
+		return fmt.Errorf(
+			"decimals probe blocks %d must be > 0",
+			config.DecimalsProbe.Blocks,
+		)
+	}
+
+	if config.Paranoid != nil &&
+		(config.Paranoid.ReplayFraction <= 0 || config.Paranoid.ReplayFraction > 1) {
+		return fmt.Errorf(
+			"paranoid replay fraction %f must be in (0, 1]",
+			config.Paranoid.ReplayFraction,
+		)
+	}
+
+	if err := assertHeaders(config); err != nil {
+		return fmt.Errorf("%w: invalid headers", err)
+	}
+
+	if config.Retry != nil {
+		if err := assertRetry(config); err != nil {
+			return fmt.Errorf("%w: invalid retry configuration", err)
+		}
+	}
+
+	if config.RateLimit != nil {
+		if err := assertRateLimit(config.RateLimit); err != nil {
+			return fmt.Errorf("%w: invalid rate limit", err)
+		}
+	}
+
+	if config.TLS != nil {
+		if err := assertTLS(config.TLS); err != nil {
+			return fmt.Errorf("%w: invalid tls configuration", err)
+		}
+	}
+
+	if config.Proxy != nil {
+		if err := assertProxy(config.Proxy); err != nil {
+			return fmt.Errorf("%w: invalid proxy configuration", err)
+		}
+	}
+
+	if len(config.SpecVersion) > 0 && !specVersionRegex.MatchString(config.SpecVersion) {
+		return fmt.Errorf("spec version %s must be a 1.4.x version", config.SpecVersion)
+	}
+
+	if err := assertAccountingModel(config); err != nil {
+		return fmt.Errorf("%w: invalid accounting model", err)
+	}
+
 	if err := assertDataConfiguration(config.Data); err != nil {
 		return fmt.Errorf("%w: invalid data configuration", err)
 	}
@@ -323,9 +1525,437 @@ func assertConfiguration(ctx context.Context, config *Configuration) error {
 		return fmt.Errorf("%w: invalid construction configuration", err)
 	}
 
+	if config.Construction != nil && config.Construction.VerifyOfflineIsolation {
+		if err := assertOfflineIsolation(config); err != nil {
+			return fmt.Errorf("%w: invalid offline isolation", err)
+		}
+	}
+
+	if config.Schedule != nil {
+		if err := assertSchedule(ctx, config); err != nil {
+			return fmt.Errorf("%w: invalid schedule", err)
+		}
+	}
+
+	if len(config.Networks) > 0 {
+		if err := assertNetworks(ctx, config); err != nil {
+			return fmt.Errorf("%w: invalid networks", err)
+		}
+	}
+
+	return nil
+}
+
+// assertAccountingModel ensures no settings that are unsupported
+// by the configured accounting model are populated.
+func assertAccountingModel(config *Configuration) error {
+	switch config.AccountingModel {
+	case "":
+		return nil
+	case UTXOModel:
+		if config.Data != nil && config.Data.CoinTrackingDisabled {
+			return errors.New("data.coin_tracking_disabled cannot be set with the utxo model")
+		}
+
+		if config.Construction == nil {
+			return nil
+		}
+
+		if config.Construction.NonceTracking != nil {
+			return errors.New("construction.nonce_tracking cannot be used with the utxo model")
+		}
+
+		if config.Construction.SenderPipelines != nil {
+			return errors.New("construction.sender_pipelines cannot be used with the utxo model")
+		}
+	case AccountBasedModel:
+		if config.Construction == nil {
+			return nil
+		}
+
+		if len(config.Construction.CoinSelection) > 0 {
+			return errors.New("construction.coin_selection cannot be used with the account model")
+		}
+
+		if config.Construction.DustConsolidation != nil {
+			return errors.New(
+				"construction.dust_consolidation cannot be used with the account model",
+			)
+		}
+
+		if config.Construction.MultiSenderSpend != nil {
+			return errors.New(
+				"construction.multi_sender_spend cannot be used with the account model",
+			)
+		}
+
+		if config.Construction.CPFP != nil {
+			return errors.New("construction.cpfp cannot be used with the account model")
+		}
+	default:
+		return fmt.Errorf(
+			"accounting model %s must be %s or %s",
+			config.AccountingModel,
+			AccountBasedModel,
+			UTXOModel,
+		)
+	}
+
+	return nil
+}
+
+func assertOfflineIsolation(config *Configuration) error {
+	if config.Construction.OfflineURL == config.OnlineURL {
+		return errors.New("offline_url must be different than online_url")
+	}
+
+	// When using an air gap, only the offline-agent can
+	// reach OfflineURL.
+	if config.Construction.AirGap != nil {
+		return errors.New("cannot verify offline isolation when using an air gap")
+	}
+
+	return nil
+}
+
+func assertHeaderTemplate(value string) error {
+	for _, match := range HeaderPlaceholderRegex.FindAllStringSubmatch(value, -1) {
+		switch HeaderPlaceholder(match[1]) {
+		case RequestIDHeaderPlaceholder, EndpointHeaderPlaceholder, TimestampHeaderPlaceholder:
+			if len(match[2]) > 0 {
+				return fmt.Errorf("placeholder %s does not take an argument", match[1])
+			}
+		case EnvHeaderPlaceholder:
+			if len(match[2]) == 0 {
+				return errors.New("placeholder env must name an environment variable")
+			}
+		default:
+			return fmt.Errorf("placeholder %s is not supported", match[1])
+		}
+	}
+
+	return nil
+}
+
+func assertHeaders(config *Configuration) error {
+	for name, value := range config.Headers {
+		if !headerNameRegex.MatchString(name) {
+			return fmt.Errorf("header name %q is invalid", name)
+		}
+
+		if len(config.BearerToken) > 0 && strings.EqualFold(name, "Authorization") {
+			return errors.New("bearer_token cannot be used with an Authorization header")
+		}
+
+		if err := assertHeaderTemplate(value); err != nil {
+			return fmt.Errorf("%w: invalid value of header %s", err, name)
+		}
+	}
+
+	if err := assertHeaderTemplate(config.BearerToken); err != nil {
+		return fmt.Errorf("%w: invalid bearer_token", err)
+	}
+
+	return nil
+}
+
+func assertRetry(config *Configuration) error {
+	retry := config.Retry
+	if config.ForceRetry ||
+		(config.Construction != nil && config.Construction.ForceRetry) {
+		return errors.New("retry cannot be used with force_retry")
+	}
+
+	if retry.Multiplier < 1 {
+		return fmt.Errorf("multiplier %f must be >= 1", retry.Multiplier)
+	}
+
+	if retry.MaxIntervalMS < retry.IntervalMS {
+		return fmt.Errorf(
+			"max_interval_ms %d must be >= interval_ms %d",
+			retry.MaxIntervalMS,
+			retry.IntervalMS,
+		)
+	}
+
+	if retry.Jitter < 0 || retry.Jitter >= 1 {
+		return fmt.Errorf("jitter %f must be in [0, 1)", retry.Jitter)
+	}
+
+	for _, code := range retry.RetriableStatusCodes {
+		if code < 100 || code > 599 || code == http.StatusOK ||
+			code == http.StatusInternalServerError {
+			return fmt.Errorf("retriable status code %d is not supported", code)
+		}
+	}
+
+	fatal := map[int32]struct{}{}
+	for _, code := range retry.FatalErrorCodes {
+		fatal[code] = struct{}{}
+	}
+
+	for _, code := range retry.RetriableErrorCodes {
+		if _, ok := fatal[code]; ok {
+			return fmt.Errorf("error code %d cannot be both retriable and fatal", code)
+		}
+	}
+
+	return nil
+}
+
+func assertRateLimit(config *RateLimitConfiguration) error {
+	if config.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests per second %f must be >= 0", config.RequestsPerSecond)
+	}
+
+	if config.Burst < 0 {
+		return fmt.Errorf("burst %d must be >= 0", config.Burst)
+	}
+
+	for endpoint, perSecond := range config.Endpoints {
+		if !strings.HasPrefix(endpoint, "/") ||
+			strings.Contains(strings.TrimSuffix(endpoint, "*"), "*") {
+			return fmt.Errorf("endpoint %s must start with / and may only end with *", endpoint)
+		}
+
+		if perSecond <= 0 {
+			return fmt.Errorf("requests per second %f of %s must be > 0", perSecond, endpoint)
+		}
+	}
+
+	return nil
+}
+
+func assertProxy(config *ProxyConfiguration) error {
+	scheme := strings.SplitN(config.URL, "://", 2)
+	if len(scheme) != 2 || len(scheme[1]) == 0 {
+		return fmt.Errorf("proxy url %s must be of the form scheme://host:port", config.URL)
+	}
+
+	switch scheme[0] {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy scheme %s is not supported (must be http, https, or socks5)", scheme[0])
+	}
+
+	for _, host := range config.NoProxy {
+		if len(strings.TrimSpace(host)) == 0 {
+			return errors.New("no_proxy hosts cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+func assertTLS(config *TLSConfiguration) error {
+	if (len(config.CertFile) > 0) != (len(config.KeyFile) > 0) {
+		return errors.New("cert_file and key_file must be populated together")
+	}
+
+	if config.InsecureSkipVerify && len(config.CAFile) > 0 {
+		return errors.New("ca_file cannot be used with insecure_skip_verify")
+	}
+
+	return nil
+}
+
+func assertSchedule(ctx context.Context, config *Configuration) error {
+	if len(config.Schedule.Phases) == 0 {
+		return errors.New("phases must be populated")
+	}
+
+	names := map[string]struct{}{}
+	for _, phase := range config.Schedule.Phases {
+		if len(phase.Name) == 0 {
+			return errors.New("phase name must be populated")
+		}
+
+		if _, ok := names[phase.Name]; ok {
+			return fmt.Errorf("duplicate phase %s", phase.Name)
+		}
+		names[phase.Name] = struct{}{}
+
+		if _, err := ApplyPhase(ctx, config, phase); err != nil {
+			return fmt.Errorf("%w: invalid phase %s", err, phase.Name)
+		}
+	}
+
+	return nil
+}
+
+func assertNetworks(ctx context.Context, config *Configuration) error {
+	names := map[string]struct{}{}
+	for _, network := range config.Networks {
+		if len(network.Name) == 0 {
+			return errors.New("network name must be populated")
+		}
+
+		if _, ok := names[network.Name]; ok {
+			return fmt.Errorf("duplicate network %s", network.Name)
+		}
+		names[network.Name] = struct{}{}
+
+		if _, err := ApplyNetwork(ctx, config, network.Name); err != nil {
+			return fmt.Errorf("%w: invalid network %s", err, network.Name)
+		}
+	}
+
 	return nil
 }
 
+// NetworkNames returns the names of the networks
+// configured in config (in order).
+func NetworkNames(config *Configuration) []string {
+	names := make([]string, len(config.Networks))
+	for i, network := range config.Networks {
+		names[i] = network.Name
+	}
+
+	return names
+}
+
+// ApplyNetwork returns the *Configuration used to test the network
+// called name: a copy of config with the network identifier, online
+// URL, and overrides of the network applied. If name is empty, the
+// first configured network is used.
+func ApplyNetwork(
+	ctx context.Context,
+	config *Configuration,
+	name string,
+) (*Configuration, error) {
+	var network *NetworkConfiguration
+	for _, n := range config.Networks {
+		if len(name) == 0 || n.Name == name {
+			network = n
+			break
+		}
+	}
+	if network == nil {
+		return nil, fmt.Errorf(
+			"network %s is not configured (%s)",
+			name,
+			strings.Join(NetworkNames(config), ", "),
+		)
+	}
+
+	// Copying through JSON ensures no pointers are
+	// shared with config.
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to copy configuration", err)
+	}
+
+	var networkConfig Configuration
+	if err := json.Unmarshal(b, &networkConfig); err != nil {
+		return nil, fmt.Errorf("%w: unable to copy configuration", err)
+	}
+	networkConfig.Networks = nil
+	networkConfig.Network = network.Network
+	if len(network.OnlineURL) > 0 {
+		networkConfig.OnlineURL = network.OnlineURL
+	}
+
+	if len(network.Data) > 0 {
+		if err := json.Unmarshal(network.Data, networkConfig.Data); err != nil {
+			return nil, fmt.Errorf("%w: unable to merge data configuration", err)
+		}
+	}
+
+	if len(network.Construction) > 0 {
+		if networkConfig.Construction == nil {
+			return nil, errors.New("construction configuration is missing")
+		}
+
+		if err := json.Unmarshal(network.Construction, networkConfig.Construction); err != nil {
+			return nil, fmt.Errorf("%w: unable to merge construction configuration", err)
+		}
+	}
+
+	// Workflows compiled from the DSL file are compiled
+	// again when the configuration is asserted.
+	if networkConfig.Construction != nil && len(networkConfig.Construction.ConstructorDSLFile) > 0 {
+		networkConfig.Construction.Workflows = nil
+	}
+
+	populateMissingFields(&networkConfig)
+	if err := assertConfiguration(ctx, &networkConfig); err != nil {
+		return nil, err
+	}
+
+	return &networkConfig, nil
+}
+
+// ApplyPhase returns the *Configuration used to run a phase of
+// check:schedule: a copy of config with the phase overrides merged
+// in and the phase duration applied as an end condition.
+func ApplyPhase(
+	ctx context.Context,
+	config *Configuration,
+	phase *PhaseConfiguration,
+) (*Configuration, error) {
+	if phase.Duration == 0 {
+		return nil, errors.New("duration must be > 0")
+	}
+
+	// Copying through JSON ensures no pointers are
+	// shared with config.
+	b, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to copy configuration", err)
+	}
+
+	var phaseConfig Configuration
+	if err := json.Unmarshal(b, &phaseConfig); err != nil {
+		return nil, fmt.Errorf("%w: unable to copy configuration", err)
+	}
+	phaseConfig.Schedule = nil
+
+	if len(phase.Data) > 0 {
+		if err := json.Unmarshal(phase.Data, phaseConfig.Data); err != nil {
+			return nil, fmt.Errorf("%w: unable to merge data configuration", err)
+		}
+	}
+
+	if len(phase.Construction) > 0 {
+		if phaseConfig.Construction == nil {
+			return nil, errors.New("construction configuration is missing")
+		}
+
+		if err := json.Unmarshal(phase.Construction, phaseConfig.Construction); err != nil {
+			return nil, fmt.Errorf("%w: unable to merge construction configuration", err)
+		}
+	}
+
+	switch phase.Check {
+	case DataPhaseCheck:
+		if phaseConfig.Data.EndConditions == nil {
+			phaseConfig.Data.EndConditions = &DataEndConditions{}
+		}
+		duration := phase.Duration
+		phaseConfig.Data.EndConditions.Duration = &duration
+	case ConstructionPhaseCheck:
+		if phaseConfig.Construction == nil {
+			return nil, errors.New("construction configuration is missing")
+		}
+		phaseConfig.Construction.EndDuration = phase.Duration
+	default:
+		return nil, fmt.Errorf("check %s is not supported", phase.Check)
+	}
+
+	// Workflows compiled from the DSL file are compiled
+	// again when the configuration is asserted.
+	if phaseConfig.Construction != nil && len(phaseConfig.Construction.ConstructorDSLFile) > 0 {
+		phaseConfig.Construction.Workflows = nil
+	}
+
+	populateMissingFields(&phaseConfig)
+	if err := assertConfiguration(ctx, &phaseConfig); err != nil {
+		return nil, err
+	}
+
+	return &phaseConfig, nil
+}
+
 // modifyFilePaths modifies a collection of filepaths in a *Configuration
 // file to make them relative to the configuration file (this makes it a lot easier
 // to store all config-related files in the same directory and to run the rosetta-cli
@@ -343,6 +1973,17 @@ func modifyFilePaths(config *Configuration, fileDir string) {
 		if len(config.Data.ExemptAccounts) > 0 {
 			config.Data.ExemptAccounts = path.Join(fileDir, config.Data.ExemptAccounts)
 		}
+
+		if len(config.Data.ReconciliationSkipsFile) > 0 {
+			config.Data.ReconciliationSkipsFile = path.Join(
+				fileDir,
+				config.Data.ReconciliationSkipsFile,
+			)
+		}
+
+		if config.Data.CheckpointSync != nil && len(config.Data.CheckpointSync.File) > 0 {
+			config.Data.CheckpointSync.File = path.Join(fileDir, config.Data.CheckpointSync.File)
+		}
 	}
 
 	if config.Construction != nil {
@@ -352,18 +1993,56 @@ func modifyFilePaths(config *Configuration, fileDir string) {
 				config.Construction.ConstructorDSLFile,
 			)
 		}
+
+		if config.Construction.SenderPipelines != nil &&
+			len(config.Construction.SenderPipelines.AddressBook) > 0 {
+			config.Construction.SenderPipelines.AddressBook = path.Join(
+				fileDir,
+				config.Construction.SenderPipelines.AddressBook,
+			)
+		}
 	}
 
 	if len(config.ValidationFile) > 0 {
 		config.ValidationFile = path.Join(fileDir, config.ValidationFile)
 	}
+
+	if config.TLS != nil {
+		if len(config.TLS.CAFile) > 0 {
+			config.TLS.CAFile = path.Join(fileDir, config.TLS.CAFile)
+		}
+
+		if len(config.TLS.CertFile) > 0 {
+			config.TLS.CertFile = path.Join(fileDir, config.TLS.CertFile)
+		}
+
+		if len(config.TLS.KeyFile) > 0 {
+			config.TLS.KeyFile = path.Join(fileDir, config.TLS.KeyFile)
+		}
+	}
 }
 
 // LoadConfiguration returns a parsed and asserted Configuration for running
 // tests.
 func LoadConfiguration(ctx context.Context, filePath string) (*Configuration, error) {
+	b, err := ioutil.ReadFile(path.Clean(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open configuration file", err)
+	}
+
+	// Configuration files may contain // comments (ex: those
+	// written by configuration:create).
+	b = StripComments(b)
+	if err := ValidateSchema(b); err != nil {
+		return nil, fmt.Errorf("%w: invalid configuration file %s", err, filePath)
+	}
+
+	// To prevent silent erroring, we explicitly
+	// reject any unknown fields.
 	var configRaw Configuration
-	if err := utils.LoadAndParse(filePath, &configRaw); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&configRaw); err != nil {
 		return nil, fmt.Errorf("%w: unable to open configuration file", err)
 	}
 
@@ -389,3 +2068,55 @@ func LoadConfiguration(ctx context.Context, filePath string) (*Configuration, er
 
 	return config, nil
 }
+
+// HardenedKeyIndex is added to the index of each
+// hardened component of a derivation path.
+const HardenedKeyIndex uint32 = 0x80000000
+
+// ParseDerivationPath parses a BIP-32 derivation path
+// (ex: m/44'/60'/0'/0). Hardened components may be
+// marked with ' or h.
+func ParseDerivationPath(derivationPath string) ([]uint32, error) {
+	components := strings.Split(strings.TrimSpace(derivationPath), "/")
+	if components[0] != "m" {
+		return nil, errors.New("derivation path must start with m")
+	}
+
+	indexes := make([]uint32, 0, len(components)-1)
+	for _, component := range components[1:] {
+		hardened := strings.HasSuffix(component, "'") || strings.HasSuffix(component, "h")
+		if hardened {
+			component = component[:len(component)-1]
+		}
+
+		index, err := strconv.ParseUint(component, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid derivation path component %s", err, component)
+		}
+
+		if hardened {
+			index += uint64(HardenedKeyIndex)
+		}
+
+		indexes = append(indexes, uint32(index))
+	}
+
+	return indexes, nil
+}
+
+// FormatDerivationPath returns the derivation path
+// of indexes (the inverse of ParseDerivationPath).
+func FormatDerivationPath(indexes []uint32) string {
+	var builder strings.Builder
+	builder.WriteString("m")
+	for _, index := range indexes {
+		if index >= HardenedKeyIndex {
+			fmt.Fprintf(&builder, "/%d'", index-HardenedKeyIndex)
+			continue
+		}
+
+		fmt.Fprintf(&builder, "/%d", index)
+	}
+
+	return builder.String()
+}
@@ -16,6 +16,7 @@ package configuration
 
 import (
 	"context"
+	"encoding/json"
 	"os/exec"
 	"path"
 	"runtime"
@@ -72,6 +73,17 @@ var (
 		SeenBlockWorkers:        300,
 		SerialBlockWorkers:      200,
 		ErrorStackTraceDisabled: false,
+		Headers: map[string]string{
+			"X-Tenant-ID":  "acme",
+			"X-Request-ID": "{{request_id}}",
+		},
+		BearerToken: "{{env:ROSETTA_TOKEN}}",
+		Polling: &PollingConfiguration{
+			IntervalMS:    500,
+			Multiplier:    2,
+			MaxIntervalMS: 5000,
+			Jitter:        0.1,
+		},
 		Construction: &ConstructionConfiguration{
 			OfflineURL:            "https://ashdjaksdkjshdk",
 			MaxOfflineConnections: 21,
@@ -271,160 +283,1257 @@ func TestLoadConfiguration(t *testing.T) {
 			provided: invalidEndIndex,
 			err:      true,
 		},
-		"invalid reconciliation coverage": {
-			provided: invalidReconciliationCoverage,
-			err:      true,
+		"invalid block transaction comparison frequency": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BlockTransactionComparisonFrequency: &badStartIndex,
+				},
+			},
+			err: true,
 		},
-		"invalid reconciliation coverage (reconciliation disabled)": {
+		"invalid reconciliation max head lag": {
 			provided: &Configuration{
 				Data: &DataConfiguration{
-					ReconciliationDisabled: true,
-					EndConditions: &DataEndConditions{
-						ReconciliationCoverage: &ReconciliationCoverage{
-							Coverage: goodCoverage,
+					ReconciliationMaxHeadLag: &badStartIndex,
+				},
+			},
+			err: true,
+		},
+		"invalid operation type alias": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					OperationTypeAliases: map[string]string{"TRANSFER": ""},
+				},
+			},
+			err: true,
+		},
+		"invalid balance rule": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BalanceRules: []*BalanceRule{
+						{
+							OperationType: "FEE",
+							Action:        ExpressionBalanceRule,
 						},
 					},
 				},
 			},
 			err: true,
 		},
-		"invalid reconciliation coverage (balance tracking disabled)": {
+		"invalid checkpoint sync": {
 			provided: &Configuration{
 				Data: &DataConfiguration{
-					BalanceTrackingDisabled: true,
-					EndConditions: &DataEndConditions{
-						ReconciliationCoverage: &ReconciliationCoverage{
-							Coverage: goodCoverage,
-						},
+					InitialBalanceFetchDisabled: true,
+					CheckpointSync: &CheckpointSyncConfiguration{
+						File: "checkpoints.ndjson",
 					},
 				},
 			},
 			err: true,
 		},
-		"invalid reconciliation coverage (ignore reconciliation error)": {
+		"invalid strictness level": {
 			provided: &Configuration{
 				Data: &DataConfiguration{
-					IgnoreReconciliationError: true,
-					EndConditions: &DataEndConditions{
-						ReconciliationCoverage: &ReconciliationCoverage{
-							Coverage: goodCoverage,
+					Strictness: "pedantic",
+				},
+			},
+			err: true,
+		},
+		"invalid escalation threshold": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EscalationThreshold: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid address pool size": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					AddressPool: &AddressPoolConfiguration{
+						CurveType: types.Secp256k1,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid address pool curve weight": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					AddressPool: &AddressPoolConfiguration{
+						Size: 10,
+						CurveTypes: []*WeightedCurveType{
+							{CurveType: types.Secp256k1, Weight: 1},
+							{CurveType: types.Edwards25519},
 						},
 					},
 				},
 			},
 			err: true,
 		},
-		"valid reconciliation coverage (with account count)": {
+		"address pool curve type and curve types": {
 			provided: &Configuration{
-				Data: &DataConfiguration{
-					EndConditions: &DataEndConditions{
-						ReconciliationCoverage: &ReconciliationCoverage{
-							Coverage:     goodCoverage,
-							AccountCount: &goodAccountCount,
-							Index:        &goodAccountCount,
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					AddressPool: &AddressPoolConfiguration{
+						Size:      10,
+						CurveType: types.Secp256k1,
+						CurveTypes: []*WeightedCurveType{
+							{CurveType: types.Edwards25519, Weight: 1},
 						},
 					},
 				},
 			},
-			expected: func() *Configuration {
-				cfg := DefaultConfiguration()
-				cfg.SeenBlockWorkers = runtime.NumCPU()
-				cfg.SerialBlockWorkers = runtime.NumCPU()
-				cfg.Data.EndConditions = &DataEndConditions{
-					ReconciliationCoverage: &ReconciliationCoverage{
-						Coverage:     goodCoverage,
-						AccountCount: &goodAccountCount,
-						Index:        &goodAccountCount,
+			err: true,
+		},
+		"invalid nonce tracking max pending": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					NonceTracking: &NonceTrackingConfiguration{
+						MetadataKey: "nonce",
 					},
-				}
-
-				return cfg
-			}(),
+				},
+			},
+			err: true,
 		},
-		"invalid reconciliation coverage (with account count)": {
+		"invalid coin selection strategy": {
 			provided: &Configuration{
-				Data: &DataConfiguration{
-					EndConditions: &DataEndConditions{
-						ReconciliationCoverage: &ReconciliationCoverage{
-							Coverage:     goodCoverage,
-							AccountCount: &badAccountCount,
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					CoinSelection:      "oldest_first",
+				},
+			},
+			err: true,
+		},
+		"invalid remote signer url": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					RemoteSigner:       &RemoteSignerConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"remote signer with generate_key workflow": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					Workflows: []*job.Workflow{
+						{
+							Name:        string(job.CreateAccount),
+							Concurrency: job.ReservedWorkflowConcurrency,
+							Scenarios: []*job.Scenario{
+								{
+									Name: "create_account",
+									Actions: []*job.Action{
+										{
+											Type:       job.GenerateKey,
+											Input:      `{"curve_type": "secp256k1"}`,
+											OutputPath: "key",
+										},
+									},
+								},
+							},
 						},
 					},
+					RemoteSigner: &RemoteSignerConfiguration{
+						URL: "http://localhost:9000",
+					},
 				},
 			},
 			err: true,
 		},
-		"invalid reconciliation coverage (with index)": {
+		"remote signer with address pool": {
 			provided: &Configuration{
-				Data: &DataConfiguration{
-					EndConditions: &DataEndConditions{
-						ReconciliationCoverage: &ReconciliationCoverage{
-							Coverage: goodCoverage,
-							Index:    &badAccountCount,
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					RemoteSigner: &RemoteSignerConfiguration{
+						URL: "http://localhost:9000",
+					},
+					AddressPool: &AddressPoolConfiguration{
+						Size:      1,
+						CurveType: types.Secp256k1,
+					},
+				},
+			},
+			err: true,
+		},
+		"remote signer account without public key": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					RemoteSigner: &RemoteSignerConfiguration{
+						URL: "http://localhost:9000",
+						Accounts: []*RemoteSignerAccount{
+							{
+								AccountIdentifier: &types.AccountIdentifier{Address: "addr1"},
+								Currency:          &types.Currency{Symbol: "BTC", Decimals: 8},
+							},
 						},
 					},
 				},
 			},
 			err: true,
 		},
-		"empty workflows": {
+		"invalid transaction assertions": {
 			provided: &Configuration{
 				Construction: &ConstructionConfiguration{
-					Workflows: []*job.Workflow{},
+					ConstructorDSLFile: "test.ros",
+					TransactionAssertions: &TransactionAssertionConfiguration{
+						MaxFee: &types.Amount{
+							Value:    "-1",
+							Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+						},
+					},
 				},
 			},
 			err: true,
 		},
-		"non-existent dsl file": {
+		"invalid dust consolidation": {
 			provided: &Configuration{
 				Construction: &ConstructionConfiguration{
-					ConstructorDSLFile: "blah.ros",
+					ConstructorDSLFile: "test.ros",
+					DustConsolidation: &DustConsolidationConfiguration{
+						Currency:            &types.Currency{Symbol: "BTC", Decimals: 8},
+						Threshold:           "1000",
+						MinCoins:            1,
+						Interval:            60,
+						InputOperationType:  "INPUT",
+						OutputOperationType: "OUTPUT",
+					},
 				},
 			},
 			err: true,
 		},
-		"multiple end conditions": {
-			provided: multipleEndConditions,
-			expected: func() *Configuration {
-				def := DefaultConfiguration()
-				def.SeenBlockWorkers = runtime.NumCPU()
-				def.SerialBlockWorkers = runtime.NumCPU()
-				def.Data.EndConditions = multipleEndConditions.Data.EndConditions
-
-				return def
-			}(),
-			err: false,
+		"injected operation with amount but no account": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					InjectedOperations: []*InjectedOperation{
+						{
+							Type: "GAS",
+							Amount: &types.Amount{
+								Value:    "-10",
+								Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
+							},
+						},
+					},
+				},
+			},
+			err: true,
 		},
-	}
-
-	for name, test := range tests {
-		t.Run(name, func(t *testing.T) {
-			dir, err := utils.CreateTempDir()
-			assert.NoError(t, err)
-			defer utils.RemoveTempDir(dir)
-
-			filePath := path.Join(dir, "test.json")
-			err = utils.SerializeAndWrite(filePath, test.provided)
-			assert.NoError(t, err)
-
-			// Copy test.ros to temp dir
-			cmd := exec.Command("cp", "testdata/test.ros", path.Join(dir, "test.ros"))
-			assert.NoError(t, cmd.Run())
-
-			// Check if expected fields populated
-			config, err := LoadConfiguration(context.Background(), filePath)
-			if test.err {
-				assert.Error(t, err)
-				assert.Nil(t, config)
-			} else {
-				assert.NoError(t, err)
-
-				// Ensure test.ros expected file path is right
-				if test.expected.Construction != nil && len(test.expected.Construction.ConstructorDSLFile) > 0 {
-					test.expected.Construction.ConstructorDSLFile = path.Join(dir, test.expected.Construction.ConstructorDSLFile)
-				}
-				assert.Equal(t, test.expected, config)
-			}
-		})
-	}
+		"invalid multi-sender spend": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					MultiSenderSpend: &MultiSenderSpendConfiguration{
+						Currency:            &types.Currency{Symbol: "BTC", Decimals: 8},
+						MinSenders:          3,
+						MaxSenders:          2,
+						Interval:            60,
+						InputOperationType:  "INPUT",
+						OutputOperationType: "OUTPUT",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid cpfp": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					CPFP: &CPFPConfiguration{
+						Currency:            &types.Currency{Symbol: "BTC", Decimals: 8},
+						Interval:            60,
+						ParentFeeMultiplier: 1.5,
+						ChildFeeMultiplier:  3,
+						InputOperationType:  "INPUT",
+						OutputOperationType: "OUTPUT",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid step delays": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					StepDelays: []*StepDelay{
+						{
+							Step: SignConstructionStep,
+							Min:  60,
+							Max:  30,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid sender pipelines": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					SenderPipelines: &SenderPipelineConfiguration{
+						Workers:       0,
+						OperationType: "Transfer",
+						Currency:      &types.Currency{Symbol: "ETH", Decimals: 18},
+						MinAmount:     "10",
+						MaxAmount:     "20",
+					},
+				},
+			},
+			err: true,
+		},
+		"offline isolation with shared url": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile:     "test.ros",
+					VerifyOfflineIsolation: true,
+				},
+			},
+			err: true,
+		},
+		"invalid sender selection": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					SenderPipelines: &SenderPipelineConfiguration{
+						Workers:         2,
+						OperationType:   "Transfer",
+						Currency:        &types.Currency{Symbol: "ETH", Decimals: 18},
+						MinAmount:       "10",
+						MaxAmount:       "20",
+						SenderSelection: "random",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid broadcast backend": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					BroadcastBackend: &BroadcastBackendConfiguration{
+						Type: JSONRPCBroadcastBackend,
+						URL:  "http://localhost:8545",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid air gap": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					AirGap:             &AirGapConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"air gap with generate_key workflow": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					Workflows: []*job.Workflow{
+						{
+							Name:        string(job.CreateAccount),
+							Concurrency: job.ReservedWorkflowConcurrency,
+							Scenarios: []*job.Scenario{
+								{
+									Name: "create_account",
+									Actions: []*job.Action{
+										{
+											Type:       job.GenerateKey,
+											Input:      `{"curve_type": "secp256k1"}`,
+											OutputPath: "key",
+										},
+									},
+								},
+							},
+						},
+					},
+					AirGap: &AirGapConfiguration{
+						ExchangeDirectory: "exchange",
+						Encoding:          JSONAirGapEncoding,
+					},
+				},
+			},
+			err: true,
+		},
+		"hd wallet without mnemonic": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					HDWallet:           &HDWalletConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"hd wallet with invalid path": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					HDWallet: &HDWalletConfiguration{
+						Mnemonic: "{{env:MNEMONIC}}",
+						Path:     "44'/0'",
+					},
+				},
+			},
+			err: true,
+		},
+		"hd wallet with remote signer": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					RemoteSigner: &RemoteSignerConfiguration{
+						URL: "http://localhost:8545",
+					},
+					HDWallet: &HDWalletConfiguration{
+						Mnemonic: "{{env:MNEMONIC}}",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid balance verification": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					BalanceVerification: &BalanceVerificationConfiguration{
+						FeeTolerance: []*types.Amount{
+							{
+								Value:    "-1",
+								Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+							},
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"partial sync without end index": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					StartIndex:  types.Int64(10),
+					PartialSync: &PartialSyncConfiguration{BaselineAtStart: true},
+				},
+			},
+			err: true,
+		},
+		"partial sync with historical balance disabled": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					StartIndex: types.Int64(10),
+					EndConditions: &DataEndConditions{
+						Index: types.Int64(20),
+					},
+					HistoricalBalanceDisabled: types.Bool(true),
+					PartialSync:               &PartialSyncConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"invalid spec version": {
+			provided: &Configuration{
+				SpecVersion: "1.3.1",
+			},
+			err: true,
+		},
+		"historical reconciliation with historical balance disabled": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					HistoricalBalanceDisabled: types.Bool(true),
+					HistoricalReconciliation:  &HistoricalReconciliationConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"negative reconciliation rate limit": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					InactiveReconciliationRateLimit: -1,
+				},
+			},
+			err: true,
+		},
+		"invalid explorer": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					Explorer: &ExplorerConfiguration{
+						Type:     EsploraExplorer,
+						URL:      "blockstream.info/api",
+						Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid accounting model": {
+			provided: &Configuration{
+				AccountingModel: "ledger",
+			},
+			err: true,
+		},
+		"coin tracking disabled with utxo model": {
+			provided: &Configuration{
+				AccountingModel: UTXOModel,
+				Data: &DataConfiguration{
+					CoinTrackingDisabled: true,
+				},
+			},
+			err: true,
+		},
+		"invalid state commitment": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					StateCommitment: &StateCommitmentConfiguration{
+						Type: SparseMerkleStateCommitment,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid custom counter": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					CustomCounters: []*CustomCounter{
+						{
+							Name: "blocks",
+							Type: "Transfer",
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid custom counter account": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					CustomCounters: []*CustomCounter{
+						{
+							Name:    "burns",
+							Account: "0x(",
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid end condition": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					Workflows: []*job.Workflow{
+						{
+							Name:        "transfer",
+							Concurrency: 10,
+						},
+					},
+					EndConditions: map[string]int{
+						"transfer":                        10,
+						TransactionsConfirmedEndCondition: 10,
+						"transfers":                       10,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid schedule check": {
+			provided: &Configuration{
+				Schedule: &ScheduleConfiguration{
+					Phases: []*PhaseConfiguration{
+						{
+							Name:     "sync",
+							Check:    "balance",
+							Duration: 60,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid schedule construction phase": {
+			provided: &Configuration{
+				Schedule: &ScheduleConfiguration{
+					Phases: []*PhaseConfiguration{
+						{
+							Name:     "construct",
+							Check:    ConstructionPhaseCheck,
+							Duration: 60,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid header name": {
+			provided: &Configuration{
+				Headers: map[string]string{"X API Key": "key"},
+			},
+			err: true,
+		},
+		"invalid header placeholder": {
+			provided: &Configuration{
+				Headers: map[string]string{"X-Request-ID": "{{uuid}}"},
+			},
+			err: true,
+		},
+		"invalid bearer token": {
+			provided: &Configuration{
+				Headers:     map[string]string{"authorization": "Basic dXNlcjpwYXNz"},
+				BearerToken: "{{env:TOKEN}}",
+			},
+			err: true,
+		},
+		"retry defaults": {
+			provided: &Configuration{
+				Retry: &RetryConfiguration{
+					Jitter:          0.2,
+					FatalErrorCodes: []int32{12},
+				},
+			},
+			expected: func() *Configuration {
+				cfg := DefaultConfiguration()
+				cfg.SeenBlockWorkers = runtime.NumCPU()
+				cfg.SerialBlockWorkers = runtime.NumCPU()
+				cfg.Retry = &RetryConfiguration{
+					IntervalMS:           DefaultRetryIntervalMS,
+					Multiplier:           DefaultRetryMultiplier,
+					MaxIntervalMS:        DefaultRetryMaxIntervalMS,
+					Jitter:               0.2,
+					RetriableStatusCodes: DefaultRetriableStatusCodes,
+					FatalErrorCodes:      []int32{12},
+				}
+
+				return cfg
+			}(),
+		},
+		"invalid retry with force retry": {
+			provided: &Configuration{
+				ForceRetry: true,
+				Retry:      &RetryConfiguration{},
+			},
+			err: true,
+		},
+		"polling backoff without max interval": {
+			provided: &Configuration{
+				Polling: &PollingConfiguration{
+					IntervalMS: 1000,
+					Multiplier: 2,
+				},
+			},
+			err: true,
+		},
+		"invalid retry multiplier": {
+			provided: &Configuration{
+				Retry: &RetryConfiguration{Multiplier: 0.5},
+			},
+			err: true,
+		},
+		"invalid retry status code": {
+			provided: &Configuration{
+				Retry: &RetryConfiguration{RetriableStatusCodes: []int{500}},
+			},
+			err: true,
+		},
+		"invalid retry error codes": {
+			provided: &Configuration{
+				Retry: &RetryConfiguration{
+					RetriableErrorCodes: []int32{1, 2},
+					FatalErrorCodes:     []int32{2},
+				},
+			},
+			err: true,
+		},
+		"invalid rate limit endpoint": {
+			provided: &Configuration{
+				RateLimit: &RateLimitConfiguration{
+					RequestsPerSecond: 10,
+					Endpoints:         map[string]float64{"/construction/*/submit": 1},
+				},
+			},
+			err: true,
+		},
+		"invalid proxy scheme": {
+			provided: &Configuration{
+				Proxy: &ProxyConfiguration{URL: "ftp://127.0.0.1:2121"},
+			},
+			err: true,
+		},
+		"invalid tls client certificate": {
+			provided: &Configuration{
+				TLS: &TLSConfiguration{
+					CertFile: "client.pem",
+				},
+			},
+			err: true,
+		},
+		"invalid tls verification": {
+			provided: &Configuration{
+				TLS: &TLSConfiguration{
+					CAFile:             "ca.pem",
+					InsecureSkipVerify: true,
+				},
+			},
+			err: true,
+		},
+		"duplicate network": {
+			provided: &Configuration{
+				Networks: []*NetworkConfiguration{
+					{
+						Name:    "testnet",
+						Network: &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Testnet3"},
+					},
+					{
+						Name:    "testnet",
+						Network: &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Regtest"},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid network override": {
+			provided: &Configuration{
+				Networks: []*NetworkConfiguration{
+					{
+						Name:    "mainnet",
+						Network: &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"},
+						Data:    json.RawMessage(`{"start_index": -10}`),
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid operation matching": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					OperationMatching: []*OperationMatchingRule{
+						{
+							Type:   "claim_rewards",
+							Amount: "approximate",
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid replay": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					Replay: &ReplayConfiguration{
+						DatabasePath:   "check-data",
+						Blocks:         100,
+						OperationTypes: []string{"Transfer"},
+						Scale:          "0",
+						Interval:       60,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid load test": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					LoadTest: &LoadTestConfiguration{
+						TargetTPS:   10,
+						Concurrency: 0,
+						Duration:    60,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid fee estimation percentile": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					FeeEstimation: &FeeEstimationConfiguration{
+						Window:     10,
+						Percentile: 1.5,
+						MaxFee:     "1000",
+					},
+				},
+			},
+			err: true,
+		},
+		"load test without sender pipelines": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					LoadTest: &LoadTestConfiguration{
+						TargetTPS:   10,
+						Concurrency: 5,
+						Duration:    60,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid skipped construction step": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					SkippedSteps:       []ConstructionStep{"hash"},
+				},
+			},
+			err: true,
+		},
+		"invalid max address reuse": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "test.ros",
+					MaxAddressReuse:    -1,
+				},
+			},
+			err: true,
+		},
+		"invalid counter threshold action": {
+			provided: &Configuration{
+				CounterThresholds: []*CounterThreshold{
+					{
+						Counter: "orphans",
+						Max:     50,
+						Action:  "explode",
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid counter threshold max": {
+			provided: &Configuration{
+				CounterThresholds: []*CounterThreshold{
+					{
+						Counter: "orphans",
+						Max:     -1,
+						Action:  FailThresholdAction,
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid paranoid replay fraction": {
+			provided: &Configuration{
+				Paranoid: &ParanoidConfiguration{
+					ReplayFraction: 1.5,
+				},
+			},
+			err: true,
+		},
+		"invalid decimals probe blocks": {
+			provided: &Configuration{
+				DecimalsProbe: &DecimalsProbeConfiguration{},
+			},
+			err: true,
+		},
+		"invalid reconciliation coverage": {
+			provided: invalidReconciliationCoverage,
+			err:      true,
+		},
+		"invalid reconciliation coverage (reconciliation disabled)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					ReconciliationDisabled: true,
+					EndConditions: &DataEndConditions{
+						ReconciliationCoverage: &ReconciliationCoverage{
+							Coverage: goodCoverage,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation coverage (balance tracking disabled)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					BalanceTrackingDisabled: true,
+					EndConditions: &DataEndConditions{
+						ReconciliationCoverage: &ReconciliationCoverage{
+							Coverage: goodCoverage,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation coverage (ignore reconciliation error)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					IgnoreReconciliationError: true,
+					EndConditions: &DataEndConditions{
+						ReconciliationCoverage: &ReconciliationCoverage{
+							Coverage: goodCoverage,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"valid reconciliation coverage (with account count)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EndConditions: &DataEndConditions{
+						ReconciliationCoverage: &ReconciliationCoverage{
+							Coverage:     goodCoverage,
+							AccountCount: &goodAccountCount,
+							Index:        &goodAccountCount,
+						},
+					},
+				},
+			},
+			expected: func() *Configuration {
+				cfg := DefaultConfiguration()
+				cfg.SeenBlockWorkers = runtime.NumCPU()
+				cfg.SerialBlockWorkers = runtime.NumCPU()
+				cfg.Data.EndConditions = &DataEndConditions{
+					ReconciliationCoverage: &ReconciliationCoverage{
+						Coverage:     goodCoverage,
+						AccountCount: &goodAccountCount,
+						Index:        &goodAccountCount,
+					},
+				}
+
+				return cfg
+			}(),
+		},
+		"invalid reconciliation coverage (with account count)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EndConditions: &DataEndConditions{
+						ReconciliationCoverage: &ReconciliationCoverage{
+							Coverage:     goodCoverage,
+							AccountCount: &badAccountCount,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"invalid reconciliation coverage (with index)": {
+			provided: &Configuration{
+				Data: &DataConfiguration{
+					EndConditions: &DataEndConditions{
+						ReconciliationCoverage: &ReconciliationCoverage{
+							Coverage: goodCoverage,
+							Index:    &badAccountCount,
+						},
+					},
+				},
+			},
+			err: true,
+		},
+		"empty workflows": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					Workflows: []*job.Workflow{},
+				},
+			},
+			err: true,
+		},
+		"non-existent dsl file": {
+			provided: &Configuration{
+				Construction: &ConstructionConfiguration{
+					ConstructorDSLFile: "blah.ros",
+				},
+			},
+			err: true,
+		},
+		"multiple end conditions": {
+			provided: multipleEndConditions,
+			expected: func() *Configuration {
+				def := DefaultConfiguration()
+				def.SeenBlockWorkers = runtime.NumCPU()
+				def.SerialBlockWorkers = runtime.NumCPU()
+				def.Data.EndConditions = multipleEndConditions.Data.EndConditions
+
+				return def
+			}(),
+			err: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := utils.CreateTempDir()
+			assert.NoError(t, err)
+			defer utils.RemoveTempDir(dir)
+
+			filePath := path.Join(dir, "test.json")
+			err = utils.SerializeAndWrite(filePath, test.provided)
+			assert.NoError(t, err)
+
+			// Copy test.ros to temp dir
+			cmd := exec.Command("cp", "testdata/test.ros", path.Join(dir, "test.ros"))
+			assert.NoError(t, cmd.Run())
+
+			// Check if expected fields populated
+			config, err := LoadConfiguration(context.Background(), filePath)
+			if test.err {
+				assert.Error(t, err)
+				assert.Nil(t, config)
+			} else {
+				assert.NoError(t, err)
+
+				// Ensure test.ros expected file path is right
+				if test.expected.Construction != nil && len(test.expected.Construction.ConstructorDSLFile) > 0 {
+					test.expected.Construction.ConstructorDSLFile = path.Join(dir, test.expected.Construction.ConstructorDSLFile)
+				}
+				assert.Equal(t, test.expected, config)
+			}
+		})
+	}
+}
+
+func TestDerivationPath(t *testing.T) {
+	var tests = map[string]struct {
+		path string
+
+		indexes []uint32
+		err     bool
+	}{
+		"master": {
+			path:    "m",
+			indexes: []uint32{},
+		},
+		"bip-44": {
+			path:    "m/44'/60'/0'/0",
+			indexes: []uint32{44 + HardenedKeyIndex, 60 + HardenedKeyIndex, HardenedKeyIndex, 0},
+		},
+		"h suffix": {
+			path:    "m/44h/1",
+			indexes: []uint32{44 + HardenedKeyIndex, 1},
+		},
+		"missing master": {
+			path: "44'/0'",
+			err:  true,
+		},
+		"index out of range": {
+			path: "m/2147483648",
+			err:  true,
+		},
+		"empty component": {
+			path: "m//0",
+			err:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			indexes, err := ParseDerivationPath(test.path)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.indexes, indexes)
+			parsed, err := ParseDerivationPath(FormatDerivationPath(indexes))
+			assert.NoError(t, err)
+			assert.Equal(t, indexes, parsed)
+		})
+	}
+}
+
+func TestAssertAccountingModel(t *testing.T) {
+	var tests = map[string]struct {
+		config *Configuration
+		err    bool
+	}{
+		"no model": {
+			config: &Configuration{
+				Construction: &ConstructionConfiguration{
+					NonceTracking: &NonceTrackingConfiguration{},
+					CoinSelection: RandomCoinSelection,
+				},
+			},
+		},
+		"utxo model": {
+			config: &Configuration{
+				AccountingModel: UTXOModel,
+				Construction: &ConstructionConfiguration{
+					CoinSelection: RandomCoinSelection,
+				},
+			},
+		},
+		"utxo model with nonce tracking": {
+			config: &Configuration{
+				AccountingModel: UTXOModel,
+				Construction: &ConstructionConfiguration{
+					NonceTracking: &NonceTrackingConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"utxo model with sender pipelines": {
+			config: &Configuration{
+				AccountingModel: UTXOModel,
+				Construction: &ConstructionConfiguration{
+					SenderPipelines: &SenderPipelineConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"account model": {
+			config: &Configuration{
+				AccountingModel: AccountBasedModel,
+				Data: &DataConfiguration{
+					CoinTrackingDisabled: true,
+				},
+				Construction: &ConstructionConfiguration{
+					NonceTracking: &NonceTrackingConfiguration{},
+				},
+			},
+		},
+		"account model with coin selection": {
+			config: &Configuration{
+				AccountingModel: AccountBasedModel,
+				Construction: &ConstructionConfiguration{
+					CoinSelection: LargestFirstCoinSelection,
+				},
+			},
+			err: true,
+		},
+		"account model with dust consolidation": {
+			config: &Configuration{
+				AccountingModel: AccountBasedModel,
+				Construction: &ConstructionConfiguration{
+					DustConsolidation: &DustConsolidationConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"account model with multi-sender spend": {
+			config: &Configuration{
+				AccountingModel: AccountBasedModel,
+				Construction: &ConstructionConfiguration{
+					MultiSenderSpend: &MultiSenderSpendConfiguration{},
+				},
+			},
+			err: true,
+		},
+		"account model with cpfp": {
+			config: &Configuration{
+				AccountingModel: AccountBasedModel,
+				Construction: &ConstructionConfiguration{
+					CPFP: &CPFPConfiguration{},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := assertAccountingModel(test.config)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyPhase(t *testing.T) {
+	ctx := context.Background()
+	config := populateMissingFields(&Configuration{
+		Construction: &ConstructionConfiguration{
+			Workflows: []*job.Workflow{
+				{
+					Name:        "transfer",
+					Concurrency: 10,
+				},
+			},
+			LoadTest: &LoadTestConfiguration{
+				TargetTPS:   1,
+				Concurrency: 1,
+				Duration:    3600,
+			},
+			SenderPipelines: &SenderPipelineConfiguration{
+				Workers:       2,
+				OperationType: "Transfer",
+				Currency:      &types.Currency{Symbol: "ETH", Decimals: 18},
+				MinAmount:     "10",
+				MaxAmount:     "20",
+			},
+		},
+		Data: &DataConfiguration{
+			ReconciliationDisabled: true,
+		},
+	})
+
+	dataPhase, err := ApplyPhase(ctx, config, &PhaseConfiguration{
+		Name:     "tip",
+		Check:    DataPhaseCheck,
+		Duration: 14400,
+		Data:     json.RawMessage(`{"reconciliation_disabled": false}`),
+	})
+	assert.NoError(t, err)
+	assert.False(t, dataPhase.Data.ReconciliationDisabled)
+	assert.Equal(t, uint64(14400), *dataPhase.Data.EndConditions.Duration)
+	assert.True(t, config.Data.ReconciliationDisabled)
+	assert.Nil(t, config.Data.EndConditions)
+
+	constructionPhase, err := ApplyPhase(ctx, config, &PhaseConfiguration{
+		Name:         "load",
+		Check:        ConstructionPhaseCheck,
+		Duration:     3600,
+		Construction: json.RawMessage(`{"load_test": {"target_tps": 10}}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3600), constructionPhase.Construction.EndDuration)
+	assert.Equal(t, &LoadTestConfiguration{
+		TargetTPS:   10,
+		Concurrency: 1,
+		Duration:    3600,
+	}, constructionPhase.Construction.LoadTest)
+	assert.Equal(t, float64(1), config.Construction.LoadTest.TargetTPS)
+
+	_, err = ApplyPhase(ctx, config, &PhaseConfiguration{
+		Name:     "sync",
+		Check:    DataPhaseCheck,
+		Duration: 0,
+	})
+	assert.Error(t, err)
+}
+
+func TestApplyNetwork(t *testing.T) {
+	ctx := context.Background()
+	config := populateMissingFields(&Configuration{
+		OnlineURL: "http://localhost:8080",
+		Networks: []*NetworkConfiguration{
+			{
+				Name:    "mainnet",
+				Network: &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"},
+			},
+			{
+				Name:      "testnet",
+				Network:   &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Testnet3"},
+				OnlineURL: "http://localhost:8081",
+				Data:      json.RawMessage(`{"reconciliation_disabled": true}`),
+			},
+		},
+	})
+	assert.NoError(t, assertConfiguration(ctx, config))
+	assert.Equal(t, []string{"mainnet", "testnet"}, NetworkNames(config))
+
+	mainnet, err := ApplyNetwork(ctx, config, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mainnet", mainnet.Network.Network)
+	assert.Equal(t, "http://localhost:8080", mainnet.OnlineURL)
+	assert.False(t, mainnet.Data.ReconciliationDisabled)
+	assert.Nil(t, mainnet.Networks)
+
+	testnet, err := ApplyNetwork(ctx, config, "testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "Testnet3", testnet.Network.Network)
+	assert.Equal(t, "http://localhost:8081", testnet.OnlineURL)
+	assert.True(t, testnet.Data.ReconciliationDisabled)
+	assert.False(t, config.Data.ReconciliationDisabled)
+	assert.Len(t, config.Networks, 2)
+
+	_, err = ApplyNetwork(ctx, config, "regtest")
+	assert.EqualError(t, err, "network regtest is not configured (mainnet, testnet)")
 }
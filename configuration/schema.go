@@ -0,0 +1,524 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxSuggestionDistance is the maximum edit distance between
+	// an unknown key and a known key for the known key to be
+	// suggested.
+	maxSuggestionDistance = 3
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// SchemaError is a problem found while checking a
+// configuration file against the Configuration schema.
+type SchemaError struct {
+	// Line and Column are the 1-indexed position
+	// of the problem in the configuration file.
+	Line   int
+	Column int
+
+	// Path is the location of the problem in the
+	// configuration (ex: construction.workflows[0].name).
+	Path string
+
+	Message string
+
+	// Context is the line of the configuration
+	// file containing the problem.
+	Context string
+}
+
+// Error returns the problem with its position.
+func (e *SchemaError) Error() string {
+	msg := fmt.Sprintf("line %d, column %d", e.Line, e.Column)
+	if len(e.Path) > 0 {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Path)
+	}
+
+	return fmt.Sprintf("%s: %s", msg, e.Message)
+}
+
+// Report returns the problem with its position and the
+// line containing it (with the column marked).
+func (e *SchemaError) Report() string {
+	if len(e.Context) == 0 {
+		return e.Error()
+	}
+
+	return fmt.Sprintf(
+		"%s\n    %s\n    %s^",
+		e.Error(),
+		e.Context,
+		strings.Repeat(" ", e.Column-1),
+	)
+}
+
+// SchemaErrors are all problems found in a
+// configuration file.
+type SchemaErrors []*SchemaError
+
+// Error returns all problems on a single line.
+func (e SchemaErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf(
+		"%d configuration schema errors: %s",
+		len(e),
+		strings.Join(msgs, "; "),
+	)
+}
+
+// Report returns the report of each problem
+// (separated by newlines).
+func (e SchemaErrors) Report() string {
+	reports := make([]string, len(e))
+	for i, err := range e {
+		reports[i] = err.Report()
+	}
+
+	return strings.Join(reports, "\n")
+}
+
+// schemaValidator walks a configuration file token by token,
+// checking each value against the type it is decoded into.
+type schemaValidator struct {
+	data   []byte
+	dec    *json.Decoder
+	errors SchemaErrors
+}
+
+// ValidateSchema checks a configuration file against the
+// Configuration schema and returns all unknown keys and type
+// mismatches (with line-level context) as SchemaErrors. A
+// syntax error is returned as a single SchemaError.
+func ValidateSchema(data []byte) error {
+	v := &schemaValidator{
+		data: data,
+		dec:  json.NewDecoder(bytes.NewReader(data)),
+	}
+	v.dec.UseNumber()
+
+	// The tokenizer reports less helpful syntax errors than
+	// json.Unmarshal, so we check the syntax first. The offset
+	// of a *json.SyntaxError is after the offending character.
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) && syntaxErr.Offset > 0 {
+			return SchemaErrors{v.newError(syntaxErr.Offset-1, "", syntaxErr.Error())}
+		}
+
+		return SchemaErrors{v.newError(int64(len(data)), "", err.Error())}
+	}
+
+	if err := v.walk(reflect.TypeOf(Configuration{}), ""); err != nil {
+		return SchemaErrors{v.newError(v.dec.InputOffset(), "", err.Error())}
+	}
+
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return v.errors
+}
+
+// newError returns a *SchemaError at offset (skipping any
+// whitespace or separators before the offending token).
+func (v *schemaValidator) newError(offset int64, path string, message string) *SchemaError {
+	for offset < int64(len(v.data)) && strings.ContainsRune(" \t\r\n,:", rune(v.data[offset])) {
+		offset++
+	}
+
+	if offset > int64(len(v.data)) {
+		offset = int64(len(v.data))
+	}
+
+	lineStart := bytes.LastIndexByte(v.data[:offset], '\n') + 1
+	lineEnd := bytes.IndexByte(v.data[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(v.data)
+	} else {
+		lineEnd += int(offset)
+	}
+
+	return &SchemaError{
+		Line:    bytes.Count(v.data[:offset], []byte("\n")) + 1,
+		Column:  int(offset) - lineStart + 1,
+		Path:    path,
+		Message: message,
+		Context: strings.TrimRight(string(v.data[lineStart:lineEnd]), "\r"),
+	}
+}
+
+// walk checks the next value against t. Problems with the
+// value are recorded and the value is skipped. An error is
+// only returned if the file cannot be tokenized.
+func (v *schemaValidator) walk(t reflect.Type, path string) error {
+	offset := v.dec.InputOffset()
+	token, err := v.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	// null is accepted for any type (the value is unchanged).
+	if token == nil {
+		return nil
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types that decode themselves are not checked.
+	if t.Kind() == reflect.Interface || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return v.skip(token)
+	}
+
+	mismatch := func(found string) error {
+		v.errors = append(v.errors, v.newError(
+			offset,
+			path,
+			fmt.Sprintf("expected %s but found %s", describeType(t), found),
+		))
+
+		return v.skip(token)
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		switch {
+		case token == '{' && t.Kind() == reflect.Struct:
+			return v.walkStruct(t, path)
+		case token == '{' && t.Kind() == reflect.Map:
+			return v.walkMap(t, path)
+		case token == '[' && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			return v.walkSlice(t, path)
+		case token == '{':
+			return mismatch("an object")
+		default:
+			return mismatch("an array")
+		}
+	case string:
+		if t.Kind() != reflect.String && !reflect.PtrTo(t).Implements(textUnmarshalerType) {
+			return mismatch(fmt.Sprintf("string %q", token))
+		}
+	case bool:
+		if t.Kind() != reflect.Bool {
+			return mismatch(fmt.Sprintf("boolean %t", token))
+		}
+	case json.Number:
+		return v.checkNumber(t, token, path, offset, mismatch)
+	}
+
+	return nil
+}
+
+// checkNumber checks that number can be decoded into t.
+func (v *schemaValidator) checkNumber(
+	t reflect.Type,
+	number json.Number,
+	path string,
+	offset int64,
+	mismatch func(string) error,
+) error {
+	found := fmt.Sprintf("number %s", number)
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, err := number.Int64(); err != nil {
+			return mismatch(found)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := number.Int64()
+		if err != nil {
+			return mismatch(found)
+		}
+
+		if value < 0 {
+			v.errors = append(v.errors, v.newError(
+				offset,
+				path,
+				fmt.Sprintf("expected %s but found negative number %s", describeType(t), number),
+			))
+		}
+	default:
+		return mismatch(found)
+	}
+
+	return nil
+}
+
+// walkStruct checks the keys and values of an object
+// decoded into the struct t (after its opening brace).
+func (v *schemaValidator) walkStruct(t reflect.Type, path string) error {
+	fields := jsonFields(t)
+	for v.dec.More() {
+		offset := v.dec.InputOffset()
+		token, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+		field, ok := fields[key]
+		if !ok {
+			// encoding/json matches keys case-insensitively.
+			for name, candidate := range fields {
+				if strings.EqualFold(name, key) {
+					field, ok = candidate, true
+					break
+				}
+			}
+		}
+
+		if !ok {
+			message := fmt.Sprintf("unknown key %q", key)
+			if suggestion := suggestKey(key, fields); len(suggestion) > 0 {
+				message = fmt.Sprintf("%s (did you mean %q?)", message, suggestion)
+			}
+
+			v.errors = append(v.errors, v.newError(offset, joinPath(path, key), message))
+			if err := v.skipValue(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := v.walk(field, joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+
+	_, err := v.dec.Token()
+	return err
+}
+
+// walkMap checks the values of an object decoded
+// into the map t (after its opening brace).
+func (v *schemaValidator) walkMap(t reflect.Type, path string) error {
+	for v.dec.More() {
+		token, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+		if err := v.walk(t.Elem(), joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+
+	_, err := v.dec.Token()
+	return err
+}
+
+// walkSlice checks the elements of an array decoded
+// into the slice t (after its opening bracket).
+func (v *schemaValidator) walkSlice(t reflect.Type, path string) error {
+	for i := 0; v.dec.More(); i++ {
+		if err := v.walk(t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+
+	_, err := v.dec.Token()
+	return err
+}
+
+// skip skips the rest of a value that started with token.
+func (v *schemaValidator) skip(token json.Token) error {
+	delim, ok := token.(json.Delim)
+	if !ok || delim == '}' || delim == ']' {
+		return nil
+	}
+
+	for depth := 1; depth > 0; {
+		token, err := v.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// skipValue skips the next value.
+func (v *schemaValidator) skipValue() error {
+	token, err := v.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	return v.skip(token)
+}
+
+// jsonFields returns the type of each field of the struct t
+// keyed by its JSON name (including the fields of embedded
+// structs).
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && len(name) == 0 {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				for name, fieldType := range jsonFields(embedded) {
+					if _, ok := fields[name]; !ok {
+						fields[name] = fieldType
+					}
+				}
+				continue
+			}
+		}
+
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+// suggestKey returns the known key closest to key (if
+// it is within maxSuggestionDistance edits).
+func suggestKey(key string, fields map[string]reflect.Type) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suggestion := ""
+	best := maxSuggestionDistance + 1
+	for _, name := range names {
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < best {
+			suggestion = name
+			best = distance
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance
+// between a and b.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(
+				minInt(previous[j]+1, current[j-1]+1),
+				previous[j-1]+cost,
+			)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// describeType returns a description of the
+// JSON value expected for t.
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return t.String()
+	}
+}
+
+func joinPath(path string, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSchema(t *testing.T) {
+	var tests = map[string]struct {
+		data     string
+		expected SchemaErrors
+	}{
+		"valid": {
+			data: `{
+  "network": {"blockchain": "Bitcoin", "network": "Mainnet"},
+  "HTTP_TIMEOUT": 10,
+  "polling": {"multiplier": 1.5},
+  "data": {
+    "end_conditions": null,
+    "active_reconciliation_concurrency": 4
+  },
+  "construction": {
+    "workflows": [{"name": "transfer", "concurrency": 1, "scenarios": []}],
+    "address_pool": {"metadata": {"anything": [1, {"goes": true}]}}
+  }
+}`,
+		},
+		"unknown keys": {
+			data: `{
+  "max_retry": 5,
+  "data": {"reconciliation_disabled": true, "foo": {"bar": [1]}}
+}`,
+			expected: SchemaErrors{
+				{
+					Line:    2,
+					Column:  3,
+					Path:    "max_retry",
+					Message: "unknown key \"max_retry\" (did you mean \"max_retries\"?)",
+					Context: "  \"max_retry\": 5,",
+				},
+				{
+					Line:    3,
+					Column:  45,
+					Path:    "data.foo",
+					Message: "unknown key \"foo\"",
+					Context: "  \"data\": {\"reconciliation_disabled\": true, \"foo\": {\"bar\": [1]}}",
+				},
+			},
+		},
+		"type mismatches": {
+			data: `{
+  "http_timeout": "10",
+  "max_retries": -1,
+  "network": ["Bitcoin"],
+  "data": {"balance_tracking_disabled": 1, "inactive_reconciliation_frequency": 2.5}
+}`,
+			expected: SchemaErrors{
+				{
+					Line:    2,
+					Column:  19,
+					Path:    "http_timeout",
+					Message: "expected a non-negative integer but found string \"10\"",
+					Context: "  \"http_timeout\": \"10\",",
+				},
+				{
+					Line:    3,
+					Column:  18,
+					Path:    "max_retries",
+					Message: "expected a non-negative integer but found negative number -1",
+					Context: "  \"max_retries\": -1,",
+				},
+				{
+					Line:    4,
+					Column:  14,
+					Path:    "network",
+					Message: "expected an object but found an array",
+					Context: "  \"network\": [\"Bitcoin\"],",
+				},
+				{
+					Line:    5,
+					Column:  41,
+					Path:    "data.balance_tracking_disabled",
+					Message: "expected a boolean but found number 1",
+					Context: "  \"data\": {\"balance_tracking_disabled\": 1, \"inactive_reconciliation_frequency\": 2.5}",
+				},
+				{
+					Line:    5,
+					Column:  81,
+					Path:    "data.inactive_reconciliation_frequency",
+					Message: "expected a non-negative integer but found number 2.5",
+					Context: "  \"data\": {\"balance_tracking_disabled\": 1, \"inactive_reconciliation_frequency\": 2.5}",
+				},
+			},
+		},
+		"syntax error": {
+			data: "{\n  \"http_timeout\": 10,\n}",
+			expected: SchemaErrors{
+				{
+					Line:    3,
+					Column:  1,
+					Message: "invalid character '}' looking for beginning of object key string",
+					Context: "}",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateSchema([]byte(test.data))
+			if test.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Equal(t, test.expected, err)
+		})
+	}
+}
+
+func TestValidateSchemaDefaultConfiguration(t *testing.T) {
+	b, err := json.Marshal(DefaultConfiguration())
+	assert.NoError(t, err)
+	assert.NoError(t, ValidateSchema(b))
+}
+
+func TestSchemaErrorReport(t *testing.T) {
+	err := &SchemaError{
+		Line:    2,
+		Column:  3,
+		Path:    "max_retry",
+		Message: "unknown key \"max_retry\"",
+		Context: "  \"max_retry\": 5,",
+	}
+
+	assert.Equal(t, "line 2, column 3 (max_retry): unknown key \"max_retry\"", err.Error())
+	assert.Equal(
+		t,
+		"line 2, column 3 (max_retry): unknown key \"max_retry\"\n      \"max_retry\": 5,\n      ^",
+		err.Report(),
+	)
+}
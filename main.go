@@ -18,6 +18,7 @@ import (
 	"os"
 
 	"github.com/coinbase/rosetta-cli/cmd"
+	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/fatih/color"
 )
@@ -26,6 +27,6 @@ func main() {
 	err := cmd.Execute()
 	if err != nil {
 		color.Red("Command Failed: %s", err.Error())
-		os.Exit(1)
+		os.Exit(results.ExitCode(err))
 	}
 }
@@ -109,6 +109,9 @@ func (l *Logger) LogDataStatus(ctx context.Context, status *results.CheckDataSta
 		status.Stats.SkippedReconciliations,
 		status.Stats.ReconciliationCoverage*utils.OneHundred,
 	)
+	for _, name := range status.Stats.CustomCounterNames() {
+		statsMessage += fmt.Sprintf(" %s: %d", name, status.Stats.CustomCounters[name])
+	}
 
 	// Don't print out the same stats message twice.
 	if statsMessage == l.lastStatsMessage {
@@ -0,0 +1,110 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// AccountMetadataPreserved returns an error if observed refers to
+// the same account as expected (same address and sub-account) but
+// its metadata was dropped or changed. Accounts that differ in
+// address or sub-account are not considered.
+func AccountMetadataPreserved(expected *types.AccountIdentifier, observed *types.AccountIdentifier) error {
+	if expected == nil || observed == nil {
+		return nil
+	}
+
+	if expected.Address != observed.Address ||
+		types.Hash(expected.SubAccount) != types.Hash(observed.SubAccount) {
+		return nil
+	}
+
+	if len(expected.Metadata) == 0 && len(observed.Metadata) == 0 {
+		return nil
+	}
+
+	if types.Hash(expected.Metadata) == types.Hash(observed.Metadata) {
+		return nil
+	}
+
+	if len(observed.Metadata) == 0 {
+		return fmt.Errorf(
+			"%w: metadata %s of account %s was dropped",
+			results.ErrAccountMetadata,
+			types.PrintStruct(expected.Metadata),
+			expected.Address,
+		)
+	}
+
+	return fmt.Errorf(
+		"%w: metadata of account %s changed from %s to %s",
+		results.ErrAccountMetadata,
+		expected.Address,
+		types.PrintStruct(expected.Metadata),
+		types.PrintStruct(observed.Metadata),
+	)
+}
+
+// AssertAccountMetadata returns an error if the account metadata
+// of any intended operation is not preserved in the observed
+// operation with the same index.
+func AssertAccountMetadata(intent []*types.Operation, observed []*types.Operation) error {
+	observedOps := map[int64]*types.Operation{}
+	for _, op := range observed {
+		observedOps[op.OperationIdentifier.Index] = op
+	}
+
+	for _, op := range intent {
+		observedOp, ok := observedOps[op.OperationIdentifier.Index]
+		if !ok {
+			continue
+		}
+
+		if err := AccountMetadataPreserved(op.Account, observedOp.Account); err != nil {
+			return fmt.Errorf("%w: operation %d", err, op.OperationIdentifier.Index)
+		}
+	}
+
+	return nil
+}
+
+// AssertSignerMetadata returns an error if the account metadata
+// of any expected signer is not preserved in the observed signers.
+func AssertSignerMetadata(expected []*types.AccountIdentifier, observed []*types.AccountIdentifier) error {
+	for _, signer := range observed {
+		var mismatch error
+		for _, expectedSigner := range expected {
+			if types.Hash(expectedSigner) == types.Hash(signer) {
+				mismatch = nil
+				break
+			}
+
+			if err := AccountMetadataPreserved(expectedSigner, signer); err != nil && mismatch == nil {
+				mismatch = err
+			}
+		}
+
+		if mismatch != nil {
+			return fmt.Errorf("%w: signer", mismatch)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountMetadataPreserved(t *testing.T) {
+	withMetadata := &types.AccountIdentifier{
+		Address:  "addr",
+		Metadata: map[string]interface{}{"memo": "1"},
+	}
+
+	var tests = map[string]struct {
+		expected *types.AccountIdentifier
+		observed *types.AccountIdentifier
+
+		err bool
+	}{
+		"no metadata": {
+			expected: &types.AccountIdentifier{Address: "addr"},
+			observed: &types.AccountIdentifier{Address: "addr", Metadata: map[string]interface{}{}},
+		},
+		"metadata preserved": {
+			expected: withMetadata,
+			observed: &types.AccountIdentifier{
+				Address:  "addr",
+				Metadata: map[string]interface{}{"memo": "1"},
+			},
+		},
+		"metadata dropped": {
+			expected: withMetadata,
+			observed: &types.AccountIdentifier{Address: "addr"},
+			err:      true,
+		},
+		"metadata changed": {
+			expected: withMetadata,
+			observed: &types.AccountIdentifier{
+				Address:  "addr",
+				Metadata: map[string]interface{}{"memo": "2"},
+			},
+			err: true,
+		},
+		"different address": {
+			expected: withMetadata,
+			observed: &types.AccountIdentifier{Address: "addr2"},
+		},
+		"different sub account": {
+			expected: withMetadata,
+			observed: &types.AccountIdentifier{
+				Address:    "addr",
+				SubAccount: &types.SubAccountIdentifier{Address: "stake"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := AccountMetadataPreserved(test.expected, test.observed)
+			if test.err {
+				assert.ErrorIs(t, err, results.ErrAccountMetadata)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAssertAccountMetadata(t *testing.T) {
+	sender := &types.AccountIdentifier{
+		Address:  "sender",
+		Metadata: map[string]interface{}{"memo": "1"},
+	}
+	recipient := &types.AccountIdentifier{Address: "recipient"}
+	intent := []*types.Operation{
+		{OperationIdentifier: &types.OperationIdentifier{Index: 0}, Account: sender},
+		{OperationIdentifier: &types.OperationIdentifier{Index: 1}, Account: recipient},
+	}
+
+	assert.NoError(t, AssertAccountMetadata(intent, intent))
+	assert.ErrorIs(t, AssertAccountMetadata(intent, []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Account:             &types.AccountIdentifier{Address: "sender"},
+		},
+		{OperationIdentifier: &types.OperationIdentifier{Index: 1}, Account: recipient},
+	}), results.ErrAccountMetadata)
+
+	assert.NoError(t, AssertSignerMetadata(
+		[]*types.AccountIdentifier{sender},
+		[]*types.AccountIdentifier{sender},
+	))
+	assert.ErrorIs(t, AssertSignerMetadata(
+		[]*types.AccountIdentifier{sender},
+		[]*types.AccountIdentifier{{Address: "sender"}},
+	), results.ErrAccountMetadata)
+}
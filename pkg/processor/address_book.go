@@ -0,0 +1,481 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// addressBookPrefix must not start with any namespace used
+	// by storage modules (ex: "acc"), which are scanned
+	// by prefix.
+	addressBookPrefix = "address_book"
+
+	// addressBookCSVExtension is the extension of address
+	// books written (and read) as CSV. All other address
+	// books are JSON.
+	addressBookCSVExtension = ".csv"
+)
+
+var (
+	_ modules.BlockWorker = (*AddressBookWorker)(nil)
+
+	// addressBookCSVHeader is the header row of
+	// an address book CSV.
+	addressBookCSVHeader = []string{
+		"address",
+		"curve_type",
+		"created_at",
+		"first_funding_transaction",
+		"tags",
+	}
+)
+
+// AddressBookEntry describes an address in the key store
+// so that it can be shared with other runs (and teams).
+type AddressBookEntry struct {
+	Address   string          `json:"address"`
+	CurveType types.CurveType `json:"curve_type,omitempty"`
+
+	// CreatedAt is when the address was created by
+	// check:construction. It is nil for imported and
+	// prefunded addresses.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// FirstFundingTransaction is the hash of the first
+	// confirmed transaction that credited the address.
+	FirstFundingTransaction string `json:"first_funding_transaction,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+}
+
+// addressRecord is what AddressBookStorage stores
+// for each address in the key store.
+type addressRecord struct {
+	CreatedAt               *time.Time `json:"created_at,omitempty"`
+	FirstFundingTransaction string     `json:"first_funding_transaction,omitempty"`
+	FirstFundingBlock       int64      `json:"first_funding_block,omitempty"`
+}
+
+func addressBookKey(account *types.AccountIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", addressBookPrefix, types.Hash(account)))
+}
+
+// AddressBookStorage stores when each address in KeyStorage
+// was created and first funded.
+type AddressBookStorage struct {
+	db database.Database
+}
+
+// NewAddressBookStorage returns a new *AddressBookStorage.
+func NewAddressBookStorage(db database.Database) *AddressBookStorage {
+	return &AddressBookStorage{db: db}
+}
+
+func (a *AddressBookStorage) get(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+) (*addressRecord, error) {
+	exists, val, err := dbTx.Get(ctx, addressBookKey(account))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get address record", err)
+	}
+
+	record := &addressRecord{}
+	if !exists {
+		return record, nil
+	}
+
+	if err := json.Unmarshal(val, record); err != nil {
+		return nil, fmt.Errorf("%w: unable to unmarshal address record", err)
+	}
+
+	return record, nil
+}
+
+func (a *AddressBookStorage) set(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	record *addressRecord,
+) error {
+	val, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal address record", err)
+	}
+
+	return dbTx.Set(ctx, addressBookKey(account), val, false)
+}
+
+// RecordCreated transactionally records that account
+// was created at createdAt (if no creation time was
+// already recorded).
+func (a *AddressBookStorage) RecordCreated(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	createdAt time.Time,
+) error {
+	record, err := a.get(ctx, dbTx, account)
+	if err != nil {
+		return err
+	}
+
+	if record.CreatedAt != nil {
+		return nil
+	}
+
+	createdAt = createdAt.UTC()
+	record.CreatedAt = &createdAt
+	return a.set(ctx, dbTx, account, record)
+}
+
+// Entries returns an *AddressBookEntry for each
+// account in keyStorage (sorted by address).
+func (a *AddressBookStorage) Entries(
+	ctx context.Context,
+	keyStorage *modules.KeyStorage,
+	tagStorage *KeyTagStorage,
+) ([]*AddressBookEntry, error) {
+	dbTx := a.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	accounts, err := keyStorage.GetAllAccountsTransactional(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	entries := make([]*AddressBookEntry, len(accounts))
+	for i, account := range accounts {
+		keyPair, err := keyStorage.GetTransactional(ctx, dbTx, account)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get key of %s", err, account.Address)
+		}
+
+		record, err := a.get(ctx, dbTx, account)
+		if err != nil {
+			return nil, err
+		}
+
+		tags, err := tagStorage.GetTags(ctx, dbTx, account)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = &AddressBookEntry{
+			Address:                 account.Address,
+			CreatedAt:               record.CreatedAt,
+			FirstFundingTransaction: record.FirstFundingTransaction,
+			Tags:                    tags,
+		}
+		if keyPair.PublicKey != nil {
+			entries[i].CurveType = keyPair.PublicKey.CurveType
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Address < entries[j].Address
+	})
+
+	return entries, nil
+}
+
+// AddressBookWorker records the first confirmed transaction
+// crediting each address in KeyStorage.
+type AddressBookWorker struct {
+	asserter    *asserter.Asserter
+	keyStorage  *modules.KeyStorage
+	addressBook *AddressBookStorage
+}
+
+// NewAddressBookWorker returns a new *AddressBookWorker.
+func NewAddressBookWorker(
+	asserter *asserter.Asserter,
+	keyStorage *modules.KeyStorage,
+	addressBook *AddressBookStorage,
+) *AddressBookWorker {
+	return &AddressBookWorker{
+		asserter:    asserter,
+		keyStorage:  keyStorage,
+		addressBook: addressBook,
+	}
+}
+
+// fundedAccounts returns the first transaction in block
+// successfully crediting each of accounts (keyed by the
+// hash of the account).
+func (w *AddressBookWorker) fundedAccounts(
+	block *types.Block,
+	accounts []*types.AccountIdentifier,
+) (map[string]*types.TransactionIdentifier, error) {
+	tracked := map[string]struct{}{}
+	for _, account := range accounts {
+		tracked[types.Hash(account)] = struct{}{}
+	}
+
+	funded := map[string]*types.TransactionIdentifier{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			key := types.Hash(op.Account)
+			if _, ok := tracked[key]; !ok {
+				continue
+			}
+
+			if _, ok := funded[key]; ok {
+				continue
+			}
+
+			successful, err := w.asserter.OperationSuccessful(op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to check operation status", err)
+			}
+
+			value, err := types.AmountValue(op.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+			}
+
+			if successful && value.Sign() > 0 {
+				funded[key] = tx.TransactionIdentifier
+			}
+		}
+	}
+
+	return funded, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *AddressBookWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	if len(block.Transactions) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := w.keyStorage.GetAllAccountsTransactional(ctx, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get generated accounts", err)
+	}
+
+	funded, err := w.fundedAccounts(block, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		tx, ok := funded[types.Hash(account)]
+		if !ok {
+			continue
+		}
+
+		record, err := w.addressBook.get(ctx, transaction, account)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record.FirstFundingTransaction) > 0 {
+			continue
+		}
+
+		record.FirstFundingTransaction = tx.Hash
+		record.FirstFundingBlock = block.BlockIdentifier.Index
+		if err := w.addressBook.set(ctx, transaction, account, record); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+// Funding recorded in an orphaned block is cleared so that it can
+// be recorded again when the funding transaction is re-included.
+func (w *AddressBookWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	if len(block.Transactions) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := w.keyStorage.GetAllAccountsTransactional(ctx, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get generated accounts", err)
+	}
+
+	for _, account := range accounts {
+		record, err := w.addressBook.get(ctx, transaction, account)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record.FirstFundingTransaction) == 0 ||
+			record.FirstFundingBlock != block.BlockIdentifier.Index {
+			continue
+		}
+
+		record.FirstFundingTransaction = ""
+		record.FirstFundingBlock = 0
+		if err := w.addressBook.set(ctx, transaction, account, record); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// WriteAddressBook writes entries to filePath as CSV (if
+// filePath ends in .csv) or JSON. Tags are separated by
+// semicolons in a CSV.
+func WriteAddressBook(filePath string, entries []*AddressBookEntry) error {
+	if !strings.EqualFold(path.Ext(filePath), addressBookCSVExtension) {
+		return utils.SerializeAndWrite(filePath, entries)
+	}
+
+	f, err := os.Create(path.Clean(filePath))
+	if err != nil {
+		return fmt.Errorf("%w: unable to create address book", err)
+	}
+	defer f.Close()
+
+	writer := csv.NewWriter(f)
+	if err := writer.Write(addressBookCSVHeader); err != nil {
+		return fmt.Errorf("%w: unable to write header", err)
+	}
+
+	for _, entry := range entries {
+		createdAt := ""
+		if entry.CreatedAt != nil {
+			createdAt = entry.CreatedAt.Format(time.RFC3339)
+		}
+
+		if err := writer.Write([]string{
+			entry.Address,
+			string(entry.CurveType),
+			createdAt,
+			entry.FirstFundingTransaction,
+			strings.Join(entry.Tags, keyCSVTagSeparator),
+		}); err != nil {
+			return fmt.Errorf("%w: unable to write %s", err, entry.Address)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// LoadAddressBook loads an address book written
+// by WriteAddressBook.
+func LoadAddressBook(filePath string) ([]*AddressBookEntry, error) {
+	if !strings.EqualFold(path.Ext(filePath), addressBookCSVExtension) {
+		entries := []*AddressBookEntry{}
+		if err := utils.LoadAndParse(filePath, &entries); err != nil {
+			return nil, fmt.Errorf("%w: unable to load address book", err)
+		}
+
+		for i, entry := range entries {
+			if len(entry.Address) == 0 {
+				return nil, fmt.Errorf("entry %d has an empty address", i)
+			}
+
+			if entry.Tags == nil {
+				entry.Tags = []string{}
+			}
+		}
+
+		return entries, nil
+	}
+
+	f, err := os.Open(path.Clean(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open address book", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = len(addressBookCSVHeader)
+	reader.TrimLeadingSpace = true
+
+	entries := []*AddressBookEntry{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read row %d", err, row)
+		}
+
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), addressBookCSVHeader[0]) {
+			continue
+		}
+
+		entry := &AddressBookEntry{
+			Address:                 strings.TrimSpace(record[0]),
+			CurveType:               types.CurveType(strings.TrimSpace(record[1])),
+			FirstFundingTransaction: strings.TrimSpace(record[3]),
+			Tags:                    []string{},
+		}
+		if len(entry.Address) == 0 {
+			return nil, fmt.Errorf("row %d has an empty address", row)
+		}
+
+		if createdAt := strings.TrimSpace(record[2]); len(createdAt) > 0 {
+			t, err := time.Parse(time.RFC3339, createdAt)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid created_at in row %d", err, row)
+			}
+			entry.CreatedAt = &t
+		}
+
+		for _, tag := range strings.Split(record[4], keyCSVTagSeparator) {
+			if tag = strings.TrimSpace(tag); len(tag) > 0 {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressBookWorker(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	keyStorage := modules.NewKeyStorage(db)
+	tagStorage := NewKeyTagStorage(db)
+	addressBook := NewAddressBookStorage(db)
+	worker := NewAddressBookWorker(a, keyStorage, addressBook)
+
+	created := time.Unix(1600000000, 0).UTC()
+	account := &types.AccountIdentifier{Address: "addr1"}
+	keyPair, err := keys.GenerateKeypair(types.Edwards25519)
+	assert.NoError(t, err)
+
+	dbTx := db.Transaction(ctx)
+	assert.NoError(t, keyStorage.StoreTransactional(ctx, account, keyPair, dbTx))
+	assert.NoError(t, addressBook.RecordCreated(ctx, dbTx, account, created))
+	assert.NoError(t, addressBook.RecordCreated(ctx, dbTx, account, created.Add(time.Hour)))
+	assert.NoError(t, tagStorage.SetTags(ctx, dbTx, account, []string{"faucet"}))
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	block := func(index int64, hash string, value string) *types.Block {
+		return &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{Index: index, Hash: hash},
+			Transactions: []*types.Transaction{
+				{
+					TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+					Operations: []*types.Operation{
+						{
+							OperationIdentifier: &types.OperationIdentifier{Index: 0},
+							Type:                "Transfer",
+							Status:              types.String("Success"),
+							Account:             account,
+							Amount: &types.Amount{
+								Value:    value,
+								Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	apply := func(f func(
+		context.Context,
+		database.Transaction,
+	) error) {
+		dbTx := db.Transaction(ctx)
+		assert.NoError(t, f(ctx, dbTx))
+		assert.NoError(t, dbTx.Commit(ctx))
+	}
+
+	entries := func() []*AddressBookEntry {
+		entries, err := addressBook.Entries(ctx, keyStorage, tagStorage)
+		assert.NoError(t, err)
+		return entries
+	}
+
+	// Debits are not funding
+	apply(func(ctx context.Context, dbTx database.Transaction) error {
+		_, err := worker.AddingBlock(ctx, nil, block(1, "tx1", "-10"), dbTx)
+		return err
+	})
+	assert.Equal(t, []*AddressBookEntry{
+		{
+			Address:   "addr1",
+			CurveType: types.Edwards25519,
+			CreatedAt: &created,
+			Tags:      []string{"faucet"},
+		},
+	}, entries())
+
+	// Only the first funding transaction is recorded
+	apply(func(ctx context.Context, dbTx database.Transaction) error {
+		_, err := worker.AddingBlock(ctx, nil, block(2, "tx2", "10"), dbTx)
+		return err
+	})
+	apply(func(ctx context.Context, dbTx database.Transaction) error {
+		_, err := worker.AddingBlock(ctx, nil, block(3, "tx3", "10"), dbTx)
+		return err
+	})
+	assert.Equal(t, "tx2", entries()[0].FirstFundingTransaction)
+
+	// Orphaning a later block does not clear funding
+	apply(func(ctx context.Context, dbTx database.Transaction) error {
+		_, err := worker.RemovingBlock(ctx, nil, block(3, "tx3", "10"), dbTx)
+		return err
+	})
+	assert.Equal(t, "tx2", entries()[0].FirstFundingTransaction)
+
+	// Orphaning the funding block does
+	apply(func(ctx context.Context, dbTx database.Transaction) error {
+		_, err := worker.RemovingBlock(ctx, nil, block(2, "tx2", "10"), dbTx)
+		return err
+	})
+	assert.Equal(t, "", entries()[0].FirstFundingTransaction)
+
+	apply(func(ctx context.Context, dbTx database.Transaction) error {
+		_, err := worker.AddingBlock(ctx, nil, block(2, "tx4", "10"), dbTx)
+		return err
+	})
+	assert.Equal(t, "tx4", entries()[0].FirstFundingTransaction)
+}
+
+func TestAddressBookFiles(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	created := time.Unix(1600000000, 0).UTC()
+	entries := []*AddressBookEntry{
+		{
+			Address:                 "addr1",
+			CurveType:               types.Secp256k1,
+			CreatedAt:               &created,
+			FirstFundingTransaction: "tx1",
+			Tags:                    []string{"faucet", "hot"},
+		},
+		{
+			Address:   "addr2",
+			CurveType: types.Edwards25519,
+			Tags:      []string{},
+		},
+	}
+
+	for _, file := range []string{"book.csv", "book.json"} {
+		t.Run(file, func(t *testing.T) {
+			filePath := path.Join(dir, file)
+			assert.NoError(t, WriteAddressBook(filePath, entries))
+
+			loaded, err := LoadAddressBook(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, entries, loaded)
+		})
+	}
+
+	_, err = LoadAddressBook(path.Join(dir, "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestAddressBookRecipients(t *testing.T) {
+	tagged := &types.AccountIdentifier{Address: "tagged"}
+	p := &SenderPipelines{
+		config: &configuration.SenderPipelineConfiguration{
+			RecipientTags: []string{"shared"},
+		},
+		claimed: map[string]struct{}{
+			types.Hash(&types.AccountIdentifier{Address: "claimed"}): {},
+		},
+		addressBook: []*AddressBookEntry{
+			{Address: "tagged", Tags: []string{"shared"}},
+			{Address: "untagged"},
+			{Address: "known", Tags: []string{"shared"}},
+			{Address: "locked", Tags: []string{"shared"}},
+			{Address: "claimed", Tags: []string{"shared"}},
+		},
+	}
+
+	known := map[string]struct{}{
+		types.Hash(&types.AccountIdentifier{Address: "known"}): {},
+	}
+	locked := map[string]struct{}{
+		types.Hash(&types.AccountIdentifier{Address: "locked"}): {},
+	}
+	assert.Equal(
+		t,
+		[]*types.AccountIdentifier{tagged},
+		p.addressBookRecipients(known, locked),
+	)
+}
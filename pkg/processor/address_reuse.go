@@ -0,0 +1,153 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// addressReusePrefix must not start with any namespace used
+// by storage modules (ex: "acc"), which are scanned
+// by prefix.
+const addressReusePrefix = "address_reuse"
+
+// addressUses is what AddressReuseTracker stores
+// for each account used in a broadcast.
+type addressUses struct {
+	Account *types.AccountIdentifier `json:"account"`
+	Uses    int                      `json:"uses"`
+}
+
+func addressReuseKey(account *types.AccountIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", addressReusePrefix, types.Hash(account)))
+}
+
+// AddressReuseTracker counts the broadcast transactions
+// each account sends or receives funds in and excludes
+// accounts that reach a maximum number of uses.
+type AddressReuseTracker struct {
+	maxReuse int
+}
+
+// NewAddressReuseTracker returns a new *AddressReuseTracker
+// (nil if maxReuse is 0).
+func NewAddressReuseTracker(maxReuse int) *AddressReuseTracker {
+	if maxReuse == 0 {
+		return nil
+	}
+
+	return &AddressReuseTracker{maxReuse: maxReuse}
+}
+
+// IntentAccounts returns the distinct accounts of
+// all operations in intent (in the order they first
+// appear).
+func IntentAccounts(intent []*types.Operation) []*types.AccountIdentifier {
+	seen := map[string]struct{}{}
+	accounts := []*types.AccountIdentifier{}
+	for _, op := range intent {
+		if op.Account == nil {
+			continue
+		}
+
+		key := types.Hash(op.Account)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		accounts = append(accounts, op.Account)
+	}
+
+	return accounts
+}
+
+// RecordUses transactionally records a use of each account
+// in intent and returns the accounts that reached the
+// maximum number of uses (and are now excluded).
+func (t *AddressReuseTracker) RecordUses(
+	ctx context.Context,
+	dbTx database.Transaction,
+	intent []*types.Operation,
+) ([]*types.AccountIdentifier, error) {
+	exhausted := []*types.AccountIdentifier{}
+	for _, account := range IntentAccounts(intent) {
+		key := addressReuseKey(account)
+		exists, val, err := dbTx.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get uses of %s", err, account.Address)
+		}
+
+		record := &addressUses{Account: account}
+		if exists {
+			if err := json.Unmarshal(val, record); err != nil {
+				return nil, fmt.Errorf("%w: unable to unmarshal address uses", err)
+			}
+		}
+
+		record.Uses++
+		val, err = json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to marshal address uses", err)
+		}
+
+		if err := dbTx.Set(ctx, key, val, false); err != nil {
+			return nil, fmt.Errorf("%w: unable to store uses of %s", err, account.Address)
+		}
+
+		if record.Uses == t.maxReuse {
+			exhausted = append(exhausted, account)
+		}
+	}
+
+	return exhausted, nil
+}
+
+// ExcludedAccounts returns all accounts that have reached
+// the maximum number of uses.
+func (t *AddressReuseTracker) ExcludedAccounts(
+	ctx context.Context,
+	dbTx database.Transaction,
+) ([]*types.AccountIdentifier, error) {
+	excluded := []*types.AccountIdentifier{}
+	if _, err := dbTx.Scan(
+		ctx,
+		[]byte(addressReusePrefix+"/"),
+		[]byte(addressReusePrefix+"/"),
+		func(k []byte, v []byte) error {
+			record := &addressUses{}
+			if err := json.Unmarshal(v, record); err != nil {
+				return fmt.Errorf("%w: unable to unmarshal address uses", err)
+			}
+
+			if record.Uses >= t.maxReuse {
+				excluded = append(excluded, record.Account)
+			}
+
+			return nil
+		},
+		false,
+		false,
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to scan address uses", err)
+	}
+
+	return excluded, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressReuseTracker(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Nil(t, NewAddressReuseTracker(0))
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	sender := &types.AccountIdentifier{Address: "sender"}
+	recipient := &types.AccountIdentifier{Address: "recipient"}
+	other := &types.AccountIdentifier{Address: "other"}
+	transfer := func(from *types.AccountIdentifier, to *types.AccountIdentifier) []*types.Operation {
+		return []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Account:             from,
+				Amount:              &types.Amount{Value: "-10"},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 1},
+				Account:             to,
+				Amount:              &types.Amount{Value: "5"},
+			},
+			{
+				// Change does not count as another use
+				OperationIdentifier: &types.OperationIdentifier{Index: 2},
+				Account:             from,
+				Amount:              &types.Amount{Value: "4"},
+			},
+		}
+	}
+
+	tracker := NewAddressReuseTracker(2)
+	record := func(intent []*types.Operation) []*types.AccountIdentifier {
+		dbTx := db.Transaction(ctx)
+		defer dbTx.Discard(ctx)
+
+		exhausted, err := tracker.RecordUses(ctx, dbTx, intent)
+		assert.NoError(t, err)
+		assert.NoError(t, dbTx.Commit(ctx))
+
+		return exhausted
+	}
+
+	excluded := func() []*types.AccountIdentifier {
+		dbTx := db.ReadTransaction(ctx)
+		defer dbTx.Discard(ctx)
+
+		accounts, err := tracker.ExcludedAccounts(ctx, dbTx)
+		assert.NoError(t, err)
+
+		return accounts
+	}
+
+	assert.Equal(t, []*types.AccountIdentifier{sender, recipient}, IntentAccounts(transfer(sender, recipient)))
+
+	assert.Empty(t, record(transfer(sender, recipient)))
+	assert.Empty(t, excluded())
+
+	assert.Equal(t, []*types.AccountIdentifier{sender}, record(transfer(sender, other)))
+	assert.Equal(t, []*types.AccountIdentifier{sender}, excluded())
+
+	// Accounts are only reported as exhausted once
+	assert.Equal(t, []*types.AccountIdentifier{recipient}, record(transfer(sender, recipient)))
+	assert.ElementsMatch(t, []*types.AccountIdentifier{sender, recipient}, excluded())
+}
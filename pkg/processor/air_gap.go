@@ -0,0 +1,829 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Methods that can be requested from the offline-agent. Each
+// construction method accepts and returns the request and
+// response of the Construction API endpoint of the same name.
+const (
+	AirGapDerive      = "derive"
+	AirGapPreprocess  = "preprocess"
+	AirGapPayloads    = "payloads"
+	AirGapParse       = "parse"
+	AirGapCombine     = "combine"
+	AirGapHash        = "hash"
+	AirGapSign        = "sign"
+	AirGapGenerateKey = "generate_key"
+
+	airGapRequestSuffix  = ".request"
+	airGapResponseSuffix = ".response"
+	airGapTempSuffix     = ".tmp"
+
+	// airGapPollInterval is how often the exchange
+	// directory is checked for new files.
+	airGapPollInterval = 100 * time.Millisecond
+)
+
+// base32Encoding only uses characters supported by the
+// alphanumeric mode of QR codes.
+var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// OfflineConstructor calls the offline Construction API
+// endpoints. *fetcher.Fetcher calls them on the offline node.
+type OfflineConstructor interface {
+	ConstructionDerive(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		publicKey *types.PublicKey,
+		metadata map[string]interface{},
+	) (*types.AccountIdentifier, map[string]interface{}, *fetcher.Error)
+
+	ConstructionPreprocess(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		intent []*types.Operation,
+		metadata map[string]interface{},
+	) (map[string]interface{}, []*types.AccountIdentifier, *fetcher.Error)
+
+	ConstructionPayloads(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		operations []*types.Operation,
+		metadata map[string]interface{},
+		publicKeys []*types.PublicKey,
+	) (string, []*types.SigningPayload, *fetcher.Error)
+
+	ConstructionParse(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		signed bool,
+		transaction string,
+	) ([]*types.Operation, []*types.AccountIdentifier, map[string]interface{}, *fetcher.Error)
+
+	ConstructionCombine(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		unsignedTransaction string,
+		signatures []*types.Signature,
+	) (string, *fetcher.Error)
+
+	ConstructionHash(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		networkTransaction string,
+	) (*types.TransactionIdentifier, *fetcher.Error)
+}
+
+var _ OfflineConstructor = (*fetcher.Fetcher)(nil)
+
+// AirGapRequest is written to the exchange directory by
+// check:construction for the offline-agent.
+type AirGapRequest struct {
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// AirGapResponse is written to the exchange directory by
+// the offline-agent in response to an AirGapRequest.
+type AirGapResponse struct {
+	ID     string          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// AirGapGenerateKeyRequest asks the offline-agent to generate,
+// derive, and store a new key.
+type AirGapGenerateKeyRequest struct {
+	NetworkIdentifier *types.NetworkIdentifier `json:"network_identifier"`
+	CurveType         types.CurveType          `json:"curve_type"`
+	Metadata          map[string]interface{}   `json:"metadata,omitempty"`
+}
+
+// AirGapGenerateKeyResponse is the account derived from a key
+// generated by the offline-agent (the private key never leaves
+// the offline-agent).
+type AirGapGenerateKeyResponse struct {
+	AccountIdentifier *types.AccountIdentifier `json:"account_identifier"`
+	PublicKey         *types.PublicKey         `json:"public_key"`
+}
+
+// EncodeAirGapMessage encodes a request or response
+// with encoding.
+func EncodeAirGapMessage(
+	encoding configuration.AirGapEncoding,
+	message interface{},
+) ([]byte, error) {
+	raw, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to marshal message", err)
+	}
+
+	switch encoding {
+	case configuration.JSONAirGapEncoding:
+		return raw, nil
+	case configuration.Base32AirGapEncoding:
+		return []byte(base32Encoding.EncodeToString(raw)), nil
+	default:
+		return nil, fmt.Errorf("encoding %s is not supported", encoding)
+	}
+}
+
+// DecodeAirGapMessage decodes a request or response encoded
+// with encoding into message. Surrounding whitespace (ex: added
+// when a message is transcribed from a QR code) is ignored.
+func DecodeAirGapMessage(
+	encoding configuration.AirGapEncoding,
+	data []byte,
+	message interface{},
+) error {
+	raw := []byte(strings.TrimSpace(string(data)))
+	switch encoding {
+	case configuration.JSONAirGapEncoding:
+	case configuration.Base32AirGapEncoding:
+		decoded, err := base32Encoding.DecodeString(string(raw))
+		if err != nil {
+			return fmt.Errorf("%w: unable to decode base32 message", err)
+		}
+
+		raw = decoded
+	default:
+		return fmt.Errorf("encoding %s is not supported", encoding)
+	}
+
+	if err := json.Unmarshal(raw, message); err != nil {
+		return fmt.Errorf("%w: unable to unmarshal message", err)
+	}
+
+	return nil
+}
+
+// writeAirGapMessage atomically writes an encoded message to
+// filePath so that a partially written file is never read.
+func writeAirGapMessage(
+	encoding configuration.AirGapEncoding,
+	filePath string,
+	message interface{},
+) error {
+	data, err := EncodeAirGapMessage(encoding, message)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filePath + airGapTempSuffix
+	if err := ioutil.WriteFile(tmpPath, data, os.FileMode(0600)); err != nil {
+		return fmt.Errorf("%w: unable to write %s", err, tmpPath)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("%w: unable to rename %s", err, tmpPath)
+	}
+
+	return nil
+}
+
+var (
+	_ OfflineConstructor = (*AirGapClient)(nil)
+	_ Signer             = (*AirGapClient)(nil)
+)
+
+// AirGapClient is used by check:construction to request
+// offline construction, signing, and key generation from
+// the offline-agent.
+type AirGapClient struct {
+	directory string
+	encoding  configuration.AirGapEncoding
+	timeout   time.Duration
+
+	// session prefixes the ID of each request so that
+	// requests from different runs never collide.
+	session int64
+
+	// requests is the number of requests sent. It is
+	// accessed atomically.
+	requests uint64
+}
+
+// NewAirGapClient returns a new *AirGapClient and
+// creates the exchange directory if it does not exist.
+func NewAirGapClient(config *configuration.AirGapConfiguration) (*AirGapClient, error) {
+	if err := os.MkdirAll(config.ExchangeDirectory, os.FileMode(0700)); err != nil {
+		return nil, fmt.Errorf("%w: unable to create exchange directory", err)
+	}
+
+	return &AirGapClient{
+		directory: config.ExchangeDirectory,
+		encoding:  config.Encoding,
+		timeout:   time.Duration(config.Timeout) * time.Second,
+		session:   time.Now().UnixNano(),
+	}, nil
+}
+
+// call writes a request for method and waits for the
+// offline-agent to write its response.
+func (c *AirGapClient) call(
+	ctx context.Context,
+	method string,
+	params interface{},
+	result interface{},
+) error {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal %s params", err, method)
+	}
+
+	id := fmt.Sprintf("%d-%06d", c.session, atomic.AddUint64(&c.requests, 1))
+	requestPath := path.Join(c.directory, id+airGapRequestSuffix)
+	responsePath := path.Join(c.directory, id+airGapResponseSuffix)
+	if err := writeAirGapMessage(c.encoding, requestPath, &AirGapRequest{
+		ID:     id,
+		Method: method,
+		Params: rawParams,
+	}); err != nil {
+		return err
+	}
+	defer os.Remove(requestPath)
+
+	timeout := time.NewTimer(c.timeout)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(airGapPollInterval)
+	defer ticker.Stop()
+
+	for {
+		data, err := ioutil.ReadFile(responsePath) // #nosec G304
+		if err == nil {
+			_ = os.Remove(responsePath)
+			return c.handleResponse(id, method, data, result)
+		}
+
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("%w: unable to read %s", err, responsePath)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C:
+			return fmt.Errorf(
+				"%w: no response to %s request %s after %s",
+				results.ErrAirGap,
+				method,
+				id,
+				c.timeout,
+			)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *AirGapClient) handleResponse(
+	id string,
+	method string,
+	data []byte,
+	result interface{},
+) error {
+	var response AirGapResponse
+	if err := DecodeAirGapMessage(c.encoding, data, &response); err != nil {
+		return fmt.Errorf("%w: unable to decode %s response %s", err, method, id)
+	}
+
+	if response.ID != id {
+		return fmt.Errorf(
+			"%w: %s response has id %s (expected %s)",
+			results.ErrAirGap,
+			method,
+			response.ID,
+			id,
+		)
+	}
+
+	if len(response.Error) > 0 {
+		return fmt.Errorf("%w: %s: %s", results.ErrAirGap, method, response.Error)
+	}
+
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("%w: unable to unmarshal %s result", err, method)
+	}
+
+	return nil
+}
+
+// ConstructionDerive requests /construction/derive from the offline-agent.
+func (c *AirGapClient) ConstructionDerive(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	publicKey *types.PublicKey,
+	metadata map[string]interface{},
+) (*types.AccountIdentifier, map[string]interface{}, *fetcher.Error) {
+	var response types.ConstructionDeriveResponse
+	if err := c.call(ctx, AirGapDerive, &types.ConstructionDeriveRequest{
+		NetworkIdentifier: network,
+		PublicKey:         publicKey,
+		Metadata:          metadata,
+	}, &response); err != nil {
+		return nil, nil, &fetcher.Error{Err: err}
+	}
+
+	return response.AccountIdentifier, response.Metadata, nil
+}
+
+// ConstructionPreprocess requests /construction/preprocess
+// from the offline-agent.
+func (c *AirGapClient) ConstructionPreprocess(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	intent []*types.Operation,
+	metadata map[string]interface{},
+) (map[string]interface{}, []*types.AccountIdentifier, *fetcher.Error) {
+	var response types.ConstructionPreprocessResponse
+	if err := c.call(ctx, AirGapPreprocess, &types.ConstructionPreprocessRequest{
+		NetworkIdentifier: network,
+		Operations:        intent,
+		Metadata:          metadata,
+	}, &response); err != nil {
+		return nil, nil, &fetcher.Error{Err: err}
+	}
+
+	return response.Options, response.RequiredPublicKeys, nil
+}
+
+// ConstructionPayloads requests /construction/payloads
+// from the offline-agent.
+func (c *AirGapClient) ConstructionPayloads(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	operations []*types.Operation,
+	metadata map[string]interface{},
+	publicKeys []*types.PublicKey,
+) (string, []*types.SigningPayload, *fetcher.Error) {
+	var response types.ConstructionPayloadsResponse
+	if err := c.call(ctx, AirGapPayloads, &types.ConstructionPayloadsRequest{
+		NetworkIdentifier: network,
+		Operations:        operations,
+		Metadata:          metadata,
+		PublicKeys:        publicKeys,
+	}, &response); err != nil {
+		return "", nil, &fetcher.Error{Err: err}
+	}
+
+	return response.UnsignedTransaction, response.Payloads, nil
+}
+
+// ConstructionParse requests /construction/parse from the offline-agent.
+func (c *AirGapClient) ConstructionParse(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	signed bool,
+	transaction string,
+) ([]*types.Operation, []*types.AccountIdentifier, map[string]interface{}, *fetcher.Error) {
+	var response types.ConstructionParseResponse
+	if err := c.call(ctx, AirGapParse, &types.ConstructionParseRequest{
+		NetworkIdentifier: network,
+		Signed:            signed,
+		Transaction:       transaction,
+	}, &response); err != nil {
+		return nil, nil, nil, &fetcher.Error{Err: err}
+	}
+
+	return response.Operations, response.AccountIdentifierSigners, response.Metadata, nil
+}
+
+// ConstructionCombine requests /construction/combine from the offline-agent.
+func (c *AirGapClient) ConstructionCombine(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	unsignedTransaction string,
+	signatures []*types.Signature,
+) (string, *fetcher.Error) {
+	var response types.ConstructionCombineResponse
+	if err := c.call(ctx, AirGapCombine, &types.ConstructionCombineRequest{
+		NetworkIdentifier:   network,
+		UnsignedTransaction: unsignedTransaction,
+		Signatures:          signatures,
+	}, &response); err != nil {
+		return "", &fetcher.Error{Err: err}
+	}
+
+	return response.SignedTransaction, nil
+}
+
+// ConstructionHash requests /construction/hash from the offline-agent.
+func (c *AirGapClient) ConstructionHash(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	networkTransaction string,
+) (*types.TransactionIdentifier, *fetcher.Error) {
+	var response types.TransactionIdentifierResponse
+	if err := c.call(ctx, AirGapHash, &types.ConstructionHashRequest{
+		NetworkIdentifier: network,
+		SignedTransaction: networkTransaction,
+	}, &response); err != nil {
+		return nil, &fetcher.Error{Err: err}
+	}
+
+	return response.TransactionIdentifier, nil
+}
+
+// Sign requests a signature for each payload from the
+// offline-agent (which signs with the keys it stores).
+func (c *AirGapClient) Sign(
+	ctx context.Context,
+	payloads []*types.SigningPayload,
+) ([]*types.Signature, error) {
+	var response RemoteSignResponse
+	if err := c.call(ctx, AirGapSign, &RemoteSignRequest{
+		Payloads: payloads,
+	}, &response); err != nil {
+		return nil, err
+	}
+
+	// The offline-agent only signs with the keys it stores, so
+	// it cannot sign with the key of a different account.
+	if err := assertSignatures(ctx, nil, payloads, response.Signatures); err != nil {
+		return nil, fmt.Errorf("%w: offline-agent returned invalid signatures", err)
+	}
+
+	return response.Signatures, nil
+}
+
+// GenerateKey requests a new key of curveType from the
+// offline-agent and returns the account derived from it
+// and its public key.
+func (c *AirGapClient) GenerateKey(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	curveType types.CurveType,
+	metadata map[string]interface{},
+) (*types.AccountIdentifier, *types.PublicKey, error) {
+	var response AirGapGenerateKeyResponse
+	if err := c.call(ctx, AirGapGenerateKey, &AirGapGenerateKeyRequest{
+		NetworkIdentifier: network,
+		CurveType:         curveType,
+		Metadata:          metadata,
+	}, &response); err != nil {
+		return nil, nil, err
+	}
+
+	return response.AccountIdentifier, response.PublicKey, nil
+}
+
+// AirGapAgent responds to the requests written by
+// check:construction using only the offline node and
+// the keys it stores.
+type AirGapAgent struct {
+	directory      string
+	encoding       configuration.AirGapEncoding
+	offlineFetcher OfflineConstructor
+	keyStorage     *modules.KeyStorage
+}
+
+// NewAirGapAgent returns a new *AirGapAgent.
+func NewAirGapAgent(
+	config *configuration.AirGapConfiguration,
+	offlineFetcher OfflineConstructor,
+	keyStorage *modules.KeyStorage,
+) *AirGapAgent {
+	return &AirGapAgent{
+		directory:      config.ExchangeDirectory,
+		encoding:       config.Encoding,
+		offlineFetcher: offlineFetcher,
+		keyStorage:     keyStorage,
+	}
+}
+
+// Run responds to requests until ctx is canceled.
+func (a *AirGapAgent) Run(ctx context.Context) error {
+	ticker := time.NewTicker(airGapPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.ProcessRequests(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessRequests responds to all requests in the exchange
+// directory that don't yet have a response (in the order
+// they were sent) and returns the number of responses
+// written.
+func (a *AirGapAgent) ProcessRequests(ctx context.Context) (int, error) {
+	files, err := ioutil.ReadDir(a.directory)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to read exchange directory", err)
+	}
+
+	names := []string{}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), airGapRequestSuffix) {
+			names = append(names, file.Name())
+		}
+	}
+	sort.Strings(names)
+
+	processed := 0
+	for _, name := range names {
+		id := strings.TrimSuffix(name, airGapRequestSuffix)
+		responsePath := path.Join(a.directory, id+airGapResponseSuffix)
+		if _, err := os.Stat(responsePath); err == nil {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(path.Join(a.directory, name)) // #nosec G304
+		if os.IsNotExist(err) {
+			// The request was removed after we listed
+			// the directory (ex: it timed out).
+			continue
+		}
+		if err != nil {
+			return processed, fmt.Errorf("%w: unable to read request %s", err, id)
+		}
+
+		response := &AirGapResponse{ID: id}
+		var request AirGapRequest
+		if err := DecodeAirGapMessage(a.encoding, data, &request); err != nil {
+			response.Error = err.Error()
+		} else {
+			response = a.Handle(ctx, &request)
+		}
+
+		if err := writeAirGapMessage(a.encoding, responsePath, response); err != nil {
+			return processed, err
+		}
+
+		processed++
+	}
+
+	return processed, nil
+}
+
+// Handle returns the response to a request.
+func (a *AirGapAgent) Handle(ctx context.Context, request *AirGapRequest) *AirGapResponse {
+	response := &AirGapResponse{ID: request.ID}
+	result, err := a.handle(ctx, request)
+	if err != nil {
+		log.Printf("offline-agent %s request %s failed: %s\n", request.Method, request.ID, err.Error())
+		response.Error = err.Error()
+		return response
+	}
+
+	rawResult, err := json.Marshal(result)
+	if err != nil {
+		response.Error = fmt.Sprintf("unable to marshal result: %s", err.Error())
+		return response
+	}
+
+	response.Result = rawResult
+	return response
+}
+
+func (a *AirGapAgent) handle( // nolint:gocognit
+	ctx context.Context,
+	request *AirGapRequest,
+) (interface{}, error) {
+	switch request.Method {
+	case AirGapDerive:
+		var params types.ConstructionDeriveRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		account, metadata, fetchErr := a.offlineFetcher.ConstructionDerive(
+			ctx,
+			params.NetworkIdentifier,
+			params.PublicKey,
+			params.Metadata,
+		)
+		if fetchErr != nil {
+			return nil, fetchErr.Err
+		}
+
+		return &types.ConstructionDeriveResponse{
+			AccountIdentifier: account,
+			Metadata:          metadata,
+		}, nil
+	case AirGapPreprocess:
+		var params types.ConstructionPreprocessRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		options, requiredPublicKeys, fetchErr := a.offlineFetcher.ConstructionPreprocess(
+			ctx,
+			params.NetworkIdentifier,
+			params.Operations,
+			params.Metadata,
+		)
+		if fetchErr != nil {
+			return nil, fetchErr.Err
+		}
+
+		return &types.ConstructionPreprocessResponse{
+			Options:            options,
+			RequiredPublicKeys: requiredPublicKeys,
+		}, nil
+	case AirGapPayloads:
+		var params types.ConstructionPayloadsRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		unsignedTransaction, payloads, fetchErr := a.offlineFetcher.ConstructionPayloads(
+			ctx,
+			params.NetworkIdentifier,
+			params.Operations,
+			params.Metadata,
+			params.PublicKeys,
+		)
+		if fetchErr != nil {
+			return nil, fetchErr.Err
+		}
+
+		return &types.ConstructionPayloadsResponse{
+			UnsignedTransaction: unsignedTransaction,
+			Payloads:            payloads,
+		}, nil
+	case AirGapParse:
+		var params types.ConstructionParseRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		operations, signers, metadata, fetchErr := a.offlineFetcher.ConstructionParse(
+			ctx,
+			params.NetworkIdentifier,
+			params.Signed,
+			params.Transaction,
+		)
+		if fetchErr != nil {
+			return nil, fetchErr.Err
+		}
+
+		return &types.ConstructionParseResponse{
+			Operations:               operations,
+			AccountIdentifierSigners: signers,
+			Metadata:                 metadata,
+		}, nil
+	case AirGapCombine:
+		var params types.ConstructionCombineRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		signedTransaction, fetchErr := a.offlineFetcher.ConstructionCombine(
+			ctx,
+			params.NetworkIdentifier,
+			params.UnsignedTransaction,
+			params.Signatures,
+		)
+		if fetchErr != nil {
+			return nil, fetchErr.Err
+		}
+
+		return &types.ConstructionCombineResponse{
+			SignedTransaction: signedTransaction,
+		}, nil
+	case AirGapHash:
+		var params types.ConstructionHashRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		transactionIdentifier, fetchErr := a.offlineFetcher.ConstructionHash(
+			ctx,
+			params.NetworkIdentifier,
+			params.SignedTransaction,
+		)
+		if fetchErr != nil {
+			return nil, fetchErr.Err
+		}
+
+		return &types.TransactionIdentifierResponse{
+			TransactionIdentifier: transactionIdentifier,
+		}, nil
+	case AirGapSign:
+		var params RemoteSignRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		signatures, err := a.keyStorage.Sign(ctx, params.Payloads)
+		if err != nil {
+			return nil, err
+		}
+
+		return &RemoteSignResponse{Signatures: signatures}, nil
+	case AirGapGenerateKey:
+		var params AirGapGenerateKeyRequest
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, err
+		}
+
+		return a.generateKey(ctx, &params)
+	default:
+		return nil, fmt.Errorf("method %s is not supported", request.Method)
+	}
+}
+
+// storeKey stores keyPair unless it is already stored
+// (check:construction may repeat a request after a restart).
+func (a *AirGapAgent) storeKey(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	keyPair *keys.KeyPair,
+) error {
+	if keyPair == nil {
+		return errors.New("key pair is missing")
+	}
+
+	if err := keyPair.IsValid(); err != nil {
+		return fmt.Errorf("%w: invalid key pair", err)
+	}
+
+	existing, err := a.keyStorage.Get(ctx, account)
+	if err == nil {
+		if types.Hash(existing.PublicKey) != types.Hash(keyPair.PublicKey) {
+			return fmt.Errorf(
+				"%w: %s already stored for a different public key",
+				results.ErrAddressCollision,
+				types.PrintStruct(account),
+			)
+		}
+
+		return nil
+	}
+
+	return a.keyStorage.Store(ctx, account, keyPair)
+}
+
+func (a *AirGapAgent) generateKey(
+	ctx context.Context,
+	request *AirGapGenerateKeyRequest,
+) (*AirGapGenerateKeyResponse, error) {
+	keyPair, err := keys.GenerateKeypair(request.CurveType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to generate keypair", err)
+	}
+
+	account, _, fetchErr := a.offlineFetcher.ConstructionDerive(
+		ctx,
+		request.NetworkIdentifier,
+		keyPair.PublicKey,
+		request.Metadata,
+	)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("%w: unable to derive address", fetchErr.Err)
+	}
+
+	if err := a.storeKey(ctx, account, keyPair); err != nil {
+		return nil, err
+	}
+
+	return &AirGapGenerateKeyResponse{
+		AccountIdentifier: account,
+		PublicKey:         keyPair.PublicKey,
+	}, nil
+}
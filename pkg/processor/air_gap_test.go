@@ -0,0 +1,227 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ OfflineConstructor = (*mockOfflineConstructor)(nil)
+
+// mockOfflineConstructor derives the address of a public key
+// as its hex bytes and rejects every other request.
+type mockOfflineConstructor struct{}
+
+func (m *mockOfflineConstructor) ConstructionDerive(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	publicKey *types.PublicKey,
+	metadata map[string]interface{},
+) (*types.AccountIdentifier, map[string]interface{}, *fetcher.Error) {
+	return &types.AccountIdentifier{Address: hex.EncodeToString(publicKey.Bytes)}, metadata, nil
+}
+
+func (m *mockOfflineConstructor) ConstructionPreprocess(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	intent []*types.Operation,
+	metadata map[string]interface{},
+) (map[string]interface{}, []*types.AccountIdentifier, *fetcher.Error) {
+	return nil, nil, &fetcher.Error{Err: errors.New("preprocess failed")}
+}
+
+func (m *mockOfflineConstructor) ConstructionPayloads(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	operations []*types.Operation,
+	metadata map[string]interface{},
+	publicKeys []*types.PublicKey,
+) (string, []*types.SigningPayload, *fetcher.Error) {
+	return "", nil, &fetcher.Error{Err: errors.New("payloads failed")}
+}
+
+func (m *mockOfflineConstructor) ConstructionParse(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	signed bool,
+	transaction string,
+) ([]*types.Operation, []*types.AccountIdentifier, map[string]interface{}, *fetcher.Error) {
+	return nil, nil, nil, &fetcher.Error{Err: errors.New("parse failed")}
+}
+
+func (m *mockOfflineConstructor) ConstructionCombine(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	unsignedTransaction string,
+	signatures []*types.Signature,
+) (string, *fetcher.Error) {
+	return "", &fetcher.Error{Err: errors.New("combine failed")}
+}
+
+func (m *mockOfflineConstructor) ConstructionHash(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	networkTransaction string,
+) (*types.TransactionIdentifier, *fetcher.Error) {
+	return &types.TransactionIdentifier{Hash: networkTransaction + " hash"}, nil
+}
+
+func TestAirGapMessageEncoding(t *testing.T) {
+	request := &AirGapRequest{
+		ID:     "1-000001",
+		Method: AirGapHash,
+		Params: []byte(`{"signed_transaction":"tx"}`),
+	}
+
+	for _, encoding := range []configuration.AirGapEncoding{
+		configuration.JSONAirGapEncoding,
+		configuration.Base32AirGapEncoding,
+	} {
+		t.Run(string(encoding), func(t *testing.T) {
+			data, err := EncodeAirGapMessage(encoding, request)
+			assert.NoError(t, err)
+
+			var decoded AirGapRequest
+			assert.NoError(t, DecodeAirGapMessage(encoding, append(data, '\n'), &decoded))
+			assert.Equal(t, request, &decoded)
+		})
+	}
+
+	data, err := EncodeAirGapMessage(configuration.Base32AirGapEncoding, request)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^[A-Z2-7]+$", string(data))
+
+	_, err = EncodeAirGapMessage("hex", request)
+	assert.Error(t, err)
+}
+
+func TestAirGap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exchangeDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(exchangeDir)
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	config := &configuration.AirGapConfiguration{
+		ExchangeDirectory: exchangeDir,
+		Encoding:          configuration.Base32AirGapEncoding,
+		Timeout:           5,
+	}
+	network := &types.NetworkIdentifier{Blockchain: "bitcoin", Network: "mainnet"}
+
+	client, err := NewAirGapClient(config)
+	assert.NoError(t, err)
+
+	keyStorage := modules.NewKeyStorage(db)
+	agent := NewAirGapAgent(config, &mockOfflineConstructor{}, keyStorage)
+	done := make(chan error)
+	go func() {
+		done <- agent.Run(ctx)
+	}()
+
+	// Offline construction
+	identifier, fetchErr := client.ConstructionHash(ctx, network, "tx")
+	assert.Nil(t, fetchErr)
+	assert.Equal(t, "tx hash", identifier.Hash)
+
+	_, _, fetchErr = client.ConstructionPayloads(ctx, network, nil, nil, nil)
+	assert.True(t, errors.Is(fetchErr.Err, results.ErrAirGap))
+	assert.Contains(t, fetchErr.Err.Error(), "payloads failed")
+
+	// Keys generated by the offline-agent
+	account, publicKey, err := client.GenerateKey(ctx, network, types.Secp256k1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(publicKey.Bytes), account.Address)
+
+	stored, err := keyStorage.Get(ctx, account)
+	assert.NoError(t, err)
+	assert.Equal(t, publicKey, stored.PublicKey)
+
+	edwardsAccount, edwardsKey, err := client.GenerateKey(ctx, network, types.Edwards25519, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, types.Edwards25519, edwardsKey.CurveType)
+
+	// Signing
+	payloads := []*types.SigningPayload{
+		{
+			AccountIdentifier: account,
+			Bytes:             bytes.Repeat([]byte{1}, 32),
+			SignatureType:     types.Ecdsa,
+		},
+		{
+			AccountIdentifier: edwardsAccount,
+			Bytes:             []byte("payload"),
+			SignatureType:     types.Ed25519,
+		},
+	}
+	signatures, err := client.Sign(ctx, payloads)
+	assert.NoError(t, err)
+	assert.Len(t, signatures, 2)
+	assert.Equal(t, publicKey, signatures[0].PublicKey)
+	assert.Equal(t, edwardsKey, signatures[1].PublicKey)
+
+	_, err = client.Sign(ctx, []*types.SigningPayload{
+		{
+			AccountIdentifier: &types.AccountIdentifier{Address: "unknown"},
+			Bytes:             []byte("payload"),
+			SignatureType:     types.Ed25519,
+		},
+	})
+	assert.True(t, errors.Is(err, results.ErrAirGap))
+
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+}
+
+func TestAirGapTimeout(t *testing.T) {
+	exchangeDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(exchangeDir)
+
+	client, err := NewAirGapClient(&configuration.AirGapConfiguration{
+		ExchangeDirectory: exchangeDir,
+		Encoding:          configuration.JSONAirGapEncoding,
+	})
+	assert.NoError(t, err)
+	client.timeout = 200 * time.Millisecond
+
+	_, fetchErr := client.ConstructionHash(context.Background(), nil, "tx")
+	assert.True(t, errors.Is(fetchErr.Err, results.ErrAirGap))
+	assert.Contains(t, fetchErr.Err.Error(), "no response")
+}
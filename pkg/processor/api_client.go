@@ -0,0 +1,306 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/client"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+var _ http.RoundTripper = (*RosettaErrorTransport)(nil)
+
+// RosettaErrorTransport is an http.RoundTripper that records
+// each *types.Error returned by the implementation so that
+// errors (and the retries of retriable errors) can be reported
+// by error code.
+type RosettaErrorTransport struct {
+	base http.RoundTripper
+}
+
+// NewRosettaErrorTransport returns a new *RosettaErrorTransport.
+func NewRosettaErrorTransport(base http.RoundTripper) *RosettaErrorTransport {
+	return &RosettaErrorTransport{base: base}
+}
+
+// RoundTrip executes a single HTTP transaction and records
+// the *types.Error in any unsuccessful response.
+func (t *RosettaErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode == http.StatusOK {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var rosettaErr types.Error
+	if json.Unmarshal(body, &rosettaErr) == nil && len(rosettaErr.Message) > 0 {
+		results.RecordRosettaError(&rosettaErr)
+	}
+
+	return resp, nil
+}
+
+var _ http.RoundTripper = (*RequestLatencyTransport)(nil)
+
+// RequestLatencyTransport is an http.RoundTripper that records
+// the latency of each request by Rosetta endpoint (so that
+// latencies can be exported as metrics).
+type RequestLatencyTransport struct {
+	base http.RoundTripper
+}
+
+// NewRequestLatencyTransport returns a new *RequestLatencyTransport.
+func NewRequestLatencyTransport(base http.RoundTripper) *RequestLatencyTransport {
+	return &RequestLatencyTransport{base: base}
+}
+
+// RoundTrip executes a single HTTP transaction and
+// records its latency.
+func (t *RequestLatencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	results.RecordRequestLatency(req.URL.Path, time.Since(start))
+
+	return resp, err
+}
+
+// NewTransport returns an *http.Transport configured like the
+// transport of the default fetcher client that uses tlsConfig
+// (if it is not nil).
+func NewTransport(maxConnections int, tlsConfig *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.IdleConnTimeout = fetcher.DefaultIdleConnTimeout
+	transport.MaxIdleConns = maxConnections
+	transport.MaxIdleConnsPerHost = fetcher.DefaultMaxConnections
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
+	}
+
+	return transport
+}
+
+// ClientOptions configure how all requests are made to
+// a Rosetta implementation.
+type ClientOptions struct {
+	// TLS is used for all requests (if not nil).
+	TLS *tls.Config
+
+	// Headers are added to all requests (if not nil).
+	Headers *HeaderTemplates
+
+	// Retry determines how failed requests are retried (if
+	// not nil). Otherwise, requests are retried by the fetcher.
+	Retry *RetryPolicy
+
+	// RateLimits delay requests (including retries and
+	// replays) so that they don't exceed the configured
+	// rates (if not nil). They are shared by all clients
+	// created with the same *ClientOptions.
+	RateLimits *RateLimits
+
+	// Proxy routes all requests through a proxy (if not nil).
+	// Otherwise, the proxy is determined by the environment.
+	Proxy *Proxy
+}
+
+// NewClientOptions returns the *ClientOptions of config.
+func NewClientOptions(config *configuration.Configuration) (*ClientOptions, error) {
+	tlsConfig, err := NewTLSConfig(config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid tls configuration", err)
+	}
+
+	headers, err := NewHeaderTemplates(config.Headers, config.BearerToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid headers", err)
+	}
+
+	proxy, err := NewProxy(config.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid proxy configuration", err)
+	}
+
+	return &ClientOptions{
+		Proxy:      proxy,
+		TLS:        tlsConfig,
+		Headers:    headers,
+		Retry:      NewRetryPolicy(config),
+		RateLimits: NewRateLimits(config.RateLimit),
+	}, nil
+}
+
+// transport returns the http.RoundTripper of requests made
+// with o (o may be nil). If keepAlives is false, connections
+// are never reused.
+func (o *ClientOptions) transport(maxConnections int, keepAlives bool) http.RoundTripper {
+	var tlsConfig *tls.Config
+	if o != nil {
+		tlsConfig = o.TLS
+	}
+
+	transport := NewTransport(maxConnections, tlsConfig)
+	transport.DisableKeepAlives = !keepAlives
+	if o == nil {
+		return transport
+	}
+
+	transport.Proxy = o.Proxy.URL
+
+	var limited http.RoundTripper = transport
+	if o.RateLimits != nil {
+		limited = NewRateLimitTransport(transport, o.RateLimits)
+	}
+
+	if o.Headers == nil {
+		return limited
+	}
+
+	return NewHeaderTransport(limited, o.Headers)
+}
+
+// httpClient returns an *http.Client that makes requests with
+// transport that time out after timeout. If o.Retry is populated,
+// failed requests are retried and each attempt times out separately.
+func (o *ClientOptions) httpClient(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	if o == nil || o.Retry == nil {
+		return &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		}
+	}
+
+	return &http.Client{
+		Transport: NewRetryTransport(transport, o.Retry, timeout),
+	}
+}
+
+// NewClient returns a *client.APIClient configured like the
+// default fetcher client that uses options (if it is not nil).
+func NewClient(
+	serverAddress string,
+	timeout time.Duration,
+	maxConnections int,
+	options *ClientOptions,
+) *client.APIClient {
+	return client.NewAPIClient(client.NewConfiguration(
+		serverAddress,
+		fetcher.DefaultUserAgent,
+		options.httpClient(options.transport(maxConnections, true), timeout),
+	))
+}
+
+// APIClientOptions are the optional response checks of a
+// client returned by NewAPIClient. The zero value disables
+// all of them.
+type APIClientOptions struct {
+	// ReplayFraction, if > 0, is the fraction of critical
+	// requests replayed over a distinct connection (see
+	// ReplayTransport).
+	ReplayFraction float64
+
+	// OnMismatch is invoked with each inconsistent replayed
+	// response.
+	OnMismatch func(*ResponseMismatch)
+
+	// OperationTypeAliases, if populated, rename aliased
+	// operation types in block responses (see
+	// OperationTypeAliasTransport).
+	OperationTypeAliases map[string]string
+}
+
+// NewAPIClient returns a *client.APIClient configured like the
+// default fetcher client that records each *types.Error returned
+// by the implementation and applies apiOptions (if it is not nil).
+// Replayed requests never reuse a connection so that they are
+// likely to be routed to a different replica. The structure of
+// construction responses is checked for drift (see
+// SchemaDriftTransport). All requests use options (if it is
+// not nil). Retries (see RetryTransport) include all of the
+// above.
+func NewAPIClient(
+	serverAddress string,
+	timeout time.Duration,
+	maxConnections int,
+	apiOptions *APIClientOptions,
+	options *ClientOptions,
+) *client.APIClient {
+	if apiOptions == nil {
+		apiOptions = &APIClientOptions{}
+	}
+
+	var transport http.RoundTripper = NewSchemaDriftTransport(
+		NewRosettaErrorTransport(
+			NewRequestLatencyTransport(options.transport(maxConnections, true)),
+		),
+	)
+	if apiOptions.ReplayFraction > 0 {
+		replay := options.transport(maxConnections, false)
+		transport = NewReplayTransport(
+			transport,
+			replay,
+			apiOptions.ReplayFraction,
+			apiOptions.OnMismatch,
+		)
+	}
+
+	if len(apiOptions.OperationTypeAliases) > 0 {
+		transport = NewOperationTypeAliasTransport(transport, apiOptions.OperationTypeAliases)
+	}
+
+	return client.NewAPIClient(client.NewConfiguration(
+		serverAddress,
+		fetcher.DefaultUserAgent,
+		options.httpClient(transport, timeout),
+	))
+}
+
+// NewOfflineAPIClient returns a *client.APIClient configured like
+// the default fetcher client that checks the structure of construction
+// responses for drift (see SchemaDriftTransport). Errors returned by
+// an offline implementation are not recorded because some are
+// expected (ex: when verifying offline isolation). All requests use
+// options (if it is not nil).
+func NewOfflineAPIClient(
+	serverAddress string,
+	timeout time.Duration,
+	maxConnections int,
+	options *ClientOptions,
+) *client.APIClient {
+	return client.NewAPIClient(client.NewConfiguration(
+		serverAddress,
+		fetcher.DefaultUserAgent,
+		options.httpClient(
+			NewSchemaDriftTransport(options.transport(maxConnections, true)),
+			timeout,
+		),
+	))
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRosettaErrorTransport(t *testing.T) {
+	transport := NewRosettaErrorTransport(roundTripFunc(
+		func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Body: ioutil.NopCloser(bytes.NewBufferString(
+					`{"code":700,"message":"transport test error","retriable":true}`,
+				)),
+			}, nil
+		},
+	))
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/block", nil)
+	assert.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "transport test error")
+
+	found := false
+	for _, stats := range results.RosettaErrors() {
+		if stats.Code == 700 {
+			found = true
+			assert.True(t, stats.Retriable)
+			assert.Equal(t, int64(1), stats.Count)
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,469 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// amountVariable is the expression variable holding
+	// the value of the original amount.
+	amountVariable = "amount"
+
+	// metadataVariablePrefix is the prefix of expression
+	// variables holding operation metadata.
+	metadataVariablePrefix = "metadata."
+)
+
+var (
+	_ modules.BlockWorker = (*BalanceRuleWorker)(nil)
+
+	// errDivisionByZero is returned when a balance
+	// expression divides by zero.
+	errDivisionByZero = errors.New("division by zero")
+)
+
+// balanceExpression evaluates an expression against the
+// amount value and metadata of an operation.
+type balanceExpression func(amount *big.Int, metadata map[string]interface{}) (*big.Int, error)
+
+// BalanceRules are compiled *configuration.BalanceRules
+// (keyed by operation type).
+type BalanceRules struct {
+	rules       map[string]*configuration.BalanceRule
+	expressions map[string]balanceExpression
+}
+
+// NewBalanceRules compiles configured balance rules. If
+// no rules are configured, nil is returned.
+func NewBalanceRules(rules []*configuration.BalanceRule) (*BalanceRules, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	b := &BalanceRules{
+		rules:       map[string]*configuration.BalanceRule{},
+		expressions: map[string]balanceExpression{},
+	}
+	for _, rule := range rules {
+		b.rules[rule.OperationType] = rule
+		if rule.Action != configuration.ExpressionBalanceRule {
+			continue
+		}
+
+		expression, err := parseBalanceExpression(rule.Expression)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: invalid expression %q for operation type %s",
+				err,
+				rule.Expression,
+				rule.OperationType,
+			)
+		}
+
+		b.expressions[rule.OperationType] = expression
+	}
+
+	return b, nil
+}
+
+// Apply modifies the amount of op according to the rule
+// of its operation type and returns a boolean indicating
+// if op was modified. Operations without an amount are
+// never modified.
+func (b *BalanceRules) Apply(op *types.Operation) (bool, error) {
+	rule, ok := b.rules[op.Type]
+	if !ok || op.Amount == nil {
+		return false, nil
+	}
+
+	value, err := types.AmountValue(op.Amount)
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to parse amount", err)
+	}
+
+	switch rule.Action {
+	case configuration.AddBalanceRule:
+		value = new(big.Int).Abs(value)
+	case configuration.SubtractBalanceRule:
+		value = new(big.Int).Neg(new(big.Int).Abs(value))
+	case configuration.IgnoreBalanceRule:
+		op.Amount = nil
+		return true, nil
+	case configuration.ExpressionBalanceRule:
+		value, err = b.expressions[op.Type](value, op.Metadata)
+		if err != nil {
+			return false, fmt.Errorf(
+				"%w: unable to evaluate expression %q",
+				err,
+				rule.Expression,
+			)
+		}
+	}
+
+	op.Amount = &types.Amount{
+		Value:    value.String(),
+		Currency: op.Amount.Currency,
+		Metadata: op.Amount.Metadata,
+	}
+	return true, nil
+}
+
+// ApplyBlock returns a copy of block with rules applied to
+// each operation and the types of the modified operations
+// (one per modified operation). block is not modified.
+func (b *BalanceRules) ApplyBlock(block *types.Block) (*types.Block, []string, error) {
+	applied := *block
+	applied.Transactions = make([]*types.Transaction, len(block.Transactions))
+	modified := []string{}
+	for i, tx := range block.Transactions {
+		appliedTx := *tx
+		appliedTx.Operations = make([]*types.Operation, len(tx.Operations))
+		for j, op := range tx.Operations {
+			// Apply only replaces the amount of the
+			// operation, so a shallow copy is sufficient.
+			appliedOp := *op
+			ok, err := b.Apply(&appliedOp)
+			if err != nil {
+				return nil, nil, fmt.Errorf(
+					"%w: unable to apply balance rule to operation %d in transaction %s",
+					err,
+					op.OperationIdentifier.Index,
+					tx.TransactionIdentifier.Hash,
+				)
+			}
+
+			if ok {
+				modified = append(modified, op.Type)
+			}
+			appliedTx.Operations[j] = &appliedOp
+		}
+
+		applied.Transactions[i] = &appliedTx
+	}
+
+	return &applied, modified, nil
+}
+
+// BalanceRuleWorker is a modules.BlockWorker that passes a copy
+// of each block with balance rules applied to a balance worker
+// (ex: *modules.BalanceStorage), so that computed balances follow
+// the blockchain's balance semantics. Blocks are validated, stored,
+// and passed to all other workers exactly as they were returned
+// by the implementation.
+type BalanceRuleWorker struct {
+	worker modules.BlockWorker
+	rules  *BalanceRules
+}
+
+// NewBalanceRuleWorker returns a new *BalanceRuleWorker.
+func NewBalanceRuleWorker(worker modules.BlockWorker, rules *BalanceRules) *BalanceRuleWorker {
+	return &BalanceRuleWorker{
+		worker: worker,
+		rules:  rules,
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *BalanceRuleWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	applied, modified, err := w.rules.ApplyBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operationType := range modified {
+		results.RecordBalanceRule(operationType)
+	}
+
+	return w.worker.AddingBlock(ctx, g, applied, transaction)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *BalanceRuleWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	applied, _, err := w.rules.ApplyBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.worker.RemovingBlock(ctx, g, applied, transaction)
+}
+
+// expressionParser is a recursive descent parser of
+// balance expressions:
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/") unary }
+//	unary      = "-" unary | primary
+//	primary    = integer | variable | "(" expression ")"
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseBalanceExpression compiles a balance expression.
+func parseBalanceExpression(s string) (balanceExpression, error) {
+	tokens, err := tokenizeExpression(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &expressionParser{tokens: tokens}
+	expression, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	return expression, nil
+}
+
+// tokenizeExpression splits s into integers, variables,
+// operators, and parentheses.
+func tokenizeExpression(s string) ([]string, error) {
+	tokens := []string{}
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("+-*/()", r):
+			tokens = append(tokens, string(r))
+			i++
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) &&
+				(unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) ||
+					runes[j] == '_' || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) expression() (balanceExpression, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "+" || p.peek() == "-" {
+		operator := p.tokens[p.pos]
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpression(operator, left, right)
+	}
+
+	return left, nil
+}
+
+func (p *expressionParser) term() (balanceExpression, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "*" || p.peek() == "/" {
+		operator := p.tokens[p.pos]
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpression(operator, left, right)
+	}
+
+	return left, nil
+}
+
+func (p *expressionParser) unary() (balanceExpression, error) {
+	if p.peek() != "-" {
+		return p.primary()
+	}
+
+	p.pos++
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(amount *big.Int, metadata map[string]interface{}) (*big.Int, error) {
+		value, err := operand(amount, metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		return new(big.Int).Neg(value), nil
+	}, nil
+}
+
+func (p *expressionParser) primary() (balanceExpression, error) {
+	token := p.peek()
+	p.pos++
+	switch {
+	case token == "":
+		return nil, errors.New("unexpected end of expression")
+	case token == "(":
+		inner, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+
+		return inner, nil
+	case unicode.IsDigit(rune(token[0])):
+		value, ok := new(big.Int).SetString(token, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %s", token)
+		}
+
+		return func(*big.Int, map[string]interface{}) (*big.Int, error) {
+			return value, nil
+		}, nil
+	case token == amountVariable:
+		return func(amount *big.Int, _ map[string]interface{}) (*big.Int, error) {
+			return amount, nil
+		}, nil
+	case strings.HasPrefix(token, metadataVariablePrefix) &&
+		len(token) > len(metadataVariablePrefix):
+		key := strings.TrimPrefix(token, metadataVariablePrefix)
+		return func(_ *big.Int, metadata map[string]interface{}) (*big.Int, error) {
+			return metadataInteger(metadata, key)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+}
+
+// binaryExpression applies operator to the
+// results of left and right.
+func binaryExpression(operator string, left, right balanceExpression) balanceExpression {
+	return func(amount *big.Int, metadata map[string]interface{}) (*big.Int, error) {
+		x, err := left(amount, metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := right(amount, metadata)
+		if err != nil {
+			return nil, err
+		}
+
+		switch operator {
+		case "+":
+			return new(big.Int).Add(x, y), nil
+		case "-":
+			return new(big.Int).Sub(x, y), nil
+		case "*":
+			return new(big.Int).Mul(x, y), nil
+		default:
+			if y.Sign() == 0 {
+				return nil, errDivisionByZero
+			}
+
+			return new(big.Int).Quo(x, y), nil
+		}
+	}
+}
+
+// metadataInteger returns the integer (or integer
+// string) value of key in metadata.
+func metadataInteger(metadata map[string]interface{}, key string) (*big.Int, error) {
+	raw, ok := metadata[key]
+	if !ok {
+		return nil, fmt.Errorf("metadata key %s is missing", key)
+	}
+
+	switch value := raw.(type) {
+	case string:
+		parsed, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, fmt.Errorf("metadata key %s is not an integer: %s", key, value)
+		}
+
+		return parsed, nil
+	case float64:
+		parsed, accuracy := big.NewFloat(value).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf(
+				"metadata key %s is not an integer: %s",
+				key,
+				strconv.FormatFloat(value, 'f', -1, 64),
+			)
+		}
+
+		return parsed, nil
+	default:
+		return nil, fmt.Errorf("metadata key %s is not an integer: %v", key, raw)
+	}
+}
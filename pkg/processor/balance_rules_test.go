@@ -0,0 +1,213 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBalanceExpression(t *testing.T) {
+	metadata := map[string]interface{}{
+		"fee":      float64(3),
+		"big":      "100000000000000000000",
+		"fraction": 1.5,
+		"text":     "abc",
+	}
+
+	var tests = map[string]struct {
+		expression string
+		expected   *big.Int
+		parseErr   bool
+		evalErr    bool
+	}{
+		"amount":          {expression: "amount", expected: big.NewInt(10)},
+		"negated":         {expression: "-amount", expected: big.NewInt(-10)},
+		"precedence":      {expression: "amount + 2 * 3", expected: big.NewInt(16)},
+		"parentheses":     {expression: "-(amount + metadata.fee)", expected: big.NewInt(-13)},
+		"truncated":       {expression: "amount / 3", expected: big.NewInt(3)},
+		"double negation": {expression: "--amount", expected: big.NewInt(10)},
+		"string metadata": {
+			expression: "metadata.big - amount",
+			expected:   new(big.Int).Sub(new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil), big.NewInt(10)),
+		},
+		"empty":                {expression: "", parseErr: true},
+		"unknown variable":     {expression: "amount + fee", parseErr: true},
+		"unbalanced":           {expression: "(amount + 1", parseErr: true},
+		"trailing operator":    {expression: "amount +", parseErr: true},
+		"trailing token":       {expression: "amount 1", parseErr: true},
+		"invalid character":    {expression: "amount % 2", parseErr: true},
+		"empty metadata key":   {expression: "metadata.", parseErr: true},
+		"division by zero":     {expression: "amount / (metadata.fee - 3)", evalErr: true},
+		"missing metadata":     {expression: "metadata.missing", evalErr: true},
+		"fractional metadata":  {expression: "metadata.fraction", evalErr: true},
+		"non-integer metadata": {expression: "metadata.text", evalErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expression, err := parseBalanceExpression(test.expression)
+			if test.parseErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			value, err := expression(big.NewInt(10), metadata)
+			if test.evalErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestBalanceRules(t *testing.T) {
+	rules, err := NewBalanceRules(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, rules)
+
+	_, err = NewBalanceRules([]*configuration.BalanceRule{
+		{
+			OperationType: "Fee",
+			Action:        configuration.ExpressionBalanceRule,
+			Expression:    "amount +",
+		},
+	})
+	assert.Error(t, err)
+
+	rules, err = NewBalanceRules([]*configuration.BalanceRule{
+		{OperationType: "RULE_TEST_REWARD", Action: configuration.AddBalanceRule},
+		{OperationType: "RULE_TEST_BURN", Action: configuration.SubtractBalanceRule},
+		{OperationType: "RULE_TEST_MEMO", Action: configuration.IgnoreBalanceRule},
+		{
+			OperationType: "RULE_TEST_FEE",
+			Action:        configuration.ExpressionBalanceRule,
+			Expression:    "-(amount + metadata.tip)",
+		},
+	})
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	op := func(index int64, opType string, value string) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: index},
+			Type:                opType,
+			Account:             &types.AccountIdentifier{Address: "addr1"},
+			Amount:              &types.Amount{Value: value, Currency: currency},
+			Metadata:            map[string]interface{}{"tip": float64(2)},
+		}
+	}
+
+	block := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Index: 1, Hash: "block 1"},
+		ParentBlockIdentifier: &types.BlockIdentifier{Index: 0, Hash: "block 0"},
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+				Operations: []*types.Operation{
+					op(0, "RULE_TEST_REWARD", "-5"),
+					op(1, "RULE_TEST_BURN", "5"),
+					op(2, "RULE_TEST_MEMO", "7"),
+					op(3, "RULE_TEST_FEE", "10"),
+					op(4, "Transfer", "-3"),
+				},
+			},
+		},
+	}
+	original := types.Hash(block)
+
+	// Rules are applied to the block passed to the wrapped
+	// worker, while the original block is not modified.
+	worker := &recordingBlockWorker{}
+	ruleWorker := NewBalanceRuleWorker(worker, rules)
+	_, err = ruleWorker.AddingBlock(context.Background(), nil, block, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, original, types.Hash(block))
+
+	ops := worker.added.Transactions[0].Operations
+	assert.Equal(t, "5", ops[0].Amount.Value)
+	assert.Equal(t, "-5", ops[1].Amount.Value)
+	assert.Nil(t, ops[2].Amount)
+	assert.Equal(t, "-12", ops[3].Amount.Value)
+	assert.Equal(t, currency, ops[3].Amount.Currency)
+	assert.Equal(t, "-3", ops[4].Amount.Value)
+
+	usage := results.BalanceRuleUsage()
+	for _, opType := range []string{
+		"RULE_TEST_REWARD",
+		"RULE_TEST_BURN",
+		"RULE_TEST_MEMO",
+		"RULE_TEST_FEE",
+	} {
+		assert.Equal(t, int64(1), usage[opType])
+	}
+
+	// Removed blocks are applied the same way (but not
+	// counted again).
+	_, err = ruleWorker.RemovingBlock(context.Background(), nil, block, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, worker.added, worker.removed)
+	assert.Equal(t, int64(1), results.BalanceRuleUsage()["RULE_TEST_REWARD"])
+
+	// Evaluation errors fail the block
+	block.Transactions[0].Operations = []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "RULE_TEST_FEE",
+			Amount:              &types.Amount{Value: "10", Currency: currency},
+		},
+	}
+	_, err = ruleWorker.AddingBlock(context.Background(), nil, block, nil)
+	assert.Error(t, err)
+}
+
+// recordingBlockWorker records the last
+// added and removed blocks.
+type recordingBlockWorker struct {
+	added   *types.Block
+	removed *types.Block
+}
+
+func (w *recordingBlockWorker) AddingBlock(
+	_ context.Context,
+	_ *errgroup.Group,
+	block *types.Block,
+	_ database.Transaction,
+) (database.CommitWorker, error) {
+	w.added = block
+	return nil, nil
+}
+
+func (w *recordingBlockWorker) RemovingBlock(
+	_ context.Context,
+	_ *errgroup.Group,
+	block *types.Block,
+	_ database.Transaction,
+) (database.CommitWorker, error) {
+	w.removed = block
+	return nil, nil
+}
@@ -43,6 +43,7 @@ type BalanceStorageHelper struct {
 	exemptAccounts       map[string]struct{}
 	balanceExemptions    []*types.BalanceExemption
 	initialFetchDisabled bool
+	baselineBlock        *types.BlockIdentifier
 
 	// Interesting-only Parsing
 	interestingOnly      bool
@@ -98,6 +99,12 @@ func (h *BalanceStorageHelper) AccountBalance(
 		}, nil
 	}
 
+	// When syncing a bounded range, we use the balance at the
+	// start of the range as the baseline for all accounts.
+	if h.baselineBlock != nil {
+		lookupBlock = h.baselineBlock
+	}
+
 	// In the case that we are syncing from arbitrary height,
 	// we may need to recover the balance of an account to
 	// perform validations.
@@ -116,6 +123,14 @@ func (h *BalanceStorageHelper) AccountBalance(
 	// If the returned balance block does not match the intended
 	// block a re-org could've occurred.
 	if types.Hash(lookupBlock) != types.Hash(block) {
+		if h.baselineBlock != nil {
+			return nil, fmt.Errorf(
+				"baseline block %s does not match %s",
+				types.PrintStruct(h.baselineBlock),
+				types.PrintStruct(block),
+			)
+		}
+
 		return nil, syncer.ErrOrphanHead
 	}
 
@@ -125,6 +140,13 @@ func (h *BalanceStorageHelper) AccountBalance(
 	}, nil
 }
 
+// SetBaselineBlock configures the block at which the balance
+// of each newly seen account is fetched (instead of the parent
+// of the block where it is first seen).
+func (h *BalanceStorageHelper) SetBaselineBlock(block *types.BlockIdentifier) {
+	h.baselineBlock = block
+}
+
 // Asserter returns a *asserter.Asserter.
 func (h *BalanceStorageHelper) Asserter() *asserter.Asserter {
 	return h.fetcher.Asserter
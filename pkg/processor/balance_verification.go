@@ -0,0 +1,167 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// BalanceChange is the net change in the balance of
+// Currency held by Account across some operations.
+type BalanceChange struct {
+	Account  *types.AccountIdentifier
+	Currency *types.Currency
+	Value    *big.Int
+}
+
+// BalanceChanges returns the net balance change of each account
+// and currency across operations, keyed by the hash of the account
+// and currency. Operations without an account or amount are skipped.
+// If asserter is not nil, only successful operations are included
+// (intents don't populate operation status).
+func BalanceChanges(
+	asserter *asserter.Asserter,
+	operations []*types.Operation,
+) (map[string]*BalanceChange, error) {
+	changes := map[string]*BalanceChange{}
+	for _, op := range operations {
+		if op.Account == nil || op.Amount == nil {
+			continue
+		}
+
+		if asserter != nil {
+			successful, err := asserter.OperationSuccessful(op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to determine if operation was successful", err)
+			}
+
+			if !successful {
+				continue
+			}
+		}
+
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+		}
+
+		key := types.Hash(op.Account) + types.Hash(op.Amount.Currency)
+		change, ok := changes[key]
+		if !ok {
+			change = &BalanceChange{
+				Account:  op.Account,
+				Currency: op.Amount.Currency,
+				Value:    new(big.Int),
+			}
+			changes[key] = change
+		}
+
+		change.Value.Add(change.Value, value)
+	}
+
+	return changes, nil
+}
+
+// AssertBalanceChanges returns an error describing every account
+// in intent whose balance change in a confirmed transaction does not
+// match its balance change in intent. An account whose balance does
+// not increase in intent may also pay a fee of up to the configured
+// fee tolerance of the currency.
+func AssertBalanceChanges(
+	verification *configuration.BalanceVerificationConfiguration,
+	asserter *asserter.Asserter,
+	transaction *types.Transaction,
+	intent []*types.Operation,
+) error {
+	if verification == nil {
+		return nil
+	}
+
+	expected, err := BalanceChanges(nil, intent)
+	if err != nil {
+		return fmt.Errorf("%w: unable to calculate intent balance changes", err)
+	}
+
+	observed, err := BalanceChanges(asserter, transaction.Operations)
+	if err != nil {
+		return fmt.Errorf("%w: unable to calculate observed balance changes", err)
+	}
+
+	tolerances := map[string]*big.Int{}
+	for _, tolerance := range verification.FeeTolerance {
+		value, err := types.AmountValue(tolerance)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse fee tolerance", err)
+		}
+
+		tolerances[types.Hash(tolerance.Currency)] = value
+	}
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	failures := []string{}
+	for _, key := range keys {
+		want := expected[key]
+		got := new(big.Int)
+		if change, ok := observed[key]; ok {
+			got = change.Value
+		}
+
+		// fee is how much more the account paid than
+		// the intent (a negative fee is a credit).
+		fee := new(big.Int).Sub(want.Value, got)
+		tolerance, ok := tolerances[types.Hash(want.Currency)]
+		if !ok || want.Value.Sign() > 0 {
+			tolerance = new(big.Int)
+		}
+
+		if fee.Sign() >= 0 && fee.Cmp(tolerance) <= 0 {
+			continue
+		}
+
+		failures = append(failures, fmt.Sprintf(
+			"account %s expected balance change %s %s (fee tolerance %s) but observed %s",
+			types.PrintStruct(want.Account),
+			want.Value.String(),
+			want.Currency.Symbol,
+			tolerance.String(),
+			got.String(),
+		))
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: transaction %s: %s",
+		results.ErrBalanceChangeMismatch,
+		transaction.TransactionIdentifier.Hash,
+		strings.Join(failures, "; "),
+	)
+}
@@ -0,0 +1,162 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertBalanceChanges(t *testing.T) {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer", "Fee"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+			{
+				Status:     "Failure",
+				Successful: false,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	op := func(opType string, status string, address string, value string) *types.Operation {
+		operation := &types.Operation{
+			Type:    opType,
+			Account: &types.AccountIdentifier{Address: address},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: currency,
+			},
+		}
+		if len(status) > 0 {
+			operation.Status = types.String(status)
+		}
+
+		return operation
+	}
+
+	intent := []*types.Operation{
+		op("Transfer", "", "sender", "-100"),
+		op("Transfer", "", "recipient", "100"),
+	}
+	verification := &configuration.BalanceVerificationConfiguration{
+		FeeTolerance: []*types.Amount{
+			{Value: "10", Currency: currency},
+		},
+	}
+
+	var tests = map[string]struct {
+		verification *configuration.BalanceVerificationConfiguration
+		operations   []*types.Operation
+		err          string
+	}{
+		"not configured": {
+			operations: []*types.Operation{
+				op("Transfer", "Success", "sender", "-100"),
+			},
+		},
+		"exact": {
+			verification: verification,
+			operations: []*types.Operation{
+				op("Transfer", "Success", "sender", "-100"),
+				op("Transfer", "Success", "recipient", "100"),
+			},
+		},
+		"fee within tolerance": {
+			verification: verification,
+			operations: []*types.Operation{
+				op("Transfer", "Success", "sender", "-100"),
+				op("Transfer", "Success", "recipient", "100"),
+				op("Fee", "Success", "sender", "-10"),
+				op("Fee", "Failure", "sender", "-50"),
+			},
+		},
+		"fee exceeds tolerance": {
+			verification: verification,
+			operations: []*types.Operation{
+				op("Transfer", "Success", "sender", "-100"),
+				op("Transfer", "Success", "recipient", "100"),
+				op("Fee", "Success", "sender", "-11"),
+			},
+			err: "expected balance change -100 BTC (fee tolerance 10) but observed -111",
+		},
+		"fee without tolerance": {
+			verification: &configuration.BalanceVerificationConfiguration{},
+			operations: []*types.Operation{
+				op("Transfer", "Success", "sender", "-101"),
+				op("Transfer", "Success", "recipient", "100"),
+			},
+			err: "observed -101",
+		},
+		"recipient short": {
+			verification: verification,
+			operations: []*types.Operation{
+				op("Transfer", "Success", "sender", "-100"),
+				op("Transfer", "Success", "recipient", "95"),
+			},
+			err: "expected balance change 100 BTC (fee tolerance 0) but observed 95",
+		},
+		"transfer failed": {
+			verification: verification,
+			operations: []*types.Operation{
+				op("Transfer", "Failure", "sender", "-100"),
+				op("Transfer", "Failure", "recipient", "100"),
+			},
+			err: "observed 0",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := AssertBalanceChanges(test.verification, a, &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx hash"},
+				Operations:            test.operations,
+			}, intent)
+			if len(test.err) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, results.ErrBalanceChangeMismatch))
+			assert.Contains(t, err.Error(), "tx hash")
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
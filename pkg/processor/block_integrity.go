@@ -0,0 +1,139 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// CheckBlockIntegrity asserts structural invariants across all
+// blocks in blockStorage (from the oldest unpruned block to the
+// head block). A block timestamp may be at most
+// timestampTolerance milliseconds before the timestamp of the
+// block preceding it. Violations are returned in the results
+// (an error is only returned if blocks cannot be loaded).
+func CheckBlockIntegrity(
+	ctx context.Context,
+	blockStorage *modules.BlockStorage,
+	timestampTolerance int64,
+) (*results.BlockIntegrityResults, error) {
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	oldest, err := blockStorage.GetOldestBlockIndex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get oldest block index", err)
+	}
+
+	integrity := &results.BlockIntegrityResults{
+		Violations: []*results.BlockViolation{},
+	}
+	var previous *types.Block
+	for index := oldest; index <= head.Index; index++ {
+		i := index
+		block, err := blockStorage.GetBlock(
+			ctx,
+			&types.PartialBlockIdentifier{Index: &i},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get block %d", err, index)
+		}
+
+		integrity.Violations = append(
+			integrity.Violations,
+			blockViolations(previous, block, timestampTolerance)...,
+		)
+		integrity.BlocksChecked++
+		previous = block
+	}
+
+	return integrity, nil
+}
+
+// blockViolations returns the invariants violated by block
+// (previous is nil if block is the oldest stored block).
+func blockViolations(
+	previous *types.Block,
+	block *types.Block,
+	timestampTolerance int64,
+) []*results.BlockViolation {
+	violations := []*results.BlockViolation{}
+	violate := func(check string, format string, args ...interface{}) {
+		violations = append(violations, &results.BlockViolation{
+			Block:   block.BlockIdentifier,
+			Check:   check,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// The genesis block may have a timestamp of 0, so we don't
+	// compare timestamps against it.
+	if previous != nil && previous.Timestamp != 0 &&
+		block.Timestamp < previous.Timestamp-timestampTolerance {
+		violate(
+			results.TimestampCheck,
+			"timestamp %d is %dms before previous block timestamp %d",
+			block.Timestamp,
+			previous.Timestamp-block.Timestamp,
+			previous.Timestamp,
+		)
+	}
+
+	if previous != nil &&
+		types.Hash(block.ParentBlockIdentifier) != types.Hash(previous.BlockIdentifier) {
+		violate(
+			results.ParentLinkageCheck,
+			"parent %s does not match previous block %s",
+			types.PrintStruct(block.ParentBlockIdentifier),
+			types.PrintStruct(previous.BlockIdentifier),
+		)
+	}
+
+	seen := map[string]struct{}{}
+	for _, tx := range block.Transactions {
+		hash := tx.TransactionIdentifier.Hash
+		if _, ok := seen[hash]; ok {
+			violate(
+				results.DuplicateTransactionCheck,
+				"transaction %s appears more than once",
+				hash,
+			)
+		}
+		seen[hash] = struct{}{}
+
+		for i, op := range tx.Operations {
+			if op.OperationIdentifier == nil || op.OperationIdentifier.Index != int64(i) {
+				violate(
+					results.OperationIndexCheck,
+					"transaction %s operation %d does not have index %d",
+					hash,
+					i,
+					i,
+				)
+				break
+			}
+		}
+	}
+
+	return violations
+}
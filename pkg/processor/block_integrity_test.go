@@ -0,0 +1,166 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func integrityTransaction(hash string, indexes ...int64) *types.Transaction {
+	ops := []*types.Operation{}
+	for _, index := range indexes {
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: index},
+			Type:                "Transfer",
+		})
+	}
+
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+		Operations:            ops,
+	}
+}
+
+func TestCheckBlockIntegrity(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(db, 1)
+	timestamps := []int64{0, 1600000000000, 1600000001000, 1600000000500, 1599999999000}
+	parent := &types.BlockIdentifier{Index: 0, Hash: "block 0"}
+	for i := int64(0); i < int64(len(timestamps)); i++ {
+		blockParent := parent
+		if i == 2 {
+			blockParent = &types.BlockIdentifier{Index: 0, Hash: "block 0"}
+		}
+
+		block := &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{
+				Index: i,
+				Hash:  fmt.Sprintf("block %d", i),
+			},
+			ParentBlockIdentifier: blockParent,
+			Timestamp:             timestamps[i],
+			Transactions: []*types.Transaction{
+				integrityTransaction(fmt.Sprintf("tx %d", i), 0, 1),
+			},
+		}
+		assert.NoError(t, blockStorage.SeeBlock(ctx, block))
+		assert.NoError(t, blockStorage.AddBlock(ctx, block))
+		parent = block.BlockIdentifier
+	}
+
+	integrity, err := CheckBlockIntegrity(ctx, blockStorage, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), integrity.BlocksChecked)
+	assert.Equal(t, []*results.BlockViolation{
+		{
+			Block:   &types.BlockIdentifier{Index: 2, Hash: "block 2"},
+			Check:   results.ParentLinkageCheck,
+			Message: "parent {\"index\":0,\"hash\":\"block 0\"} does not match previous block {\"index\":1,\"hash\":\"block 1\"}",
+		},
+		{
+			Block:   &types.BlockIdentifier{Index: 4, Hash: "block 4"},
+			Check:   results.TimestampCheck,
+			Message: "timestamp 1599999999000 is 1500ms before previous block timestamp 1600000000500",
+		},
+	}, integrity.Violations)
+}
+
+func TestBlockViolations(t *testing.T) {
+	previous := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 1, Hash: "block 1"},
+		Timestamp:       1600000000000,
+	}
+
+	tests := map[string]struct {
+		transactions []*types.Transaction
+		violations   []*results.BlockViolation
+	}{
+		"no violations": {
+			transactions: []*types.Transaction{
+				integrityTransaction("tx 1", 0, 1, 2),
+				integrityTransaction("tx 2"),
+			},
+			violations: []*results.BlockViolation{},
+		},
+		"duplicate transaction": {
+			transactions: []*types.Transaction{
+				integrityTransaction("tx 1", 0),
+				integrityTransaction("tx 1", 0),
+			},
+			violations: []*results.BlockViolation{
+				{
+					Check:   results.DuplicateTransactionCheck,
+					Message: "transaction tx 1 appears more than once",
+				},
+			},
+		},
+		"operation index gap": {
+			transactions: []*types.Transaction{
+				integrityTransaction("tx 1", 0, 2, 3),
+			},
+			violations: []*results.BlockViolation{
+				{
+					Check:   results.OperationIndexCheck,
+					Message: "transaction tx 1 operation 1 does not have index 1",
+				},
+			},
+		},
+		"operation index not zero": {
+			transactions: []*types.Transaction{
+				integrityTransaction("tx 1", 1),
+			},
+			violations: []*results.BlockViolation{
+				{
+					Check:   results.OperationIndexCheck,
+					Message: "transaction tx 1 operation 0 does not have index 0",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			block := &types.Block{
+				BlockIdentifier:       &types.BlockIdentifier{Index: 2, Hash: "block 2"},
+				ParentBlockIdentifier: previous.BlockIdentifier,
+				Timestamp:             previous.Timestamp,
+				Transactions:          test.transactions,
+			}
+			for _, violation := range test.violations {
+				violation.Block = block.BlockIdentifier
+			}
+
+			assert.Equal(t, test.violations, blockViolations(previous, block, 0))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*BlockStatsWorker)(nil)
+
+// BlockStatsWorker records the size of each synced block,
+// the number of transactions it contains, and the number
+// of operations in each transaction.
+type BlockStatsWorker struct{}
+
+// NewBlockStatsWorker returns a new *BlockStatsWorker.
+func NewBlockStatsWorker() *BlockStatsWorker {
+	return &BlockStatsWorker{}
+}
+
+// BlockObservation returns the serialized size of block
+// and the number of operations in each of its transactions.
+func BlockObservation(block *types.Block) (int64, []int64, error) {
+	b, err := json.Marshal(block)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: unable to serialize block", err)
+	}
+
+	operations := make([]int64, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		operations[i] = int64(len(tx.Operations))
+	}
+
+	return int64(len(b)), operations, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *BlockStatsWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	size, operations, err := BlockObservation(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		results.RecordBlockObservation(block.BlockIdentifier, size, operations)
+		return nil
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *BlockStatsWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	size, operations, err := BlockObservation(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		results.RemoveBlockObservation(size, operations)
+		return nil
+	}, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/client"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// maxTracedBalanceChanges is the maximum number of balance
+	// changes included in a trace (to keep traces of large
+	// blocks readable).
+	maxTracedBalanceChanges = 100
+)
+
+// BlockTracer re-fetches and re-processes a single block one step
+// at a time (without retries), recording the outcome and duration
+// of each step. This is used to diagnose the block that caused a
+// violation without rerunning check:data.
+type BlockTracer struct {
+	client  *client.APIClient
+	network *types.NetworkIdentifier
+	parser  *parser.Parser
+}
+
+// NewBlockTracer returns a new *BlockTracer.
+func NewBlockTracer(
+	client *client.APIClient,
+	network *types.NetworkIdentifier,
+	parser *parser.Parser,
+) *BlockTracer {
+	return &BlockTracer{
+		client:  client,
+		network: network,
+		parser:  parser,
+	}
+}
+
+// traceStep runs f and appends its detail, error, and
+// duration to trace. The error of f is returned.
+func traceStep(
+	trace *results.ViolationTrace,
+	name string,
+	f func() (string, error),
+) error {
+	start := time.Now()
+	detail, err := f()
+	step := &results.TraceStep{
+		Step:       name,
+		Detail:     detail,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	trace.Steps = append(trace.Steps, step)
+
+	return err
+}
+
+// clientError returns err with the *types.Error
+// returned by the implementation (if any).
+func clientError(err error, clientErr *types.Error) error {
+	if clientErr == nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", err, types.PrintStruct(clientErr))
+}
+
+// Trace re-fetches and re-processes the block at index. If account
+// is populated (ex: the account of a failed reconciliation), only
+// the balance changes of account are traced. Tracing stops at the
+// first step that fails.
+func (t *BlockTracer) Trace(
+	ctx context.Context,
+	index int64,
+	account *types.AccountIdentifier,
+) *results.ViolationTrace {
+	trace := &results.ViolationTrace{
+		BlockIndex: index,
+		Steps:      []*results.TraceStep{},
+	}
+
+	var blockResponse *types.BlockResponse
+	if err := traceStep(trace, "Fetch Block", func() (string, error) {
+		var clientErr *types.Error
+		var err error
+		blockResponse, clientErr, err = t.client.BlockAPI.Block(
+			ctx,
+			&types.BlockRequest{
+				NetworkIdentifier: t.network,
+				BlockIdentifier:   &types.PartialBlockIdentifier{Index: &index},
+			},
+		)
+		if err != nil {
+			return "", clientError(err, clientErr)
+		}
+
+		if blockResponse.Block == nil {
+			return "block omitted", nil
+		}
+
+		return fmt.Sprintf(
+			"%s with %d transactions and %d other_transactions",
+			types.PrintStruct(blockResponse.Block.BlockIdentifier),
+			len(blockResponse.Block.Transactions),
+			len(blockResponse.OtherTransactions),
+		), nil
+	}); err != nil || blockResponse.Block == nil {
+		return trace
+	}
+
+	block := blockResponse.Block
+	failed := false
+	for _, txID := range blockResponse.OtherTransactions {
+		if err := traceStep(trace, "Fetch Other Transaction", func() (string, error) {
+			txResponse, clientErr, err := t.client.BlockAPI.BlockTransaction(
+				ctx,
+				&types.BlockTransactionRequest{
+					NetworkIdentifier:     t.network,
+					BlockIdentifier:       block.BlockIdentifier,
+					TransactionIdentifier: txID,
+				},
+			)
+			if err != nil {
+				return txID.Hash, clientError(err, clientErr)
+			}
+
+			block.Transactions = append(block.Transactions, txResponse.Transaction)
+			return fmt.Sprintf(
+				"%s with %d operations",
+				txID.Hash,
+				len(txResponse.Transaction.Operations),
+			), nil
+		}); err != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return trace
+	}
+
+	for _, tx := range block.Transactions {
+		if err := traceStep(trace, "Assert Transaction", func() (string, error) {
+			operationTypes := make([]string, len(tx.Operations))
+			for i, op := range tx.Operations {
+				operationTypes[i] = op.Type
+			}
+			detail := fmt.Sprintf(
+				"%s with operations [%s]",
+				tx.TransactionIdentifier.Hash,
+				strings.Join(operationTypes, ", "),
+			)
+
+			return detail, t.parser.Asserter.Transaction(tx)
+		}); err != nil {
+			return trace
+		}
+	}
+
+	if err := traceStep(trace, "Assert Block", func() (string, error) {
+		return "", t.parser.Asserter.Block(block)
+	}); err != nil {
+		return trace
+	}
+
+	var changes []*parser.BalanceChange
+	if err := traceStep(trace, "Compute Balance Changes", func() (string, error) {
+		var err error
+		changes, err = t.parser.BalanceChanges(ctx, block, false)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%d balance changes", len(changes)), nil
+	}); err != nil {
+		return trace
+	}
+
+	traced := 0
+	for _, change := range changes {
+		if account != nil && types.Hash(change.Account) != types.Hash(account) {
+			continue
+		}
+
+		if traced == maxTracedBalanceChanges {
+			_ = traceStep(trace, "Balance Change", func() (string, error) {
+				return "additional balance changes omitted", nil
+			})
+			break
+		}
+		traced++
+
+		_ = traceStep(trace, "Balance Change", func() (string, error) {
+			return fmt.Sprintf(
+				"%s %s%s",
+				types.AccountString(change.Account),
+				change.Difference,
+				change.Currency.Symbol,
+			), nil
+		})
+	}
+
+	return trace
+}
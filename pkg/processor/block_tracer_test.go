@@ -0,0 +1,207 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockTracer(t *testing.T) {
+	network := &types.NetworkIdentifier{
+		Blockchain: "bitcoin",
+		Network:    "mainnet",
+	}
+	a, err := asserter.NewClientWithOptions(
+		network,
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+	p := parser.New(a, nil, nil)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	sender := &types.AccountIdentifier{Address: "sender"}
+	recipient := &types.AccountIdentifier{Address: "recipient"}
+	transfer := func(
+		hash string,
+		operationType string,
+	) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                operationType,
+					Status:              types.String("Success"),
+					Account:             sender,
+					Amount:              &types.Amount{Value: "-10", Currency: currency},
+				},
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 1},
+					Type:                operationType,
+					Status:              types.String("Success"),
+					Account:             recipient,
+					Amount:              &types.Amount{Value: "10", Currency: currency},
+				},
+			},
+		}
+	}
+
+	var tests = map[string]struct {
+		transactions      []*types.Transaction
+		otherTransactions []*types.TransactionIdentifier
+		account           *types.AccountIdentifier
+
+		steps []string
+		err   string
+	}{
+		"all balance changes": {
+			transactions: []*types.Transaction{transfer("tx 1", "Transfer")},
+			steps: []string{
+				"Fetch Block",
+				"Assert Transaction",
+				"Assert Block",
+				"Compute Balance Changes",
+				"Balance Change",
+				"Balance Change",
+			},
+		},
+		"balance changes of account": {
+			transactions:      []*types.Transaction{},
+			otherTransactions: []*types.TransactionIdentifier{{Hash: "tx 2"}},
+			account:           sender,
+			steps: []string{
+				"Fetch Block",
+				"Fetch Other Transaction",
+				"Assert Transaction",
+				"Assert Block",
+				"Compute Balance Changes",
+				"Balance Change",
+			},
+		},
+		"missing other transaction": {
+			transactions:      []*types.Transaction{},
+			otherTransactions: []*types.TransactionIdentifier{{Hash: "missing"}},
+			steps: []string{
+				"Fetch Block",
+				"Fetch Other Transaction",
+			},
+			err: "transaction not found",
+		},
+		"invalid operation type": {
+			transactions: []*types.Transaction{transfer("tx 1", "Mint")},
+			steps: []string{
+				"Fetch Block",
+				"Assert Transaction",
+			},
+			err: "Mint",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/block":
+					assert.NoError(t, json.NewEncoder(w).Encode(&types.BlockResponse{
+						Block: &types.Block{
+							BlockIdentifier: &types.BlockIdentifier{
+								Hash:  "block 1",
+								Index: 1,
+							},
+							ParentBlockIdentifier: &types.BlockIdentifier{
+								Hash:  "block 0",
+								Index: 0,
+							},
+							Timestamp:    asserter.MinUnixEpoch + 1,
+							Transactions: test.transactions,
+						},
+						OtherTransactions: test.otherTransactions,
+					}))
+				case "/block/transaction":
+					var request types.BlockTransactionRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+					if request.TransactionIdentifier.Hash == "missing" {
+						w.WriteHeader(http.StatusInternalServerError)
+						assert.NoError(t, json.NewEncoder(w).Encode(&types.Error{
+							Code:    1,
+							Message: "transaction not found",
+						}))
+						return
+					}
+
+					assert.NoError(t, json.NewEncoder(w).Encode(&types.BlockTransactionResponse{
+						Transaction: transfer(request.TransactionIdentifier.Hash, "Transfer"),
+					}))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			tracer := NewBlockTracer(NewClient(server.URL, time.Minute, 1, nil), network, p)
+			trace := tracer.Trace(context.Background(), 1, test.account)
+			assert.Equal(t, int64(1), trace.BlockIndex)
+
+			steps := make([]string, len(trace.Steps))
+			for i, step := range trace.Steps {
+				steps[i] = step.Step
+			}
+			assert.Equal(t, test.steps, steps)
+
+			last := trace.Steps[len(trace.Steps)-1]
+			if len(test.err) > 0 {
+				assert.Contains(t, last.Error, test.err)
+			} else {
+				assert.Empty(t, last.Error)
+			}
+
+			if test.account != nil {
+				assert.Equal(t, &results.TraceStep{
+					Step:       "Balance Change",
+					Detail:     "sender -10BTC",
+					DurationMS: last.DurationMS,
+				}, last)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*BlockTransactionWorker)(nil)
+
+// BlockTransactionWorker fetches each transaction in an added
+// block with /block/transaction and ensures it matches the
+// transaction returned by /block.
+type BlockTransactionWorker struct {
+	network   *types.NetworkIdentifier
+	fetcher   *fetcher.Fetcher
+	frequency int64
+}
+
+// NewBlockTransactionWorker returns a new *BlockTransactionWorker
+// that compares the transactions in every block with an index
+// divisible by frequency.
+func NewBlockTransactionWorker(
+	network *types.NetworkIdentifier,
+	fetcher *fetcher.Fetcher,
+	frequency int64,
+) *BlockTransactionWorker {
+	return &BlockTransactionWorker{
+		network:   network,
+		fetcher:   fetcher,
+		frequency: frequency,
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *BlockTransactionWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	if len(block.Transactions) == 0 || block.BlockIdentifier.Index%w.frequency != 0 {
+		return nil, nil
+	}
+
+	g.Go(func() error {
+		return w.compareTransactions(ctx, block)
+	})
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *BlockTransactionWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+func (w *BlockTransactionWorker) compareTransactions(
+	ctx context.Context,
+	block *types.Block,
+) error {
+	identifiers := make([]*types.TransactionIdentifier, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		identifiers[i] = tx.TransactionIdentifier
+	}
+
+	fetched, fetchErr := w.fetcher.UnsafeTransactions(
+		ctx,
+		w.network,
+		block.BlockIdentifier,
+		identifiers,
+	)
+	if fetchErr != nil {
+		return fmt.Errorf(
+			"%w: unable to fetch transactions in block %d:%s",
+			fetchErr.Err,
+			block.BlockIdentifier.Index,
+			block.BlockIdentifier.Hash,
+		)
+	}
+
+	for _, tx := range fetched {
+		if err := w.fetcher.Asserter.Transaction(tx); err != nil {
+			return fmt.Errorf(
+				"%w: transaction %s in block %d:%s is invalid",
+				err,
+				tx.TransactionIdentifier.Hash,
+				block.BlockIdentifier.Index,
+				block.BlockIdentifier.Hash,
+			)
+		}
+	}
+
+	if err := CompareBlockTransactions(block.Transactions, fetched); err != nil {
+		return fmt.Errorf(
+			"%w: block %d:%s",
+			err,
+			block.BlockIdentifier.Index,
+			block.BlockIdentifier.Hash,
+		)
+	}
+
+	return nil
+}
+
+// CompareBlockTransactions ensures each transaction returned in a block
+// is identical to the transaction with the same identifier returned by
+// /block/transaction.
+func CompareBlockTransactions(
+	inline []*types.Transaction,
+	fetched []*types.Transaction,
+) error {
+	fetchedTxs := map[string]*types.Transaction{}
+	for _, tx := range fetched {
+		fetchedTxs[tx.TransactionIdentifier.Hash] = tx
+	}
+
+	for _, tx := range inline {
+		fetchedTx, ok := fetchedTxs[tx.TransactionIdentifier.Hash]
+		if !ok {
+			return fmt.Errorf(
+				"%w: transaction %s not returned by /block/transaction",
+				results.ErrBlockTransactionMismatch,
+				tx.TransactionIdentifier.Hash,
+			)
+		}
+
+		if types.Hash(tx) != types.Hash(fetchedTx) {
+			return fmt.Errorf(
+				"%w: transaction %s in /block is %s but /block/transaction returned %s",
+				results.ErrBlockTransactionMismatch,
+				tx.TransactionIdentifier.Hash,
+				types.PrintStruct(tx),
+				types.PrintStruct(fetchedTx),
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareBlockTransactions(t *testing.T) {
+	transaction := func(hash string, value string) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                "Transfer",
+					Status:              types.String("Success"),
+					Account:             &types.AccountIdentifier{Address: "addr1"},
+					Amount: &types.Amount{
+						Value:    value,
+						Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+					},
+				},
+			},
+		}
+	}
+
+	inline := []*types.Transaction{
+		transaction("tx1", "100"),
+		transaction("tx2", "200"),
+	}
+
+	var tests = map[string]struct {
+		fetched []*types.Transaction
+
+		err bool
+	}{
+		"identical": {
+			fetched: []*types.Transaction{
+				transaction("tx1", "100"),
+				transaction("tx2", "200"),
+			},
+		},
+		"reordered but identical": {
+			fetched: []*types.Transaction{
+				transaction("tx2", "200"),
+				transaction("tx1", "100"),
+			},
+		},
+		"missing transaction": {
+			fetched: []*types.Transaction{
+				transaction("tx1", "100"),
+			},
+			err: true,
+		},
+		"mismatched transaction": {
+			fetched: []*types.Transaction{
+				transaction("tx1", "100"),
+				transaction("tx2", "250"),
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := CompareBlockTransactions(inline, test.fetched)
+			if test.err {
+				assert.True(t, errors.Is(err, results.ErrBlockTransactionMismatch))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
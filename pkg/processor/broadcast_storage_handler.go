@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/coinbase/rosetta-sdk-go/constructor/coordinator"
 	"github.com/coinbase/rosetta-sdk-go/parser"
@@ -38,6 +39,8 @@ type BroadcastStorageHandler struct {
 	counterStorage *modules.CounterStorage
 	coordinator    *coordinator.Coordinator
 	parser         *parser.Parser
+	matcher        *OperationMatcher
+	nonceTracker   *NonceTracker
 }
 
 // NewBroadcastStorageHandler returns a new *BroadcastStorageHandler.
@@ -46,12 +49,15 @@ func NewBroadcastStorageHandler(
 	counterStorage *modules.CounterStorage,
 	coordinator *coordinator.Coordinator,
 	parser *parser.Parser,
+	nonceTracker *NonceTracker,
 ) *BroadcastStorageHandler {
 	return &BroadcastStorageHandler{
 		config:         config,
 		counterStorage: counterStorage,
 		coordinator:    coordinator,
 		parser:         parser,
+		matcher:        NewOperationMatcher(parser, config.Construction.OperationMatching),
+		nonceTracker:   nonceTracker,
 	}
 }
 
@@ -65,16 +71,59 @@ func (h *BroadcastStorageHandler) TransactionConfirmed(
 	transaction *types.Transaction,
 	intent []*types.Operation,
 ) error {
-	if err := h.parser.ExpectedOperations(intent, transaction.Operations, false, true); err != nil {
+	if err := h.matcher.ExpectedOperations(intent, transaction.Operations, true); err != nil {
 		return fmt.Errorf("%w: confirmed transaction did not match intent", err)
 	}
 
-	_, _ = h.counterStorage.UpdateTransactional(
+	if err := AssertConfirmedTransaction(
+		h.config.Construction.TransactionAssertions,
+		h.parser.Asserter,
+		transaction,
+		intent,
+	); err != nil {
+		return err
+	}
+
+	// Injected operations are included in the expected balance
+	// changes so that their amounts aren't mistaken for fees.
+	injectedIntent, err := InjectOperations(intent, h.config.Construction.InjectedOperations)
+	if err != nil {
+		return err
+	}
+
+	if err := AssertBalanceChanges(
+		h.config.Construction.BalanceVerification,
+		h.parser.Asserter,
+		transaction,
+		injectedIntent,
+	); err != nil {
+		return err
+	}
+
+	if _, err := h.counterStorage.UpdateTransactional(
 		ctx,
 		dbTx,
 		modules.TransactionsConfirmedCounter,
 		big.NewInt(1),
-	)
+	); err != nil {
+		return fmt.Errorf("%w: unable to update %s counter", err, modules.TransactionsConfirmedCounter)
+	}
+	results.RecordTransactionConfirmed(identifier)
+
+	// Dust consolidations, replays, and pipeline
+	// transfers are not run by the coordinator.
+	if counter, ok := standaloneCounter(identifier); ok {
+		if _, err := h.counterStorage.UpdateTransactional(
+			ctx,
+			dbTx,
+			counter,
+			big.NewInt(1),
+		); err != nil {
+			return fmt.Errorf("%w: unable to update %s counter", err, counter)
+		}
+
+		return nil
+	}
 
 	if err := h.coordinator.BroadcastComplete(
 		ctx,
@@ -97,12 +146,14 @@ func (h *BroadcastStorageHandler) TransactionStale(
 	identifier string,
 	transactionIdentifier *types.TransactionIdentifier,
 ) error {
-	_, _ = h.counterStorage.UpdateTransactional(
+	if _, err := h.counterStorage.UpdateTransactional(
 		ctx,
 		dbTx,
 		modules.StaleBroadcastsCounter,
 		big.NewInt(1),
-	)
+	); err != nil {
+		return fmt.Errorf("%w: unable to update %s counter", err, modules.StaleBroadcastsCounter)
+	}
 
 	return nil
 }
@@ -116,20 +167,31 @@ func (h *BroadcastStorageHandler) BroadcastFailed(
 	transactionIdentifier *types.TransactionIdentifier,
 	intent []*types.Operation,
 ) error {
-	_, _ = h.counterStorage.UpdateTransactional(
+	if _, err := h.counterStorage.UpdateTransactional(
 		ctx,
 		dbTx,
 		modules.FailedBroadcastsCounter,
 		big.NewInt(1),
-	)
-
-	if err := h.coordinator.BroadcastComplete(
-		ctx,
-		dbTx,
-		identifier,
-		nil,
 	); err != nil {
-		return fmt.Errorf("%w: coordinator could not handle transaction", err)
+		return fmt.Errorf("%w: unable to update %s counter", err, modules.FailedBroadcastsCounter)
+	}
+
+	// A failed broadcast leaves a gap in the nonces of its
+	// senders, so we go back to using the nonces returned
+	// by the node for them.
+	if h.nonceTracker != nil {
+		h.nonceTracker.ResetSenders(ctx, dbTx, intent)
+	}
+
+	if _, ok := standaloneCounter(identifier); !ok {
+		if err := h.coordinator.BroadcastComplete(
+			ctx,
+			dbTx,
+			identifier,
+			nil,
+		); err != nil {
+			return fmt.Errorf("%w: coordinator could not handle transaction", err)
+		}
 	}
 
 	if h.config.Construction.IgnoreBroadcastFailures {
@@ -142,3 +204,24 @@ func (h *BroadcastStorageHandler) BroadcastFailed(
 		types.PrettyPrintStruct(intent),
 	)
 }
+
+// standaloneCounter returns the counter incremented when a
+// transaction constructed outside of the coordinator with
+// identifier is confirmed. If the transaction was constructed
+// by the coordinator, false is returned.
+func standaloneCounter(identifier string) (string, bool) {
+	switch {
+	case IsDustConsolidation(identifier):
+		return results.DustConsolidationsCounter, true
+	case IsMultiSenderSpend(identifier):
+		return results.MultiSenderSpendsCounter, true
+	case IsCPFP(identifier):
+		return results.CPFPTransactionsCounter, true
+	case IsReplay(identifier):
+		return results.ReplayedTransactionsCounter, true
+	case IsPipelineTransfer(identifier):
+		return results.PipelineTransfersCounter, true
+	default:
+		return "", false
+	}
+}
@@ -17,12 +17,17 @@ package processor
 import (
 	"context"
 	"fmt"
-	"github.com/coinbase/rosetta-sdk-go/utils"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
 )
 
 var _ modules.BroadcastStorageHelper = (*BroadcastStorageHelper)(nil)
@@ -30,9 +35,18 @@ var _ modules.BroadcastStorageHelper = (*BroadcastStorageHelper)(nil)
 // BroadcastStorageHelper implements the storage.Helper
 // interface.
 type BroadcastStorageHelper struct {
-	network      *types.NetworkIdentifier
-	blockStorage *modules.BlockStorage
-	fetcher      *fetcher.Fetcher
+	network        *types.NetworkIdentifier
+	blockStorage   *modules.BlockStorage
+	fetcher        *fetcher.Fetcher
+	counterStorage *modules.CounterStorage
+
+	// mempoolVerifier, if populated, is notified of
+	// each accepted submission.
+	mempoolVerifier *MempoolVerifier
+
+	// broadcaster, if populated, is used to broadcast
+	// transactions instead of /construction/submit.
+	broadcaster Broadcaster
 }
 
 // NewBroadcastStorageHelper returns a new BroadcastStorageHelper.
@@ -40,11 +54,17 @@ func NewBroadcastStorageHelper(
 	network *types.NetworkIdentifier,
 	blockStorage *modules.BlockStorage,
 	fetcher *fetcher.Fetcher,
+	counterStorage *modules.CounterStorage,
+	mempoolVerifier *MempoolVerifier,
+	broadcaster Broadcaster,
 ) *BroadcastStorageHelper {
 	return &BroadcastStorageHelper{
-		network:      network,
-		blockStorage: blockStorage,
-		fetcher:      fetcher,
+		network:         network,
+		blockStorage:    blockStorage,
+		fetcher:         fetcher,
+		counterStorage:  counterStorage,
+		mempoolVerifier: mempoolVerifier,
+		broadcaster:     broadcaster,
 	}
 }
 
@@ -91,19 +111,71 @@ func (h *BroadcastStorageHelper) FindTransaction(
 }
 
 // BroadcastTransaction broadcasts a transaction to a Rosetta implementation
-// and returns the *types.TransactionIdentifier returned by the implementation.
+// (or with the configured Broadcaster) and returns the
+// *types.TransactionIdentifier returned by the implementation.
 func (h *BroadcastStorageHelper) BroadcastTransaction(
 	ctx context.Context,
 	networkIdentifier *types.NetworkIdentifier,
 	networkTransaction string,
 ) (*types.TransactionIdentifier, error) {
+	var transactionIdentifier *types.TransactionIdentifier
+	var err error
+	if h.broadcaster != nil {
+		start := time.Now()
+		transactionIdentifier, err = h.broadcaster.Broadcast(
+			ctx,
+			networkIdentifier,
+			networkTransaction,
+		)
+		results.RecordTransactionSubmitted(err == nil, time.Since(start))
+	} else {
+		transactionIdentifier, err = h.submit(ctx, networkIdentifier, networkTransaction)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to broadcast transaction", err)
+	}
+
+	if h.mempoolVerifier != nil {
+		h.mempoolVerifier.TransactionSubmitted(transactionIdentifier)
+	}
+
+	return transactionIdentifier, nil
+}
+
+// submit broadcasts a transaction with /construction/submit.
+func (h *BroadcastStorageHelper) submit(
+	ctx context.Context,
+	networkIdentifier *types.NetworkIdentifier,
+	networkTransaction string,
+) (*types.TransactionIdentifier, error) {
+	start := time.Now()
 	transactionIdentifier, _, fetchErr := h.fetcher.ConstructionSubmit(
 		ctx,
 		networkIdentifier,
 		networkTransaction,
 	)
+	latency := time.Since(start)
+	results.RecordEndpointLatency(constructionSubmit, latency)
+	results.RecordTransactionSubmitted(fetchErr == nil, latency)
 	if fetchErr != nil {
-		return nil, fmt.Errorf("%w: unable to broadcast transaction", fetchErr.Err)
+		// Implementations should reject invalid transactions (ex: those
+		// constructed with expired metadata) with a *types.Error so that
+		// callers can determine why the submission failed.
+		if fetchErr.ClientErr == nil {
+			if _, err := h.counterStorage.Update(
+				ctx,
+				results.UnstructuredSubmitErrorsCounter,
+				big.NewInt(1),
+			); err != nil {
+				log.Printf(
+					"unable to update %s counter: %s\n",
+					results.UnstructuredSubmitErrorsCounter,
+					err.Error(),
+				)
+			}
+		}
+
+		return nil, fetchErr.Err
 	}
 
 	return transactionIdentifier, nil
@@ -0,0 +1,153 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Broadcaster broadcasts signed transactions. If no Broadcaster
+// is provided to the BroadcastStorageHelper, transactions are
+// broadcast with /construction/submit.
+type Broadcaster interface {
+	Broadcast(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		networkTransaction string,
+	) (*types.TransactionIdentifier, error)
+}
+
+var _ Broadcaster = (*JSONRPCBroadcaster)(nil)
+
+// JSONRPCRequest is a JSON-RPC 2.0 request.
+type JSONRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// JSONRPCError is the error returned in a
+// JSON-RPC response.
+type JSONRPCError struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSONRPCResponse is a JSON-RPC 2.0 response.
+type JSONRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *JSONRPCError   `json:"error"`
+}
+
+// JSONRPCBroadcaster is a Broadcaster that submits signed
+// transactions directly to a node with a JSON-RPC request,
+// bypassing the implementation.
+type JSONRPCBroadcaster struct {
+	url    string
+	method string
+	client *http.Client
+}
+
+// NewJSONRPCBroadcaster returns a new *JSONRPCBroadcaster.
+func NewJSONRPCBroadcaster(
+	url string,
+	method string,
+	timeout time.Duration,
+	proxy *Proxy,
+) *JSONRPCBroadcaster {
+	return &JSONRPCBroadcaster{
+		url:    url,
+		method: method,
+		client: NewHTTPClient(timeout, proxy),
+	}
+}
+
+// Broadcast calls the configured method with networkTransaction
+// and returns the transaction hash returned by the node.
+func (b *JSONRPCBroadcaster) Broadcast(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	networkTransaction string,
+) (*types.TransactionIdentifier, error) {
+	body, err := json.Marshal(&JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  b.method,
+		Params:  []interface{}{networkTransaction},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to marshal broadcast request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create broadcast request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to send broadcast request", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read broadcast response", err)
+	}
+
+	var rpcResponse JSONRPCResponse
+	if err := json.Unmarshal(respBody, &rpcResponse); err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to unmarshal broadcast response (status %d): %s",
+			err,
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	if rpcResponse.Error != nil {
+		return nil, fmt.Errorf(
+			"%w: %s returned code %d: %s",
+			results.ErrNodeBroadcast,
+			b.method,
+			rpcResponse.Error.Code,
+			rpcResponse.Error.Message,
+		)
+	}
+
+	var hash string
+	if err := json.Unmarshal(rpcResponse.Result, &hash); err != nil || len(hash) == 0 {
+		return nil, fmt.Errorf(
+			"%w: %s returned invalid transaction hash %s",
+			results.ErrNodeBroadcast,
+			b.method,
+			string(rpcResponse.Result),
+		)
+	}
+
+	return &types.TransactionIdentifier{Hash: hash}, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONRPCBroadcaster(t *testing.T) {
+	var tests = map[string]struct {
+		response string
+
+		hash string
+		err  error
+	}{
+		"valid": {
+			response: `{"jsonrpc":"2.0","id":1,"result":"0xabc"}`,
+			hash:     "0xabc",
+		},
+		"rejected": {
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`,
+			err:      results.ErrNodeBroadcast,
+		},
+		"invalid hash": {
+			response: `{"jsonrpc":"2.0","id":1,"result":12}`,
+			err:      results.ErrNodeBroadcast,
+		},
+		"missing hash": {
+			response: `{"jsonrpc":"2.0","id":1}`,
+			err:      results.ErrNodeBroadcast,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+
+				var req JSONRPCRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, "2.0", req.JSONRPC)
+				assert.Equal(t, "eth_sendRawTransaction", req.Method)
+				assert.Equal(t, []interface{}{"0xsigned"}, req.Params)
+
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			broadcaster := NewJSONRPCBroadcaster(server.URL, "eth_sendRawTransaction", time.Second, nil)
+			transactionIdentifier, err := broadcaster.Broadcast(
+				context.Background(),
+				&types.NetworkIdentifier{Blockchain: "Ethereum", Network: "Mainnet"},
+				"0xsigned",
+			)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, &types.TransactionIdentifier{Hash: test.hash}, transactionIdentifier)
+		})
+	}
+}
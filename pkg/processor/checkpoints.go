@@ -0,0 +1,309 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// fastForwardLogFrequency is the frequency that
+	// fast-forward progress is logged.
+	fastForwardLogFrequency = 10 * time.Second
+)
+
+// Checkpoint is the header of a validated block. A sequence
+// of checkpoints forms a hash chain that can be used as a set
+// of trusted checkpoints (and verified against the node).
+type Checkpoint struct {
+	Index      int64  `json:"index"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parent_hash"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// ExportCheckpoints writes a *Checkpoint for each block in
+// blockStorage (from the oldest unpruned block to the head
+// block) to w as newline-delimited JSON. An error is returned
+// if any block does not reference the hash of its predecessor.
+// The number of checkpoints written is returned.
+func ExportCheckpoints(
+	ctx context.Context,
+	blockStorage *modules.BlockStorage,
+	w io.Writer,
+) (int64, error) {
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	oldest, err := blockStorage.GetOldestBlockIndex(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to get oldest block index", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	var previous *types.BlockIdentifier
+	for index := oldest; index <= head.Index; index++ {
+		i := index
+		blockResponse, err := blockStorage.GetBlockLazy(
+			ctx,
+			&types.PartialBlockIdentifier{Index: &i},
+		)
+		if err != nil {
+			return index - oldest, fmt.Errorf("%w: unable to get block %d", err, index)
+		}
+
+		block := blockResponse.Block
+		if previous != nil && block.ParentBlockIdentifier.Hash != previous.Hash {
+			return index - oldest, fmt.Errorf(
+				"block %s parent %s does not match previous block %s",
+				types.PrintStruct(block.BlockIdentifier),
+				types.PrintStruct(block.ParentBlockIdentifier),
+				types.PrintStruct(previous),
+			)
+		}
+
+		if err := encoder.Encode(&Checkpoint{
+			Index:      block.BlockIdentifier.Index,
+			Hash:       block.BlockIdentifier.Hash,
+			ParentHash: block.ParentBlockIdentifier.Hash,
+			Timestamp:  block.Timestamp,
+		}); err != nil {
+			return index - oldest, fmt.Errorf("%w: unable to write checkpoint %d", err, index)
+		}
+
+		previous = block.BlockIdentifier
+	}
+
+	return head.Index - oldest + 1, nil
+}
+
+// LoadCheckpoints loads the newline-delimited JSON checkpoints
+// at filePath (ex: written by ExportCheckpoints). An error is
+// returned if there are no checkpoints, any checkpoint does not
+// have a hash, or the checkpoints are not in increasing order of
+// index.
+func LoadCheckpoints(filePath string) ([]*Checkpoint, error) {
+	f, err := os.Open(path.Clean(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open checkpoint file", err)
+	}
+	defer f.Close()
+
+	checkpoints := []*Checkpoint{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var checkpoint Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &checkpoint); err != nil {
+			return nil, fmt.Errorf("%w: unable to parse checkpoint on line %d", err, line)
+		}
+
+		if len(checkpoint.Hash) == 0 {
+			return nil, fmt.Errorf("checkpoint on line %d does not have a hash", line)
+		}
+
+		if len(checkpoints) > 0 && checkpoint.Index <= checkpoints[len(checkpoints)-1].Index {
+			return nil, fmt.Errorf(
+				"checkpoint %d on line %d is not after checkpoint %d",
+				checkpoint.Index,
+				line,
+				checkpoints[len(checkpoints)-1].Index,
+			)
+		}
+
+		checkpoints = append(checkpoints, &checkpoint)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: unable to read checkpoint file", err)
+	}
+
+	if len(checkpoints) == 0 {
+		return nil, errors.New("checkpoint file is empty")
+	}
+
+	return checkpoints, nil
+}
+
+// CheckpointChain checks that a sequence of blocks (added in
+// order of index from the first checkpoint to the last) forms
+// a hash chain through every checkpoint.
+type CheckpointChain struct {
+	checkpoints map[int64]*Checkpoint
+
+	next     int64
+	previous *types.BlockIdentifier
+}
+
+// NewCheckpointChain returns a new *CheckpointChain for
+// checkpoints (which must be in increasing order of index).
+func NewCheckpointChain(checkpoints []*Checkpoint) *CheckpointChain {
+	c := &CheckpointChain{
+		checkpoints: map[int64]*Checkpoint{},
+		next:        checkpoints[0].Index,
+	}
+	for _, checkpoint := range checkpoints {
+		c.checkpoints[checkpoint.Index] = checkpoint
+	}
+
+	return c
+}
+
+// Add checks the block at index (nil if omitted) against
+// the previous block and any checkpoint at index.
+func (c *CheckpointChain) Add(index int64, block *types.Block) error {
+	if index != c.next {
+		return fmt.Errorf("expected block %d but got block %d", c.next, index)
+	}
+	c.next++
+
+	checkpoint, ok := c.checkpoints[index]
+	if block == nil {
+		if ok {
+			return fmt.Errorf("%w: block %d is omitted", results.ErrCheckpointMismatch, index)
+		}
+
+		return nil
+	}
+
+	if block.BlockIdentifier.Index != index {
+		return fmt.Errorf(
+			"%w: requested block %d but got block %s",
+			results.ErrCheckpointMismatch,
+			index,
+			types.PrintStruct(block.BlockIdentifier),
+		)
+	}
+
+	if c.previous != nil && block.ParentBlockIdentifier.Hash != c.previous.Hash {
+		return fmt.Errorf(
+			"%w: block %s parent %s does not match previous block %s",
+			results.ErrCheckpointMismatch,
+			types.PrintStruct(block.BlockIdentifier),
+			types.PrintStruct(block.ParentBlockIdentifier),
+			types.PrintStruct(c.previous),
+		)
+	}
+
+	if ok {
+		if block.BlockIdentifier.Hash != checkpoint.Hash {
+			return fmt.Errorf(
+				"%w: block %d has hash %s but checkpoint has hash %s",
+				results.ErrCheckpointMismatch,
+				index,
+				block.BlockIdentifier.Hash,
+				checkpoint.Hash,
+			)
+		}
+
+		if len(checkpoint.ParentHash) > 0 &&
+			block.ParentBlockIdentifier.Hash != checkpoint.ParentHash {
+			return fmt.Errorf(
+				"%w: block %d has parent hash %s but checkpoint has parent hash %s",
+				results.ErrCheckpointMismatch,
+				index,
+				block.ParentBlockIdentifier.Hash,
+				checkpoint.ParentHash,
+			)
+		}
+	}
+
+	c.previous = block.BlockIdentifier
+	return nil
+}
+
+// FastForwardCheckpoints fetches every block from the first to
+// the last checkpoint (concurrency blocks at a time) and checks
+// them with a *CheckpointChain. Fetched blocks are only checked
+// by the asserter of f (balances are not tracked).
+func FastForwardCheckpoints(
+	ctx context.Context,
+	f *fetcher.Fetcher,
+	network *types.NetworkIdentifier,
+	checkpoints []*Checkpoint,
+	concurrency int64,
+) (*results.CheckpointSync, error) {
+	chain := NewCheckpointChain(checkpoints)
+	first := checkpoints[0].Index
+	last := checkpoints[len(checkpoints)-1].Index
+	lastLog := time.Now()
+	for start := first; start <= last; start += concurrency {
+		end := start + concurrency - 1
+		if end > last {
+			end = last
+		}
+
+		blocks := make([]*types.Block, end-start+1)
+		g, gctx := errgroup.WithContext(ctx)
+		for index := start; index <= end; index++ {
+			i := index
+			g.Go(func() error {
+				block, fetchErr := f.BlockRetry(gctx, network, &types.PartialBlockIdentifier{
+					Index: &i,
+				})
+				if fetchErr != nil {
+					return fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, i)
+				}
+
+				blocks[i-start] = block
+				return nil
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return nil, err
+		}
+
+		for i, block := range blocks {
+			if err := chain.Add(start+int64(i), block); err != nil {
+				return nil, err
+			}
+		}
+
+		if time.Since(lastLog) > fastForwardLogFrequency {
+			log.Printf("fast-forwarded to block %d of %d\n", end, last)
+			lastLog = time.Now()
+		}
+	}
+
+	return &results.CheckpointSync{
+		StartIndex:  first,
+		EndIndex:    last,
+		Checkpoints: int64(len(checkpoints)),
+		Blocks:      last - first + 1,
+	}, nil
+}
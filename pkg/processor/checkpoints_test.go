@@ -0,0 +1,219 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(db, 1)
+	parent := &types.BlockIdentifier{Index: 0, Hash: "block 0"}
+	for i := int64(0); i < 3; i++ {
+		block := &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{
+				Index: i,
+				Hash:  fmt.Sprintf("block %d", i),
+			},
+			ParentBlockIdentifier: parent,
+			Timestamp:             1600000000000 + i,
+		}
+		assert.NoError(t, blockStorage.SeeBlock(ctx, block))
+		assert.NoError(t, blockStorage.AddBlock(ctx, block))
+		parent = block.BlockIdentifier
+	}
+
+	var buf bytes.Buffer
+	exported, err := ExportCheckpoints(ctx, blockStorage, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), exported)
+
+	checkpoints := []*Checkpoint{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var checkpoint Checkpoint
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &checkpoint))
+		checkpoints = append(checkpoints, &checkpoint)
+	}
+
+	assert.Equal(t, []*Checkpoint{
+		{Index: 0, Hash: "block 0", ParentHash: "block 0", Timestamp: 1600000000000},
+		{Index: 1, Hash: "block 1", ParentHash: "block 0", Timestamp: 1600000000001},
+		{Index: 2, Hash: "block 2", ParentHash: "block 1", Timestamp: 1600000000002},
+	}, checkpoints)
+}
+
+func TestLoadCheckpoints(t *testing.T) {
+	var tests = map[string]struct {
+		contents    string
+		checkpoints []*Checkpoint
+		err         bool
+	}{
+		"valid": {
+			contents: "{\"index\": 0, \"hash\": \"block 0\"}\n\n{\"index\": 10, \"hash\": \"block 10\"}\n",
+			checkpoints: []*Checkpoint{
+				{Index: 0, Hash: "block 0"},
+				{Index: 10, Hash: "block 10"},
+			},
+		},
+		"empty": {
+			contents: "\n",
+			err:      true,
+		},
+		"invalid json": {
+			contents: "{\"index\": 0,",
+			err:      true,
+		},
+		"missing hash": {
+			contents: "{\"index\": 0}",
+			err:      true,
+		},
+		"out of order": {
+			contents: "{\"index\": 10, \"hash\": \"block 10\"}\n{\"index\": 10, \"hash\": \"block 10\"}",
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := utils.CreateTempDir()
+			assert.NoError(t, err)
+			defer utils.RemoveTempDir(dir)
+
+			filePath := path.Join(dir, "checkpoints.ndjson")
+			assert.NoError(t, ioutil.WriteFile(filePath, []byte(test.contents), 0600))
+
+			checkpoints, err := LoadCheckpoints(filePath)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.checkpoints, checkpoints)
+		})
+	}
+}
+
+func TestCheckpointChain(t *testing.T) {
+	block := func(index int64, hash string, parentHash string) *types.Block {
+		return &types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Index: index, Hash: hash},
+			ParentBlockIdentifier: &types.BlockIdentifier{Index: index - 1, Hash: parentHash},
+		}
+	}
+	checkpoints := []*Checkpoint{
+		{Index: 10, Hash: "block 10", ParentHash: "block 9"},
+		{Index: 13, Hash: "block 13"},
+	}
+
+	var tests = map[string]struct {
+		blocks []*types.Block
+		err    bool
+	}{
+		"valid": {
+			blocks: []*types.Block{
+				block(10, "block 10", "block 9"),
+				block(11, "block 11", "block 10"),
+				nil,
+				block(13, "block 13", "block 11"),
+			},
+		},
+		"checkpoint hash mismatch": {
+			blocks: []*types.Block{
+				block(10, "block 10", "block 9"),
+				block(11, "block 11", "block 10"),
+				block(12, "block 12", "block 11"),
+				block(13, "other 13", "block 12"),
+			},
+			err: true,
+		},
+		"checkpoint parent hash mismatch": {
+			blocks: []*types.Block{
+				block(10, "block 10", "other 9"),
+			},
+			err: true,
+		},
+		"broken hash chain": {
+			blocks: []*types.Block{
+				block(10, "block 10", "block 9"),
+				block(11, "block 11", "other 10"),
+			},
+			err: true,
+		},
+		"wrong index": {
+			blocks: []*types.Block{
+				block(10, "block 10", "block 9"),
+				block(12, "block 12", "block 10"),
+			},
+			err: true,
+		},
+		"omitted checkpoint": {
+			blocks: []*types.Block{
+				nil,
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			chain := NewCheckpointChain(checkpoints)
+
+			var err error
+			for i, block := range test.blocks {
+				if err = chain.Add(checkpoints[0].Index+int64(i), block); err != nil {
+					break
+				}
+			}
+
+			if test.err {
+				assert.True(t, errors.Is(err, results.ErrCheckpointMismatch))
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+
+	// Blocks must be added in order
+	chain := NewCheckpointChain(checkpoints)
+	assert.Error(t, chain.Add(11, block(11, "block 11", "block 10")))
+}
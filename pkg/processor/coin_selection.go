@@ -0,0 +1,80 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// SelectCoins orders coins according to a
+// configuration.CoinSelectionStrategy. The coordinator selects
+// the first coin (in the returned order) that satisfies the
+// minimum balance requested by a workflow.
+func SelectCoins(
+	coins []*types.Coin,
+	strategy configuration.CoinSelectionStrategy,
+) ([]*types.Coin, error) {
+	switch strategy {
+	case "":
+		return coins, nil
+	case configuration.RandomCoinSelection:
+		selected := make([]*types.Coin, len(coins))
+		copy(selected, coins)
+		rand.Shuffle(len(selected), func(i, j int) { // #nosec G404
+			selected[i], selected[j] = selected[j], selected[i]
+		})
+
+		return selected, nil
+	case configuration.LargestFirstCoinSelection, configuration.SmallestFirstCoinSelection:
+	default:
+		return nil, fmt.Errorf("coin selection strategy %s is not supported", strategy)
+	}
+
+	values := make(map[string]*big.Int, len(coins))
+	for _, coin := range coins {
+		value, err := types.AmountValue(coin.Amount)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to parse value of coin %s",
+				err,
+				coin.CoinIdentifier.Identifier,
+			)
+		}
+
+		values[coin.CoinIdentifier.Identifier] = value
+	}
+
+	selected := make([]*types.Coin, len(coins))
+	copy(selected, coins)
+	sort.SliceStable(selected, func(i, j int) bool {
+		cmp := values[selected[i].CoinIdentifier.Identifier].Cmp(
+			values[selected[j].CoinIdentifier.Identifier],
+		)
+		if strategy == configuration.LargestFirstCoinSelection {
+			return cmp > 0
+		}
+
+		return cmp < 0
+	})
+
+	return selected, nil
+}
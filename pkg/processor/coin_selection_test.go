@@ -0,0 +1,100 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectCoins(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	coin := func(identifier string, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: identifier},
+			Amount:         &types.Amount{Value: value, Currency: currency},
+		}
+	}
+	identifiers := func(coins []*types.Coin) []string {
+		ids := make([]string, len(coins))
+		for i, c := range coins {
+			ids[i] = c.CoinIdentifier.Identifier
+		}
+
+		return ids
+	}
+
+	coins := []*types.Coin{
+		coin("a", "100"),
+		coin("b", "1000"),
+		coin("c", "10"),
+		coin("d", "100"),
+	}
+
+	var tests = map[string]struct {
+		strategy configuration.CoinSelectionStrategy
+
+		expected []string
+		err      bool
+	}{
+		"storage order": {
+			expected: []string{"a", "b", "c", "d"},
+		},
+		"largest first": {
+			strategy: configuration.LargestFirstCoinSelection,
+			expected: []string{"b", "a", "d", "c"},
+		},
+		"smallest first": {
+			strategy: configuration.SmallestFirstCoinSelection,
+			expected: []string{"c", "a", "d", "b"},
+		},
+		"unsupported": {
+			strategy: "oldest_first",
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selected, err := SelectCoins(coins, test.strategy)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, identifiers(selected))
+		})
+	}
+
+	t.Run("random", func(t *testing.T) {
+		selected, err := SelectCoins(coins, configuration.RandomCoinSelection)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, identifiers(selected))
+		assert.Equal(t, []string{"a", "b", "c", "d"}, identifiers(coins))
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := SelectCoins(
+			[]*types.Coin{coin("a", "blah")},
+			configuration.SmallestFirstCoinSelection,
+		)
+		assert.Error(t, err)
+	})
+}
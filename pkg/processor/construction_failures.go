@@ -0,0 +1,125 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// ConstructionFailure contains everything needed to replay a
+// failed construction step offline: the request and response (or
+// error) of the failed call, all artifacts produced by earlier
+// steps, and the *results.RunMetadata of the run that failed.
+type ConstructionFailure struct {
+	Run      *results.RunMetadata           `json:"run,omitempty"`
+	Step     configuration.ConstructionStep `json:"step"`
+	Endpoint string                         `json:"endpoint,omitempty"`
+	Error    string                         `json:"error"`
+	Request  interface{}                    `json:"request,omitempty"`
+	Response interface{}                    `json:"response,omitempty"`
+
+	Network             *types.NetworkIdentifier `json:"network_identifier,omitempty"`
+	Intent              []*types.Operation       `json:"intent,omitempty"`
+	Metadata            map[string]interface{}   `json:"metadata,omitempty"`
+	PublicKeys          []*types.PublicKey       `json:"public_keys,omitempty"`
+	UnsignedTransaction string                   `json:"unsigned_transaction,omitempty"`
+	Payloads            []*types.SigningPayload  `json:"payloads,omitempty"`
+	Signatures          []*types.Signature       `json:"signatures,omitempty"`
+	NetworkTransaction  string                   `json:"network_transaction,omitempty"`
+}
+
+// FailureRecorder writes each ConstructionFailure to a
+// directory as JSON.
+type FailureRecorder struct {
+	directory string
+
+	lock  sync.Mutex
+	count int
+}
+
+// NewFailureRecorder returns a new *FailureRecorder that
+// writes to directory (creating it if it does not exist).
+func NewFailureRecorder(directory string) (*FailureRecorder, error) {
+	if err := os.MkdirAll(directory, os.FileMode(0700)); err != nil {
+		return nil, fmt.Errorf("%w: unable to create failure directory %s", err, directory)
+	}
+
+	return &FailureRecorder{directory: directory}, nil
+}
+
+// Record clusters failure in the results and writes it to the
+// failure directory. Errors are logged instead of returned so
+// that they don't mask the original failure. It is safe to call
+// Record on a nil *FailureRecorder (the failure is only
+// clustered).
+func (r *FailureRecorder) Record(failure *ConstructionFailure) {
+	results.RecordFailure(&results.FailureSignature{
+		Category:      results.ConstructionFailureCategory,
+		Code:          string(failure.Step),
+		OperationType: intentOperationTypes(failure.Intent),
+	}, failure.Error)
+
+	if r == nil {
+		return
+	}
+
+	failure.Run = results.CurrentRunMetadata()
+
+	r.lock.Lock()
+	r.count++
+	filePath := path.Join(
+		r.directory,
+		fmt.Sprintf("%d-%d-%s.json", time.Now().Unix(), r.count, failure.Step),
+	)
+	r.lock.Unlock()
+
+	if err := utils.SerializeAndWrite(filePath, failure); err != nil {
+		log.Printf("%s: unable to record construction failure\n", err.Error())
+		return
+	}
+
+	log.Printf("recorded %s failure at %s\n", failure.Step, filePath)
+}
+
+// intentOperationTypes returns the distinct operation
+// types in intent (sorted and comma-separated).
+func intentOperationTypes(intent []*types.Operation) string {
+	seen := map[string]struct{}{}
+	operationTypes := []string{}
+	for _, op := range intent {
+		if _, ok := seen[op.Type]; ok {
+			continue
+		}
+
+		seen[op.Type] = struct{}{}
+		operationTypes = append(operationTypes, op.Type)
+	}
+	sort.Strings(operationTypes)
+
+	return strings.Join(operationTypes, ",")
+}
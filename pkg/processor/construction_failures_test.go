@@ -0,0 +1,141 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureRecorder(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	var nilRecorder *FailureRecorder
+	nilRecorder.Record(&ConstructionFailure{Step: configuration.SignConstructionStep})
+
+	recorder, err := NewFailureRecorder(path.Join(dir, "failures"))
+	assert.NoError(t, err)
+
+	run, err := results.NewRunMetadata("fingerprint", "0.7.3", "node")
+	assert.NoError(t, err)
+	results.SetRunMetadata(run)
+	defer results.SetRunMetadata(nil)
+
+	helper := &CoordinatorHelper{
+		offlineFetcher:  &mockOfflineConstructor{},
+		intents:         map[string]*constructedIntent{},
+		quiet:           true,
+		failureRecorder: recorder,
+	}
+	network := &types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Testnet3"}
+	sender := &types.AccountIdentifier{Address: "sender"}
+	intent := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "Transfer",
+			Account:             sender,
+		},
+	}
+	payloads := []*types.SigningPayload{{AccountIdentifier: sender, Bytes: []byte("a")}}
+	signatures := []*types.Signature{
+		{SigningPayload: payloads[0], Bytes: []byte("signature")},
+	}
+
+	helper.recordIntent("unsigned", intent, payloads)
+	_, err = helper.Combine(ctx, network, "unsigned", signatures)
+	assert.Error(t, err)
+
+	_, _, _, err = helper.Parse(ctx, network, false, "unsigned")
+	assert.Error(t, err)
+
+	files, err := ioutil.ReadDir(path.Join(dir, "failures"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	steps := map[configuration.ConstructionStep]*ConstructionFailure{}
+	for _, file := range files {
+		var failure ConstructionFailure
+		assert.NoError(t, utils.LoadAndParse(path.Join(dir, "failures", file.Name()), &failure))
+		steps[failure.Step] = &failure
+	}
+
+	combineFailure := steps[configuration.CombineConstructionStep]
+	assert.NotNil(t, combineFailure)
+	assert.Equal(t, "combine failed", combineFailure.Error)
+	assert.Equal(t, constructionCombine, combineFailure.Endpoint)
+	assert.Equal(t, intent, combineFailure.Intent)
+	assert.Equal(t, "unsigned", combineFailure.UnsignedTransaction)
+	assert.Equal(t, payloads, combineFailure.Payloads)
+	assert.Equal(t, signatures, combineFailure.Signatures)
+	assert.NotNil(t, combineFailure.Request)
+	assert.Equal(t, run, combineFailure.Run)
+
+	parseFailure := steps[configuration.ParseConstructionStep]
+	assert.NotNil(t, parseFailure)
+	assert.Equal(t, "parse failed", parseFailure.Error)
+	assert.Equal(t, network, parseFailure.Network)
+	assert.Equal(t, intent, parseFailure.Intent)
+}
+
+func TestAssertParsed(t *testing.T) {
+	sender := &types.AccountIdentifier{Address: "sender"}
+	intent := &constructedIntent{
+		operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Type:                "Transfer",
+				Account:             sender,
+			},
+		},
+		signers:  []*types.AccountIdentifier{sender},
+		payloads: []*types.SigningPayload{{AccountIdentifier: sender, Bytes: []byte("a")}},
+	}
+
+	assert.NoError(t, assertParsed(nil, true, nil, nil))
+	assert.NoError(t, assertParsed(intent, false, intent.operations, nil))
+	assert.NoError(t, assertParsed(intent, true, intent.operations, intent.signers))
+
+	// Signers in unsigned transaction
+	assert.Error(t, assertParsed(intent, false, intent.operations, intent.signers))
+
+	// Parsed ops do not match intent
+	assert.Error(t, assertParsed(intent, false, []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "Other",
+			Account:             sender,
+		},
+	}, nil))
+
+	// Signers do not match intent
+	assert.Error(t, assertParsed(
+		intent,
+		true,
+		intent.operations,
+		[]*types.AccountIdentifier{{Address: "other"}},
+	))
+}
@@ -20,11 +20,19 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/coinbase/rosetta-sdk-go/constructor/coordinator"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
 )
@@ -62,12 +70,13 @@ var _ coordinator.Helper = (*CoordinatorHelper)(nil)
 // CoordinatorHelper implements the Coordinator.Helper
 // interface.
 type CoordinatorHelper struct {
-	offlineFetcher *fetcher.Fetcher
+	offlineFetcher OfflineConstructor
 	onlineFetcher  *fetcher.Fetcher
 
 	database         database.Database
 	blockStorage     *modules.BlockStorage
 	keyStorage       *modules.KeyStorage
+	signer           Signer
 	balanceStorage   *modules.BalanceStorage
 	coinStorage      *modules.CoinStorage
 	broadcastStorage *modules.BroadcastStorage
@@ -75,14 +84,184 @@ type CoordinatorHelper struct {
 
 	balanceStorageHelper *BalanceStorageHelper
 
+	// airGap, if populated, generates the keys of the address
+	// pool so that private keys are only stored by the
+	// offline-agent.
+	airGap *AirGapClient
+
+	// metadataCache is used to reuse /construction/metadata
+	// responses. If nil, metadata is always fetched.
+	metadataCache *MetadataCache
+
+	// metadataDelay is how long to wait after fetching
+	// metadata before returning it.
+	metadataDelay time.Duration
+
+	// stepDelayer, if populated, injects artificial
+	// delays before construction steps.
+	stepDelayer *StepDelayer
+
+	// feeEstimator, if populated, replaces the suggested_fee
+	// returned by /construction/metadata with a rolling estimate.
+	feeEstimator *FeeEstimator
+
+	// nonceTracker, if populated, assigns sender nonces locally
+	// so that senders don't need to be locked while a broadcast
+	// is pending.
+	nonceTracker *NonceTracker
+
+	// coinSelection determines the order in which coins
+	// are returned by Coins.
+	coinSelection configuration.CoinSelectionStrategy
+
+	// skipParse indicates that /construction/parse should not
+	// be called. Instead, the intent and signers provided to
+	// /construction/payloads are returned.
+	skipParse bool
+
+	// rateLimiter and maxPending, if populated, limit the
+	// rate at which transactions are created and the number
+	// of transactions pending at once (during a load test).
+	rateLimiter *RateLimiter
+	maxPending  int
+
+	// constructingLock protects constructing.
+	constructingLock sync.Mutex
+
+	// constructing is the number of sender pipeline transfers
+	// that reserved a load test slot and are not yet enqueued
+	// for broadcast (or were abandoned).
+	constructing int
+
+	// transactionAssertions, if populated, are checked
+	// against each signed transaction.
+	transactionAssertions *configuration.TransactionAssertionConfiguration
+
+	// multisigThreshold is the minimum number of distinct
+	// accounts that must be asked to sign each transaction.
+	// If 0, any number of signers is accepted.
+	multisigThreshold int
+
+	// injectedOperations are appended to the intent provided
+	// to /construction/preprocess and /construction/payloads.
+	injectedOperations []*configuration.InjectedOperation
+
 	// quiet determines if requests/responses logging
 	// should be silenced.
 	quiet bool
+
+	// failureRecorder, if populated, records the artifacts
+	// of each failed construction step.
+	failureRecorder *FailureRecorder
+
+	// addressBook, if populated, records when each
+	// stored key was created.
+	addressBook *AddressBookStorage
+
+	// addressReuse, if populated, excludes accounts from
+	// selection once they have been used in too many
+	// broadcasts.
+	addressReuse *AddressReuseTracker
+
+	// hdWallet, if populated, replaces the random key of
+	// each new account with a key derived from a mnemonic.
+	hdWallet *HDWallet
+
+	// derivedLock protects derived.
+	derivedLock sync.Mutex
+
+	// derived tracks the public key of every address
+	// returned by /construction/derive in this run so
+	// that collisions can be detected.
+	derived map[string]*types.PublicKey
+
+	// intentsLock protects intents.
+	intentsLock sync.Mutex
+
+	// intents tracks the intent of each unsigned and signed
+	// transaction constructed so that /construction/parse can be
+	// skipped (or its account metadata can be verified).
+	intents map[string]*constructedIntent
+
+	// paused is 1 when no new jobs should be processed.
+	// It is accessed atomically.
+	paused int32
+}
+
+// CoordinatorHelperOptions are the optional features of a
+// *CoordinatorHelper. The zero value disables all of them.
+type CoordinatorHelperOptions struct {
+	// Signer signs payloads. If nil, payloads are signed
+	// with the keys in KeyStorage.
+	Signer Signer
+
+	// AirGap, if populated, is sent every key stored (and
+	// generates the keys of the address pool).
+	AirGap *AirGapClient
+
+	// MetadataCache, if populated, is used to reuse
+	// /construction/metadata responses.
+	MetadataCache *MetadataCache
+
+	// MetadataDelay is how long to wait after fetching
+	// metadata before returning it.
+	MetadataDelay time.Duration
+
+	// StepDelayer, if populated, injects artificial
+	// delays before construction steps.
+	StepDelayer *StepDelayer
+
+	// FeeEstimator, if populated, replaces the suggested_fee
+	// returned by /construction/metadata with a rolling estimate.
+	FeeEstimator *FeeEstimator
+
+	// NonceTracker, if populated, assigns sender nonces locally.
+	NonceTracker *NonceTracker
+
+	// CoinSelection determines the order in which coins
+	// are returned by Coins.
+	CoinSelection configuration.CoinSelectionStrategy
+
+	// SkipParse indicates that /construction/parse should
+	// not be called.
+	SkipParse bool
+
+	// LoadTest, if populated, limits the rate at which
+	// transactions are created and the number pending.
+	LoadTest *configuration.LoadTestConfiguration
+
+	// TransactionAssertions, if populated, are checked
+	// against each signed transaction.
+	TransactionAssertions *configuration.TransactionAssertionConfiguration
+
+	// MultisigThreshold is the minimum number of distinct
+	// accounts that must be asked to sign each transaction.
+	MultisigThreshold int
+
+	// InjectedOperations are appended to each intent.
+	InjectedOperations []*configuration.InjectedOperation
+
+	// FailureRecorder, if populated, records the artifacts
+	// of each failed construction step.
+	FailureRecorder *FailureRecorder
+
+	// AddressBook, if populated, records when each
+	// stored key was created.
+	AddressBook *AddressBookStorage
+
+	// AddressReuse, if populated, excludes accounts used
+	// in too many broadcasts from selection.
+	AddressReuse *AddressReuseTracker
+
+	// HDWallet, if populated, replaces the random key of
+	// each new account with a key derived from a mnemonic.
+	HDWallet *HDWallet
 }
 
 // NewCoordinatorHelper returns a new *CoordinatorHelper.
+// If options is nil, no optional features are enabled.
 func NewCoordinatorHelper(
-	offlineFetcher *fetcher.Fetcher,
+	offlineFetcher OfflineConstructor,
 	onlineFetcher *fetcher.Fetcher,
 	database database.Database,
 	blockStorage *modules.BlockStorage,
@@ -93,20 +272,55 @@ func NewCoordinatorHelper(
 	balanceStorageHelper *BalanceStorageHelper,
 	counterStorage *modules.CounterStorage,
 	quiet bool,
+	options *CoordinatorHelperOptions,
 ) *CoordinatorHelper {
-	return &CoordinatorHelper{
-		offlineFetcher:       offlineFetcher,
-		onlineFetcher:        onlineFetcher,
-		database:             database,
-		blockStorage:         blockStorage,
-		keyStorage:           keyStorage,
-		balanceStorage:       balanceStorage,
-		coinStorage:          coinStorage,
-		broadcastStorage:     broadcastStorage,
-		counterStorage:       counterStorage,
-		balanceStorageHelper: balanceStorageHelper,
-		quiet:                quiet,
+	if options == nil {
+		options = &CoordinatorHelperOptions{}
+	}
+
+	var signer Signer = keyStorage
+	if options.Signer != nil {
+		signer = options.Signer
+	}
+
+	c := &CoordinatorHelper{
+		offlineFetcher:        offlineFetcher,
+		onlineFetcher:         onlineFetcher,
+		database:              database,
+		blockStorage:          blockStorage,
+		keyStorage:            keyStorage,
+		signer:                signer,
+		airGap:                options.AirGap,
+		balanceStorage:        balanceStorage,
+		coinStorage:           coinStorage,
+		broadcastStorage:      broadcastStorage,
+		counterStorage:        counterStorage,
+		balanceStorageHelper:  balanceStorageHelper,
+		metadataCache:         options.MetadataCache,
+		metadataDelay:         options.MetadataDelay,
+		stepDelayer:           options.StepDelayer,
+		feeEstimator:          options.FeeEstimator,
+		nonceTracker:          options.NonceTracker,
+		coinSelection:         options.CoinSelection,
+		skipParse:             options.SkipParse,
+		intents:               map[string]*constructedIntent{},
+		transactionAssertions: options.TransactionAssertions,
+		multisigThreshold:     options.MultisigThreshold,
+		injectedOperations:    options.InjectedOperations,
+		quiet:                 quiet,
+		failureRecorder:       options.FailureRecorder,
+		addressBook:           options.AddressBook,
+		addressReuse:          options.AddressReuse,
+		hdWallet:              options.HDWallet,
+		derived:               map[string]*types.PublicKey{},
+	}
+
+	if options.LoadTest != nil {
+		c.rateLimiter = NewRateLimiter(options.LoadTest.TargetTPS)
+		c.maxPending = options.LoadTest.Concurrency
 	}
+
+	return c
 }
 
 // DatabaseTransaction returns a new write-ready database.Transaction.
@@ -134,23 +348,48 @@ func (c *CoordinatorHelper) verboseLog(reqres string, endpoint string, args ...a
 }
 
 // Derive returns a new address for a provided publicKey.
+// When using an HD wallet, the address is derived from
+// the key that replaces publicKey (see HDWallet.Replace)
+// so that StoreKey can store the derived key instead.
 func (c *CoordinatorHelper) Derive(
 	ctx context.Context,
 	networkIdentifier *types.NetworkIdentifier,
 	publicKey *types.PublicKey,
 	metadata map[string]interface{},
+) (*types.AccountIdentifier, map[string]interface{}, error) {
+	if c.hdWallet != nil {
+		keyPair, err := c.hdWallet.Replace(publicKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to replace key", err)
+		}
+
+		publicKey = keyPair.PublicKey
+	}
+
+	return c.derive(ctx, networkIdentifier, publicKey, metadata)
+}
+
+// derive calls the /construction/derive endpoint
+// on an offline node.
+func (c *CoordinatorHelper) derive(
+	ctx context.Context,
+	networkIdentifier *types.NetworkIdentifier,
+	publicKey *types.PublicKey,
+	metadata map[string]interface{},
 ) (*types.AccountIdentifier, map[string]interface{}, error) {
 	c.verboseLog(request, constructionDerive,
 		arg{argNetwork, networkIdentifier},
 		arg{"public_key", publicKey},
 		arg{argMetadata, metadata},
 	)
+	start := time.Now()
 	account, metadata, fetchErr := c.offlineFetcher.ConstructionDerive(
 		ctx,
 		networkIdentifier,
 		publicKey,
 		metadata,
 	)
+	results.RecordEndpointLatency(constructionDerive, time.Since(start))
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionDerive, arg{argError, fetchErr})
 		return nil, nil, fetchErr.Err
@@ -160,9 +399,40 @@ func (c *CoordinatorHelper) Derive(
 		arg{argAccount, account},
 		arg{argMetadata, metadata},
 	)
+
+	if err := c.recordDerived(account, publicKey); err != nil {
+		return nil, nil, err
+	}
+
 	return account, metadata, nil
 }
 
+// recordDerived records the public key an account was
+// derived from and returns an error if the account was
+// previously derived from a different public key.
+func (c *CoordinatorHelper) recordDerived(
+	account *types.AccountIdentifier,
+	publicKey *types.PublicKey,
+) error {
+	c.derivedLock.Lock()
+	defer c.derivedLock.Unlock()
+
+	key := types.Hash(account)
+	existing, ok := c.derived[key]
+	if ok && types.Hash(existing) != types.Hash(publicKey) {
+		return fmt.Errorf(
+			"%w: %s derived from public keys %s and %s",
+			results.ErrAddressCollision,
+			types.PrintStruct(account),
+			types.PrintStruct(existing),
+			types.PrintStruct(publicKey),
+		)
+	}
+
+	c.derived[key] = publicKey
+	return nil
+}
+
 // Preprocess calls the /construction/preprocess endpoint
 // on an offline node.
 func (c *CoordinatorHelper) Preprocess(
@@ -171,20 +441,44 @@ func (c *CoordinatorHelper) Preprocess(
 	intent []*types.Operation,
 	metadata map[string]interface{},
 ) (map[string]interface{}, []*types.AccountIdentifier, error) {
+	if err := c.stepDelayer.Wait(ctx, configuration.PreprocessConstructionStep); err != nil {
+		return nil, nil, err
+	}
+
+	intent, err := InjectOperations(intent, c.injectedOperations)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	c.verboseLog(request, constructionPreprocess,
 		arg{argNetwork, networkIdentifier},
 		arg{argIntent, intent},
 		arg{argMetadata, metadata},
 	)
+	start := time.Now()
 	options, requiredPublicKeys, fetchErr := c.offlineFetcher.ConstructionPreprocess(
 		ctx,
 		networkIdentifier,
 		intent,
 		metadata,
 	)
+	results.RecordEndpointLatency(constructionPreprocess, time.Since(start))
 
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionPreprocess, arg{argError, fetchErr})
+		c.failureRecorder.Record(&ConstructionFailure{
+			Step:     configuration.PreprocessConstructionStep,
+			Endpoint: constructionPreprocess,
+			Error:    fetchErr.Err.Error(),
+			Request: &types.ConstructionPreprocessRequest{
+				NetworkIdentifier: networkIdentifier,
+				Operations:        intent,
+				Metadata:          metadata,
+			},
+			Response: fetchErr.ClientErr,
+			Network:  networkIdentifier,
+			Intent:   intent,
+		})
 		return nil, nil, fetchErr.Err
 	}
 
@@ -203,20 +497,52 @@ func (c *CoordinatorHelper) Metadata(
 	metadataRequest map[string]interface{},
 	publicKeys []*types.PublicKey,
 ) (map[string]interface{}, []*types.Amount, error) {
+	if err := c.stepDelayer.Wait(ctx, configuration.MetadataConstructionStep); err != nil {
+		return nil, nil, err
+	}
+
 	c.verboseLog(request, constructionMetadata,
 		arg{argNetwork, networkIdentifier},
 		arg{argMetadata, metadataRequest},
 		arg{argPublicKeys, publicKeys},
 	)
+
+	cacheKey := metadataCacheKey(networkIdentifier, metadataRequest, publicKeys)
+	if c.metadataCache != nil {
+		if metadata, suggestedFee, ok := c.metadataCache.Get(cacheKey); ok {
+			c.verboseLog(response, constructionMetadata,
+				arg{argMetadata, metadata},
+				arg{"suggested_fee", suggestedFee},
+				arg{"cached", true},
+			)
+			return c.finalizeMetadata(publicKeys, metadata, suggestedFee)
+		}
+	}
+
+	start := time.Now()
 	metadata, suggestedFee, fetchErr := c.onlineFetcher.ConstructionMetadata(
 		ctx,
 		networkIdentifier,
 		metadataRequest,
 		publicKeys,
 	)
+	results.RecordEndpointLatency(constructionMetadata, time.Since(start))
 
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionMetadata, arg{argError, fetchErr})
+		c.failureRecorder.Record(&ConstructionFailure{
+			Step:     configuration.MetadataConstructionStep,
+			Endpoint: constructionMetadata,
+			Error:    fetchErr.Err.Error(),
+			Request: &types.ConstructionMetadataRequest{
+				NetworkIdentifier: networkIdentifier,
+				Options:           metadataRequest,
+				PublicKeys:        publicKeys,
+			},
+			Response:   fetchErr.ClientErr,
+			Network:    networkIdentifier,
+			PublicKeys: publicKeys,
+		})
 		return nil, nil, fetchErr.Err
 	}
 
@@ -224,7 +550,69 @@ func (c *CoordinatorHelper) Metadata(
 		arg{argMetadata, metadata},
 		arg{"suggested_fee", suggestedFee},
 	)
-	return metadata, suggestedFee, nil
+
+	if c.metadataCache != nil {
+		c.metadataCache.Set(cacheKey, metadata, suggestedFee)
+	}
+
+	if c.feeEstimator != nil {
+		if err := c.feeEstimator.Record(suggestedFee); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// When testing metadata expiry, we intentionally hold on to
+	// the fetched metadata before it is used to construct
+	// and submit a transaction.
+	if c.metadataDelay > 0 {
+		log.Printf("delaying use of fetched metadata for %s\n", c.metadataDelay)
+		timer := time.NewTimer(c.metadataDelay)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return c.finalizeMetadata(publicKeys, metadata, suggestedFee)
+}
+
+// finalizeMetadata assigns a tracked nonce (if nonceTracker is
+// populated) and estimates the fee of fetched or cached metadata.
+func (c *CoordinatorHelper) finalizeMetadata(
+	publicKeys []*types.PublicKey,
+	metadata map[string]interface{},
+	suggestedFee []*types.Amount,
+) (map[string]interface{}, []*types.Amount, error) {
+	if c.nonceTracker != nil {
+		var err error
+		metadata, err = c.nonceTracker.Assign(publicKeys, metadata)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to assign nonce", err)
+		}
+	}
+
+	return c.estimateFee(metadata, suggestedFee)
+}
+
+// estimateFee replaces suggestedFee with the rolling
+// estimate from feeEstimator (if populated).
+func (c *CoordinatorHelper) estimateFee(
+	metadata map[string]interface{},
+	suggestedFee []*types.Amount,
+) (map[string]interface{}, []*types.Amount, error) {
+	if c.feeEstimator == nil {
+		return metadata, suggestedFee, nil
+	}
+
+	estimate, err := c.feeEstimator.Estimate(suggestedFee)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return metadata, estimate, nil
 }
 
 // Payloads calls the /construction/payloads endpoint
@@ -236,11 +624,21 @@ func (c *CoordinatorHelper) Payloads(
 	requiredMetadata map[string]interface{},
 	publicKeys []*types.PublicKey,
 ) (string, []*types.SigningPayload, error) {
+	if err := c.stepDelayer.Wait(ctx, configuration.PayloadsConstructionStep); err != nil {
+		return "", nil, err
+	}
+
+	intent, err := InjectOperations(intent, c.injectedOperations)
+	if err != nil {
+		return "", nil, err
+	}
+
 	c.verboseLog(request, constructionPayloads,
 		arg{argNetwork, networkIdentifier},
 		arg{argIntent, intent},
 		arg{argPublicKeys, publicKeys},
 	)
+	start := time.Now()
 	res, payloads, fetchErr := c.offlineFetcher.ConstructionPayloads(
 		ctx,
 		networkIdentifier,
@@ -248,9 +646,26 @@ func (c *CoordinatorHelper) Payloads(
 		requiredMetadata,
 		publicKeys,
 	)
+	results.RecordEndpointLatency(constructionPayloads, time.Since(start))
 
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionPayloads, arg{argError, fetchErr})
+		c.failureRecorder.Record(&ConstructionFailure{
+			Step:     configuration.PayloadsConstructionStep,
+			Endpoint: constructionPayloads,
+			Error:    fetchErr.Err.Error(),
+			Request: &types.ConstructionPayloadsRequest{
+				NetworkIdentifier: networkIdentifier,
+				Operations:        intent,
+				Metadata:          requiredMetadata,
+				PublicKeys:        publicKeys,
+			},
+			Response:   fetchErr.ClientErr,
+			Network:    networkIdentifier,
+			Intent:     intent,
+			Metadata:   requiredMetadata,
+			PublicKeys: publicKeys,
+		})
 		return "", nil, fetchErr.Err
 	}
 
@@ -258,9 +673,165 @@ func (c *CoordinatorHelper) Payloads(
 		arg{argUnsignedTransaction, res},
 		arg{"payloads", payloads},
 	)
+
+	c.recordIntent(res, intent, payloads)
+
 	return res, payloads, nil
 }
 
+// constructedIntent is the intent and signers
+// of a constructed transaction (and the artifacts
+// produced while constructing it).
+type constructedIntent struct {
+	operations []*types.Operation
+	signers    []*types.AccountIdentifier
+
+	unsignedTransaction string
+	payloads            []*types.SigningPayload
+	signatures          []*types.Signature
+	networkTransaction  string
+}
+
+// failure returns a *ConstructionFailure populated
+// with the artifacts of a constructed transaction.
+func (i *constructedIntent) failure(
+	step configuration.ConstructionStep,
+	endpoint string,
+	network *types.NetworkIdentifier,
+	err error,
+) *ConstructionFailure {
+	failure := &ConstructionFailure{
+		Step:     step,
+		Endpoint: endpoint,
+		Error:    err.Error(),
+		Network:  network,
+	}
+	if i == nil {
+		return failure
+	}
+
+	failure.Intent = i.operations
+	failure.UnsignedTransaction = i.unsignedTransaction
+	failure.Payloads = i.payloads
+	failure.Signatures = i.signatures
+	failure.NetworkTransaction = i.networkTransaction
+	return failure
+}
+
+// recordIntent tracks the intent and signers of an unsigned
+// transaction so that /construction/parse can be skipped (or
+// its account metadata can be verified).
+func (c *CoordinatorHelper) recordIntent(
+	unsignedTransaction string,
+	intent []*types.Operation,
+	payloads []*types.SigningPayload,
+) {
+	signers := []*types.AccountIdentifier{}
+	seen := map[string]struct{}{}
+	for _, payload := range payloads {
+		key := types.Hash(payload.AccountIdentifier)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		signers = append(signers, payload.AccountIdentifier)
+	}
+
+	c.intentsLock.Lock()
+	defer c.intentsLock.Unlock()
+
+	c.intents[unsignedTransaction] = &constructedIntent{
+		operations:          intent,
+		signers:             signers,
+		unsignedTransaction: unsignedTransaction,
+		payloads:            payloads,
+	}
+}
+
+// signedIntent tracks the intent of an unsigned transaction
+// under its signed transaction.
+func (c *CoordinatorHelper) signedIntent(
+	unsignedTransaction string,
+	signedTransaction string,
+	signatures ...*types.Signature,
+) {
+	c.intentsLock.Lock()
+	defer c.intentsLock.Unlock()
+
+	intent, ok := c.intents[unsignedTransaction]
+	if !ok {
+		return
+	}
+
+	intent.signatures = signatures
+	intent.networkTransaction = signedTransaction
+	c.intents[signedTransaction] = intent
+	delete(c.intents, unsignedTransaction)
+}
+
+// peekIntent returns the recorded intent of a transaction
+// (or nil if none was recorded) without removing it.
+func (c *CoordinatorHelper) peekIntent(transaction string) *constructedIntent {
+	c.intentsLock.Lock()
+	defer c.intentsLock.Unlock()
+
+	return c.intents[transaction]
+}
+
+// lookupIntent returns the recorded intent of a transaction
+// (or nil if none was recorded).
+func (c *CoordinatorHelper) lookupIntent(signed bool, transaction string) *constructedIntent {
+	c.intentsLock.Lock()
+	defer c.intentsLock.Unlock()
+
+	intent, ok := c.intents[transaction]
+	if !ok {
+		return nil
+	}
+
+	// The signed transaction is parsed last, so we no
+	// longer need to track it.
+	if signed {
+		delete(c.intents, transaction)
+	}
+
+	return intent
+}
+
+// parseIntent returns the recorded intent of a transaction
+// (in place of calling /construction/parse). Signers are only
+// returned for signed transactions.
+func (c *CoordinatorHelper) parseIntent(
+	signed bool,
+	transaction string,
+) ([]*types.Operation, []*types.AccountIdentifier, map[string]interface{}, error) {
+	intent := c.lookupIntent(signed, transaction)
+	if intent == nil {
+		return nil, nil, nil, fmt.Errorf(
+			"/construction/parse is skipped but no intent was recorded for transaction %s",
+			transaction,
+		)
+	}
+
+	if !signed {
+		return intent.operations, nil, nil, nil
+	}
+
+	return intent.operations, intent.signers, nil, nil
+}
+
+// parseEndpoint returns the endpoint that /construction/parse
+// latencies are recorded under (signed and unsigned transactions
+// are recorded separately).
+func parseEndpoint(signed bool) string {
+	if signed {
+		return constructionParse + " (signed)"
+	}
+
+	return constructionParse + " (unsigned)"
+}
+
 // Parse calls the /construction/parse endpoint
 // using the offline node.
 func (c *CoordinatorHelper) Parse(
@@ -269,20 +840,45 @@ func (c *CoordinatorHelper) Parse(
 	signed bool,
 	transaction string,
 ) ([]*types.Operation, []*types.AccountIdentifier, map[string]interface{}, error) {
+	if c.skipParse {
+		return c.parseIntent(signed, transaction)
+	}
+
+	if err := c.stepDelayer.Wait(ctx, configuration.ParseConstructionStep); err != nil {
+		return nil, nil, nil, err
+	}
+
 	c.verboseLog(request, constructionParse,
 		arg{argNetwork, networkIdentifier},
 		arg{"signed", signed},
 		arg{"transaction", transaction},
 	)
+	start := time.Now()
 	ops, signers, metadata, fetchErr := c.offlineFetcher.ConstructionParse(
 		ctx,
 		networkIdentifier,
 		signed,
 		transaction,
 	)
+	results.RecordEndpointLatency(parseEndpoint(signed), time.Since(start))
 
+	intent := c.lookupIntent(signed, transaction)
+	parseRequest := &types.ConstructionParseRequest{
+		NetworkIdentifier: networkIdentifier,
+		Signed:            signed,
+		Transaction:       transaction,
+	}
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionParse, arg{argError, fetchErr})
+		failure := intent.failure(
+			configuration.ParseConstructionStep,
+			constructionParse,
+			networkIdentifier,
+			fetchErr.Err,
+		)
+		failure.Request = parseRequest
+		failure.Response = fetchErr.ClientErr
+		c.failureRecorder.Record(failure)
 		return nil, nil, nil, fetchErr.Err
 	}
 
@@ -291,9 +887,77 @@ func (c *CoordinatorHelper) Parse(
 		arg{"signers", signers},
 		arg{argMetadata, metadata},
 	)
+
+	err := assertParsed(intent, signed, ops, signers)
+	if err == nil && signed {
+		err = AssertSignerThreshold(signers, c.multisigThreshold)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: /construction/parse", err)
+		failure := intent.failure(
+			configuration.ParseConstructionStep,
+			constructionParse,
+			networkIdentifier,
+			err,
+		)
+		failure.Request = parseRequest
+		failure.Response = &types.ConstructionParseResponse{
+			Operations:               ops,
+			AccountIdentifierSigners: signers,
+			Metadata:                 metadata,
+		}
+		c.failureRecorder.Record(failure)
+		return nil, nil, nil, err
+	}
+
 	return ops, signers, metadata, nil
 }
 
+// assertParsed returns an error if the operations and signers
+// returned by /construction/parse don't match the recorded intent
+// of a transaction. The coordinator performs the same comparison
+// but we check here so that the failure can be recorded (and
+// dropped or mangled account metadata can be described).
+func assertParsed(
+	intent *constructedIntent,
+	signed bool,
+	ops []*types.Operation,
+	signers []*types.AccountIdentifier,
+) error {
+	if intent == nil {
+		return nil
+	}
+
+	if err := AssertAccountMetadata(intent.operations, ops); err != nil {
+		return err
+	}
+
+	if err := (&parser.Parser{}).ExpectedOperations(intent.operations, ops, false, false); err != nil {
+		return fmt.Errorf("%w: parsed ops do not match intent", err)
+	}
+
+	if !signed {
+		if len(signers) != 0 {
+			return fmt.Errorf(
+				"signers should be empty in unsigned transaction but found %d",
+				len(signers),
+			)
+		}
+
+		return nil
+	}
+
+	if err := AssertSignerMetadata(intent.signers, signers); err != nil {
+		return err
+	}
+
+	if err := parser.ExpectedSigners(intent.payloads, signers); err != nil {
+		return fmt.Errorf("%w: signers do not match intent", err)
+	}
+
+	return nil
+}
+
 // Combine calls the /construction/combine endpoint
 // using the offline node.
 func (c *CoordinatorHelper) Combine(
@@ -302,24 +966,56 @@ func (c *CoordinatorHelper) Combine(
 	unsignedTransaction string,
 	signatures []*types.Signature,
 ) (string, error) {
+	if err := c.stepDelayer.Wait(ctx, configuration.CombineConstructionStep); err != nil {
+		return "", err
+	}
+
 	c.verboseLog(request, constructionCombine,
 		arg{argNetwork, networkIdentifier},
 		arg{argUnsignedTransaction, unsignedTransaction},
 		arg{"signatures", signatures},
 	)
+	start := time.Now()
 	res, fetchErr := c.offlineFetcher.ConstructionCombine(
 		ctx,
 		networkIdentifier,
 		unsignedTransaction,
 		signatures,
 	)
+	results.RecordEndpointLatency(constructionCombine, time.Since(start))
 
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionCombine, arg{argError, fetchErr})
+		failure := c.peekIntent(unsignedTransaction).failure(
+			configuration.CombineConstructionStep,
+			constructionCombine,
+			networkIdentifier,
+			fetchErr.Err,
+		)
+		failure.Request = &types.ConstructionCombineRequest{
+			NetworkIdentifier:   networkIdentifier,
+			UnsignedTransaction: unsignedTransaction,
+			Signatures:          signatures,
+		}
+		failure.Response = fetchErr.ClientErr
+		failure.UnsignedTransaction = unsignedTransaction
+		failure.Signatures = signatures
+		c.failureRecorder.Record(failure)
 		return "", fetchErr.Err
 	}
 
 	c.verboseLog(response, constructionCombine, arg{argNetworkTransaction, res})
+
+	if err := AssertSignedTransactionSize(
+		c.transactionAssertions,
+		unsignedTransaction,
+		res,
+	); err != nil {
+		return "", err
+	}
+
+	c.signedIntent(unsignedTransaction, res, signatures...)
+
 	return res, nil
 }
 
@@ -330,18 +1026,36 @@ func (c *CoordinatorHelper) Hash(
 	networkIdentifier *types.NetworkIdentifier,
 	networkTransaction string,
 ) (*types.TransactionIdentifier, error) {
+	if err := c.stepDelayer.Wait(ctx, configuration.HashConstructionStep); err != nil {
+		return nil, err
+	}
+
 	c.verboseLog(request, constructionHash,
 		arg{argNetwork, networkIdentifier},
 		arg{argNetworkTransaction, networkTransaction},
 	)
+	start := time.Now()
 	res, fetchErr := c.offlineFetcher.ConstructionHash(
 		ctx,
 		networkIdentifier,
 		networkTransaction,
 	)
+	results.RecordEndpointLatency(constructionHash, time.Since(start))
 
 	if fetchErr != nil {
 		c.verboseLog(reqerror, constructionHash, arg{argError, fetchErr})
+		c.failureRecorder.Record(&ConstructionFailure{
+			Step:     configuration.HashConstructionStep,
+			Endpoint: constructionHash,
+			Error:    fetchErr.Err.Error(),
+			Request: &types.ConstructionHashRequest{
+				NetworkIdentifier: networkIdentifier,
+				SignedTransaction: networkTransaction,
+			},
+			Response:           fetchErr.ClientErr,
+			Network:            networkIdentifier,
+			NetworkTransaction: networkTransaction,
+		})
 		return nil, fetchErr.Err
 	}
 
@@ -350,12 +1064,80 @@ func (c *CoordinatorHelper) Hash(
 }
 
 // Sign invokes the KeyStorage backend
-// to sign some payloads.
+// to sign some payloads. Payloads may be requested
+// from several different accounts (ex: a multisig
+// transaction), in which case signatures are collected
+// from each stored key before /construction/combine.
 func (c *CoordinatorHelper) Sign(
 	ctx context.Context,
 	payloads []*types.SigningPayload,
 ) ([]*types.Signature, error) {
-	return c.keyStorage.Sign(ctx, payloads)
+	if err := c.stepDelayer.Wait(ctx, configuration.SignConstructionStep); err != nil {
+		return nil, err
+	}
+
+	if err := AssertMultisigThreshold(payloads, c.multisigThreshold); err != nil {
+		return nil, err
+	}
+
+	signatures, err := c.signer.Sign(ctx, payloads)
+	if err != nil {
+		c.failureRecorder.Record(&ConstructionFailure{
+			Step:     configuration.SignConstructionStep,
+			Error:    err.Error(),
+			Payloads: payloads,
+		})
+		return nil, err
+	}
+
+	for _, signature := range signatures {
+		if signature.PublicKey == nil {
+			continue
+		}
+
+		counter := results.SignaturesCounter(signature.PublicKey.CurveType)
+		if _, err := c.counterStorage.Update(ctx, counter, big.NewInt(1)); err != nil {
+			return nil, fmt.Errorf("%w: unable to update %s counter", err, counter)
+		}
+	}
+
+	return signatures, nil
+}
+
+// AssertMultisigThreshold returns an error if payloads
+// do not request signatures from at least threshold
+// distinct accounts.
+func AssertMultisigThreshold(payloads []*types.SigningPayload, threshold int) error {
+	signers := make([]*types.AccountIdentifier, len(payloads))
+	for i, payload := range payloads {
+		signers[i] = payload.AccountIdentifier
+	}
+
+	return AssertSignerThreshold(signers, threshold)
+}
+
+// AssertSignerThreshold returns an error if signers
+// does not contain at least threshold distinct accounts.
+func AssertSignerThreshold(signers []*types.AccountIdentifier, threshold int) error {
+	if threshold == 0 {
+		return nil
+	}
+
+	distinct := map[string]struct{}{}
+	for _, signer := range signers {
+		distinct[types.Hash(signer)] = struct{}{}
+	}
+
+	if len(distinct) < threshold {
+		return fmt.Errorf(
+			"%w: %d signers but threshold is %d",
+			results.ErrMultisigThresholdNotMet,
+			len(distinct),
+			threshold,
+		)
+	}
+
+	return nil
 }
 
 // GetKey is called to get the *types.KeyPair
@@ -369,29 +1151,158 @@ func (c *CoordinatorHelper) GetKey(
 }
 
 // StoreKey stores a KeyPair and address
-// in KeyStorage.
+// in KeyStorage. When using an HD wallet, the
+// public key of the derived key that replaced
+// keyPair is stored with its derivation path
+// instead.
 func (c *CoordinatorHelper) StoreKey(
 	ctx context.Context,
 	dbTx database.Transaction,
 	account *types.AccountIdentifier,
 	keyPair *keys.KeyPair,
 ) error {
+	var derivedKey *hdKey
+	if c.hdWallet != nil {
+		if key, ok := c.hdWallet.lookup(keyPair.PublicKey); ok {
+			derivedKey = key
+			keyPair = &keys.KeyPair{PublicKey: key.keyPair.PublicKey}
+		}
+	}
+
+	// KeyStorage refuses to overwrite an existing account, but we check
+	// explicitly so that an implementation deriving the same address
+	// for a different key is reported as a collision.
+	existing, err := c.keyStorage.GetTransactional(ctx, dbTx, account)
+	if err == nil {
+		if types.Hash(existing.PublicKey) != types.Hash(keyPair.PublicKey) {
+			return fmt.Errorf(
+				"%w: %s already stored for public key %s (new public key %s)",
+				results.ErrAddressCollision,
+				types.PrintStruct(account),
+				types.PrintStruct(existing.PublicKey),
+				types.PrintStruct(keyPair.PublicKey),
+			)
+		}
+	} else if !errors.Is(err, storageErrs.ErrAddrNotFound) {
+		return err
+	}
+
 	// We optimisically add the interesting address although the dbTx could be reverted.
 	c.balanceStorageHelper.AddInterestingAddress(account.Address)
 
-	_, _ = c.counterStorage.UpdateTransactional(
+	if _, err := c.counterStorage.UpdateTransactional(
 		ctx,
 		dbTx,
 		modules.AddressesCreatedCounter,
 		big.NewInt(1),
-	)
+	); err != nil {
+		return fmt.Errorf("%w: unable to update %s counter", err, modules.AddressesCreatedCounter)
+	}
+
+	if c.addressBook != nil {
+		if err := c.addressBook.RecordCreated(ctx, dbTx, account, time.Now()); err != nil {
+			return fmt.Errorf("%w: unable to record address creation", err)
+		}
+	}
+
+	if derivedKey != nil {
+		if err := c.hdWallet.store(ctx, dbTx, account, derivedKey); err != nil {
+			return fmt.Errorf("%w: unable to store derivation path", err)
+		}
+	}
+
 	return c.keyStorage.StoreTransactional(ctx, account, keyPair, dbTx)
 }
 
+// PregenerateAddresses derives and stores new addresses
+// until there are at least size accounts in KeyStorage.
+// Keys are generated with curveTypes (see CurveMix).
+// Each address is stored in its own database transaction
+// so that progress is kept if generation is interrupted.
+// The number of addresses generated is returned.
+func (c *CoordinatorHelper) PregenerateAddresses(
+	ctx context.Context,
+	networkIdentifier *types.NetworkIdentifier,
+	curveTypes []*configuration.WeightedCurveType,
+	size int,
+	metadata map[string]interface{},
+) (int, error) {
+	accounts, err := c.keyStorage.GetAllAccounts(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to load addresses", err)
+	}
+
+	mix := NewCurveMix(curveTypes)
+	generated := 0
+	for i := len(accounts); i < size; i++ {
+		account, keyPair, err := c.generateKey(ctx, networkIdentifier, mix.Next(), metadata)
+		if err != nil {
+			return generated, err
+		}
+
+		dbTx := c.DatabaseTransaction(ctx)
+		if err := c.StoreKey(ctx, dbTx, account, keyPair); err != nil {
+			dbTx.Discard(ctx)
+			return generated, fmt.Errorf("%w: unable to store key", err)
+		}
+
+		if err := dbTx.Commit(ctx); err != nil {
+			return generated, fmt.Errorf("%w: unable to commit key", err)
+		}
+
+		generated++
+	}
+
+	return generated, nil
+}
+
+// generateKey generates a key of curveType and derives its
+// address. When using an air gap, the key is generated by the
+// offline-agent and only its public key is returned. When
+// using an HD wallet, the key at the next index is used.
+func (c *CoordinatorHelper) generateKey(
+	ctx context.Context,
+	networkIdentifier *types.NetworkIdentifier,
+	curveType types.CurveType,
+	metadata map[string]interface{},
+) (*types.AccountIdentifier, *keys.KeyPair, error) {
+	if c.airGap != nil {
+		account, publicKey, err := c.airGap.GenerateKey(ctx, networkIdentifier, curveType, metadata)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to generate key with offline-agent", err)
+		}
+
+		if err := c.recordDerived(account, publicKey); err != nil {
+			return nil, nil, err
+		}
+
+		return account, &keys.KeyPair{PublicKey: publicKey}, nil
+	}
+
+	var keyPair *keys.KeyPair
+	var err error
+	if c.hdWallet != nil {
+		keyPair, err = c.hdWallet.Next(curveType)
+	} else {
+		keyPair, err = keys.GenerateKeypair(curveType)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to generate keypair", err)
+	}
+
+	account, _, err := c.derive(ctx, networkIdentifier, keyPair.PublicKey, metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to derive address", err)
+	}
+
+	return account, keyPair, nil
+}
+
 // Balance returns the balance
 // for a provided address using BalanceStorage.
 // If the address balance does not exist,
-// 0 will be returned.
+// 0 will be returned. If nonces are tracked,
+// pending debits are subtracted.
 func (c *CoordinatorHelper) Balance(
 	ctx context.Context,
 	dbTx database.Transaction,
@@ -406,17 +1317,49 @@ func (c *CoordinatorHelper) Balance(
 		return nil, errors.New("no blocks synced")
 	}
 
-	return c.balanceStorage.GetOrSetBalanceTransactional(
+	balance, err := c.balanceStorage.GetOrSetBalanceTransactional(
 		ctx,
 		dbTx,
 		accountIdentifier,
 		currency,
 		headBlock,
 	)
+	if err != nil || c.nonceTracker == nil {
+		return balance, err
+	}
+
+	// Senders with pending broadcasts are not locked when
+	// nonces are tracked, so we subtract their pending debits
+	// to avoid selecting funds that are already being spent.
+	broadcasts, err := c.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	debits, err := PendingDebits(broadcasts, accountIdentifier, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := types.AmountValue(balance)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse balance", err)
+	}
+
+	spendable := new(big.Int).Sub(value, debits)
+	if spendable.Sign() < 0 {
+		spendable = big.NewInt(0)
+	}
+
+	return &types.Amount{
+		Value:    spendable.String(),
+		Currency: balance.Currency,
+	}, nil
 }
 
 // Coins returns all *types.Coin owned by
-// an account.
+// an account (ordered by the coin selection
+// strategy).
 func (c *CoordinatorHelper) Coins(
 	ctx context.Context,
 	dbTx database.Transaction,
@@ -441,16 +1384,48 @@ func (c *CoordinatorHelper) Coins(
 		coinsToReturn = append(coinsToReturn, coin)
 	}
 
-	return coinsToReturn, nil
+	return SelectCoins(coinsToReturn, c.coinSelection)
 }
 
 // LockedAccounts returns a slice of all accounts currently sending or receiving
-// funds.
+// funds (and all accounts excluded for reaching the maximum address reuse).
 func (c *CoordinatorHelper) LockedAccounts(
 	ctx context.Context,
 	dbTx database.Transaction,
 ) ([]*types.AccountIdentifier, error) {
-	return c.broadcastStorage.LockedAccounts(ctx, dbTx)
+	locked, err := c.pendingAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.addressReuse == nil {
+		return locked, nil
+	}
+
+	excluded, err := c.addressReuse.ExcludedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get excluded accounts", err)
+	}
+
+	return append(locked, excluded...), nil
+}
+
+// pendingAccounts returns a slice of all accounts currently
+// sending or receiving funds.
+func (c *CoordinatorHelper) pendingAccounts(
+	ctx context.Context,
+	dbTx database.Transaction,
+) ([]*types.AccountIdentifier, error) {
+	if c.nonceTracker == nil {
+		return c.broadcastStorage.LockedAccounts(ctx, dbTx)
+	}
+
+	broadcasts, err := c.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	return c.nonceTracker.LockedAccounts(broadcasts)
 }
 
 // AllBroadcasts returns a slice of all in-progress broadcasts in BroadcastStorage.
@@ -474,13 +1449,17 @@ func (c *CoordinatorHelper) Broadcast(
 	payload string,
 	confirmationDepth int64,
 ) error {
+	if err := c.stepDelayer.Wait(ctx, configuration.SubmitConstructionStep); err != nil {
+		return err
+	}
+
 	c.verboseLog(queue, constructionSubmit,
 		arg{argNetwork, network},
 		arg{argIntent, intent},
 		arg{argTransactionIdentifier, transactionIdentifier},
 		arg{argNetworkTransaction, payload},
 	)
-	return c.broadcastStorage.Broadcast(
+	if err := c.broadcastStorage.Broadcast(
 		ctx,
 		dbTx,
 		identifier,
@@ -489,7 +1468,39 @@ func (c *CoordinatorHelper) Broadcast(
 		transactionIdentifier,
 		payload,
 		confirmationDepth,
-	)
+	); err != nil {
+		return err
+	}
+
+	if c.addressReuse != nil {
+		exhausted, err := c.addressReuse.RecordUses(ctx, dbTx, intent)
+		if err != nil {
+			return fmt.Errorf("%w: unable to record address uses", err)
+		}
+
+		if len(exhausted) > 0 {
+			if _, err := c.counterStorage.UpdateTransactional(
+				ctx,
+				dbTx,
+				results.ExcludedAddressesCounter,
+				big.NewInt(int64(len(exhausted))),
+			); err != nil {
+				return fmt.Errorf("%w: unable to update %s counter", err, results.ExcludedAddressesCounter)
+			}
+		}
+
+		for _, account := range exhausted {
+			log.Printf("excluding %s after reaching max address reuse\n", types.AccountString(account))
+		}
+	}
+
+	// Pipeline transfers reserve their slot in reserveTransfer.
+	if c.rateLimiter != nil && !IsPipelineTransfer(identifier) {
+		c.rateLimiter.Take()
+	}
+
+	results.RecordTransactionCreated(identifier)
+	return nil
 }
 
 // BroadcastAll attempts to broadcast all ready transactions.
@@ -499,6 +1510,28 @@ func (c *CoordinatorHelper) BroadcastAll(
 	return c.broadcastStorage.BroadcastAll(ctx, true)
 }
 
+// BroadcastStatus returns the status of the pending broadcast
+// with identifier (the identifier provided to Broadcast). If the
+// broadcast is not pending (ex: because it was confirmed or it
+// failed), nil is returned.
+func (c *CoordinatorHelper) BroadcastStatus(
+	ctx context.Context,
+	identifier string,
+) (*results.BroadcastStatus, error) {
+	broadcasts, err := c.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	for _, broadcast := range broadcasts {
+		if broadcast.Identifier == identifier {
+			return results.NewBroadcastStatus(broadcast), nil
+		}
+	}
+
+	return nil, nil
+}
+
 // AllAccounts returns a slice of all known accounts.
 func (c *CoordinatorHelper) AllAccounts(
 	ctx context.Context,
@@ -507,9 +1540,100 @@ func (c *CoordinatorHelper) AllAccounts(
 	return c.keyStorage.GetAllAccountsTransactional(ctx, dbTx)
 }
 
+// loadAllowed waits until the rate limit allows another
+// transaction to be created and returns a boolean indicating
+// if fewer than maxPending transactions are pending (only
+// used during a load test).
+func (c *CoordinatorHelper) loadAllowed(ctx context.Context) bool {
+	if c.rateLimiter == nil {
+		return true
+	}
+
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return false
+	}
+
+	c.constructingLock.Lock()
+	defer c.constructingLock.Unlock()
+
+	return c.belowMaxPending(ctx)
+}
+
+// belowMaxPending returns a boolean indicating if fewer than
+// maxPending transactions are pending or being constructed
+// by sender pipelines. The caller must hold constructingLock.
+func (c *CoordinatorHelper) belowMaxPending(ctx context.Context) bool {
+	broadcasts, err := c.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return false
+	}
+
+	return len(broadcasts)+c.constructing < c.maxPending
+}
+
+// reserveTransfer waits until a sender pipeline may construct
+// a transfer and returns a function that must be called once
+// the transfer is enqueued for broadcast (or abandoned). If no
+// transfer may be constructed (ex: while paused or while a load
+// test is at its concurrency limit), nil is returned.
+//
+// Unlike the coordinator, pipelines construct transfers
+// concurrently, so each transfer reserves its own slot of the
+// load test rate and counts towards maxPending while it is
+// being constructed.
+func (c *CoordinatorHelper) reserveTransfer(ctx context.Context) func() {
+	if c.Paused() {
+		return nil
+	}
+
+	if headBlock, _ := c.blockStorage.GetHeadBlockIdentifier(ctx); headBlock == nil {
+		return nil
+	}
+
+	if c.rateLimiter == nil {
+		return func() {}
+	}
+
+	c.constructingLock.Lock()
+	if !c.belowMaxPending(ctx) {
+		c.constructingLock.Unlock()
+		return nil
+	}
+	c.constructing++
+	c.constructingLock.Unlock()
+
+	release := func() {
+		c.constructingLock.Lock()
+		defer c.constructingLock.Unlock()
+
+		c.constructing--
+	}
+
+	if err := c.rateLimiter.Acquire(ctx); err != nil {
+		release()
+		return nil
+	}
+
+	return release
+}
+
 // HeadBlockExists returns a boolean indicating if a block has been
 // synced by BlockStorage.
+//
+// The coordinator checks this before looking for any job to process,
+// so we also return false while paused (or while a load test is at
+// its concurrency limit). This stops the creation of new transactions
+// without interfering with broadcast tracking (which is driven by the
+// syncer).
 func (c *CoordinatorHelper) HeadBlockExists(ctx context.Context) bool {
+	if c.Paused() {
+		return false
+	}
+
+	if !c.loadAllowed(ctx) {
+		return false
+	}
+
 	headBlock, _ := c.blockStorage.GetHeadBlockIdentifier(ctx)
 
 	return headBlock != nil
@@ -541,3 +1665,21 @@ func (c *CoordinatorHelper) GetBlob(
 ) (bool, []byte, error) {
 	return dbTx.Get(ctx, kvKey(key))
 }
+
+// Pause stops the coordinator from processing new jobs
+// and returns a boolean indicating if it was running.
+func (c *CoordinatorHelper) Pause() bool {
+	return atomic.CompareAndSwapInt32(&c.paused, 0, 1)
+}
+
+// Resume allows the coordinator to process new jobs
+// and returns a boolean indicating if it was paused.
+func (c *CoordinatorHelper) Resume() bool {
+	return atomic.CompareAndSwapInt32(&c.paused, 1, 0)
+}
+
+// Paused returns a boolean indicating if the
+// coordinator is paused.
+func (c *CoordinatorHelper) Paused() bool {
+	return atomic.LoadInt32(&c.paused) == 1
+}
@@ -0,0 +1,260 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertMultisigThreshold(t *testing.T) {
+	payloads := []*types.SigningPayload{
+		{
+			AccountIdentifier: &types.AccountIdentifier{Address: "cosigner1"},
+			Bytes:             []byte("tx"),
+		},
+		{
+			AccountIdentifier: &types.AccountIdentifier{Address: "cosigner2"},
+			Bytes:             []byte("tx"),
+		},
+		{
+			AccountIdentifier: &types.AccountIdentifier{Address: "cosigner2"},
+			Bytes:             []byte("tx2"),
+		},
+	}
+
+	var tests = map[string]struct {
+		threshold int
+
+		err error
+	}{
+		"no threshold": {},
+		"threshold met": {
+			threshold: 2,
+		},
+		"threshold not met": {
+			threshold: 3,
+			err:       results.ErrMultisigThresholdNotMet,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := AssertMultisigThreshold(payloads, test.threshold)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			signers := make([]*types.AccountIdentifier, len(payloads))
+			for i, payload := range payloads {
+				signers[i] = payload.AccountIdentifier
+			}
+			err = AssertSignerThreshold(signers, test.threshold)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRecordDerived(t *testing.T) {
+	c := &CoordinatorHelper{derived: map[string]*types.PublicKey{}}
+	account := &types.AccountIdentifier{Address: "addr1"}
+	pk1 := &types.PublicKey{Bytes: []byte("pk1"), CurveType: types.Secp256k1}
+	pk2 := &types.PublicKey{Bytes: []byte("pk2"), CurveType: types.Secp256k1}
+
+	assert.NoError(t, c.recordDerived(account, pk1))
+
+	// Deriving the same address from the same key is fine
+	assert.NoError(t, c.recordDerived(account, pk1))
+
+	// A different account from a different key is fine
+	assert.NoError(t, c.recordDerived(&types.AccountIdentifier{Address: "addr2"}, pk2))
+
+	// The same account from a different key is a collision
+	assert.ErrorIs(t, c.recordDerived(account, pk2), results.ErrAddressCollision)
+}
+
+func TestPause(t *testing.T) {
+	helper := &CoordinatorHelper{}
+	assert.False(t, helper.Paused())
+	assert.False(t, helper.Resume())
+
+	assert.True(t, helper.Pause())
+	assert.False(t, helper.Pause())
+	assert.True(t, helper.Paused())
+	assert.False(t, helper.HeadBlockExists(context.Background()))
+
+	assert.True(t, helper.Resume())
+	assert.False(t, helper.Paused())
+}
+
+func TestReserveTransfer(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(db, 1)
+	helper := NewCoordinatorHelper(
+		nil,
+		nil,
+		db,
+		blockStorage,
+		nil,
+		nil,
+		nil,
+		modules.NewBroadcastStorage(db, 0, 0, 0, false, 0),
+		nil,
+		nil,
+		false,
+		&CoordinatorHelperOptions{
+			LoadTest: &configuration.LoadTestConfiguration{
+				TargetTPS:   1000,
+				Concurrency: 2,
+				Duration:    60,
+			},
+		},
+	)
+
+	// No transfers are constructed before a block is synced
+	assert.Nil(t, helper.reserveTransfer(ctx))
+
+	dbTx := db.Transaction(ctx)
+	assert.NoError(t, blockStorage.StoreHeadBlockIdentifier(
+		ctx,
+		dbTx,
+		&types.BlockIdentifier{Index: 1, Hash: "block 1"},
+	))
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	// Transfers being constructed count towards the concurrency
+	release1 := helper.reserveTransfer(ctx)
+	assert.NotNil(t, release1)
+	release2 := helper.reserveTransfer(ctx)
+	assert.NotNil(t, release2)
+	assert.Nil(t, helper.reserveTransfer(ctx))
+	assert.False(t, helper.HeadBlockExists(ctx))
+
+	release1()
+	release3 := helper.reserveTransfer(ctx)
+	assert.NotNil(t, release3)
+
+	release2()
+	release3()
+	assert.True(t, helper.HeadBlockExists(ctx))
+
+	helper.Pause()
+	assert.Nil(t, helper.reserveTransfer(ctx))
+}
+
+func TestBroadcastStatus(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	broadcastStorage := modules.NewBroadcastStorage(db, 0, 0, 0, false, 0)
+	helper := &CoordinatorHelper{broadcastStorage: broadcastStorage}
+
+	status, err := helper.BroadcastStatus(ctx, "transfer")
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+
+	dbTx := db.Transaction(ctx)
+	assert.NoError(t, broadcastStorage.Broadcast(
+		ctx,
+		dbTx,
+		"transfer",
+		&types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"},
+		[]*types.Operation{},
+		&types.TransactionIdentifier{Hash: "tx1"},
+		"payload",
+		3,
+	))
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	status, err = helper.BroadcastStatus(ctx, "transfer")
+	assert.NoError(t, err)
+	assert.Equal(t, &results.BroadcastStatus{
+		Identifier:            "transfer",
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+		ConfirmationDepth:     3,
+	}, status)
+
+	status, err = helper.BroadcastStatus(ctx, "other")
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestSkipParse(t *testing.T) {
+	ctx := context.Background()
+	helper := &CoordinatorHelper{
+		skipParse: true,
+		intents:   map[string]*constructedIntent{},
+	}
+	sender := &types.AccountIdentifier{Address: "sender"}
+	intent := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "Transfer",
+			Account:             sender,
+		},
+	}
+	payloads := []*types.SigningPayload{
+		{AccountIdentifier: sender, Bytes: []byte("a")},
+		{AccountIdentifier: sender, Bytes: []byte("b")},
+	}
+
+	_, _, _, err := helper.Parse(ctx, nil, false, "unsigned")
+	assert.Error(t, err)
+
+	helper.recordIntent("unsigned", intent, payloads)
+	ops, signers, metadata, err := helper.Parse(ctx, nil, false, "unsigned")
+	assert.NoError(t, err)
+	assert.Equal(t, intent, ops)
+	assert.Nil(t, signers)
+	assert.Nil(t, metadata)
+
+	helper.signedIntent("unsigned", "signed")
+	ops, signers, _, err = helper.Parse(ctx, nil, true, "signed")
+	assert.NoError(t, err)
+	assert.Equal(t, intent, ops)
+	assert.Equal(t, []*types.AccountIdentifier{sender}, signers)
+	assert.Len(t, helper.intents, 0)
+}
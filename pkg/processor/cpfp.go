@@ -0,0 +1,660 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+const (
+	// cpfpParentPrefix is the prefix of the broadcast identifier
+	// of the parent of each child-pays-for-parent scenario.
+	cpfpParentPrefix = "cpfp_parent:"
+
+	// cpfpChildPrefix is the prefix of the broadcast identifier
+	// of the child of each child-pays-for-parent scenario.
+	cpfpChildPrefix = "cpfp_child:"
+)
+
+// IsCPFP returns a boolean indicating if a broadcast identifier
+// belongs to the parent or child of a child-pays-for-parent scenario.
+func IsCPFP(identifier string) bool {
+	return strings.HasPrefix(identifier, cpfpParentPrefix) ||
+		strings.HasPrefix(identifier, cpfpChildPrefix)
+}
+
+// CPFPIntent returns the intent of a transaction that spends
+// coin held by account into a single output of value to the
+// same account.
+func CPFPIntent(
+	config *configuration.CPFPConfiguration,
+	account *types.AccountIdentifier,
+	coin *types.Coin,
+	value *big.Int,
+) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                config.InputOperationType,
+			Account:             account,
+			Amount: &types.Amount{
+				Value:    "-" + coin.Amount.Value,
+				Currency: coin.Amount.Currency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                config.OutputOperationType,
+			Account:             account,
+			Amount: &types.Amount{
+				Value:    value.String(),
+				Currency: config.Currency,
+			},
+		},
+	}
+}
+
+// CPFPParentFee returns the fee paid by the parent (a
+// fraction of the suggested fee).
+func CPFPParentFee(config *configuration.CPFPConfiguration, suggested *big.Int) *big.Int {
+	fee, _ := new(big.Float).Mul(
+		new(big.Float).SetInt(suggested),
+		big.NewFloat(config.ParentFeeMultiplier),
+	).Int(nil)
+
+	return fee
+}
+
+// CPFPChildFee returns the fee paid by the child (a multiple
+// of the suggested fee plus the deficit, the part of the
+// suggested fee of the parent that the parent did not pay).
+func CPFPChildFee(
+	config *configuration.CPFPConfiguration,
+	suggested *big.Int,
+	deficit *big.Int,
+) *big.Int {
+	fee, _ := new(big.Float).Mul(
+		new(big.Float).SetInt(suggested),
+		big.NewFloat(config.ChildFeeMultiplier),
+	).Int(nil)
+
+	return fee.Add(fee, deficit)
+}
+
+// CreatedCoin returns the first coin of currency created for
+// account by an operation of transaction (nil if there is none).
+func CreatedCoin(
+	transaction *types.Transaction,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+) *types.Coin {
+	for _, op := range transaction.Operations {
+		if op.CoinChange == nil ||
+			op.CoinChange.CoinAction != types.CoinCreated ||
+			op.Amount == nil ||
+			types.Hash(op.Account) != types.Hash(account) ||
+			types.Hash(op.Amount.Currency) != types.Hash(currency) {
+			continue
+		}
+
+		return &types.Coin{
+			CoinIdentifier: op.CoinChange.CoinIdentifier,
+			Amount:         op.Amount,
+		}
+	}
+
+	return nil
+}
+
+// SpendsCoin returns a boolean indicating if an operation of
+// transaction spends the coin with coinIdentifier.
+func SpendsCoin(transaction *types.Transaction, coinIdentifier *types.CoinIdentifier) bool {
+	for _, op := range transaction.Operations {
+		if op.CoinChange != nil &&
+			op.CoinChange.CoinAction == types.CoinSpent &&
+			op.CoinChange.CoinIdentifier.Identifier == coinIdentifier.Identifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cpfpScenario is the state of the child-pays-for-parent
+// scenario in progress.
+type cpfpScenario struct {
+	account *types.AccountIdentifier
+	started time.Time
+
+	parent *types.TransactionIdentifier
+
+	// deficit is the part of the suggested fee
+	// the parent did not pay.
+	deficit *big.Int
+
+	// change is the coin created by the parent (as exposed
+	// in the mempool). It is populated when the child is
+	// broadcast.
+	change *types.Coin
+	child  *types.TransactionIdentifier
+
+	// childVerified indicates if the child was seen in the
+	// mempool spending change (or was included in a block
+	// before it was seen).
+	childVerified bool
+}
+
+// CPFPRunner runs child-pays-for-parent scenarios (one at
+// a time) using accounts in the key store.
+type CPFPRunner struct {
+	network           *types.NetworkIdentifier
+	helper            *CoordinatorHelper
+	config            *configuration.CPFPConfiguration
+	confirmationDepth int64
+
+	scenario *cpfpScenario
+}
+
+// NewCPFPRunner returns a new *CPFPRunner.
+func NewCPFPRunner(
+	network *types.NetworkIdentifier,
+	helper *CoordinatorHelper,
+	config *configuration.CPFPConfiguration,
+	confirmationDepth int64,
+) *CPFPRunner {
+	return &CPFPRunner{
+		network:           network,
+		helper:            helper,
+		config:            config,
+		confirmationDepth: confirmationDepth,
+	}
+}
+
+// Start advances the scenario in progress (or starts a new one)
+// every configured interval until ctx is canceled or a scenario
+// fails verification.
+func (r *CPFPRunner) Start(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(r.config.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := r.Step(ctx); err != nil {
+				return fmt.Errorf("%w: unable to run cpfp scenario", err)
+			}
+		}
+	}
+}
+
+// Step advances the scenario in progress by as many
+// stages as possible. If no scenario is in progress, a
+// parent is broadcast (if an unlocked account holds a coin).
+func (r *CPFPRunner) Step(ctx context.Context) error {
+	if r.scenario == nil {
+		return r.broadcastParent(ctx)
+	}
+
+	timeout := time.Duration(r.config.Timeout) * time.Second
+	if time.Since(r.scenario.started) > timeout {
+		return fmt.Errorf(
+			"%w: scenario with parent %s did not complete within %s",
+			results.ErrCPFPVerification,
+			r.scenario.parent.Hash,
+			timeout,
+		)
+	}
+
+	if r.scenario.child == nil {
+		if err := r.broadcastChild(ctx); err != nil || r.scenario == nil || r.scenario.child == nil {
+			return err
+		}
+	}
+
+	if !r.scenario.childVerified {
+		if err := r.verifyChildInMempool(ctx); err != nil || !r.scenario.childVerified {
+			return err
+		}
+	}
+
+	return r.verifyConfirmed(ctx)
+}
+
+// suggestedFee returns the value of the suggested
+// fee in the configured currency.
+func (r *CPFPRunner) suggestedFee(suggestedFee []*types.Amount) (*big.Int, error) {
+	fee := new(big.Int)
+	for _, amount := range suggestedFee {
+		if types.Hash(amount.Currency) != types.Hash(r.config.Currency) {
+			continue
+		}
+
+		value, err := types.AmountValue(amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse suggested fee", err)
+		}
+
+		fee.Add(fee, value)
+	}
+
+	return fee, nil
+}
+
+// broadcastParent enqueues the broadcast of a transaction spending
+// the first coin of the first unlocked account holding a coin while
+// paying a fraction of the suggested fee.
+func (r *CPFPRunner) broadcastParent(ctx context.Context) error {
+	if r.helper.Paused() {
+		return nil
+	}
+
+	headBlock, err := r.helper.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil || headBlock == nil {
+		return nil
+	}
+
+	dbTx := r.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	accounts, err := r.helper.AllAccounts(ctx, dbTx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	locked, err := r.helper.LockedAccounts(ctx, dbTx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get locked accounts", err)
+	}
+
+	lockedAccounts := map[string]struct{}{}
+	for _, account := range locked {
+		lockedAccounts[types.Hash(account)] = struct{}{}
+	}
+
+	for _, account := range accounts {
+		if _, ok := lockedAccounts[types.Hash(account)]; ok {
+			continue
+		}
+
+		coins, err := r.helper.Coins(ctx, dbTx, account, r.config.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: unable to get coins", err)
+		}
+
+		if len(coins) == 0 {
+			continue
+		}
+
+		value, err := types.AmountValue(coins[0].Amount)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse coin amount", err)
+		}
+
+		// The suggested fee is determined using an intent
+		// that spends the coin without paying a fee.
+		_, suggested, _, err := standaloneMetadata(
+			ctx,
+			dbTx,
+			r.helper,
+			r.network,
+			CPFPIntent(r.config, account, coins[0], value),
+		)
+		if err != nil {
+			return err
+		}
+
+		suggestedFee, err := r.suggestedFee(suggested)
+		if err != nil {
+			return err
+		}
+
+		parentFee := CPFPParentFee(r.config, suggestedFee)
+		output := new(big.Int).Sub(value, parentFee)
+		if output.Sign() <= 0 {
+			continue
+		}
+
+		intent := CPFPIntent(r.config, account, coins[0], output)
+		metadata, _, publicKeys, err := standaloneMetadata(ctx, dbTx, r.helper, r.network, intent)
+		if err != nil {
+			return err
+		}
+
+		parent, err := standaloneBroadcast(
+			ctx,
+			dbTx,
+			r.helper,
+			r.network,
+			cpfpParentPrefix,
+			intent,
+			metadata,
+			publicKeys,
+			r.confirmationDepth,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := dbTx.Commit(ctx); err != nil {
+			return fmt.Errorf("%w: unable to commit cpfp parent", err)
+		}
+
+		color.Cyan(
+			"broadcasting cpfp parent %s paying %s of suggested fee %s",
+			parent.Hash,
+			parentFee.String(),
+			suggestedFee.String(),
+		)
+		r.scenario = &cpfpScenario{
+			account: account,
+			started: time.Now(),
+			parent:  parent,
+			deficit: new(big.Int).Sub(suggestedFee, parentFee),
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// abandon ends the scenario in progress without
+// verifying it (ex: when the parent is confirmed
+// before the child could be broadcast).
+func (r *CPFPRunner) abandon(reason string) {
+	color.Yellow("abandoning cpfp scenario with parent %s: %s", r.scenario.parent.Hash, reason)
+	r.scenario = nil
+}
+
+// pending returns a boolean indicating if the broadcast of
+// transactionIdentifier has not yet been confirmed (or failed).
+func (r *CPFPRunner) pending(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+) (bool, error) {
+	broadcasts, err := r.helper.AllBroadcasts(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	for _, broadcast := range broadcasts {
+		if broadcast.TransactionIdentifier.Hash == transactionIdentifier.Hash {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// findTransaction returns the block including transactionIdentifier
+// and the transaction in that block (nil if it is not yet included).
+func (r *CPFPRunner) findTransaction(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+) (*types.BlockIdentifier, *types.Transaction, error) {
+	dbTx := r.helper.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	block, transaction, err := r.helper.blockStorage.FindTransaction(
+		ctx,
+		transactionIdentifier,
+		dbTx,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to perform transaction search", err)
+	}
+
+	return block, transaction, nil
+}
+
+// broadcastChild enqueues the broadcast of a transaction spending
+// the change of the parent (as exposed in the mempool) while paying
+// a multiple of the suggested fee plus the fee the parent did not pay.
+func (r *CPFPRunner) broadcastChild(ctx context.Context) error {
+	parent, _, fetchErr := r.helper.onlineFetcher.MempoolTransaction(
+		ctx,
+		r.network,
+		r.scenario.parent,
+	)
+	if fetchErr != nil {
+		block, _, err := r.findTransaction(ctx, r.scenario.parent)
+		if err != nil {
+			return err
+		}
+
+		if block != nil {
+			r.abandon("parent was included in a block before it was seen in the mempool")
+			return nil
+		}
+
+		pending, err := r.pending(ctx, r.scenario.parent)
+		if err != nil {
+			return err
+		}
+
+		if !pending {
+			r.abandon("parent broadcast failed")
+		}
+
+		return nil
+	}
+
+	change := CreatedCoin(parent, r.scenario.account, r.config.Currency)
+	if change == nil {
+		return fmt.Errorf(
+			"%w: parent %s in the mempool does not create a coin for %s",
+			results.ErrCPFPVerification,
+			r.scenario.parent.Hash,
+			types.PrintStruct(r.scenario.account),
+		)
+	}
+
+	value, err := types.AmountValue(change.Amount)
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse change amount", err)
+	}
+
+	dbTx := r.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	// The suggested fee is determined using an intent
+	// that spends the change without paying a fee.
+	_, suggested, _, err := standaloneMetadata(
+		ctx,
+		dbTx,
+		r.helper,
+		r.network,
+		CPFPIntent(r.config, r.scenario.account, change, value),
+	)
+	if err != nil {
+		return err
+	}
+
+	suggestedFee, err := r.suggestedFee(suggested)
+	if err != nil {
+		return err
+	}
+
+	childFee := CPFPChildFee(r.config, suggestedFee, r.scenario.deficit)
+	output := new(big.Int).Sub(value, childFee)
+	if output.Sign() <= 0 {
+		r.abandon("change of parent cannot cover the fee of the child")
+		return nil
+	}
+
+	intent := CPFPIntent(r.config, r.scenario.account, change, output)
+	metadata, _, publicKeys, err := standaloneMetadata(ctx, dbTx, r.helper, r.network, intent)
+	if err != nil {
+		return err
+	}
+
+	child, err := standaloneBroadcast(
+		ctx,
+		dbTx,
+		r.helper,
+		r.network,
+		cpfpChildPrefix,
+		intent,
+		metadata,
+		publicKeys,
+		r.confirmationDepth,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit cpfp child", err)
+	}
+
+	color.Cyan(
+		"broadcasting cpfp child %s of parent %s paying %s",
+		child.Hash,
+		r.scenario.parent.Hash,
+		childFee.String(),
+	)
+	r.scenario.change = change
+	r.scenario.child = child
+
+	return nil
+}
+
+// verifyChildInMempool ensures the child spends the change
+// of the parent once it appears in the mempool.
+func (r *CPFPRunner) verifyChildInMempool(ctx context.Context) error {
+	child, _, fetchErr := r.helper.onlineFetcher.MempoolTransaction(
+		ctx,
+		r.network,
+		r.scenario.child,
+	)
+	if fetchErr != nil {
+		block, _, err := r.findTransaction(ctx, r.scenario.child)
+		if err != nil {
+			return err
+		}
+
+		// The child is verified in blocks instead.
+		r.scenario.childVerified = block != nil
+		return nil
+	}
+
+	if !SpendsCoin(child, r.scenario.change.CoinIdentifier) {
+		return fmt.Errorf(
+			"%w: child %s in the mempool does not spend change %s of parent %s",
+			results.ErrCPFPVerification,
+			r.scenario.child.Hash,
+			r.scenario.change.CoinIdentifier.Identifier,
+			r.scenario.parent.Hash,
+		)
+	}
+
+	r.scenario.childVerified = true
+	return nil
+}
+
+// verifyConfirmed ensures that once both the parent and the child
+// are confirmed, the child spends the change of the parent and was
+// not included in a block before the parent.
+func (r *CPFPRunner) verifyConfirmed(ctx context.Context) error {
+	for _, transactionIdentifier := range []*types.TransactionIdentifier{
+		r.scenario.parent,
+		r.scenario.child,
+	} {
+		pending, err := r.pending(ctx, transactionIdentifier)
+		if err != nil || pending {
+			return err
+		}
+	}
+
+	parentBlock, parent, err := r.findTransaction(ctx, r.scenario.parent)
+	if err != nil {
+		return err
+	}
+
+	childBlock, child, err := r.findTransaction(ctx, r.scenario.child)
+	if err != nil {
+		return err
+	}
+
+	if parentBlock == nil || childBlock == nil {
+		r.abandon("parent or child broadcast failed")
+		return nil
+	}
+
+	change := CreatedCoin(parent, r.scenario.account, r.config.Currency)
+	if change == nil ||
+		change.CoinIdentifier.Identifier != r.scenario.change.CoinIdentifier.Identifier {
+		return fmt.Errorf(
+			"%w: confirmed parent %s does not create change %s",
+			results.ErrCPFPVerification,
+			r.scenario.parent.Hash,
+			r.scenario.change.CoinIdentifier.Identifier,
+		)
+	}
+
+	if !SpendsCoin(child, r.scenario.change.CoinIdentifier) {
+		return fmt.Errorf(
+			"%w: confirmed child %s does not spend change %s of parent %s",
+			results.ErrCPFPVerification,
+			r.scenario.child.Hash,
+			r.scenario.change.CoinIdentifier.Identifier,
+			r.scenario.parent.Hash,
+		)
+	}
+
+	if childBlock.Index < parentBlock.Index {
+		return fmt.Errorf(
+			"%w: child %s was included in block %d before parent %s in block %d",
+			results.ErrCPFPVerification,
+			r.scenario.child.Hash,
+			childBlock.Index,
+			r.scenario.parent.Hash,
+			parentBlock.Index,
+		)
+	}
+
+	if _, err := r.helper.counterStorage.Update(
+		ctx,
+		results.CPFPScenariosCounter,
+		big.NewInt(1),
+	); err != nil {
+		return fmt.Errorf("%w: unable to update %s counter", err, results.CPFPScenariosCounter)
+	}
+
+	color.Green(
+		"verified cpfp scenario with parent %s and child %s",
+		r.scenario.parent.Hash,
+		r.scenario.child.Hash,
+	)
+	r.scenario = nil
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPFPFees(t *testing.T) {
+	config := &configuration.CPFPConfiguration{
+		ParentFeeMultiplier: 0.25,
+		ChildFeeMultiplier:  2,
+	}
+
+	parentFee := CPFPParentFee(config, big.NewInt(1000))
+	assert.Equal(t, big.NewInt(250), parentFee)
+
+	deficit := new(big.Int).Sub(big.NewInt(1000), parentFee)
+	assert.Equal(t, big.NewInt(2350), CPFPChildFee(config, big.NewInt(800), deficit))
+}
+
+func TestCPFPIntent(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	config := &configuration.CPFPConfiguration{
+		Currency:            currency,
+		InputOperationType:  "INPUT",
+		OutputOperationType: "OUTPUT",
+	}
+	account := &types.AccountIdentifier{Address: "a"}
+	coin := &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: "parent:0"},
+		Amount:         &types.Amount{Value: "1000", Currency: currency},
+	}
+
+	intent := CPFPIntent(config, account, coin, big.NewInt(900))
+	assert.Len(t, intent, 2)
+	assert.Equal(t, "-1000", intent[0].Amount.Value)
+	assert.Equal(t, types.CoinSpent, intent[0].CoinChange.CoinAction)
+	assert.Equal(t, "parent:0", intent[0].CoinChange.CoinIdentifier.Identifier)
+	assert.Equal(t, "OUTPUT", intent[1].Type)
+	assert.Equal(t, account, intent[1].Account)
+	assert.Equal(t, "900", intent[1].Amount.Value)
+}
+
+func TestCreatedCoinAndSpendsCoin(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	account := &types.AccountIdentifier{Address: "a"}
+	parent := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "parent"},
+		Operations: []*types.Operation{
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 0},
+				Type:                "INPUT",
+				Account:             account,
+				Amount:              &types.Amount{Value: "-1000", Currency: currency},
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: &types.CoinIdentifier{Identifier: "funding:0"},
+					CoinAction:     types.CoinSpent,
+				},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 1},
+				Type:                "OUTPUT",
+				Account:             &types.AccountIdentifier{Address: "b"},
+				Amount:              &types.Amount{Value: "100", Currency: currency},
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: &types.CoinIdentifier{Identifier: "parent:0"},
+					CoinAction:     types.CoinCreated,
+				},
+			},
+			{
+				OperationIdentifier: &types.OperationIdentifier{Index: 2},
+				Type:                "OUTPUT",
+				Account:             account,
+				Amount:              &types.Amount{Value: "850", Currency: currency},
+				CoinChange: &types.CoinChange{
+					CoinIdentifier: &types.CoinIdentifier{Identifier: "parent:1"},
+					CoinAction:     types.CoinCreated,
+				},
+			},
+		},
+	}
+
+	change := CreatedCoin(parent, account, currency)
+	assert.Equal(t, &types.Coin{
+		CoinIdentifier: &types.CoinIdentifier{Identifier: "parent:1"},
+		Amount:         &types.Amount{Value: "850", Currency: currency},
+	}, change)
+	assert.Nil(t, CreatedCoin(parent, &types.AccountIdentifier{Address: "c"}, currency))
+	assert.Nil(t, CreatedCoin(parent, account, &types.Currency{Symbol: "ETH", Decimals: 18}))
+
+	assert.True(t, SpendsCoin(parent, &types.CoinIdentifier{Identifier: "funding:0"}))
+	assert.False(t, SpendsCoin(parent, change.CoinIdentifier))
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// CurveMix selects curve types according to their weights
+// using smooth weighted round-robin, so that curves are
+// interleaved (instead of generating all keys of one curve
+// before moving to the next).
+type CurveMix struct {
+	curves  []*configuration.WeightedCurveType
+	current []int
+	total   int
+}
+
+// NewCurveMix returns a new *CurveMix.
+func NewCurveMix(curves []*configuration.WeightedCurveType) *CurveMix {
+	total := 0
+	for _, curve := range curves {
+		total += curve.Weight
+	}
+
+	return &CurveMix{
+		curves:  curves,
+		current: make([]int, len(curves)),
+		total:   total,
+	}
+}
+
+// Next returns the next curve type to use.
+func (m *CurveMix) Next() types.CurveType {
+	selected := 0
+	for i, curve := range m.curves {
+		m.current[i] += curve.Weight
+		if m.current[i] > m.current[selected] {
+			selected = i
+		}
+	}
+
+	m.current[selected] -= m.total
+	return m.curves[selected].CurveType
+}
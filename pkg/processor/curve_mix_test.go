@@ -0,0 +1,51 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurveMix(t *testing.T) {
+	mix := NewCurveMix([]*configuration.WeightedCurveType{
+		{CurveType: types.Secp256k1, Weight: 3},
+		{CurveType: types.Edwards25519, Weight: 1},
+		{CurveType: types.Secp256r1, Weight: 1},
+	})
+
+	selected := []types.CurveType{}
+	for i := 0; i < 5; i++ {
+		selected = append(selected, mix.Next())
+	}
+
+	// Curves are interleaved instead of grouped
+	assert.Equal(t, []types.CurveType{
+		types.Secp256k1,
+		types.Edwards25519,
+		types.Secp256k1,
+		types.Secp256r1,
+		types.Secp256k1,
+	}, selected)
+
+	// The mix repeats every total weight selections
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, selected[i], mix.Next())
+	}
+}
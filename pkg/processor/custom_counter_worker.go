@@ -0,0 +1,191 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*CustomCounterWorker)(nil)
+
+// CustomCounter is a compiled *configuration.CustomCounter.
+type CustomCounter struct {
+	config    *configuration.CustomCounter
+	account   *regexp.Regexp
+	minAmount *big.Int
+	maxAmount *big.Int
+}
+
+// NewCustomCounter compiles a *configuration.CustomCounter.
+func NewCustomCounter(config *configuration.CustomCounter) (*CustomCounter, error) {
+	counter := &CustomCounter{config: config}
+	if len(config.Account) > 0 {
+		account, err := regexp.Compile(config.Account)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid account pattern", err)
+		}
+		counter.account = account
+	}
+
+	if len(config.MinAmount) > 0 {
+		minAmount, err := types.BigInt(config.MinAmount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid min amount", err)
+		}
+		counter.minAmount = minAmount
+	}
+
+	if len(config.MaxAmount) > 0 {
+		maxAmount, err := types.BigInt(config.MaxAmount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid max amount", err)
+		}
+		counter.maxAmount = maxAmount
+	}
+
+	return counter, nil
+}
+
+// Matches returns a boolean indicating if op matches
+// all populated predicates of the counter.
+func (c *CustomCounter) Matches(op *types.Operation) bool {
+	if len(c.config.Type) > 0 && op.Type != c.config.Type {
+		return false
+	}
+
+	if len(c.config.Status) > 0 && (op.Status == nil || *op.Status != c.config.Status) {
+		return false
+	}
+
+	if c.account != nil && (op.Account == nil || !c.account.MatchString(op.Account.Address)) {
+		return false
+	}
+
+	if c.config.Currency == nil && c.minAmount == nil && c.maxAmount == nil {
+		return true
+	}
+
+	if op.Amount == nil {
+		return false
+	}
+
+	if c.config.Currency != nil && types.Hash(op.Amount.Currency) != types.Hash(c.config.Currency) {
+		return false
+	}
+
+	value, err := types.BigInt(op.Amount.Value)
+	if err != nil {
+		return false
+	}
+
+	if c.minAmount != nil && value.Cmp(c.minAmount) < 0 {
+		return false
+	}
+
+	return c.maxAmount == nil || value.Cmp(c.maxAmount) <= 0
+}
+
+// CustomCounterWorker updates user-defined counters
+// of the operations in each synced block.
+type CustomCounterWorker struct {
+	counterStorage *modules.CounterStorage
+	counters       []*CustomCounter
+}
+
+// NewCustomCounterWorker returns a new *CustomCounterWorker.
+func NewCustomCounterWorker(
+	counterStorage *modules.CounterStorage,
+	configs []*configuration.CustomCounter,
+) (*CustomCounterWorker, error) {
+	counters := make([]*CustomCounter, len(configs))
+	for i, config := range configs {
+		counter, err := NewCustomCounter(config)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to compile custom counter %s", err, config.Name)
+		}
+
+		counters[i] = counter
+	}
+
+	return &CustomCounterWorker{
+		counterStorage: counterStorage,
+		counters:       counters,
+	}, nil
+}
+
+// update adds the number of operations in block matching
+// each counter (multiplied by sign) to the counter.
+func (w *CustomCounterWorker) update(
+	ctx context.Context,
+	block *types.Block,
+	transaction database.Transaction,
+	sign int64,
+) error {
+	for _, counter := range w.counters {
+		matches := int64(0)
+		for _, tx := range block.Transactions {
+			for _, op := range tx.Operations {
+				if counter.Matches(op) {
+					matches++
+				}
+			}
+		}
+
+		if matches == 0 {
+			continue
+		}
+
+		if _, err := w.counterStorage.UpdateTransactional(
+			ctx,
+			transaction,
+			counter.config.Name,
+			big.NewInt(sign*matches),
+		); err != nil {
+			return fmt.Errorf("%w: unable to update custom counter %s", err, counter.config.Name)
+		}
+	}
+
+	return nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *CustomCounterWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.update(ctx, block, transaction, 1)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *CustomCounterWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, w.update(ctx, block, transaction, -1)
+}
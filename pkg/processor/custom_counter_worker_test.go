@@ -0,0 +1,186 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	counterCurrency = &types.Currency{Symbol: "BTC", Decimals: 8}
+)
+
+func counterOp(opType string, status string, address string, value string) *types.Operation {
+	return &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{},
+		Type:                opType,
+		Status:              types.String(status),
+		Account:             &types.AccountIdentifier{Address: address},
+		Amount:              &types.Amount{Value: value, Currency: counterCurrency},
+	}
+}
+
+func TestCustomCounterMatches(t *testing.T) {
+	var tests = map[string]struct {
+		counter *configuration.CustomCounter
+		op      *types.Operation
+		matches bool
+	}{
+		"no predicates": {
+			counter: &configuration.CustomCounter{Name: "all"},
+			op:      &types.Operation{Type: "Fee"},
+			matches: true,
+		},
+		"type and status": {
+			counter: &configuration.CustomCounter{
+				Name:   "transfers",
+				Type:   "Transfer",
+				Status: "SUCCESS",
+			},
+			op:      counterOp("Transfer", "SUCCESS", "a", "10"),
+			matches: true,
+		},
+		"status mismatch": {
+			counter: &configuration.CustomCounter{
+				Name:   "transfers",
+				Type:   "Transfer",
+				Status: "SUCCESS",
+			},
+			op: counterOp("Transfer", "FAILURE", "a", "10"),
+		},
+		"amount in range": {
+			counter: &configuration.CustomCounter{
+				Name:      "large_debits",
+				Currency:  counterCurrency,
+				MinAmount: "-1000",
+				MaxAmount: "-100",
+			},
+			op:      counterOp("Transfer", "SUCCESS", "a", "-100"),
+			matches: true,
+		},
+		"amount out of range": {
+			counter: &configuration.CustomCounter{
+				Name:      "large_debits",
+				MinAmount: "-1000",
+				MaxAmount: "-100",
+			},
+			op: counterOp("Transfer", "SUCCESS", "a", "-99"),
+		},
+		"currency mismatch": {
+			counter: &configuration.CustomCounter{
+				Name:     "eth",
+				Currency: &types.Currency{Symbol: "ETH", Decimals: 18},
+			},
+			op: counterOp("Transfer", "SUCCESS", "a", "10"),
+		},
+		"missing amount": {
+			counter: &configuration.CustomCounter{
+				Name:      "positive",
+				MinAmount: "1",
+			},
+			op: &types.Operation{Type: "Transfer"},
+		},
+		"account pattern": {
+			counter: &configuration.CustomCounter{
+				Name:    "burns",
+				Account: "^0x0+$",
+			},
+			op:      counterOp("Transfer", "SUCCESS", "0x0000", "10"),
+			matches: true,
+		},
+		"account mismatch": {
+			counter: &configuration.CustomCounter{
+				Name:    "burns",
+				Account: "^0x0+$",
+			},
+			op: counterOp("Transfer", "SUCCESS", "0x0001", "10"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			counter, err := NewCustomCounter(test.counter)
+			assert.NoError(t, err)
+			assert.Equal(t, test.matches, counter.Matches(test.op))
+		})
+	}
+}
+
+func TestCustomCounterWorker(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	counterStorage := modules.NewCounterStorage(db)
+	worker, err := NewCustomCounterWorker(counterStorage, []*configuration.CustomCounter{
+		{Name: "transfers", Type: "Transfer"},
+		{Name: "fees", Type: "Fee"},
+	})
+	assert.NoError(t, err)
+
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 1, Hash: "block 1"},
+		Transactions: []*types.Transaction{
+			{
+				Operations: []*types.Operation{
+					counterOp("Transfer", "SUCCESS", "a", "-10"),
+					counterOp("Transfer", "SUCCESS", "b", "10"),
+				},
+			},
+			{
+				Operations: []*types.Operation{
+					counterOp("Transfer", "SUCCESS", "a", "-5"),
+					counterOp("Transfer", "SUCCESS", "c", "5"),
+				},
+			},
+		},
+	}
+
+	counterValue := func(name string) int64 {
+		value, err := counterStorage.Get(ctx, name)
+		assert.NoError(t, err)
+
+		return value.Int64()
+	}
+
+	dbTx := db.Transaction(ctx)
+	commitWorker, err := worker.AddingBlock(ctx, nil, block, dbTx)
+	assert.NoError(t, err)
+	assert.Nil(t, commitWorker)
+	assert.NoError(t, dbTx.Commit(ctx))
+	assert.Equal(t, int64(4), counterValue("transfers"))
+	assert.Equal(t, int64(0), counterValue("fees"))
+
+	dbTx = db.Transaction(ctx)
+	_, err = worker.RemovingBlock(ctx, nil, block, dbTx)
+	assert.NoError(t, err)
+	assert.NoError(t, dbTx.Commit(ctx))
+	assert.Equal(t, int64(0), counterValue("transfers"))
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// decimalsProbeMinSamples is the minimum number of non-zero
+	// amounts of a currency that must be observed before its
+	// magnitudes are checked.
+	decimalsProbeMinSamples = 10
+
+	// maxMedianExponent is the largest (base 10) exponent of the
+	// median amount of a currency, in whole units, that is considered
+	// plausible. A median transfer of over a million whole units
+	// usually means decimals is too small (ex: 8 instead of 18).
+	maxMedianExponent = 6
+
+	// minMedianExponent is the smallest (base 10) exponent of the
+	// median amount of a currency, in whole units, that is considered
+	// plausible. A median transfer below 10^-12 whole units usually
+	// means decimals is too large (ex: 18 instead of 8).
+	minMedianExponent = -12
+)
+
+// DecimalsMismatch is a currency whose observed amounts are
+// inconsistent with its decimals.
+type DecimalsMismatch struct {
+	Currency *types.Currency
+	Reason   string
+}
+
+// observedCurrency is a currency and the absolute value
+// of each non-zero amount of it.
+type observedCurrency struct {
+	currency *types.Currency
+	amounts  []*big.Int
+}
+
+// CheckCurrencyDecimals returns the currencies in blocks with
+// decimals that differ from the expected currency with the same
+// symbol or with median amounts of implausible magnitude.
+func CheckCurrencyDecimals(
+	expected []*types.Currency,
+	blocks []*types.Block,
+) []*DecimalsMismatch {
+	observed := map[string]*observedCurrency{}
+	for _, block := range blocks {
+		for _, tx := range block.Transactions {
+			for _, op := range tx.Operations {
+				if op.Amount == nil || op.Amount.Currency == nil {
+					continue
+				}
+
+				value, ok := new(big.Int).SetString(op.Amount.Value, 10)
+				if !ok || value.Sign() == 0 {
+					continue
+				}
+
+				key := types.Hash(op.Amount.Currency)
+				if _, ok := observed[key]; !ok {
+					observed[key] = &observedCurrency{currency: op.Amount.Currency}
+				}
+				observed[key].amounts = append(observed[key].amounts, value.Abs(value))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(observed))
+	for key := range observed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mismatches := []*DecimalsMismatch{}
+	for _, key := range keys {
+		o := observed[key]
+		if mismatch := checkExpectedDecimals(expected, o.currency); mismatch != nil {
+			mismatches = append(mismatches, mismatch)
+			continue
+		}
+
+		if mismatch := checkMedianMagnitude(o); mismatch != nil {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	return mismatches
+}
+
+// checkExpectedDecimals returns a *DecimalsMismatch if an
+// expected currency with the same symbol as currency has
+// different decimals.
+func checkExpectedDecimals(
+	expected []*types.Currency,
+	currency *types.Currency,
+) *DecimalsMismatch {
+	for _, e := range expected {
+		if e.Symbol != currency.Symbol || e.Decimals == currency.Decimals {
+			continue
+		}
+
+		return &DecimalsMismatch{
+			Currency: currency,
+			Reason: fmt.Sprintf(
+				"observed decimals %d but configured decimals %d",
+				currency.Decimals,
+				e.Decimals,
+			),
+		}
+	}
+
+	return nil
+}
+
+// checkMedianMagnitude returns a *DecimalsMismatch if the
+// median amount of o (in whole units) is implausibly large
+// or small.
+func checkMedianMagnitude(o *observedCurrency) *DecimalsMismatch {
+	if len(o.amounts) < decimalsProbeMinSamples {
+		return nil
+	}
+
+	sort.Slice(o.amounts, func(i, j int) bool {
+		return o.amounts[i].Cmp(o.amounts[j]) < 0
+	})
+	median := o.amounts[len(o.amounts)/2]
+	exponent := len(median.String()) - 1 - int(o.currency.Decimals)
+
+	switch {
+	case exponent >= maxMedianExponent:
+		return &DecimalsMismatch{
+			Currency: o.currency,
+			Reason: fmt.Sprintf(
+				"median amount %s is at least 10^%d whole units (decimals %d may be too small)",
+				median.String(),
+				exponent,
+				o.currency.Decimals,
+			),
+		}
+	case exponent < minMedianExponent:
+		return &DecimalsMismatch{
+			Currency: o.currency,
+			Reason: fmt.Sprintf(
+				"median amount %s is less than 10^%d whole units (decimals %d may be too large)",
+				median.String(),
+				exponent+1,
+				o.currency.Decimals,
+			),
+		}
+	default:
+		return nil
+	}
+}
+
+// ProbeCurrencyDecimals fetches the most recent blocks (up to
+// and including tip) and checks them with CheckCurrencyDecimals.
+func ProbeCurrencyDecimals(
+	ctx context.Context,
+	f *fetcher.Fetcher,
+	network *types.NetworkIdentifier,
+	tip *types.BlockIdentifier,
+	blocks int64,
+	expected []*types.Currency,
+) ([]*DecimalsMismatch, error) {
+	start := tip.Index - blocks + 1
+	if start < 0 {
+		start = 0
+	}
+
+	fetched := []*types.Block{}
+	for index := start; index <= tip.Index; index++ {
+		i := index
+		block, fetchErr := f.BlockRetry(ctx, network, &types.PartialBlockIdentifier{
+			Index: &i,
+		})
+		if fetchErr != nil {
+			return nil, fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, index)
+		}
+
+		// Omitted blocks are nil
+		if block != nil {
+			fetched = append(fetched, block)
+		}
+	}
+
+	return CheckCurrencyDecimals(expected, fetched), nil
+}
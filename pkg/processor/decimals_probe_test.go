@@ -0,0 +1,113 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func blockWithAmounts(currency *types.Currency, values ...string) *types.Block {
+	ops := []*types.Operation{}
+	for i, value := range values {
+		ops = append(ops, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(i)},
+			Type:                "Transfer",
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: currency,
+			},
+		})
+	}
+
+	return &types.Block{
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+				Operations:            ops,
+			},
+		},
+	}
+}
+
+func repeat(value string, n int) []string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = value
+	}
+
+	return values
+}
+
+func TestCheckCurrencyDecimals(t *testing.T) {
+	btc := &types.Currency{Symbol: "BTC", Decimals: 8}
+	eth := &types.Currency{Symbol: "ETH", Decimals: 18}
+	ethAs8 := &types.Currency{Symbol: "ETH", Decimals: 8}
+
+	var tests = map[string]struct {
+		expected []*types.Currency
+		blocks   []*types.Block
+
+		mismatches int
+	}{
+		"plausible amounts": {
+			blocks: []*types.Block{
+				blockWithAmounts(btc, repeat("-150000000", 10)...),
+				blockWithAmounts(eth, repeat("21000000000000000", 10)...),
+			},
+		},
+		"too few samples": {
+			blocks: []*types.Block{
+				blockWithAmounts(ethAs8, repeat("1000000000000000000", 5)...),
+			},
+		},
+		"zero amounts ignored": {
+			blocks: []*types.Block{
+				blockWithAmounts(ethAs8, repeat("0", 20)...),
+			},
+		},
+		"wei with 8 decimals": {
+			blocks: []*types.Block{
+				blockWithAmounts(ethAs8, repeat("1000000000000000000", 10)...),
+			},
+			mismatches: 1,
+		},
+		"satoshis with 18 decimals": {
+			blocks: []*types.Block{
+				blockWithAmounts(
+					&types.Currency{Symbol: "BTC", Decimals: 18},
+					repeat("5000", 10)...,
+				),
+			},
+			mismatches: 1,
+		},
+		"configured decimals differ": {
+			expected: []*types.Currency{eth},
+			blocks: []*types.Block{
+				blockWithAmounts(ethAs8, "1"),
+			},
+			mismatches: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mismatches := CheckCurrencyDecimals(test.expected, test.blocks)
+			assert.Len(t, mismatches, test.mismatches)
+		})
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+const (
+	// dustConsolidationPrefix is the prefix of the broadcast
+	// identifier of each dust consolidation.
+	dustConsolidationPrefix = "dust_consolidation:"
+)
+
+// IsDustConsolidation returns a boolean indicating if a
+// broadcast identifier belongs to a dust consolidation
+// (rather than a job run by the coordinator).
+func IsDustConsolidation(identifier string) bool {
+	return strings.HasPrefix(identifier, dustConsolidationPrefix)
+}
+
+// FindDustCoins returns the coins valued below threshold
+// (smallest first, at most maxCoins if maxCoins > 0) if
+// there are at least minCoins of them. Otherwise, nil
+// is returned.
+func FindDustCoins(
+	coins []*types.Coin,
+	threshold *big.Int,
+	minCoins int,
+	maxCoins int,
+) ([]*types.Coin, error) {
+	dust := []*types.Coin{}
+	values := map[string]*big.Int{}
+	for _, coin := range coins {
+		value, err := types.AmountValue(coin.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse coin amount", err)
+		}
+
+		if value.Sign() <= 0 || value.Cmp(threshold) >= 0 {
+			continue
+		}
+
+		dust = append(dust, coin)
+		values[coin.CoinIdentifier.Identifier] = value
+	}
+
+	if len(dust) < minCoins {
+		return nil, nil
+	}
+
+	sort.SliceStable(dust, func(i, j int) bool {
+		return values[dust[i].CoinIdentifier.Identifier].Cmp(
+			values[dust[j].CoinIdentifier.Identifier],
+		) < 0
+	})
+
+	if maxCoins > 0 && len(dust) > maxCoins {
+		dust = dust[:maxCoins]
+	}
+
+	return dust, nil
+}
+
+// ConsolidationIntent returns the intent of a transaction that
+// spends coins held by account into a single output of value
+// to the same account.
+func ConsolidationIntent(
+	config *configuration.DustConsolidationConfiguration,
+	account *types.AccountIdentifier,
+	coins []*types.Coin,
+	value *big.Int,
+) []*types.Operation {
+	intent := []*types.Operation{}
+	for i, coin := range coins {
+		intent = append(intent, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(i)},
+			Type:                config.InputOperationType,
+			Account:             account,
+			Amount: &types.Amount{
+				Value:    "-" + coin.Amount.Value,
+				Currency: coin.Amount.Currency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		})
+	}
+
+	return append(intent, &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{Index: int64(len(coins))},
+		Type:                config.OutputOperationType,
+		Account:             account,
+		Amount: &types.Amount{
+			Value:    value.String(),
+			Currency: config.Currency,
+		},
+	})
+}
+
+// DustConsolidator periodically consolidates the dust
+// coins held by accounts in the key store.
+type DustConsolidator struct {
+	network           *types.NetworkIdentifier
+	helper            *CoordinatorHelper
+	config            *configuration.DustConsolidationConfiguration
+	threshold         *big.Int
+	confirmationDepth int64
+}
+
+// NewDustConsolidator returns a new *DustConsolidator.
+func NewDustConsolidator(
+	network *types.NetworkIdentifier,
+	helper *CoordinatorHelper,
+	config *configuration.DustConsolidationConfiguration,
+	confirmationDepth int64,
+) *DustConsolidator {
+	// The threshold is validated when the configuration is loaded.
+	threshold, _ := new(big.Int).SetString(config.Threshold, 10)
+
+	return &DustConsolidator{
+		network:           network,
+		helper:            helper,
+		config:            config,
+		threshold:         threshold,
+		confirmationDepth: confirmationDepth,
+	}
+}
+
+// Start attempts a consolidation every configured interval
+// until ctx is canceled.
+func (d *DustConsolidator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(d.config.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := d.Consolidate(ctx); err != nil {
+				return fmt.Errorf("%w: unable to consolidate dust", err)
+			}
+		}
+	}
+}
+
+// Consolidate broadcasts a transaction spending the dust
+// coins of the first unlocked account holding at least
+// MinCoins of them. It returns the identifier of the
+// transaction (nil if no account needed consolidation).
+func (d *DustConsolidator) Consolidate(
+	ctx context.Context,
+) (*types.TransactionIdentifier, error) {
+	if d.helper.Paused() {
+		return nil, nil
+	}
+
+	headBlock, err := d.helper.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil || headBlock == nil {
+		return nil, nil
+	}
+
+	dbTx := d.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	accounts, err := d.helper.AllAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	locked, err := d.helper.LockedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get locked accounts", err)
+	}
+
+	lockedAccounts := map[string]struct{}{}
+	for _, account := range locked {
+		lockedAccounts[types.Hash(account)] = struct{}{}
+	}
+
+	for _, account := range accounts {
+		if _, ok := lockedAccounts[types.Hash(account)]; ok {
+			continue
+		}
+
+		coins, err := d.helper.Coins(ctx, dbTx, account, d.config.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get coins", err)
+		}
+
+		dust, err := FindDustCoins(coins, d.threshold, d.config.MinCoins, d.config.MaxCoins)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(dust) == 0 {
+			continue
+		}
+
+		transactionIdentifier, err := d.consolidate(ctx, dbTx, account, dust)
+		if err != nil {
+			return nil, err
+		}
+
+		if transactionIdentifier == nil {
+			continue
+		}
+
+		if err := dbTx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("%w: unable to commit consolidation", err)
+		}
+
+		color.Cyan(
+			"consolidating %d dust coins of %s in transaction %s",
+			len(dust),
+			types.PrintStruct(account),
+			transactionIdentifier.Hash,
+		)
+
+		return transactionIdentifier, nil
+	}
+
+	return nil, nil
+}
+
+// consolidate constructs, signs, and enqueues a transaction
+// spending coins. If the suggested fee is at least the value
+// of coins, nothing is broadcast.
+func (d *DustConsolidator) consolidate(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	coins []*types.Coin,
+) (*types.TransactionIdentifier, error) {
+	total := new(big.Int)
+	for _, coin := range coins {
+		value, err := types.AmountValue(coin.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse coin amount", err)
+		}
+
+		total.Add(total, value)
+	}
+
+	// The suggested fee is determined using an intent
+	// that spends all coins without paying a fee.
+	_, suggestedFee, _, err := standaloneMetadata(
+		ctx,
+		dbTx,
+		d.helper,
+		d.network,
+		ConsolidationIntent(d.config, account, coins, total),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := new(big.Int)
+	for _, amount := range suggestedFee {
+		if types.Hash(amount.Currency) != types.Hash(d.config.Currency) {
+			continue
+		}
+
+		value, err := types.AmountValue(amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse suggested fee", err)
+		}
+
+		fee.Add(fee, value)
+	}
+
+	output := new(big.Int).Sub(total, fee)
+	if output.Sign() <= 0 {
+		return nil, nil
+	}
+
+	intent := ConsolidationIntent(d.config, account, coins, output)
+	metadata, _, publicKeys, err := standaloneMetadata(ctx, dbTx, d.helper, d.network, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	return standaloneBroadcast(
+		ctx,
+		dbTx,
+		d.helper,
+		d.network,
+		dustConsolidationPrefix,
+		intent,
+		metadata,
+		publicKeys,
+		d.confirmationDepth,
+	)
+}
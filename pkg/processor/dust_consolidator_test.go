@@ -0,0 +1,131 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDustCoins(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	coin := func(identifier string, value string) *types.Coin {
+		return &types.Coin{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: identifier},
+			Amount:         &types.Amount{Value: value, Currency: currency},
+		}
+	}
+	coins := []*types.Coin{
+		coin("a", "500"),
+		coin("b", "5000"),
+		coin("c", "100"),
+		coin("d", "999"),
+		coin("e", "1000"),
+	}
+	threshold := big.NewInt(1000)
+
+	var tests = map[string]struct {
+		minCoins int
+		maxCoins int
+		expected []string
+	}{
+		"all dust": {
+			minCoins: 2,
+			expected: []string{"c", "a", "d"},
+		},
+		"capped": {
+			minCoins: 2,
+			maxCoins: 2,
+			expected: []string{"c", "a"},
+		},
+		"not enough dust": {
+			minCoins: 4,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dust, err := FindDustCoins(coins, threshold, test.minCoins, test.maxCoins)
+			assert.NoError(t, err)
+
+			identifiers := []string{}
+			for _, coin := range dust {
+				identifiers = append(identifiers, coin.CoinIdentifier.Identifier)
+			}
+			if test.expected == nil {
+				assert.Empty(t, identifiers)
+			} else {
+				assert.Equal(t, test.expected, identifiers)
+			}
+		})
+	}
+}
+
+func TestConsolidationIntent(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	account := &types.AccountIdentifier{Address: "addr"}
+	config := &configuration.DustConsolidationConfiguration{
+		Currency:            currency,
+		InputOperationType:  "INPUT",
+		OutputOperationType: "OUTPUT",
+	}
+	coins := []*types.Coin{
+		{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: "a"},
+			Amount:         &types.Amount{Value: "100", Currency: currency},
+		},
+		{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: "b"},
+			Amount:         &types.Amount{Value: "200", Currency: currency},
+		},
+	}
+
+	assert.Equal(t, []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "INPUT",
+			Account:             account,
+			Amount:              &types.Amount{Value: "-100", Currency: currency},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coins[0].CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                "INPUT",
+			Account:             account,
+			Amount:              &types.Amount{Value: "-200", Currency: currency},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: coins[1].CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 2},
+			Type:                "OUTPUT",
+			Account:             account,
+			Amount:              &types.Amount{Value: "290", Currency: currency},
+		},
+	}, ConsolidationIntent(config, account, coins, big.NewInt(290)))
+
+	assert.True(t, IsDustConsolidation(dustConsolidationPrefix+"tx"))
+	assert.False(t, IsDustConsolidation("job"))
+}
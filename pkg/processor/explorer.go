@@ -0,0 +1,368 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// Explorer is an independent source of chain data (ex: a
+// public block explorer) that check:data cross-checks the
+// implementation against.
+type Explorer interface {
+	// TipIndex returns the index of the latest block
+	// known to the explorer.
+	TipIndex(ctx context.Context) (int64, error)
+
+	// Balance returns the balance (in atomic units) of
+	// an account at the explorer's tip.
+	Balance(ctx context.Context, account *types.AccountIdentifier) (*big.Int, error)
+
+	// TransactionExists returns a boolean indicating if the
+	// explorer knows of a confirmed transaction with hash.
+	TransactionExists(ctx context.Context, hash string) (bool, error)
+}
+
+var _ Explorer = (*EsploraExplorer)(nil)
+
+// EsploraExplorer is an Explorer backed by the Esplora API
+// (used by blockstream.info and mempool.space).
+type EsploraExplorer struct {
+	url    string
+	client *http.Client
+}
+
+// NewEsploraExplorer returns a new *EsploraExplorer.
+func NewEsploraExplorer(url string, timeout time.Duration, proxy *Proxy) *EsploraExplorer {
+	return &EsploraExplorer{
+		url:    strings.TrimSuffix(url, "/"),
+		client: NewHTTPClient(timeout, proxy),
+	}
+}
+
+// get returns the status code and body of a GET request
+// to path.
+func (e *EsploraExplorer) get(ctx context.Context, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url+path, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: unable to create explorer request", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: unable to send explorer request", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: unable to read explorer response", err)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// getOK returns the body of a GET request to path
+// and errors if the request was not successful.
+func (e *EsploraExplorer) getOK(ctx context.Context, path string) ([]byte, error) {
+	status, body, err := e.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("explorer returned status %d for %s: %s", status, path, string(body))
+	}
+
+	return body, nil
+}
+
+// TipIndex returns the height of the explorer's tip.
+func (e *EsploraExplorer) TipIndex(ctx context.Context) (int64, error) {
+	body, err := e.getOK(ctx, "/blocks/tip/height")
+	if err != nil {
+		return -1, err
+	}
+
+	index, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf("%w: invalid tip height %s", err, string(body))
+	}
+
+	return index, nil
+}
+
+// esploraAddress is the response of /address/:address.
+type esploraAddress struct {
+	ChainStats struct {
+		FundedTxoSum *big.Int `json:"funded_txo_sum"`
+		SpentTxoSum  *big.Int `json:"spent_txo_sum"`
+	} `json:"chain_stats"`
+}
+
+// Balance returns the confirmed balance of an address.
+func (e *EsploraExplorer) Balance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+) (*big.Int, error) {
+	body, err := e.getOK(ctx, "/address/"+url.PathEscape(account.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	var address esploraAddress
+	if err := json.Unmarshal(body, &address); err != nil {
+		return nil, fmt.Errorf("%w: unable to unmarshal address", err)
+	}
+
+	if address.ChainStats.FundedTxoSum == nil || address.ChainStats.SpentTxoSum == nil {
+		return nil, fmt.Errorf("explorer returned incomplete address stats: %s", string(body))
+	}
+
+	return new(big.Int).Sub(address.ChainStats.FundedTxoSum, address.ChainStats.SpentTxoSum), nil
+}
+
+// esploraTransactionStatus is the response of /tx/:txid/status.
+type esploraTransactionStatus struct {
+	Confirmed bool `json:"confirmed"`
+}
+
+// TransactionExists returns true if the explorer knows of a
+// confirmed transaction with hash.
+func (e *EsploraExplorer) TransactionExists(ctx context.Context, hash string) (bool, error) {
+	status, body, err := e.get(ctx, "/tx/"+url.PathEscape(hash)+"/status")
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusBadRequest:
+		return false, nil
+	default:
+		return false, fmt.Errorf("explorer returned status %d for transaction %s: %s", status, hash, string(body))
+	}
+
+	var txStatus esploraTransactionStatus
+	if err := json.Unmarshal(body, &txStatus); err != nil {
+		return false, fmt.Errorf("%w: unable to unmarshal transaction status", err)
+	}
+
+	return txStatus.Confirmed, nil
+}
+
+// ExplorerComparer periodically cross-checks a random
+// transaction (and the balance of a random account in it)
+// from the latest synced block against an Explorer.
+type ExplorerComparer struct {
+	network        *types.NetworkIdentifier
+	fetcher        *fetcher.Fetcher
+	blockStorage   *modules.BlockStorage
+	counterStorage *modules.CounterStorage
+	explorer       Explorer
+	currency       *types.Currency
+	interval       time.Duration
+}
+
+// NewExplorerComparer returns a new *ExplorerComparer.
+func NewExplorerComparer(
+	network *types.NetworkIdentifier,
+	fetcher *fetcher.Fetcher,
+	blockStorage *modules.BlockStorage,
+	counterStorage *modules.CounterStorage,
+	explorer Explorer,
+	currency *types.Currency,
+	interval time.Duration,
+) *ExplorerComparer {
+	return &ExplorerComparer{
+		network:        network,
+		fetcher:        fetcher,
+		blockStorage:   blockStorage,
+		counterStorage: counterStorage,
+		explorer:       explorer,
+		currency:       currency,
+		interval:       interval,
+	}
+}
+
+// Start performs a comparison every interval until
+// a mismatch is found or ctx is canceled.
+func (c *ExplorerComparer) Start(ctx context.Context) error {
+	tc := time.NewTicker(c.interval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		if err := c.Compare(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Compare cross-checks a random transaction in the head block
+// (and the balance of a random account in it) against the
+// explorer. Errors returned by the explorer are logged (the
+// explorer is not the system under test) and only mismatches
+// are returned.
+func (c *ExplorerComparer) Compare(ctx context.Context) error {
+	head, err := c.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil
+	}
+
+	explorerTip, err := c.explorer.TipIndex(ctx)
+	if err != nil {
+		log.Printf("%s: unable to get explorer tip\n", err.Error())
+		return nil
+	}
+
+	// The explorer has not seen the head block yet.
+	if head.Index > explorerTip {
+		return nil
+	}
+
+	block, err := c.blockStorage.GetBlock(ctx, types.ConstructPartialBlockIdentifier(head))
+	if err != nil || len(block.Transactions) == 0 {
+		return nil
+	}
+
+	transaction := block.Transactions[rand.Intn(len(block.Transactions))] // #nosec G404
+	exists, err := c.explorer.TransactionExists(ctx, transaction.TransactionIdentifier.Hash)
+	if err != nil {
+		log.Printf("%s: unable to look up transaction on explorer\n", err.Error())
+		return nil
+	}
+
+	if !exists {
+		return fmt.Errorf(
+			"%w: transaction %s in block %d not found",
+			results.ErrExplorerMismatch,
+			transaction.TransactionIdentifier.Hash,
+			head.Index,
+		)
+	}
+	c.recordCheck(ctx)
+
+	account := c.sampleAccount(transaction)
+	if account == nil {
+		return nil
+	}
+
+	return c.compareBalance(ctx, account)
+}
+
+// sampleAccount returns a random account with a balance change
+// of the explorer's currency in transaction (or nil if there
+// are none).
+func (c *ExplorerComparer) sampleAccount(transaction *types.Transaction) *types.AccountIdentifier {
+	accounts := []*types.AccountIdentifier{}
+	for _, op := range transaction.Operations {
+		if op.Account == nil || op.Account.SubAccount != nil || op.Amount == nil ||
+			types.Hash(op.Amount.Currency) != types.Hash(c.currency) {
+			continue
+		}
+
+		accounts = append(accounts, op.Account)
+	}
+
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	return accounts[rand.Intn(len(accounts))] // #nosec G404
+}
+
+// compareBalance compares the live balance of an account to the
+// balance returned by the explorer. The comparison is skipped if
+// the explorer's tip changes during the lookup or differs from the
+// block the live balance is returned at.
+func (c *ExplorerComparer) compareBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+) error {
+	explorerTip, err := c.explorer.TipIndex(ctx)
+	if err != nil {
+		log.Printf("%s: unable to get explorer tip\n", err.Error())
+		return nil
+	}
+
+	explorerBalance, err := c.explorer.Balance(ctx, account)
+	if err != nil {
+		log.Printf("%s: unable to get explorer balance\n", err.Error())
+		return nil
+	}
+
+	if tip, err := c.explorer.TipIndex(ctx); err != nil || tip != explorerTip {
+		return nil
+	}
+
+	amount, block, err := utils.CurrencyBalance(
+		ctx,
+		c.network,
+		c.fetcher,
+		account,
+		c.currency,
+		-1,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get live balance", err)
+	}
+
+	if block.Index != explorerTip {
+		return nil
+	}
+
+	if amount.Value != explorerBalance.String() {
+		return fmt.Errorf(
+			"%w: balance of %s at block %d is %s but explorer returned %s",
+			results.ErrExplorerMismatch,
+			types.AccountString(account),
+			block.Index,
+			amount.Value,
+			explorerBalance.String(),
+		)
+	}
+	c.recordCheck(ctx)
+
+	return nil
+}
+
+// recordCheck increments the count of successful checks.
+func (c *ExplorerComparer) recordCheck(ctx context.Context) {
+	_, _ = c.counterStorage.Update(ctx, results.ExplorerChecksCounter, big.NewInt(1))
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEsploraExplorer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		switch r.URL.Path {
+		case "/blocks/tip/height":
+			_, _ = w.Write([]byte("100"))
+		case "/address/addr1":
+			_, _ = w.Write([]byte(
+				`{"chain_stats":{"funded_txo_sum":150000000,"spent_txo_sum":50000000}}`,
+			))
+		case "/address/addr2":
+			_, _ = w.Write([]byte(`{"mempool_stats":{}}`))
+		case "/tx/tx1/status":
+			_, _ = w.Write([]byte(`{"confirmed":true,"block_height":99}`))
+		case "/tx/tx2/status":
+			_, _ = w.Write([]byte(`{"confirmed":false}`))
+		case "/tx/tx3/status":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	explorer := NewEsploraExplorer(server.URL+"/", time.Second, nil)
+
+	tip, err := explorer.TipIndex(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), tip)
+
+	balance, err := explorer.Balance(ctx, &types.AccountIdentifier{Address: "addr1"})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100000000), balance)
+
+	balance, err = explorer.Balance(ctx, &types.AccountIdentifier{Address: "addr2"})
+	assert.Error(t, err)
+	assert.Nil(t, balance)
+
+	balance, err = explorer.Balance(ctx, &types.AccountIdentifier{Address: "addr3"})
+	assert.Error(t, err)
+	assert.Nil(t, balance)
+
+	exists, err := explorer.TransactionExists(ctx, "tx1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = explorer.TransactionExists(ctx, "tx2")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = explorer.TransactionExists(ctx, "tx3")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = explorer.TransactionExists(ctx, "tx4")
+	assert.Error(t, err)
+	assert.False(t, exists)
+}
+
+func TestExplorerSampleAccount(t *testing.T) {
+	btc := &types.Currency{Symbol: "BTC", Decimals: 8}
+	comparer := &ExplorerComparer{currency: btc}
+
+	account := &types.AccountIdentifier{Address: "addr1"}
+	transaction := &types.Transaction{
+		Operations: []*types.Operation{
+			{
+				Account: &types.AccountIdentifier{Address: "addr2"},
+				Amount:  &types.Amount{Value: "1", Currency: &types.Currency{Symbol: "ETH"}},
+			},
+			{
+				Account: &types.AccountIdentifier{
+					Address:    "addr3",
+					SubAccount: &types.SubAccountIdentifier{Address: "staking"},
+				},
+				Amount: &types.Amount{Value: "1", Currency: btc},
+			},
+			{
+				Account: &types.AccountIdentifier{Address: "addr4"},
+			},
+			{
+				Account: account,
+				Amount:  &types.Amount{Value: "-1", Currency: btc},
+			},
+		},
+	}
+
+	assert.Equal(t, account, comparer.sampleAccount(transaction))
+	assert.Nil(t, comparer.sampleAccount(&types.Transaction{}))
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*ExternalDepositWorker)(nil)
+
+// ExternalDeposit is an inbound transfer to a generated
+// address that was not broadcast by the rosetta-cli.
+type ExternalDeposit struct {
+	BlockIdentifier       *types.BlockIdentifier
+	TransactionIdentifier *types.TransactionIdentifier
+	Account               *types.AccountIdentifier
+	Amount                *types.Amount
+}
+
+// ExternalDepositWorker reports inbound transfers to generated
+// addresses that were not initiated by the rosetta-cli (ex: faucet
+// deposits or stray transfers). These transfers are already applied
+// to computed balances by BalanceStorage, so they are only reported
+// (and counted) to explain balance changes that would otherwise
+// appear to come from nowhere.
+type ExternalDepositWorker struct {
+	asserter         *asserter.Asserter
+	keyStorage       *modules.KeyStorage
+	broadcastStorage *modules.BroadcastStorage
+	counterStorage   *modules.CounterStorage
+}
+
+// NewExternalDepositWorker returns a new *ExternalDepositWorker.
+func NewExternalDepositWorker(
+	asserter *asserter.Asserter,
+	keyStorage *modules.KeyStorage,
+	broadcastStorage *modules.BroadcastStorage,
+	counterStorage *modules.CounterStorage,
+) *ExternalDepositWorker {
+	return &ExternalDepositWorker{
+		asserter:         asserter,
+		keyStorage:       keyStorage,
+		broadcastStorage: broadcastStorage,
+		counterStorage:   counterStorage,
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *ExternalDepositWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	if len(block.Transactions) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := w.keyStorage.GetAllAccountsTransactional(ctx, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get generated accounts", err)
+	}
+
+	// Broadcasts are only removed once they are confirmed, so
+	// transactions broadcast by the rosetta-cli are still
+	// pending when they are first included in a block.
+	broadcasts, err := w.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	deposits, err := FindExternalDeposits(w.asserter, block, accounts, broadcasts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deposits) == 0 {
+		return nil, nil
+	}
+
+	if _, err := w.counterStorage.UpdateTransactional(
+		ctx,
+		transaction,
+		results.ExternalDepositsCounter,
+		big.NewInt(int64(len(deposits))),
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to update external deposits counter", err)
+	}
+
+	return func(ctx context.Context) error {
+		for _, deposit := range deposits {
+			color.Cyan(
+				"external deposit of %s %s to %s in transaction %s (block %d)",
+				deposit.Amount.Value,
+				deposit.Amount.Currency.Symbol,
+				types.AccountString(deposit.Account),
+				deposit.TransactionIdentifier.Hash,
+				deposit.BlockIdentifier.Index,
+			)
+		}
+
+		return nil
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *ExternalDepositWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+// FindExternalDeposits returns all successful operations in a block
+// crediting one of accounts in transactions that are not in broadcasts.
+func FindExternalDeposits(
+	asserter *asserter.Asserter,
+	block *types.Block,
+	accounts []*types.AccountIdentifier,
+	broadcasts []*modules.Broadcast,
+) ([]*ExternalDeposit, error) {
+	tracked := map[string]struct{}{}
+	for _, account := range accounts {
+		tracked[types.Hash(account)] = struct{}{}
+	}
+
+	initiated := map[string]struct{}{}
+	for _, broadcast := range broadcasts {
+		initiated[broadcast.TransactionIdentifier.Hash] = struct{}{}
+	}
+
+	deposits := []*ExternalDeposit{}
+	for _, tx := range block.Transactions {
+		if _, ok := initiated[tx.TransactionIdentifier.Hash]; ok {
+			continue
+		}
+
+		for _, op := range tx.Operations {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			if _, ok := tracked[types.Hash(op.Account)]; !ok {
+				continue
+			}
+
+			successful, err := asserter.OperationSuccessful(op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to check operation status", err)
+			}
+
+			value, err := types.AmountValue(op.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+			}
+
+			if !successful || value.Sign() <= 0 {
+				continue
+			}
+
+			deposits = append(deposits, &ExternalDeposit{
+				BlockIdentifier:       block.BlockIdentifier,
+				TransactionIdentifier: tx.TransactionIdentifier,
+				Account:               op.Account,
+				Amount:                op.Amount,
+			})
+		}
+	}
+
+	return deposits, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindExternalDeposits(t *testing.T) {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+			{
+				Status:     "Failure",
+				Successful: false,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	tracked := &types.AccountIdentifier{Address: "tracked"}
+	other := &types.AccountIdentifier{Address: "other"}
+	transfer := func(
+		hash string,
+		from *types.AccountIdentifier,
+		to *types.AccountIdentifier,
+		status string,
+	) *types.Transaction {
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 0},
+					Type:                "Transfer",
+					Status:              types.String(status),
+					Account:             from,
+					Amount:              &types.Amount{Value: "-10", Currency: currency},
+				},
+				{
+					OperationIdentifier: &types.OperationIdentifier{Index: 1},
+					Type:                "Transfer",
+					Status:              types.String(status),
+					Account:             to,
+					Amount:              &types.Amount{Value: "10", Currency: currency},
+				},
+			},
+		}
+	}
+
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+		Transactions: []*types.Transaction{
+			transfer("faucet", other, tracked, "Success"),
+			transfer("broadcast", other, tracked, "Success"),
+			transfer("failed", other, tracked, "Failure"),
+			transfer("outbound", tracked, other, "Success"),
+			transfer("untracked", other, other, "Success"),
+		},
+	}
+
+	deposits, err := FindExternalDeposits(
+		a,
+		block,
+		[]*types.AccountIdentifier{tracked},
+		[]*modules.Broadcast{
+			{TransactionIdentifier: &types.TransactionIdentifier{Hash: "broadcast"}},
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ExternalDeposit{
+		{
+			BlockIdentifier:       block.BlockIdentifier,
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "faucet"},
+			Account:               tracked,
+			Amount:                &types.Amount{Value: "10", Currency: currency},
+		},
+	}, deposits)
+}
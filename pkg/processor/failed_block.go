@@ -0,0 +1,53 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// FindFailedBlock fetches the blocks in [start, end] in order
+// (without retries) and returns the index of the first one that
+// can't be fetched. If all blocks can be fetched (ex: the
+// failure was transient), false is returned.
+//
+// The syncer fetches up to max_sync_concurrency blocks ahead of
+// the head at once and its fetch errors don't carry the index of
+// the failed block, so this lookup is used to determine it.
+func FindFailedBlock(
+	ctx context.Context,
+	f *fetcher.Fetcher,
+	network *types.NetworkIdentifier,
+	start int64,
+	end int64,
+) (int64, bool) {
+	for index := start; index <= end; index++ {
+		index := index
+		if _, err := f.Block(ctx, network, &types.PartialBlockIdentifier{
+			Index: &index,
+		}); err != nil {
+			if ctx.Err() != nil {
+				return -1, false
+			}
+
+			return index, true
+		}
+	}
+
+	return -1, false
+}
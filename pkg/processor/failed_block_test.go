@@ -0,0 +1,125 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindFailedBlock(t *testing.T) {
+	network := &types.NetworkIdentifier{
+		Blockchain: "bitcoin",
+		Network:    "mainnet",
+	}
+	a, err := asserter.NewClientWithOptions(
+		network,
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	var tests = map[string]struct {
+		failed []int64
+
+		index int64
+		ok    bool
+	}{
+		"first block failed": {
+			failed: []int64{11},
+			index:  11,
+			ok:     true,
+		},
+		"block ahead of head failed": {
+			failed: []int64{14, 16},
+			index:  14,
+			ok:     true,
+		},
+		"no block failed": {
+			index: -1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				var request types.BlockRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				index := *request.BlockIdentifier.Index
+				for _, failed := range test.failed {
+					if index == failed {
+						w.WriteHeader(http.StatusInternalServerError)
+						assert.NoError(t, json.NewEncoder(w).Encode(&types.Error{
+							Code:    1,
+							Message: "block unavailable",
+						}))
+						return
+					}
+				}
+
+				assert.NoError(t, json.NewEncoder(w).Encode(&types.BlockResponse{
+					Block: &types.Block{
+						BlockIdentifier: &types.BlockIdentifier{
+							Hash:  fmt.Sprintf("block %d", index),
+							Index: index,
+						},
+						ParentBlockIdentifier: &types.BlockIdentifier{
+							Hash:  fmt.Sprintf("block %d", index-1),
+							Index: index - 1,
+						},
+						Timestamp:    asserter.MinUnixEpoch + 1,
+						Transactions: []*types.Transaction{},
+					},
+				}))
+			}))
+			defer server.Close()
+
+			index, ok := FindFailedBlock(
+				context.Background(),
+				fetcher.New(server.URL, fetcher.WithAsserter(a)),
+				network,
+				11,
+				20,
+			)
+			assert.Equal(t, test.index, index)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// ErrMaxFeeExceeded is returned when the estimated
+// fee exceeds the configured max fee.
+var ErrMaxFeeExceeded = errors.New("estimated fee exceeds max fee")
+
+// FeeEstimator maintains a rolling estimate of the
+// suggested_fee returned by /construction/metadata
+// for each currency.
+//
+// The estimate is the percentile of the fees suggested
+// in the last window responses (including the current
+// one), so it is lower than the current suggestion when
+// the current suggestion is a spike. An estimate above
+// maxFee is an error (instead of being capped, which
+// would underfund the transaction).
+type FeeEstimator struct {
+	window     int
+	percentile float64
+	maxFee     *big.Int
+
+	lock sync.Mutex
+	fees map[string][]*big.Int
+}
+
+// NewFeeEstimator returns a new *FeeEstimator that
+// estimates the percentile (in (0, 1]) of the window.
+func NewFeeEstimator(window int, percentile float64, maxFee *big.Int) *FeeEstimator {
+	return &FeeEstimator{
+		window:     window,
+		percentile: percentile,
+		maxFee:     maxFee,
+		fees:       map[string][]*big.Int{},
+	}
+}
+
+// Record adds suggested fees to the rolling window.
+func (f *FeeEstimator) Record(suggestedFee []*types.Amount) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, amount := range suggestedFee {
+		value, err := types.BigInt(amount.Value)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse suggested fee", err)
+		}
+
+		key := types.Hash(amount.Currency)
+		fees := append(f.fees[key], value)
+		if len(fees) > f.window {
+			fees = fees[len(fees)-f.window:]
+		}
+
+		f.fees[key] = fees
+	}
+
+	return nil
+}
+
+// Estimate returns the rolling estimate for each currency
+// in suggestedFee (the provided fee if none have been
+// recorded). If any estimate exceeds maxFee,
+// ErrMaxFeeExceeded is returned.
+func (f *FeeEstimator) Estimate(suggestedFee []*types.Amount) ([]*types.Amount, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	estimates := make([]*types.Amount, len(suggestedFee))
+	for i, amount := range suggestedFee {
+		estimate, err := types.BigInt(amount.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse suggested fee", err)
+		}
+
+		if fees := f.fees[types.Hash(amount.Currency)]; len(fees) > 0 {
+			estimate = feePercentile(fees, f.percentile)
+		}
+
+		if estimate.Cmp(f.maxFee) > 0 {
+			return nil, fmt.Errorf(
+				"%w: estimate %s %s exceeds max fee %s",
+				ErrMaxFeeExceeded,
+				estimate.String(),
+				amount.Currency.Symbol,
+				f.maxFee.String(),
+			)
+		}
+
+		estimates[i] = &types.Amount{
+			Value:    estimate.String(),
+			Currency: amount.Currency,
+			Metadata: amount.Metadata,
+		}
+	}
+
+	return estimates, nil
+}
+
+// feePercentile returns the percentile (in (0, 1]) of fees
+// using the nearest-rank method.
+func feePercentile(fees []*big.Int, percentile float64) *big.Int {
+	sorted := make([]*big.Int, len(fees))
+	copy(sorted, fees)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	rank := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
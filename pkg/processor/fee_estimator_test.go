@@ -0,0 +1,79 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeEstimator(t *testing.T) {
+	currency := &types.Currency{Symbol: "ETH", Decimals: 18}
+	fee := func(value string) []*types.Amount {
+		return []*types.Amount{{Value: value, Currency: currency}}
+	}
+
+	estimator := NewFeeEstimator(3, 0.5, big.NewInt(1000))
+
+	// No history: suggested fee is used
+	estimate, err := estimator.Estimate(fee("100"))
+	assert.NoError(t, err)
+	assert.Equal(t, fee("100"), estimate)
+
+	// Fees above the max fee are rejected
+	_, err = estimator.Estimate(fee("5000"))
+	assert.True(t, errors.Is(err, ErrMaxFeeExceeded))
+
+	// Estimate is the median of the window
+	assert.NoError(t, estimator.Record(fee("300")))
+	assert.NoError(t, estimator.Record(fee("100")))
+	assert.NoError(t, estimator.Record(fee("200")))
+	estimate, err = estimator.Estimate(fee("200"))
+	assert.NoError(t, err)
+	assert.Equal(t, fee("200"), estimate)
+
+	// A suggested fee spike is estimated below the suggestion
+	assert.NoError(t, estimator.Record(fee("900")))
+	estimate, err = estimator.Estimate(fee("900"))
+	assert.NoError(t, err)
+	assert.Equal(t, fee("200"), estimate)
+
+	// Old fees leave the window
+	assert.NoError(t, estimator.Record(fee("950")))
+	estimate, err = estimator.Estimate(fee("950"))
+	assert.NoError(t, err)
+	assert.Equal(t, fee("900"), estimate)
+
+	// Sustained fees above the max fee are rejected
+	assert.NoError(t, estimator.Record(fee("9000")))
+	assert.NoError(t, estimator.Record(fee("9500")))
+	_, err = estimator.Estimate(fee("9500"))
+	assert.True(t, errors.Is(err, ErrMaxFeeExceeded))
+
+	// Invalid fees are rejected
+	assert.Error(t, estimator.Record(fee("blah")))
+
+	// The max of the window is used at the 100th percentile
+	estimator = NewFeeEstimator(3, 1, big.NewInt(1000))
+	assert.NoError(t, estimator.Record(fee("300")))
+	assert.NoError(t, estimator.Record(fee("100")))
+	estimate, err = estimator.Estimate(fee("100"))
+	assert.NoError(t, err)
+	assert.Equal(t, fee("300"), estimate)
+}
@@ -0,0 +1,454 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// hdPathPrefix must not start with any namespace used
+	// by storage modules (ex: "acc"), which are scanned
+	// by prefix.
+	hdPathPrefix = "hd_path"
+
+	// hdNextIndexKey stores the index of the next key
+	// to derive.
+	hdNextIndexKey = "hd_next_index"
+
+	// mnemonicIterations is the number of PBKDF2 iterations
+	// used to derive a seed from a BIP-39 mnemonic.
+	mnemonicIterations = 2048
+)
+
+var (
+	_ Signer = (*HDWallet)(nil)
+
+	// secp256k1Order is the order of the secp256k1 group.
+	secp256k1Order, _ = new(big.Int).SetString(
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+		16,
+	)
+
+	// hdMasterKeys are the HMAC keys used to derive the
+	// master key of each supported curve.
+	hdMasterKeys = map[types.CurveType][]byte{
+		types.Secp256k1:    []byte("Bitcoin seed"),
+		types.Edwards25519: []byte("ed25519 seed"),
+	}
+)
+
+// hdPathRecord is what HDWallet stores for each
+// account with a derived key.
+type hdPathRecord struct {
+	Path      string          `json:"path"`
+	CurveType types.CurveType `json:"curve_type"`
+}
+
+// hdKey is a derived key (and the index it was
+// derived at).
+type hdKey struct {
+	index   uint32
+	path    []uint32
+	keyPair *keys.KeyPair
+}
+
+func hdPathKey(account *types.AccountIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", hdPathPrefix, types.Hash(account)))
+}
+
+// HDWallet derives the key of each new account from a
+// mnemonic instead of using a random key. secp256k1 keys
+// are derived with BIP-32 and edwards25519 keys are derived
+// with SLIP-10. Only the derivation path of each account
+// is stored, so private keys are re-derived to sign.
+type HDWallet struct {
+	db database.Database
+
+	// fallback signs payloads of accounts without
+	// a derivation path (ex: prefunded accounts).
+	fallback Signer
+
+	seed []byte
+	path []uint32
+
+	// keysLock protects next and keys.
+	keysLock sync.Mutex
+
+	// next is the index of the next key to derive.
+	next uint32
+
+	// keys are the keys derived in this run, by the hash
+	// of their public key and of the random public key
+	// they replaced.
+	keys map[string]*hdKey
+}
+
+// NewHDWallet returns a new *HDWallet. Keys are derived from
+// the first unused index recorded in db.
+func NewHDWallet(
+	ctx context.Context,
+	config *configuration.HDWalletConfiguration,
+	db database.Database,
+	fallback Signer,
+) (*HDWallet, error) {
+	mnemonic, err := resolveEnvPlaceholders(config.Mnemonic)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to resolve mnemonic", err)
+	}
+
+	passphrase, err := resolveEnvPlaceholders(config.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to resolve passphrase", err)
+	}
+
+	path, err := configuration.ParseDerivationPath(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid path %s", err, config.Path)
+	}
+
+	dbTx := db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	next, err := hdNextIndex(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HDWallet{
+		db:       db,
+		fallback: fallback,
+		seed:     MnemonicSeed(mnemonic, passphrase),
+		path:     path,
+		next:     next,
+		keys:     map[string]*hdKey{},
+	}, nil
+}
+
+func hdNextIndex(ctx context.Context, dbTx database.Transaction) (uint32, error) {
+	exists, val, err := dbTx.Get(ctx, []byte(hdNextIndexKey))
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to get next hd index", err)
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	var next uint32
+	if err := json.Unmarshal(val, &next); err != nil {
+		return 0, fmt.Errorf("%w: unable to unmarshal next hd index", err)
+	}
+
+	return next, nil
+}
+
+// Next derives the key of curveType at the next index.
+func (w *HDWallet) Next(curveType types.CurveType) (*keys.KeyPair, error) {
+	w.keysLock.Lock()
+	defer w.keysLock.Unlock()
+
+	key, err := w.allocate(curveType)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.keyPair, nil
+}
+
+// Replace returns the derived key that replaces publicKey,
+// deriving the key at the next index if publicKey has not
+// been replaced yet. Derived keys are returned unchanged.
+func (w *HDWallet) Replace(publicKey *types.PublicKey) (*keys.KeyPair, error) {
+	w.keysLock.Lock()
+	defer w.keysLock.Unlock()
+
+	if key, ok := w.keys[types.Hash(publicKey)]; ok {
+		return key.keyPair, nil
+	}
+
+	key, err := w.allocate(publicKey.CurveType)
+	if err != nil {
+		return nil, err
+	}
+
+	w.keys[types.Hash(publicKey)] = key
+	return key.keyPair, nil
+}
+
+// allocate derives the key of curveType at the next
+// index. It must be called with keysLock held.
+func (w *HDWallet) allocate(curveType types.CurveType) (*hdKey, error) {
+	index := w.next
+	if curveType == types.Edwards25519 {
+		// SLIP-10 only supports hardened derivation
+		// of edwards25519 keys.
+		index += configuration.HardenedKeyIndex
+	}
+
+	path := append(append([]uint32{}, w.path...), index)
+	keyPair, err := DeriveKey(w.seed, curveType, path)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to derive key at %s",
+			err,
+			configuration.FormatDerivationPath(path),
+		)
+	}
+
+	key := &hdKey{index: w.next, path: path, keyPair: keyPair}
+	w.keys[types.Hash(keyPair.PublicKey)] = key
+	w.next++
+
+	return key, nil
+}
+
+// lookup returns the derived key that replaced (or is)
+// publicKey.
+func (w *HDWallet) lookup(publicKey *types.PublicKey) (*hdKey, bool) {
+	w.keysLock.Lock()
+	defer w.keysLock.Unlock()
+
+	key, ok := w.keys[types.Hash(publicKey)]
+	return key, ok
+}
+
+// store transactionally records the derivation path of
+// account and that all indexes up to key's have been used.
+func (w *HDWallet) store(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	key *hdKey,
+) error {
+	record, err := json.Marshal(&hdPathRecord{
+		Path:      configuration.FormatDerivationPath(key.path),
+		CurveType: key.keyPair.PublicKey.CurveType,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal hd path", err)
+	}
+
+	if err := dbTx.Set(ctx, hdPathKey(account), record, false); err != nil {
+		return fmt.Errorf("%w: unable to store hd path", err)
+	}
+
+	// Keys may be stored out of order when
+	// jobs run concurrently.
+	next, err := hdNextIndex(ctx, dbTx)
+	if err != nil {
+		return err
+	}
+
+	if key.index < next {
+		return nil
+	}
+
+	val, err := json.Marshal(key.index + 1)
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal next hd index", err)
+	}
+
+	if err := dbTx.Set(ctx, []byte(hdNextIndexKey), val, false); err != nil {
+		return fmt.Errorf("%w: unable to store next hd index", err)
+	}
+
+	return nil
+}
+
+// Sign signs each payload with the key re-derived from the
+// stored derivation path of its account. Payloads of
+// accounts without a derivation path are signed by the
+// fallback Signer.
+func (w *HDWallet) Sign(
+	ctx context.Context,
+	payloads []*types.SigningPayload,
+) ([]*types.Signature, error) {
+	dbTx := w.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	signatures := make([]*types.Signature, len(payloads))
+	for i, payload := range payloads {
+		exists, val, err := dbTx.Get(ctx, hdPathKey(payload.AccountIdentifier))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get hd path", err)
+		}
+
+		if !exists {
+			fallbackSignatures, err := w.fallback.Sign(ctx, []*types.SigningPayload{payload})
+			if err != nil {
+				return nil, err
+			}
+
+			signatures[i] = fallbackSignatures[0]
+			continue
+		}
+
+		var record hdPathRecord
+		if err := json.Unmarshal(val, &record); err != nil {
+			return nil, fmt.Errorf("%w: unable to unmarshal hd path", err)
+		}
+
+		path, err := configuration.ParseDerivationPath(record.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid stored hd path %s", err, record.Path)
+		}
+
+		keyPair, err := DeriveKey(w.seed, record.CurveType, path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to derive key at %s", err, record.Path)
+		}
+
+		signer, err := keyPair.Signer()
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to create signer", err)
+		}
+
+		if len(payload.SignatureType) == 0 {
+			return nil, fmt.Errorf("signature type of payload %d is not populated", i)
+		}
+
+		signatures[i], err = signer.Sign(payload, payload.SignatureType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to sign payload %d", err, i)
+		}
+	}
+
+	return signatures, nil
+}
+
+// MnemonicSeed returns the BIP-39 seed of mnemonic and
+// passphrase (PBKDF2-HMAC-SHA512 with 2048 iterations).
+// Words are separated by a single space but the mnemonic
+// is not otherwise normalized.
+func MnemonicSeed(mnemonic string, passphrase string) []byte {
+	password := []byte(strings.Join(strings.Fields(mnemonic), " "))
+	salt := []byte("mnemonic" + passphrase)
+
+	// The seed is a single 64-byte PBKDF2 block.
+	prf := hmac.New(sha512.New, password)
+	_, _ = prf.Write(salt)
+	_, _ = prf.Write([]byte{0, 0, 0, 1})
+	u := prf.Sum(nil)
+
+	seed := append([]byte{}, u...)
+	for i := 1; i < mnemonicIterations; i++ {
+		prf.Reset()
+		_, _ = prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range seed {
+			seed[j] ^= u[j]
+		}
+	}
+
+	return seed
+}
+
+// DeriveKey derives the key of curveType at path from seed.
+// secp256k1 keys are derived with BIP-32 and edwards25519
+// keys are derived with SLIP-10 (which only supports
+// hardened indexes).
+func DeriveKey(seed []byte, curveType types.CurveType, path []uint32) (*keys.KeyPair, error) {
+	masterKey, ok := hdMasterKeys[curveType]
+	if !ok {
+		return nil, fmt.Errorf("curve %s does not support hd derivation", curveType)
+	}
+
+	key, chainCode := hdHMAC(masterKey, seed)
+	if curveType == types.Secp256k1 {
+		if err := assertSecp256k1Key(key); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, index := range path {
+		data := make([]byte, 0, 37)
+		switch {
+		case index >= configuration.HardenedKeyIndex:
+			data = append(append(data, 0), key...)
+		case curveType == types.Secp256k1:
+			keyPair, err := keys.ImportPrivateKey(hex.EncodeToString(key), curveType)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to import parent key", err)
+			}
+
+			data = append(data, keyPair.PublicKey.Bytes...)
+		default:
+			return nil, fmt.Errorf("curve %s only supports hardened derivation", curveType)
+		}
+
+		data = append(data, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+		tweak, childChainCode := hdHMAC(chainCode, data)
+		chainCode = childChainCode
+		if curveType != types.Secp256k1 {
+			key = tweak
+			continue
+		}
+
+		if err := assertSecp256k1Key(tweak); err != nil {
+			return nil, err
+		}
+
+		child := new(big.Int).SetBytes(tweak)
+		child.Add(child, new(big.Int).SetBytes(key))
+		child.Mod(child, secp256k1Order)
+		if child.Sign() == 0 {
+			return nil, errors.New("derived key is invalid")
+		}
+
+		key = child.FillBytes(make([]byte, 32))
+	}
+
+	return keys.ImportPrivateKey(hex.EncodeToString(key), curveType)
+}
+
+// hdHMAC returns the key and chain code
+// halves of HMAC-SHA512(key, data).
+func hdHMAC(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	_, _ = mac.Write(data)
+	sum := mac.Sum(nil)
+
+	return sum[:32], sum[32:]
+}
+
+// assertSecp256k1Key returns an error if key is not
+// a valid secp256k1 private key (or tweak).
+func assertSecp256k1Key(key []byte) error {
+	value := new(big.Int).SetBytes(key)
+	if value.Sign() == 0 || value.Cmp(secp256k1Order) >= 0 {
+		return errors.New("derived key is invalid")
+	}
+
+	return nil
+}
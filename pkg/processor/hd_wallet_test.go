@@ -0,0 +1,234 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMnemonicSeed(t *testing.T) {
+	// BIP-39 test vector (with passphrase TREZOR).
+	mnemonic := "abandon abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon abandon abandon  about"
+	assert.Equal(
+		t,
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f"+
+			"09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		hex.EncodeToString(MnemonicSeed(mnemonic, "TREZOR")),
+	)
+}
+
+func TestDeriveKey(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	assert.NoError(t, err)
+
+	var tests = map[string]struct {
+		curveType types.CurveType
+		path      string
+
+		privateKey string
+		err        bool
+	}{
+		"secp256k1 master": {
+			curveType:  types.Secp256k1,
+			path:       "m",
+			privateKey: "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		},
+		"secp256k1 hardened": {
+			curveType:  types.Secp256k1,
+			path:       "m/0'",
+			privateKey: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		},
+		"secp256k1 normal": {
+			curveType:  types.Secp256k1,
+			path:       "m/0'/1",
+			privateKey: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		},
+		"edwards25519 master": {
+			curveType:  types.Edwards25519,
+			path:       "m",
+			privateKey: "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		},
+		"edwards25519 hardened": {
+			curveType:  types.Edwards25519,
+			path:       "m/0'",
+			privateKey: "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		},
+		"edwards25519 normal": {
+			curveType: types.Edwards25519,
+			path:      "m/0'/1",
+			err:       true,
+		},
+		"unsupported curve": {
+			curveType: types.Secp256r1,
+			path:      "m/0'",
+			err:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := configuration.ParseDerivationPath(test.path)
+			assert.NoError(t, err)
+
+			keyPair, err := DeriveKey(seed, test.curveType, path)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			expected, err := keys.ImportPrivateKey(test.privateKey, test.curveType)
+			assert.NoError(t, err)
+			assert.Equal(t, expected.PublicKey, keyPair.PublicKey)
+		})
+	}
+}
+
+func TestHDWallet(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	assert.NoError(t, os.Setenv("HD_WALLET_TEST_MNEMONIC", "test mnemonic"))
+	defer os.Unsetenv("HD_WALLET_TEST_MNEMONIC")
+
+	config := &configuration.HDWalletConfiguration{
+		Mnemonic: "{{env:HD_WALLET_TEST_MNEMONIC}}",
+		Path:     "m/44'/0'/0'",
+	}
+	keyStorage := modules.NewKeyStorage(db)
+	wallet, err := NewHDWallet(ctx, config, db, keyStorage)
+	assert.NoError(t, err)
+
+	seed := MnemonicSeed("test mnemonic", "")
+	expectedKey := func(path string, curveType types.CurveType) *keys.KeyPair {
+		indexes, err := configuration.ParseDerivationPath(path)
+		assert.NoError(t, err)
+
+		keyPair, err := DeriveKey(seed, curveType, indexes)
+		assert.NoError(t, err)
+
+		return keyPair
+	}
+
+	// A random key is replaced by the key at the next
+	// index (and is replaced consistently).
+	random, err := keys.GenerateKeypair(types.Secp256k1)
+	assert.NoError(t, err)
+	replacement, err := wallet.Replace(random.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedKey("m/44'/0'/0'/0", types.Secp256k1), replacement)
+
+	again, err := wallet.Replace(random.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, replacement, again)
+
+	derived, err := wallet.Replace(replacement.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, replacement, derived)
+
+	// edwards25519 keys are derived at hardened indexes.
+	next, err := wallet.Next(types.Edwards25519)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedKey("m/44'/0'/0'/1'", types.Edwards25519), next)
+
+	// Keys are stored out of order.
+	account1 := &types.AccountIdentifier{Address: "addr1"}
+	account2 := &types.AccountIdentifier{Address: "addr2"}
+	key2, ok := wallet.lookup(next.PublicKey)
+	assert.True(t, ok)
+	key1, ok := wallet.lookup(random.PublicKey)
+	assert.True(t, ok)
+
+	dbTx := db.Transaction(ctx)
+	assert.NoError(t, wallet.store(ctx, dbTx, account2, key2))
+	assert.NoError(t, wallet.store(ctx, dbTx, account1, key1))
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	// Payloads of derived accounts are signed with re-derived
+	// keys and all others are signed by the fallback.
+	prefunded := &types.AccountIdentifier{Address: "prefunded"}
+	prefundedKey, err := keys.GenerateKeypair(types.Secp256k1)
+	assert.NoError(t, err)
+	assert.NoError(t, keyStorage.Store(ctx, prefunded, prefundedKey))
+
+	message := bytes.Repeat([]byte{1}, 32)
+	payloads := []*types.SigningPayload{
+		{
+			AccountIdentifier: account1,
+			Bytes:             message,
+			SignatureType:     types.Ecdsa,
+		},
+		{
+			AccountIdentifier: account2,
+			Bytes:             message,
+			SignatureType:     types.Ed25519,
+		},
+		{
+			AccountIdentifier: prefunded,
+			Bytes:             message,
+			SignatureType:     types.Ecdsa,
+		},
+	}
+	signatures, err := wallet.Sign(ctx, payloads)
+	assert.NoError(t, err)
+	assert.Len(t, signatures, 3)
+	for i, keyPair := range []*keys.KeyPair{replacement, next, prefundedKey} {
+		assert.Equal(t, keyPair.PublicKey, signatures[i].PublicKey)
+
+		signer, err := keyPair.Signer()
+		assert.NoError(t, err)
+		assert.NoError(t, signer.Verify(signatures[i]))
+	}
+
+	// A restarted wallet continues after the last stored index.
+	restarted, err := NewHDWallet(ctx, config, db, keyStorage)
+	assert.NoError(t, err)
+	next, err = restarted.Next(types.Secp256k1)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedKey("m/44'/0'/0'/2", types.Secp256k1), next)
+
+	// The mnemonic must be resolvable.
+	_, err = NewHDWallet(
+		ctx,
+		&configuration.HDWalletConfiguration{
+			Mnemonic: "{{env:HD_WALLET_TEST_MISSING}}",
+			Path:     "m",
+		},
+		db,
+		keyStorage,
+	)
+	assert.Error(t, err)
+}
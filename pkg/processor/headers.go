@@ -0,0 +1,173 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+const (
+	// requestIDBytes is the number of random bytes
+	// in a {{request_id}} (hex-encoded).
+	requestIDBytes = 8
+)
+
+// HeaderTemplates are the headers added to every request made
+// to a Rosetta implementation. Environment variables are resolved
+// when the templates are created and all other placeholders are
+// replaced for each request.
+type HeaderTemplates struct {
+	headers map[string]string
+}
+
+// NewHeaderTemplates returns the *HeaderTemplates of headers and
+// bearerToken (nil if neither is populated). An error is returned
+// if an environment variable referenced by a placeholder is not set.
+func NewHeaderTemplates(
+	headers map[string]string,
+	bearerToken string,
+) (*HeaderTemplates, error) {
+	if len(headers) == 0 && len(bearerToken) == 0 {
+		return nil, nil
+	}
+
+	templates := &HeaderTemplates{headers: map[string]string{}}
+	for name, value := range headers {
+		resolved, err := resolveEnvPlaceholders(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to resolve header %s", err, name)
+		}
+
+		templates.headers[http.CanonicalHeaderKey(name)] = resolved
+	}
+
+	if len(bearerToken) > 0 {
+		resolved, err := resolveEnvPlaceholders(bearerToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to resolve bearer token", err)
+		}
+
+		templates.headers["Authorization"] = "Bearer " + resolved
+	}
+
+	return templates, nil
+}
+
+// resolveEnvPlaceholders replaces each {{env:NAME}}
+// placeholder in value with the value of NAME.
+func resolveEnvPlaceholders(value string) (string, error) {
+	var err error
+	resolved := configuration.HeaderPlaceholderRegex.ReplaceAllStringFunc(
+		value,
+		func(placeholder string) string {
+			match := configuration.HeaderPlaceholderRegex.FindStringSubmatch(placeholder)
+			if configuration.HeaderPlaceholder(match[1]) != configuration.EnvHeaderPlaceholder {
+				return placeholder
+			}
+
+			env, ok := os.LookupEnv(match[2])
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %s is not set", match[2])
+			}
+
+			return env
+		},
+	)
+
+	return resolved, err
+}
+
+// Render returns the headers of req (with all
+// placeholders replaced).
+func (h *HeaderTemplates) Render(req *http.Request) (http.Header, error) {
+	var requestID string
+	var err error
+	header := http.Header{}
+	for name, value := range h.headers {
+		header.Set(name, configuration.HeaderPlaceholderRegex.ReplaceAllStringFunc(
+			value,
+			func(placeholder string) string {
+				match := configuration.HeaderPlaceholderRegex.FindStringSubmatch(placeholder)
+				switch configuration.HeaderPlaceholder(match[1]) {
+				case configuration.RequestIDHeaderPlaceholder:
+					// All headers of a request share its ID.
+					if len(requestID) == 0 {
+						id := make([]byte, requestIDBytes)
+						if _, randErr := rand.Read(id); randErr != nil {
+							err = randErr
+						}
+						requestID = hex.EncodeToString(id)
+					}
+
+					return requestID
+				case configuration.EndpointHeaderPlaceholder:
+					return req.URL.Path
+				case configuration.TimestampHeaderPlaceholder:
+					return strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+				default:
+					return placeholder
+				}
+			},
+		))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to generate request id", err)
+	}
+
+	return header, nil
+}
+
+var _ http.RoundTripper = (*HeaderTransport)(nil)
+
+// HeaderTransport is an http.RoundTripper that adds the
+// rendered *HeaderTemplates to every request.
+type HeaderTransport struct {
+	base    http.RoundTripper
+	headers *HeaderTemplates
+}
+
+// NewHeaderTransport returns a new *HeaderTransport.
+func NewHeaderTransport(base http.RoundTripper, headers *HeaderTemplates) *HeaderTransport {
+	return &HeaderTransport{
+		base:    base,
+		headers: headers,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction with
+// the configured headers.
+func (t *HeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header, err := t.headers.Render(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the provided request.
+	req = req.Clone(req.Context())
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHeaderTemplates(t *testing.T) {
+	templates, err := NewHeaderTemplates(nil, "")
+	assert.NoError(t, err)
+	assert.Nil(t, templates)
+
+	assert.NoError(t, os.Setenv("ROSETTA_CLI_TEST_TOKEN", "secret"))
+	defer os.Unsetenv("ROSETTA_CLI_TEST_TOKEN")
+
+	templates, err = NewHeaderTemplates(
+		map[string]string{
+			"x-api-key":    "key-{{ env:ROSETTA_CLI_TEST_TOKEN }}",
+			"X-Request-ID": "{{request_id}}",
+			"X-Trace":      "{{endpoint}}/{{request_id}}",
+			"X-Sent-At":    "{{timestamp}}",
+		},
+		"{{env:ROSETTA_CLI_TEST_TOKEN}}",
+	)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/block", nil)
+	assert.NoError(t, err)
+	header, err := templates.Render(req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "key-secret", header.Get("X-Api-Key"))
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Regexp(t, regexp.MustCompile("^[0-9a-f]{16}$"), header.Get("X-Request-ID"))
+	assert.Equal(t, "/block/"+header.Get("X-Request-ID"), header.Get("X-Trace"))
+	_, err = strconv.ParseInt(header.Get("X-Sent-At"), 10, 64)
+	assert.NoError(t, err)
+
+	// Each request has a new ID
+	next, err := templates.Render(req)
+	assert.NoError(t, err)
+	assert.NotEqual(t, header.Get("X-Request-ID"), next.Get("X-Request-ID"))
+
+	_, err = NewHeaderTemplates(nil, "{{env:ROSETTA_CLI_TEST_MISSING}}")
+	assert.EqualError(
+		t,
+		err,
+		"environment variable ROSETTA_CLI_TEST_MISSING is not set: unable to resolve bearer token",
+	)
+}
+
+func TestHeaderTransport(t *testing.T) {
+	templates, err := NewHeaderTemplates(
+		map[string]string{"X-Tenant-ID": "acme"},
+		"token",
+	)
+	assert.NoError(t, err)
+
+	transport := NewHeaderTransport(roundTripFunc(
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "acme", req.Header.Get("X-Tenant-ID"))
+			assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+			}, nil
+		},
+	), templates)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/block", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, err = transport.RoundTrip(req)
+	assert.NoError(t, err)
+
+	// The provided request is not modified
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// HistoricalReconciler periodically reconciles the computed
+// balance of a sampled account at a historical block against
+// the live balance returned by /account/balance at that block.
+// Unlike active reconciliation (performed when a block is
+// synced), this detects implementations whose historical
+// balance lookups become incorrect after later blocks are
+// added.
+type HistoricalReconciler struct {
+	network        *types.NetworkIdentifier
+	fetcher        *fetcher.Fetcher
+	blockStorage   *modules.BlockStorage
+	balanceStorage *modules.BalanceStorage
+	counterStorage *modules.CounterStorage
+	parser         *parser.Parser
+	exemptAccounts map[string]struct{}
+	interval       time.Duration
+	minDepth       int64
+}
+
+// NewHistoricalReconciler returns a new *HistoricalReconciler.
+func NewHistoricalReconciler(
+	network *types.NetworkIdentifier,
+	fetcher *fetcher.Fetcher,
+	blockStorage *modules.BlockStorage,
+	balanceStorage *modules.BalanceStorage,
+	counterStorage *modules.CounterStorage,
+	parser *parser.Parser,
+	exemptAccounts []*types.AccountCurrency,
+	interval time.Duration,
+	minDepth int64,
+) *HistoricalReconciler {
+	exempt := map[string]struct{}{}
+	for _, account := range exemptAccounts {
+		exempt[types.Hash(account)] = struct{}{}
+	}
+
+	return &HistoricalReconciler{
+		network:        network,
+		fetcher:        fetcher,
+		blockStorage:   blockStorage,
+		balanceStorage: balanceStorage,
+		counterStorage: counterStorage,
+		parser:         parser,
+		exemptAccounts: exempt,
+		interval:       interval,
+		minDepth:       minDepth,
+	}
+}
+
+// Start performs a historical reconciliation every interval
+// until a reconciliation fails or ctx is canceled.
+func (h *HistoricalReconciler) Start(ctx context.Context) error {
+	tc := time.NewTicker(h.interval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		if err := h.Reconcile(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Reconcile reconciles a random account and currency with a
+// balance change in a random stored block at least minDepth
+// blocks below the head. If there is no such block (or the
+// computed balance at the block was pruned), nothing is
+// reconciled.
+func (h *HistoricalReconciler) Reconcile(ctx context.Context) error {
+	head, err := h.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil
+	}
+
+	oldest, err := h.blockStorage.GetOldestBlockIndex(ctx)
+	if err != nil {
+		return nil
+	}
+
+	newest := head.Index - h.minDepth
+	if newest < oldest {
+		return nil
+	}
+
+	index := oldest + rand.Int63n(newest-oldest+1) // #nosec G404
+	block, err := h.blockStorage.GetBlock(ctx, &types.PartialBlockIdentifier{Index: &index})
+	if err != nil {
+		// The block may have been pruned or orphaned
+		// since the head was fetched.
+		return nil
+	}
+
+	change := h.sampleChange(block)
+	if change == nil {
+		return nil
+	}
+
+	computed, err := h.balanceStorage.GetBalance(ctx, change.Account, change.Currency, index)
+	if errors.Is(err, storageErrs.ErrBalancePruned) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to get computed balance", err)
+	}
+
+	live, liveBlock, err := utils.CurrencyBalance(
+		ctx,
+		h.network,
+		h.fetcher,
+		change.Account,
+		change.Currency,
+		index,
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"%w: unable to get live balance of %s at block %d",
+			err,
+			types.AccountString(change.Account),
+			index,
+		)
+	}
+
+	// The block was orphaned after it was sampled.
+	if liveBlock.Hash != block.BlockIdentifier.Hash {
+		return nil
+	}
+
+	if computed.Value != live.Value {
+		return fmt.Errorf(
+			"%w: computed balance of %s %s at block %s is %s but live balance is %s",
+			results.ErrHistoricalReconciliation,
+			types.AccountString(change.Account),
+			types.PrintStruct(change.Currency),
+			types.PrintStruct(block.BlockIdentifier),
+			computed.Value,
+			live.Value,
+		)
+	}
+
+	_, _ = h.counterStorage.Update(ctx, results.HistoricalReconciliationsCounter, big.NewInt(1))
+	return nil
+}
+
+// sampleChange returns a random account and currency with a
+// balance change in block that is not exempt from
+// reconciliation (or nil if there are none).
+func (h *HistoricalReconciler) sampleChange(block *types.Block) *types.AccountCurrency {
+	changes := []*types.AccountCurrency{}
+	seen := map[string]struct{}{}
+	for _, transaction := range block.Transactions {
+		for _, op := range transaction.Operations {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			change := &types.AccountCurrency{
+				Account:  op.Account,
+				Currency: op.Amount.Currency,
+			}
+			key := types.Hash(change)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if _, ok := h.exemptAccounts[key]; ok {
+				continue
+			}
+
+			if len(h.parser.FindExemptions(change.Account, change.Currency)) > 0 {
+				continue
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return changes[rand.Intn(len(changes))] // #nosec G404
+}
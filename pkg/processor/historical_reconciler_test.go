@@ -0,0 +1,106 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoricalReconcilerSampleChange(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	otherCurrency := &types.Currency{Symbol: "ETH", Decimals: 18}
+	exemptCurrency := &types.Currency{Symbol: "STK", Decimals: 8}
+	addr1 := &types.AccountIdentifier{Address: "addr1"}
+	addr2 := &types.AccountIdentifier{Address: "addr2"}
+
+	p := parser.New(nil, nil, []*types.BalanceExemption{
+		{
+			Currency:      exemptCurrency,
+			ExemptionType: types.BalanceDynamic,
+		},
+	})
+	h := NewHistoricalReconciler(
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		p,
+		[]*types.AccountCurrency{
+			{Account: addr2, Currency: currency},
+		},
+		0,
+		0,
+	)
+
+	op := func(account *types.AccountIdentifier, currency *types.Currency) *types.Operation {
+		o := &types.Operation{Account: account}
+		if currency != nil {
+			o.Amount = &types.Amount{Value: "1", Currency: currency}
+		}
+
+		return o
+	}
+
+	t.Run("no eligible changes", func(t *testing.T) {
+		block := &types.Block{
+			Transactions: []*types.Transaction{
+				{
+					Operations: []*types.Operation{
+						op(nil, currency),
+						op(addr1, nil),
+						op(addr2, currency),
+						op(addr1, exemptCurrency),
+					},
+				},
+			},
+		}
+
+		assert.Nil(t, h.sampleChange(block))
+	})
+
+	t.Run("eligible changes", func(t *testing.T) {
+		block := &types.Block{
+			Transactions: []*types.Transaction{
+				{
+					Operations: []*types.Operation{
+						op(addr2, currency),
+						op(addr1, currency),
+					},
+				},
+				{
+					Operations: []*types.Operation{
+						op(addr1, currency),
+						op(addr2, otherCurrency),
+					},
+				},
+			},
+		}
+
+		expected := map[string]struct{}{
+			types.Hash(&types.AccountCurrency{Account: addr1, Currency: currency}):      {},
+			types.Hash(&types.AccountCurrency{Account: addr2, Currency: otherCurrency}): {},
+		}
+		for i := 0; i < 20; i++ {
+			change := h.sampleChange(block)
+			assert.NotNil(t, change)
+			assert.Contains(t, expected, types.Hash(change))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// InjectOperations returns intent with injected appended (indexed
+// after the last operation in intent). The account of an injected
+// operation populating AccountFromOperation is copied from the
+// operation in intent with that index. If injected is empty,
+// intent is returned unmodified.
+func InjectOperations(
+	intent []*types.Operation,
+	injected []*configuration.InjectedOperation,
+) ([]*types.Operation, error) {
+	if len(injected) == 0 {
+		return intent, nil
+	}
+
+	accounts := map[int64]*types.AccountIdentifier{}
+	next := int64(0)
+	for _, op := range intent {
+		accounts[op.OperationIdentifier.Index] = op.Account
+		if op.OperationIdentifier.Index >= next {
+			next = op.OperationIdentifier.Index + 1
+		}
+	}
+
+	operations := make([]*types.Operation, len(intent), len(intent)+len(injected))
+	copy(operations, intent)
+	for i, injection := range injected {
+		account := injection.Account
+		if index := injection.AccountFromOperation; index != nil {
+			var ok bool
+			account, ok = accounts[*index]
+			if !ok || account == nil {
+				return nil, fmt.Errorf(
+					"injected operation %d copies the account of operation %d but it has no account",
+					i,
+					*index,
+				)
+			}
+		}
+
+		operations = append(operations, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: next + int64(i)},
+			Type:                injection.Type,
+			Account:             account,
+			Amount:              injection.Amount,
+			Metadata:            injection.Metadata,
+		})
+	}
+
+	return operations, nil
+}
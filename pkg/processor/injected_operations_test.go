@@ -0,0 +1,107 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectOperations(t *testing.T) {
+	currency := &types.Currency{Symbol: "ETH", Decimals: 18}
+	sender := &types.AccountIdentifier{Address: "sender"}
+	recipient := &types.AccountIdentifier{Address: "recipient"}
+	delegate := &types.AccountIdentifier{Address: "delegate"}
+	intent := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "TRANSFER",
+			Account:             sender,
+			Amount:              &types.Amount{Value: "-100", Currency: currency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                "TRANSFER",
+			Account:             recipient,
+			Amount:              &types.Amount{Value: "100", Currency: currency},
+		},
+	}
+
+	var tests = map[string]struct {
+		injected []*configuration.InjectedOperation
+		expected []*types.Operation
+		err      bool
+	}{
+		"no injected operations": {
+			expected: intent,
+		},
+		"static and templated operations": {
+			injected: []*configuration.InjectedOperation{
+				{
+					Type:                 "GAS",
+					AccountFromOperation: types.Int64(0),
+					Amount:               &types.Amount{Value: "-10", Currency: currency},
+				},
+				{
+					Type:     "DELEGATE",
+					Account:  delegate,
+					Metadata: map[string]interface{}{"resource": "bandwidth"},
+				},
+			},
+			expected: append(intent[:2:2],
+				&types.Operation{
+					OperationIdentifier: &types.OperationIdentifier{Index: 2},
+					Type:                "GAS",
+					Account:             sender,
+					Amount:              &types.Amount{Value: "-10", Currency: currency},
+				},
+				&types.Operation{
+					OperationIdentifier: &types.OperationIdentifier{Index: 3},
+					Type:                "DELEGATE",
+					Account:             delegate,
+					Metadata:            map[string]interface{}{"resource": "bandwidth"},
+				},
+			),
+		},
+		"missing operation": {
+			injected: []*configuration.InjectedOperation{
+				{
+					Type:                 "GAS",
+					AccountFromOperation: types.Int64(2),
+				},
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			operations, err := InjectOperations(intent, test.injected)
+			if test.err {
+				assert.Error(t, err)
+				assert.Nil(t, operations)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, operations)
+			assert.Len(t, intent, 2)
+		})
+	}
+}
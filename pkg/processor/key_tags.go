@@ -0,0 +1,219 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// keyTagsPrefix must not start with any namespace used
+	// by storage modules (ex: "key"), which are scanned
+	// by prefix.
+	keyTagsPrefix = "tag"
+
+	// keyCSVHeader is the first column of an optional
+	// header row in a key CSV.
+	keyCSVHeader = "address"
+
+	// keyCSVTagSeparator separates the tags in
+	// the last column of a key CSV.
+	keyCSVTagSeparator = ";"
+)
+
+func keyTagsKey(account *types.AccountIdentifier) []byte {
+	return []byte(fmt.Sprintf("%s/%s", keyTagsPrefix, types.Hash(account)))
+}
+
+// KeyTagStorage stores the tags of keys in
+// KeyStorage (ex: "faucet").
+type KeyTagStorage struct {
+	db database.Database
+}
+
+// NewKeyTagStorage returns a new *KeyTagStorage.
+func NewKeyTagStorage(db database.Database) *KeyTagStorage {
+	return &KeyTagStorage{db: db}
+}
+
+// SetTags transactionally replaces the tags of account.
+func (k *KeyTagStorage) SetTags(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	tags []string,
+) error {
+	val, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal tags", err)
+	}
+
+	return dbTx.Set(ctx, keyTagsKey(account), val, false)
+}
+
+// GetTags transactionally retrieves the tags of account.
+func (k *KeyTagStorage) GetTags(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+) ([]string, error) {
+	exists, val, err := dbTx.Get(ctx, keyTagsKey(account))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get tags", err)
+	}
+
+	if !exists {
+		return []string{}, nil
+	}
+
+	var tags []string
+	if err := json.Unmarshal(val, &tags); err != nil {
+		return nil, fmt.Errorf("%w: unable to unmarshal tags", err)
+	}
+
+	return tags, nil
+}
+
+// HasAnyTag returns a boolean indicating if account has any
+// of tags. If no tags are provided, true is returned.
+func (k *KeyTagStorage) HasAnyTag(
+	ctx context.Context,
+	dbTx database.Transaction,
+	account *types.AccountIdentifier,
+	tags []string,
+) (bool, error) {
+	if len(tags) == 0 {
+		return true, nil
+	}
+
+	accountTags, err := k.GetTags(ctx, dbTx, account)
+	if err != nil {
+		return false, err
+	}
+
+	return anyTag(accountTags, tags), nil
+}
+
+// anyTag returns a boolean indicating if accountTags contains
+// any of tags. If no tags are provided, true is returned.
+func anyTag(accountTags []string, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	for _, tag := range tags {
+		for _, accountTag := range accountTags {
+			if tag == accountTag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ImportKeysCSV imports keys from a CSV with rows of
+// address, curve type, hex-encoded private key, and
+// (optionally) tags separated by semicolons. A header row
+// starting with "address" is skipped. Keys that already exist
+// are not overwritten but their tags are replaced. The number
+// of imported rows is returned.
+//
+// All rows are imported in a single database transaction, so
+// no keys are imported if any row is invalid.
+func ImportKeysCSV(
+	ctx context.Context,
+	r io.Reader,
+	db database.Database,
+	keyStorage *modules.KeyStorage,
+	tagStorage *KeyTagStorage,
+) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	dbTx := db.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	imported := 0
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: unable to read row %d", err, row)
+		}
+
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), keyCSVHeader) {
+			continue
+		}
+
+		if len(record) < 3 || len(record) > 4 {
+			return 0, fmt.Errorf("row %d has %d columns but expected 3 or 4", row, len(record))
+		}
+
+		account := &types.AccountIdentifier{Address: strings.TrimSpace(record[0])}
+		if len(account.Address) == 0 {
+			return 0, fmt.Errorf("row %d has an empty address", row)
+		}
+
+		keyPair, err := keys.ImportPrivateKey(
+			strings.TrimSpace(record[2]),
+			types.CurveType(strings.TrimSpace(record[1])),
+		)
+		if err != nil {
+			return 0, fmt.Errorf("%w: unable to import private key in row %d", err, row)
+		}
+
+		err = keyStorage.StoreTransactional(ctx, account, keyPair, dbTx)
+		if err != nil && !errors.Is(err, storageErrs.ErrAddrExists) {
+			return 0, fmt.Errorf("%w: unable to store key in row %d", err, row)
+		}
+
+		tags := []string{}
+		if len(record) == 4 {
+			for _, tag := range strings.Split(record[3], keyCSVTagSeparator) {
+				if tag = strings.TrimSpace(tag); len(tag) > 0 {
+					tags = append(tags, tag)
+				}
+			}
+		}
+
+		if err := tagStorage.SetTags(ctx, dbTx, account, tags); err != nil {
+			return 0, fmt.Errorf("%w: unable to store tags in row %d", err, row)
+		}
+
+		imported++
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%w: unable to commit keys", err)
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportKeysCSV(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	keyStorage := modules.NewKeyStorage(db)
+	tagStorage := NewKeyTagStorage(db)
+
+	privateKeys := []string{}
+	for i := 0; i < 2; i++ {
+		keyPair, err := keys.GenerateKeypair(types.Secp256k1)
+		assert.NoError(t, err)
+		privateKeys = append(privateKeys, hex.EncodeToString(keyPair.PrivateKey))
+	}
+
+	faucet := &types.AccountIdentifier{Address: "addr1"}
+	untagged := &types.AccountIdentifier{Address: "addr2"}
+
+	// Invalid rows abort the entire import
+	csv := fmt.Sprintf(
+		"addr1,secp256k1,%s,faucet\naddr2,secp256k1,%s,a,b\n",
+		privateKeys[0],
+		privateKeys[1],
+	)
+	imported, err := ImportKeysCSV(ctx, strings.NewReader(csv), db, keyStorage, tagStorage)
+	assert.Error(t, err)
+	assert.Equal(t, 0, imported)
+
+	accounts, err := keyStorage.GetAllAccounts(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 0)
+
+	csv = fmt.Sprintf(
+		"address,curve_type,private_key,tags\naddr1,secp256k1,%s,faucet; hot\naddr2,secp256k1,%s\n",
+		privateKeys[0],
+		privateKeys[1],
+	)
+	imported, err = ImportKeysCSV(ctx, strings.NewReader(csv), db, keyStorage, tagStorage)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	accounts, err = keyStorage.GetAllAccounts(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*types.AccountIdentifier{faucet, untagged}, accounts)
+
+	dbTx := db.ReadTransaction(ctx)
+	tags, err := tagStorage.GetTags(ctx, dbTx, faucet)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"faucet", "hot"}, tags)
+
+	tagged, err := tagStorage.HasAnyTag(ctx, dbTx, faucet, []string{"cold", "faucet"})
+	assert.NoError(t, err)
+	assert.True(t, tagged)
+
+	tagged, err = tagStorage.HasAnyTag(ctx, dbTx, untagged, []string{"faucet"})
+	assert.NoError(t, err)
+	assert.False(t, tagged)
+
+	tagged, err = tagStorage.HasAnyTag(ctx, dbTx, untagged, nil)
+	assert.NoError(t, err)
+	assert.True(t, tagged)
+	dbTx.Discard(ctx)
+
+	// Re-importing an existing key replaces its tags
+	csv = fmt.Sprintf("addr1,secp256k1,%s,cold\n", privateKeys[0])
+	imported, err = ImportKeysCSV(ctx, strings.NewReader(csv), db, keyStorage, tagStorage)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	dbTx = db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+	tags, err = tagStorage.GetTags(ctx, dbTx, faucet)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cold"}, tags)
+}
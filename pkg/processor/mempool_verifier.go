@@ -0,0 +1,258 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// MempoolVerificationInterval is how often the
+	// mempool is checked for submitted transactions.
+	MempoolVerificationInterval = 1 * time.Second
+)
+
+// MempoolVerifier checks that each submitted transaction
+// appears in /mempool with operations matching its intent
+// before it is included in a block.
+type MempoolVerifier struct {
+	network          *types.NetworkIdentifier
+	fetcher          *fetcher.Fetcher
+	database         database.Database
+	blockStorage     *modules.BlockStorage
+	broadcastStorage *modules.BroadcastStorage
+	matcher          *OperationMatcher
+	timeout          time.Duration
+
+	// submittedLock protects submitted.
+	submittedLock sync.Mutex
+
+	// submitted is the time each transaction that has not
+	// yet been seen in the mempool was first submitted
+	// (keyed by transaction hash).
+	submitted map[string]time.Time
+}
+
+// NewMempoolVerifier returns a new *MempoolVerifier.
+func NewMempoolVerifier(
+	network *types.NetworkIdentifier,
+	fetcher *fetcher.Fetcher,
+	database database.Database,
+	blockStorage *modules.BlockStorage,
+	broadcastStorage *modules.BroadcastStorage,
+	matcher *OperationMatcher,
+	timeout time.Duration,
+) *MempoolVerifier {
+	return &MempoolVerifier{
+		network:          network,
+		fetcher:          fetcher,
+		database:         database,
+		blockStorage:     blockStorage,
+		broadcastStorage: broadcastStorage,
+		matcher:          matcher,
+		timeout:          timeout,
+		submitted:        map[string]time.Time{},
+	}
+}
+
+// TransactionSubmitted is called when a transaction is accepted
+// by /construction/submit. Rebroadcasts of a transaction that has
+// not yet been seen do not reset its submission time.
+func (v *MempoolVerifier) TransactionSubmitted(
+	transactionIdentifier *types.TransactionIdentifier,
+) {
+	v.submittedLock.Lock()
+	defer v.submittedLock.Unlock()
+
+	if _, ok := v.submitted[transactionIdentifier.Hash]; ok {
+		return
+	}
+
+	v.submitted[transactionIdentifier.Hash] = time.Now()
+}
+
+// pendingSubmissions returns a copy of submitted.
+func (v *MempoolVerifier) pendingSubmissions() map[string]time.Time {
+	v.submittedLock.Lock()
+	defer v.submittedLock.Unlock()
+
+	pending := make(map[string]time.Time, len(v.submitted))
+	for hash, submittedAt := range v.submitted {
+		pending[hash] = submittedAt
+	}
+
+	return pending
+}
+
+func (v *MempoolVerifier) remove(hash string) {
+	v.submittedLock.Lock()
+	defer v.submittedLock.Unlock()
+
+	delete(v.submitted, hash)
+}
+
+// Start checks the mempool every MempoolVerificationInterval
+// until ctx is canceled or a transaction fails verification.
+func (v *MempoolVerifier) Start(ctx context.Context) error {
+	ticker := time.NewTicker(MempoolVerificationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := v.Verify(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Verify checks all submitted transactions that have not
+// yet been seen against the current mempool.
+func (v *MempoolVerifier) Verify(ctx context.Context) error {
+	pending := v.pendingSubmissions()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	broadcasts, err := v.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	intents := map[string][]*types.Operation{}
+	for _, broadcast := range broadcasts {
+		intents[broadcast.TransactionIdentifier.Hash] = broadcast.Intent
+	}
+
+	mempool, fetchErr := v.fetcher.Mempool(ctx, v.network)
+	if fetchErr != nil {
+		return fmt.Errorf("%w: unable to fetch mempool", fetchErr.Err)
+	}
+
+	inMempool := map[string]struct{}{}
+	for _, transactionIdentifier := range mempool {
+		inMempool[transactionIdentifier.Hash] = struct{}{}
+	}
+
+	for hash, submittedAt := range pending {
+		transactionIdentifier := &types.TransactionIdentifier{Hash: hash}
+		intent, ok := intents[hash]
+		if !ok {
+			// The broadcast is no longer tracked (it was
+			// confirmed or it failed) and was never seen.
+			v.remove(hash)
+			results.RecordMempoolMissed()
+			continue
+		}
+
+		if _, ok := inMempool[hash]; ok {
+			if err := v.verifyTransaction(ctx, transactionIdentifier, intent); err != nil {
+				return err
+			}
+
+			v.remove(hash)
+			results.RecordMempoolLatency(time.Since(submittedAt))
+			continue
+		}
+
+		included, err := v.includedInBlock(ctx, transactionIdentifier)
+		if err != nil {
+			return err
+		}
+
+		if included {
+			v.remove(hash)
+			results.RecordMempoolMissed()
+			continue
+		}
+
+		if time.Since(submittedAt) > v.timeout {
+			return fmt.Errorf(
+				"%w: transaction %s was not seen in the mempool within %s of submission",
+				results.ErrMempoolVerification,
+				hash,
+				v.timeout,
+			)
+		}
+	}
+
+	return nil
+}
+
+// verifyTransaction fetches a transaction from /mempool/transaction
+// and ensures its operations match intent.
+func (v *MempoolVerifier) verifyTransaction(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+	intent []*types.Operation,
+) error {
+	transaction, _, fetchErr := v.fetcher.MempoolTransaction(
+		ctx,
+		v.network,
+		transactionIdentifier,
+	)
+	if fetchErr != nil {
+		return fmt.Errorf(
+			"%w: unable to fetch mempool transaction %s",
+			fetchErr.Err,
+			transactionIdentifier.Hash,
+		)
+	}
+
+	if err := v.matcher.ExpectedOperations(
+		intent,
+		transaction.Operations,
+		false,
+	); err != nil {
+		return fmt.Errorf(
+			"%w: mempool transaction %s did not match intent: %s",
+			results.ErrMempoolVerification,
+			transactionIdentifier.Hash,
+			err.Error(),
+		)
+	}
+
+	return nil
+}
+
+// includedInBlock returns a boolean indicating if a transaction
+// has been included in a synced block.
+func (v *MempoolVerifier) includedInBlock(
+	ctx context.Context,
+	transactionIdentifier *types.TransactionIdentifier,
+) (bool, error) {
+	dbTx := v.database.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	block, _, err := v.blockStorage.FindTransaction(ctx, transactionIdentifier, dbTx)
+	if err != nil {
+		return false, fmt.Errorf("%w: unable to perform transaction search", err)
+	}
+
+	return block != nil, nil
+}
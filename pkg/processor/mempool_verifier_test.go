@@ -0,0 +1,196 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMempoolVerifier(t *testing.T) {
+	network := &types.NetworkIdentifier{
+		Blockchain: "bitcoin",
+		Network:    "mainnet",
+	}
+	a, err := asserter.NewClientWithOptions(
+		network,
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	transfer := func(address string, value string, status *string) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{},
+			Type:                "Transfer",
+			Status:              status,
+			Account:             &types.AccountIdentifier{Address: address},
+			Amount:              &types.Amount{Value: value, Currency: currency},
+		}
+	}
+	intent := []*types.Operation{transfer("sender", "-100", nil)}
+
+	var tests = map[string]struct {
+		mempool  []*types.TransactionIdentifier
+		observed []*types.Operation
+		tracked  bool
+		timeout  time.Duration
+
+		err      bool
+		verified int64
+		missed   int64
+	}{
+		"verified": {
+			mempool:  []*types.TransactionIdentifier{{Hash: "tx"}},
+			observed: []*types.Operation{transfer("sender", "-100", types.String("Success"))},
+			tracked:  true,
+			timeout:  time.Minute,
+			verified: 1,
+		},
+		"mismatched operations": {
+			mempool:  []*types.TransactionIdentifier{{Hash: "tx"}},
+			observed: []*types.Operation{transfer("other", "-100", types.String("Success"))},
+			tracked:  true,
+			timeout:  time.Minute,
+			err:      true,
+		},
+		"not yet seen": {
+			mempool: []*types.TransactionIdentifier{},
+			tracked: true,
+			timeout: time.Minute,
+		},
+		"timed out": {
+			mempool: []*types.TransactionIdentifier{},
+			tracked: true,
+			err:     true,
+		},
+		"no longer tracked": {
+			mempool: []*types.TransactionIdentifier{},
+			timeout: time.Minute,
+			missed:  1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/mempool":
+					assert.NoError(t, json.NewEncoder(w).Encode(&types.MempoolResponse{
+						TransactionIdentifiers: test.mempool,
+					}))
+				case "/mempool/transaction":
+					assert.NoError(t, json.NewEncoder(w).Encode(&types.MempoolTransactionResponse{
+						Transaction: &types.Transaction{
+							TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+							Operations:            test.observed,
+						},
+					}))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			dir, err := utils.CreateTempDir()
+			assert.NoError(t, err)
+			defer utils.RemoveTempDir(dir)
+
+			db, err := database.NewBadgerDatabase(ctx, dir)
+			assert.NoError(t, err)
+			defer db.Close(ctx)
+
+			blockStorage := modules.NewBlockStorage(db, 1)
+			broadcastStorage := modules.NewBroadcastStorage(db, 10, 3, 10, false, 5)
+			if test.tracked {
+				dbTx := db.Transaction(ctx)
+				assert.NoError(t, broadcastStorage.Broadcast(
+					ctx,
+					dbTx,
+					"broadcast",
+					network,
+					intent,
+					&types.TransactionIdentifier{Hash: "tx"},
+					"payload",
+					1,
+				))
+				assert.NoError(t, dbTx.Commit(ctx))
+			}
+
+			verifier := NewMempoolVerifier(
+				network,
+				fetcher.New(server.URL, fetcher.WithAsserter(a)),
+				db,
+				blockStorage,
+				broadcastStorage,
+				NewOperationMatcher(parser.New(a, nil, nil), nil),
+				test.timeout,
+			)
+
+			before := results.MempoolResults()
+			if before == nil {
+				before = &results.MempoolStats{}
+			}
+
+			verifier.TransactionSubmitted(&types.TransactionIdentifier{Hash: "tx"})
+			err = verifier.Verify(ctx)
+			if test.err {
+				assert.True(t, errors.Is(err, results.ErrMempoolVerification))
+				return
+			}
+			assert.NoError(t, err)
+
+			after := results.MempoolResults()
+			if after == nil {
+				after = &results.MempoolStats{}
+			}
+			assert.Equal(t, test.verified, after.TransactionsVerified-before.TransactionsVerified)
+			assert.Equal(t, test.missed, after.TransactionsMissed-before.TransactionsMissed)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// metadataCacheEntry is a single cached
+// /construction/metadata response.
+type metadataCacheEntry struct {
+	metadata     map[string]interface{}
+	suggestedFee []*types.Amount
+	fetched      time.Time
+}
+
+// MetadataCache stores /construction/metadata responses
+// so that they can be reused for identical requests
+// until they expire.
+type MetadataCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*metadataCacheEntry
+}
+
+// NewMetadataCache returns a new *MetadataCache where
+// entries expire after ttl.
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	return &MetadataCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]*metadataCacheEntry{},
+	}
+}
+
+// metadataCacheKey returns the key used to cache the response
+// to a particular /construction/metadata request.
+func metadataCacheKey(
+	networkIdentifier *types.NetworkIdentifier,
+	options map[string]interface{},
+	publicKeys []*types.PublicKey,
+) string {
+	return types.Hash(&types.ConstructionMetadataRequest{
+		NetworkIdentifier: networkIdentifier,
+		Options:           options,
+		PublicKeys:        publicKeys,
+	})
+}
+
+// Get returns a copy of the cached metadata and suggested fee for
+// a key if it exists and has not expired (so that callers can modify
+// it without affecting other jobs). Expired entries are evicted.
+func (m *MetadataCache) Get(key string) (map[string]interface{}, []*types.Amount, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if m.now().Sub(entry.fetched) > m.ttl {
+		delete(m.entries, key)
+		return nil, nil, false
+	}
+
+	return copyMetadata(entry.metadata), copyAmounts(entry.suggestedFee), true
+}
+
+// Set stores a copy of the metadata and suggested fee for a key.
+func (m *MetadataCache) Set(
+	key string,
+	metadata map[string]interface{},
+	suggestedFee []*types.Amount,
+) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.entries[key] = &metadataCacheEntry{
+		metadata:     copyMetadata(metadata),
+		suggestedFee: copyAmounts(suggestedFee),
+		fetched:      m.now(),
+	}
+}
+
+// copyMetadata returns a deep copy of metadata. Metadata is
+// decoded from JSON, so only maps and slices are copied.
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+
+	copied := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		copied[k] = copyValue(v)
+	}
+
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyMetadata(v)
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+
+		return copied
+	default:
+		return v
+	}
+}
+
+// copyAmounts returns a deep copy of amounts.
+func copyAmounts(amounts []*types.Amount) []*types.Amount {
+	if amounts == nil {
+		return nil
+	}
+
+	copied := make([]*types.Amount, len(amounts))
+	for i, amount := range amounts {
+		if amount == nil {
+			continue
+		}
+
+		copied[i] = &types.Amount{
+			Value:    amount.Value,
+			Metadata: copyMetadata(amount.Metadata),
+		}
+		if amount.Currency != nil {
+			copied[i].Currency = &types.Currency{
+				Symbol:   amount.Currency.Symbol,
+				Decimals: amount.Currency.Decimals,
+				Metadata: copyMetadata(amount.Currency.Metadata),
+			}
+		}
+	}
+
+	return copied
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataCache(t *testing.T) {
+	network := &types.NetworkIdentifier{
+		Blockchain: "bitcoin",
+		Network:    "testnet",
+	}
+	metadata := map[string]interface{}{
+		"nonce":  float64(10),
+		"inputs": []interface{}{map[string]interface{}{"index": float64(0)}},
+	}
+	suggestedFee := []*types.Amount{
+		{
+			Value:    "100",
+			Currency: opAmountCurrency.Currency,
+		},
+	}
+
+	start := time.Now()
+	now := start
+	cache := NewMetadataCache(10 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	key := metadataCacheKey(network, map[string]interface{}{"from": "addr1"}, nil)
+	otherKey := metadataCacheKey(network, map[string]interface{}{"from": "addr2"}, nil)
+	assert.NotEqual(t, key, otherKey)
+
+	_, _, ok := cache.Get(key)
+	assert.False(t, ok)
+
+	cache.Set(key, metadata, suggestedFee)
+
+	// Cached entry is returned before expiry
+	now = start.Add(5 * time.Second)
+	cachedMetadata, cachedFee, ok := cache.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, metadata, cachedMetadata)
+	assert.Equal(t, suggestedFee, cachedFee)
+
+	_, _, ok = cache.Get(otherKey)
+	assert.False(t, ok)
+
+	// Modifying a returned entry does not modify the cache
+	cachedMetadata["nonce"] = float64(11)
+	cachedMetadata["inputs"].([]interface{})[0].(map[string]interface{})["index"] = float64(1)
+	cachedFee[0].Value = "200"
+	metadata["nonce"] = float64(12)
+
+	cachedMetadata, cachedFee, ok = cache.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]interface{}{
+		"nonce":  float64(10),
+		"inputs": []interface{}{map[string]interface{}{"index": float64(0)}},
+	}, cachedMetadata)
+	assert.Equal(t, "100", cachedFee[0].Value)
+
+	// Entry is evicted after expiry
+	now = start.Add(11 * time.Second)
+	_, _, ok = cache.Get(key)
+	assert.False(t, ok)
+	assert.Len(t, cache.entries, 0)
+}
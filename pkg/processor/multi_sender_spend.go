@@ -0,0 +1,296 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+const (
+	// multiSenderSpendPrefix is the prefix of the broadcast
+	// identifier of each multi-sender spend.
+	multiSenderSpendPrefix = "multi_sender_spend:"
+)
+
+// IsMultiSenderSpend returns a boolean indicating if a
+// broadcast identifier belongs to a multi-sender spend.
+func IsMultiSenderSpend(identifier string) bool {
+	return strings.HasPrefix(identifier, multiSenderSpendPrefix)
+}
+
+// SelectSenderCoins returns a random selection of at most
+// maxSenders (if maxSenders > 0) of candidates if there are at
+// least minSenders of them. Otherwise, nil is returned.
+func SelectSenderCoins(
+	candidates []*types.AccountCoin,
+	minSenders int,
+	maxSenders int,
+) []*types.AccountCoin {
+	if len(candidates) < minSenders {
+		return nil
+	}
+
+	selected := make([]*types.AccountCoin, len(candidates))
+	copy(selected, candidates)
+	rand.Shuffle(len(selected), func(i, j int) { // #nosec G404
+		selected[i], selected[j] = selected[j], selected[i]
+	})
+
+	if maxSenders > 0 && len(selected) > maxSenders {
+		selected = selected[:maxSenders]
+	}
+
+	return selected
+}
+
+// MultiSenderIntent returns the intent of a transaction that
+// spends the coin of each of spends into a single output of
+// value to the account of the first spend.
+func MultiSenderIntent(
+	config *configuration.MultiSenderSpendConfiguration,
+	spends []*types.AccountCoin,
+	value *big.Int,
+) []*types.Operation {
+	intent := []*types.Operation{}
+	for i, spend := range spends {
+		intent = append(intent, &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(i)},
+			Type:                config.InputOperationType,
+			Account:             spend.Account,
+			Amount: &types.Amount{
+				Value:    "-" + spend.Coin.Amount.Value,
+				Currency: spend.Coin.Amount.Currency,
+			},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: spend.Coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		})
+	}
+
+	return append(intent, &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{Index: int64(len(spends))},
+		Type:                config.OutputOperationType,
+		Account:             spends[0].Account,
+		Amount: &types.Amount{
+			Value:    value.String(),
+			Currency: config.Currency,
+		},
+	})
+}
+
+// MultiSenderSpender periodically spends coins held by
+// several accounts in the key store in a single transaction.
+type MultiSenderSpender struct {
+	network           *types.NetworkIdentifier
+	helper            *CoordinatorHelper
+	config            *configuration.MultiSenderSpendConfiguration
+	confirmationDepth int64
+}
+
+// NewMultiSenderSpender returns a new *MultiSenderSpender.
+func NewMultiSenderSpender(
+	network *types.NetworkIdentifier,
+	helper *CoordinatorHelper,
+	config *configuration.MultiSenderSpendConfiguration,
+	confirmationDepth int64,
+) *MultiSenderSpender {
+	return &MultiSenderSpender{
+		network:           network,
+		helper:            helper,
+		config:            config,
+		confirmationDepth: confirmationDepth,
+	}
+}
+
+// Start attempts a spend every configured interval
+// until ctx is canceled.
+func (m *MultiSenderSpender) Start(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(m.config.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := m.Spend(ctx); err != nil {
+				return fmt.Errorf("%w: unable to perform multi-sender spend", err)
+			}
+		}
+	}
+}
+
+// Spend broadcasts a transaction spending one coin from each
+// of a random selection of unlocked accounts. It returns the
+// identifier of the transaction (nil if there were not enough
+// accounts holding coins or the coins could not cover the fee).
+func (m *MultiSenderSpender) Spend(
+	ctx context.Context,
+) (*types.TransactionIdentifier, error) {
+	if m.helper.Paused() {
+		return nil, nil
+	}
+
+	headBlock, err := m.helper.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil || headBlock == nil {
+		return nil, nil
+	}
+
+	dbTx := m.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	accounts, err := m.helper.AllAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	locked, err := m.helper.LockedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get locked accounts", err)
+	}
+
+	lockedAccounts := map[string]struct{}{}
+	for _, account := range locked {
+		lockedAccounts[types.Hash(account)] = struct{}{}
+	}
+
+	candidates := []*types.AccountCoin{}
+	for _, account := range accounts {
+		if _, ok := lockedAccounts[types.Hash(account)]; ok {
+			continue
+		}
+
+		coins, err := m.helper.Coins(ctx, dbTx, account, m.config.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get coins", err)
+		}
+
+		if len(coins) == 0 {
+			continue
+		}
+
+		candidates = append(candidates, &types.AccountCoin{
+			Account: account,
+			Coin:    coins[0],
+		})
+	}
+
+	spends := SelectSenderCoins(candidates, m.config.MinSenders, m.config.MaxSenders)
+	if len(spends) == 0 {
+		return nil, nil
+	}
+
+	transactionIdentifier, err := m.spend(ctx, dbTx, spends)
+	if err != nil {
+		return nil, err
+	}
+
+	if transactionIdentifier == nil {
+		return nil, nil
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit multi-sender spend", err)
+	}
+
+	color.Cyan(
+		"spending coins of %d accounts in transaction %s",
+		len(spends),
+		transactionIdentifier.Hash,
+	)
+
+	return transactionIdentifier, nil
+}
+
+// spend constructs, signs, and enqueues a transaction spending
+// the coins of spends. If the suggested fee is at least the
+// value of the coins, nothing is broadcast.
+func (m *MultiSenderSpender) spend(
+	ctx context.Context,
+	dbTx database.Transaction,
+	spends []*types.AccountCoin,
+) (*types.TransactionIdentifier, error) {
+	total := new(big.Int)
+	for _, spend := range spends {
+		value, err := types.AmountValue(spend.Coin.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse coin amount", err)
+		}
+
+		total.Add(total, value)
+	}
+
+	// The suggested fee is determined using an intent
+	// that spends all coins without paying a fee.
+	_, suggestedFee, _, err := standaloneMetadata(
+		ctx,
+		dbTx,
+		m.helper,
+		m.network,
+		MultiSenderIntent(m.config, spends, total),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := new(big.Int)
+	for _, amount := range suggestedFee {
+		if types.Hash(amount.Currency) != types.Hash(m.config.Currency) {
+			continue
+		}
+
+		value, err := types.AmountValue(amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse suggested fee", err)
+		}
+
+		fee.Add(fee, value)
+	}
+
+	output := new(big.Int).Sub(total, fee)
+	if output.Sign() <= 0 {
+		return nil, nil
+	}
+
+	intent := MultiSenderIntent(m.config, spends, output)
+	metadata, _, publicKeys, err := standaloneMetadata(ctx, dbTx, m.helper, m.network, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	return standaloneBroadcast(
+		ctx,
+		dbTx,
+		m.helper,
+		m.network,
+		multiSenderSpendPrefix,
+		intent,
+		metadata,
+		publicKeys,
+		m.confirmationDepth,
+	)
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectSenderCoins(t *testing.T) {
+	candidates := []*types.AccountCoin{}
+	for _, address := range []string{"a", "b", "c", "d"} {
+		candidates = append(candidates, &types.AccountCoin{
+			Account: &types.AccountIdentifier{Address: address},
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: address + ":0"},
+			},
+		})
+	}
+
+	var tests = map[string]struct {
+		minSenders int
+		maxSenders int
+		expected   int
+	}{
+		"all senders": {
+			minSenders: 2,
+			expected:   4,
+		},
+		"capped": {
+			minSenders: 2,
+			maxSenders: 3,
+			expected:   3,
+		},
+		"not enough senders": {
+			minSenders: 5,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selected := SelectSenderCoins(candidates, test.minSenders, test.maxSenders)
+			assert.Len(t, selected, test.expected)
+
+			seen := map[string]struct{}{}
+			for _, spend := range selected {
+				assert.Contains(t, candidates, spend)
+				seen[spend.Account.Address] = struct{}{}
+			}
+			assert.Len(t, seen, test.expected)
+		})
+	}
+}
+
+func TestMultiSenderIntent(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	config := &configuration.MultiSenderSpendConfiguration{
+		Currency:            currency,
+		InputOperationType:  "INPUT",
+		OutputOperationType: "OUTPUT",
+	}
+	spends := []*types.AccountCoin{
+		{
+			Account: &types.AccountIdentifier{Address: "addr1"},
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: "a"},
+				Amount:         &types.Amount{Value: "100", Currency: currency},
+			},
+		},
+		{
+			Account: &types.AccountIdentifier{Address: "addr2"},
+			Coin: &types.Coin{
+				CoinIdentifier: &types.CoinIdentifier{Identifier: "b"},
+				Amount:         &types.Amount{Value: "200", Currency: currency},
+			},
+		},
+	}
+
+	assert.Equal(t, []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "INPUT",
+			Account:             spends[0].Account,
+			Amount:              &types.Amount{Value: "-100", Currency: currency},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: spends[0].Coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                "INPUT",
+			Account:             spends[1].Account,
+			Amount:              &types.Amount{Value: "-200", Currency: currency},
+			CoinChange: &types.CoinChange{
+				CoinIdentifier: spends[1].Coin.CoinIdentifier,
+				CoinAction:     types.CoinSpent,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 2},
+			Type:                "OUTPUT",
+			Account:             spends[0].Account,
+			Amount:              &types.Amount{Value: "290", Currency: currency},
+		},
+	}, MultiSenderIntent(config, spends, big.NewInt(290)))
+
+	assert.True(t, IsMultiSenderSpend(multiSenderSpendPrefix+"tx"))
+	assert.False(t, IsMultiSenderSpend("job"))
+}
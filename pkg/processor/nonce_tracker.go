@@ -0,0 +1,285 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// maxExactFloatNonce is the largest nonce that can be
+// represented exactly by a JSON number decoded as a float64.
+const maxExactFloatNonce = 1 << 53
+
+// NonceTracker tracks the next nonce of each sender locally
+// so that multiple transactions can be constructed and
+// broadcast from the same sender concurrently on account-based
+// chains (the nonce returned by /construction/metadata does not
+// account for transactions that have not yet been broadcast).
+//
+// Senders are identified by the public keys provided to
+// /construction/metadata.
+type NonceTracker struct {
+	key        string
+	maxPending int
+	keyStorage *modules.KeyStorage
+
+	lock sync.Mutex
+	next map[string]*big.Int
+
+	// signers are the hashes of the individual public
+	// keys of each tracked sender.
+	signers map[string][]string
+}
+
+// NewNonceTracker returns a new *NonceTracker that reads and
+// overrides the nonce at key in /construction/metadata responses.
+// keyStorage is used to find the public keys of senders whose
+// broadcasts fail.
+func NewNonceTracker(
+	key string,
+	maxPending int,
+	keyStorage *modules.KeyStorage,
+) *NonceTracker {
+	return &NonceTracker{
+		key:        key,
+		maxPending: maxPending,
+		keyStorage: keyStorage,
+		next:       map[string]*big.Int{},
+		signers:    map[string][]string{},
+	}
+}
+
+// parseNonce parses a nonce returned in metadata (either
+// a JSON number or a string).
+func parseNonce(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case float64:
+		if v > maxExactFloatNonce {
+			return nil, fmt.Errorf(
+				"nonce %f cannot be represented exactly as a JSON number (return it as a string)",
+				v,
+			)
+		}
+
+		nonce, accuracy := new(big.Float).SetFloat64(v).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("nonce %f is not an integer", v)
+		}
+
+		return nonce, nil
+	case json.Number:
+		return types.BigInt(v.String())
+	case string:
+		return types.BigInt(v)
+	default:
+		return nil, fmt.Errorf("unsupported nonce type %T", value)
+	}
+}
+
+// formatNonce formats nonce with the same JSON type as original
+// (numbers are formatted as a json.Number so they are never
+// rounded).
+func formatNonce(original interface{}, nonce *big.Int) interface{} {
+	if _, ok := original.(string); ok {
+		return nonce.String()
+	}
+
+	return json.Number(nonce.String())
+}
+
+// Assign returns a copy of metadata with the nonce replaced by
+// the next nonce tracked for publicKeys (if it is larger than
+// the nonce returned by the node) and advances the tracked nonce.
+func (n *NonceTracker) Assign(
+	publicKeys []*types.PublicKey,
+	metadata map[string]interface{},
+) (map[string]interface{}, error) {
+	value, ok := metadata[n.key]
+	if !ok {
+		return nil, fmt.Errorf("nonce key %s not found in metadata", n.key)
+	}
+
+	fetched, err := parseNonce(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse nonce", err)
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	sender := types.Hash(publicKeys)
+	nonce := fetched
+	if next, ok := n.next[sender]; ok && next.Cmp(fetched) > 0 {
+		nonce = next
+	}
+	n.next[sender] = new(big.Int).Add(nonce, big.NewInt(1))
+
+	signers := make([]string, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		signers[i] = types.Hash(publicKey)
+	}
+	n.signers[sender] = signers
+
+	// We copy metadata so that we don't modify any
+	// cached responses.
+	assigned := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		assigned[k] = v
+	}
+	assigned[n.key] = formatNonce(value, nonce)
+
+	return assigned, nil
+}
+
+// Reset clears all tracked nonces so that the nonces returned
+// by the node are used.
+func (n *NonceTracker) Reset() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.next = map[string]*big.Int{}
+	n.signers = map[string][]string{}
+}
+
+// ResetSenders clears the tracked nonces of all senders debited
+// in intent so that the nonces returned by the node are used for
+// them. This should be called when a broadcast fails (leaving a
+// gap in the nonces of its senders). If the public key of any
+// sender is unknown, all tracked nonces are cleared.
+func (n *NonceTracker) ResetSenders(
+	ctx context.Context,
+	dbTx database.Transaction,
+	intent []*types.Operation,
+) {
+	failed := map[string]struct{}{}
+	for _, account := range debitedAccounts(intent) {
+		keyPair, err := n.keyStorage.GetTransactional(ctx, dbTx, account)
+		if err != nil {
+			n.Reset()
+			return
+		}
+
+		failed[types.Hash(keyPair.PublicKey)] = struct{}{}
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for sender, signers := range n.signers {
+		for _, signer := range signers {
+			if _, ok := failed[signer]; !ok {
+				continue
+			}
+
+			delete(n.next, sender)
+			delete(n.signers, sender)
+			break
+		}
+	}
+}
+
+// debitedAccounts returns the distinct accounts debited
+// in intent (in the order they first appear).
+func debitedAccounts(intent []*types.Operation) []*types.AccountIdentifier {
+	seen := map[string]struct{}{}
+	accounts := []*types.AccountIdentifier{}
+	for _, op := range intent {
+		if op.Account == nil || op.Amount == nil {
+			continue
+		}
+
+		value, ok := new(big.Int).SetString(op.Amount.Value, 10)
+		if !ok || value.Sign() >= 0 {
+			continue
+		}
+
+		key := types.Hash(op.Account)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		accounts = append(accounts, op.Account)
+	}
+
+	return accounts
+}
+
+// LockedAccounts returns all senders with at least maxPending
+// in-progress broadcasts. Unlike BroadcastStorage.LockedAccounts,
+// accounts with fewer pending broadcasts (or that are only
+// receiving funds) are not locked, so PendingDebits must be
+// subtracted from the balance of unlocked senders.
+func (n *NonceTracker) LockedAccounts(
+	broadcasts []*modules.Broadcast,
+) ([]*types.AccountIdentifier, error) {
+	pending := map[string]int{}
+	locked := []*types.AccountIdentifier{}
+	for _, broadcast := range broadcasts {
+		for _, account := range debitedAccounts(broadcast.Intent) {
+			key := types.Hash(account)
+			pending[key]++
+			if pending[key] == n.maxPending {
+				locked = append(locked, account)
+			}
+		}
+	}
+
+	return locked, nil
+}
+
+// PendingDebits returns the sum of all debits of account in
+// currency in broadcasts. Debits are counted until a broadcast
+// is removed from BroadcastStorage, so a debit may be counted
+// after it is already reflected in the synced balance (which
+// only underestimates the spendable balance).
+func PendingDebits(
+	broadcasts []*modules.Broadcast,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+) (*big.Int, error) {
+	debits := big.NewInt(0)
+	for _, broadcast := range broadcasts {
+		for _, op := range broadcast.Intent {
+			if op.Account == nil || op.Amount == nil {
+				continue
+			}
+
+			if types.Hash(op.Account) != types.Hash(account) ||
+				types.Hash(op.Amount.Currency) != types.Hash(currency) {
+				continue
+			}
+
+			value, err := types.AmountValue(op.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to parse intent amount", err)
+			}
+
+			if value.Sign() < 0 {
+				debits.Sub(debits, value)
+			}
+		}
+	}
+
+	return debits, nil
+}
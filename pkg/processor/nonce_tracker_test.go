@@ -0,0 +1,194 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var nonceCurrency = &types.Currency{Symbol: "ETH", Decimals: 18}
+
+func nonceTransfer(from string, to string, value string) *modules.Broadcast {
+	return &modules.Broadcast{
+		Intent: []*types.Operation{
+			{
+				Account: &types.AccountIdentifier{Address: from},
+				Amount:  &types.Amount{Value: "-" + value, Currency: nonceCurrency},
+			},
+			{
+				Account: &types.AccountIdentifier{Address: to},
+				Amount:  &types.Amount{Value: value, Currency: nonceCurrency},
+			},
+		},
+	}
+}
+
+func TestNonceTrackerAssign(t *testing.T) {
+	sender1 := []*types.PublicKey{{Bytes: []byte("pk1"), CurveType: types.Secp256k1}}
+	sender2 := []*types.PublicKey{{Bytes: []byte("pk2"), CurveType: types.Secp256k1}}
+	tracker := NewNonceTracker("nonce", 5, nil)
+
+	// The first nonce returned by the node is used.
+	metadata := map[string]interface{}{"nonce": float64(3), "gas": "10"}
+	assigned, err := tracker.Assign(sender1, metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"nonce": json.Number("3"), "gas": "10"}, assigned)
+
+	// The node doesn't know about our pending transaction yet.
+	assigned, err = tracker.Assign(sender1, metadata)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("4"), assigned["nonce"])
+	assert.Equal(t, float64(3), metadata["nonce"])
+
+	// Nonces are tracked per sender and keep their type.
+	assigned, err = tracker.Assign(sender2, map[string]interface{}{"nonce": "3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "3", assigned["nonce"])
+
+	// The node is ahead of us.
+	assigned, err = tracker.Assign(sender1, map[string]interface{}{"nonce": float64(10)})
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("10"), assigned["nonce"])
+
+	// After a reset, the node nonce is used.
+	tracker.Reset()
+	assigned, err = tracker.Assign(sender1, map[string]interface{}{"nonce": float64(6)})
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("6"), assigned["nonce"])
+
+	// Large nonces are never rounded.
+	assigned, err = tracker.Assign(sender2, map[string]interface{}{"nonce": "9007199254740993"})
+	assert.NoError(t, err)
+	assert.Equal(t, "9007199254740993", assigned["nonce"])
+	assigned, err = tracker.Assign(
+		sender2,
+		map[string]interface{}{"nonce": json.Number("9007199254740993")},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("9007199254740994"), assigned["nonce"])
+
+	// Invalid nonces are rejected.
+	_, err = tracker.Assign(sender1, map[string]interface{}{})
+	assert.Error(t, err)
+	_, err = tracker.Assign(sender1, map[string]interface{}{"nonce": 1.5})
+	assert.Error(t, err)
+	_, err = tracker.Assign(sender1, map[string]interface{}{"nonce": float64(1 << 60)})
+	assert.Error(t, err)
+	_, err = tracker.Assign(sender1, map[string]interface{}{"nonce": true})
+	assert.Error(t, err)
+}
+
+func TestNonceTrackerResetSenders(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	keyStorage := modules.NewKeyStorage(db)
+	tracker := NewNonceTracker("nonce", 5, keyStorage)
+
+	senders := map[string][]*types.PublicKey{}
+	dbTx := db.Transaction(ctx)
+	for _, address := range []string{"addr1", "addr2"} {
+		keyPair, err := keys.GenerateKeypair(types.Secp256k1)
+		assert.NoError(t, err)
+		assert.NoError(t, keyStorage.StoreTransactional(
+			ctx,
+			&types.AccountIdentifier{Address: address},
+			keyPair,
+			dbTx,
+		))
+		senders[address] = []*types.PublicKey{keyPair.PublicKey}
+	}
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	for _, publicKeys := range senders {
+		for i := 0; i < 2; i++ {
+			_, err := tracker.Assign(publicKeys, map[string]interface{}{"nonce": "0"})
+			assert.NoError(t, err)
+		}
+	}
+
+	// Only the nonce of the failed sender is reset.
+	dbTx = db.ReadTransaction(ctx)
+	tracker.ResetSenders(ctx, dbTx, nonceTransfer("addr1", "addr2", "10").Intent)
+	dbTx.Discard(ctx)
+
+	assigned, err := tracker.Assign(senders["addr1"], map[string]interface{}{"nonce": "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0", assigned["nonce"])
+	assigned, err = tracker.Assign(senders["addr2"], map[string]interface{}{"nonce": "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", assigned["nonce"])
+
+	// An unknown sender resets all nonces.
+	dbTx = db.ReadTransaction(ctx)
+	tracker.ResetSenders(ctx, dbTx, nonceTransfer("addr3", "addr2", "10").Intent)
+	dbTx.Discard(ctx)
+
+	assigned, err = tracker.Assign(senders["addr2"], map[string]interface{}{"nonce": "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "0", assigned["nonce"])
+}
+
+func TestNonceTrackerLockedAccounts(t *testing.T) {
+	tracker := NewNonceTracker("nonce", 2, nil)
+	locked, err := tracker.LockedAccounts([]*modules.Broadcast{
+		nonceTransfer("addr1", "addr2", "10"),
+		nonceTransfer("addr1", "addr3", "10"),
+		nonceTransfer("addr2", "addr3", "10"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.AccountIdentifier{{Address: "addr1"}}, locked)
+}
+
+func TestPendingDebits(t *testing.T) {
+	broadcasts := []*modules.Broadcast{
+		nonceTransfer("addr1", "addr2", "10"),
+		nonceTransfer("addr1", "addr3", "15"),
+		nonceTransfer("addr2", "addr1", "5"),
+	}
+
+	debits, err := PendingDebits(broadcasts, &types.AccountIdentifier{Address: "addr1"}, nonceCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(25), debits)
+
+	debits, err = PendingDebits(broadcasts, &types.AccountIdentifier{Address: "addr3"}, nonceCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), debits)
+
+	debits, err = PendingDebits(
+		broadcasts,
+		&types.AccountIdentifier{Address: "addr1"},
+		&types.Currency{Symbol: "BTC", Decimals: 8},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), debits)
+}
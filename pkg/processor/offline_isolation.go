@@ -0,0 +1,63 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// NetworkStatusFetcher fetches the network status of
+// an implementation. *fetcher.Fetcher implements it.
+type NetworkStatusFetcher interface {
+	NetworkStatus(
+		ctx context.Context,
+		network *types.NetworkIdentifier,
+		metadata map[string]interface{},
+	) (*types.NetworkStatusResponse, *fetcher.Error)
+}
+
+// VerifyOfflineIsolation returns an error if the implementation
+// behind offline serves /network/status. An implementation in
+// "offline mode" has no network state, so a successful
+// response means it is syncing (and that the offline
+// Construction API endpoints may depend on network state).
+func VerifyOfflineIsolation(
+	ctx context.Context,
+	offline NetworkStatusFetcher,
+	network *types.NetworkIdentifier,
+) error {
+	status, fetchErr := offline.NetworkStatus(ctx, network, nil)
+	if fetchErr != nil {
+		// The request may have failed because the
+		// run was stopped.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: /network/status returned current block %s",
+		results.ErrOfflineNotIsolated,
+		types.PrintStruct(status.CurrentBlockIdentifier),
+	)
+}
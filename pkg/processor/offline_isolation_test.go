@@ -0,0 +1,95 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNetworkStatusFetcher struct {
+	status *types.NetworkStatusResponse
+	err    *fetcher.Error
+}
+
+func (m *mockNetworkStatusFetcher) NetworkStatus(
+	ctx context.Context,
+	network *types.NetworkIdentifier,
+	metadata map[string]interface{},
+) (*types.NetworkStatusResponse, *fetcher.Error) {
+	return m.status, m.err
+}
+
+func TestVerifyOfflineIsolation(t *testing.T) {
+	network := &types.NetworkIdentifier{
+		Blockchain: "bitcoin",
+		Network:    "mainnet",
+	}
+
+	var tests = map[string]struct {
+		offline  *mockNetworkStatusFetcher
+		canceled bool
+
+		err error
+	}{
+		"isolated": {
+			offline: &mockNetworkStatusFetcher{
+				err: &fetcher.Error{Err: errors.New("unavailable offline")},
+			},
+		},
+		"serves network status": {
+			offline: &mockNetworkStatusFetcher{
+				status: &types.NetworkStatusResponse{
+					CurrentBlockIdentifier: &types.BlockIdentifier{
+						Index: 10,
+						Hash:  "block 10",
+					},
+				},
+			},
+			err: results.ErrOfflineNotIsolated,
+		},
+		"canceled": {
+			offline: &mockNetworkStatusFetcher{
+				err: &fetcher.Error{Err: context.Canceled},
+			},
+			canceled: true,
+			err:      context.Canceled,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if test.canceled {
+				cancel()
+			}
+
+			err := VerifyOfflineIsolation(ctx, test.offline, network)
+			if test.err != nil {
+				assert.True(t, errors.Is(err, test.err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
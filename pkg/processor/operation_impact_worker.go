@@ -0,0 +1,120 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*OperationImpactWorker)(nil)
+
+// OperationImpactWorker records the number of operations of
+// each operation type, the distinct accounts they touch, and
+// the value they move.
+type OperationImpactWorker struct {
+	asserter *asserter.Asserter
+}
+
+// NewOperationImpactWorker returns a new *OperationImpactWorker.
+func NewOperationImpactWorker(asserter *asserter.Asserter) *OperationImpactWorker {
+	return &OperationImpactWorker{asserter: asserter}
+}
+
+// OperationImpacts returns the *results.OperationImpact
+// of each operation in block. The value of unsuccessful
+// operations is not counted because it does not move.
+func OperationImpacts(
+	asserter *asserter.Asserter,
+	block *types.Block,
+) ([]*results.OperationImpact, error) {
+	impacts := []*results.OperationImpact{}
+	for _, tx := range block.Transactions {
+		for _, op := range tx.Operations {
+			impact := &results.OperationImpact{Type: op.Type}
+			if op.Account != nil {
+				impact.Account = types.Hash(op.Account)
+			}
+			impacts = append(impacts, impact)
+
+			if op.Amount == nil {
+				continue
+			}
+
+			successful, err := asserter.OperationSuccessful(op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to check operation status", err)
+			}
+
+			if !successful {
+				continue
+			}
+
+			value, err := types.AmountValue(op.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+			}
+
+			impact.Currency = op.Amount.Currency
+			impact.Amount = value
+		}
+	}
+
+	return impacts, nil
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *OperationImpactWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	impacts, err := OperationImpacts(w.asserter, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		results.RecordOperationImpacts(impacts)
+		return nil
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *OperationImpactWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	impacts, err := OperationImpacts(w.asserter, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		results.RemoveOperationImpacts(impacts)
+		return nil
+	}, nil
+}
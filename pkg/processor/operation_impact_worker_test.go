@@ -0,0 +1,105 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationImpacts(t *testing.T) {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer", "Fee"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+			{
+				Status:     "Failure",
+				Successful: false,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	account := &types.AccountIdentifier{Address: "addr"}
+	block := &types.Block{
+		Transactions: []*types.Transaction{
+			{
+				Operations: []*types.Operation{
+					{
+						Type:    "Transfer",
+						Status:  types.String("Success"),
+						Account: account,
+						Amount:  &types.Amount{Value: "-10", Currency: currency},
+					},
+					{
+						Type:    "Transfer",
+						Status:  types.String("Failure"),
+						Account: account,
+						Amount:  &types.Amount{Value: "10", Currency: currency},
+					},
+					{
+						Type:   "Fee",
+						Status: types.String("Success"),
+					},
+				},
+			},
+		},
+	}
+
+	impacts, err := OperationImpacts(a, block)
+	assert.NoError(t, err)
+	assert.Equal(t, []*results.OperationImpact{
+		{
+			Type:     "Transfer",
+			Account:  types.Hash(account),
+			Currency: currency,
+			Amount:   big.NewInt(-10),
+		},
+		{
+			Type:    "Transfer",
+			Account: types.Hash(account),
+		},
+		{
+			Type: "Fee",
+		},
+	}, impacts)
+
+	block.Transactions[0].Operations[0].Amount.Value = "invalid"
+	_, err = OperationImpacts(a, block)
+	assert.Error(t, err)
+}
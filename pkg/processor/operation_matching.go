@@ -0,0 +1,233 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// OperationMatcher compares the observed operations of a
+// broadcast transaction to its intent, applying any
+// configured OperationMatchingRules.
+type OperationMatcher struct {
+	parser *parser.Parser
+	rules  map[string]*configuration.OperationMatchingRule
+}
+
+// NewOperationMatcher returns a new *OperationMatcher.
+func NewOperationMatcher(
+	parser *parser.Parser,
+	rules []*configuration.OperationMatchingRule,
+) *OperationMatcher {
+	ruleMap := map[string]*configuration.OperationMatchingRule{}
+	for _, rule := range rules {
+		ruleMap[rule.Type] = rule
+	}
+
+	return &OperationMatcher{
+		parser: parser,
+		rules:  ruleMap,
+	}
+}
+
+// MatchOperation returns an error if an observed operation
+// differs from the intended operation according to rule. If
+// rule is nil, the operations must be equal.
+func MatchOperation(
+	rule *configuration.OperationMatchingRule,
+	intent *types.Operation,
+	observed *types.Operation,
+) error {
+	if rule == nil {
+		return parser.ExpectedOperation(intent, observed)
+	}
+
+	if !matchAccount(rule.Account, intent.Account, observed.Account) {
+		return fmt.Errorf(
+			"%w: expected %s but got %s",
+			parser.ErrExpectedOperationAccountMismatch,
+			types.PrettyPrintStruct(intent.Account),
+			types.PrettyPrintStruct(observed.Account),
+		)
+	}
+
+	if !matchAmount(rule.Amount, intent.Amount, observed.Amount) {
+		return fmt.Errorf(
+			"%w: expected %s but got %s",
+			parser.ErrExpectedOperationAmountMismatch,
+			types.PrettyPrintStruct(intent.Amount),
+			types.PrettyPrintStruct(observed.Amount),
+		)
+	}
+
+	if intent.Type != observed.Type && !utils.ContainsString(rule.ObservedTypes, observed.Type) {
+		return fmt.Errorf(
+			"%w: expected %s but got %s",
+			parser.ErrExpectedOperationTypeMismatch,
+			intent.Type,
+			observed.Type,
+		)
+	}
+
+	return nil
+}
+
+func matchAccount(
+	match configuration.AccountMatch,
+	intent *types.AccountIdentifier,
+	observed *types.AccountIdentifier,
+) bool {
+	switch match {
+	case configuration.AnyAccountMatch:
+		return true
+	case configuration.AddressAccountMatch:
+		if intent == nil || observed == nil {
+			return intent == observed
+		}
+
+		return intent.Address == observed.Address
+	default:
+		return types.Hash(intent) == types.Hash(observed)
+	}
+}
+
+func matchAmount(
+	match configuration.AmountMatch,
+	intent *types.Amount,
+	observed *types.Amount,
+) bool {
+	switch match {
+	case configuration.AnyAmountMatch:
+		return true
+	case configuration.SignAmountMatch:
+		if intent == nil || observed == nil {
+			return intent == observed
+		}
+
+		if types.Hash(intent.Currency) != types.Hash(observed.Currency) {
+			return false
+		}
+
+		intentValue, err := types.BigInt(intent.Value)
+		if err != nil {
+			return false
+		}
+
+		observedValue, err := types.BigInt(observed.Value)
+		if err != nil {
+			return false
+		}
+
+		return intentValue.Sign() == observedValue.Sign()
+	default:
+		return types.Hash(intent) == types.Hash(observed)
+	}
+}
+
+// ExpectedOperations returns an error if the intended operations
+// are not all observed (ignoring those matched by an Optional rule).
+// Extra observed operations are ignored. Exact matches are made
+// before matches relying on rules so that a relaxed rule does not
+// claim an observed operation intended for another operation. If
+// no rules are configured, this is equivalent to
+// parser.ExpectedOperations.
+func (m *OperationMatcher) ExpectedOperations(
+	intent []*types.Operation,
+	observed []*types.Operation,
+	confirmSuccess bool,
+) error {
+	if len(m.rules) == 0 {
+		return m.parser.ExpectedOperations(intent, observed, false, confirmSuccess)
+	}
+
+	matches := make(map[int]struct{})
+	observedMatches := make(map[int]struct{})
+	failedMatches := []*types.Operation{}
+	for _, relaxed := range []bool{false, true} {
+		for j, obs := range observed {
+			if _, exists := observedMatches[j]; exists {
+				continue
+			}
+
+			for i, in := range intent {
+				if _, exists := matches[i]; exists {
+					continue
+				}
+
+				var rule *configuration.OperationMatchingRule
+				if relaxed {
+					rule = m.rules[in.Type]
+					if rule == nil {
+						continue
+					}
+				}
+
+				if err := MatchOperation(rule, in, obs); err != nil {
+					continue
+				}
+
+				if confirmSuccess {
+					obsSuccess, err := m.parser.Asserter.OperationSuccessful(obs)
+					if err != nil {
+						return fmt.Errorf("%w: unable to check operation success", err)
+					}
+
+					if !obsSuccess {
+						failedMatches = append(failedMatches, obs)
+						continue
+					}
+				}
+
+				matches[i] = struct{}{}
+				observedMatches[j] = struct{}{}
+				break
+			}
+		}
+	}
+
+	missingIntent := []int{}
+	for i, in := range intent {
+		if _, exists := matches[i]; exists {
+			continue
+		}
+
+		if rule, ok := m.rules[in.Type]; ok && rule.Optional {
+			continue
+		}
+
+		missingIntent = append(missingIntent, i)
+	}
+
+	if len(missingIntent) == 0 {
+		return nil
+	}
+
+	errString := fmt.Sprintf("could not intent match %v", missingIntent)
+	if len(failedMatches) > 0 {
+		errString += fmt.Sprintf(
+			": found matching ops with unsuccessful status %s",
+			types.PrettyPrintStruct(failedMatches),
+		)
+	}
+
+	return errors.New(errString)
+}
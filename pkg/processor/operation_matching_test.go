@@ -0,0 +1,161 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	matchingCurrency = &types.Currency{Symbol: "ATOM", Decimals: 6}
+)
+
+func matchingOp(opType string, address string, value string) *types.Operation {
+	return &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{},
+		Type:                opType,
+		Status:              types.String("SUCCESS"),
+		Account:             &types.AccountIdentifier{Address: address},
+		Amount:              &types.Amount{Value: value, Currency: matchingCurrency},
+	}
+}
+
+func TestOperationMatcher(t *testing.T) {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{Blockchain: "cosmos", Network: "mainnet"},
+		&types.BlockIdentifier{Index: 0, Hash: "block 0"},
+		[]string{"delegate", "claim_rewards", "reward", "burn"},
+		[]*types.OperationStatus{
+			{Status: "SUCCESS", Successful: true},
+			{Status: "FAILURE", Successful: false},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	var tests = map[string]struct {
+		rules    []*configuration.OperationMatchingRule
+		intent   []*types.Operation
+		observed []*types.Operation
+		err      bool
+	}{
+		"exact without rules": {
+			intent:   []*types.Operation{matchingOp("delegate", "a", "-10")},
+			observed: []*types.Operation{matchingOp("delegate", "a", "-10")},
+		},
+		"mismatch without rules": {
+			intent:   []*types.Operation{matchingOp("claim_rewards", "a", "1")},
+			observed: []*types.Operation{matchingOp("claim_rewards", "a", "5")},
+			err:      true,
+		},
+		"sign amount and observed type": {
+			rules: []*configuration.OperationMatchingRule{
+				{
+					Type:          "claim_rewards",
+					ObservedTypes: []string{"reward"},
+					Amount:        configuration.SignAmountMatch,
+				},
+			},
+			intent:   []*types.Operation{matchingOp("claim_rewards", "a", "1")},
+			observed: []*types.Operation{matchingOp("reward", "a", "5")},
+		},
+		"sign mismatch": {
+			rules: []*configuration.OperationMatchingRule{
+				{Type: "claim_rewards", Amount: configuration.SignAmountMatch},
+			},
+			intent:   []*types.Operation{matchingOp("claim_rewards", "a", "1")},
+			observed: []*types.Operation{matchingOp("claim_rewards", "a", "-5")},
+			err:      true,
+		},
+		"address account": {
+			rules: []*configuration.OperationMatchingRule{
+				{Type: "delegate", Account: configuration.AddressAccountMatch},
+			},
+			intent: []*types.Operation{matchingOp("delegate", "a", "-10")},
+			observed: []*types.Operation{
+				{
+					Type:   "delegate",
+					Status: types.String("SUCCESS"),
+					Account: &types.AccountIdentifier{
+						Address:    "a",
+						SubAccount: &types.SubAccountIdentifier{Address: "staked"},
+					},
+					Amount: &types.Amount{Value: "-10", Currency: matchingCurrency},
+				},
+			},
+		},
+		"exact matches first": {
+			rules: []*configuration.OperationMatchingRule{
+				{Type: "delegate", Account: configuration.AnyAccountMatch},
+			},
+			intent: []*types.Operation{
+				matchingOp("delegate", "b", "-10"),
+				matchingOp("delegate", "a", "-10"),
+			},
+			observed: []*types.Operation{
+				matchingOp("delegate", "a", "-10"),
+				matchingOp("delegate", "c", "-10"),
+			},
+		},
+		"optional": {
+			rules: []*configuration.OperationMatchingRule{
+				{Type: "burn", Optional: true},
+			},
+			intent: []*types.Operation{
+				matchingOp("delegate", "a", "-10"),
+				matchingOp("burn", "a", "-1"),
+			},
+			observed: []*types.Operation{matchingOp("delegate", "a", "-10")},
+		},
+		"unsuccessful": {
+			rules: []*configuration.OperationMatchingRule{
+				{Type: "delegate", Amount: configuration.AnyAmountMatch},
+			},
+			intent: []*types.Operation{matchingOp("delegate", "a", "-10")},
+			observed: []*types.Operation{
+				{
+					Type:    "delegate",
+					Status:  types.String("FAILURE"),
+					Account: &types.AccountIdentifier{Address: "a"},
+					Amount:  &types.Amount{Value: "-10", Currency: matchingCurrency},
+				},
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matcher := NewOperationMatcher(parser.New(a, nil, nil), test.rules)
+			err := matcher.ExpectedOperations(test.intent, test.observed, true)
+			if test.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
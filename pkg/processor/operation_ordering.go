@@ -0,0 +1,162 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*OperationOrderingWorker)(nil)
+
+// OperationOrderingWorker ensures the operations in each
+// added block can be applied in a deterministic order.
+type OperationOrderingWorker struct{}
+
+// NewOperationOrderingWorker returns a new *OperationOrderingWorker.
+func NewOperationOrderingWorker() *OperationOrderingWorker {
+	return &OperationOrderingWorker{}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *OperationOrderingWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, AssertOperationOrdering(block)
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *OperationOrderingWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+// orderingViolation returns an ErrOperationOrdering error
+// with the full context of the offending operation.
+func orderingViolation(
+	block *types.Block,
+	position int,
+	tx *types.Transaction,
+	op *types.Operation,
+	reason string,
+) error {
+	account := "none"
+	if op.Account != nil {
+		account = types.AccountString(op.Account)
+	}
+
+	return fmt.Errorf(
+		"%w: %s (operation %d for account %s in transaction %s at position %d in block %d:%s)",
+		results.ErrOperationOrdering,
+		reason,
+		op.OperationIdentifier.Index,
+		account,
+		tx.TransactionIdentifier.Hash,
+		position,
+		block.BlockIdentifier.Index,
+		block.BlockIdentifier.Hash,
+	)
+}
+
+// AssertOperationOrdering ensures the operations in a block
+// can be applied in a single, deterministic order (by transaction
+// position and then by operation index). In particular:
+// * transaction hashes are unique within the block
+// * operation indices are strictly increasing within a transaction
+// * related_operations only reference earlier operations
+// * coins created in the block are not spent before they are created
+func AssertOperationOrdering(block *types.Block) error { // nolint:gocognit
+	seenTxs := map[string]int{}
+	spentCoins := map[string]struct{}{}
+	for position, tx := range block.Transactions {
+		if prior, ok := seenTxs[tx.TransactionIdentifier.Hash]; ok {
+			return fmt.Errorf(
+				"%w: transaction %s appears at positions %d and %d in block %d:%s",
+				results.ErrOperationOrdering,
+				tx.TransactionIdentifier.Hash,
+				prior,
+				position,
+				block.BlockIdentifier.Index,
+				block.BlockIdentifier.Hash,
+			)
+		}
+		seenTxs[tx.TransactionIdentifier.Hash] = position
+
+		seenOps := map[int64]struct{}{}
+		lastIndex := int64(-1)
+		for _, op := range tx.Operations {
+			index := op.OperationIdentifier.Index
+			if index <= lastIndex {
+				return orderingViolation(
+					block,
+					position,
+					tx,
+					op,
+					fmt.Sprintf("operation index %d follows operation index %d", index, lastIndex),
+				)
+			}
+			lastIndex = index
+
+			for _, related := range op.RelatedOperations {
+				if _, ok := seenOps[related.Index]; !ok {
+					return orderingViolation(
+						block,
+						position,
+						tx,
+						op,
+						fmt.Sprintf("related operation %d is not an earlier operation", related.Index),
+					)
+				}
+			}
+			seenOps[index] = struct{}{}
+
+			if op.CoinChange == nil {
+				continue
+			}
+
+			coin := op.CoinChange.CoinIdentifier.Identifier
+			switch op.CoinChange.CoinAction {
+			case types.CoinSpent:
+				spentCoins[coin] = struct{}{}
+			case types.CoinCreated:
+				if _, ok := spentCoins[coin]; ok {
+					return orderingViolation(
+						block,
+						position,
+						tx,
+						op,
+						fmt.Sprintf("coin %s is created after it is spent", coin),
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func orderingOp(
+	index int64,
+	related []int64,
+	coinAction types.CoinAction,
+) *types.Operation {
+	op := &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{Index: index},
+		Account:             &types.AccountIdentifier{Address: "addr1"},
+	}
+
+	for _, r := range related {
+		op.RelatedOperations = append(op.RelatedOperations, &types.OperationIdentifier{Index: r})
+	}
+
+	if len(coinAction) > 0 {
+		op.CoinChange = &types.CoinChange{
+			CoinIdentifier: &types.CoinIdentifier{Identifier: "coin1"},
+			CoinAction:     coinAction,
+		}
+	}
+
+	return op
+}
+
+func orderingTx(hash string, ops ...*types.Operation) *types.Transaction {
+	return &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+		Operations:            ops,
+	}
+}
+
+func TestAssertOperationOrdering(t *testing.T) {
+	var tests = map[string]struct {
+		transactions []*types.Transaction
+
+		err bool
+	}{
+		"valid": {
+			transactions: []*types.Transaction{
+				orderingTx(
+					"tx1",
+					orderingOp(0, nil, types.CoinCreated),
+					orderingOp(1, []int64{0}, ""),
+				),
+				orderingTx(
+					"tx2",
+					orderingOp(0, nil, types.CoinSpent),
+				),
+			},
+		},
+		"no transactions": {},
+		"duplicate transaction": {
+			transactions: []*types.Transaction{
+				orderingTx("tx1", orderingOp(0, nil, "")),
+				orderingTx("tx1", orderingOp(0, nil, "")),
+			},
+			err: true,
+		},
+		"decreasing operation index": {
+			transactions: []*types.Transaction{
+				orderingTx(
+					"tx1",
+					orderingOp(1, nil, ""),
+					orderingOp(0, nil, ""),
+				),
+			},
+			err: true,
+		},
+		"related operation later": {
+			transactions: []*types.Transaction{
+				orderingTx(
+					"tx1",
+					orderingOp(0, []int64{1}, ""),
+					orderingOp(1, nil, ""),
+				),
+			},
+			err: true,
+		},
+		"related operation missing": {
+			transactions: []*types.Transaction{
+				orderingTx(
+					"tx1",
+					orderingOp(0, nil, ""),
+					orderingOp(2, []int64{1}, ""),
+				),
+			},
+			err: true,
+		},
+		"coin spent before created": {
+			transactions: []*types.Transaction{
+				orderingTx("tx1", orderingOp(0, nil, types.CoinSpent)),
+				orderingTx("tx2", orderingOp(0, nil, types.CoinCreated)),
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := AssertOperationOrdering(&types.Block{
+				BlockIdentifier: &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+				Transactions:    test.transactions,
+			})
+			if test.err {
+				assert.ErrorIs(t, err, results.ErrOperationOrdering)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+var _ http.RoundTripper = (*OperationTypeAliasTransport)(nil)
+
+// OperationTypeAliasTransport is an http.RoundTripper that
+// renames aliased (old) operation types in /block and
+// /block/transaction responses before they are asserted, so
+// that blocks produced by an earlier implementation version
+// validate against the current network options.
+type OperationTypeAliasTransport struct {
+	base    http.RoundTripper
+	aliases map[string]string
+}
+
+// NewOperationTypeAliasTransport returns a new
+// *OperationTypeAliasTransport. aliases maps old
+// operation types to new operation types.
+func NewOperationTypeAliasTransport(
+	base http.RoundTripper,
+	aliases map[string]string,
+) *OperationTypeAliasTransport {
+	return &OperationTypeAliasTransport{
+		base:    base,
+		aliases: aliases,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction and renames
+// aliased operation types in any successful block response.
+func (t *OperationTypeAliasTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	return rewriteBlockResponse(req, resp, func(path string, body []byte) ([]byte, error) {
+		return AliasOperationTypes(path, body, t.aliases)
+	})
+}
+
+// rewriteBlockResponse replaces the body of resp (if it is a
+// successful /block or /block/transaction response) with the
+// result of rewrite.
+func rewriteBlockResponse(
+	req *http.Request,
+	resp *http.Response,
+	rewrite func(path string, body []byte) ([]byte, error),
+) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	if !strings.HasSuffix(req.URL.Path, "/block") &&
+		!strings.HasSuffix(req.URL.Path, "/block/transaction") {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rewritten, err := rewrite(req.URL.Path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(rewritten))
+	resp.ContentLength = int64(len(rewritten))
+	return resp, nil
+}
+
+// rewriteTransactions calls rewrite on each transaction in a
+// serialized /block or /block/transaction response. If rewrite
+// does not modify any transaction, body is returned unmodified.
+func rewriteTransactions(
+	path string,
+	body []byte,
+	rewrite func(*types.Transaction) (bool, error),
+) ([]byte, error) {
+	var response interface{}
+	rewritten := false
+	if strings.HasSuffix(path, "/block/transaction") {
+		var txResponse types.BlockTransactionResponse
+		if err := json.Unmarshal(body, &txResponse); err != nil {
+			return nil, fmt.Errorf("%w: unable to unmarshal block transaction response", err)
+		}
+
+		modified, err := rewrite(txResponse.Transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		rewritten = modified
+		response = &txResponse
+	} else {
+		var blockResponse types.BlockResponse
+		if err := json.Unmarshal(body, &blockResponse); err != nil {
+			return nil, fmt.Errorf("%w: unable to unmarshal block response", err)
+		}
+
+		if blockResponse.Block != nil {
+			for _, tx := range blockResponse.Block.Transactions {
+				modified, err := rewrite(tx)
+				if err != nil {
+					return nil, err
+				}
+
+				if modified {
+					rewritten = true
+				}
+			}
+		}
+		response = &blockResponse
+	}
+
+	if !rewritten {
+		return body, nil
+	}
+
+	return json.Marshal(response)
+}
+
+// aliasTransaction renames the aliased operation types in tx
+// and returns a boolean indicating if any were renamed.
+func aliasTransaction(tx *types.Transaction, aliases map[string]string) bool {
+	if tx == nil {
+		return false
+	}
+
+	renamed := false
+	for _, op := range tx.Operations {
+		newType, ok := aliases[op.Type]
+		if !ok {
+			continue
+		}
+
+		results.RecordOperationTypeAlias(op.Type)
+		op.Type = newType
+		renamed = true
+	}
+
+	return renamed
+}
+
+// AliasOperationTypes renames the aliased operation types in a
+// serialized /block or /block/transaction response. If no
+// operation types are renamed, body is returned unmodified.
+func AliasOperationTypes(
+	path string,
+	body []byte,
+	aliases map[string]string,
+) ([]byte, error) {
+	return rewriteTransactions(path, body, func(tx *types.Transaction) (bool, error) {
+		return aliasTransaction(tx, aliases), nil
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTypeAliasTransport(t *testing.T) {
+	aliases := map[string]string{"ALIAS_TEST_TRANSFER": "Transfer"}
+	blockResponse := &types.BlockResponse{
+		Block: &types.Block{
+			BlockIdentifier:       &types.BlockIdentifier{Index: 1, Hash: "block 1"},
+			ParentBlockIdentifier: &types.BlockIdentifier{Index: 0, Hash: "block 0"},
+			Transactions: []*types.Transaction{
+				{
+					TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+					Operations: []*types.Operation{
+						{
+							OperationIdentifier: &types.OperationIdentifier{Index: 0},
+							Type:                "ALIAS_TEST_TRANSFER",
+						},
+						{
+							OperationIdentifier: &types.OperationIdentifier{Index: 1},
+							Type:                "Fee",
+						},
+					},
+				},
+			},
+		},
+	}
+	body, err := json.Marshal(blockResponse)
+	assert.NoError(t, err)
+
+	transport := NewOperationTypeAliasTransport(roundTripFunc(
+		func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			}, nil
+		},
+	), aliases)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:8080/block", nil)
+	assert.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	assert.NoError(t, err)
+
+	var aliased types.BlockResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&aliased))
+	ops := aliased.Block.Transactions[0].Operations
+	assert.Equal(t, "Transfer", ops[0].Type)
+	assert.Equal(t, "Fee", ops[1].Type)
+	assert.Equal(t, int64(1), results.OperationTypeAliasUsage()["ALIAS_TEST_TRANSFER"])
+
+	// Responses without aliased operation types are not modified
+	unmodified, err := AliasOperationTypes("/block", body, map[string]string{"Other": "Fee"})
+	assert.NoError(t, err)
+	assert.Equal(t, body, unmodified)
+
+	// Other endpoints are not inspected
+	req, err = http.NewRequest(http.MethodPost, "http://localhost:8080/network/status", nil)
+	assert.NoError(t, err)
+	resp, err = transport.RoundTrip(req)
+	assert.NoError(t, err)
+	raw, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, raw)
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/rosetta-sdk-go/client"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// MissingOtherTransaction is a transaction listed in
+// the other_transactions of a /block response that
+// could not be fetched from /block/transaction.
+type MissingOtherTransaction struct {
+	TransactionIdentifier *types.TransactionIdentifier
+	Err                   error
+}
+
+// FindMissingOtherTransactions fetches the block at index
+// without populating other_transactions and attempts to fetch
+// each listed transaction individually (without retries). Any
+// transaction that can't be fetched is returned.
+//
+// The fetcher merges other_transactions into the block before
+// the syncer sees it, so when any one of them fails the only
+// error surfaced is a generic block fetch failure. This lookup
+// is used to report exactly which hashes the implementation
+// lists but does not serve.
+func FindMissingOtherTransactions(
+	ctx context.Context,
+	rosettaClient *client.APIClient,
+	network *types.NetworkIdentifier,
+	index int64,
+) ([]*MissingOtherTransaction, error) {
+	blockResponse, clientErr, err := rosettaClient.BlockAPI.Block(ctx, &types.BlockRequest{
+		NetworkIdentifier: network,
+		BlockIdentifier: &types.PartialBlockIdentifier{
+			Index: &index,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to fetch block %d %s",
+			err,
+			index,
+			types.PrintStruct(clientErr),
+		)
+	}
+
+	if blockResponse.Block == nil {
+		return nil, nil
+	}
+
+	missing := []*MissingOtherTransaction{}
+	for _, txID := range blockResponse.OtherTransactions {
+		_, clientErr, err := rosettaClient.BlockAPI.BlockTransaction(
+			ctx,
+			&types.BlockTransactionRequest{
+				NetworkIdentifier:     network,
+				BlockIdentifier:       blockResponse.Block.BlockIdentifier,
+				TransactionIdentifier: txID,
+			},
+		)
+		if err == nil {
+			continue
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+
+		if clientErr != nil {
+			err = fmt.Errorf("%w: %s", err, types.PrintStruct(clientErr))
+		}
+
+		missing = append(missing, &MissingOtherTransaction{
+			TransactionIdentifier: txID,
+			Err:                   err,
+		})
+	}
+
+	return missing, nil
+}
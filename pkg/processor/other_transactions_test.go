@@ -0,0 +1,151 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingOtherTransactions(t *testing.T) {
+	network := &types.NetworkIdentifier{
+		Blockchain: "bitcoin",
+		Network:    "mainnet",
+	}
+
+	var tests = map[string]struct {
+		block             *types.Block
+		otherTransactions []*types.TransactionIdentifier
+
+		missing []string
+		err     bool
+	}{
+		"all served": {
+			block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{Hash: "block 1", Index: 1},
+			},
+			otherTransactions: []*types.TransactionIdentifier{{Hash: "tx 1"}, {Hash: "tx 2"}},
+			missing:           []string{},
+		},
+		"some missing": {
+			block: &types.Block{
+				BlockIdentifier: &types.BlockIdentifier{Hash: "block 1", Index: 1},
+			},
+			otherTransactions: []*types.TransactionIdentifier{
+				{Hash: "tx 1"},
+				{Hash: "missing 1"},
+				{Hash: "tx 2"},
+				{Hash: "missing 2"},
+			},
+			missing: []string{"missing 1", "missing 2"},
+		},
+		"omitted block": {},
+		"block fetch failed": {
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/block":
+					var request types.BlockRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+					assert.Equal(t, int64(1), *request.BlockIdentifier.Index)
+
+					if test.err {
+						w.WriteHeader(http.StatusInternalServerError)
+						assert.NoError(t, json.NewEncoder(w).Encode(&types.Error{
+							Code:    1,
+							Message: "block unavailable",
+						}))
+						return
+					}
+
+					if test.block != nil {
+						test.block.ParentBlockIdentifier = &types.BlockIdentifier{
+							Hash:  "block 0",
+							Index: 0,
+						}
+						test.block.Timestamp = asserter.MinUnixEpoch + 1
+					}
+
+					assert.NoError(t, json.NewEncoder(w).Encode(&types.BlockResponse{
+						Block:             test.block,
+						OtherTransactions: test.otherTransactions,
+					}))
+				case "/block/transaction":
+					var request types.BlockTransactionRequest
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+					assert.Equal(t, test.block.BlockIdentifier, request.BlockIdentifier)
+
+					hash := request.TransactionIdentifier.Hash
+					if hash == "missing 1" || hash == "missing 2" {
+						w.WriteHeader(http.StatusInternalServerError)
+						assert.NoError(t, json.NewEncoder(w).Encode(&types.Error{
+							Code:    2,
+							Message: "transaction not found",
+						}))
+						return
+					}
+
+					assert.NoError(t, json.NewEncoder(w).Encode(&types.BlockTransactionResponse{
+						Transaction: &types.Transaction{
+							TransactionIdentifier: request.TransactionIdentifier,
+							Operations:            []*types.Operation{},
+						},
+					}))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			missing, err := FindMissingOtherTransactions(
+				context.Background(),
+				NewClient(server.URL, time.Minute, 1, nil),
+				network,
+				1,
+			)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			if test.missing == nil {
+				assert.Nil(t, missing)
+				return
+			}
+
+			hashes := make([]string, len(missing))
+			for i, m := range missing {
+				hashes[i] = m.TransactionIdentifier.Hash
+				assert.Contains(t, m.Err.Error(), "transaction not found")
+			}
+			assert.Equal(t, test.missing, hashes)
+		})
+	}
+}
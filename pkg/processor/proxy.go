@@ -0,0 +1,104 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// Proxy routes requests through a configured
+// HTTP or SOCKS5 proxy.
+type Proxy struct {
+	url     *url.URL
+	noProxy []string
+}
+
+// NewProxy returns the *Proxy of config (nil
+// if config is nil).
+func NewProxy(config *configuration.ProxyConfiguration) (*Proxy, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	resolved, err := resolveEnvPlaceholders(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to resolve proxy url", err)
+	}
+
+	proxyURL, err := url.Parse(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse proxy url", err)
+	}
+
+	if len(proxyURL.Host) == 0 {
+		return nil, fmt.Errorf("proxy url %s is missing a host", proxyURL.Redacted())
+	}
+
+	noProxy := make([]string, len(config.NoProxy))
+	for i, host := range config.NoProxy {
+		noProxy[i] = strings.ToLower(strings.TrimSpace(host))
+	}
+
+	return &Proxy{
+		url:     proxyURL,
+		noProxy: noProxy,
+	}, nil
+}
+
+// URL returns the URL of the proxy to use for req (nil
+// if req should be made directly). If p is nil, the proxy
+// is determined by the environment (HTTP_PROXY, HTTPS_PROXY,
+// and NO_PROXY).
+func (p *Proxy) URL(req *http.Request) (*url.URL, error) {
+	if p == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+
+	host := strings.ToLower(req.URL.Hostname())
+	for _, noProxy := range p.noProxy {
+		if host == noProxy ||
+			(strings.HasPrefix(noProxy, ".") &&
+				(strings.HasSuffix(host, noProxy) || host == noProxy[1:])) {
+			return nil, nil
+		}
+	}
+
+	return p.url, nil
+}
+
+// String returns the URL of the proxy (with any
+// password redacted).
+func (p *Proxy) String() string {
+	return p.url.Redacted()
+}
+
+// NewHTTPClient returns an *http.Client for requests made
+// to services other than the Rosetta implementation (ex:
+// remote signers or explorers), routed through proxy.
+func NewHTTPClient(timeout time.Duration, proxy *Proxy) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy.URL
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// endpointLimit is the *TokenBucket of
+// the endpoints matching pattern.
+type endpointLimit struct {
+	pattern string
+	bucket  *TokenBucket
+}
+
+// matches returns a boolean indicating if the
+// request to path matches the pattern.
+func (l *endpointLimit) matches(path string) bool {
+	if prefix := strings.TrimSuffix(l.pattern, "*"); prefix != l.pattern {
+		return strings.Contains(path, prefix)
+	}
+
+	return strings.HasSuffix(path, l.pattern)
+}
+
+// RateLimits are the token buckets that limit the
+// rate of requests made to a Rosetta implementation.
+type RateLimits struct {
+	global *TokenBucket
+
+	// endpoints are sorted by descending
+	// pattern length (most specific first).
+	endpoints []*endpointLimit
+}
+
+// NewRateLimits returns the *RateLimits of config
+// (nil if config is nil).
+func NewRateLimits(config *configuration.RateLimitConfiguration) *RateLimits {
+	if config == nil {
+		return nil
+	}
+
+	limits := &RateLimits{}
+	if config.RequestsPerSecond > 0 {
+		limits.global = NewTokenBucket(config.RequestsPerSecond, config.Burst)
+	}
+
+	for pattern, perSecond := range config.Endpoints {
+		limits.endpoints = append(limits.endpoints, &endpointLimit{
+			pattern: pattern,
+			bucket:  NewTokenBucket(perSecond, config.Burst),
+		})
+	}
+
+	sort.Slice(limits.endpoints, func(i, j int) bool {
+		if len(limits.endpoints[i].pattern) != len(limits.endpoints[j].pattern) {
+			return len(limits.endpoints[i].pattern) > len(limits.endpoints[j].pattern)
+		}
+
+		return limits.endpoints[i].pattern < limits.endpoints[j].pattern
+	})
+
+	return limits
+}
+
+// Wait blocks until a request to path is allowed
+// or the context is canceled.
+func (l *RateLimits) Wait(ctx context.Context, path string) error {
+	for _, endpoint := range l.endpoints {
+		if !endpoint.matches(path) {
+			continue
+		}
+
+		if err := endpoint.bucket.Wait(ctx); err != nil {
+			return err
+		}
+
+		break
+	}
+
+	if l.global == nil {
+		return nil
+	}
+
+	return l.global.Wait(ctx)
+}
+
+var _ http.RoundTripper = (*RateLimitTransport)(nil)
+
+// RateLimitTransport is an http.RoundTripper that delays
+// requests so that they don't exceed the configured rate
+// limits (globally and by endpoint).
+type RateLimitTransport struct {
+	base   http.RoundTripper
+	limits *RateLimits
+}
+
+// NewRateLimitTransport returns a new *RateLimitTransport.
+func NewRateLimitTransport(base http.RoundTripper, limits *RateLimits) *RateLimitTransport {
+	return &RateLimitTransport{
+		base:   base,
+		limits: limits,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction
+// once it is allowed by the rate limits.
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limits.Wait(req.Context(), req.URL.Path); err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimits(t *testing.T) {
+	assert.Nil(t, NewRateLimits(nil))
+
+	limits := NewRateLimits(&configuration.RateLimitConfiguration{
+		Burst: 1,
+		Endpoints: map[string]float64{
+			"/construction/*":        1,
+			"/construction/payloads": 2,
+			"/block":                 5,
+		},
+	})
+	assert.Nil(t, limits.global)
+	assert.Len(t, limits.endpoints, 3)
+	assert.Equal(t, "/construction/payloads", limits.endpoints[0].pattern)
+	assert.Equal(t, "/construction/*", limits.endpoints[1].pattern)
+	assert.Equal(t, "/block", limits.endpoints[2].pattern)
+
+	assert.True(t, limits.endpoints[1].matches("/construction/parse"))
+	assert.True(t, limits.endpoints[1].matches("/rosetta/construction/parse"))
+	assert.False(t, limits.endpoints[1].matches("/block"))
+	assert.True(t, limits.endpoints[2].matches("/block"))
+	assert.False(t, limits.endpoints[2].matches("/block/transaction"))
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	limits := NewRateLimits(&configuration.RateLimitConfiguration{
+		RequestsPerSecond: 100,
+		Burst:             1,
+		Endpoints: map[string]float64{
+			"/block": 20,
+		},
+	})
+
+	requests := 0
+	transport := NewRateLimitTransport(roundTripFunc(
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		},
+	), limits)
+
+	roundTrip := func(path string) {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:8080"+path, nil)
+		assert.NoError(t, err)
+
+		_, err = transport.RoundTrip(req)
+		assert.NoError(t, err)
+	}
+
+	// Other endpoints are only limited globally
+	start := time.Now()
+	roundTrip("/network/status")
+	roundTrip("/block")
+	assert.Less(t, int64(time.Since(start)), int64(30*time.Millisecond))
+
+	// The endpoint limit is stricter than the global limit
+	roundTrip("/block")
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+	assert.Equal(t, 3, requests)
+}
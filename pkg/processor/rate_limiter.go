@@ -0,0 +1,178 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces out events so that no more
+// than a target number occur per second.
+type RateLimiter struct {
+	interval time.Duration
+
+	lock sync.Mutex
+	next time.Time
+}
+
+// NewRateLimiter returns a new *RateLimiter that
+// allows perSecond events per second.
+func NewRateLimiter(perSecond float64) *RateLimiter {
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+	}
+}
+
+// Wait blocks until the next event is allowed
+// or the context is canceled. It does not reserve
+// the event (see Take).
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	r.lock.Lock()
+	wait := time.Until(r.next)
+	r.lock.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Acquire reserves the next allowed event and blocks
+// until it occurs or the context is canceled. Unlike
+// Wait followed by Take, concurrent callers of Acquire
+// are always spaced out by the interval.
+func (r *RateLimiter) Acquire(ctx context.Context) error {
+	r.lock.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	slot := r.next
+	r.next = r.next.Add(r.interval)
+	r.lock.Unlock()
+
+	wait := time.Until(slot)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Take records an event, delaying the next
+// allowed event by the interval.
+func (r *RateLimiter) Take() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	r.next = r.next.Add(r.interval)
+}
+
+// TokenBucket allows events at a target rate while
+// permitting bursts of up to a number of events after
+// a pause.
+type TokenBucket struct {
+	perSecond float64
+	burst     float64
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a new (full) *TokenBucket that
+// is refilled with perSecond tokens per second and holds
+// at most burst tokens.
+func NewTokenBucket(perSecond float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+		last:      time.Now(),
+	}
+}
+
+// reserve takes a token (which may not be available yet)
+// and returns how long to wait until it is available.
+func (b *TokenBucket) reserve() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.perSecond
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.perSecond * float64(time.Second))
+}
+
+// release returns a token that was reserved
+// but not used.
+func (b *TokenBucket) release() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.tokens++
+}
+
+// Wait takes a token, blocking until it is available
+// or the context is canceled.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	wait := b.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.release()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(20)
+
+	// The first event is allowed immediately
+	start := time.Now()
+	assert.NoError(t, limiter.Wait(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Millisecond))
+
+	// Waiting does not reserve an event
+	assert.NoError(t, limiter.Wait(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Millisecond))
+
+	limiter.Take()
+	assert.NoError(t, limiter.Wait(ctx))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+
+	limiter.Take()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(canceled), context.Canceled)
+}
+
+func TestRateLimiterAcquire(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(20)
+
+	// The first event is allowed immediately
+	start := time.Now()
+	assert.NoError(t, limiter.Acquire(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Millisecond))
+
+	// Acquiring reserves an event
+	assert.NoError(t, limiter.Acquire(ctx))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, limiter.Acquire(canceled), context.Canceled)
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	bucket := NewTokenBucket(20, 2)
+
+	// The first burst is allowed immediately
+	start := time.Now()
+	assert.NoError(t, bucket.Wait(ctx))
+	assert.NoError(t, bucket.Wait(ctx))
+	assert.Less(t, int64(time.Since(start)), int64(10*time.Millisecond))
+
+	assert.NoError(t, bucket.Wait(ctx))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(40*time.Millisecond))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, bucket.Wait(canceled), context.Canceled)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/coinbase/rosetta-sdk-go/reconciler"
 	"github.com/coinbase/rosetta-sdk-go/storage/modules"
 	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
 )
 
 const (
@@ -52,9 +53,34 @@ type ReconcilerHandler struct {
 	balanceStorage            *modules.BalanceStorage
 	haltOnReconciliationError bool
 
+	// escalationThreshold is the number of failures with the
+	// same signature that are logged as warnings before
+	// further failures halt (if haltOnReconciliationError).
+	escalationThreshold int64
+
+	// activeLimiter and inactiveLimiter, if populated, limit
+	// the rate of active and inactive reconciliations. Because
+	// the reconciler invokes the handler from the worker that
+	// performed the reconciliation, blocking here delays that
+	// worker's next /account/balance request.
+	activeLimiter   *RateLimiter
+	inactiveLimiter *RateLimiter
+
+	// paused maps each paused reconciliation type to a
+	// channel that is closed when it is resumed. Like
+	// rate limiting, pausing blocks workers in the handler
+	// (after their current reconciliation).
+	pauseLock sync.Mutex
+	paused    map[string]chan struct{}
+
+	// skips are triaged reconciliation failures that
+	// should not be considered failures until they expire.
+	skips []*results.ReconciliationSkip
+
 	InactiveFailure      *types.AccountCurrency
 	InactiveFailureBlock *types.BlockIdentifier
 
+	ActiveFailure      *types.AccountCurrency
 	ActiveFailureBlock *types.BlockIdentifier
 
 	counterLock sync.Mutex
@@ -67,21 +93,119 @@ func NewReconcilerHandler(
 	counterStorage *modules.CounterStorage,
 	balanceStorage *modules.BalanceStorage,
 	haltOnReconciliationError bool,
+	escalationThreshold int64,
+	activeRateLimit float64,
+	inactiveRateLimit float64,
+	skips []*results.ReconciliationSkip,
 ) *ReconcilerHandler {
 	counts := map[string]int64{}
 	for _, key := range countKeys {
 		counts[key] = 0
 	}
 
-	return &ReconcilerHandler{
+	h := &ReconcilerHandler{
 		logger:                    logger,
 		counterStorage:            counterStorage,
 		balanceStorage:            balanceStorage,
 		haltOnReconciliationError: haltOnReconciliationError,
+		escalationThreshold:       escalationThreshold,
+		skips:                     skips,
 		counts:                    counts,
+		paused:                    map[string]chan struct{}{},
+	}
+
+	if activeRateLimit > 0 {
+		h.activeLimiter = NewRateLimiter(activeRateLimit)
+	}
+
+	if inactiveRateLimit > 0 {
+		h.inactiveLimiter = NewRateLimiter(inactiveRateLimit)
+	}
+
+	return h
+}
+
+// Pause stops reconciliations of reconciliationType (without
+// stopping syncing). Each worker finishes its current reconciliation
+// and then waits until Resume is called, so active balance changes
+// synced while paused are skipped once the backlog is full. Pause
+// returns false if reconciliationType was already paused.
+func (h *ReconcilerHandler) Pause(reconciliationType string) bool {
+	h.pauseLock.Lock()
+	defer h.pauseLock.Unlock()
+
+	if _, ok := h.paused[reconciliationType]; ok {
+		return false
+	}
+
+	h.paused[reconciliationType] = make(chan struct{})
+	return true
+}
+
+// Resume restarts reconciliations of reconciliationType. Resume
+// returns false if reconciliationType was not paused.
+func (h *ReconcilerHandler) Resume(reconciliationType string) bool {
+	h.pauseLock.Lock()
+	defer h.pauseLock.Unlock()
+
+	resumed, ok := h.paused[reconciliationType]
+	if !ok {
+		return false
+	}
+
+	close(resumed)
+	delete(h.paused, reconciliationType)
+	return true
+}
+
+// Paused returns a boolean indicating if reconciliations
+// of reconciliationType are paused.
+func (h *ReconcilerHandler) Paused(reconciliationType string) bool {
+	h.pauseLock.Lock()
+	defer h.pauseLock.Unlock()
+
+	_, ok := h.paused[reconciliationType]
+	return ok
+}
+
+// waitIfPaused blocks until reconciliations of
+// reconciliationType are resumed (if paused).
+func (h *ReconcilerHandler) waitIfPaused(ctx context.Context, reconciliationType string) error {
+	h.pauseLock.Lock()
+	resumed, ok := h.paused[reconciliationType]
+	h.pauseLock.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
+// throttle blocks until another reconciliation of
+// reconciliationType is allowed (if paused or rate limited).
+func (h *ReconcilerHandler) throttle(ctx context.Context, reconciliationType string) error {
+	if err := h.waitIfPaused(ctx, reconciliationType); err != nil {
+		return err
+	}
+
+	limiter := h.activeLimiter
+	if reconciliationType == reconciler.InactiveReconciliation {
+		limiter = h.inactiveLimiter
+	}
+
+	if limiter == nil {
+		return nil
+	}
+
+	return limiter.Acquire(ctx)
+}
+
 // Updater periodically updates modules.with cached counts.
 func (h *ReconcilerHandler) Updater(ctx context.Context) error {
 	tc := time.NewTicker(updateFrequency)
@@ -121,7 +245,10 @@ func (h *ReconcilerHandler) UpdateCounts(ctx context.Context) error {
 
 // ReconciliationFailed is called each time a reconciliation fails.
 // In this Handler implementation, we halt if haltOnReconciliationError
-// was set to true. We also cancel the context.
+// was set to true (once more than escalationThreshold failures with
+// the same signature have occurred). We also cancel the context.
+// Failures matching an unexpired skip are logged but not considered
+// failures.
 func (h *ReconcilerHandler) ReconciliationFailed(
 	ctx context.Context,
 	reconciliationType string,
@@ -131,10 +258,46 @@ func (h *ReconcilerHandler) ReconciliationFailed(
 	liveBalance string,
 	block *types.BlockIdentifier,
 ) error {
+	skip := MatchReconciliationSkip(h.skips, account, currency, block, time.Now())
+	if skip != nil {
+		results.RecordReconciliationSkip(skip)
+		color.Yellow(
+			"Skipping known reconciliation failure for %s at %d computed: %s%s live: %s%s (%s, expires %s)",
+			types.AccountString(account),
+			block.Index,
+			computedBalance,
+			currency.Symbol,
+			liveBalance,
+			currency.Symbol,
+			skip.Reason,
+			skip.Expires.Format(time.RFC3339),
+		)
+
+		return h.throttle(ctx, reconciliationType)
+	}
+
 	h.counterLock.Lock()
 	h.counts[modules.FailedReconciliationCounter]++
 	h.counterLock.Unlock()
 
+	occurrences := results.RecordFailure(&results.FailureSignature{
+		Category:       results.ReconciliationFailureCategory,
+		Code:           reconciliationType,
+		AccountPattern: results.AccountPattern(account),
+	}, fmt.Sprintf(
+		"%s at %d computed: %s%s live: %s%s",
+		types.AccountString(account),
+		block.Index,
+		computedBalance,
+		currency.Symbol,
+		liveBalance,
+		currency.Symbol,
+	))
+
+	if err := h.throttle(ctx, reconciliationType); err != nil {
+		return err
+	}
+
 	err := h.logger.ReconcileFailureStream(
 		ctx,
 		reconciliationType,
@@ -148,6 +311,16 @@ func (h *ReconcilerHandler) ReconciliationFailed(
 		return err
 	}
 
+	if h.haltOnReconciliationError && occurrences <= h.escalationThreshold {
+		color.Yellow(
+			"Reconciliation failure logged as a warning (%d of %d allowed with this signature before halting)",
+			occurrences,
+			h.escalationThreshold,
+		)
+
+		return nil
+	}
+
 	if h.haltOnReconciliationError {
 		// Update counts before exiting
 		_ = h.UpdateCounts(ctx)
@@ -173,6 +346,10 @@ func (h *ReconcilerHandler) ReconciliationFailed(
 		}
 
 		// If we halt on an active reconciliation error, store in the handler.
+		h.ActiveFailure = &types.AccountCurrency{
+			Account:  account,
+			Currency: currency,
+		}
 		h.ActiveFailureBlock = block
 		return fmt.Errorf(
 			"%w: active reconciliation error for %s at %d (computed: %s%s, live: %s%s)",
@@ -205,6 +382,10 @@ func (h *ReconcilerHandler) ReconciliationExempt(
 	h.counts[modules.ExemptReconciliationCounter]++
 	h.counterLock.Unlock()
 
+	if err := h.throttle(ctx, reconciliationType); err != nil {
+		return err
+	}
+
 	// Although the reconciliation was exempt (non-zero difference that was ignored),
 	// we still mark the account as being reconciled because the balance was in the range
 	// specified by exemption.
@@ -227,7 +408,15 @@ func (h *ReconcilerHandler) ReconciliationSkipped(
 	h.counts[modules.SkippedReconciliationsCounter]++
 	h.counterLock.Unlock()
 
-	return nil
+	// Reconciliations skipped because the syncer is behind
+	// or the backlog is full are skipped before the live
+	// balance is fetched (by the goroutine queueing changes,
+	// which must never block).
+	if cause == reconciler.HeadBehind || cause == reconciler.BacklogFull {
+		return nil
+	}
+
+	return h.throttle(ctx, reconciliationType)
 }
 
 // ReconciliationSucceeded is called each time a reconciliation succeeds.
@@ -249,6 +438,10 @@ func (h *ReconcilerHandler) ReconciliationSucceeded(
 	h.counts[counter]++
 	h.counterLock.Unlock()
 
+	if err := h.throttle(ctx, reconciliationType); err != nil {
+		return err
+	}
+
 	if err := h.balanceStorage.Reconciled(ctx, account, currency, block); err != nil {
 		return fmt.Errorf("%w: unable to store updated reconciliation", err)
 	}
@@ -0,0 +1,91 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/reconciler"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcilerHandlerPause(t *testing.T) {
+	ctx := context.Background()
+	h := NewReconcilerHandler(nil, nil, nil, false, 0, 0, 0, nil)
+	account := &types.AccountIdentifier{Address: "addr1"}
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+
+	assert.False(t, h.Resume(reconciler.ActiveReconciliation))
+	assert.True(t, h.Pause(reconciler.ActiveReconciliation))
+	assert.False(t, h.Pause(reconciler.ActiveReconciliation))
+	assert.True(t, h.Paused(reconciler.ActiveReconciliation))
+	assert.False(t, h.Paused(reconciler.InactiveReconciliation))
+
+	// Inactive reconciliation is not paused
+	assert.NoError(t, h.ReconciliationSkipped(
+		ctx,
+		reconciler.InactiveReconciliation,
+		account,
+		currency,
+		reconciler.TipFailure,
+	))
+
+	// Changes skipped while queueing never block
+	for _, cause := range []string{reconciler.HeadBehind, reconciler.BacklogFull} {
+		assert.NoError(t, h.ReconciliationSkipped(
+			ctx,
+			reconciler.ActiveReconciliation,
+			account,
+			currency,
+			cause,
+		))
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- h.ReconciliationSkipped(
+			ctx,
+			reconciler.ActiveReconciliation,
+			account,
+			currency,
+			reconciler.TipFailure,
+		)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("active reconciliation was not paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.True(t, h.Resume(reconciler.ActiveReconciliation))
+	assert.False(t, h.Paused(reconciler.ActiveReconciliation))
+	assert.NoError(t, <-done)
+
+	// Paused workers exit when the context is canceled
+	assert.True(t, h.Pause(reconciler.InactiveReconciliation))
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, h.ReconciliationSkipped(
+		cancelCtx,
+		reconciler.InactiveReconciliation,
+		account,
+		currency,
+		reconciler.TipFailure,
+	), context.Canceled)
+}
@@ -16,8 +16,10 @@ package processor
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
 
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
 	"github.com/coinbase/rosetta-sdk-go/reconciler"
@@ -74,15 +76,31 @@ func (h *ReconcilerHelper) DatabaseTransaction(
 // CanonicalBlock returns a boolean indicating if a block
 // is in the canonical chain. This is necessary to reconcile across
 // reorgs. If the block returned on an account balance fetch
-// does not exist, reconciliation will be skipped.
+// does not exist (or is outside of the range of a partial sync),
+// reconciliation will be skipped.
 func (h *ReconcilerHelper) CanonicalBlock(
 	ctx context.Context,
 	dbTx database.Transaction,
 	block *types.BlockIdentifier,
 ) (bool, error) {
+	if !InSyncRange(h.config.Data, block.Index) {
+		return false, nil
+	}
+
 	return h.blockStorage.CanonicalBlockTransactional(ctx, block, dbTx)
 }
 
+// InSyncRange returns a boolean indicating if a block index is
+// within the range of a partial sync. If partial sync is not
+// configured, all indexes are considered in range.
+func InSyncRange(config *configuration.DataConfiguration, index int64) bool {
+	if config == nil || config.PartialSync == nil {
+		return true
+	}
+
+	return index >= *config.StartIndex && index <= *config.EndConditions.Index
+}
+
 // IndexAtTip returns a boolean indicating if a block
 // index is at tip (provided some acceptable
 // tip delay). If the index is ahead of the head block
@@ -129,7 +147,30 @@ func (h *ReconcilerHelper) LiveBalance(
 	currency *types.Currency,
 	index int64,
 ) (*types.Amount, *types.BlockIdentifier, error) {
-	amt, block, err := utils.CurrencyBalance(
+	amt, block, err := h.liveBalance(ctx, account, currency, index)
+	if err != nil {
+		if h.config.Data.VerifyAccountMetadata && len(account.Metadata) > 0 {
+			return nil, nil, h.verifyAccountMetadata(ctx, account, currency, index, err)
+		}
+
+		return nil, nil, err
+	}
+	return amt, block, nil
+}
+
+// liveBalance returns the live balance of an account (verifying
+// the currencies filter, if configured).
+func (h *ReconcilerHelper) liveBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) (*types.Amount, *types.BlockIdentifier, error) {
+	if h.config.Data.VerifyCurrencyFilter {
+		return h.filterVerifiedLiveBalance(ctx, account, currency, index)
+	}
+
+	return utils.CurrencyBalance(
 		ctx,
 		h.network,
 		h.fetcher,
@@ -137,10 +178,135 @@ func (h *ReconcilerHelper) LiveBalance(
 		currency,
 		index,
 	)
-	if err != nil {
-		return nil, nil, err
+}
+
+// verifyAccountMetadata retries a failed live balance lookup of an
+// account without its metadata. If the retry succeeds, the original
+// failure was caused by the account metadata.
+func (h *ReconcilerHelper) verifyAccountMetadata(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+	lookupErr error,
+) error {
+	if _, _, err := utils.CurrencyBalance(
+		ctx,
+		h.network,
+		h.fetcher,
+		&types.AccountIdentifier{
+			Address:    account.Address,
+			SubAccount: account.SubAccount,
+		},
+		currency,
+		index,
+	); err != nil {
+		return lookupErr
 	}
-	return amt, block, nil
+
+	return fmt.Errorf(
+		"%w: /account/balance rejected metadata %s of account %s observed in operations: %s",
+		results.ErrAccountMetadata,
+		types.PrintStruct(account.Metadata),
+		account.Address,
+		lookupErr.Error(),
+	)
+}
+
+// filterVerifiedLiveBalance returns the live balance of an account
+// after ensuring the implementation honors the currencies filter
+// on /account/balance.
+func (h *ReconcilerHelper) filterVerifiedLiveBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) (*types.Amount, *types.BlockIdentifier, error) {
+	var lookupBlock *types.PartialBlockIdentifier
+	if index >= 0 {
+		lookupBlock = &types.PartialBlockIdentifier{
+			Index: &index,
+		}
+	}
+
+	block, filtered, _, fetchErr := h.fetcher.AccountBalanceRetry(
+		ctx,
+		h.network,
+		account,
+		lookupBlock,
+		[]*types.Currency{currency},
+	)
+	if fetchErr != nil {
+		return nil, nil, fetchErr.Err
+	}
+
+	// We fetch the unfiltered balance at the block returned by the
+	// filtered request to ensure both lookups are at the same height.
+	_, unfiltered, _, fetchErr := h.fetcher.AccountBalanceRetry(
+		ctx,
+		h.network,
+		account,
+		types.ConstructPartialBlockIdentifier(block),
+		nil,
+	)
+	if fetchErr != nil {
+		return nil, nil, fetchErr.Err
+	}
+
+	if err := VerifyCurrencyFilter(currency, filtered, unfiltered); err != nil {
+		return nil, nil, fmt.Errorf(
+			"%w: account %s at block %d",
+			err,
+			types.AccountString(account),
+			block.Index,
+		)
+	}
+
+	return types.ExtractAmount(filtered, currency), block, nil
+}
+
+// VerifyCurrencyFilter ensures the balances returned by a request
+// to /account/balance filtered by currency only contain that currency
+// and that the unfiltered balances are a superset of the filtered
+// balances. Implementations are permitted to omit zero balances.
+func VerifyCurrencyFilter(
+	currency *types.Currency,
+	filtered []*types.Amount,
+	unfiltered []*types.Amount,
+) error {
+	if len(filtered) > 1 {
+		return fmt.Errorf(
+			"%w: requested %s but received %d balances",
+			results.ErrCurrencyFilterIgnored,
+			types.CurrencyString(currency),
+			len(filtered),
+		)
+	}
+
+	for _, amount := range filtered {
+		if types.Hash(amount.Currency) != types.Hash(currency) {
+			return fmt.Errorf(
+				"%w: requested %s but received %s",
+				results.ErrCurrencyFilterIgnored,
+				types.CurrencyString(currency),
+				types.CurrencyString(amount.Currency),
+			)
+		}
+	}
+
+	filteredAmount := types.ExtractAmount(filtered, currency)
+	unfilteredAmount := types.ExtractAmount(unfiltered, currency)
+	if filteredAmount.Value != unfilteredAmount.Value {
+		return fmt.Errorf(
+			"%w: %s filtered balance %s but unfiltered balance %s",
+			results.ErrCurrencyFilterMismatch,
+			types.CurrencyString(currency),
+			filteredAmount.Value,
+			unfilteredAmount.Value,
+		)
+	}
+
+	return nil
 }
 
 // PruneBalances removes all historical balance states
@@ -0,0 +1,125 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCurrencyFilter(t *testing.T) {
+	btc := &types.Currency{
+		Symbol:   "BTC",
+		Decimals: 8,
+	}
+	eth := &types.Currency{
+		Symbol:   "ETH",
+		Decimals: 18,
+	}
+
+	var tests = map[string]struct {
+		filtered   []*types.Amount
+		unfiltered []*types.Amount
+
+		err error
+	}{
+		"filter honored": {
+			filtered: []*types.Amount{
+				{Value: "10", Currency: btc},
+			},
+			unfiltered: []*types.Amount{
+				{Value: "5", Currency: eth},
+				{Value: "10", Currency: btc},
+			},
+		},
+		"zero balance omitted": {
+			filtered: []*types.Amount{},
+			unfiltered: []*types.Amount{
+				{Value: "5", Currency: eth},
+			},
+		},
+		"filter ignored": {
+			filtered: []*types.Amount{
+				{Value: "5", Currency: eth},
+				{Value: "10", Currency: btc},
+			},
+			unfiltered: []*types.Amount{
+				{Value: "5", Currency: eth},
+				{Value: "10", Currency: btc},
+			},
+			err: results.ErrCurrencyFilterIgnored,
+		},
+		"wrong currency returned": {
+			filtered: []*types.Amount{
+				{Value: "5", Currency: eth},
+			},
+			unfiltered: []*types.Amount{
+				{Value: "5", Currency: eth},
+			},
+			err: results.ErrCurrencyFilterIgnored,
+		},
+		"unfiltered missing currency": {
+			filtered: []*types.Amount{
+				{Value: "10", Currency: btc},
+			},
+			unfiltered: []*types.Amount{
+				{Value: "5", Currency: eth},
+			},
+			err: results.ErrCurrencyFilterMismatch,
+		},
+		"unfiltered balance differs": {
+			filtered: []*types.Amount{
+				{Value: "10", Currency: btc},
+			},
+			unfiltered: []*types.Amount{
+				{Value: "11", Currency: btc},
+			},
+			err: results.ErrCurrencyFilterMismatch,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := VerifyCurrencyFilter(btc, test.filtered, test.unfiltered)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInSyncRange(t *testing.T) {
+	assert.True(t, InSyncRange(nil, 10))
+	assert.True(t, InSyncRange(&configuration.DataConfiguration{}, 10))
+
+	config := &configuration.DataConfiguration{
+		StartIndex: types.Int64(5),
+		EndConditions: &configuration.DataEndConditions{
+			Index: types.Int64(10),
+		},
+		PartialSync: &configuration.PartialSyncConfiguration{},
+	}
+	assert.False(t, InSyncRange(config, 4))
+	assert.True(t, InSyncRange(config, 5))
+	assert.True(t, InSyncRange(config, 10))
+	assert.False(t, InSyncRange(config, 11))
+}
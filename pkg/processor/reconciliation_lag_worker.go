@@ -0,0 +1,107 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// lagCheckInterval is how often the reconciler
+	// is checked while sync is paused.
+	lagCheckInterval = 100 * time.Millisecond
+)
+
+var _ modules.BlockWorker = (*ReconciliationLagWorker)(nil)
+
+// ReconciliationProgress reports how far active
+// reconciliation has progressed. It is implemented
+// by *reconciler.Reconciler.
+type ReconciliationProgress interface {
+	QueueSize() int
+	LastIndexReconciled() int64
+}
+
+// ReconciliationLagWorker pauses syncing after a block is
+// added until active reconciliation is no more than maxLag
+// blocks behind it.
+type ReconciliationLagWorker struct {
+	progress ReconciliationProgress
+	maxLag   int64
+}
+
+// NewReconciliationLagWorker returns a new *ReconciliationLagWorker.
+func NewReconciliationLagWorker(
+	progress ReconciliationProgress,
+	maxLag int64,
+) *ReconciliationLagWorker {
+	return &ReconciliationLagWorker{
+		progress: progress,
+		maxLag:   maxLag,
+	}
+}
+
+// lagging returns a boolean indicating if active reconciliation
+// is more than maxLag blocks behind index.
+func (w *ReconciliationLagWorker) lagging(index int64) bool {
+	return w.progress.QueueSize() > 0 &&
+		index-w.progress.LastIndexReconciled() > w.maxLag
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+// Waiting is done in the returned CommitWorker so that the
+// database transaction is not held open while sync is paused.
+func (w *ReconciliationLagWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	index := block.BlockIdentifier.Index
+	return func(ctx context.Context) error {
+		if !w.lagging(index) {
+			return nil
+		}
+
+		tc := time.NewTicker(lagCheckInterval)
+		defer tc.Stop()
+
+		for w.lagging(index) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-tc.C:
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *ReconciliationLagWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
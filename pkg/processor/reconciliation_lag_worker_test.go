@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProgress struct {
+	queueSize int64
+	lastIndex int64
+}
+
+func (m *mockProgress) QueueSize() int {
+	return int(atomic.LoadInt64(&m.queueSize))
+}
+
+func (m *mockProgress) LastIndexReconciled() int64 {
+	return atomic.LoadInt64(&m.lastIndex)
+}
+
+func TestReconciliationLagWorker(t *testing.T) {
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 100},
+	}
+
+	t.Run("within lag", func(t *testing.T) {
+		progress := &mockProgress{queueSize: 10, lastIndex: 95}
+		w := NewReconciliationLagWorker(progress, 5)
+
+		commitWorker, err := w.AddingBlock(context.Background(), nil, block, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, commitWorker(context.Background()))
+	})
+
+	t.Run("empty queue", func(t *testing.T) {
+		progress := &mockProgress{queueSize: 0, lastIndex: 10}
+		w := NewReconciliationLagWorker(progress, 5)
+
+		commitWorker, err := w.AddingBlock(context.Background(), nil, block, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, commitWorker(context.Background()))
+	})
+
+	t.Run("waits for reconciler", func(t *testing.T) {
+		progress := &mockProgress{queueSize: 10, lastIndex: 10}
+		w := NewReconciliationLagWorker(progress, 5)
+
+		go func() {
+			time.Sleep(2 * lagCheckInterval)
+			atomic.StoreInt64(&progress.lastIndex, 95)
+		}()
+
+		commitWorker, err := w.AddingBlock(context.Background(), nil, block, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, commitWorker(context.Background()))
+		assert.Equal(t, int64(95), progress.LastIndexReconciled())
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		progress := &mockProgress{queueSize: 10, lastIndex: 10}
+		w := NewReconciliationLagWorker(progress, 5)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		commitWorker, err := w.AddingBlock(ctx, nil, block, nil)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, commitWorker(ctx), context.Canceled)
+	})
+
+	t.Run("removing block", func(t *testing.T) {
+		w := NewReconciliationLagWorker(&mockProgress{}, 5)
+
+		commitWorker, err := w.RemovingBlock(context.Background(), nil, block, nil)
+		assert.NoError(t, err)
+		assert.Nil(t, commitWorker)
+	})
+}
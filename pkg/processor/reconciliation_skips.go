@@ -0,0 +1,130 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+// LoadReconciliationSkips loads the reconciliation skips
+// persisted at filePath. If the file does not exist, no
+// skips are returned.
+func LoadReconciliationSkips(filePath string) ([]*results.ReconciliationSkip, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return []*results.ReconciliationSkip{}, nil
+	}
+
+	skips := []*results.ReconciliationSkip{}
+	if err := utils.LoadAndParse(filePath, &skips); err != nil {
+		return nil, fmt.Errorf("%w: unable to load reconciliation skips", err)
+	}
+
+	for i, skip := range skips {
+		if err := AssertReconciliationSkip(skip); err != nil {
+			return nil, fmt.Errorf("%w: reconciliation skip %d is invalid", err, i)
+		}
+	}
+
+	return skips, nil
+}
+
+// SaveReconciliationSkips persists skips to filePath.
+func SaveReconciliationSkips(filePath string, skips []*results.ReconciliationSkip) error {
+	if err := utils.SerializeAndWrite(filePath, skips); err != nil {
+		return fmt.Errorf("%w: unable to save reconciliation skips", err)
+	}
+
+	return nil
+}
+
+// AssertReconciliationSkip ensures a *results.ReconciliationSkip
+// identifies a failure and is set to expire.
+func AssertReconciliationSkip(skip *results.ReconciliationSkip) error {
+	if skip.Account == nil || len(skip.Account.Address) == 0 {
+		return errors.New("account identifier is missing")
+	}
+
+	if skip.Currency == nil || len(skip.Currency.Symbol) == 0 {
+		return errors.New("currency is missing")
+	}
+
+	if len(skip.Reason) == 0 {
+		return errors.New("reason is missing")
+	}
+
+	if skip.Expires.IsZero() {
+		return errors.New("expiry is missing")
+	}
+
+	return nil
+}
+
+// PartitionReconciliationSkips splits skips into those that
+// are active and those that have expired at now.
+func PartitionReconciliationSkips(
+	skips []*results.ReconciliationSkip,
+	now time.Time,
+) ([]*results.ReconciliationSkip, []*results.ReconciliationSkip) {
+	active := []*results.ReconciliationSkip{}
+	expired := []*results.ReconciliationSkip{}
+	for _, skip := range skips {
+		if now.Before(skip.Expires) {
+			active = append(active, skip)
+		} else {
+			expired = append(expired, skip)
+		}
+	}
+
+	return active, expired
+}
+
+// MatchReconciliationSkip returns the first skip in skips that
+// has not expired at now and matches a reconciliation failure
+// of account and currency at block. If there is no match, nil
+// is returned.
+func MatchReconciliationSkip(
+	skips []*results.ReconciliationSkip,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+	now time.Time,
+) *results.ReconciliationSkip {
+	for _, skip := range skips {
+		if !now.Before(skip.Expires) {
+			continue
+		}
+
+		if types.Hash(skip.Account) != types.Hash(account) ||
+			types.Hash(skip.Currency) != types.Hash(currency) {
+			continue
+		}
+
+		if skip.BlockIndex != nil && (block == nil || *skip.BlockIndex != block.Index) {
+			continue
+		}
+
+		return skip
+	}
+
+	return nil
+}
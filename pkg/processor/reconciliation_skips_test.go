@@ -0,0 +1,149 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	skipNow = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	skipAccount = &types.AccountIdentifier{Address: "addr1"}
+	skipBTC     = &types.Currency{Symbol: "BTC", Decimals: 8}
+	skipETH     = &types.Currency{Symbol: "ETH", Decimals: 18}
+)
+
+func TestReconciliationSkipsPersistence(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	filePath := path.Join(dir, "skips.json")
+	skips, err := LoadReconciliationSkips(filePath)
+	assert.NoError(t, err)
+	assert.Len(t, skips, 0)
+
+	blockIndex := int64(10)
+	saved := []*results.ReconciliationSkip{
+		{
+			Account:    skipAccount,
+			Currency:   skipBTC,
+			BlockIndex: &blockIndex,
+			Reason:     "known rounding issue",
+			Expires:    skipNow,
+		},
+	}
+	assert.NoError(t, SaveReconciliationSkips(filePath, saved))
+
+	skips, err = LoadReconciliationSkips(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, skips)
+
+	saved[0].Reason = ""
+	assert.NoError(t, SaveReconciliationSkips(filePath, saved))
+	skips, err = LoadReconciliationSkips(filePath)
+	assert.Error(t, err)
+	assert.Nil(t, skips)
+}
+
+func TestPartitionReconciliationSkips(t *testing.T) {
+	expired := &results.ReconciliationSkip{Expires: skipNow}
+	active := &results.ReconciliationSkip{Expires: skipNow.Add(time.Hour)}
+
+	activeSkips, expiredSkips := PartitionReconciliationSkips(
+		[]*results.ReconciliationSkip{expired, active},
+		skipNow,
+	)
+	assert.Equal(t, []*results.ReconciliationSkip{active}, activeSkips)
+	assert.Equal(t, []*results.ReconciliationSkip{expired}, expiredSkips)
+}
+
+func TestMatchReconciliationSkip(t *testing.T) {
+	blockIndex := int64(10)
+	anyBlock := &results.ReconciliationSkip{
+		Account:  skipAccount,
+		Currency: skipBTC,
+		Expires:  skipNow.Add(time.Hour),
+	}
+	singleBlock := &results.ReconciliationSkip{
+		Account:    skipAccount,
+		Currency:   skipETH,
+		BlockIndex: &blockIndex,
+		Expires:    skipNow.Add(time.Hour),
+	}
+	skips := []*results.ReconciliationSkip{anyBlock, singleBlock}
+
+	tests := map[string]struct {
+		account  *types.AccountIdentifier
+		currency *types.Currency
+		block    *types.BlockIdentifier
+		now      time.Time
+		match    *results.ReconciliationSkip
+	}{
+		"any block": {
+			account:  skipAccount,
+			currency: skipBTC,
+			block:    &types.BlockIdentifier{Index: 5, Hash: "block 5"},
+			now:      skipNow,
+			match:    anyBlock,
+		},
+		"single block": {
+			account:  skipAccount,
+			currency: skipETH,
+			block:    &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+			now:      skipNow,
+			match:    singleBlock,
+		},
+		"different block": {
+			account:  skipAccount,
+			currency: skipETH,
+			block:    &types.BlockIdentifier{Index: 11, Hash: "block 11"},
+			now:      skipNow,
+		},
+		"different account": {
+			account:  &types.AccountIdentifier{Address: "addr2"},
+			currency: skipBTC,
+			block:    &types.BlockIdentifier{Index: 5, Hash: "block 5"},
+			now:      skipNow,
+		},
+		"expired": {
+			account:  skipAccount,
+			currency: skipBTC,
+			block:    &types.BlockIdentifier{Index: 5, Hash: "block 5"},
+			now:      skipNow.Add(time.Hour),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.match, MatchReconciliationSkip(
+				skips,
+				test.account,
+				test.currency,
+				test.block,
+				test.now,
+			))
+		})
+	}
+}
@@ -0,0 +1,404 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sort"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/parser"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+var _ modules.BalanceStorageHelper = (*reorgBalanceHelper)(nil)
+var _ modules.BalanceStorageHandler = (*reorgBalanceHandler)(nil)
+
+// reorgBaseBalance is the balance of every account before the
+// simulated fork. It is large enough that no account balance
+// becomes negative, so a simulation doesn't require the actual
+// balances of the accounts in the fork.
+var reorgBaseBalance = new(big.Int).Exp(big.NewInt(10), big.NewInt(40), nil)
+
+// reorgBalanceHelper implements the modules.BalanceStorageHelper
+// interface for simulated reorgs (where balances are never fetched).
+type reorgBalanceHelper struct {
+	asserter *asserter.Asserter
+}
+
+func (h *reorgBalanceHelper) AccountBalance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	block *types.BlockIdentifier,
+) (*types.Amount, error) {
+	return &types.Amount{Value: reorgBaseBalance.String(), Currency: currency}, nil
+}
+
+func (h *reorgBalanceHelper) ExemptFunc() parser.ExemptOperation {
+	return func(*types.Operation) bool { return false }
+}
+
+func (h *reorgBalanceHelper) BalanceExemptions() []*types.BalanceExemption {
+	return nil
+}
+
+func (h *reorgBalanceHelper) Asserter() *asserter.Asserter {
+	return h.asserter
+}
+
+func (h *reorgBalanceHelper) AccountsReconciled(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (h *reorgBalanceHelper) AccountsSeen(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+// reorgBalanceHandler implements the modules.BalanceStorageHandler
+// interface for simulated reorgs (where balances are never reconciled).
+type reorgBalanceHandler struct{}
+
+func (h *reorgBalanceHandler) BlockAdded(
+	ctx context.Context,
+	block *types.Block,
+	changes []*parser.BalanceChange,
+) error {
+	return nil
+}
+
+func (h *reorgBalanceHandler) BlockRemoved(
+	ctx context.Context,
+	block *types.Block,
+	changes []*parser.BalanceChange,
+) error {
+	return nil
+}
+
+func (h *reorgBalanceHandler) AccountsReconciled(
+	ctx context.Context,
+	dbTx database.Transaction,
+	count int,
+) error {
+	return nil
+}
+
+func (h *reorgBalanceHandler) AccountsSeen(
+	ctx context.Context,
+	dbTx database.Transaction,
+	count int,
+) error {
+	return nil
+}
+
+// reorgChain is block and balance storage backed by
+// a temporary database.
+type reorgChain struct {
+	dir            string
+	db             database.Database
+	blockStorage   *modules.BlockStorage
+	balanceStorage *modules.BalanceStorage
+}
+
+// newReorgChain returns a *reorgChain with parent as its
+// oldest block. The transactions of parent are not applied
+// (balances are only tracked after parent).
+func newReorgChain(
+	ctx context.Context,
+	asserter *asserter.Asserter,
+	parent *types.Block,
+) (*reorgChain, error) {
+	dir, err := utils.CreateTempDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create temporary directory", err)
+	}
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	if err != nil {
+		utils.RemoveTempDir(dir)
+		return nil, fmt.Errorf("%w: unable to initialize database", err)
+	}
+
+	blockStorage := modules.NewBlockStorage(db, runtime.NumCPU())
+	balanceStorage := modules.NewBalanceStorage(db)
+	balanceStorage.Initialize(&reorgBalanceHelper{asserter: asserter}, &reorgBalanceHandler{})
+
+	c := &reorgChain{
+		dir:            dir,
+		db:             db,
+		blockStorage:   blockStorage,
+		balanceStorage: balanceStorage,
+	}
+
+	if err := c.add(ctx, &types.Block{
+		BlockIdentifier:       parent.BlockIdentifier,
+		ParentBlockIdentifier: parent.ParentBlockIdentifier,
+		Timestamp:             parent.Timestamp,
+	}); err != nil {
+		c.close(ctx)
+		return nil, err
+	}
+
+	blockStorage.Initialize([]modules.BlockWorker{balanceStorage})
+	return c, nil
+}
+
+// add adds a block the same way as the stateful syncer.
+func (c *reorgChain) add(ctx context.Context, block *types.Block) error {
+	if err := c.blockStorage.SeeBlock(ctx, block); err != nil {
+		return fmt.Errorf(
+			"%w: unable to pre-store block %s",
+			err,
+			types.PrintStruct(block.BlockIdentifier),
+		)
+	}
+
+	if err := c.blockStorage.AddBlock(ctx, block); err != nil {
+		return fmt.Errorf(
+			"%w: unable to add block %s",
+			err,
+			types.PrintStruct(block.BlockIdentifier),
+		)
+	}
+
+	return nil
+}
+
+// remove removes a block the same way as the stateful syncer.
+func (c *reorgChain) remove(ctx context.Context, block *types.Block) error {
+	if err := c.blockStorage.RemoveBlock(ctx, block.BlockIdentifier); err != nil {
+		return fmt.Errorf(
+			"%w: unable to remove block %s",
+			err,
+			types.PrintStruct(block.BlockIdentifier),
+		)
+	}
+
+	return nil
+}
+
+// balance returns the balance of account at index (nil
+// if the account has no stored balance).
+func (c *reorgChain) balance(
+	ctx context.Context,
+	account *types.AccountIdentifier,
+	currency *types.Currency,
+	index int64,
+) (*big.Int, error) {
+	amount, err := c.balanceStorage.GetBalance(ctx, account, currency, index)
+	if errors.Is(err, storageErrs.ErrAccountMissing) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"%w: unable to get balance of %s",
+			err,
+			types.AccountString(account),
+		)
+	}
+
+	return types.AmountValue(amount)
+}
+
+func (c *reorgChain) close(ctx context.Context) {
+	_ = c.db.Close(ctx)
+	utils.RemoveTempDir(c.dir)
+}
+
+// assertSegment returns an error if blocks don't
+// form a chain extending parent.
+func assertSegment(parent *types.BlockIdentifier, blocks []*types.Block) error {
+	if len(blocks) == 0 {
+		return errors.New("segment is empty")
+	}
+
+	for _, block := range blocks {
+		if types.Hash(block.ParentBlockIdentifier) != types.Hash(parent) {
+			return fmt.Errorf(
+				"parent of block %s is %s but expected %s",
+				types.PrintStruct(block.BlockIdentifier),
+				types.PrintStruct(block.ParentBlockIdentifier),
+				types.PrintStruct(parent),
+			)
+		}
+
+		parent = block.BlockIdentifier
+	}
+
+	return nil
+}
+
+// SimulateReorg verifies that block and balance storage correctly
+// revert and re-apply balance changes across a reorg from canonical
+// to fork (both of which must extend parent). canonical is added
+// and then removed (in reverse order) before fork is added. The
+// balance of every account changed in either segment is then
+// compared to its balance when fork is added directly. Accounts
+// with differing balances are returned.
+func SimulateReorg(
+	ctx context.Context,
+	asserter *asserter.Asserter,
+	parent *types.Block,
+	canonical []*types.Block,
+	fork []*types.Block,
+) ([]*results.ReorgInconsistency, error) {
+	if err := assertSegment(parent.BlockIdentifier, canonical); err != nil {
+		return nil, fmt.Errorf("%w: invalid canonical segment", err)
+	}
+
+	if err := assertSegment(parent.BlockIdentifier, fork); err != nil {
+		return nil, fmt.Errorf("%w: invalid fork segment", err)
+	}
+
+	reorged, err := newReorgChain(ctx, asserter, parent)
+	if err != nil {
+		return nil, err
+	}
+	defer reorged.close(ctx)
+
+	for _, block := range canonical {
+		if err := reorged.add(ctx, block); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := len(canonical) - 1; i >= 0; i-- {
+		if err := reorged.remove(ctx, canonical[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, block := range fork {
+		if err := reorged.add(ctx, block); err != nil {
+			return nil, err
+		}
+	}
+
+	direct, err := newReorgChain(ctx, asserter, parent)
+	if err != nil {
+		return nil, err
+	}
+	defer direct.close(ctx)
+
+	for _, block := range fork {
+		if err := direct.add(ctx, block); err != nil {
+			return nil, err
+		}
+	}
+
+	head, err := reorged.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	forkHead := fork[len(fork)-1].BlockIdentifier
+	if types.Hash(head) != types.Hash(forkHead) {
+		return nil, fmt.Errorf(
+			"head block after reorg is %s but expected %s",
+			types.PrintStruct(head),
+			types.PrintStruct(forkHead),
+		)
+	}
+
+	return compareReorgBalances(ctx, asserter, reorged, direct, forkHead.Index, canonical, fork)
+}
+
+// compareReorgBalances returns the accounts changed in canonical
+// or fork whose balance at index differs between reorged and
+// direct. An account without a stored balance is considered
+// to hold reorgBaseBalance.
+func compareReorgBalances(
+	ctx context.Context,
+	asserter *asserter.Asserter,
+	reorged *reorgChain,
+	direct *reorgChain,
+	index int64,
+	canonical []*types.Block,
+	fork []*types.Block,
+) ([]*results.ReorgInconsistency, error) {
+	p := parser.New(asserter, nil, nil)
+	accounts := map[string]*types.AccountCurrency{}
+	for _, block := range append(append([]*types.Block{}, canonical...), fork...) {
+		changes, err := p.BalanceChanges(ctx, block, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to calculate balance changes", err)
+		}
+
+		for _, change := range changes {
+			account := &types.AccountCurrency{
+				Account:  change.Account,
+				Currency: change.Currency,
+			}
+			accounts[types.Hash(account)] = account
+		}
+	}
+
+	keys := make([]string, 0, len(accounts))
+	for key := range accounts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	inconsistencies := []*results.ReorgInconsistency{}
+	for _, key := range keys {
+		account := accounts[key]
+		reorgedBalance, err := reorged.balance(ctx, account.Account, account.Currency, index)
+		if err != nil {
+			return nil, err
+		}
+
+		directBalance, err := direct.balance(ctx, account.Account, account.Currency, index)
+		if err != nil {
+			return nil, err
+		}
+
+		if reorgedBalance == nil {
+			reorgedBalance = reorgBaseBalance
+		}
+
+		if directBalance == nil {
+			directBalance = reorgBaseBalance
+		}
+
+		if reorgedBalance.Cmp(directBalance) == 0 {
+			continue
+		}
+
+		inconsistencies = append(inconsistencies, &results.ReorgInconsistency{
+			Account:  account.Account,
+			Currency: account.Currency,
+			Reorged:  new(big.Int).Sub(reorgedBalance, reorgBaseBalance).String(),
+			Expected: new(big.Int).Sub(directBalance, reorgBaseBalance).String(),
+		})
+	}
+
+	return inconsistencies, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateReorg(t *testing.T) {
+	ctx := context.Background()
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	transfer := func(hash string, from string, to string, value string) *types.Transaction {
+		op := func(index int64, address string, value string) *types.Operation {
+			return &types.Operation{
+				OperationIdentifier: &types.OperationIdentifier{Index: index},
+				Type:                "Transfer",
+				Status:              types.String("Success"),
+				Account:             &types.AccountIdentifier{Address: address},
+				Amount:              &types.Amount{Value: value, Currency: currency},
+			}
+		}
+
+		return &types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: hash},
+			Operations: []*types.Operation{
+				op(0, from, "-"+value),
+				op(1, to, value),
+			},
+		}
+	}
+	chain := func(
+		parent *types.BlockIdentifier,
+		suffix string,
+		transactions ...[]*types.Transaction,
+	) []*types.Block {
+		blocks := []*types.Block{}
+		for _, txs := range transactions {
+			identifier := &types.BlockIdentifier{
+				Index: parent.Index + 1,
+				Hash:  fmt.Sprintf("block %d%s", parent.Index+1, suffix),
+			}
+			blocks = append(blocks, &types.Block{
+				BlockIdentifier:       identifier,
+				ParentBlockIdentifier: parent,
+				Timestamp:             asserter.MinUnixEpoch + 1,
+				Transactions:          txs,
+			})
+			parent = identifier
+		}
+
+		return blocks
+	}
+
+	parent := &types.Block{
+		BlockIdentifier:       &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+		ParentBlockIdentifier: &types.BlockIdentifier{Index: 9, Hash: "block 9"},
+		Timestamp:             asserter.MinUnixEpoch + 1,
+		Transactions: []*types.Transaction{
+			transfer("tx0", "alice", "zed", "1000"),
+		},
+	}
+	canonical := chain(
+		parent.BlockIdentifier,
+		"a",
+		[]*types.Transaction{transfer("tx1", "alice", "bob", "50")},
+		[]*types.Transaction{transfer("tx2", "bob", "carol", "20")},
+	)
+	fork := chain(
+		parent.BlockIdentifier,
+		"b",
+		[]*types.Transaction{transfer("tx1", "alice", "bob", "50")},
+		[]*types.Transaction{transfer("tx3", "alice", "dave", "10")},
+		[]*types.Transaction{},
+	)
+
+	t.Run("consistent", func(t *testing.T) {
+		inconsistencies, err := SimulateReorg(ctx, a, parent, canonical, fork)
+		assert.NoError(t, err)
+		assert.Empty(t, inconsistencies)
+	})
+
+	t.Run("invalid fork", func(t *testing.T) {
+		inconsistencies, err := SimulateReorg(ctx, a, parent, canonical, fork[1:])
+		assert.Error(t, err)
+		assert.Nil(t, inconsistencies)
+	})
+
+	t.Run("inconsistent", func(t *testing.T) {
+		reorged, err := newReorgChain(ctx, a, parent)
+		assert.NoError(t, err)
+		defer reorged.close(ctx)
+
+		direct, err := newReorgChain(ctx, a, parent)
+		assert.NoError(t, err)
+		defer direct.close(ctx)
+
+		// Simulate balance storage that never reverted the
+		// balance changes of the last canonical block.
+		stale := *fork[1]
+		stale.Transactions = append(
+			append([]*types.Transaction{}, fork[1].Transactions...),
+			canonical[1].Transactions...,
+		)
+		for _, block := range []*types.Block{fork[0], &stale} {
+			assert.NoError(t, reorged.add(ctx, block))
+		}
+		for _, block := range fork[:2] {
+			assert.NoError(t, direct.add(ctx, block))
+		}
+
+		inconsistencies, err := compareReorgBalances(ctx, a, reorged, direct, 12, canonical, fork[:2])
+		assert.NoError(t, err)
+		assert.Equal(t, []*results.ReorgInconsistency{
+			{
+				Account:  &types.AccountIdentifier{Address: "carol"},
+				Currency: currency,
+				Reorged:  "20",
+				Expected: "0",
+			},
+			{
+				Account:  &types.AccountIdentifier{Address: "bob"},
+				Currency: currency,
+				Reorged:  "30",
+				Expected: "50",
+			},
+		}, inconsistencies)
+	})
+}
@@ -0,0 +1,460 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+)
+
+const (
+	// replayPrefix is the prefix of the broadcast
+	// identifier of each replayed transaction.
+	replayPrefix = "replay:"
+)
+
+// IsReplay returns a boolean indicating if a broadcast
+// identifier belongs to a replayed transaction (rather
+// than a job run by the coordinator).
+func IsReplay(identifier string) bool {
+	return strings.HasPrefix(identifier, replayPrefix)
+}
+
+// ReplaySample is a transaction observed by check:data
+// that can be replayed.
+type ReplaySample struct {
+	TransactionIdentifier *types.TransactionIdentifier
+
+	// Operations are the operations of the transaction
+	// that are replayed.
+	Operations []*types.Operation
+}
+
+// NewReplaySample returns a *ReplaySample of the operations in
+// transaction of operationTypes. If these operations do not net
+// to zero in a single currency or any of them spends or creates
+// a coin, nil is returned.
+func NewReplaySample(
+	transaction *types.Transaction,
+	operationTypes []string,
+) (*ReplaySample, error) {
+	var currency *types.Currency
+	net := new(big.Int)
+	operations := []*types.Operation{}
+	for _, op := range transaction.Operations {
+		if !utils.ContainsString(operationTypes, op.Type) || op.Amount == nil {
+			continue
+		}
+
+		if op.CoinChange != nil {
+			return nil, nil
+		}
+
+		if currency == nil {
+			currency = op.Amount.Currency
+		}
+
+		if types.Hash(currency) != types.Hash(op.Amount.Currency) {
+			return nil, nil
+		}
+
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+		}
+
+		net.Add(net, value)
+		operations = append(operations, op)
+	}
+
+	if len(operations) == 0 || net.Sign() != 0 {
+		return nil, nil
+	}
+
+	return &ReplaySample{
+		TransactionIdentifier: transaction.TransactionIdentifier,
+		Operations:            operations,
+	}, nil
+}
+
+// SampleTransactions returns a *ReplaySample of each replayable
+// transaction in the most recent blocks stored in blockStorage.
+// Sampling stops at the first block that is not stored (ex: if
+// it was pruned).
+func SampleTransactions(
+	ctx context.Context,
+	blockStorage *modules.BlockStorage,
+	blocks int64,
+	operationTypes []string,
+) ([]*ReplaySample, error) {
+	head, err := blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get head block", err)
+	}
+
+	samples := []*ReplaySample{}
+	for index := head.Index; index > head.Index-blocks && index >= 0; index-- {
+		i := index
+		block, err := blockStorage.GetBlock(ctx, &types.PartialBlockIdentifier{Index: &i})
+		if err != nil {
+			break
+		}
+
+		for _, transaction := range block.Transactions {
+			sample, err := NewReplaySample(transaction, operationTypes)
+			if err != nil {
+				return nil, err
+			}
+
+			if sample != nil {
+				samples = append(samples, sample)
+			}
+		}
+	}
+
+	return samples, nil
+}
+
+// ScaleAmount divides value by scale (rounding toward zero).
+// A non-zero value is never scaled to zero.
+func ScaleAmount(value *big.Int, scale *big.Int) *big.Int {
+	scaled := new(big.Int).Quo(value, scale)
+	if scaled.Sign() == 0 && value.Sign() != 0 {
+		scaled.SetInt64(int64(value.Sign()))
+	}
+
+	return scaled
+}
+
+// ReplayIntent returns the intent of a replay of sample in which
+// each account is replaced by its entry in accounts (keyed by
+// types.Hash of the original account). Amounts are scaled so that
+// they still net to zero. If this is not possible, nil is returned.
+func ReplayIntent(
+	sample *ReplaySample,
+	accounts map[string]*types.AccountIdentifier,
+	scale *big.Int,
+) ([]*types.Operation, error) {
+	intent := []*types.Operation{}
+	net := new(big.Int)
+	var last *types.Operation
+	for i, op := range sample.Operations {
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+		}
+
+		scaled := ScaleAmount(value, scale)
+		net.Add(net, scaled)
+		replayed := &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: int64(i)},
+			Type:                op.Type,
+			Account:             accounts[types.Hash(op.Account)],
+			Amount: &types.Amount{
+				Value:    scaled.String(),
+				Currency: op.Amount.Currency,
+			},
+		}
+		intent = append(intent, replayed)
+
+		if scaled.Sign() > 0 {
+			last = replayed
+		}
+	}
+
+	// Rounding can leave scaled amounts that don't net
+	// to zero, so the difference is taken from the
+	// last credit.
+	if net.Sign() != 0 && last != nil {
+		value, _ := types.AmountValue(last.Amount)
+		value.Sub(value, net)
+		if value.Sign() <= 0 {
+			return nil, nil
+		}
+
+		last.Amount.Value = value.String()
+	}
+
+	return intent, nil
+}
+
+// Replayer periodically constructs transactions modeled
+// on transactions sampled from a check:data database.
+type Replayer struct {
+	network           *types.NetworkIdentifier
+	helper            *CoordinatorHelper
+	samples           []*ReplaySample
+	scale             *big.Int
+	interval          time.Duration
+	confirmationDepth int64
+
+	// nextLock protects next.
+	nextLock sync.Mutex
+
+	// next is the index of the next sample to replay.
+	next int
+}
+
+// NewReplayer returns a new *Replayer.
+func NewReplayer(
+	network *types.NetworkIdentifier,
+	helper *CoordinatorHelper,
+	config *configuration.ReplayConfiguration,
+	samples []*ReplaySample,
+	confirmationDepth int64,
+) *Replayer {
+	// The scale is validated when the configuration is loaded.
+	scale, _ := new(big.Int).SetString(config.Scale, 10)
+
+	return &Replayer{
+		network:           network,
+		helper:            helper,
+		samples:           samples,
+		scale:             scale,
+		interval:          time.Duration(config.Interval) * time.Second,
+		confirmationDepth: confirmationDepth,
+	}
+}
+
+// Start replays a sample every configured interval
+// until ctx is canceled.
+func (r *Replayer) Start(ctx context.Context) error {
+	if len(r.samples) == 0 {
+		color.Yellow("no replayable transactions were sampled")
+		return nil
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := r.Replay(ctx); err != nil {
+				return fmt.Errorf("%w: unable to replay transaction", err)
+			}
+		}
+	}
+}
+
+func (r *Replayer) nextSample() *ReplaySample {
+	r.nextLock.Lock()
+	defer r.nextLock.Unlock()
+
+	sample := r.samples[r.next%len(r.samples)]
+	r.next++
+
+	return sample
+}
+
+// Replay constructs and broadcasts a replay of the next sample.
+// If there are not enough unlocked, funded accounts in the key
+// store to replay it, the sample is skipped and nil is returned.
+func (r *Replayer) Replay(ctx context.Context) (*types.TransactionIdentifier, error) {
+	if r.helper.Paused() {
+		return nil, nil
+	}
+
+	headBlock, err := r.helper.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil || headBlock == nil {
+		return nil, nil
+	}
+
+	sample := r.nextSample()
+
+	dbTx := r.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	accounts, err := r.assignAccounts(ctx, dbTx, sample)
+	if err != nil {
+		return nil, err
+	}
+
+	if accounts == nil {
+		return nil, nil
+	}
+
+	intent, err := ReplayIntent(sample, accounts, r.scale)
+	if err != nil {
+		return nil, err
+	}
+
+	if intent == nil {
+		return nil, nil
+	}
+
+	metadata, _, publicKeys, err := standaloneMetadata(ctx, dbTx, r.helper, r.network, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionIdentifier, err := standaloneBroadcast(
+		ctx,
+		dbTx,
+		r.helper,
+		r.network,
+		replayPrefix,
+		intent,
+		metadata,
+		publicKeys,
+		r.confirmationDepth,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit replay", err)
+	}
+
+	color.Cyan(
+		"replaying transaction %s in transaction %s",
+		sample.TransactionIdentifier.Hash,
+		transactionIdentifier.Hash,
+	)
+
+	return transactionIdentifier, nil
+}
+
+// assignAccounts maps each distinct account in sample to a distinct
+// unlocked account in the key store. Accounts that are debited are
+// only mapped to accounts with a balance larger than the (scaled)
+// amount they are debited. If there are not enough such accounts,
+// nil is returned.
+func (r *Replayer) assignAccounts(
+	ctx context.Context,
+	dbTx database.Transaction,
+	sample *ReplaySample,
+) (map[string]*types.AccountIdentifier, error) {
+	all, err := r.helper.AllAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	locked, err := r.helper.LockedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get locked accounts", err)
+	}
+
+	lockedAccounts := map[string]struct{}{}
+	for _, account := range locked {
+		lockedAccounts[types.Hash(account)] = struct{}{}
+	}
+
+	available := []*types.AccountIdentifier{}
+	for _, account := range all {
+		if _, ok := lockedAccounts[types.Hash(account)]; !ok {
+			available = append(available, account)
+		}
+	}
+
+	// Debits are summed per account so that senders
+	// are assigned before receivers.
+	debits := map[string]*big.Int{}
+	order := []string{}
+	var currency *types.Currency
+	for _, op := range sample.Operations {
+		key := types.Hash(op.Account)
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+		}
+		currency = op.Amount.Currency
+
+		if _, ok := debits[key]; !ok {
+			debits[key] = new(big.Int)
+			order = append(order, key)
+		}
+
+		if value.Sign() < 0 {
+			debits[key].Sub(debits[key], ScaleAmount(value, r.scale))
+		}
+	}
+
+	used := map[string]struct{}{}
+	accounts := map[string]*types.AccountIdentifier{}
+	for _, debited := range []bool{true, false} {
+		for _, key := range order {
+			if (debits[key].Sign() > 0) != debited {
+				continue
+			}
+
+			account, err := r.findAccount(ctx, dbTx, available, used, currency, debits[key])
+			if err != nil {
+				return nil, err
+			}
+
+			if account == nil {
+				return nil, nil
+			}
+
+			used[types.Hash(account)] = struct{}{}
+			accounts[key] = account
+		}
+	}
+
+	return accounts, nil
+}
+
+// findAccount returns the first unused account in available
+// with a balance larger than minimum (nil if none exists).
+func (r *Replayer) findAccount(
+	ctx context.Context,
+	dbTx database.Transaction,
+	available []*types.AccountIdentifier,
+	used map[string]struct{},
+	currency *types.Currency,
+	minimum *big.Int,
+) (*types.AccountIdentifier, error) {
+	for _, account := range available {
+		if _, ok := used[types.Hash(account)]; ok {
+			continue
+		}
+
+		if minimum.Sign() == 0 {
+			return account, nil
+		}
+
+		balance, err := r.helper.Balance(ctx, dbTx, account, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get balance", err)
+		}
+
+		value, err := types.AmountValue(balance)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse balance", err)
+		}
+
+		if value.Cmp(minimum) > 0 {
+			return account, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	replayCurrency = &types.Currency{Symbol: "ETH", Decimals: 18}
+)
+
+func replayOp(opType string, address string, value string) *types.Operation {
+	return &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{},
+		Type:                opType,
+		Status:              types.String("SUCCESS"),
+		Account:             &types.AccountIdentifier{Address: address},
+		Amount:              &types.Amount{Value: value, Currency: replayCurrency},
+	}
+}
+
+func TestNewReplaySample(t *testing.T) {
+	var tests = map[string]struct {
+		operations []*types.Operation
+		expected   int
+	}{
+		"transfer with fee": {
+			operations: []*types.Operation{
+				replayOp("CALL", "a", "-100"),
+				replayOp("CALL", "b", "100"),
+				replayOp("FEE", "a", "-1"),
+			},
+			expected: 2,
+		},
+		"unbalanced": {
+			operations: []*types.Operation{
+				replayOp("CALL", "a", "-100"),
+				replayOp("CALL", "b", "90"),
+			},
+		},
+		"no replayable operations": {
+			operations: []*types.Operation{
+				replayOp("FEE", "a", "-1"),
+			},
+		},
+		"spends coin": {
+			operations: []*types.Operation{
+				{
+					Type:    "CALL",
+					Account: &types.AccountIdentifier{Address: "a"},
+					Amount:  &types.Amount{Value: "0", Currency: replayCurrency},
+					CoinChange: &types.CoinChange{
+						CoinIdentifier: &types.CoinIdentifier{Identifier: "coin"},
+						CoinAction:     types.CoinSpent,
+					},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sample, err := NewReplaySample(&types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+				Operations:            test.operations,
+			}, []string{"CALL"})
+			assert.NoError(t, err)
+
+			if test.expected == 0 {
+				assert.Nil(t, sample)
+				return
+			}
+
+			assert.Len(t, sample.Operations, test.expected)
+		})
+	}
+}
+
+func TestReplayIntent(t *testing.T) {
+	assert.Equal(t, big.NewInt(3), ScaleAmount(big.NewInt(35), big.NewInt(10)))
+	assert.Equal(t, big.NewInt(-1), ScaleAmount(big.NewInt(-5), big.NewInt(10)))
+
+	sample := &ReplaySample{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+		Operations: []*types.Operation{
+			replayOp("CALL", "a", "-35"),
+			replayOp("CALL", "b", "15"),
+			replayOp("CALL", "c", "20"),
+		},
+	}
+	accounts := map[string]*types.AccountIdentifier{
+		types.Hash(&types.AccountIdentifier{Address: "a"}): {Address: "x"},
+		types.Hash(&types.AccountIdentifier{Address: "b"}): {Address: "y"},
+		types.Hash(&types.AccountIdentifier{Address: "c"}): {Address: "z"},
+	}
+
+	intent, err := ReplayIntent(sample, accounts, big.NewInt(10))
+	assert.NoError(t, err)
+
+	// -35/10 = -3, 15/10 = 1, and 20/10 = 2 so the last
+	// credit is reduced by 1 to net to zero.
+	assert.Equal(t, []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: "x"},
+			Amount:              &types.Amount{Value: "-3", Currency: replayCurrency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: "y"},
+			Amount:              &types.Amount{Value: "1", Currency: replayCurrency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 2},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: "z"},
+			Amount:              &types.Amount{Value: "2", Currency: replayCurrency},
+		},
+	}, intent)
+
+	assert.True(t, IsReplay(replayPrefix+"tx"))
+	assert.False(t, IsReplay("job"))
+}
+
+func TestSampleTransactions(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(db, 1)
+	parent := &types.BlockIdentifier{Index: 0, Hash: "block 0"}
+	for i := int64(0); i < 3; i++ {
+		block := &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{
+				Index: i,
+				Hash:  []string{"block 0", "block 1", "block 2"}[i],
+			},
+			ParentBlockIdentifier: parent,
+			Transactions: []*types.Transaction{
+				{
+					TransactionIdentifier: &types.TransactionIdentifier{
+						Hash: []string{"tx 0", "tx 1", "tx 2"}[i],
+					},
+					Operations: []*types.Operation{
+						replayOp("CALL", "a", "-100"),
+						replayOp("CALL", "b", "100"),
+					},
+				},
+			},
+		}
+		assert.NoError(t, blockStorage.SeeBlock(ctx, block))
+		assert.NoError(t, blockStorage.AddBlock(ctx, block))
+		parent = block.BlockIdentifier
+	}
+
+	samples, err := SampleTransactions(ctx, blockStorage, 2, []string{"CALL"})
+	assert.NoError(t, err)
+	assert.Len(t, samples, 2)
+	assert.Equal(t, "tx 2", samples[0].TransactionIdentifier.Hash)
+	assert.Equal(t, "tx 1", samples[1].TransactionIdentifier.Hash)
+}
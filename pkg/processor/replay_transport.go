@@ -0,0 +1,255 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	networkStatusPath  = "/network/status"
+	accountBalancePath = "/account/balance"
+)
+
+var _ http.RoundTripper = (*ReplayTransport)(nil)
+
+// ResponseMismatch is a pair of differing answers
+// to the same request issued over distinct connections.
+type ResponseMismatch struct {
+	Path    string
+	Request []byte
+	Primary []byte
+	Replay  []byte
+	Reason  string
+}
+
+// ReplayTransport is an http.RoundTripper that issues a
+// fraction of critical requests (/network/status and
+// /account/balance) a second time over a distinct connection
+// and flags any differing answers. This detects load balancers
+// that route requests for a single URL to node replicas
+// that disagree with each other.
+type ReplayTransport struct {
+	primary    http.RoundTripper
+	replay     http.RoundTripper
+	fraction   float64
+	onMismatch func(*ResponseMismatch)
+}
+
+// NewReplayTransport returns a new *ReplayTransport. onMismatch
+// is invoked each time replayed answers differ.
+func NewReplayTransport(
+	primary http.RoundTripper,
+	replay http.RoundTripper,
+	fraction float64,
+	onMismatch func(*ResponseMismatch),
+) *ReplayTransport {
+	return &ReplayTransport{
+		primary:    primary,
+		replay:     replay,
+		fraction:   fraction,
+		onMismatch: onMismatch,
+	}
+}
+
+// replayable returns the path of a request if it should be replayed.
+func (t *ReplayTransport) replayable(req *http.Request) (string, bool) {
+	if req.Method != http.MethodPost || req.Body == nil {
+		return "", false
+	}
+
+	var path string
+	switch {
+	case strings.HasSuffix(req.URL.Path, networkStatusPath):
+		path = networkStatusPath
+	case strings.HasSuffix(req.URL.Path, accountBalancePath):
+		path = accountBalancePath
+	default:
+		return "", false
+	}
+
+	return path, rand.Float64() < t.fraction // #nosec G404
+}
+
+// RoundTrip executes a single HTTP transaction. If the request
+// is sampled, it is issued concurrently over the replay transport
+// and the answers are compared. Replay failures are ignored: the
+// caller always receives the primary response.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, ok := t.replayable(req)
+	if !ok {
+		return t.primary.RoundTrip(req)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read request body", err)
+	}
+
+	replayReq := req.Clone(req.Context())
+	replayReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	replayed := make(chan []byte, 1)
+	go func() {
+		resp, err := t.replay.RoundTrip(replayReq)
+		if err != nil {
+			replayed <- nil
+			return
+		}
+
+		replayed <- readOK(resp)
+	}()
+
+	resp, err := t.primary.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	primaryBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read response body", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(primaryBody))
+
+	replayBody := <-replayed
+	if resp.StatusCode != http.StatusOK || replayBody == nil {
+		return resp, nil
+	}
+
+	if reason := CompareResponses(path, primaryBody, replayBody); len(reason) > 0 {
+		t.onMismatch(&ResponseMismatch{
+			Path:    path,
+			Request: body,
+			Primary: primaryBody,
+			Replay:  replayBody,
+			Reason:  reason,
+		})
+	}
+
+	return resp, nil
+}
+
+// readOK returns the body of a successful response
+// (nil if the request was not successful).
+func readOK(resp *http.Response) []byte {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil
+	}
+
+	return body
+}
+
+// CompareResponses returns a description of how two answers
+// to the same request are inconsistent (empty if they are
+// consistent). Answers are only considered inconsistent if
+// they can't both be correct: a replica that is a few blocks
+// behind another is not flagged, but replicas that disagree
+// on the hash of a block at the same index are.
+func CompareResponses(path string, primary []byte, replay []byte) string {
+	switch path {
+	case networkStatusPath:
+		return compareNetworkStatus(primary, replay)
+	case accountBalancePath:
+		return compareAccountBalance(primary, replay)
+	default:
+		return ""
+	}
+}
+
+func compareNetworkStatus(primary []byte, replay []byte) string {
+	var p, r types.NetworkStatusResponse
+	if json.Unmarshal(primary, &p) != nil || json.Unmarshal(replay, &r) != nil {
+		return ""
+	}
+
+	if types.Hash(p.GenesisBlockIdentifier) != types.Hash(r.GenesisBlockIdentifier) {
+		return fmt.Sprintf(
+			"genesis block %s != %s",
+			types.PrintStruct(p.GenesisBlockIdentifier),
+			types.PrintStruct(r.GenesisBlockIdentifier),
+		)
+	}
+
+	return compareBlocks("current block", p.CurrentBlockIdentifier, r.CurrentBlockIdentifier)
+}
+
+func compareAccountBalance(primary []byte, replay []byte) string {
+	var p, r types.AccountBalanceResponse
+	if json.Unmarshal(primary, &p) != nil || json.Unmarshal(replay, &r) != nil {
+		return ""
+	}
+
+	if reason := compareBlocks("balance block", p.BlockIdentifier, r.BlockIdentifier); len(reason) > 0 {
+		return reason
+	}
+
+	if p.BlockIdentifier == nil || r.BlockIdentifier == nil ||
+		p.BlockIdentifier.Index != r.BlockIdentifier.Index {
+		return ""
+	}
+
+	// Implementations may return balances in any order.
+	if types.Hash(sortAmounts(p.Balances)) != types.Hash(sortAmounts(r.Balances)) {
+		return fmt.Sprintf(
+			"balances at block %d %s != %s",
+			p.BlockIdentifier.Index,
+			types.PrintStruct(p.Balances),
+			types.PrintStruct(r.Balances),
+		)
+	}
+
+	return ""
+}
+
+// sortAmounts returns a copy of amounts sorted by currency.
+func sortAmounts(amounts []*types.Amount) []*types.Amount {
+	sorted := make([]*types.Amount, len(amounts))
+	copy(sorted, amounts)
+	sort.Slice(sorted, func(i, j int) bool {
+		return types.Hash(sorted[i].Currency) < types.Hash(sorted[j].Currency)
+	})
+
+	return sorted
+}
+
+// compareBlocks returns a description of the inconsistency
+// if two block identifiers have the same index but a
+// different hash.
+func compareBlocks(name string, p *types.BlockIdentifier, r *types.BlockIdentifier) string {
+	if p == nil || r == nil || p.Index != r.Index || p.Hash == r.Hash {
+		return ""
+	}
+
+	return fmt.Sprintf("%s %d has hash %s != %s", name, p.Index, p.Hash, r.Hash)
+}
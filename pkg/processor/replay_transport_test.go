@@ -0,0 +1,160 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func staticResponse(body string) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		_, _ = ioutil.ReadAll(req.Body)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+		}, nil
+	}
+}
+
+func TestCompareResponses(t *testing.T) {
+	var tests = map[string]struct {
+		path    string
+		primary string
+		replay  string
+
+		inconsistent bool
+	}{
+		"identical status": {
+			path:    networkStatusPath,
+			primary: `{"current_block_identifier":{"index":10,"hash":"a"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+			replay:  `{"current_block_identifier":{"index":10,"hash":"a"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+		},
+		"lagging replica status": {
+			path:    networkStatusPath,
+			primary: `{"current_block_identifier":{"index":10,"hash":"a"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+			replay:  `{"current_block_identifier":{"index":9,"hash":"b"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+		},
+		"forked replica status": {
+			path:         networkStatusPath,
+			primary:      `{"current_block_identifier":{"index":10,"hash":"a"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+			replay:       `{"current_block_identifier":{"index":10,"hash":"b"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+			inconsistent: true,
+		},
+		"different genesis": {
+			path:         networkStatusPath,
+			primary:      `{"current_block_identifier":{"index":10,"hash":"a"},"genesis_block_identifier":{"index":0,"hash":"g"}}`,
+			replay:       `{"current_block_identifier":{"index":9,"hash":"b"},"genesis_block_identifier":{"index":0,"hash":"h"}}`,
+			inconsistent: true,
+		},
+		"identical balance": {
+			path:    accountBalancePath,
+			primary: `{"block_identifier":{"index":10,"hash":"a"},"balances":[{"value":"100","currency":{"symbol":"BTC","decimals":8}}]}`,
+			replay:  `{"block_identifier":{"index":10,"hash":"a"},"balances":[{"value":"100","currency":{"symbol":"BTC","decimals":8}}]}`,
+		},
+		"balance at different blocks": {
+			path:    accountBalancePath,
+			primary: `{"block_identifier":{"index":10,"hash":"a"},"balances":[{"value":"100","currency":{"symbol":"BTC","decimals":8}}]}`,
+			replay:  `{"block_identifier":{"index":9,"hash":"b"},"balances":[{"value":"90","currency":{"symbol":"BTC","decimals":8}}]}`,
+		},
+		"different balance": {
+			path:         accountBalancePath,
+			primary:      `{"block_identifier":{"index":10,"hash":"a"},"balances":[{"value":"100","currency":{"symbol":"BTC","decimals":8}}]}`,
+			replay:       `{"block_identifier":{"index":10,"hash":"a"},"balances":[{"value":"90","currency":{"symbol":"BTC","decimals":8}}]}`,
+			inconsistent: true,
+		},
+		"reordered balances": {
+			path: accountBalancePath,
+			primary: `{"block_identifier":{"index":10,"hash":"a"},"balances":[` +
+				`{"value":"100","currency":{"symbol":"BTC","decimals":8}},` +
+				`{"value":"5","currency":{"symbol":"ETH","decimals":18}}]}`,
+			replay: `{"block_identifier":{"index":10,"hash":"a"},"balances":[` +
+				`{"value":"5","currency":{"symbol":"ETH","decimals":18}},` +
+				`{"value":"100","currency":{"symbol":"BTC","decimals":8}}]}`,
+		},
+		"unparsable": {
+			path:    accountBalancePath,
+			primary: `{`,
+			replay:  `{"block_identifier":{"index":10,"hash":"a"}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reason := CompareResponses(test.path, []byte(test.primary), []byte(test.replay))
+			assert.Equal(t, test.inconsistent, len(reason) > 0, reason)
+		})
+	}
+}
+
+func TestReplayTransport(t *testing.T) {
+	primaryBody := `{"current_block_identifier":{"index":10,"hash":"a"},"genesis_block_identifier":{"index":0,"hash":"g"}}`
+	replayBody := `{"current_block_identifier":{"index":10,"hash":"b"},"genesis_block_identifier":{"index":0,"hash":"g"}}`
+
+	newRequest := func(path string) *http.Request {
+		req, err := http.NewRequest(
+			http.MethodPost,
+			"http://localhost:8080"+path,
+			bytes.NewBufferString(`{"network_identifier":{}}`),
+		)
+		assert.NoError(t, err)
+		return req
+	}
+
+	t.Run("mismatch flagged", func(t *testing.T) {
+		mismatches := []*ResponseMismatch{}
+		transport := NewReplayTransport(
+			staticResponse(primaryBody),
+			staticResponse(replayBody),
+			1,
+			func(m *ResponseMismatch) { mismatches = append(mismatches, m) },
+		)
+
+		resp, err := transport.RoundTrip(newRequest(networkStatusPath))
+		assert.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, primaryBody, string(body))
+
+		assert.Len(t, mismatches, 1)
+		assert.Equal(t, networkStatusPath, mismatches[0].Path)
+		assert.Equal(t, `{"network_identifier":{}}`, string(mismatches[0].Request))
+	})
+
+	t.Run("other paths not replayed", func(t *testing.T) {
+		transport := NewReplayTransport(
+			staticResponse(primaryBody),
+			roundTripFunc(func(*http.Request) (*http.Response, error) {
+				t.Fatal("unexpected replay")
+				return nil, nil
+			}),
+			1,
+			func(*ResponseMismatch) { t.Fatal("unexpected mismatch") },
+		)
+
+		_, err := transport.RoundTrip(newRequest("/block"))
+		assert.NoError(t, err)
+	})
+}
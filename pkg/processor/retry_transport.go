@@ -0,0 +1,273 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// RetryPolicy determines which failed requests are
+// retried and how long to wait between retries.
+type RetryPolicy struct {
+	MaxRetries  uint64
+	MaxElapsed  time.Duration
+	Interval    time.Duration
+	MaxInterval time.Duration
+	Multiplier  float64
+	Jitter      float64
+
+	RetriableStatusCodes map[int]struct{}
+	RetriableErrorCodes  map[int32]struct{}
+	FatalErrorCodes      map[int32]struct{}
+}
+
+// NewRetryPolicy returns the *RetryPolicy of config
+// (nil if config.Retry is not populated).
+func NewRetryPolicy(config *configuration.Configuration) *RetryPolicy {
+	if config.Retry == nil {
+		return nil
+	}
+
+	policy := &RetryPolicy{
+		MaxRetries:  config.MaxRetries,
+		MaxElapsed:  time.Duration(config.RetryElapsedTime) * time.Second,
+		Interval:    time.Duration(config.Retry.IntervalMS) * time.Millisecond,
+		MaxInterval: time.Duration(config.Retry.MaxIntervalMS) * time.Millisecond,
+		Multiplier:  config.Retry.Multiplier,
+		Jitter:      config.Retry.Jitter,
+
+		RetriableStatusCodes: map[int]struct{}{},
+		RetriableErrorCodes:  map[int32]struct{}{},
+		FatalErrorCodes:      map[int32]struct{}{},
+	}
+	for _, code := range config.Retry.RetriableStatusCodes {
+		policy.RetriableStatusCodes[code] = struct{}{}
+	}
+
+	for _, code := range config.Retry.RetriableErrorCodes {
+		policy.RetriableErrorCodes[code] = struct{}{}
+	}
+
+	for _, code := range config.Retry.FatalErrorCodes {
+		policy.FatalErrorCodes[code] = struct{}{}
+	}
+
+	return policy
+}
+
+// retriableError returns a boolean indicating if
+// rosettaErr should be retried.
+func (p *RetryPolicy) retriableError(rosettaErr *types.Error) bool {
+	if _, ok := p.FatalErrorCodes[rosettaErr.Code]; ok {
+		return false
+	}
+
+	if _, ok := p.RetriableErrorCodes[rosettaErr.Code]; ok {
+		return true
+	}
+
+	return rosettaErr.Retriable
+}
+
+// wait returns the wait before retry attempt (starting
+// at 0) with jitter applied.
+func (p *RetryPolicy) wait(attempt int) time.Duration {
+	wait := float64(p.Interval)
+	for i := 0; i < attempt && wait < float64(p.MaxInterval); i++ {
+		wait *= p.Multiplier
+	}
+
+	if wait > float64(p.MaxInterval) {
+		wait = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		// Randomize wait in [wait * (1 - jitter), wait * (1 + jitter)).
+		delta := p.Jitter * wait
+		wait = wait - delta + rand.Float64()*2*delta // #nosec G404
+	}
+
+	return time.Duration(wait)
+}
+
+// RetryFetcherOptions returns the fetcher options that must be
+// applied (after all other options) when config.Retry is populated.
+// Requests are then retried by RetryTransport and, because the
+// backoff of the fetcher cannot be configured, the fetcher gives
+// up on the first retriable error instead of retrying it again.
+func RetryFetcherOptions(config *configuration.Configuration) []fetcher.Option {
+	if config.Retry == nil {
+		return nil
+	}
+
+	return []fetcher.Option{
+		fetcher.WithMaxRetries(1),
+		fetcher.WithRetryElapsedTime(time.Nanosecond),
+	}
+}
+
+var _ http.RoundTripper = (*RetryTransport)(nil)
+
+// RetryTransport is an http.RoundTripper that retries failed
+// requests according to a *RetryPolicy. Each attempt times out
+// separately, so the HTTP timeout of a client that uses this
+// transport should not be set.
+type RetryTransport struct {
+	base    http.RoundTripper
+	policy  *RetryPolicy
+	timeout time.Duration
+}
+
+// NewRetryTransport returns a new *RetryTransport. Each
+// attempt times out after timeout (if it is populated).
+func NewRetryTransport(
+	base http.RoundTripper,
+	policy *RetryPolicy,
+	timeout time.Duration,
+) *RetryTransport {
+	return &RetryTransport{
+		base:    base,
+		policy:  policy,
+		timeout: timeout,
+	}
+}
+
+// attempt executes a single HTTP transaction with its
+// own timeout and reads the entire response body.
+func (t *RetryTransport) attempt(req *http.Request, body []byte) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+	}
+	defer cancel()
+
+	attemptReq := req.Clone(ctx)
+	if body != nil {
+		attemptReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := t.base.RoundTrip(attemptReq)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read response body", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	return resp, nil
+}
+
+// retriable returns a boolean indicating if the outcome
+// of an attempt should be retried. If resp carries a Rosetta
+// error that is marked retriable but is fatal according to
+// the policy, it is marked not retriable (so that the caller
+// does not retry it either).
+func (t *RetryTransport) retriable(resp *http.Response, err error) (bool, string) {
+	if err != nil {
+		return true, err.Error()
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		return false, ""
+	}
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if resp.StatusCode != http.StatusInternalServerError {
+		_, ok := t.policy.RetriableStatusCodes[resp.StatusCode]
+		return ok, fmt.Sprintf("status code %d", resp.StatusCode)
+	}
+
+	var rosettaErr types.Error
+	if json.Unmarshal(body, &rosettaErr) != nil {
+		return false, ""
+	}
+
+	if t.policy.retriableError(&rosettaErr) {
+		return true, types.PrintStruct(&rosettaErr)
+	}
+
+	if rosettaErr.Retriable {
+		rosettaErr.Retriable = false
+		if marked, err := json.Marshal(&rosettaErr); err == nil {
+			resp.Body = ioutil.NopCloser(bytes.NewReader(marked))
+			resp.ContentLength = int64(len(marked))
+		}
+	}
+
+	return false, ""
+}
+
+// RoundTrip executes a single HTTP transaction, retrying
+// it with backoff until it succeeds, fails with an error
+// that is not retriable, or retries are exhausted.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read request body", err)
+		}
+	}
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req, body)
+		retry, reason := t.retriable(resp, err)
+		if !retry || req.Context().Err() != nil || uint64(attempt) >= t.policy.MaxRetries {
+			return resp, err
+		}
+
+		wait := t.policy.wait(attempt)
+		if t.policy.MaxElapsed > 0 && time.Since(start)+wait > t.policy.MaxElapsed {
+			return resp, err
+		}
+
+		log.Printf(
+			"%s: retrying %s after %fs (prior attempts: %d)\n",
+			reason,
+			req.URL.Path,
+			wait.Seconds(),
+			attempt+1,
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransport(t *testing.T) {
+	policy := NewRetryPolicy(&configuration.Configuration{
+		MaxRetries: 3,
+		Retry: &configuration.RetryConfiguration{
+			IntervalMS:           1,
+			Multiplier:           2,
+			MaxIntervalMS:        2,
+			RetriableStatusCodes: []int{http.StatusServiceUnavailable},
+			RetriableErrorCodes:  []int32{1},
+			FatalErrorCodes:      []int32{2},
+		},
+	})
+
+	rosettaError := func(code int32, retriable bool) *http.Response {
+		body, _ := json.Marshal(&types.Error{
+			Code:      code,
+			Message:   "retry test error",
+			Retriable: retriable,
+		})
+
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		}
+	}
+
+	var tests = map[string]struct {
+		responses []func() (*http.Response, error)
+
+		attempts   int
+		statusCode int
+		retriable  bool
+		err        bool
+	}{
+		"success": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+					}, nil
+				},
+			},
+			attempts:   1,
+			statusCode: http.StatusOK,
+		},
+		"retriable status code": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusServiceUnavailable,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+					}, nil
+				},
+				func() (*http.Response, error) {
+					return nil, errors.New("connection reset by peer")
+				},
+				func() (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("{}")),
+					}, nil
+				},
+			},
+			attempts:   3,
+			statusCode: http.StatusOK,
+		},
+		"status code not retriable": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusBadGateway,
+						Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+					}, nil
+				},
+			},
+			attempts:   1,
+			statusCode: http.StatusBadGateway,
+		},
+		"retriable error code": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return rosettaError(1, false), nil },
+			},
+			attempts:   4,
+			statusCode: http.StatusInternalServerError,
+		},
+		"fatal error code": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return rosettaError(2, true), nil },
+			},
+			attempts:   1,
+			statusCode: http.StatusInternalServerError,
+		},
+		"error marked retriable": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) { return rosettaError(3, true), nil },
+			},
+			attempts:   4,
+			statusCode: http.StatusInternalServerError,
+			retriable:  true,
+		},
+		"retries exhausted": {
+			responses: []func() (*http.Response, error){
+				func() (*http.Response, error) {
+					return nil, errors.New("EOF")
+				},
+			},
+			attempts: 4,
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			transport := NewRetryTransport(roundTripFunc(
+				func(req *http.Request) (*http.Response, error) {
+					body, err := ioutil.ReadAll(req.Body)
+					assert.NoError(t, err)
+					assert.Equal(t, `{"index":1}`, string(body))
+
+					response := test.responses[len(test.responses)-1]
+					if attempts < len(test.responses) {
+						response = test.responses[attempts]
+					}
+					attempts++
+
+					return response()
+				},
+			), policy, time.Minute)
+
+			req, err := http.NewRequest(
+				http.MethodPost,
+				"http://localhost:8080/block",
+				bytes.NewBufferString(`{"index":1}`),
+			)
+			assert.NoError(t, err)
+
+			resp, err := transport.RoundTrip(req)
+			assert.Equal(t, test.attempts, attempts)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.statusCode, resp.StatusCode)
+			if resp.StatusCode == http.StatusInternalServerError {
+				var rosettaErr types.Error
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&rosettaErr))
+				assert.Equal(t, test.retriable, rosettaErr.Retriable)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	policy := &RetryPolicy{
+		Interval:    100 * time.Millisecond,
+		MaxInterval: time.Second,
+		Multiplier:  3,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, policy.wait(0))
+	assert.Equal(t, 300*time.Millisecond, policy.wait(1))
+	assert.Equal(t, 900*time.Millisecond, policy.wait(2))
+	assert.Equal(t, time.Second, policy.wait(3))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		wait := policy.wait(0)
+		assert.GreaterOrEqual(t, int64(wait), int64(50*time.Millisecond))
+		assert.Less(t, int64(wait), int64(150*time.Millisecond))
+	}
+}
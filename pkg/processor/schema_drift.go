@@ -0,0 +1,160 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+// constructionPathPrefix is the prefix of the path
+// of each construction endpoint.
+const constructionPathPrefix = "/construction/"
+
+var _ http.RoundTripper = (*SchemaDriftTransport)(nil)
+
+// SchemaDriftTransport is an http.RoundTripper that records the
+// fields of each successful construction response and warns when
+// a response has fields that the first response of its endpoint
+// did not have (or is missing fields it had). This catches
+// implementations with divergent code paths for different
+// transaction shapes.
+type SchemaDriftTransport struct {
+	base http.RoundTripper
+}
+
+// NewSchemaDriftTransport returns a new *SchemaDriftTransport.
+func NewSchemaDriftTransport(base http.RoundTripper) *SchemaDriftTransport {
+	return &SchemaDriftTransport{base: base}
+}
+
+// RoundTrip executes a single HTTP transaction and records
+// the fields of any successful construction response.
+func (t *SchemaDriftTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := strings.Index(req.URL.Path, constructionPathPrefix)
+	if i < 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	endpoint := req.URL.Path[i:]
+	if endpoint == constructionParse {
+		endpoint = parseEndpoint(parseRequestSigned(req))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	fields, err := SchemaFields(body)
+	if err != nil {
+		// The response is rejected when it is asserted.
+		return resp, nil
+	}
+
+	appeared, disappeared := results.RecordResponseSchema(endpoint, fields)
+	if len(appeared) > 0 || len(disappeared) > 0 {
+		color.Yellow(
+			"%s response drifted from its first response (appeared: %s, disappeared: %s)",
+			endpoint,
+			types.PrintStruct(appeared),
+			types.PrintStruct(disappeared),
+		)
+	}
+
+	return resp, nil
+}
+
+// parseRequestSigned returns the value of signed in the
+// body of a /construction/parse request (without consuming
+// the body of req).
+func parseRequestSigned(req *http.Request) bool {
+	if req.GetBody == nil {
+		return false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+
+	var parseRequest types.ConstructionParseRequest
+	if err := json.NewDecoder(body).Decode(&parseRequest); err != nil {
+		return false
+	}
+
+	return parseRequest.Signed
+}
+
+// SchemaFields returns the path of every field populated in the
+// JSON object body (ex: "operations[].account.address"). Elements
+// of an array share a path and null fields are treated as missing.
+func SchemaFields(body []byte) ([]string, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]struct{}{}
+	fields := []string{}
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		if value == nil {
+			return
+		}
+
+		if len(path) > 0 {
+			if _, ok := seen[path]; !ok {
+				seen[path] = struct{}{}
+				fields = append(fields, path)
+			}
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				childPath := key
+				if len(path) > 0 {
+					childPath = path + "." + key
+				}
+
+				walk(childPath, child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(path+"[]", child)
+			}
+		}
+	}
+	walk("", value)
+
+	return fields, nil
+}
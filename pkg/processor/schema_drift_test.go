@@ -0,0 +1,107 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaFields(t *testing.T) {
+	fields, err := SchemaFields([]byte(`{
+		"operations": [
+			{"type": "transfer", "account": {"address": "addr1"}},
+			{"type": "fee", "amount": {"value": "-1"}, "metadata": null}
+		],
+		"signers": [],
+		"metadata": {"memo": "hello"}
+	}`))
+	assert.NoError(t, err)
+	sort.Strings(fields)
+	assert.Equal(t, []string{
+		"metadata",
+		"metadata.memo",
+		"operations",
+		"operations[]",
+		"operations[].account",
+		"operations[].account.address",
+		"operations[].amount",
+		"operations[].amount.value",
+		"operations[].type",
+		"signers",
+	}, fields)
+
+	_, err = SchemaFields([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestSchemaDriftTransport(t *testing.T) {
+	responses := []string{
+		`{"operations": [{"type": "transfer"}], "metadata": {}}`,
+		`{"operations": [{"type": "transfer"}], "account_identifier_signers": [{"address": "a"}]}`,
+		`{"operations": [{"type": "transfer"}], "metadata": {}}`,
+	}
+	transport := NewSchemaDriftTransport(roundTripFunc(
+		func(req *http.Request) (*http.Response, error) {
+			body := responses[0]
+			responses = responses[1:]
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		},
+	))
+
+	parse := func(signed string) {
+		req, err := http.NewRequest(
+			http.MethodPost,
+			"http://localhost:8080/construction/parse",
+			bytes.NewBufferString(`{"signed": `+signed+`, "transaction": "tx"}`),
+		)
+		assert.NoError(t, err)
+
+		resp, err := transport.RoundTrip(req)
+		assert.NoError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "transfer")
+	}
+
+	// Signed and unsigned responses have distinct schemas
+	parse("false")
+	parse("true")
+	parse("false")
+
+	found := 0
+	for _, stats := range results.SchemaDrift() {
+		switch stats.Endpoint {
+		case "/construction/parse (unsigned)":
+			found++
+			assert.Equal(t, int64(2), stats.Responses)
+			assert.Equal(t, int64(0), stats.Drifted)
+		case "/construction/parse (signed)":
+			found++
+			assert.Equal(t, int64(1), stats.Responses)
+		}
+	}
+	assert.Equal(t, 2, found)
+}
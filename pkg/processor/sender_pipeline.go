@@ -0,0 +1,582 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// pipelinePrefix is the prefix of the broadcast
+	// identifier of each pipeline transfer.
+	pipelinePrefix = "pipeline:"
+
+	// pipelineWaitTime is how long a pipeline waits
+	// before looking for a sender again when it could
+	// not transfer.
+	pipelineWaitTime = 5 * time.Second
+)
+
+var (
+	// errPipelineAccountLocked is returned when the sender or
+	// recipient of a constructed transfer was locked (ex: by
+	// a workflow) while the transfer was being constructed.
+	errPipelineAccountLocked = errors.New("pipeline account locked during construction")
+)
+
+// IsPipelineTransfer returns a boolean indicating if the
+// broadcast with identifier is a pipeline transfer.
+func IsPipelineTransfer(identifier string) bool {
+	return strings.HasPrefix(identifier, pipelinePrefix)
+}
+
+// AccountShard returns the shard (in [0, shards)) an
+// account is assigned to.
+func AccountShard(account *types.AccountIdentifier, shards int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(types.Hash(account)))
+
+	return int(h.Sum32() % uint32(shards))
+}
+
+// PipelineIntent returns the intent of a transfer of
+// amount from sender to recipient.
+func PipelineIntent(
+	operationType string,
+	sender *types.AccountIdentifier,
+	recipient *types.AccountIdentifier,
+	currency *types.Currency,
+	amount *big.Int,
+) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                operationType,
+			Account:             sender,
+			Amount: &types.Amount{
+				Value:    new(big.Int).Neg(amount).String(),
+				Currency: currency,
+			},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			RelatedOperations: []*types.OperationIdentifier{
+				{Index: 0},
+			},
+			Type:    operationType,
+			Account: recipient,
+			Amount: &types.Amount{
+				Value:    amount.String(),
+				Currency: currency,
+			},
+		},
+	}
+}
+
+// SenderPipelines run workers that each construct transfers from
+// a disjoint shard of the accounts in the key store. Unlike the
+// coordinator, pipelines only hold the database write lock while
+// selecting accounts and enqueueing broadcasts (not while calling
+// the Construction API), so transfers are constructed in parallel.
+type SenderPipelines struct {
+	network           *types.NetworkIdentifier
+	helper            *CoordinatorHelper
+	tagStorage        *KeyTagStorage
+	config            *configuration.SenderPipelineConfiguration
+	minAmount         *big.Int
+	maxAmount         *big.Int
+	reserve           *big.Int
+	confirmationDepth int64
+
+	// claimedLock protects claimed.
+	claimedLock sync.Mutex
+
+	// claimed are the accounts used by transfers that
+	// are being constructed (and are not yet locked
+	// by broadcast storage).
+	claimed map[string]struct{}
+
+	// lastSenders is the hash of the last sender chosen
+	// in each shard (used by RoundRobinSenderSelection).
+	lastSenders map[int]string
+
+	// senderUses records when each account was last chosen
+	// as a sender (used by LeastRecentlyUsedSenderSelection).
+	// Uses are ordered by a counter instead of a timestamp
+	// so that ties can't occur.
+	senderUses map[string]uint64
+	uses       uint64
+
+	// addressBook are addresses (ex: shared testnet accounts)
+	// that can be chosen as recipients in addition to the
+	// accounts in the key store.
+	addressBook []*AddressBookEntry
+}
+
+// senderCandidate is an account with sufficient
+// balance to send a transfer.
+type senderCandidate struct {
+	account *types.AccountIdentifier
+	balance *big.Int
+}
+
+// NewSenderPipelines returns a new *SenderPipelines.
+func NewSenderPipelines(
+	network *types.NetworkIdentifier,
+	helper *CoordinatorHelper,
+	tagStorage *KeyTagStorage,
+	config *configuration.SenderPipelineConfiguration,
+	addressBook []*AddressBookEntry,
+	confirmationDepth int64,
+) *SenderPipelines {
+	// Amounts are validated when the configuration is loaded.
+	minAmount, _ := types.BigInt(config.MinAmount)
+	maxAmount, _ := types.BigInt(config.MaxAmount)
+	reserve := new(big.Int)
+	if len(config.Reserve) > 0 {
+		reserve, _ = types.BigInt(config.Reserve)
+	}
+
+	return &SenderPipelines{
+		network:           network,
+		helper:            helper,
+		tagStorage:        tagStorage,
+		config:            config,
+		minAmount:         minAmount,
+		maxAmount:         maxAmount,
+		reserve:           reserve,
+		confirmationDepth: confirmationDepth,
+		claimed:           map[string]struct{}{},
+		lastSenders:       map[int]string{},
+		senderUses:        map[string]uint64{},
+		addressBook:       addressBook,
+	}
+}
+
+// Start runs all pipelines until ctx is canceled
+// or a pipeline returns an error.
+func (p *SenderPipelines) Start(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for shard := 0; shard < p.config.Workers; shard++ {
+		shard := shard
+		g.Go(func() error {
+			return p.run(ctx, shard)
+		})
+	}
+
+	return g.Wait()
+}
+
+func (p *SenderPipelines) run(ctx context.Context, shard int) error {
+	for ctx.Err() == nil {
+		var transactionIdentifier *types.TransactionIdentifier
+		var err error
+		if release := p.helper.reserveTransfer(ctx); release != nil {
+			transactionIdentifier, err = p.Transfer(ctx, shard)
+			release()
+			if err != nil && !errors.Is(err, errPipelineAccountLocked) {
+				return fmt.Errorf("%w: pipeline %d unable to transfer", err, shard)
+			}
+		}
+
+		if transactionIdentifier != nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(pipelineWaitTime):
+		}
+	}
+
+	return ctx.Err()
+}
+
+// Transfer constructs a transfer from a sender in shard and
+// enqueues its broadcast. If there is no unlocked sender with a
+// sufficient balance in shard (or no unlocked recipient), nil is
+// returned.
+func (p *SenderPipelines) Transfer(
+	ctx context.Context,
+	shard int,
+) (*types.TransactionIdentifier, error) {
+	amount := p.randomAmount()
+	sender, recipient, err := p.claimAccounts(ctx, shard, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if sender == nil {
+		return nil, nil
+	}
+	defer p.release(sender, recipient)
+
+	intent := PipelineIntent(
+		p.config.OperationType,
+		sender,
+		recipient,
+		p.config.Currency,
+		amount,
+	)
+
+	readTx := p.helper.database.ReadTransaction(ctx)
+	metadata, _, publicKeys, err := standaloneMetadata(ctx, readTx, p.helper, p.network, intent)
+	readTx.Discard(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionIdentifier, networkTransaction, err := standaloneConstruct(
+		ctx,
+		p.helper,
+		p.network,
+		intent,
+		metadata,
+		publicKeys,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The coordinator may have locked the sender or recipient
+	// while we were constructing the transfer.
+	dbTx := p.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	locked, err := p.lockedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range []*types.AccountIdentifier{sender, recipient} {
+		if _, ok := locked[types.Hash(account)]; ok {
+			return nil, fmt.Errorf(
+				"%w: %s",
+				errPipelineAccountLocked,
+				types.PrintStruct(account),
+			)
+		}
+	}
+
+	if err := p.helper.Broadcast(
+		ctx,
+		dbTx,
+		pipelinePrefix+transactionIdentifier.Hash,
+		p.network,
+		intent,
+		transactionIdentifier,
+		networkTransaction,
+		p.confirmationDepth,
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to enqueue broadcast", err)
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%w: unable to commit pipeline transfer", err)
+	}
+
+	color.Cyan(
+		"pipeline %d transferring %s from %s to %s in transaction %s",
+		shard,
+		amount.String(),
+		sender.Address,
+		recipient.Address,
+		transactionIdentifier.Hash,
+	)
+
+	return transactionIdentifier, nil
+}
+
+// randomAmount returns an amount in [minAmount, maxAmount].
+func (p *SenderPipelines) randomAmount() *big.Int {
+	spread := new(big.Int).Sub(p.maxAmount, p.minAmount)
+	if spread.Sign() == 0 {
+		return new(big.Int).Set(p.minAmount)
+	}
+
+	offset := new(big.Int).Rand(
+		rand.New(rand.NewSource(rand.Int63())), // #nosec G404
+		new(big.Int).Add(spread, big.NewInt(1)),
+	)
+
+	return offset.Add(offset, p.minAmount)
+}
+
+func (p *SenderPipelines) lockedAccounts(
+	ctx context.Context,
+	dbTx database.Transaction,
+) (map[string]struct{}, error) {
+	locked, err := p.helper.LockedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get locked accounts", err)
+	}
+
+	lockedAccounts := map[string]struct{}{}
+	for _, account := range locked {
+		lockedAccounts[types.Hash(account)] = struct{}{}
+	}
+
+	return lockedAccounts, nil
+}
+
+// claimAccounts claims an unlocked account in shard with a
+// balance of at least amount plus the reserve (chosen with the
+// configured sender selection) as the sender and a random
+// unlocked account as the recipient. If no such accounts exist,
+// nil is returned.
+func (p *SenderPipelines) claimAccounts(
+	ctx context.Context,
+	shard int,
+	amount *big.Int,
+) (*types.AccountIdentifier, *types.AccountIdentifier, error) {
+	// Balances are written the first time they are
+	// fetched, so we use a write transaction.
+	dbTx := p.helper.DatabaseTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	all, err := p.helper.AllAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to get accounts", err)
+	}
+
+	locked, err := p.lockedAccounts(ctx, dbTx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.claimedLock.Lock()
+	defer p.claimedLock.Unlock()
+
+	known := map[string]struct{}{}
+	available := []*types.AccountIdentifier{}
+	for _, account := range all {
+		key := types.Hash(account)
+		known[key] = struct{}{}
+		if _, ok := locked[key]; ok {
+			continue
+		}
+
+		if _, ok := p.claimed[key]; ok {
+			continue
+		}
+
+		available = append(available, account)
+	}
+
+	minimum := new(big.Int).Add(amount, p.reserve)
+	candidates := []*senderCandidate{}
+	for _, account := range available {
+		if AccountShard(account, p.config.Workers) != shard {
+			continue
+		}
+
+		tagged, err := p.tagStorage.HasAnyTag(ctx, dbTx, account, p.config.SenderTags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to check sender tags", err)
+		}
+
+		if !tagged {
+			continue
+		}
+
+		balance, err := p.helper.Balance(ctx, dbTx, account, p.config.Currency)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to get balance", err)
+		}
+
+		value, err := types.AmountValue(balance)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to parse balance", err)
+		}
+
+		if value.Cmp(minimum) < 0 {
+			continue
+		}
+
+		candidates = append(candidates, &senderCandidate{account: account, balance: value})
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil, nil
+	}
+
+	sender := p.selectSender(shard, candidates)
+
+	recipients := []*types.AccountIdentifier{}
+	for _, account := range available {
+		if types.Hash(account) == types.Hash(sender) {
+			continue
+		}
+
+		tagged, err := p.tagStorage.HasAnyTag(ctx, dbTx, account, p.config.RecipientTags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: unable to check recipient tags", err)
+		}
+
+		if tagged {
+			recipients = append(recipients, account)
+		}
+	}
+
+	recipients = append(recipients, p.addressBookRecipients(known, locked)...)
+	if len(recipients) == 0 {
+		return nil, nil, nil
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("%w: unable to commit balances", err)
+	}
+
+	recipient := recipients[rand.Intn(len(recipients))] // #nosec G404
+	p.claimed[types.Hash(sender)] = struct{}{}
+	p.claimed[types.Hash(recipient)] = struct{}{}
+
+	p.recordSender(shard, sender)
+
+	return sender, recipient, nil
+}
+
+// addressBookRecipients returns the accounts in the address
+// book that are not in the key store (known), locked, or claimed
+// and have any of the recipient tags. It must be called while
+// holding claimedLock.
+func (p *SenderPipelines) addressBookRecipients(
+	known map[string]struct{},
+	locked map[string]struct{},
+) []*types.AccountIdentifier {
+	recipients := []*types.AccountIdentifier{}
+	for _, entry := range p.addressBook {
+		account := &types.AccountIdentifier{Address: entry.Address}
+		key := types.Hash(account)
+		if _, ok := known[key]; ok {
+			continue
+		}
+
+		if _, ok := locked[key]; ok {
+			continue
+		}
+
+		if _, ok := p.claimed[key]; ok {
+			continue
+		}
+
+		if anyTag(entry.Tags, p.config.RecipientTags) {
+			recipients = append(recipients, account)
+		}
+	}
+
+	return recipients
+}
+
+// recordSender records that sender was chosen in shard. It
+// must be called while holding claimedLock.
+func (p *SenderPipelines) recordSender(shard int, sender *types.AccountIdentifier) {
+	p.uses++
+	p.senderUses[types.Hash(sender)] = p.uses
+	p.lastSenders[shard] = types.Hash(sender)
+}
+
+// selectSender chooses a sender among candidates with the
+// configured sender selection. It must be called while
+// holding claimedLock.
+func (p *SenderPipelines) selectSender(
+	shard int,
+	candidates []*senderCandidate,
+) *types.AccountIdentifier {
+	// Candidates are sorted by hash so that selection does
+	// not depend on the order of accounts in key storage.
+	sort.Slice(candidates, func(i, j int) bool {
+		return types.Hash(candidates[i].account) < types.Hash(candidates[j].account)
+	})
+
+	switch p.config.SenderSelection {
+	case configuration.WeightedSenderSelection:
+		total := new(big.Int)
+		for _, candidate := range candidates {
+			total.Add(total, candidate.balance)
+		}
+
+		// All candidates have a positive balance unless the
+		// amount and reserve are 0 (which is not allowed).
+		if total.Sign() <= 0 {
+			return candidates[0].account
+		}
+
+		target := new(big.Int).Rand(rand.New(rand.NewSource(rand.Int63())), total) // #nosec G404
+		for _, candidate := range candidates {
+			if target.Cmp(candidate.balance) < 0 {
+				return candidate.account
+			}
+
+			target.Sub(target, candidate.balance)
+		}
+
+		return candidates[len(candidates)-1].account
+	case configuration.RoundRobinSenderSelection:
+		last := p.lastSenders[shard]
+		for _, candidate := range candidates {
+			if types.Hash(candidate.account) > last {
+				return candidate.account
+			}
+		}
+
+		return candidates[0].account
+	case configuration.LeastRecentlyUsedSenderSelection:
+		sender := candidates[0]
+		for _, candidate := range candidates[1:] {
+			if p.senderUses[types.Hash(candidate.account)] <
+				p.senderUses[types.Hash(sender.account)] {
+				sender = candidate
+			}
+		}
+
+		return sender.account
+	default:
+		sender := candidates[0]
+		for _, candidate := range candidates[1:] {
+			if candidate.balance.Cmp(sender.balance) > 0 {
+				sender = candidate
+			}
+		}
+
+		return sender.account
+	}
+}
+
+// release releases the claims on accounts.
+func (p *SenderPipelines) release(accounts ...*types.AccountIdentifier) {
+	p.claimedLock.Lock()
+	defer p.claimedLock.Unlock()
+
+	for _, account := range accounts {
+		delete(p.claimed, types.Hash(account))
+	}
+}
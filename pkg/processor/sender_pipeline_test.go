@@ -0,0 +1,146 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountShard(t *testing.T) {
+	counts := make([]int, 4)
+	for i := 0; i < 100; i++ {
+		account := &types.AccountIdentifier{Address: fmt.Sprintf("addr%d", i)}
+		shard := AccountShard(account, 4)
+		assert.True(t, shard >= 0 && shard < 4)
+		assert.Equal(t, shard, AccountShard(account, 4))
+		counts[shard]++
+	}
+
+	for _, count := range counts {
+		assert.True(t, count > 0)
+	}
+
+	assert.Equal(t, 0, AccountShard(&types.AccountIdentifier{Address: "addr"}, 1))
+}
+
+func TestPipelineIntent(t *testing.T) {
+	currency := &types.Currency{Symbol: "ETH", Decimals: 18}
+	sender := &types.AccountIdentifier{Address: "sender"}
+	recipient := &types.AccountIdentifier{Address: "recipient"}
+
+	intent := PipelineIntent("transfer", sender, recipient, currency, big.NewInt(100))
+	assert.Equal(t, []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "transfer",
+			Account:             sender,
+			Amount:              &types.Amount{Value: "-100", Currency: currency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			RelatedOperations:   []*types.OperationIdentifier{{Index: 0}},
+			Type:                "transfer",
+			Account:             recipient,
+			Amount:              &types.Amount{Value: "100", Currency: currency},
+		},
+	}, intent)
+
+	assert.True(t, IsPipelineTransfer(pipelinePrefix+"1"))
+	assert.False(t, IsPipelineTransfer("job"))
+}
+
+func TestPipelineRandomAmount(t *testing.T) {
+	p := &SenderPipelines{minAmount: big.NewInt(10), maxAmount: big.NewInt(20)}
+	for i := 0; i < 50; i++ {
+		amount := p.randomAmount()
+		assert.True(t, amount.Cmp(p.minAmount) >= 0)
+		assert.True(t, amount.Cmp(p.maxAmount) <= 0)
+	}
+
+	p.maxAmount = big.NewInt(10)
+	assert.Equal(t, big.NewInt(10), p.randomAmount())
+}
+
+func TestSelectSender(t *testing.T) {
+	accounts := []*types.AccountIdentifier{}
+	for i := 0; i < 3; i++ {
+		accounts = append(accounts, &types.AccountIdentifier{Address: fmt.Sprintf("addr%d", i)})
+	}
+
+	candidates := func() []*senderCandidate {
+		return []*senderCandidate{
+			{account: accounts[2], balance: big.NewInt(10)},
+			{account: accounts[0], balance: big.NewInt(30)},
+			{account: accounts[1], balance: big.NewInt(20)},
+		}
+	}
+
+	newPipelines := func(selection configuration.SenderSelection) *SenderPipelines {
+		return &SenderPipelines{
+			config:      &configuration.SenderPipelineConfiguration{SenderSelection: selection},
+			lastSenders: map[int]string{},
+			senderUses:  map[string]uint64{},
+		}
+	}
+
+	// selectN chooses (and records) n senders.
+	selectN := func(p *SenderPipelines, n int) map[string]int {
+		chosen := map[string]int{}
+		for i := 0; i < n; i++ {
+			sender := p.selectSender(0, candidates())
+			p.recordSender(0, sender)
+			chosen[sender.Address]++
+		}
+
+		return chosen
+	}
+
+	t.Run("highest balance", func(t *testing.T) {
+		p := newPipelines(configuration.HighestBalanceSenderSelection)
+		assert.Equal(t, map[string]int{"addr0": 5}, selectN(p, 5))
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		p := newPipelines(configuration.WeightedSenderSelection)
+		chosen := selectN(p, 600)
+		assert.Len(t, chosen, 3)
+		assert.True(t, chosen["addr0"] > chosen["addr2"])
+	})
+
+	t.Run("round robin", func(t *testing.T) {
+		p := newPipelines(configuration.RoundRobinSenderSelection)
+		assert.Equal(t, map[string]int{"addr0": 2, "addr1": 2, "addr2": 2}, selectN(p, 6))
+	})
+
+	t.Run("least recently used", func(t *testing.T) {
+		p := newPipelines(configuration.LeastRecentlyUsedSenderSelection)
+		assert.Equal(t, map[string]int{"addr0": 2, "addr1": 2, "addr2": 2}, selectN(p, 6))
+
+		// A new account is chosen before all others
+		newAccount := &types.AccountIdentifier{Address: "addr3"}
+		sender := p.selectSender(0, append(candidates(), &senderCandidate{
+			account: newAccount,
+			balance: big.NewInt(1),
+		}))
+		assert.Equal(t, newAccount, sender)
+	})
+}
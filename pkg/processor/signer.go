@@ -0,0 +1,189 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// Signer signs payloads returned by /construction/payloads.
+// *modules.KeyStorage signs with locally stored keys.
+type Signer interface {
+	Sign(ctx context.Context, payloads []*types.SigningPayload) ([]*types.Signature, error)
+}
+
+var _ Signer = (*RemoteSigner)(nil)
+
+// KeyGetter returns the stored key of an account.
+// *modules.KeyStorage is a KeyGetter.
+type KeyGetter interface {
+	Get(ctx context.Context, account *types.AccountIdentifier) (*keys.KeyPair, error)
+}
+
+// RemoteSignRequest is sent to a remote signing service.
+type RemoteSignRequest struct {
+	Payloads []*types.SigningPayload `json:"payloads"`
+}
+
+// RemoteSignResponse is returned by a remote signing service.
+type RemoteSignResponse struct {
+	Signatures []*types.Signature `json:"signatures"`
+}
+
+// RemoteSigner is a Signer backed by an external signing
+// service (ex: one fronting an HSM) so that private keys
+// never need to be provided to the cli.
+type RemoteSigner struct {
+	url    string
+	client *http.Client
+
+	// keys returns the public key of each account, which
+	// each returned signature must be made with.
+	keys KeyGetter
+}
+
+// NewRemoteSigner returns a new *RemoteSigner.
+func NewRemoteSigner(
+	url string,
+	timeout time.Duration,
+	proxy *Proxy,
+	keys KeyGetter,
+) *RemoteSigner {
+	return &RemoteSigner{
+		url:    url,
+		client: NewHTTPClient(timeout, proxy),
+		keys:   keys,
+	}
+}
+
+// Sign requests a signature for each payload from the
+// remote signing service.
+func (s *RemoteSigner) Sign(
+	ctx context.Context,
+	payloads []*types.SigningPayload,
+) ([]*types.Signature, error) {
+	body, err := json.Marshal(&RemoteSignRequest{Payloads: payloads})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to marshal sign request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to create sign request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to send sign request", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read sign response", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(
+			"remote signer returned status %d: %s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	var signResponse RemoteSignResponse
+	if err := json.Unmarshal(respBody, &signResponse); err != nil {
+		return nil, fmt.Errorf("%w: unable to unmarshal sign response", err)
+	}
+
+	if err := assertSignatures(ctx, s.keys, payloads, signResponse.Signatures); err != nil {
+		return nil, fmt.Errorf("%w: remote signer returned invalid signatures", err)
+	}
+
+	return signResponse.Signatures, nil
+}
+
+// assertSignatures returns an error if signatures does
+// not contain a valid signature for each payload (in order).
+// Signatures are verified so that an invalid signature
+// from an external signer is reported before
+// /construction/combine. If keyGetter is not nil, each signature
+// must also be made with the stored public key of the
+// account of its payload.
+func assertSignatures(
+	ctx context.Context,
+	keyGetter KeyGetter,
+	payloads []*types.SigningPayload,
+	signatures []*types.Signature,
+) error {
+	if len(signatures) != len(payloads) {
+		return fmt.Errorf(
+			"%d signatures returned for %d payloads",
+			len(signatures),
+			len(payloads),
+		)
+	}
+
+	for i, signature := range signatures {
+		if signature == nil || types.Hash(signature.SigningPayload) != types.Hash(payloads[i]) {
+			return fmt.Errorf("signature %d is for a different payload", i)
+		}
+
+		if signature.PublicKey == nil {
+			return fmt.Errorf("signature %d is missing a public key", i)
+		}
+
+		if keyGetter != nil {
+			stored, err := keyGetter.Get(ctx, payloads[i].AccountIdentifier)
+			if err != nil {
+				return fmt.Errorf(
+					"%w: unable to get key of %s",
+					err,
+					types.AccountString(payloads[i].AccountIdentifier),
+				)
+			}
+
+			if types.Hash(stored.PublicKey) != types.Hash(signature.PublicKey) {
+				return fmt.Errorf(
+					"signature %d is made with public key %s instead of the key of %s",
+					i,
+					types.PrintStruct(signature.PublicKey),
+					types.AccountString(payloads[i].AccountIdentifier),
+				)
+			}
+		}
+
+		verifier, err := (&keys.KeyPair{PublicKey: signature.PublicKey}).Signer()
+		if err != nil {
+			return fmt.Errorf("%w: unable to verify signature %d", err, i)
+		}
+
+		if err := verifier.Verify(signature); err != nil {
+			return fmt.Errorf("%w: signature %d is invalid", err, i)
+		}
+	}
+
+	return nil
+}
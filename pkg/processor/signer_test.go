@@ -0,0 +1,152 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/keys"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	keyPair, err := keys.GenerateKeypair(types.Secp256k1)
+	assert.NoError(t, err)
+	signer, err := keyPair.Signer()
+	assert.NoError(t, err)
+
+	other, err := keys.GenerateKeypair(types.Secp256k1)
+	assert.NoError(t, err)
+	otherSigner, err := other.Signer()
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	// Only the public key of the account is stored.
+	keyStorage := modules.NewKeyStorage(db)
+	assert.NoError(t, keyStorage.Store(
+		ctx,
+		&types.AccountIdentifier{Address: "addr1"},
+		&keys.KeyPair{PublicKey: keyPair.PublicKey},
+	))
+
+	payloads := []*types.SigningPayload{
+		{
+			AccountIdentifier: &types.AccountIdentifier{Address: "addr1"},
+			Bytes:             bytes.Repeat([]byte{1}, 32),
+			SignatureType:     types.Ecdsa,
+		},
+	}
+
+	var tests = map[string]struct {
+		status   int
+		mutate   func(*types.Signature)
+		wrongKey bool
+		wrongLen bool
+
+		err bool
+	}{
+		"valid": {
+			status: http.StatusOK,
+		},
+		"error status": {
+			status: http.StatusInternalServerError,
+			err:    true,
+		},
+		"wrong payload": {
+			status: http.StatusOK,
+			mutate: func(s *types.Signature) {
+				s.SigningPayload = &types.SigningPayload{Bytes: []byte("other")}
+			},
+			err: true,
+		},
+		"invalid signature": {
+			status: http.StatusOK,
+			mutate: func(s *types.Signature) {
+				s.Bytes[0] ^= 1
+			},
+			err: true,
+		},
+		"signature of other key": {
+			status:   http.StatusOK,
+			wrongKey: true,
+			err:      true,
+		},
+		"missing signature": {
+			status:   http.StatusOK,
+			wrongLen: true,
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+
+				var req RemoteSignRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, payloads, req.Payloads)
+
+				w.WriteHeader(test.status)
+				signatures := []*types.Signature{}
+				if !test.wrongLen {
+					payloadSigner := signer
+					if test.wrongKey {
+						payloadSigner = otherSigner
+					}
+					signature, err := payloadSigner.Sign(req.Payloads[0], req.Payloads[0].SignatureType)
+					assert.NoError(t, err)
+					if test.mutate != nil {
+						test.mutate(signature)
+					}
+					signatures = append(signatures, signature)
+				}
+				assert.NoError(t, json.NewEncoder(w).Encode(&RemoteSignResponse{
+					Signatures: signatures,
+				}))
+			}))
+			defer server.Close()
+
+			remoteSigner := NewRemoteSigner(server.URL, time.Second, nil, keyStorage)
+			signatures, err := remoteSigner.Sign(ctx, payloads)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, signatures, 1)
+			assert.Equal(t, keyPair.PublicKey, signatures[0].PublicKey)
+		})
+	}
+}
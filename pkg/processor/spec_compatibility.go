@@ -0,0 +1,135 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// specVersionPrefix is the major and minor version of
+// the Rosetta API supported by the rosetta-cli.
+const specVersionPrefix = "1.4."
+
+// SpecFeature is a version-specific feature of the Rosetta
+// API that check:data or check:construction relies on.
+type SpecFeature struct {
+	Name string `json:"name"`
+
+	// Introduced is the first version of the Rosetta API
+	// with the feature.
+	Introduced string `json:"introduced"`
+
+	// Translated indicates if the responses of implementations
+	// written against an older version are translated into the
+	// current shape (instead of the feature being unsupported).
+	Translated bool `json:"translated"`
+
+	Description string `json:"description"`
+}
+
+// SpecFeatures is the compatibility matrix of the
+// version-specific features relied on by the rosetta-cli.
+var SpecFeatures = []*SpecFeature{
+	{
+		Name:       "construction_account_identifiers",
+		Introduced: "1.4.4",
+		Translated: true,
+		Description: "/construction/derive, /construction/payloads, and /construction/parse " +
+			"return account identifiers (the deprecated address and signers fields are read instead)",
+	},
+	{
+		Name:       "suggested_fee",
+		Introduced: "1.4.5",
+		Description: "/construction/metadata returns suggested_fee (workflows that use " +
+			"suggested_fee require construction.fee_estimation)",
+	},
+	{
+		Name:       "timestamp_start_index",
+		Introduced: "1.4.8",
+		Translated: true,
+		Description: "/network/options returns timestamp_start_index (block timestamps " +
+			"are validated after the genesis block instead)",
+	},
+}
+
+// ParseSpecVersion returns the patch number of a version
+// of the Rosetta API. Only versions between 1.4.0 and the
+// version supported by the rosetta-cli are valid.
+func ParseSpecVersion(version string) (int, error) {
+	latest, err := parsePatch(types.RosettaAPIVersion)
+	if err != nil {
+		return -1, err
+	}
+
+	patch, err := parsePatch(version)
+	if err != nil {
+		return -1, err
+	}
+
+	if patch > latest {
+		return -1, fmt.Errorf(
+			"spec version %s is newer than the supported version %s",
+			version,
+			types.RosettaAPIVersion,
+		)
+	}
+
+	return patch, nil
+}
+
+func parsePatch(version string) (int, error) {
+	if !strings.HasPrefix(version, specVersionPrefix) {
+		return -1, fmt.Errorf("spec version %s is not a %sx version", version, specVersionPrefix)
+	}
+
+	patch, err := strconv.Atoi(strings.TrimPrefix(version, specVersionPrefix))
+	if err != nil || patch < 0 {
+		return -1, fmt.Errorf("spec version %s has an invalid patch version", version)
+	}
+
+	return patch, nil
+}
+
+// SpecCompatibility returns the features in SpecFeatures that
+// are translated and that are unsupported when testing an
+// implementation written against version.
+func SpecCompatibility(version string) ([]*SpecFeature, []*SpecFeature, error) {
+	patch, err := ParseSpecVersion(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	translated := []*SpecFeature{}
+	unsupported := []*SpecFeature{}
+	for _, feature := range SpecFeatures {
+		// Features are only listed with valid versions.
+		introduced, _ := parsePatch(feature.Introduced)
+		if patch >= introduced {
+			continue
+		}
+
+		if feature.Translated {
+			translated = append(translated, feature)
+		} else {
+			unsupported = append(unsupported, feature)
+		}
+	}
+
+	return translated, unsupported, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecCompatibility(t *testing.T) {
+	var tests = map[string]struct {
+		version string
+
+		translated  []string
+		unsupported []string
+		err         bool
+	}{
+		"latest": {
+			version:     types.RosettaAPIVersion,
+			translated:  []string{},
+			unsupported: []string{},
+		},
+		"before timestamp start index": {
+			version:     "1.4.7",
+			translated:  []string{"timestamp_start_index"},
+			unsupported: []string{},
+		},
+		"before account identifiers": {
+			version:     "1.4.3",
+			translated:  []string{"construction_account_identifiers", "timestamp_start_index"},
+			unsupported: []string{"suggested_fee"},
+		},
+		"newer than supported": {
+			version: "1.4.99",
+			err:     true,
+		},
+		"unsupported minor version": {
+			version: "1.5.0",
+			err:     true,
+		},
+		"invalid patch": {
+			version: "1.4.x",
+			err:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			translated, unsupported, err := SpecCompatibility(test.version)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			names := func(features []*SpecFeature) []string {
+				n := []string{}
+				for _, feature := range features {
+					n = append(n, feature.Name)
+				}
+
+				return n
+			}
+			assert.Equal(t, test.translated, names(translated))
+			assert.Equal(t, test.unsupported, names(unsupported))
+		})
+	}
+}
+
+func TestSpecFeatureVersions(t *testing.T) {
+	for _, feature := range SpecFeatures {
+		_, err := ParseSpecVersion(feature.Introduced)
+		assert.NoError(t, err, feature.Name)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// standaloneMetadata calls /construction/preprocess and
+// /construction/metadata for an intent constructed outside
+// of the coordinator (ex: a dust consolidation) and returns
+// the metadata, the suggested fee, and the public keys of
+// all accounts that must sign.
+func standaloneMetadata(
+	ctx context.Context,
+	dbTx database.Transaction,
+	helper *CoordinatorHelper,
+	network *types.NetworkIdentifier,
+	intent []*types.Operation,
+) (map[string]interface{}, []*types.Amount, []*types.PublicKey, error) {
+	options, requiredAccounts, err := helper.Preprocess(ctx, network, intent, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to preprocess intent", err)
+	}
+
+	publicKeys := make([]*types.PublicKey, len(requiredAccounts))
+	for i, account := range requiredAccounts {
+		keyPair, err := helper.GetKey(ctx, dbTx, account)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf(
+				"%w: unable to get key for %s",
+				err,
+				types.PrintStruct(account),
+			)
+		}
+
+		publicKeys[i] = keyPair.PublicKey
+	}
+
+	metadata, suggestedFee, err := helper.Metadata(ctx, network, options, publicKeys)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: unable to fetch metadata", err)
+	}
+
+	return metadata, suggestedFee, publicKeys, nil
+}
+
+// standaloneConstruct constructs, signs, and hashes a transaction
+// for an intent constructed outside of the coordinator.
+func standaloneConstruct(
+	ctx context.Context,
+	helper *CoordinatorHelper,
+	network *types.NetworkIdentifier,
+	intent []*types.Operation,
+	metadata map[string]interface{},
+	publicKeys []*types.PublicKey,
+) (*types.TransactionIdentifier, string, error) {
+	unsignedTransaction, payloads, err := helper.Payloads(
+		ctx,
+		network,
+		intent,
+		metadata,
+		publicKeys,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unable to construct payloads", err)
+	}
+
+	signatures, err := helper.Sign(ctx, payloads)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unable to sign payloads", err)
+	}
+
+	networkTransaction, err := helper.Combine(ctx, network, unsignedTransaction, signatures)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unable to combine signatures", err)
+	}
+
+	transactionIdentifier, err := helper.Hash(ctx, network, networkTransaction)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unable to hash transaction", err)
+	}
+
+	return transactionIdentifier, networkTransaction, nil
+}
+
+// standaloneBroadcast constructs and signs a transaction for an
+// intent constructed outside of the coordinator and enqueues its
+// broadcast with identifier prefix followed by its hash.
+func standaloneBroadcast(
+	ctx context.Context,
+	dbTx database.Transaction,
+	helper *CoordinatorHelper,
+	network *types.NetworkIdentifier,
+	prefix string,
+	intent []*types.Operation,
+	metadata map[string]interface{},
+	publicKeys []*types.PublicKey,
+	confirmationDepth int64,
+) (*types.TransactionIdentifier, error) {
+	transactionIdentifier, networkTransaction, err := standaloneConstruct(
+		ctx,
+		helper,
+		network,
+		intent,
+		metadata,
+		publicKeys,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := helper.Broadcast(
+		ctx,
+		dbTx,
+		prefix+transactionIdentifier.Hash,
+		network,
+		intent,
+		transactionIdentifier,
+		networkTransaction,
+		confirmationDepth,
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to enqueue broadcast", err)
+	}
+
+	return transactionIdentifier, nil
+}
@@ -0,0 +1,375 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+)
+
+const (
+	// stateCommitmentPollInterval is how often the head block
+	// is checked for a new state commitment checkpoint.
+	stateCommitmentPollInterval = 10 * time.Second
+
+	// sparseMerkleDepth is the depth of a sparse merkle
+	// tree (one level for each bit of a key).
+	sparseMerkleDepth = sha256.Size * 8
+
+	// sparseMerkleLeafPrefix and sparseMerkleNodePrefix
+	// domain separate leaf and inner node hashes.
+	sparseMerkleLeafPrefix = 0x00
+	sparseMerkleNodePrefix = 0x01
+)
+
+// StateBalance is the non-zero balance of an account
+// at a state commitment checkpoint.
+type StateBalance struct {
+	Account  *types.AccountIdentifier
+	Currency *types.Currency
+	Value    *big.Int
+}
+
+// StateCommitmentVerifier validates the balances computed by
+// check:data against the state commitment of a chain.
+type StateCommitmentVerifier interface {
+	// Verify returns a boolean indicating if block contains a
+	// state commitment and an error if balances (all non-zero
+	// balances at block) do not match it.
+	Verify(ctx context.Context, block *types.Block, balances []*StateBalance) (bool, error)
+}
+
+var _ StateCommitmentVerifier = (*SparseMerkleVerifier)(nil)
+
+// SparseMerkleVerifier is a StateCommitmentVerifier for chains
+// that commit to the root of a sparse merkle tree of balances in
+// block metadata.
+//
+// The tree has a leaf for each of the 2^256 possible keys. The key
+// of a balance is the SHA-256 of the JSON of its types.AccountCurrency
+// (see types.Hash) and its leaf is
+// SHA-256(0x00 || key || big-endian value). Inner nodes are
+// SHA-256(0x01 || left || right), where the left child holds keys
+// with a 0 bit at the node's depth (most significant bit first).
+// Empty leaves are 32 zero bytes.
+type SparseMerkleVerifier struct {
+	metadataKey string
+}
+
+// NewSparseMerkleVerifier returns a new *SparseMerkleVerifier
+// that reads the hex-encoded root from metadataKey in block
+// metadata.
+func NewSparseMerkleVerifier(metadataKey string) *SparseMerkleVerifier {
+	return &SparseMerkleVerifier{metadataKey: metadataKey}
+}
+
+// Verify compares the sparse merkle root of balances to the
+// root in the metadata of block.
+func (v *SparseMerkleVerifier) Verify(
+	ctx context.Context,
+	block *types.Block,
+	balances []*StateBalance,
+) (bool, error) {
+	value, ok := block.Metadata[v.metadataKey]
+	if !ok {
+		return false, nil
+	}
+
+	expected, ok := value.(string)
+	if !ok {
+		return false, fmt.Errorf(
+			"%w: %s in block %d metadata is not a string",
+			results.ErrStateCommitmentMismatch,
+			v.metadataKey,
+			block.BlockIdentifier.Index,
+		)
+	}
+
+	root, err := SparseMerkleRoot(balances)
+	if err != nil {
+		return false, err
+	}
+
+	expected = strings.ToLower(strings.TrimPrefix(expected, "0x"))
+	if root != expected {
+		return false, fmt.Errorf(
+			"%w: computed root %s of %d balances but block %d committed to %s",
+			results.ErrStateCommitmentMismatch,
+			root,
+			len(balances),
+			block.BlockIdentifier.Index,
+			expected,
+		)
+	}
+
+	return true, nil
+}
+
+// sparseMerkleLeaf is a populated leaf of a sparse merkle tree.
+type sparseMerkleLeaf struct {
+	key  []byte
+	hash []byte
+}
+
+// SparseMerkleRoot returns the hex-encoded root of the sparse
+// merkle tree of balances (see SparseMerkleVerifier).
+func SparseMerkleRoot(balances []*StateBalance) (string, error) {
+	leaves := make([]*sparseMerkleLeaf, 0, len(balances))
+	for _, balance := range balances {
+		if balance.Value.Sign() < 0 {
+			return "", fmt.Errorf(
+				"balance of %s is negative",
+				types.PrintStruct(balance.Account),
+			)
+		}
+		if balance.Value.Sign() == 0 {
+			continue
+		}
+
+		key, err := hex.DecodeString(types.Hash(&types.AccountCurrency{
+			Account:  balance.Account,
+			Currency: balance.Currency,
+		}))
+		if err != nil {
+			return "", fmt.Errorf("%w: unable to decode key", err)
+		}
+
+		leaves = append(leaves, &sparseMerkleLeaf{
+			key:  key,
+			hash: hashSparseMerkle(sparseMerkleLeafPrefix, key, balance.Value.Bytes()),
+		})
+	}
+
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].key, leaves[j].key) < 0
+	})
+	for i := 1; i < len(leaves); i++ {
+		if bytes.Equal(leaves[i-1].key, leaves[i].key) {
+			return "", errors.New("duplicate balance")
+		}
+	}
+
+	return hex.EncodeToString(
+		sparseMerkleSubtree(leaves, 0, sparseMerkleEmptyHashes()),
+	), nil
+}
+
+// hashSparseMerkle returns the SHA-256 of prefix
+// followed by parts.
+func hashSparseMerkle(prefix byte, parts ...[]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	for _, part := range parts {
+		h.Write(part)
+	}
+
+	return h.Sum(nil)
+}
+
+// sparseMerkleEmptyHashes returns the hash of an empty
+// subtree rooted at each depth.
+func sparseMerkleEmptyHashes() [][]byte {
+	empty := make([][]byte, sparseMerkleDepth+1)
+	empty[sparseMerkleDepth] = make([]byte, sha256.Size)
+	for depth := sparseMerkleDepth - 1; depth >= 0; depth-- {
+		empty[depth] = hashSparseMerkle(
+			sparseMerkleNodePrefix,
+			empty[depth+1],
+			empty[depth+1],
+		)
+	}
+
+	return empty
+}
+
+// sparseMerkleSubtree returns the hash of the subtree rooted at
+// depth that contains leaves (sorted by key).
+func sparseMerkleSubtree(leaves []*sparseMerkleLeaf, depth int, empty [][]byte) []byte {
+	if len(leaves) == 0 {
+		return empty[depth]
+	}
+	if depth == sparseMerkleDepth {
+		return leaves[0].hash
+	}
+
+	// Leaves with a 0 bit at depth sort before
+	// leaves with a 1 bit.
+	split := sort.Search(len(leaves), func(i int) bool {
+		return leaves[i].key[depth/8]&(0x80>>(depth%8)) != 0
+	})
+
+	return hashSparseMerkle(
+		sparseMerkleNodePrefix,
+		sparseMerkleSubtree(leaves[:split], depth+1, empty),
+		sparseMerkleSubtree(leaves[split:], depth+1, empty),
+	)
+}
+
+// StateCommitmentChecker verifies the balances of all accounts
+// against the state commitment of the latest synced checkpoint
+// (every interval blocks).
+type StateCommitmentChecker struct {
+	blockStorage   *modules.BlockStorage
+	balanceStorage *modules.BalanceStorage
+	counterStorage *modules.CounterStorage
+	verifier       StateCommitmentVerifier
+	interval       int64
+
+	lastCheckpoint int64
+}
+
+// NewStateCommitmentChecker returns a new *StateCommitmentChecker.
+func NewStateCommitmentChecker(
+	blockStorage *modules.BlockStorage,
+	balanceStorage *modules.BalanceStorage,
+	counterStorage *modules.CounterStorage,
+	verifier StateCommitmentVerifier,
+	interval int64,
+) *StateCommitmentChecker {
+	return &StateCommitmentChecker{
+		blockStorage:   blockStorage,
+		balanceStorage: balanceStorage,
+		counterStorage: counterStorage,
+		verifier:       verifier,
+		interval:       interval,
+		lastCheckpoint: -1,
+	}
+}
+
+// Start verifies each new checkpoint until a mismatch
+// is found or ctx is canceled.
+func (c *StateCommitmentChecker) Start(ctx context.Context) error {
+	tc := time.NewTicker(stateCommitmentPollInterval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tc.C:
+		}
+
+		if err := c.Check(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Check verifies the latest synced checkpoint (if it has not
+// already been verified). Errors reading storage are logged
+// (ex: the checkpoint was pruned) and only mismatches are
+// returned.
+func (c *StateCommitmentChecker) Check(ctx context.Context) error {
+	head, err := c.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return nil
+	}
+
+	checkpoint := head.Index - head.Index%c.interval
+	if checkpoint <= c.lastCheckpoint {
+		return nil
+	}
+	c.lastCheckpoint = checkpoint
+
+	block, err := c.blockStorage.GetBlock(
+		ctx,
+		&types.PartialBlockIdentifier{Index: &checkpoint},
+	)
+	if err != nil {
+		log.Printf("%s: unable to get checkpoint block %d\n", err.Error(), checkpoint)
+		return nil
+	}
+
+	balances, err := c.balances(ctx, checkpoint)
+	if err != nil {
+		log.Printf("%s: unable to get balances at checkpoint %d\n", err.Error(), checkpoint)
+		return nil
+	}
+
+	verified, err := c.verifier.Verify(ctx, block, balances)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return nil
+	}
+
+	color.Cyan(
+		"Verified state commitment of %d balances at block %d",
+		len(balances),
+		checkpoint,
+	)
+	_, _ = c.counterStorage.Update(ctx, results.StateCommitmentsCounter, big.NewInt(1))
+	return nil
+}
+
+// balances returns all non-zero balances at index.
+func (c *StateCommitmentChecker) balances(
+	ctx context.Context,
+	index int64,
+) ([]*StateBalance, error) {
+	accounts, err := c.balanceStorage.GetAllAccountCurrency(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := []*StateBalance{}
+	for _, account := range accounts {
+		amount, err := c.balanceStorage.GetBalance(
+			ctx,
+			account.Account,
+			account.Currency,
+			index,
+		)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get balance of %s",
+				err,
+				types.PrintStruct(account),
+			)
+		}
+
+		value, err := types.BigInt(amount.Value)
+		if err != nil {
+			return nil, err
+		}
+		if value.Sign() == 0 {
+			continue
+		}
+
+		balances = append(balances, &StateBalance{
+			Account:  account.Account,
+			Currency: account.Currency,
+			Value:    value,
+		})
+	}
+
+	return balances, nil
+}
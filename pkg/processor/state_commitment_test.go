@@ -0,0 +1,141 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func stateBalance(address string, value int64) *StateBalance {
+	return &StateBalance{
+		Account:  &types.AccountIdentifier{Address: address},
+		Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+		Value:    big.NewInt(value),
+	}
+}
+
+func TestSparseMerkleRoot(t *testing.T) {
+	empty := sparseMerkleEmptyHashes()
+
+	// An empty tree (or one with only zero balances)
+	// has the root of an empty subtree.
+	root, err := SparseMerkleRoot(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(empty[0]), root)
+
+	zeroRoot, err := SparseMerkleRoot([]*StateBalance{stateBalance("addr1", 0)})
+	assert.NoError(t, err)
+	assert.Equal(t, root, zeroRoot)
+
+	// A tree with a single leaf hashes the leaf up
+	// with empty siblings.
+	balance := stateBalance("addr1", 100)
+	key, err := hex.DecodeString(types.Hash(&types.AccountCurrency{
+		Account:  balance.Account,
+		Currency: balance.Currency,
+	}))
+	assert.NoError(t, err)
+
+	node := hashSparseMerkle(sparseMerkleLeafPrefix, key, balance.Value.Bytes())
+	for depth := sparseMerkleDepth - 1; depth >= 0; depth-- {
+		if key[depth/8]&(0x80>>(depth%8)) == 0 {
+			node = hashSparseMerkle(sparseMerkleNodePrefix, node, empty[depth+1])
+		} else {
+			node = hashSparseMerkle(sparseMerkleNodePrefix, empty[depth+1], node)
+		}
+	}
+
+	singleRoot, err := SparseMerkleRoot([]*StateBalance{balance})
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(node), singleRoot)
+
+	// The root does not depend on the order of balances
+	// but does depend on their values.
+	balances := []*StateBalance{
+		stateBalance("addr1", 100),
+		stateBalance("addr2", 200),
+		stateBalance("addr3", 300),
+	}
+	root, err = SparseMerkleRoot(balances)
+	assert.NoError(t, err)
+
+	reversedRoot, err := SparseMerkleRoot([]*StateBalance{balances[2], balances[1], balances[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, root, reversedRoot)
+
+	changedRoot, err := SparseMerkleRoot([]*StateBalance{
+		stateBalance("addr1", 100),
+		stateBalance("addr2", 201),
+		stateBalance("addr3", 300),
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, root, changedRoot)
+
+	// Negative and duplicate balances are invalid.
+	_, err = SparseMerkleRoot([]*StateBalance{stateBalance("addr1", -1)})
+	assert.Error(t, err)
+
+	_, err = SparseMerkleRoot([]*StateBalance{balances[0], balances[0]})
+	assert.Error(t, err)
+}
+
+func TestSparseMerkleVerifier(t *testing.T) {
+	ctx := context.Background()
+	verifier := NewSparseMerkleVerifier("state_root")
+	balances := []*StateBalance{
+		stateBalance("addr1", 100),
+		stateBalance("addr2", 200),
+	}
+	root, err := SparseMerkleRoot(balances)
+	assert.NoError(t, err)
+
+	block := func(metadata map[string]interface{}) *types.Block {
+		return &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{Index: 1000, Hash: "block 1000"},
+			Metadata:        metadata,
+		}
+	}
+
+	// Blocks without a state commitment are not verified.
+	verified, err := verifier.Verify(ctx, block(nil), balances)
+	assert.NoError(t, err)
+	assert.False(t, verified)
+
+	verified, err = verifier.Verify(ctx, block(map[string]interface{}{
+		"state_root": "0x" + root,
+	}), balances)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	verified, err = verifier.Verify(ctx, block(map[string]interface{}{
+		"state_root": root,
+	}), balances[:1])
+	assert.True(t, errors.Is(err, results.ErrStateCommitmentMismatch))
+	assert.False(t, verified)
+
+	_, err = verifier.Verify(ctx, block(map[string]interface{}{
+		"state_root": 1,
+	}), balances)
+	assert.True(t, errors.Is(err, results.ErrStateCommitmentMismatch))
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// StepDelayer injects artificial delays before construction
+// steps (ex: to simulate slow human or HSM signing).
+type StepDelayer struct {
+	delays map[configuration.ConstructionStep]*configuration.StepDelay
+}
+
+// NewStepDelayer returns a new *StepDelayer. If no
+// delays are provided, nil is returned.
+func NewStepDelayer(delays []*configuration.StepDelay) *StepDelayer {
+	if len(delays) == 0 {
+		return nil
+	}
+
+	d := &StepDelayer{
+		delays: map[configuration.ConstructionStep]*configuration.StepDelay{},
+	}
+	for _, delay := range delays {
+		d.delays[delay.Step] = delay
+	}
+
+	return d
+}
+
+// Duration returns the delay to inject before step.
+func (d *StepDelayer) Duration(step configuration.ConstructionStep) time.Duration {
+	if d == nil {
+		return 0
+	}
+
+	delay, ok := d.delays[step]
+	if !ok {
+		return 0
+	}
+
+	seconds := delay.Min
+	if delay.Max > delay.Min {
+		seconds += uint64(rand.Int63n(int64(delay.Max-delay.Min) + 1)) // #nosec G404
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// Wait blocks for the delay of step (returning
+// early with an error if ctx is canceled).
+func (d *StepDelayer) Wait(ctx context.Context, step configuration.ConstructionStep) error {
+	duration := d.Duration(step)
+	if duration == 0 {
+		return nil
+	}
+
+	log.Printf("delaying %s step for %s\n", step, duration)
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepDelayer(t *testing.T) {
+	var delayer *StepDelayer
+	assert.Nil(t, NewStepDelayer(nil))
+	assert.Equal(t, time.Duration(0), delayer.Duration(configuration.SignConstructionStep))
+	assert.NoError(t, delayer.Wait(context.Background(), configuration.SignConstructionStep))
+
+	delayer = NewStepDelayer([]*configuration.StepDelay{
+		{Step: configuration.SignConstructionStep, Min: 60, Max: 120},
+		{Step: configuration.SubmitConstructionStep, Min: 30},
+	})
+
+	for i := 0; i < 100; i++ {
+		duration := delayer.Duration(configuration.SignConstructionStep)
+		assert.True(t, duration >= 60*time.Second && duration <= 120*time.Second)
+	}
+	assert.Equal(t, 30*time.Second, delayer.Duration(configuration.SubmitConstructionStep))
+	assert.Equal(t, time.Duration(0), delayer.Duration(configuration.PayloadsConstructionStep))
+	assert.NoError(t, delayer.Wait(context.Background(), configuration.PayloadsConstructionStep))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, delayer.Wait(ctx, configuration.SignConstructionStep))
+}
@@ -0,0 +1,257 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/fatih/color"
+	"github.com/neilotoole/errgroup"
+)
+
+var _ modules.BlockWorker = (*StrictnessWorker)(nil)
+
+// StrictnessWorker handles conditions the Rosetta specification
+// is ambiguous about according to a configuration.StrictnessLevel.
+type StrictnessWorker struct {
+	strictness          configuration.StrictnessLevel
+	escalationThreshold int64
+	counterStorage      *modules.CounterStorage
+}
+
+// NewStrictnessWorker returns a new *StrictnessWorker.
+func NewStrictnessWorker(
+	strictness configuration.StrictnessLevel,
+	escalationThreshold int64,
+	counterStorage *modules.CounterStorage,
+) *StrictnessWorker {
+	return &StrictnessWorker{
+		strictness:          strictness,
+		escalationThreshold: escalationThreshold,
+		counterStorage:      counterStorage,
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *StrictnessWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	conditions := findAmbiguousConditions(block)
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	if w.strictness == configuration.StrictStrictness {
+		if escalated := w.escalated(conditions); escalated != nil {
+			return nil, fmt.Errorf(
+				"%w: %s in block %s",
+				results.ErrAmbiguousCondition,
+				escalated.description,
+				types.PrintStruct(block.BlockIdentifier),
+			)
+		}
+	}
+
+	if _, err := w.counterStorage.UpdateTransactional(
+		ctx,
+		transaction,
+		results.AmbiguousConditionsCounter,
+		big.NewInt(int64(len(conditions))),
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to update ambiguous conditions counter", err)
+	}
+
+	return func(ctx context.Context) error {
+		recordAmbiguousConditions(block, conditions)
+		color.Yellow(
+			"found %d ambiguous conditions in block %d (first: %s)",
+			len(conditions),
+			block.BlockIdentifier.Index,
+			conditions[0].description,
+		)
+		return nil
+	}, nil
+}
+
+// escalated returns the first condition in conditions whose
+// signature would have more than escalationThreshold occurrences
+// once conditions are recorded (or nil if there is none).
+func (w *StrictnessWorker) escalated(conditions []*ambiguousCondition) *ambiguousCondition {
+	occurrences := map[results.FailureSignature]int64{}
+	for _, condition := range conditions {
+		signature := ambiguousSignature(condition)
+		if _, ok := occurrences[*signature]; !ok {
+			occurrences[*signature] = results.FailureCount(signature)
+		}
+
+		occurrences[*signature]++
+		if occurrences[*signature] > w.escalationThreshold {
+			return condition
+		}
+	}
+
+	return nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+func (w *StrictnessWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	return nil, nil
+}
+
+// emptyMetadata returns a boolean indicating if metadata
+// was populated with an empty object.
+func emptyMetadata(metadata map[string]interface{}) bool {
+	return metadata != nil && len(metadata) == 0
+}
+
+// ambiguousCondition is a condition in a block that
+// the Rosetta specification is ambiguous about.
+type ambiguousCondition struct {
+	// kind is the type of condition (without any
+	// block-specific details).
+	kind string
+
+	// operation is the operation the condition
+	// was found in (if any).
+	operation *types.Operation
+
+	description string
+}
+
+// FindAmbiguousConditions returns a description of each
+// condition in block that the Rosetta specification is
+// ambiguous about: empty metadata objects (instead of
+// omitted metadata) and zero-value operation amounts.
+func FindAmbiguousConditions(block *types.Block) []string {
+	conditions := findAmbiguousConditions(block)
+	descriptions := make([]string, len(conditions))
+	for i, condition := range conditions {
+		descriptions[i] = condition.description
+	}
+
+	return descriptions
+}
+
+// findAmbiguousConditions returns each condition in
+// block that the Rosetta specification is ambiguous about.
+func findAmbiguousConditions(block *types.Block) []*ambiguousCondition {
+	conditions := []*ambiguousCondition{}
+	if emptyMetadata(block.Metadata) {
+		conditions = append(conditions, &ambiguousCondition{
+			kind:        "empty block metadata",
+			description: "empty block metadata",
+		})
+	}
+
+	for _, tx := range block.Transactions {
+		if emptyMetadata(tx.Metadata) {
+			conditions = append(conditions, &ambiguousCondition{
+				kind: "empty transaction metadata",
+				description: fmt.Sprintf(
+					"empty metadata in transaction %s",
+					tx.TransactionIdentifier.Hash,
+				),
+			})
+		}
+
+		for _, op := range tx.Operations {
+			if emptyMetadata(op.Metadata) {
+				conditions = append(conditions, &ambiguousCondition{
+					kind:      "empty operation metadata",
+					operation: op,
+					description: fmt.Sprintf(
+						"empty metadata in operation %d of transaction %s",
+						op.OperationIdentifier.Index,
+						tx.TransactionIdentifier.Hash,
+					),
+				})
+			}
+
+			if op.Amount == nil {
+				continue
+			}
+
+			if emptyMetadata(op.Amount.Metadata) {
+				conditions = append(conditions, &ambiguousCondition{
+					kind:      "empty amount metadata",
+					operation: op,
+					description: fmt.Sprintf(
+						"empty amount metadata in operation %d of transaction %s",
+						op.OperationIdentifier.Index,
+						tx.TransactionIdentifier.Hash,
+					),
+				})
+			}
+
+			value, ok := new(big.Int).SetString(op.Amount.Value, 10)
+			if ok && value.Sign() == 0 {
+				conditions = append(conditions, &ambiguousCondition{
+					kind:      "zero-value amount",
+					operation: op,
+					description: fmt.Sprintf(
+						"zero-value amount in operation %d of transaction %s",
+						op.OperationIdentifier.Index,
+						tx.TransactionIdentifier.Hash,
+					),
+				})
+			}
+		}
+	}
+
+	return conditions
+}
+
+// ambiguousSignature returns the *results.FailureSignature
+// of condition.
+func ambiguousSignature(condition *ambiguousCondition) *results.FailureSignature {
+	signature := &results.FailureSignature{
+		Category: results.AmbiguousConditionCategory,
+		Code:     condition.kind,
+	}
+	if condition.operation != nil {
+		signature.OperationType = condition.operation.Type
+		signature.AccountPattern = results.AccountPattern(condition.operation.Account)
+	}
+
+	return signature
+}
+
+// recordAmbiguousConditions clusters the ambiguous
+// conditions found in block in the results.
+func recordAmbiguousConditions(block *types.Block, conditions []*ambiguousCondition) {
+	for _, condition := range conditions {
+		results.RecordFailure(ambiguousSignature(condition), fmt.Sprintf(
+			"%s in block %d",
+			condition.description,
+			block.BlockIdentifier.Index,
+		))
+	}
+}
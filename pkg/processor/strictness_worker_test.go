@@ -0,0 +1,142 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAmbiguousConditions(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 1, Hash: "block 1"},
+		Metadata:        map[string]interface{}{},
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{Index: 0},
+						Amount: &types.Amount{
+							Value:    "0",
+							Currency: currency,
+						},
+					},
+					{
+						OperationIdentifier: &types.OperationIdentifier{Index: 1},
+						Amount: &types.Amount{
+							Value:    "-10",
+							Currency: currency,
+						},
+						Metadata: map[string]interface{}{},
+					},
+				},
+			},
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx2"},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{Index: 0},
+						Amount: &types.Amount{
+							Value:    "10",
+							Currency: currency,
+						},
+						Metadata: map[string]interface{}{"memo": "hello"},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{
+		"empty block metadata",
+		"zero-value amount in operation 0 of transaction tx1",
+		"empty metadata in operation 1 of transaction tx1",
+	}, FindAmbiguousConditions(block))
+
+	block.Metadata = nil
+	block.Transactions = block.Transactions[1:]
+	assert.Len(t, FindAmbiguousConditions(block), 0)
+}
+
+func TestStrictnessWorkerEscalation(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	worker := NewStrictnessWorker(
+		configuration.StrictStrictness,
+		2,
+		modules.NewCounterStorage(db),
+	)
+	block := &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 1, Hash: "block 1"},
+		Transactions: []*types.Transaction{
+			{
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+				Operations: []*types.Operation{
+					{
+						OperationIdentifier: &types.OperationIdentifier{Index: 0},
+						Type:                "EscalationTest",
+						Account:             &types.AccountIdentifier{Address: "addr1"},
+						Amount: &types.Amount{
+							Value:    "0",
+							Currency: &types.Currency{Symbol: "BTC", Decimals: 8},
+						},
+					},
+				},
+			},
+		},
+	}
+	signature := &results.FailureSignature{
+		Category:       results.AmbiguousConditionCategory,
+		Code:           "zero-value amount",
+		OperationType:  "EscalationTest",
+		AccountPattern: "*",
+	}
+
+	// The first 2 occurrences are warnings.
+	for i := int64(1); i <= 2; i++ {
+		dbTx := db.Transaction(ctx)
+		commitWorker, err := worker.AddingBlock(ctx, nil, block, dbTx)
+		assert.NoError(t, err)
+		assert.NoError(t, dbTx.Commit(ctx))
+		assert.NoError(t, commitWorker(ctx))
+		assert.Equal(t, i, results.FailureCount(signature))
+	}
+
+	// Further occurrences are fatal.
+	dbTx := db.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+	commitWorker, err := worker.AddingBlock(ctx, nil, block, dbTx)
+	assert.Nil(t, commitWorker)
+	assert.ErrorIs(t, err, results.ErrAmbiguousCondition)
+	assert.Equal(t, int64(2), results.FailureCount(signature))
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// NewTLSConfig returns the *tls.Config of requests made to the
+// Rosetta implementation (nil if config is nil, in which case
+// the default *tls.Config is used).
+func NewTLSConfig(config *configuration.TLSConfiguration) (*tls.Config, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify, // #nosec G402
+	}
+
+	if len(config.CAFile) > 0 {
+		ca, err := ioutil.ReadFile(path.Clean(config.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read CA file %s", err, config.CAFile)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.CertFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to load client certificate %s",
+				err,
+				config.CertFile,
+			)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCertificate is a certificate (and its key)
+// signed by a test certificate authority.
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCertificate(
+	t *testing.T,
+	template *x509.Certificate,
+	parent *testCertificate,
+) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return &testCertificate{cert: cert, key: key, der: der}
+}
+
+// write saves the PEM-encoded certificate and key
+// of c to dir and returns their paths.
+func (c *testCertificate) write(t *testing.T, dir string, name string) (string, string) {
+	certPath := path.Join(dir, name+".pem")
+	assert.NoError(t, ioutil.WriteFile(
+		certPath,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}),
+		0600,
+	))
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	keyPath := path.Join(dir, name+"-key.pem")
+	assert.NoError(t, ioutil.WriteFile(
+		keyPath,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		0600,
+	))
+
+	return certPath, keyPath
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	ca := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	server := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	client := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "rosetta-cli"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := client.write(t, dir, "client")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		},
+	))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{server.der},
+			PrivateKey:  server.key,
+		}},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	get := func(config *configuration.TLSConfiguration) error {
+		tlsConfig, err := NewTLSConfig(config)
+		assert.NoError(t, err)
+
+		client := &http.Client{Transport: NewTransport(1, tlsConfig)}
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	tlsConfig, err := NewTLSConfig(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	// The server certificate is not trusted
+	assert.Error(t, get(&configuration.TLSConfiguration{}))
+
+	// The server requires a client certificate
+	assert.Error(t, get(&configuration.TLSConfiguration{CAFile: caFile}))
+	assert.Error(t, get(&configuration.TLSConfiguration{InsecureSkipVerify: true}))
+
+	assert.NoError(t, get(&configuration.TLSConfiguration{
+		CAFile:   caFile,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}))
+	assert.NoError(t, get(&configuration.TLSConfiguration{
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+		InsecureSkipVerify: true,
+	}))
+
+	_, err = NewTLSConfig(&configuration.TLSConfiguration{CAFile: path.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+
+	_, err = NewTLSConfig(&configuration.TLSConfiguration{CAFile: keyFile})
+	assert.Error(t, err)
+
+	_, err = NewTLSConfig(&configuration.TLSConfiguration{CertFile: certFile, KeyFile: caFile})
+	assert.Error(t, err)
+}
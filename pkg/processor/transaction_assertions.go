@@ -0,0 +1,143 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// AssertSignedTransactionSize returns an error if the signed
+// transaction returned by /construction/combine is larger than
+// the configured max size.
+func AssertSignedTransactionSize(
+	assertions *configuration.TransactionAssertionConfiguration,
+	unsignedTransaction string,
+	signedTransaction string,
+) error {
+	if assertions == nil || assertions.MaxSize == 0 {
+		return nil
+	}
+
+	if size := len(signedTransaction); size > assertions.MaxSize {
+		return fmt.Errorf(
+			"%w: signed transaction size %d bytes exceeds max size %d bytes\n"+
+				"unsigned transaction: %s\nsigned transaction: %s",
+			results.ErrTransactionAssertion,
+			size,
+			assertions.MaxSize,
+			unsignedTransaction,
+			signedTransaction,
+		)
+	}
+
+	return nil
+}
+
+// TransactionFee returns the fee charged for a confirmed transaction
+// in currency. This is the net decrease in the balance of currency
+// across all successful operations in the transaction.
+func TransactionFee(
+	asserter *asserter.Asserter,
+	transaction *types.Transaction,
+	currency *types.Currency,
+) (*big.Int, error) {
+	fee := new(big.Int)
+	for _, op := range transaction.Operations {
+		if op.Amount == nil || types.Hash(op.Amount.Currency) != types.Hash(currency) {
+			continue
+		}
+
+		successful, err := asserter.OperationSuccessful(op)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to determine if operation was successful", err)
+		}
+
+		if !successful {
+			continue
+		}
+
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+		}
+
+		fee.Sub(fee, value)
+	}
+
+	return fee, nil
+}
+
+// AssertConfirmedTransaction returns an error describing every
+// configured assertion a confirmed transaction fails.
+func AssertConfirmedTransaction(
+	assertions *configuration.TransactionAssertionConfiguration,
+	asserter *asserter.Asserter,
+	transaction *types.Transaction,
+	intent []*types.Operation,
+) error {
+	if assertions == nil {
+		return nil
+	}
+
+	failures := []string{}
+	if assertions.MaxOperations > 0 && len(transaction.Operations) > assertions.MaxOperations {
+		failures = append(failures, fmt.Sprintf(
+			"transaction has %d operations (intent had %d) which exceeds max operations %d",
+			len(transaction.Operations),
+			len(intent),
+			assertions.MaxOperations,
+		))
+	}
+
+	if assertions.MaxFee != nil {
+		fee, err := TransactionFee(asserter, transaction, assertions.MaxFee.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: unable to calculate transaction fee", err)
+		}
+
+		maxFee, err := types.AmountValue(assertions.MaxFee)
+		if err != nil {
+			return fmt.Errorf("%w: unable to parse max fee", err)
+		}
+
+		if fee.Cmp(maxFee) > 0 {
+			failures = append(failures, fmt.Sprintf(
+				"transaction charged fee %s %s which exceeds max fee %s",
+				fee.String(),
+				assertions.MaxFee.Currency.Symbol,
+				maxFee.String(),
+			))
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: %s\ntransaction: %s",
+		results.ErrTransactionAssertion,
+		strings.Join(failures, "; "),
+		types.PrettyPrintStruct(transaction),
+	)
+}
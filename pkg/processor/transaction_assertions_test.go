@@ -0,0 +1,138 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertSignedTransactionSize(t *testing.T) {
+	assertions := &configuration.TransactionAssertionConfiguration{MaxSize: 4}
+
+	assert.NoError(t, AssertSignedTransactionSize(nil, "unsigned", "signed transaction"))
+	assert.NoError(t, AssertSignedTransactionSize(assertions, "unsigned", "tx"))
+	assert.NoError(t, AssertSignedTransactionSize(assertions, "unsigned", "abcd"))
+
+	err := AssertSignedTransactionSize(assertions, "unsigned", "abcde")
+	assert.True(t, errors.Is(err, results.ErrTransactionAssertion))
+	assert.Contains(t, err.Error(), "5 bytes")
+}
+
+func TestAssertConfirmedTransaction(t *testing.T) {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer", "Fee"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+			{
+				Status:     "Failure",
+				Successful: false,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	other := &types.Currency{Symbol: "ETH", Decimals: 18}
+	op := func(opType string, status string, value string, currency *types.Currency) *types.Operation {
+		return &types.Operation{
+			Type:    opType,
+			Status:  types.String(status),
+			Account: &types.AccountIdentifier{Address: "addr"},
+			Amount: &types.Amount{
+				Value:    value,
+				Currency: currency,
+			},
+		}
+	}
+
+	intent := []*types.Operation{
+		op("Transfer", "", "-100", currency),
+		op("Transfer", "", "100", currency),
+	}
+	transaction := &types.Transaction{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx"},
+		Operations: []*types.Operation{
+			op("Transfer", "Success", "-100", currency),
+			op("Transfer", "Success", "100", currency),
+			op("Fee", "Success", "-10", currency),
+			op("Fee", "Failure", "-1000", currency),
+			op("Fee", "Success", "-1000", other),
+		},
+	}
+
+	fee, err := TransactionFee(a, transaction, currency)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", fee.String())
+
+	var tests = map[string]struct {
+		assertions *configuration.TransactionAssertionConfiguration
+		err        bool
+	}{
+		"no assertions": {},
+		"within limits": {
+			assertions: &configuration.TransactionAssertionConfiguration{
+				MaxOperations: 5,
+				MaxFee:        &types.Amount{Value: "10", Currency: currency},
+			},
+		},
+		"too many operations": {
+			assertions: &configuration.TransactionAssertionConfiguration{
+				MaxOperations: 4,
+			},
+			err: true,
+		},
+		"fee too high": {
+			assertions: &configuration.TransactionAssertionConfiguration{
+				MaxFee: &types.Amount{Value: "9", Currency: currency},
+			},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := AssertConfirmedTransaction(test.assertions, a, transaction, intent)
+			if test.err {
+				assert.True(t, errors.Is(err, results.ErrTransactionAssertion))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,544 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/neilotoole/errgroup"
+)
+
+const (
+	// transferGraphPrefix must not start with any namespace used
+	// by storage modules (ex: "acc"), which are scanned
+	// by prefix.
+	transferGraphPrefix = "transfer_graph"
+
+	// TransferGraphDOTExtension is the extension of transfer
+	// graphs written in the DOT language (Graphviz).
+	TransferGraphDOTExtension = ".dot"
+
+	// TransferGraphGraphMLExtension is the extension of
+	// transfer graphs written as GraphML.
+	TransferGraphGraphMLExtension = ".graphml"
+)
+
+var _ modules.BlockWorker = (*TransferGraphWorker)(nil)
+
+// TransferGraphEdge is a confirmed transfer of Value (in
+// atomic units of Currency) from Sender to Recipient.
+type TransferGraphEdge struct {
+	Sender          string          `json:"sender"`
+	Recipient       string          `json:"recipient"`
+	TransactionHash string          `json:"transaction_hash"`
+	BlockIndex      int64           `json:"block_index"`
+	Value           string          `json:"value"`
+	Currency        *types.Currency `json:"currency"`
+
+	// Fee is the fee (in atomic units of Currency) paid by
+	// the transaction (shared by all of its edges). It is
+	// empty if the transaction paid no fee in Currency.
+	Fee string `json:"fee,omitempty"`
+}
+
+// TransferGraphNode is an address that sent or received
+// at least one confirmed transfer.
+type TransferGraphNode struct {
+	Address  string `json:"address"`
+	Sent     int    `json:"sent"`
+	Received int    `json:"received"`
+}
+
+// TransferGraph is the graph of confirmed transfers
+// made by check:construction.
+type TransferGraph struct {
+	Nodes []*TransferGraphNode `json:"nodes"`
+	Edges []*TransferGraphEdge `json:"edges"`
+}
+
+// TransferEdges returns the edges of a successful transaction,
+// from each account with a net debit to each account with a
+// net credit (in the same currency). Accounts that are both
+// debited and credited (ex: change outputs) only appear on the
+// side of their net balance change. When a transaction has
+// several senders, each recipient has an edge from each sender
+// with the full value it received.
+func TransferEdges(
+	asserter *asserter.Asserter,
+	blockIdentifier *types.BlockIdentifier,
+	tx *types.Transaction,
+) ([]*TransferGraphEdge, error) {
+	currencies := map[string]*types.Currency{}
+	changes := map[string]map[string]*big.Int{}
+	for _, op := range tx.Operations {
+		if op.Account == nil || op.Amount == nil {
+			continue
+		}
+
+		successful, err := asserter.OperationSuccessful(op)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to check operation status", err)
+		}
+
+		if !successful {
+			continue
+		}
+
+		value, err := types.AmountValue(op.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse operation amount", err)
+		}
+
+		currency := types.Hash(op.Amount.Currency)
+		currencies[currency] = op.Amount.Currency
+		if _, ok := changes[currency]; !ok {
+			changes[currency] = map[string]*big.Int{}
+		}
+
+		account := types.AccountString(op.Account)
+		if _, ok := changes[currency][account]; !ok {
+			changes[currency][account] = new(big.Int)
+		}
+
+		changes[currency][account].Add(changes[currency][account], value)
+	}
+
+	edges := []*TransferGraphEdge{}
+	for currency, accounts := range changes {
+		senders := []string{}
+		recipients := []string{}
+		net := new(big.Int)
+		for account, change := range accounts {
+			net.Add(net, change)
+			switch change.Sign() {
+			case -1:
+				senders = append(senders, account)
+			case 1:
+				recipients = append(recipients, account)
+			}
+		}
+
+		sort.Strings(senders)
+		sort.Strings(recipients)
+
+		fee := ""
+		if net.Sign() < 0 {
+			fee = new(big.Int).Neg(net).String()
+		}
+
+		for _, sender := range senders {
+			for _, recipient := range recipients {
+				edges = append(edges, &TransferGraphEdge{
+					Sender:          sender,
+					Recipient:       recipient,
+					TransactionHash: tx.TransactionIdentifier.Hash,
+					BlockIndex:      blockIdentifier.Index,
+					Value:           accounts[recipient].String(),
+					Currency:        currencies[currency],
+					Fee:             fee,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].Currency.Symbol != edges[j].Currency.Symbol {
+			return edges[i].Currency.Symbol < edges[j].Currency.Symbol
+		}
+
+		if edges[i].Sender != edges[j].Sender {
+			return edges[i].Sender < edges[j].Sender
+		}
+
+		return edges[i].Recipient < edges[j].Recipient
+	})
+
+	return edges, nil
+}
+
+func transferGraphKey(blockIndex int64, hash string) []byte {
+	return []byte(fmt.Sprintf("%s/%d/%s", transferGraphPrefix, blockIndex, hash))
+}
+
+// TransferGraphStorage stores the edges of each confirmed
+// transaction broadcast by check:construction.
+type TransferGraphStorage struct {
+	db database.Database
+}
+
+// NewTransferGraphStorage returns a new *TransferGraphStorage.
+func NewTransferGraphStorage(db database.Database) *TransferGraphStorage {
+	return &TransferGraphStorage{db: db}
+}
+
+// Graph returns the *TransferGraph of all stored
+// edges (with nodes sorted by address).
+func (s *TransferGraphStorage) Graph(ctx context.Context) (*TransferGraph, error) {
+	dbTx := s.db.ReadTransaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	edges := []*TransferGraphEdge{}
+	if _, err := dbTx.Scan(
+		ctx,
+		[]byte(transferGraphPrefix+"/"),
+		[]byte(transferGraphPrefix+"/"),
+		func(k []byte, v []byte) error {
+			var txEdges []*TransferGraphEdge
+			if err := json.Unmarshal(v, &txEdges); err != nil {
+				return fmt.Errorf("%w: unable to unmarshal edges of %s", err, string(k))
+			}
+
+			edges = append(edges, txEdges...)
+			return nil
+		},
+		false,
+		false,
+	); err != nil {
+		return nil, fmt.Errorf("%w: unable to scan transfer graph", err)
+	}
+
+	// Keys are not sorted numerically by block index.
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].BlockIndex < edges[j].BlockIndex
+	})
+
+	return NewTransferGraph(edges), nil
+}
+
+// NewTransferGraph returns the *TransferGraph of edges.
+func NewTransferGraph(edges []*TransferGraphEdge) *TransferGraph {
+	nodes := map[string]*TransferGraphNode{}
+	node := func(address string) *TransferGraphNode {
+		if _, ok := nodes[address]; !ok {
+			nodes[address] = &TransferGraphNode{Address: address}
+		}
+
+		return nodes[address]
+	}
+
+	for _, edge := range edges {
+		node(edge.Sender).Sent++
+		node(edge.Recipient).Received++
+	}
+
+	graph := &TransferGraph{
+		Nodes: make([]*TransferGraphNode, 0, len(nodes)),
+		Edges: edges,
+	}
+	for _, n := range nodes {
+		graph.Nodes = append(graph.Nodes, n)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Address < graph.Nodes[j].Address
+	})
+
+	return graph
+}
+
+// TransferGraphWorker records the edges of each transaction
+// broadcast by check:construction once it is included in a
+// block.
+type TransferGraphWorker struct {
+	asserter         *asserter.Asserter
+	broadcastStorage *modules.BroadcastStorage
+}
+
+// NewTransferGraphWorker returns a new *TransferGraphWorker.
+func NewTransferGraphWorker(
+	asserter *asserter.Asserter,
+	broadcastStorage *modules.BroadcastStorage,
+) *TransferGraphWorker {
+	return &TransferGraphWorker{
+		asserter:         asserter,
+		broadcastStorage: broadcastStorage,
+	}
+}
+
+// AddingBlock is called by BlockStorage when adding a block.
+func (w *TransferGraphWorker) AddingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	if len(block.Transactions) == 0 {
+		return nil, nil
+	}
+
+	// Broadcasts are only removed once they are confirmed, so
+	// transactions broadcast by the rosetta-cli are still
+	// pending when they are first included in a block.
+	broadcasts, err := w.broadcastStorage.GetAllBroadcasts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to get broadcasts", err)
+	}
+
+	if len(broadcasts) == 0 {
+		return nil, nil
+	}
+
+	broadcasted := map[string]struct{}{}
+	for _, broadcast := range broadcasts {
+		broadcasted[broadcast.TransactionIdentifier.Hash] = struct{}{}
+	}
+
+	for _, tx := range block.Transactions {
+		if _, ok := broadcasted[tx.TransactionIdentifier.Hash]; !ok {
+			continue
+		}
+
+		edges, err := TransferEdges(w.asserter, block.BlockIdentifier, tx)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to find transfers in %s",
+				err,
+				tx.TransactionIdentifier.Hash,
+			)
+		}
+
+		if len(edges) == 0 {
+			continue
+		}
+
+		val, err := json.Marshal(edges)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to marshal transfer edges", err)
+		}
+
+		if err := transaction.Set(
+			ctx,
+			transferGraphKey(block.BlockIdentifier.Index, tx.TransactionIdentifier.Hash),
+			val,
+			false,
+		); err != nil {
+			return nil, fmt.Errorf("%w: unable to store transfer edges", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// RemovingBlock is called by BlockStorage when removing a block.
+// Edges recorded in an orphaned block are removed so that they
+// can be recorded again when the transaction is re-included.
+func (w *TransferGraphWorker) RemovingBlock(
+	ctx context.Context,
+	g *errgroup.Group,
+	block *types.Block,
+	transaction database.Transaction,
+) (database.CommitWorker, error) {
+	for _, tx := range block.Transactions {
+		if err := transaction.Delete(
+			ctx,
+			transferGraphKey(block.BlockIdentifier.Index, tx.TransactionIdentifier.Hash),
+		); err != nil {
+			return nil, fmt.Errorf("%w: unable to remove transfer edges", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// dotQuote returns s as a quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// writeTransferGraphDOT writes graph in the DOT language.
+func writeTransferGraphDOT(w io.Writer, graph *TransferGraph) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "digraph transfers {")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(
+			out,
+			"  %s [label=%s, sent=%d, received=%d];\n",
+			dotQuote(node.Address),
+			dotQuote(fmt.Sprintf("%s\nsent: %d, received: %d", node.Address, node.Sent, node.Received)),
+			node.Sent,
+			node.Received,
+		)
+	}
+
+	for _, edge := range graph.Edges {
+		label := fmt.Sprintf("%s %s", edge.Value, edge.Currency.Symbol)
+		if len(edge.Fee) > 0 {
+			label = fmt.Sprintf("%s (fee %s)", label, edge.Fee)
+		}
+
+		fmt.Fprintf(
+			out,
+			"  %s -> %s [label=%s, transaction=%s, block=%d, value=%s, currency=%s, fee=%s];\n",
+			dotQuote(edge.Sender),
+			dotQuote(edge.Recipient),
+			dotQuote(label),
+			dotQuote(edge.TransactionHash),
+			edge.BlockIndex,
+			dotQuote(edge.Value),
+			dotQuote(edge.Currency.Symbol),
+			dotQuote(edge.Fee),
+		)
+	}
+	fmt.Fprintln(out, "}")
+
+	return out.Flush()
+}
+
+// graphML is the root element of a GraphML document.
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeTransferGraphGraphML writes graph as GraphML.
+func writeTransferGraphGraphML(w io.Writer, graph *TransferGraph) error {
+	doc := &graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "sent", For: "node", Name: "sent", Type: "int"},
+			{ID: "received", For: "node", Name: "received", Type: "int"},
+			{ID: "transaction", For: "edge", Name: "transaction", Type: "string"},
+			{ID: "block", For: "edge", Name: "block", Type: "long"},
+			{ID: "value", For: "edge", Name: "value", Type: "string"},
+			{ID: "currency", For: "edge", Name: "currency", Type: "string"},
+			{ID: "fee", For: "edge", Name: "fee", Type: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          "transfers",
+			EdgeDefault: "directed",
+		},
+	}
+
+	for _, node := range graph.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: node.Address,
+			Data: []graphMLData{
+				{Key: "sent", Value: fmt.Sprintf("%d", node.Sent)},
+				{Key: "received", Value: fmt.Sprintf("%d", node.Received)},
+			},
+		})
+	}
+
+	for i, edge := range graph.Edges {
+		data := []graphMLData{
+			{Key: "transaction", Value: edge.TransactionHash},
+			{Key: "block", Value: fmt.Sprintf("%d", edge.BlockIndex)},
+			{Key: "value", Value: edge.Value},
+			{Key: "currency", Value: edge.Currency.Symbol},
+		}
+		if len(edge.Fee) > 0 {
+			data = append(data, graphMLData{Key: "fee", Value: edge.Fee})
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: edge.Sender,
+			Target: edge.Recipient,
+			Data:   data,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("%w: unable to encode graphml", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteTransferGraph writes graph to filePath in the DOT
+// language (if filePath ends in .dot) or as GraphML (if
+// filePath ends in .graphml).
+func WriteTransferGraph(filePath string, graph *TransferGraph) error {
+	var write func(io.Writer, *TransferGraph) error
+	switch strings.ToLower(path.Ext(filePath)) {
+	case TransferGraphDOTExtension:
+		write = writeTransferGraphDOT
+	case TransferGraphGraphMLExtension:
+		write = writeTransferGraphGraphML
+	default:
+		return fmt.Errorf(
+			"transfer graph path %s must end in %s or %s",
+			filePath,
+			TransferGraphDOTExtension,
+			TransferGraphGraphMLExtension,
+		)
+	}
+
+	f, err := os.Create(path.Clean(filePath))
+	if err != nil {
+		return fmt.Errorf("%w: unable to create transfer graph", err)
+	}
+	defer f.Close()
+
+	if err := write(f, graph); err != nil {
+		return fmt.Errorf("%w: unable to write transfer graph", err)
+	}
+
+	return nil
+}
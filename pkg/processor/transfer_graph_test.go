@@ -0,0 +1,202 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func transferGraphAsserter(t *testing.T) *asserter.Asserter {
+	a, err := asserter.NewClientWithOptions(
+		&types.NetworkIdentifier{
+			Blockchain: "bitcoin",
+			Network:    "mainnet",
+		},
+		&types.BlockIdentifier{
+			Hash:  "block 0",
+			Index: 0,
+		},
+		[]string{"Transfer"},
+		[]*types.OperationStatus{
+			{
+				Status:     "Success",
+				Successful: true,
+			},
+			{
+				Status:     "Failure",
+				Successful: false,
+			},
+		},
+		[]*types.Error{},
+		nil,
+		&asserter.Validations{
+			Enabled: false,
+		},
+	)
+	assert.NoError(t, err)
+
+	return a
+}
+
+func TestTransferEdges(t *testing.T) {
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	op := func(address string, value string, status string) *types.Operation {
+		return &types.Operation{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "Transfer",
+			Status:              types.String(status),
+			Account:             &types.AccountIdentifier{Address: address},
+			Amount:              &types.Amount{Value: value, Currency: currency},
+		}
+	}
+
+	edges, err := TransferEdges(
+		transferGraphAsserter(t),
+		&types.BlockIdentifier{Index: 10, Hash: "block 10"},
+		&types.Transaction{
+			TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+			Operations: []*types.Operation{
+				op("a", "-1000", "Success"),
+				op("b", "-500", "Success"),
+				op("c", "700", "Success"),
+				op("a", "600", "Success"), // change
+				op("d", "150", "Success"),
+				op("e", "100", "Failure"),
+			},
+		},
+	)
+	assert.NoError(t, err)
+
+	edge := func(sender string, recipient string, value string) *TransferGraphEdge {
+		return &TransferGraphEdge{
+			Sender:          sender,
+			Recipient:       recipient,
+			TransactionHash: "tx1",
+			BlockIndex:      10,
+			Value:           value,
+			Currency:        currency,
+			Fee:             "50",
+		}
+	}
+	assert.Equal(t, []*TransferGraphEdge{
+		edge("a", "c", "700"),
+		edge("a", "d", "150"),
+		edge("b", "c", "700"),
+		edge("b", "d", "150"),
+	}, edges)
+}
+
+func TestTransferGraphStorage(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	currency := &types.Currency{Symbol: "BTC", Decimals: 8}
+	edges := map[string]*TransferGraphEdge{
+		"tx1": {
+			Sender:          "a",
+			Recipient:       "b",
+			TransactionHash: "tx1",
+			BlockIndex:      9,
+			Value:           "100",
+			Currency:        currency,
+			Fee:             "10",
+		},
+		"tx2": {
+			Sender:          "b",
+			Recipient:       "c",
+			TransactionHash: "tx2",
+			BlockIndex:      10,
+			Value:           "50",
+			Currency:        currency,
+		},
+	}
+
+	dbTx := db.Transaction(ctx)
+	for hash, edge := range edges {
+		val, err := json.Marshal([]*TransferGraphEdge{edge})
+		assert.NoError(t, err)
+		assert.NoError(t, dbTx.Set(ctx, transferGraphKey(edge.BlockIndex, hash), val, false))
+	}
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	graph, err := NewTransferGraphStorage(db).Graph(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &TransferGraph{
+		Nodes: []*TransferGraphNode{
+			{Address: "a", Sent: 1},
+			{Address: "b", Sent: 1, Received: 1},
+			{Address: "c", Received: 1},
+		},
+		Edges: []*TransferGraphEdge{edges["tx1"], edges["tx2"]},
+	}, graph)
+
+	// Orphaned transactions are removed
+	worker := NewTransferGraphWorker(transferGraphAsserter(t), nil)
+	dbTx = db.Transaction(ctx)
+	_, err = worker.RemovingBlock(ctx, nil, &types.Block{
+		BlockIdentifier: &types.BlockIdentifier{Index: 10, Hash: "block 10"},
+		Transactions: []*types.Transaction{
+			{TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx2"}},
+		},
+	}, dbTx)
+	assert.NoError(t, err)
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	graph, err = NewTransferGraphStorage(db).Graph(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*TransferGraphEdge{edges["tx1"]}, graph.Edges)
+
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	dotPath := path.Join(dir, "graph.dot")
+	assert.NoError(t, WriteTransferGraph(dotPath, graph))
+	dot, err := ioutil.ReadFile(dotPath)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(dot), "digraph transfers {\n"))
+	assert.Contains(
+		t,
+		string(dot),
+		`"a" -> "b" [label="100 BTC (fee 10)", transaction="tx1", block=9, value="100", currency="BTC", fee="10"];`,
+	)
+
+	graphMLPath := path.Join(dir, "graph.graphml")
+	assert.NoError(t, WriteTransferGraph(graphMLPath, graph))
+	graphML, err := ioutil.ReadFile(graphMLPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(graphML), `<edge id="e0" source="a" target="b">`)
+	assert.Contains(t, string(graphML), `<data key="fee">10</data>`)
+
+	assert.Error(t, WriteTransferGraph(path.Join(dir, "graph.png"), graph))
+}
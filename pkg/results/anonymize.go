@@ -0,0 +1,247 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// anonymizedPrefix is prepended to each pseudonym
+	// so that anonymized values are easy to recognize.
+	anonymizedPrefix = "anon_"
+
+	// pseudonymLength is the number of hex characters
+	// of the salted hash used in each pseudonym.
+	pseudonymLength = 16
+
+	// saltLength is the number of random bytes
+	// in the salt of each *Anonymizer.
+	saltLength = 32
+
+	// minSubstringLength is the minimum length of an
+	// identifier for it to be replaced where it appears
+	// within a longer string (ex: an error message). Shorter
+	// identifiers are only replaced when they are an entire
+	// value, so that unrelated text is not mangled.
+	minSubstringLength = 8
+)
+
+// Anonymizer replaces the addresses and transaction hashes
+// in JSON reports with salted hashes so that reports can be
+// shared without exposing account identifiers. The salt is
+// generated for each *Anonymizer (and never persisted), so
+// pseudonyms are consistent across all reports anonymized
+// by the same *Anonymizer but can't be linked to other runs.
+type Anonymizer struct {
+	salt       []byte
+	pseudonyms map[string]string
+}
+
+// NewAnonymizer returns a new *Anonymizer with a random salt.
+func NewAnonymizer() (*Anonymizer, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("%w: unable to generate salt", err)
+	}
+
+	return &Anonymizer{
+		salt:       salt,
+		pseudonyms: map[string]string{},
+	}, nil
+}
+
+// decodeReport decodes each JSON value in report (a single
+// JSON document or newline-delimited JSON).
+func decodeReport(report []byte) ([]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(report))
+	decoder.UseNumber()
+
+	values := []interface{}{}
+	for {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			return values, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to decode report", err)
+		}
+
+		values = append(values, value)
+	}
+}
+
+// add records a pseudonym for identifier.
+func (a *Anonymizer) add(identifier string) {
+	if len(identifier) == 0 {
+		return
+	}
+
+	if _, ok := a.pseudonyms[identifier]; ok {
+		return
+	}
+
+	digest := sha256.Sum256(append(append([]byte{}, a.salt...), identifier...))
+	a.pseudonyms[identifier] = anonymizedPrefix + hex.EncodeToString(digest[:])[:pseudonymLength]
+}
+
+// collect records every address and transaction hash in value.
+func (a *Anonymizer) collect(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			switch key {
+			case "address":
+				if address, ok := child.(string); ok {
+					a.add(address)
+				}
+			case "transaction_identifier":
+				if identifier, ok := child.(map[string]interface{}); ok {
+					if hash, ok := identifier["hash"].(string); ok {
+						a.add(hash)
+					}
+				}
+			}
+
+			a.collect(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			a.collect(child)
+		}
+	}
+}
+
+// Collect records the addresses (the address field of
+// any object) and transaction hashes (the hash field of any
+// transaction_identifier) in report so that they are replaced
+// by Anonymize. When anonymizing multiple related reports,
+// all reports should be collected before any are anonymized
+// (identifiers that only appear within strings in a report
+// are only replaced if they are collected from another).
+func (a *Anonymizer) Collect(report []byte) error {
+	values, err := decodeReport(report)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		a.collect(value)
+	}
+
+	return nil
+}
+
+// replacer returns a *strings.Replacer that replaces each collected
+// identifier (long enough to be replaced within strings) with
+// its pseudonym, preferring longer identifiers.
+func (a *Anonymizer) replacer() *strings.Replacer {
+	identifiers := []string{}
+	for identifier := range a.pseudonyms {
+		if len(identifier) >= minSubstringLength {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+
+	sort.Slice(identifiers, func(i, j int) bool {
+		if len(identifiers[i]) != len(identifiers[j]) {
+			return len(identifiers[i]) > len(identifiers[j])
+		}
+
+		return identifiers[i] < identifiers[j]
+	})
+
+	oldnew := make([]string, 0, len(identifiers)*2)
+	for _, identifier := range identifiers {
+		oldnew = append(oldnew, identifier, a.pseudonyms[identifier])
+	}
+
+	return strings.NewReplacer(oldnew...)
+}
+
+// anonymizeString returns s with all collected identifiers replaced.
+func (a *Anonymizer) anonymizeString(s string, replacer *strings.Replacer) string {
+	if pseudonym, ok := a.pseudonyms[s]; ok {
+		return pseudonym
+	}
+
+	return replacer.Replace(s)
+}
+
+// anonymize returns value with all collected identifiers replaced.
+func (a *Anonymizer) anonymize(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		anonymized := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			anonymized[a.anonymizeString(key, replacer)] = a.anonymize(child, replacer)
+		}
+
+		return anonymized
+	case []interface{}:
+		anonymized := make([]interface{}, len(v))
+		for i, child := range v {
+			anonymized[i] = a.anonymize(child, replacer)
+		}
+
+		return anonymized
+	case string:
+		return a.anonymizeString(v, replacer)
+	default:
+		return v
+	}
+}
+
+// Anonymize collects the identifiers in report and returns
+// report with every collected identifier replaced by its
+// pseudonym (wherever it appears). A report containing a
+// single JSON document is returned indented and a report of
+// newline-delimited JSON is returned with one value per line.
+func (a *Anonymizer) Anonymize(report []byte) ([]byte, error) {
+	values, err := decodeReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		a.collect(value)
+	}
+
+	replacer := a.replacer()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if len(values) == 1 {
+		encoder.SetIndent("", " ")
+	}
+
+	for _, value := range values {
+		if err := encoder.Encode(a.anonymize(value, replacer)); err != nil {
+			return nil, fmt.Errorf("%w: unable to encode anonymized report", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
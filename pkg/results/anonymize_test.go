@@ -0,0 +1,110 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	anonymizeAddress = "0x5a0b54d5dc17e0aadc383d2db43b0a0d3e029c4c"
+	anonymizeTxHash  = "0xe2d6c3e7a3f1b8c02e3e09c5c67ae3d9e9b4f3a5c8e5b7d6f4a3c2b1a0f9e8d7"
+)
+
+func TestAnonymize(t *testing.T) {
+	anonymizer, err := NewAnonymizer()
+	assert.NoError(t, err)
+
+	report := []byte(`{
+ "error": "active reconciliation error for ` + anonymizeAddress + ` at 10",
+ "intent": [
+  {
+   "account": {"address": "` + anonymizeAddress + `", "sub_account": {"address": "stake"}},
+   "amount": {"value": "-10", "currency": {"symbol": "BTC", "decimals": 8}}
+  }
+ ],
+ "pending": [{"transaction_identifier": {"hash": "` + anonymizeTxHash + `"}, "index": 5}],
+ "block_identifier": {"index": 10, "hash": "block 10"}
+}`)
+
+	anonymized, err := anonymizer.Anonymize(report)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(anonymized), anonymizeAddress)
+	assert.NotContains(t, string(anonymized), anonymizeTxHash)
+
+	var parsed map[string]interface{}
+	assert.NoError(t, json.Unmarshal(anonymized, &parsed))
+
+	intent := parsed["intent"].([]interface{})[0].(map[string]interface{})
+	account := intent["account"].(map[string]interface{})
+	address := account["address"].(string)
+	assert.True(t, strings.HasPrefix(address, anonymizedPrefix))
+	assert.Len(t, address, len(anonymizedPrefix)+pseudonymLength)
+	assert.Equal(
+		t,
+		"active reconciliation error for "+address+" at 10",
+		parsed["error"],
+	)
+
+	// Short identifiers are only replaced when they are an entire value.
+	subAccount := account["sub_account"].(map[string]interface{})
+	assert.True(t, strings.HasPrefix(subAccount["address"].(string), anonymizedPrefix))
+
+	// Other values (including block hashes) are not modified.
+	assert.Equal(t, map[string]interface{}{
+		"value": "-10",
+		"currency": map[string]interface{}{
+			"symbol":   "BTC",
+			"decimals": float64(8),
+		},
+	}, intent["amount"])
+	assert.Equal(t, map[string]interface{}{
+		"index": float64(10),
+		"hash":  "block 10",
+	}, parsed["block_identifier"])
+
+	pending := parsed["pending"].([]interface{})[0].(map[string]interface{})
+	txHash := pending["transaction_identifier"].(map[string]interface{})["hash"].(string)
+	assert.True(t, strings.HasPrefix(txHash, anonymizedPrefix))
+	assert.Equal(t, float64(5), pending["index"])
+
+	// Pseudonyms are consistent across reports anonymized together
+	// and identifiers collected from other reports are replaced.
+	checkpoints := []byte(
+		`{"address":"` + anonymizeAddress + `"}` + "\n" +
+			`{"message":"tx ` + anonymizeTxHash + ` failed"}` + "\n",
+	)
+	anonymized, err = anonymizer.Anonymize(checkpoints)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"address":"`+address+`"}`+"\n"+`{"message":"tx `+txHash+` failed"}`+"\n",
+		string(anonymized),
+	)
+
+	// A different *Anonymizer uses a different salt.
+	other, err := NewAnonymizer()
+	assert.NoError(t, err)
+	anonymized, err = other.Anonymize(checkpoints)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(anonymized), address)
+
+	_, err = anonymizer.Anonymize([]byte("{"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+var (
+	balanceRuleUsageLock sync.Mutex
+
+	// balanceRuleUsage is the number of operations of each
+	// operation type modified by a balance rule in this
+	// invocation.
+	balanceRuleUsage = map[string]int64{}
+)
+
+// RecordBalanceRule records an operation modified
+// by the balance rule of its operation type.
+func RecordBalanceRule(operationType string) {
+	balanceRuleUsageLock.Lock()
+	defer balanceRuleUsageLock.Unlock()
+
+	balanceRuleUsage[operationType]++
+}
+
+// BalanceRuleUsage returns the number of operations of each
+// operation type that were modified by a balance rule. If no
+// operations were modified, nil is returned.
+func BalanceRuleUsage() map[string]int64 {
+	balanceRuleUsageLock.Lock()
+	defer balanceRuleUsageLock.Unlock()
+
+	if len(balanceRuleUsage) == 0 {
+		return nil
+	}
+
+	usage := map[string]int64{}
+	for operationType, count := range balanceRuleUsage {
+		usage[operationType] = count
+	}
+
+	return usage
+}
+
+// printBalanceRules logs balance rule
+// usage to the console.
+func printBalanceRules(usage map[string]int64) {
+	operationTypes := make([]string, 0, len(usage))
+	for operationType := range usage {
+		operationTypes = append(operationTypes, operationType)
+	}
+	sort.Strings(operationTypes)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Balance Rule Operation Type", "Count"})
+	for _, operationType := range operationTypes {
+		table.Append([]string{
+			operationType,
+			strconv.FormatInt(usage[operationType], 10),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	// TimestampCheck asserts that block timestamps do not
+	// decrease (by more than the configured tolerance).
+	TimestampCheck = "Timestamp"
+
+	// ParentLinkageCheck asserts that each block references
+	// the block before it as its parent.
+	ParentLinkageCheck = "Parent Linkage"
+
+	// DuplicateTransactionCheck asserts that no transaction
+	// hash appears more than once in a block.
+	DuplicateTransactionCheck = "Duplicate Transaction"
+
+	// OperationIndexCheck asserts that the operation indexes
+	// in each transaction are contiguous and start at 0.
+	OperationIndexCheck = "Operation Index"
+)
+
+// blockIntegrityChecks is the order checks are
+// printed in the summary.
+var blockIntegrityChecks = []string{
+	TimestampCheck,
+	ParentLinkageCheck,
+	DuplicateTransactionCheck,
+	OperationIndexCheck,
+}
+
+// BlockViolation is a structural invariant
+// violated by a stored block.
+type BlockViolation struct {
+	Block   *types.BlockIdentifier `json:"block_identifier"`
+	Check   string                 `json:"check"`
+	Message string                 `json:"message"`
+}
+
+// BlockIntegrityResults contains the violations
+// found while checking stored blocks.
+type BlockIntegrityResults struct {
+	BlocksChecked int64             `json:"blocks_checked"`
+	Violations    []*BlockViolation `json:"violations"`
+}
+
+// Print logs the violations and a summary of the
+// number of violations of each check to the console.
+func (r *BlockIntegrityResults) Print() {
+	if len(r.Violations) > 0 {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetRowLine(true)
+		table.SetRowSeparator("-")
+		table.SetHeader([]string{"Block", "Check", "Violation"})
+		for _, violation := range r.Violations {
+			table.Append([]string{
+				types.PrintStruct(violation.Block),
+				violation.Check,
+				violation.Message,
+			})
+		}
+
+		table.Render()
+	}
+
+	counts := map[string]int{}
+	for _, violation := range r.Violations {
+		counts[violation.Check]++
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"check:blocks Summary", "Description", "Value"})
+	table.Append([]string{
+		"Blocks Checked",
+		"# of stored blocks checked",
+		strconv.FormatInt(r.BlocksChecked, 10),
+	})
+	for _, check := range blockIntegrityChecks {
+		table.Append([]string{
+			check,
+			"# of violations",
+			strconv.Itoa(counts[check]),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,244 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Histogram is the distribution of some observed value in
+// power-of-two buckets. Buckets[0] counts observations of 0
+// and Buckets[i] counts observations in [2^(i-1), 2^i).
+type Histogram struct {
+	Count   int64   `json:"count"`
+	Total   int64   `json:"total"`
+	Max     int64   `json:"max"`
+	Buckets []int64 `json:"buckets"`
+}
+
+// bucket returns the index of the bucket value belongs in.
+func bucket(value int64) int {
+	if value <= 0 {
+		return 0
+	}
+
+	return bits.Len64(uint64(value))
+}
+
+// bucketLabel returns the range of values
+// counted in the bucket at index i.
+func bucketLabel(i int) string {
+	switch i {
+	case 0:
+		return "0"
+	case 1:
+		return "1"
+	default:
+		return fmt.Sprintf("%d-%d", int64(1)<<(i-1), int64(1)<<i-1)
+	}
+}
+
+// observe adds value to the histogram.
+func (h *Histogram) observe(value int64) {
+	i := bucket(value)
+	for len(h.Buckets) <= i {
+		h.Buckets = append(h.Buckets, 0)
+	}
+
+	h.Buckets[i]++
+	h.Count++
+	h.Total += value
+	if value > h.Max {
+		h.Max = value
+	}
+}
+
+// remove removes a previously observed value from the histogram.
+// Max is not updated because it is the largest value ever observed.
+func (h *Histogram) remove(value int64) {
+	i := bucket(value)
+	if len(h.Buckets) <= i || h.Buckets[i] == 0 {
+		return
+	}
+
+	h.Buckets[i]--
+	h.Count--
+	h.Total -= value
+}
+
+// Mean returns the average observed value.
+func (h *Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return float64(h.Total) / float64(h.Count)
+}
+
+func (h *Histogram) copy() *Histogram {
+	buckets := make([]int64, len(h.Buckets))
+	copy(buckets, h.Buckets)
+
+	return &Histogram{
+		Count:   h.Count,
+		Total:   h.Total,
+		Max:     h.Max,
+		Buckets: buckets,
+	}
+}
+
+// BlockStats are the distributions of block size (in serialized
+// bytes), transactions per block, and operations per transaction
+// over the blocks synced during check:data.
+type BlockStats struct {
+	BlockSize                *Histogram `json:"block_size"`
+	TransactionsPerBlock     *Histogram `json:"transactions_per_block"`
+	OperationsPerTransaction *Histogram `json:"operations_per_transaction"`
+
+	// LargestBlock is the largest block (in serialized
+	// bytes) observed.
+	LargestBlock *types.BlockIdentifier `json:"largest_block"`
+}
+
+var (
+	blockStatsLock sync.Mutex
+
+	// blockStats are the *BlockStats of this invocation.
+	blockStats = newBlockStats()
+)
+
+func newBlockStats() *BlockStats {
+	return &BlockStats{
+		BlockSize:                &Histogram{},
+		TransactionsPerBlock:     &Histogram{},
+		OperationsPerTransaction: &Histogram{},
+	}
+}
+
+// RecordBlockObservation records the serialized size of a
+// synced block, the number of transactions it contains, and
+// the number of operations in each of those transactions.
+func RecordBlockObservation(
+	blockIdentifier *types.BlockIdentifier,
+	size int64,
+	operations []int64,
+) {
+	blockStatsLock.Lock()
+	defer blockStatsLock.Unlock()
+
+	if size > blockStats.BlockSize.Max {
+		blockStats.LargestBlock = blockIdentifier
+	}
+
+	blockStats.BlockSize.observe(size)
+	blockStats.TransactionsPerBlock.observe(int64(len(operations)))
+	for _, count := range operations {
+		blockStats.OperationsPerTransaction.observe(count)
+	}
+}
+
+// RemoveBlockObservation removes the observation of an orphaned
+// block so that it is not counted twice after a reorg.
+func RemoveBlockObservation(size int64, operations []int64) {
+	blockStatsLock.Lock()
+	defer blockStatsLock.Unlock()
+
+	blockStats.BlockSize.remove(size)
+	blockStats.TransactionsPerBlock.remove(int64(len(operations)))
+	for _, count := range operations {
+		blockStats.OperationsPerTransaction.remove(count)
+	}
+}
+
+// BlockStatsResults returns the *BlockStats of this invocation
+// (nil if no blocks were observed).
+func BlockStatsResults() *BlockStats {
+	blockStatsLock.Lock()
+	defer blockStatsLock.Unlock()
+
+	if blockStats.BlockSize.Count == 0 {
+		return nil
+	}
+
+	return &BlockStats{
+		BlockSize:                blockStats.BlockSize.copy(),
+		TransactionsPerBlock:     blockStats.TransactionsPerBlock.copy(),
+		OperationsPerTransaction: blockStats.OperationsPerTransaction.copy(),
+		LargestBlock:             blockStats.LargestBlock,
+	}
+}
+
+// Print logs BlockStats to the console.
+func (s *BlockStats) Print() {
+	histograms := []*Histogram{
+		s.BlockSize,
+		s.TransactionsPerBlock,
+		s.OperationsPerTransaction,
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{
+		"Block Observations",
+		"Block Size (bytes)",
+		"Txs Per Block",
+		"Ops Per Tx",
+	})
+	summary := [][]string{{"Count"}, {"Mean"}, {"Max"}}
+	buckets := 0
+	for _, h := range histograms {
+		summary[0] = append(summary[0], strconv.FormatInt(h.Count, 10))
+		summary[1] = append(summary[1], fmt.Sprintf("%.2f", h.Mean()))
+		summary[2] = append(summary[2], strconv.FormatInt(h.Max, 10))
+		if len(h.Buckets) > buckets {
+			buckets = len(h.Buckets)
+		}
+	}
+	table.AppendBulk(summary)
+
+	for i := 0; i < buckets; i++ {
+		row := []string{bucketLabel(i)}
+		empty := true
+		for _, h := range histograms {
+			count := int64(0)
+			if i < len(h.Buckets) {
+				count = h.Buckets[i]
+			}
+
+			if count > 0 {
+				empty = false
+			}
+			row = append(row, strconv.FormatInt(count, 10))
+		}
+
+		if !empty {
+			table.Append(row)
+		}
+	}
+
+	table.Render()
+
+	if s.LargestBlock != nil {
+		fmt.Printf("Largest Block: %s\n", types.PrintStruct(s.LargestBlock))
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := &Histogram{}
+	for _, value := range []int64{0, 1, 2, 3, 4, 7, 8, 1000} {
+		h.observe(value)
+	}
+
+	assert.Equal(t, int64(8), h.Count)
+	assert.Equal(t, int64(1025), h.Total)
+	assert.Equal(t, int64(1000), h.Max)
+	assert.Equal(t, []int64{1, 1, 2, 2, 1, 0, 0, 0, 0, 0, 1}, h.Buckets)
+	assert.Equal(t, "512-1023", bucketLabel(10))
+
+	h.remove(1000)
+	assert.Equal(t, int64(7), h.Count)
+	assert.Equal(t, int64(25), h.Total)
+	assert.Equal(t, int64(1000), h.Max)
+	assert.Equal(t, int64(0), h.Buckets[10])
+}
+
+func TestBlockStatsResults(t *testing.T) {
+	blockStats = newBlockStats()
+	defer func() {
+		blockStats = newBlockStats()
+	}()
+	assert.Nil(t, BlockStatsResults())
+
+	small := &types.BlockIdentifier{Index: 1, Hash: "block 1"}
+	large := &types.BlockIdentifier{Index: 2, Hash: "block 2"}
+	RecordBlockObservation(small, 100, []int64{1, 2})
+	RecordBlockObservation(large, 1000, []int64{3})
+	RecordBlockObservation(small, 100, []int64{})
+	RemoveBlockObservation(100, []int64{})
+
+	stats := BlockStatsResults()
+	assert.Equal(t, large, stats.LargestBlock)
+	assert.Equal(t, int64(2), stats.BlockSize.Count)
+	assert.Equal(t, int64(1000), stats.BlockSize.Max)
+	assert.Equal(t, 1.5, stats.TransactionsPerBlock.Mean())
+	assert.Equal(t, int64(3), stats.OperationsPerTransaction.Count)
+	assert.Equal(t, int64(6), stats.OperationsPerTransaction.Total)
+}
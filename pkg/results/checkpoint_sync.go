@@ -0,0 +1,56 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"sync"
+)
+
+var (
+	checkpointSyncLock sync.Mutex
+
+	// checkpointSync is the fast-forward performed
+	// in this invocation (if any).
+	checkpointSync *CheckpointSync
+)
+
+// CheckpointSync describes the blocks a check:data run
+// fast-forwarded through (checking only their structure
+// and that they form a hash chain through every checkpoint).
+// Full validation started at EndIndex.
+type CheckpointSync struct {
+	StartIndex  int64 `json:"start_index"`
+	EndIndex    int64 `json:"end_index"`
+	Checkpoints int64 `json:"checkpoints"`
+	Blocks      int64 `json:"blocks"`
+}
+
+// RecordCheckpointSync records the fast-forward
+// performed in this invocation.
+func RecordCheckpointSync(sync *CheckpointSync) {
+	checkpointSyncLock.Lock()
+	defer checkpointSyncLock.Unlock()
+
+	checkpointSync = sync
+}
+
+// CheckpointSyncResults returns the fast-forward performed
+// in this invocation (or nil if there was none).
+func CheckpointSyncResults() *CheckpointSync {
+	checkpointSyncLock.Lock()
+	defer checkpointSyncLock.Unlock()
+
+	return checkpointSync
+}
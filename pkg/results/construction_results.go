@@ -36,14 +36,56 @@ import (
 // occurred on a check:construction run and a collection
 // of interesting stats.
 type CheckConstructionResults struct {
+	Run           *RunMetadata            `json:"run,omitempty"`
+	Status        CheckStatus             `json:"status"`
+	Timing        *Timing                 `json:"timing,omitempty"`
 	Error         string                  `json:"error"`
 	EndConditions map[string]int          `json:"end_conditions"`
 	Stats         *CheckConstructionStats `json:"stats"`
+
+	// RosettaErrors are the errors returned by the
+	// implementation (grouped by code).
+	RosettaErrors []*RosettaErrorStats `json:"rosetta_errors,omitempty"`
+
+	// NotValidated are the checks that were not performed
+	// because their construction step was skipped.
+	NotValidated []string `json:"not_validated,omitempty"`
+
+	// LoadTest is only populated during a load test.
+	LoadTest *LoadTestStats `json:"load_test,omitempty"`
+
+	// Mempool is only populated when mempool
+	// verification is enabled.
+	Mempool *MempoolStats `json:"mempool,omitempty"`
+
+	// EndpointLatencies are the latencies of requests
+	// made to each construction endpoint.
+	EndpointLatencies []*EndpointLatencyStats `json:"endpoint_latencies,omitempty"`
+
+	// SchemaDrift is the canonical schema of the responses
+	// of each construction endpoint and how many responses
+	// drifted from it.
+	SchemaDrift []*SchemaDriftStats `json:"schema_drift,omitempty"`
+
+	// FailureClusters are the failed construction steps
+	// (grouped by signature).
+	FailureClusters []*FailureCluster `json:"failure_clusters,omitempty"`
 	// TODO: add test output (like check data)
 }
 
 // Print logs CheckConstructionResults to the console.
 func (c *CheckConstructionResults) Print() {
+	if c.Run != nil {
+		fmt.Printf("\n")
+		color.Cyan(
+			"Run: %s [config: %s, cli: %s, node: %s]",
+			c.Run.RunID,
+			c.Run.ConfigFingerprint,
+			c.Run.CLIVersion,
+			c.Run.NodeVersion,
+		)
+	}
+
 	if len(c.Error) > 0 {
 		fmt.Printf("\n")
 		color.Red("Error: %s", c.Error)
@@ -57,6 +99,55 @@ func (c *CheckConstructionResults) Print() {
 		c.Stats.Print()
 		fmt.Printf("\n")
 	}
+	if len(c.RosettaErrors) > 0 {
+		printRosettaErrors(c.RosettaErrors)
+		fmt.Printf("\n")
+	}
+	if c.LoadTest != nil {
+		c.LoadTest.Print()
+		fmt.Printf("\n")
+	}
+	if c.Mempool != nil {
+		c.Mempool.Print()
+		fmt.Printf("\n")
+	}
+	if len(c.EndpointLatencies) > 0 {
+		printEndpointLatencies(c.EndpointLatencies)
+		fmt.Printf("\n")
+	}
+	if printSchemaDrift(c.SchemaDrift) {
+		fmt.Printf("\n")
+	}
+	if len(c.FailureClusters) > 0 {
+		printFailureClusters(c.FailureClusters)
+		fmt.Printf("\n")
+	}
+	for _, check := range c.NotValidated {
+		color.Yellow("Not Validated: %s", check)
+	}
+	if len(c.NotValidated) > 0 {
+		fmt.Printf("\n")
+	}
+}
+
+// skippedStepChecks are the checks that are not performed
+// when a construction step is skipped.
+var skippedStepChecks = map[configuration.ConstructionStep][]string{
+	configuration.ParseConstructionStep: {
+		"/construction/parse returns the intended operations",
+		"/construction/parse returns the expected signers",
+	},
+}
+
+// NotValidatedChecks returns the checks that are not
+// performed because of skipped construction steps.
+func NotValidatedChecks(steps []configuration.ConstructionStep) []string {
+	var checks []string
+	for _, step := range steps {
+		checks = append(checks, skippedStepChecks[step]...)
+	}
+
+	return checks
 }
 
 // Output writes CheckConstructionResults to the provided
@@ -81,7 +172,19 @@ func ComputeCheckConstructionResults(
 	ctx := context.Background()
 	stats := ComputeCheckConstructionStats(ctx, cfg, counterStorage, jobStorage)
 	results := &CheckConstructionResults{
-		Stats: stats,
+		Run:               run,
+		Status:            errStatus(err),
+		Timing:            computeTiming(),
+		Stats:             stats,
+		RosettaErrors:     RosettaErrors(),
+		LoadTest:          LoadTestResults(),
+		Mempool:           MempoolResults(),
+		EndpointLatencies: EndpointLatencies(),
+		SchemaDrift:       SchemaDrift(),
+		FailureClusters:   FailureClusters(),
+	}
+	if cfg.Construction != nil {
+		results.NotValidated = NotValidatedChecks(cfg.Construction.SkippedSteps)
 	}
 
 	if err != nil {
@@ -106,7 +209,22 @@ type CheckConstructionStats struct {
 	FailedBroadcasts      int64 `json:"failed_broadcasts"`
 	AddressesCreated      int64 `json:"addresses_created"`
 
+	UnstructuredSubmitErrors int64 `json:"unstructured_submit_errors"`
+	ExternalDeposits         int64 `json:"external_deposits"`
+	DustConsolidations       int64 `json:"dust_consolidations"`
+	MultiSenderSpends        int64 `json:"multi_sender_spends"`
+	CPFPTransactions         int64 `json:"cpfp_transactions"`
+	CPFPScenarios            int64 `json:"cpfp_scenarios"`
+	ReplayedTransactions     int64 `json:"replayed_transactions"`
+	PipelineTransfers        int64 `json:"pipeline_transfers"`
+	ExcludedAddresses        int64 `json:"excluded_addresses"`
+
 	WorkflowsCompleted map[string]int64 `json:"workflows_completed"`
+
+	// SignaturesByCurve is the number of signatures
+	// of each curve type (only curves with signatures
+	// are included).
+	SignaturesByCurve map[string]int64 `json:"signatures_by_curve,omitempty"`
 }
 
 // PrintCounts logs counter-related stats to the console.
@@ -140,6 +258,63 @@ func (c *CheckConstructionStats) PrintCounts() {
 		"# of transactions that exceeded broadcast limit",
 		strconv.FormatInt(c.FailedBroadcasts, 10),
 	})
+	table.Append([]string{
+		"Unstructured Submit Errors",
+		"# of rejected submissions without a Rosetta error",
+		strconv.FormatInt(c.UnstructuredSubmitErrors, 10),
+	})
+	table.Append([]string{
+		"External Deposits",
+		"# of inbound transfers not broadcast by the rosetta-cli",
+		strconv.FormatInt(c.ExternalDeposits, 10),
+	})
+	table.Append([]string{
+		"Dust Consolidations",
+		"# of confirmed transactions consolidating dust coins",
+		strconv.FormatInt(c.DustConsolidations, 10),
+	})
+	table.Append([]string{
+		"Multi-Sender Spends",
+		"# of confirmed transactions spending coins of several accounts",
+		strconv.FormatInt(c.MultiSenderSpends, 10),
+	})
+	table.Append([]string{
+		"CPFP Transactions",
+		"# of confirmed parents and children of fee-bumping scenarios",
+		strconv.FormatInt(c.CPFPTransactions, 10),
+	})
+	table.Append([]string{
+		"CPFP Scenarios",
+		"# of verified child-pays-for-parent scenarios",
+		strconv.FormatInt(c.CPFPScenarios, 10),
+	})
+	table.Append([]string{
+		"Replayed Transactions",
+		"# of confirmed transactions replaying observed transactions",
+		strconv.FormatInt(c.ReplayedTransactions, 10),
+	})
+	table.Append([]string{
+		"Pipeline Transfers",
+		"# of confirmed transfers constructed by sender pipelines",
+		strconv.FormatInt(c.PipelineTransfers, 10),
+	})
+	table.Append([]string{
+		"Excluded Addresses",
+		"# of addresses excluded after reaching max address reuse",
+		strconv.FormatInt(c.ExcludedAddresses, 10),
+	})
+	for _, curveType := range curveTypes {
+		count, ok := c.SignaturesByCurve[string(curveType)]
+		if !ok {
+			continue
+		}
+
+		table.Append([]string{
+			fmt.Sprintf("Signatures (%s)", curveType),
+			fmt.Sprintf("# of payloads signed with %s keys", curveType),
+			strconv.FormatInt(count, 10),
+		})
+	}
 
 	table.Render()
 }
@@ -208,6 +383,78 @@ func ComputeCheckConstructionStats(
 		return nil
 	}
 
+	unstructuredSubmitErrors, err := counters.Get(ctx, UnstructuredSubmitErrorsCounter)
+	if err != nil {
+		log.Printf("%s cannot get unstructured submit errors counter\n", err.Error())
+		return nil
+	}
+
+	externalDeposits, err := counters.Get(ctx, ExternalDepositsCounter)
+	if err != nil {
+		log.Printf("%s cannot get external deposits counter\n", err.Error())
+		return nil
+	}
+
+	dustConsolidations, err := counters.Get(ctx, DustConsolidationsCounter)
+	if err != nil {
+		log.Printf("%s cannot get dust consolidations counter\n", err.Error())
+		return nil
+	}
+
+	multiSenderSpends, err := counters.Get(ctx, MultiSenderSpendsCounter)
+	if err != nil {
+		log.Printf("%s cannot get multi-sender spends counter\n", err.Error())
+		return nil
+	}
+
+	cpfpTransactions, err := counters.Get(ctx, CPFPTransactionsCounter)
+	if err != nil {
+		log.Printf("%s cannot get cpfp transactions counter\n", err.Error())
+		return nil
+	}
+
+	cpfpScenarios, err := counters.Get(ctx, CPFPScenariosCounter)
+	if err != nil {
+		log.Printf("%s cannot get cpfp scenarios counter\n", err.Error())
+		return nil
+	}
+
+	replayedTransactions, err := counters.Get(ctx, ReplayedTransactionsCounter)
+	if err != nil {
+		log.Printf("%s cannot get replayed transactions counter\n", err.Error())
+		return nil
+	}
+
+	pipelineTransfers, err := counters.Get(ctx, PipelineTransfersCounter)
+	if err != nil {
+		log.Printf("%s cannot get pipeline transfers counter\n", err.Error())
+		return nil
+	}
+
+	excludedAddresses, err := counters.Get(ctx, ExcludedAddressesCounter)
+	if err != nil {
+		log.Printf("%s cannot get excluded addresses counter\n", err.Error())
+		return nil
+	}
+
+	var signaturesByCurve map[string]int64
+	for _, curveType := range curveTypes {
+		signatures, err := counters.Get(ctx, SignaturesCounter(curveType))
+		if err != nil {
+			log.Printf("%s cannot get %s signatures counter\n", err.Error(), curveType)
+			return nil
+		}
+
+		if signatures.Sign() == 0 {
+			continue
+		}
+
+		if signaturesByCurve == nil {
+			signaturesByCurve = map[string]int64{}
+		}
+		signaturesByCurve[string(curveType)] = signatures.Int64()
+	}
+
 	workflowsCompleted := map[string]int64{}
 	for _, workflow := range config.Construction.Workflows {
 		completed, err := jobs.Completed(ctx, workflow.Name)
@@ -220,12 +467,22 @@ func ComputeCheckConstructionStats(
 	}
 
 	return &CheckConstructionStats{
-		TransactionsCreated:   transactionsCreated.Int64(),
-		TransactionsConfirmed: transactionsConfirmed.Int64(),
-		StaleBroadcasts:       staleBroadcasts.Int64(),
-		FailedBroadcasts:      failedBroadcasts.Int64(),
-		AddressesCreated:      addressesCreated.Int64(),
-		WorkflowsCompleted:    workflowsCompleted,
+		TransactionsCreated:      transactionsCreated.Int64(),
+		TransactionsConfirmed:    transactionsConfirmed.Int64(),
+		StaleBroadcasts:          staleBroadcasts.Int64(),
+		FailedBroadcasts:         failedBroadcasts.Int64(),
+		AddressesCreated:         addressesCreated.Int64(),
+		UnstructuredSubmitErrors: unstructuredSubmitErrors.Int64(),
+		ExternalDeposits:         externalDeposits.Int64(),
+		DustConsolidations:       dustConsolidations.Int64(),
+		MultiSenderSpends:        multiSenderSpends.Int64(),
+		CPFPTransactions:         cpfpTransactions.Int64(),
+		CPFPScenarios:            cpfpScenarios.Int64(),
+		ReplayedTransactions:     replayedTransactions.Int64(),
+		PipelineTransfers:        pipelineTransfers.Int64(),
+		ExcludedAddresses:        excludedAddresses.Int64(),
+		WorkflowsCompleted:       workflowsCompleted,
+		SignaturesByCurve:        signaturesByCurve,
 	}
 }
 
@@ -235,6 +492,34 @@ func ComputeCheckConstructionStats(
 type CheckConstructionProgress struct {
 	Broadcasting int `json:"broadcasting"`
 	Processing   int `json:"processing"`
+
+	// Pending contains the status of each transaction
+	// that has been broadcast but not yet confirmed.
+	Pending []*BroadcastStatus `json:"pending,omitempty"`
+}
+
+// BroadcastStatus is the status of a transaction that
+// has been broadcast but not yet confirmed. Transactions
+// that do not appear on-chain within the stale depth are
+// automatically rebroadcast (up to the broadcast limit).
+type BroadcastStatus struct {
+	Identifier            string                       `json:"identifier"`
+	TransactionIdentifier *types.TransactionIdentifier `json:"transaction_identifier"`
+	ConfirmationDepth     int64                        `json:"confirmation_depth"`
+	Broadcasts            int                          `json:"broadcasts"`
+	LastBroadcast         *types.BlockIdentifier       `json:"last_broadcast,omitempty"`
+}
+
+// NewBroadcastStatus returns the *BroadcastStatus
+// of a pending broadcast.
+func NewBroadcastStatus(broadcast *modules.Broadcast) *BroadcastStatus {
+	return &BroadcastStatus{
+		Identifier:            broadcast.Identifier,
+		TransactionIdentifier: broadcast.TransactionIdentifier,
+		ConfirmationDepth:     broadcast.ConfirmationDepth,
+		Broadcasts:            broadcast.Broadcasts,
+		LastBroadcast:         broadcast.LastBroadcast,
+	}
 }
 
 // ComputeCheckConstructionProgress computes
@@ -256,16 +541,27 @@ func ComputeCheckConstructionProgress(
 		return nil
 	}
 
+	pending := make([]*BroadcastStatus, len(inflight))
+	for i, broadcast := range inflight {
+		pending[i] = NewBroadcastStatus(broadcast)
+	}
+
 	return &CheckConstructionProgress{
 		Broadcasting: len(inflight),
 		Processing:   len(processing),
+		Pending:      pending,
 	}
 }
 
 // CheckConstructionStatus contains CheckConstructionStats.
 type CheckConstructionStatus struct {
+	Run      *RunMetadata               `json:"run,omitempty"`
 	Stats    *CheckConstructionStats    `json:"stats"`
 	Progress *CheckConstructionProgress `json:"progress"`
+
+	// Paused indicates that no new transactions are
+	// being created (pending broadcasts are still tracked).
+	Paused bool `json:"paused"`
 }
 
 // ComputeCheckConstructionStatus returns a populated
@@ -278,6 +574,7 @@ func ComputeCheckConstructionStatus(
 	jobs *modules.JobStorage,
 ) *CheckConstructionStatus {
 	return &CheckConstructionStatus{
+		Run:      run,
 		Stats:    ComputeCheckConstructionStats(ctx, config, counters, jobs),
 		Progress: ComputeCheckConstructionProgress(ctx, broadcasts, jobs),
 	}
@@ -312,6 +609,7 @@ func ExitConstruction(
 		jobStorage,
 	)
 	if results != nil {
+		recordPhaseConstruction(results)
 		results.Print()
 		if config.Construction != nil {
 			results.Output(config.Construction.ResultsOutputFile)
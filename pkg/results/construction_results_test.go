@@ -0,0 +1,80 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeCheckConstructionProgress(t *testing.T) {
+	ctx := context.Background()
+
+	dbDir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dbDir)
+
+	db, err := database.NewBadgerDatabase(ctx, dbDir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	broadcastStorage := modules.NewBroadcastStorage(db, 0, 0, 0, false, 0)
+	jobStorage := modules.NewJobStorage(db)
+
+	// Nothing is pending before a broadcast
+	progress := ComputeCheckConstructionProgress(ctx, broadcastStorage, jobStorage)
+	assert.Equal(t, &CheckConstructionProgress{
+		Pending: []*BroadcastStatus{},
+	}, progress)
+
+	dbTx := db.Transaction(ctx)
+	assert.NoError(t, broadcastStorage.Broadcast(
+		ctx,
+		dbTx,
+		"transfer",
+		&types.NetworkIdentifier{Blockchain: "Bitcoin", Network: "Mainnet"},
+		[]*types.Operation{},
+		&types.TransactionIdentifier{Hash: "tx1"},
+		"payload",
+		3,
+	))
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	progress = ComputeCheckConstructionProgress(ctx, broadcastStorage, jobStorage)
+	assert.Equal(t, &CheckConstructionProgress{
+		Broadcasting: 1,
+		Pending: []*BroadcastStatus{
+			{
+				Identifier:            "transfer",
+				TransactionIdentifier: &types.TransactionIdentifier{Hash: "tx1"},
+				ConfirmationDepth:     3,
+			},
+		},
+	}, progress)
+
+	// Statuses are reported in the JSON status
+	assert.Equal(
+		t,
+		`{"broadcasting":1,"processing":0,"pending":[{"identifier":"transfer",`+
+			`"transaction_identifier":{"hash":"tx1"},"confirmation_depth":3,"broadcasts":0}]}`,
+		types.PrintStruct(progress),
+	)
+}
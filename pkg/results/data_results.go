@@ -21,6 +21,7 @@ import (
 	"log"
 	"math/big"
 	"os"
+	"sort"
 	"strconv"
 
 	pkgError "github.com/pkg/errors"
@@ -52,23 +53,130 @@ type EndCondition struct {
 	Detail string                              `json:"detail"`
 }
 
+// SyncScope describes the range of blocks a partial check:data
+// run reconciled balance changes in.
+type SyncScope struct {
+	StartIndex int64 `json:"start_index"`
+	EndIndex   int64 `json:"end_index"`
+
+	// BaselineAtStart is true if account balances at the start of
+	// the range were used as the baseline for reconciliation.
+	BaselineAtStart bool `json:"baseline_at_start"`
+}
+
 // CheckDataResults contains any error that occurred
 // on a check:data run, the outcome of certain tests,
 // and a collection of interesting stats.
 type CheckDataResults struct {
+	Run          *RunMetadata    `json:"run,omitempty"`
+	Status       CheckStatus     `json:"status"`
+	Timing       *Timing         `json:"timing,omitempty"`
 	Error        string          `json:"error"`
 	EndCondition *EndCondition   `json:"end_condition"`
 	Tests        *CheckDataTests `json:"tests"`
 	Stats        *CheckDataStats `json:"stats"`
+
+	// ViolationTrace is populated when the block that caused
+	// Error was re-fetched and re-processed with tracing.
+	ViolationTrace *ViolationTrace `json:"violation_trace,omitempty"`
+
+	// RosettaErrors are the errors returned by the
+	// implementation (grouped by code).
+	RosettaErrors []*RosettaErrorStats `json:"rosetta_errors,omitempty"`
+
+	// ReplayMismatches are the replayed requests that returned
+	// inconsistent answers over distinct connections (grouped
+	// by path). Any mismatch fails the run.
+	ReplayMismatches []*ReplayMismatchStats `json:"replay_mismatches,omitempty"`
+
+	// OperationTypeAliases is the number of operations of each
+	// aliased (old) operation type that were renamed before
+	// validation.
+	OperationTypeAliases map[string]int64 `json:"operation_type_aliases,omitempty"`
+
+	// BalanceRules is the number of operations of each
+	// operation type modified by a balance rule.
+	BalanceRules map[string]int64 `json:"balance_rules,omitempty"`
+
+	// ReconciliationSkips are the triaged reconciliation failures
+	// that were active (and how many failures each suppressed).
+	ReconciliationSkips []*ReconciliationSkipStats `json:"reconciliation_skips,omitempty"`
+
+	// FailureClusters are the reconciliation failures and
+	// ambiguous conditions (grouped by signature).
+	FailureClusters []*FailureCluster `json:"failure_clusters,omitempty"`
+
+	// BlockStats are the distributions of block size, transactions
+	// per block, and operations per transaction over the synced range.
+	BlockStats *BlockStats `json:"block_stats,omitempty"`
+
+	// OperationImpacts are the number of operations, distinct
+	// accounts touched, and value moved by each operation type
+	// over the synced range.
+	OperationImpacts []*OperationImpactStats `json:"operation_impacts,omitempty"`
+
+	// Scope is populated when the run was a partial sync. All
+	// tests and stats only cover the blocks in this range.
+	Scope *SyncScope `json:"scope,omitempty"`
+
+	// CheckpointSync is populated when the run fast-forwarded
+	// through checkpoints before fully validating blocks.
+	CheckpointSync *CheckpointSync `json:"checkpoint_sync,omitempty"`
+
+	// Timeline is the changes made to the run while it was in
+	// progress (ex: pausing reconciliation).
+	Timeline []*TimelineEvent `json:"timeline,omitempty"`
 }
 
 // Print logs CheckDataResults to the console.
 func (c *CheckDataResults) Print() {
+	if c.Run != nil {
+		fmt.Printf("\n")
+		color.Cyan(
+			"Run: %s [config: %s, cli: %s, node: %s]",
+			c.Run.RunID,
+			c.Run.ConfigFingerprint,
+			c.Run.CLIVersion,
+			c.Run.NodeVersion,
+		)
+	}
+
+	if c.Scope != nil {
+		baseline := "parent of first seen block"
+		if c.Scope.BaselineAtStart {
+			baseline = "start of range"
+		}
+
+		fmt.Printf("\n")
+		color.Cyan(
+			"Range-Scoped: blocks %d to %d [baseline: %s]",
+			c.Scope.StartIndex,
+			c.Scope.EndIndex,
+			baseline,
+		)
+	}
+
+	if c.CheckpointSync != nil {
+		fmt.Printf("\n")
+		color.Cyan(
+			"Checkpoint-Synced: blocks %d to %d [%d checkpoints, %d blocks fast-forwarded]",
+			c.CheckpointSync.StartIndex,
+			c.CheckpointSync.EndIndex,
+			c.CheckpointSync.Checkpoints,
+			c.CheckpointSync.Blocks,
+		)
+	}
+
 	if len(c.Error) > 0 {
 		fmt.Printf("\n")
 		color.Red("Error: %s", c.Error)
 	}
 
+	if c.ViolationTrace != nil {
+		fmt.Printf("\n")
+		c.ViolationTrace.Print()
+	}
+
 	if c.EndCondition != nil {
 		fmt.Printf("\n")
 		color.Green("Success: %s [%s]", c.EndCondition.Type, c.EndCondition.Detail)
@@ -83,6 +191,42 @@ func (c *CheckDataResults) Print() {
 		c.Stats.Print()
 		fmt.Printf("\n")
 	}
+	if len(c.RosettaErrors) > 0 {
+		printRosettaErrors(c.RosettaErrors)
+		fmt.Printf("\n")
+	}
+	if len(c.ReplayMismatches) > 0 {
+		printReplayMismatches(c.ReplayMismatches)
+		fmt.Printf("\n")
+	}
+	if len(c.OperationTypeAliases) > 0 {
+		printOperationTypeAliases(c.OperationTypeAliases)
+		fmt.Printf("\n")
+	}
+	if len(c.BalanceRules) > 0 {
+		printBalanceRules(c.BalanceRules)
+		fmt.Printf("\n")
+	}
+	if len(c.ReconciliationSkips) > 0 {
+		printReconciliationSkips(c.ReconciliationSkips)
+		fmt.Printf("\n")
+	}
+	if len(c.FailureClusters) > 0 {
+		printFailureClusters(c.FailureClusters)
+		fmt.Printf("\n")
+	}
+	if c.BlockStats != nil {
+		c.BlockStats.Print()
+		fmt.Printf("\n")
+	}
+	if len(c.OperationImpacts) > 0 {
+		printOperationImpacts(c.OperationImpacts)
+		fmt.Printf("\n")
+	}
+	if len(c.Timeline) > 0 {
+		printTimeline(c.Timeline)
+		fmt.Printf("\n")
+	}
 }
 
 // Output writes *CheckDataResults to the provided
@@ -99,17 +243,37 @@ func (c *CheckDataResults) Output(path string) {
 // CheckDataStats contains interesting stats that
 // are counted while running the check:data.
 type CheckDataStats struct {
-	Blocks                  int64   `json:"blocks"`
-	Orphans                 int64   `json:"orphans"`
-	Transactions            int64   `json:"transactions"`
-	Operations              int64   `json:"operations"`
-	Accounts                int64   `json:"accounts"`
-	ActiveReconciliations   int64   `json:"active_reconciliations"`
-	InactiveReconciliations int64   `json:"inactive_reconciliations"`
-	ExemptReconciliations   int64   `json:"exempt_reconciliations"`
-	FailedReconciliations   int64   `json:"failed_reconciliations"`
-	SkippedReconciliations  int64   `json:"skipped_reconciliations"`
-	ReconciliationCoverage  float64 `json:"reconciliation_coverage"`
+	Blocks                    int64   `json:"blocks"`
+	Orphans                   int64   `json:"orphans"`
+	Transactions              int64   `json:"transactions"`
+	Operations                int64   `json:"operations"`
+	Accounts                  int64   `json:"accounts"`
+	ActiveReconciliations     int64   `json:"active_reconciliations"`
+	InactiveReconciliations   int64   `json:"inactive_reconciliations"`
+	ExemptReconciliations     int64   `json:"exempt_reconciliations"`
+	FailedReconciliations     int64   `json:"failed_reconciliations"`
+	SkippedReconciliations    int64   `json:"skipped_reconciliations"`
+	ReconciliationCoverage    float64 `json:"reconciliation_coverage"`
+	AmbiguousConditions       int64   `json:"ambiguous_conditions"`
+	ExplorerChecks            int64   `json:"explorer_checks"`
+	HistoricalReconciliations int64   `json:"historical_reconciliations"`
+	StateCommitments          int64   `json:"state_commitments"`
+
+	// CustomCounters are the values of user-defined
+	// counters (keyed by name).
+	CustomCounters map[string]int64 `json:"custom_counters,omitempty"`
+}
+
+// CustomCounterNames returns the names of all
+// custom counters in sorted order.
+func (c *CheckDataStats) CustomCounterNames() []string {
+	names := make([]string, 0, len(c.CustomCounters))
+	for name := range c.CustomCounters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
 }
 
 // Print logs CheckDataStats to the console.
@@ -175,6 +339,43 @@ func (c *CheckDataStats) Print() {
 			fmt.Sprintf("%f%%", c.ReconciliationCoverage*utils.OneHundred),
 		},
 	)
+	table.Append(
+		[]string{
+			"Ambiguous Conditions",
+			"# of conditions the spec is ambiguous about (ex: empty metadata)",
+			strconv.FormatInt(c.AmbiguousConditions, 10),
+		},
+	)
+	table.Append(
+		[]string{
+			"Explorer Checks",
+			"# of transactions and balances matching an explorer",
+			strconv.FormatInt(c.ExplorerChecks, 10),
+		},
+	)
+	table.Append(
+		[]string{
+			"Historical Reconciliations",
+			"# of balances reconciled at a sampled historical block",
+			strconv.FormatInt(c.HistoricalReconciliations, 10),
+		},
+	)
+	table.Append(
+		[]string{
+			"State Commitments",
+			"# of checkpoints where balances matched the chain's state commitment",
+			strconv.FormatInt(c.StateCommitments, 10),
+		},
+	)
+	for _, name := range c.CustomCounterNames() {
+		table.Append(
+			[]string{
+				name,
+				"# of operations matching custom counter",
+				strconv.FormatInt(c.CustomCounters[name], 10),
+			},
+		)
+	}
 
 	table.Render()
 }
@@ -184,6 +385,7 @@ func ComputeCheckDataStats(
 	ctx context.Context,
 	counters *modules.CounterStorage,
 	balances *modules.BalanceStorage,
+	customCounters []*configuration.CustomCounter,
 ) *CheckDataStats {
 	if counters == nil {
 		return nil
@@ -249,17 +451,58 @@ func ComputeCheckDataStats(
 		return nil
 	}
 
+	ambiguousConditions, err := counters.Get(ctx, AmbiguousConditionsCounter)
+	if err != nil {
+		log.Printf("%s: cannot get ambiguous conditions counter", err.Error())
+		return nil
+	}
+
+	explorerChecks, err := counters.Get(ctx, ExplorerChecksCounter)
+	if err != nil {
+		log.Printf("%s: cannot get explorer checks counter", err.Error())
+		return nil
+	}
+
+	historicalReconciliations, err := counters.Get(ctx, HistoricalReconciliationsCounter)
+	if err != nil {
+		log.Printf("%s: cannot get historical reconciliations counter", err.Error())
+		return nil
+	}
+
+	stateCommitments, err := counters.Get(ctx, StateCommitmentsCounter)
+	if err != nil {
+		log.Printf("%s: cannot get state commitments counter", err.Error())
+		return nil
+	}
+
 	stats := &CheckDataStats{
-		Blocks:                  blocks.Int64(),
-		Orphans:                 orphans.Int64(),
-		Transactions:            txs.Int64(),
-		Operations:              ops.Int64(),
-		Accounts:                accounts.Int64(),
-		ActiveReconciliations:   activeReconciliations.Int64(),
-		InactiveReconciliations: inactiveReconciliations.Int64(),
-		ExemptReconciliations:   exemptReconciliations.Int64(),
-		FailedReconciliations:   failedReconciliations.Int64(),
-		SkippedReconciliations:  skippedReconciliations.Int64(),
+		Blocks:                    blocks.Int64(),
+		Orphans:                   orphans.Int64(),
+		Transactions:              txs.Int64(),
+		Operations:                ops.Int64(),
+		Accounts:                  accounts.Int64(),
+		ActiveReconciliations:     activeReconciliations.Int64(),
+		InactiveReconciliations:   inactiveReconciliations.Int64(),
+		ExemptReconciliations:     exemptReconciliations.Int64(),
+		FailedReconciliations:     failedReconciliations.Int64(),
+		SkippedReconciliations:    skippedReconciliations.Int64(),
+		AmbiguousConditions:       ambiguousConditions.Int64(),
+		ExplorerChecks:            explorerChecks.Int64(),
+		HistoricalReconciliations: historicalReconciliations.Int64(),
+		StateCommitments:          stateCommitments.Int64(),
+	}
+
+	if len(customCounters) > 0 {
+		stats.CustomCounters = map[string]int64{}
+		for _, counter := range customCounters {
+			value, err := counters.Get(ctx, counter.Name)
+			if err != nil {
+				log.Printf("%s: cannot get custom counter %s", err.Error(), counter.Name)
+				return nil
+			}
+
+			stats.CustomCounters[counter.Name] = value.Int64()
+		}
 	}
 
 	if balances != nil {
@@ -393,8 +636,17 @@ func ComputeCheckDataProgress(
 // CheckDataStatus contains both CheckDataStats
 // and CheckDataProgress.
 type CheckDataStatus struct {
-	Stats    *CheckDataStats    `json:"stats"`
-	Progress *CheckDataProgress `json:"progress"`
+	Run            *RunMetadata          `json:"run,omitempty"`
+	Stats          *CheckDataStats       `json:"stats"`
+	Progress       *CheckDataProgress    `json:"progress"`
+	Reconciliation *ReconciliationStatus `json:"reconciliation,omitempty"`
+}
+
+// ReconciliationStatus indicates which types of
+// reconciliation are paused.
+type ReconciliationStatus struct {
+	ActivePaused   bool `json:"active_paused"`
+	InactivePaused bool `json:"inactive_paused"`
 }
 
 // ComputeCheckDataStatus returns a populated
@@ -407,12 +659,15 @@ func ComputeCheckDataStatus(
 	fetcher *fetcher.Fetcher,
 	network *types.NetworkIdentifier,
 	reconciler *reconciler.Reconciler,
+	customCounters []*configuration.CustomCounter,
 ) *CheckDataStatus {
 	return &CheckDataStatus{
+		Run: run,
 		Stats: ComputeCheckDataStats(
 			ctx,
 			counters,
 			balances,
+			customCounters,
 		),
 		Progress: ComputeCheckDataProgress(
 			ctx,
@@ -531,6 +786,7 @@ func BlockSyncingTest(err error, blocksSynced bool) *bool {
 	syncPass := true
 	storageFailed, _ := storageErrs.Err(err)
 	if syncer.Err(err) ||
+		errors.Is(err, ErrCheckpointMismatch) ||
 		(storageFailed && !errors.Is(err, storageErrs.ErrNegativeBalance)) {
 		syncPass = false
 	}
@@ -664,10 +920,33 @@ func ComputeCheckDataResults(
 ) *CheckDataResults {
 	ctx := context.Background()
 	tests := ComputeCheckDataTests(ctx, cfg, err, counterStorage)
-	stats := ComputeCheckDataStats(ctx, counterStorage, balanceStorage)
+	stats := ComputeCheckDataStats(ctx, counterStorage, balanceStorage, cfg.Data.CustomCounters)
 	results := &CheckDataResults{
-		Tests: tests,
-		Stats: stats,
+		Run:            run,
+		Status:         dataStatus(err, tests),
+		Timing:         computeTiming(),
+		Tests:          tests,
+		Stats:          stats,
+		ViolationTrace: ViolationTraceResults(),
+		RosettaErrors:  RosettaErrors(),
+
+		ReplayMismatches:     ReplayMismatches(),
+		OperationTypeAliases: OperationTypeAliasUsage(),
+		BalanceRules:         BalanceRuleUsage(),
+		ReconciliationSkips:  ActiveReconciliationSkips(),
+		FailureClusters:      FailureClusters(),
+		BlockStats:           BlockStatsResults(),
+		OperationImpacts:     OperationImpacts(),
+		CheckpointSync:       CheckpointSyncResults(),
+		Timeline:             Timeline(),
+	}
+
+	if cfg.Data.PartialSync != nil {
+		results.Scope = &SyncScope{
+			StartIndex:      *cfg.Data.StartIndex,
+			EndIndex:        *cfg.Data.EndConditions.Index,
+			BaselineAtStart: cfg.Data.PartialSync.BaselineAtStart,
+		}
 	}
 
 	if err != nil {
@@ -709,6 +988,13 @@ func ExitData(
 	endCondition configuration.CheckDataEndCondition,
 	endConditionDetail string,
 ) error {
+	// Inconsistent answers don't stop the run (the caller
+	// always receives the original answer), so they fail
+	// it once it ends.
+	if err == nil {
+		err = replayMismatchError()
+	}
+
 	if !config.ErrorStackTraceDisabled {
 		err = pkgError.WithStack(err)
 	}
@@ -722,6 +1008,7 @@ func ExitData(
 		endConditionDetail,
 	)
 	if results != nil {
+		recordPhaseData(results)
 		results.Print()
 		results.Output(config.Data.ResultsOutputFile)
 	}
@@ -102,6 +102,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 			cfg: configuration.DefaultConfiguration(),
 			err: []error{nil},
 			result: &CheckDataResults{
+				Status: PassStatus,
 				Tests: &CheckDataTests{
 					RequestResponse:   true,
 					ResponseAssertion: true,
@@ -199,6 +200,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 			blockCount:            100,
 			err:                   []error{nil},
 			result: &CheckDataResults{
+				Status: PassStatus,
 				Tests: &CheckDataTests{
 					RequestResponse:   true,
 					ResponseAssertion: true,
@@ -216,6 +218,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 			operationCount:        1,
 			err:                   []error{nil},
 			result: &CheckDataResults{
+				Status: PassStatus,
 				Tests: &CheckDataTests{
 					RequestResponse:   true,
 					ResponseAssertion: true,
@@ -239,6 +242,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 			totalAccounts:           4,
 			err:                     []error{nil},
 			result: &CheckDataResults{
+				Status: PassStatus,
 				Tests: &CheckDataTests{
 					RequestResponse:   true,
 					ResponseAssertion: true,
@@ -265,6 +269,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 			totalAccounts:         2,
 			err:                   []error{nil},
 			result: &CheckDataResults{
+				Status: PassStatus,
 				Tests: &CheckDataTests{
 					RequestResponse:   true,
 					ResponseAssertion: true,
@@ -294,6 +299,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 			endConditionDetail:      "index 100",
 			err:                     []error{nil},
 			result: &CheckDataResults{
+				Status: PassStatus,
 				EndCondition: &EndCondition{
 					Type:   configuration.IndexEndCondition,
 					Detail: "index 100",
@@ -389,6 +395,7 @@ func TestComputeCheckDataResults(t *testing.T) {
 					testName = err.Error()
 					testErr = fmt.Errorf("%w: test wrapping", err)
 					test.result.Error = testErr.Error()
+					test.result.Status = FailStatus
 				}
 
 				dir, err := utils.CreateTempDir()
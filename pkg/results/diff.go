@@ -0,0 +1,251 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ChangeKind describes whether a change between two
+// results files is a regression, an improvement, or
+// neither.
+type ChangeKind string
+
+const (
+	// Regression is a change that indicates the
+	// implementation got worse (ex: a test failed).
+	Regression ChangeKind = "regression"
+
+	// Improvement is a change that indicates the
+	// implementation got better (ex: higher coverage).
+	Improvement ChangeKind = "improvement"
+
+	// Changed is a change that is neither a
+	// regression nor an improvement (ex: blocks synced).
+	Changed ChangeKind = "changed"
+)
+
+// regressionFields are substrings of fields where an
+// increase is a regression.
+var regressionFields = []string{
+	"failed",
+	"error",
+	"stale",
+	"skipped",
+	"orphan",
+	"mismatch",
+}
+
+// improvementFields are substrings of fields where an
+// increase is an improvement.
+var improvementFields = []string{
+	"coverage",
+	"confirmed",
+	"completed",
+}
+
+// ResultsChange is a field that differs between
+// two results files.
+type ResultsChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+	Kind  ChangeKind  `json:"kind"`
+}
+
+// DiffResults compares two serialized CheckDataResults or
+// CheckConstructionResults and returns all fields that differ
+// (sorted by field). Run metadata is ignored because it differs
+// in every run.
+func DiffResults(oldResults []byte, newResults []byte) ([]*ResultsChange, error) {
+	var oldParsed, newParsed map[string]interface{}
+	if err := json.Unmarshal(oldResults, &oldParsed); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse old results", err)
+	}
+	if err := json.Unmarshal(newResults, &newParsed); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse new results", err)
+	}
+	delete(oldParsed, "run")
+	delete(newParsed, "run")
+
+	oldFields := map[string]interface{}{}
+	flattenResults("", oldParsed, oldFields)
+	newFields := map[string]interface{}{}
+	flattenResults("", newParsed, newFields)
+
+	fields := map[string]struct{}{}
+	for field := range oldFields {
+		fields[field] = struct{}{}
+	}
+	for field := range newFields {
+		fields[field] = struct{}{}
+	}
+
+	changes := []*ResultsChange{}
+	for field := range fields {
+		oldValue, newValue := oldFields[field], newFields[field]
+		if oldValue == newValue {
+			continue
+		}
+
+		changes = append(changes, &ResultsChange{
+			Field: field,
+			Old:   oldValue,
+			New:   newValue,
+			Kind:  classifyChange(field, oldValue, newValue),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes, nil
+}
+
+// flattenResults populates fields with each scalar in value
+// keyed by its dot-separated path. Rosetta errors are keyed by
+// code (instead of position) so that they can be compared
+// across runs.
+func flattenResults(path string, value interface{}, fields map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenResults(joinField(path, key), child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			key := fmt.Sprintf("%d", i)
+			if path == "rosetta_errors" {
+				if rosettaErr, ok := child.(map[string]interface{}); ok {
+					key = fmt.Sprintf("%v", rosettaErr["code"])
+				}
+			}
+
+			flattenResults(joinField(path, key), child, fields)
+		}
+	case nil:
+	default:
+		fields[path] = v
+	}
+}
+
+// joinField joins a path and a key.
+func joinField(path string, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// fieldMatches returns a boolean indicating if
+// field contains any of substrings.
+func fieldMatches(field string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(field, substring) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classifyChange determines the ChangeKind of a field
+// that changed from oldValue to newValue.
+func classifyChange(field string, oldValue interface{}, newValue interface{}) ChangeKind {
+	if field == "error" {
+		switch {
+		case oldValue == "":
+			return Regression
+		case newValue == "":
+			return Improvement
+		default:
+			return Changed
+		}
+	}
+
+	// A test that passed now fails (or vice versa)
+	if strings.HasPrefix(field, "tests.") {
+		if newValue == false {
+			return Regression
+		}
+		if newValue == true {
+			return Improvement
+		}
+
+		return Changed
+	}
+
+	// Rosetta errors and counters that are missing
+	// in one results file are 0.
+	oldNumber, oldOK := oldValue.(float64)
+	newNumber, newOK := newValue.(float64)
+	if !oldOK && oldValue != nil || !newOK && newValue != nil {
+		return Changed
+	}
+
+	increased := newNumber > oldNumber
+	switch {
+	case strings.HasPrefix(field, "rosetta_errors.") && strings.HasSuffix(field, ".count"),
+		fieldMatches(field, regressionFields):
+		if increased {
+			return Regression
+		}
+
+		return Improvement
+	case fieldMatches(field, improvementFields):
+		if increased {
+			return Improvement
+		}
+
+		return Regression
+	default:
+		return Changed
+	}
+}
+
+// PrintResultsDiff logs *ResultsChange to the console.
+func PrintResultsDiff(changes []*ResultsChange) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Field", "Old", "New", "Kind"})
+	for _, change := range changes {
+		table.Append([]string{
+			change.Field,
+			formatResultsValue(change.Old),
+			formatResultsValue(change.New),
+			string(change.Kind),
+		})
+	}
+
+	table.Render()
+}
+
+// formatResultsValue formats a field value for printing.
+func formatResultsValue(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffResults(t *testing.T) {
+	tru := true
+	fls := false
+	oldResults := &CheckDataResults{
+		Run:   &RunMetadata{RunID: "old"},
+		Tests: &CheckDataTests{RequestResponse: true, Reconciliation: &tru},
+		Stats: &CheckDataStats{
+			Blocks:                 10,
+			FailedReconciliations:  1,
+			ReconciliationCoverage: 0.5,
+		},
+		RosettaErrors: []*RosettaErrorStats{
+			{Code: 1, Message: "timeout", Retriable: true, Count: 2},
+		},
+	}
+	newResults := &CheckDataResults{
+		Run:   &RunMetadata{RunID: "new"},
+		Error: "reconciliation failure",
+		Tests: &CheckDataTests{RequestResponse: true, Reconciliation: &fls},
+		Stats: &CheckDataStats{
+			Blocks:                 20,
+			FailedReconciliations:  0,
+			ReconciliationCoverage: 0.4,
+		},
+		RosettaErrors: []*RosettaErrorStats{
+			{Code: 1, Message: "timeout", Retriable: true, Count: 5},
+		},
+	}
+
+	oldBytes, err := json.Marshal(oldResults)
+	assert.NoError(t, err)
+	newBytes, err := json.Marshal(newResults)
+	assert.NoError(t, err)
+
+	changes, err := DiffResults(oldBytes, newBytes)
+	assert.NoError(t, err)
+
+	kinds := map[string]ChangeKind{}
+	for _, change := range changes {
+		kinds[change.Field] = change.Kind
+	}
+
+	assert.Equal(t, map[string]ChangeKind{
+		"error":                         Regression,
+		"tests.reconciliation":          Regression,
+		"stats.blocks":                  Changed,
+		"stats.failed_reconciliations":  Improvement,
+		"stats.reconciliation_coverage": Regression,
+		"rosetta_errors.1.count":        Regression,
+	}, kinds)
+
+	changes, err = DiffResults(oldBytes, oldBytes)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 0)
+
+	_, err = DiffResults([]byte("{"), oldBytes)
+	assert.Error(t, err)
+}
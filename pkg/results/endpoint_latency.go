@@ -0,0 +1,159 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// endpointOrder is the order in which construction
+// endpoints are reported (the order they are called
+// while constructing a transaction). Endpoints not
+// listed here are reported last (in lexical order).
+var endpointOrder = []string{
+	"/construction/derive",
+	"/construction/preprocess",
+	"/construction/metadata",
+	"/construction/payloads",
+	"/construction/parse (unsigned)",
+	"/construction/combine",
+	"/construction/parse (signed)",
+	"/construction/hash",
+	"/construction/submit",
+}
+
+// EndpointLatencyStats are the latencies of the
+// requests made to a single endpoint.
+type EndpointLatencyStats struct {
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+
+	// Latencies are in milliseconds (with microsecond
+	// precision because offline endpoints are often
+	// faster than a millisecond).
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+	Max float64 `json:"max_ms"`
+}
+
+var (
+	endpointLatencyLock sync.Mutex
+
+	endpointLatencies = map[string][]time.Duration{}
+)
+
+// RecordEndpointLatency records the latency of
+// a request made to endpoint.
+func RecordEndpointLatency(endpoint string, latency time.Duration) {
+	endpointLatencyLock.Lock()
+	defer endpointLatencyLock.Unlock()
+
+	endpointLatencies[endpoint] = append(endpointLatencies[endpoint], latency)
+}
+
+// durationMilliseconds returns d in milliseconds
+// (rounded to the nearest microsecond).
+func durationMilliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / float64(time.Millisecond/time.Microsecond)
+}
+
+// sortedEndpoints returns endpoints in report order.
+func sortedEndpoints(endpoints []string) []string {
+	rank := map[string]int{}
+	for i, endpoint := range endpointOrder {
+		rank[endpoint] = i
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		ri, iok := rank[endpoints[i]]
+		rj, jok := rank[endpoints[j]]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		default:
+			return endpoints[i] < endpoints[j]
+		}
+	})
+
+	return endpoints
+}
+
+// EndpointLatencies returns the *EndpointLatencyStats of
+// each endpoint requested in this invocation (nil if no
+// requests were recorded).
+func EndpointLatencies() []*EndpointLatencyStats {
+	endpointLatencyLock.Lock()
+	defer endpointLatencyLock.Unlock()
+
+	if len(endpointLatencies) == 0 {
+		return nil
+	}
+
+	endpoints := []string{}
+	for endpoint := range endpointLatencies {
+		endpoints = append(endpoints, endpoint)
+	}
+
+	stats := []*EndpointLatencyStats{}
+	for _, endpoint := range sortedEndpoints(endpoints) {
+		latencies := make([]time.Duration, len(endpointLatencies[endpoint]))
+		copy(latencies, endpointLatencies[endpoint])
+		sort.Slice(latencies, func(i, j int) bool {
+			return latencies[i] < latencies[j]
+		})
+
+		stats = append(stats, &EndpointLatencyStats{
+			Endpoint: endpoint,
+			Requests: int64(len(latencies)),
+			P50:      durationMilliseconds(percentileDuration(latencies, 0.5)),
+			P90:      durationMilliseconds(percentileDuration(latencies, 0.9)),
+			P99:      durationMilliseconds(percentileDuration(latencies, 0.99)),
+			Max:      durationMilliseconds(latencies[len(latencies)-1]),
+		})
+	}
+
+	return stats
+}
+
+// printEndpointLatencies logs the latency of
+// each endpoint to the console.
+func printEndpointLatencies(stats []*EndpointLatencyStats) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Endpoint", "Requests", "p50", "p90", "p99", "Max"})
+	for _, s := range stats {
+		table.Append([]string{
+			s.Endpoint,
+			strconv.FormatInt(s.Requests, 10),
+			fmt.Sprintf("%.3fms", s.P50),
+			fmt.Sprintf("%.3fms", s.P90),
+			fmt.Sprintf("%.3fms", s.P99),
+			fmt.Sprintf("%.3fms", s.Max),
+		})
+	}
+
+	table.Render()
+}
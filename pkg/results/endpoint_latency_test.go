@@ -0,0 +1,71 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointLatencies(t *testing.T) {
+	defer func() { endpointLatencies = map[string][]time.Duration{} }()
+
+	assert.Nil(t, EndpointLatencies())
+
+	for i := 1; i <= 100; i++ {
+		RecordEndpointLatency("/construction/submit", time.Duration(i)*time.Millisecond)
+	}
+	RecordEndpointLatency("/construction/parse (signed)", 250*time.Microsecond)
+	RecordEndpointLatency("/construction/preprocess", 2*time.Millisecond)
+	RecordEndpointLatency("/custom", time.Millisecond)
+
+	stats := EndpointLatencies()
+	assert.Equal(t, []*EndpointLatencyStats{
+		{
+			Endpoint: "/construction/preprocess",
+			Requests: 1,
+			P50:      2,
+			P90:      2,
+			P99:      2,
+			Max:      2,
+		},
+		{
+			Endpoint: "/construction/parse (signed)",
+			Requests: 1,
+			P50:      0.25,
+			P90:      0.25,
+			P99:      0.25,
+			Max:      0.25,
+		},
+		{
+			Endpoint: "/construction/submit",
+			Requests: 100,
+			P50:      50,
+			P90:      90,
+			P99:      99,
+			Max:      100,
+		},
+		{
+			Endpoint: "/custom",
+			Requests: 1,
+			P50:      1,
+			P90:      1,
+			P99:      1,
+			Max:      1,
+		},
+	}, stats)
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+)
+
+// Exit codes of the rosetta-cli. CI pipelines can use them
+// to distinguish a check that met its end conditions from a
+// check that failed or was interrupted.
+const (
+	// SuccessExitCode is returned when a check completes
+	// (or reaches its end conditions) without error.
+	SuccessExitCode = 0
+
+	// FailureExitCode is returned when a check fails (or
+	// the command could not be run).
+	FailureExitCode = 1
+
+	// HaltedExitCode is returned when a check is stopped by
+	// a signal before reaching its end conditions.
+	HaltedExitCode = 2
+)
+
+// ExitCode returns the exit code of a command that
+// returned err.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return SuccessExitCode
+	case errors.Is(err, ErrCheckHalted):
+		return HaltedExitCode
+	default:
+		return FailureExitCode
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgError "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, SuccessExitCode, ExitCode(nil))
+	assert.Equal(t, FailureExitCode, ExitCode(errors.New("reconciliation failure")))
+	assert.Equal(t, HaltedExitCode, ExitCode(ErrCheckHalted))
+	assert.Equal(
+		t,
+		HaltedExitCode,
+		ExitCode(fmt.Errorf("%w: phase data failed", pkgError.WithStack(ErrCheckHalted))),
+	)
+}
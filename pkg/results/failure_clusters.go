@@ -0,0 +1,182 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	// ReconciliationFailureCategory is the category of
+	// reconciliation failures.
+	ReconciliationFailureCategory = "Reconciliation"
+
+	// AmbiguousConditionCategory is the category of
+	// ambiguous conditions.
+	AmbiguousConditionCategory = "Ambiguous Condition"
+
+	// ConstructionFailureCategory is the category of
+	// failed construction steps.
+	ConstructionFailureCategory = "Construction"
+)
+
+// FailureSignature identifies a class of failures. Failures
+// with the same signature are reported as a single cluster.
+type FailureSignature struct {
+	Category       string `json:"category"`
+	Code           string `json:"code"`
+	OperationType  string `json:"operation_type,omitempty"`
+	AccountPattern string `json:"account_pattern,omitempty"`
+}
+
+// FailureCluster is the number of failures observed with a
+// signature and a representative example of one of them.
+type FailureCluster struct {
+	Signature *FailureSignature `json:"signature"`
+
+	Count   int64  `json:"count"`
+	Example string `json:"example"`
+}
+
+var (
+	failureClustersLock sync.Mutex
+
+	// failureClusters are the *FailureCluster of this
+	// invocation (keyed by signature).
+	failureClusters = map[FailureSignature]*FailureCluster{}
+)
+
+// AccountPattern returns the shape of account (ignoring the
+// address) so that failures affecting many accounts of the
+// same kind are clustered together. The address is replaced
+// by *, followed by the sub-account address (if any) and the
+// sorted keys of any metadata.
+func AccountPattern(account *types.AccountIdentifier) string {
+	if account == nil {
+		return ""
+	}
+
+	pattern := "*"
+	metadata := account.Metadata
+	if account.SubAccount != nil {
+		pattern += ":" + account.SubAccount.Address
+		metadata = account.SubAccount.Metadata
+	}
+
+	if len(metadata) > 0 {
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pattern += "{" + strings.Join(keys, ",") + "}"
+	}
+
+	return pattern
+}
+
+// RecordFailure records a failure with signature and returns
+// the number of failures recorded with signature (including
+// this one). The first example recorded for a signature is
+// retained.
+func RecordFailure(signature *FailureSignature, example string) int64 {
+	failureClustersLock.Lock()
+	defer failureClustersLock.Unlock()
+
+	cluster, ok := failureClusters[*signature]
+	if !ok {
+		copied := *signature
+		cluster = &FailureCluster{
+			Signature: &copied,
+			Example:   example,
+		}
+		failureClusters[*signature] = cluster
+	}
+
+	cluster.Count++
+	return cluster.Count
+}
+
+// FailureCount returns the number of failures
+// recorded with signature.
+func FailureCount(signature *FailureSignature) int64 {
+	failureClustersLock.Lock()
+	defer failureClustersLock.Unlock()
+
+	cluster, ok := failureClusters[*signature]
+	if !ok {
+		return 0
+	}
+
+	return cluster.Count
+}
+
+// FailureClusters returns the *FailureCluster of all recorded
+// failures (sorted by count, largest first). If no failures
+// were recorded, nil is returned.
+func FailureClusters() []*FailureCluster {
+	failureClustersLock.Lock()
+	defer failureClustersLock.Unlock()
+
+	var clusters []*FailureCluster
+	for _, cluster := range failureClusters {
+		copied := *cluster
+		clusters = append(clusters, &copied)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Count != clusters[j].Count {
+			return clusters[i].Count > clusters[j].Count
+		}
+
+		return types.Hash(clusters[i].Signature) < types.Hash(clusters[j].Signature)
+	})
+
+	return clusters
+}
+
+// printFailureClusters logs *FailureCluster to the console.
+func printFailureClusters(clusters []*FailureCluster) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{
+		"Failure Cluster",
+		"Code",
+		"Operation Type",
+		"Account Pattern",
+		"Count",
+		"Example",
+	})
+	for _, cluster := range clusters {
+		table.Append([]string{
+			cluster.Signature.Category,
+			cluster.Signature.Code,
+			cluster.Signature.OperationType,
+			cluster.Signature.AccountPattern,
+			strconv.FormatInt(cluster.Count, 10),
+			cluster.Example,
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountPattern(t *testing.T) {
+	tests := map[string]struct {
+		account *types.AccountIdentifier
+		pattern string
+	}{
+		"nil": {},
+		"address": {
+			account: &types.AccountIdentifier{Address: "addr1"},
+			pattern: "*",
+		},
+		"metadata": {
+			account: &types.AccountIdentifier{
+				Address:  "addr1",
+				Metadata: map[string]interface{}{"type": "contract", "id": 1},
+			},
+			pattern: "*{id,type}",
+		},
+		"sub-account": {
+			account: &types.AccountIdentifier{
+				Address: "addr1",
+				SubAccount: &types.SubAccountIdentifier{
+					Address:  "staking",
+					Metadata: map[string]interface{}{"validator": "val1"},
+				},
+			},
+			pattern: "*:staking{validator}",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.pattern, AccountPattern(test.account))
+		})
+	}
+}
+
+func TestFailureClusters(t *testing.T) {
+	defer func() {
+		failureClusters = map[FailureSignature]*FailureCluster{}
+	}()
+
+	assert.Nil(t, FailureClusters())
+
+	reconciliation := &FailureSignature{
+		Category:       ReconciliationFailureCategory,
+		Code:           "ACTIVE",
+		AccountPattern: "*",
+	}
+	ambiguous := &FailureSignature{
+		Category:       AmbiguousConditionCategory,
+		Code:           "zero-value amount",
+		OperationType:  "Fee",
+		AccountPattern: "*",
+	}
+
+	RecordFailure(ambiguous, "zero-value amount in operation 0 of transaction tx1 in block 1")
+	RecordFailure(reconciliation, "addr1 at 1 computed: 10BTC live: 11BTC")
+	RecordFailure(reconciliation, "addr2 at 2 computed: 20BTC live: 21BTC")
+
+	assert.Equal(t, []*FailureCluster{
+		{
+			Signature: reconciliation,
+			Count:     2,
+			Example:   "addr1 at 1 computed: 10BTC live: 11BTC",
+		},
+		{
+			Signature: ambiguous,
+			Count:     1,
+			Example:   "zero-value amount in operation 0 of transaction tx1 in block 1",
+		},
+	}, FailureClusters())
+}
@@ -0,0 +1,256 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/olekukonko/tablewriter"
+)
+
+// LoadTestStats are the stats of a
+// check:construction load test.
+type LoadTestStats struct {
+	TargetTPS   float64 `json:"target_tps"`
+	AchievedTPS float64 `json:"achieved_tps"`
+	Duration    int64   `json:"duration_seconds"`
+
+	TransactionsCreated   int64 `json:"transactions_created"`
+	TransactionsConfirmed int64 `json:"transactions_confirmed"`
+
+	// ConfirmationLatencies are from the creation of a
+	// transaction to its confirmation (in milliseconds), not
+	// from its broadcast.
+	ConfirmationLatencyP50 int64 `json:"confirmation_latency_p50_ms"`
+	ConfirmationLatencyP90 int64 `json:"confirmation_latency_p90_ms"`
+	ConfirmationLatencyP99 int64 `json:"confirmation_latency_p99_ms"`
+
+	// SubmitAttempts is the number of calls to /construction/submit
+	// and AcceptanceRate is the fraction of them that succeeded.
+	SubmitAttempts int64   `json:"submit_attempts"`
+	AcceptanceRate float64 `json:"acceptance_rate"`
+
+	// SubmitLatencies are the durations of the calls to
+	// /construction/submit (in milliseconds).
+	SubmitLatencyP50 int64 `json:"submit_latency_p50_ms"`
+	SubmitLatencyP90 int64 `json:"submit_latency_p90_ms"`
+	SubmitLatencyP99 int64 `json:"submit_latency_p99_ms"`
+}
+
+// loadTest tracks the progress of a load test.
+type loadTest struct {
+	targetTPS float64
+	start     time.Time
+	end       time.Time
+
+	created         map[string]time.Time
+	latencies       []time.Duration
+	submitLatencies []time.Duration
+
+	transactionsCreated int64
+	submitAttempts      int64
+	submitsAccepted     int64
+}
+
+var (
+	loadTestLock sync.Mutex
+
+	// currentLoadTest is the load test of this invocation
+	// (nil if no load test is running).
+	currentLoadTest *loadTest
+)
+
+// StartLoadTest starts tracking a load test. All Record*
+// functions are no-ops until it is called.
+func StartLoadTest(targetTPS float64) {
+	loadTestLock.Lock()
+	defer loadTestLock.Unlock()
+
+	currentLoadTest = &loadTest{
+		targetTPS: targetTPS,
+		start:     time.Now(),
+		created:   map[string]time.Time{},
+	}
+}
+
+// StopLoadTest stops tracking the current load test
+// (ex: before returning funds) so that later activity
+// is not included in its stats.
+func StopLoadTest() {
+	loadTestLock.Lock()
+	defer loadTestLock.Unlock()
+
+	if currentLoadTest == nil || !currentLoadTest.end.IsZero() {
+		return
+	}
+
+	currentLoadTest.end = time.Now()
+}
+
+// active returns a boolean indicating if a load test
+// is being tracked. The caller must hold loadTestLock.
+func active() bool {
+	return currentLoadTest != nil && currentLoadTest.end.IsZero()
+}
+
+// RecordTransactionCreated records the creation of the
+// transaction broadcast with identifier.
+func RecordTransactionCreated(identifier string) {
+	loadTestLock.Lock()
+	defer loadTestLock.Unlock()
+
+	if !active() {
+		return
+	}
+
+	currentLoadTest.created[identifier] = time.Now()
+	currentLoadTest.transactionsCreated++
+}
+
+// RecordTransactionSubmitted records a call to
+// /construction/submit (whether it succeeded and
+// how long it took).
+func RecordTransactionSubmitted(accepted bool, latency time.Duration) {
+	loadTestLock.Lock()
+	defer loadTestLock.Unlock()
+
+	if !active() {
+		return
+	}
+
+	currentLoadTest.submitAttempts++
+	currentLoadTest.submitLatencies = append(currentLoadTest.submitLatencies, latency)
+	if accepted {
+		currentLoadTest.submitsAccepted++
+	}
+}
+
+// RecordTransactionConfirmed records the confirmation of
+// the transaction broadcast with identifier.
+func RecordTransactionConfirmed(identifier string) {
+	loadTestLock.Lock()
+	defer loadTestLock.Unlock()
+
+	if !active() {
+		return
+	}
+
+	created, ok := currentLoadTest.created[identifier]
+	if !ok {
+		return
+	}
+
+	delete(currentLoadTest.created, identifier)
+	currentLoadTest.latencies = append(currentLoadTest.latencies, time.Since(created))
+}
+
+// percentile returns the p-th percentile (in [0, 1])
+// of sorted latencies in milliseconds.
+func percentile(sorted []time.Duration, p float64) int64 {
+	return percentileDuration(sorted, p).Milliseconds()
+}
+
+// percentileDuration returns the p-th percentile
+// (in [0, 1]) of sorted latencies.
+func percentileDuration(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(p * float64(len(sorted)-1))
+	return sorted[i]
+}
+
+// sortedLatencies returns a sorted copy of latencies.
+func sortedLatencies(latencies []time.Duration) []time.Duration {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	return sorted
+}
+
+// LoadTestResults returns the *LoadTestStats of the current
+// load test (nil if no load test is running).
+func LoadTestResults() *LoadTestStats {
+	loadTestLock.Lock()
+	defer loadTestLock.Unlock()
+
+	if currentLoadTest == nil {
+		return nil
+	}
+
+	end := currentLoadTest.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	elapsed := end.Sub(currentLoadTest.start)
+	latencies := sortedLatencies(currentLoadTest.latencies)
+	submitLatencies := sortedLatencies(currentLoadTest.submitLatencies)
+
+	stats := &LoadTestStats{
+		TargetTPS:              currentLoadTest.targetTPS,
+		AchievedTPS:            float64(len(latencies)) / elapsed.Seconds(),
+		Duration:               int64(elapsed.Seconds()),
+		TransactionsCreated:    currentLoadTest.transactionsCreated,
+		TransactionsConfirmed:  int64(len(latencies)),
+		ConfirmationLatencyP50: percentile(latencies, 0.5),
+		ConfirmationLatencyP90: percentile(latencies, 0.9),
+		ConfirmationLatencyP99: percentile(latencies, 0.99),
+		SubmitAttempts:         currentLoadTest.submitAttempts,
+		SubmitLatencyP50:       percentile(submitLatencies, 0.5),
+		SubmitLatencyP90:       percentile(submitLatencies, 0.9),
+		SubmitLatencyP99:       percentile(submitLatencies, 0.99),
+	}
+	if stats.SubmitAttempts > 0 {
+		stats.AcceptanceRate = float64(currentLoadTest.submitsAccepted) /
+			float64(stats.SubmitAttempts)
+	}
+
+	return stats
+}
+
+// Print logs LoadTestStats to the console.
+func (s *LoadTestStats) Print() {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Load Test", "Value"})
+	table.AppendBulk([][]string{
+		{"Target TPS", fmt.Sprintf("%.2f", s.TargetTPS)},
+		{"Achieved TPS", fmt.Sprintf("%.2f", s.AchievedTPS)},
+		{"Duration", fmt.Sprintf("%ds", s.Duration)},
+		{"Transactions Created", strconv.FormatInt(s.TransactionsCreated, 10)},
+		{"Transactions Confirmed", strconv.FormatInt(s.TransactionsConfirmed, 10)},
+		{"Creation To Confirmation p50", fmt.Sprintf("%dms", s.ConfirmationLatencyP50)},
+		{"Creation To Confirmation p90", fmt.Sprintf("%dms", s.ConfirmationLatencyP90)},
+		{"Creation To Confirmation p99", fmt.Sprintf("%dms", s.ConfirmationLatencyP99)},
+		{"Submit Attempts", strconv.FormatInt(s.SubmitAttempts, 10)},
+		{"Acceptance Rate", fmt.Sprintf("%.2f%%", s.AcceptanceRate*utils.OneHundred)},
+		{"Submit Latency p50", fmt.Sprintf("%dms", s.SubmitLatencyP50)},
+		{"Submit Latency p90", fmt.Sprintf("%dms", s.SubmitLatencyP90)},
+		{"Submit Latency p99", fmt.Sprintf("%dms", s.SubmitLatencyP99)},
+	})
+
+	table.Render()
+}
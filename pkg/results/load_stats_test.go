@@ -0,0 +1,53 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTestResults(t *testing.T) {
+	defer func() { currentLoadTest = nil }()
+
+	// Nothing is recorded without a load test
+	RecordTransactionCreated("tx1")
+	assert.Nil(t, LoadTestResults())
+
+	StartLoadTest(5)
+	RecordTransactionCreated("tx1")
+	RecordTransactionCreated("tx2")
+	RecordTransactionSubmitted(true, 10*time.Millisecond)
+	RecordTransactionSubmitted(true, 30*time.Millisecond)
+	RecordTransactionSubmitted(false, 20*time.Millisecond)
+	RecordTransactionConfirmed("tx1")
+	RecordTransactionConfirmed("unknown")
+
+	StopLoadTest()
+	RecordTransactionConfirmed("tx2")
+
+	stats := LoadTestResults()
+	assert.Equal(t, float64(5), stats.TargetTPS)
+	assert.Equal(t, int64(2), stats.TransactionsCreated)
+	assert.Equal(t, int64(1), stats.TransactionsConfirmed)
+	assert.Equal(t, int64(3), stats.SubmitAttempts)
+	assert.InDelta(t, 2.0/3, stats.AcceptanceRate, 0.0001)
+	assert.Equal(t, int64(20), stats.SubmitLatencyP50)
+	assert.Equal(t, int64(20), stats.SubmitLatencyP90)
+	assert.Equal(t, int64(20), stats.SubmitLatencyP99)
+	assert.Greater(t, stats.AchievedTPS, float64(0))
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// MempoolStats are the stats of mempool verification
+// during check:construction.
+type MempoolStats struct {
+	// TransactionsVerified is the number of broadcast transactions
+	// that appeared in the mempool with operations matching their
+	// intent.
+	TransactionsVerified int64 `json:"transactions_verified"`
+
+	// TransactionsMissed is the number of broadcast transactions
+	// that were included in a block before they were seen in
+	// the mempool.
+	TransactionsMissed int64 `json:"transactions_missed"`
+
+	// Latencies are from the submission of a transaction
+	// to its appearance in the mempool (in milliseconds).
+	LatencyP50 int64 `json:"latency_p50_ms"`
+	LatencyP90 int64 `json:"latency_p90_ms"`
+	LatencyP99 int64 `json:"latency_p99_ms"`
+}
+
+var (
+	mempoolLock sync.Mutex
+
+	mempoolLatencies []time.Duration
+	mempoolMissed    int64
+)
+
+// RecordMempoolLatency records the time it took for a
+// submitted transaction to appear in the mempool.
+func RecordMempoolLatency(latency time.Duration) {
+	mempoolLock.Lock()
+	defer mempoolLock.Unlock()
+
+	mempoolLatencies = append(mempoolLatencies, latency)
+}
+
+// RecordMempoolMissed records a submitted transaction that
+// was included in a block before it was seen in the mempool.
+func RecordMempoolMissed() {
+	mempoolLock.Lock()
+	defer mempoolLock.Unlock()
+
+	mempoolMissed++
+}
+
+// MempoolResults returns the *MempoolStats of this invocation
+// (nil if no transactions were verified or missed).
+func MempoolResults() *MempoolStats {
+	mempoolLock.Lock()
+	defer mempoolLock.Unlock()
+
+	if len(mempoolLatencies) == 0 && mempoolMissed == 0 {
+		return nil
+	}
+
+	latencies := make([]time.Duration, len(mempoolLatencies))
+	copy(latencies, mempoolLatencies)
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	return &MempoolStats{
+		TransactionsVerified: int64(len(latencies)),
+		TransactionsMissed:   mempoolMissed,
+		LatencyP50:           percentile(latencies, 0.5),
+		LatencyP90:           percentile(latencies, 0.9),
+		LatencyP99:           percentile(latencies, 0.99),
+	}
+}
+
+// Print logs MempoolStats to the console.
+func (s *MempoolStats) Print() {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Mempool", "Value"})
+	table.AppendBulk([][]string{
+		{"Transactions Verified", strconv.FormatInt(s.TransactionsVerified, 10)},
+		{"Transactions Missed", strconv.FormatInt(s.TransactionsMissed, 10)},
+		{"Propagation Latency p50", fmt.Sprintf("%dms", s.LatencyP50)},
+		{"Propagation Latency p90", fmt.Sprintf("%dms", s.LatencyP90)},
+		{"Propagation Latency p99", fmt.Sprintf("%dms", s.LatencyP99)},
+	})
+
+	table.Render()
+}
@@ -0,0 +1,325 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+)
+
+const (
+	// metricsPrefix is prepended to the
+	// name of all exported metrics.
+	metricsPrefix = "rosetta_cli_"
+
+	// metricsContentType is the content type of
+	// the Prometheus text exposition format.
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// requestLatencyBuckets are the upper bounds (in seconds)
+	// of the buckets request latencies are counted in.
+	requestLatencyBuckets = []float64{
+		0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+	}
+
+	// labeledCounters are the counters exported as a
+	// single metric (with the value of label for each
+	// counter).
+	labeledCounters = []struct {
+		name  string
+		help  string
+		label string
+		keys  map[string]string
+	}{
+		{
+			name:  "reconciliations_total",
+			help:  "Reconciliations performed (by type).",
+			label: "type",
+			keys: map[string]string{
+				"active":   modules.ActiveReconciliationCounter,
+				"inactive": modules.InactiveReconciliationCounter,
+				"exempt":   modules.ExemptReconciliationCounter,
+				"skipped":  modules.SkippedReconciliationsCounter,
+			},
+		},
+		{
+			name:  "broadcasts_total",
+			help:  "Transaction broadcasts (by status).",
+			label: "status",
+			keys: map[string]string{
+				"created":   modules.TransactionsCreatedCounter,
+				"confirmed": modules.TransactionsConfirmedCounter,
+				"stale":     modules.StaleBroadcastsCounter,
+				"failed":    modules.FailedBroadcastsCounter,
+			},
+		},
+	}
+
+	requestLatenciesLock sync.Mutex
+
+	// requestLatencies are the latencies of requests
+	// made to each Rosetta endpoint (keyed by path).
+	requestLatencies = map[string]*latencyHistogram{}
+)
+
+// latencyHistogram counts request latencies in
+// requestLatencyBuckets (with an implicit +Inf bucket).
+type latencyHistogram struct {
+	buckets []int64
+	sum     float64
+	count   int64
+}
+
+// RecordRequestLatency records the latency of a
+// request made to a Rosetta endpoint.
+func RecordRequestLatency(endpoint string, latency time.Duration) {
+	requestLatenciesLock.Lock()
+	defer requestLatenciesLock.Unlock()
+
+	histogram, ok := requestLatencies[endpoint]
+	if !ok {
+		histogram = &latencyHistogram{
+			buckets: make([]int64, len(requestLatencyBuckets)),
+		}
+		requestLatencies[endpoint] = histogram
+	}
+
+	seconds := latency.Seconds()
+	for i, bound := range requestLatencyBuckets {
+		if seconds <= bound {
+			histogram.buckets[i]++
+			break
+		}
+	}
+	histogram.sum += seconds
+	histogram.count++
+}
+
+// storageSize returns the total size of all files in
+// dataPath. Files removed while walking (ex: by compaction)
+// are ignored.
+func storageSize(dataPath string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dataPath, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
+
+// metricsWriter writes metrics in the Prometheus
+// text exposition format.
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (m *metricsWriter) header(name string, help string, metricType string) {
+	fmt.Fprintf(m.w, "# HELP %s%s %s\n", metricsPrefix, name, help)
+	fmt.Fprintf(m.w, "# TYPE %s%s %s\n", metricsPrefix, name, metricType)
+}
+
+func (m *metricsWriter) sample(name string, labels string, value string) {
+	if len(labels) > 0 {
+		labels = "{" + labels + "}"
+	}
+
+	fmt.Fprintf(m.w, "%s%s%s %s\n", metricsPrefix, name, labels, value)
+}
+
+func (m *metricsWriter) metric(name string, help string, metricType string, value string) {
+	m.header(name, help, metricType)
+	m.sample(name, "", value)
+}
+
+// WriteMetrics writes the counters in counterStorage, the
+// latencies of requests made to each Rosetta endpoint, and
+// the size of the storage in dataPath to w in the Prometheus
+// text exposition format.
+func WriteMetrics(
+	ctx context.Context,
+	w io.Writer,
+	counterStorage *modules.CounterStorage,
+	dataPath string,
+) error {
+	get := func(key string) (*big.Int, error) {
+		value, err := counterStorage.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to get %s counter", err, key)
+		}
+
+		return value, nil
+	}
+
+	blocks, err := get(modules.BlockCounter)
+	if err != nil {
+		return err
+	}
+
+	orphans, err := get(modules.OrphanCounter)
+	if err != nil {
+		return err
+	}
+
+	elapsed, err := get(TimeElapsedCounter)
+	if err != nil {
+		return err
+	}
+
+	failedReconciliations, err := get(modules.FailedReconciliationCounter)
+	if err != nil {
+		return err
+	}
+
+	size, err := storageSize(dataPath)
+	if err != nil {
+		return fmt.Errorf("%w: unable to determine storage size", err)
+	}
+
+	m := &metricsWriter{w: w}
+	m.metric("blocks_synced_total", "Blocks synced (including orphaned blocks).", "counter", blocks.String())
+	m.metric("blocks_orphaned_total", "Blocks orphaned by reorgs.", "counter", orphans.String())
+
+	var rate float64
+	if elapsed.Sign() > 0 {
+		rate = float64(blocks.Int64()-orphans.Int64()) / float64(elapsed.Int64())
+	}
+	m.metric(
+		"blocks_synced_per_second",
+		"Average number of (non-orphaned) blocks synced per second.",
+		"gauge",
+		strconv.FormatFloat(rate, 'g', -1, 64),
+	)
+
+	for _, counter := range labeledCounters {
+		labelValues := make([]string, 0, len(counter.keys))
+		for labelValue := range counter.keys {
+			labelValues = append(labelValues, labelValue)
+		}
+		sort.Strings(labelValues)
+
+		m.header(counter.name, counter.help, "counter")
+		for _, labelValue := range labelValues {
+			value, err := get(counter.keys[labelValue])
+			if err != nil {
+				return err
+			}
+
+			m.sample(counter.name, fmt.Sprintf("%s=%q", counter.label, labelValue), value.String())
+		}
+	}
+
+	m.metric(
+		"reconciliation_failures_total",
+		"Reconciliations that failed.",
+		"counter",
+		failedReconciliations.String(),
+	)
+	m.metric(
+		"storage_size_bytes",
+		"Size of the storage in the data directory.",
+		"gauge",
+		strconv.FormatInt(size, 10),
+	)
+
+	writeRequestLatencies(m)
+	return nil
+}
+
+// writeRequestLatencies writes the latencies of requests
+// made to each Rosetta endpoint as a histogram.
+func writeRequestLatencies(m *metricsWriter) {
+	requestLatenciesLock.Lock()
+	defer requestLatenciesLock.Unlock()
+
+	endpoints := make([]string, 0, len(requestLatencies))
+	for endpoint := range requestLatencies {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	name := "request_duration_seconds"
+	m.header(name, "Latency of requests made to each Rosetta endpoint.", "histogram")
+	for _, endpoint := range endpoints {
+		histogram := requestLatencies[endpoint]
+		var cumulative int64
+		for i, bound := range requestLatencyBuckets {
+			cumulative += histogram.buckets[i]
+			m.sample(
+				name+"_bucket",
+				fmt.Sprintf("endpoint=%q,le=%q", endpoint, strconv.FormatFloat(bound, 'g', -1, 64)),
+				strconv.FormatInt(cumulative, 10),
+			)
+		}
+
+		m.sample(
+			name+"_bucket",
+			fmt.Sprintf("endpoint=%q,le=\"+Inf\"", endpoint),
+			strconv.FormatInt(histogram.count, 10),
+		)
+		m.sample(
+			name+"_sum",
+			fmt.Sprintf("endpoint=%q", endpoint),
+			strconv.FormatFloat(histogram.sum, 'g', -1, 64),
+		)
+		m.sample(
+			name+"_count",
+			fmt.Sprintf("endpoint=%q", endpoint),
+			strconv.FormatInt(histogram.count, 10),
+		)
+	}
+}
+
+// MetricsHandler returns an http.Handler that serves the
+// metrics written by WriteMetrics.
+func MetricsHandler(counterStorage *modules.CounterStorage, dataPath string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Metrics are buffered so that an error
+		// doesn't produce a partial response.
+		var buf bytes.Buffer
+		if err := WriteMetrics(r.Context(), &buf, counterStorage, dataPath); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", metricsContentType)
+		_, _ = buf.WriteTo(w)
+	})
+}
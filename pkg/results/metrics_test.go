@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/big"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	defer func() {
+		requestLatencies = map[string]*latencyHistogram{}
+	}()
+
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, path.Join(dir, "db"))
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	counters := modules.NewCounterStorage(db)
+	for key, value := range map[string]int64{
+		modules.BlockCounter:                 110,
+		modules.OrphanCounter:                10,
+		TimeElapsedCounter:                   50,
+		modules.ActiveReconciliationCounter:  7,
+		modules.FailedReconciliationCounter:  1,
+		modules.TransactionsCreatedCounter:   3,
+		modules.TransactionsConfirmedCounter: 2,
+	} {
+		_, err := counters.Update(ctx, key, big.NewInt(value))
+		assert.NoError(t, err)
+	}
+
+	RecordRequestLatency("/block", 20*time.Millisecond)
+	RecordRequestLatency("/block", 300*time.Millisecond)
+	RecordRequestLatency("/account/balance", 20*time.Second)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteMetrics(ctx, &buf, counters, dir))
+	metrics := buf.String()
+
+	for _, line := range []string{
+		"# TYPE rosetta_cli_blocks_synced_total counter",
+		"rosetta_cli_blocks_synced_total 110",
+		"rosetta_cli_blocks_orphaned_total 10",
+		"rosetta_cli_blocks_synced_per_second 2",
+		"rosetta_cli_reconciliations_total{type=\"active\"} 7",
+		"rosetta_cli_reconciliations_total{type=\"inactive\"} 0",
+		"rosetta_cli_reconciliation_failures_total 1",
+		"rosetta_cli_broadcasts_total{status=\"created\"} 3",
+		"rosetta_cli_broadcasts_total{status=\"confirmed\"} 2",
+		"# TYPE rosetta_cli_request_duration_seconds histogram",
+		"rosetta_cli_request_duration_seconds_bucket{endpoint=\"/block\",le=\"0.01\"} 0",
+		"rosetta_cli_request_duration_seconds_bucket{endpoint=\"/block\",le=\"0.025\"} 1",
+		"rosetta_cli_request_duration_seconds_bucket{endpoint=\"/block\",le=\"0.5\"} 2",
+		"rosetta_cli_request_duration_seconds_count{endpoint=\"/block\"} 2",
+		"rosetta_cli_request_duration_seconds_bucket{endpoint=\"/account/balance\",le=\"10\"} 0",
+		"rosetta_cli_request_duration_seconds_bucket{endpoint=\"/account/balance\",le=\"+Inf\"} 1",
+	} {
+		assert.Contains(t, metrics, line+"\n")
+	}
+
+	size, err := storageSize(dir)
+	assert.NoError(t, err)
+	assert.True(t, size > 0)
+
+	assert.NoError(t, ioutil.WriteFile(path.Join(dir, "extra"), make([]byte, 100), 0600))
+	newSize, err := storageSize(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, size+100, newSize)
+}
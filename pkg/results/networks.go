@@ -0,0 +1,170 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/olekukonko/tablewriter"
+)
+
+// NetworkResults are the results of check:data on one
+// of the networks tested by check:data --all-networks.
+type NetworkResults struct {
+	Name    string                   `json:"name"`
+	Network *types.NetworkIdentifier `json:"network"`
+
+	// Elapsed is the length of the check in seconds.
+	Elapsed int64 `json:"elapsed"`
+
+	// Error is populated if check:data could not be run
+	// on the network or did not save its results.
+	Error string `json:"error,omitempty"`
+
+	Data *CheckDataResults `json:"data,omitempty"`
+}
+
+// failure returns the reason check:data failed on
+// the network (empty if it passed).
+func (n *NetworkResults) failure() string {
+	switch {
+	case len(n.Error) > 0:
+		return n.Error
+	case n.Data == nil:
+		return "no results"
+	case n.Data.Status != PassStatus:
+		if len(n.Data.Error) > 0 {
+			return n.Data.Error
+		}
+
+		return "failed tests"
+	default:
+		return ""
+	}
+}
+
+// NetworksResults are the results of all networks
+// tested by check:data --all-networks.
+type NetworksResults struct {
+	Status   CheckStatus       `json:"status"`
+	Timing   *Timing           `json:"timing,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Networks []*NetworkResults `json:"networks"`
+}
+
+// ComputeNetworksResults returns the *NetworksResults of
+// networks, tested between start and end.
+func ComputeNetworksResults(
+	networks []*NetworkResults,
+	start time.Time,
+	end time.Time,
+) *NetworksResults {
+	results := &NetworksResults{
+		Status: PassStatus,
+		Timing: &Timing{
+			StartTime: start.Unix(),
+			EndTime:   end.Unix(),
+			Elapsed:   int64(end.Sub(start).Seconds()),
+		},
+		Networks: networks,
+	}
+
+	if err := networksErr(networks); err != nil {
+		results.Status = FailStatus
+		results.Error = err.Error()
+	}
+
+	return results
+}
+
+// networksErr returns an error listing the networks
+// check:data failed on (nil if it passed on all of them).
+func networksErr(networks []*NetworkResults) error {
+	failed := []string{}
+	for _, network := range networks {
+		if len(network.failure()) > 0 {
+			failed = append(failed, network.Name)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrNetworksFailed, strings.Join(failed, ", "))
+}
+
+// Print logs a summary of NetworksResults to the console
+// (the results of each network are logged when it ends).
+func (n *NetworksResults) Print() {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Network", "Blocks", "Elapsed", "Status"})
+	for _, network := range n.Networks {
+		blocks := "-"
+		if network.Data != nil && network.Data.Stats != nil {
+			blocks = fmt.Sprintf("%d", network.Data.Stats.Blocks)
+		}
+
+		// Errors may include a stack trace, which is
+		// not useful in a summary.
+		status := "Succeeded"
+		if failure := network.failure(); len(failure) > 0 {
+			status = "Failed: " + strings.SplitN(failure, "\n", 2)[0]
+		}
+
+		table.Append([]string{
+			network.Name,
+			blocks,
+			(time.Duration(network.Elapsed) * time.Second).String(),
+			status,
+		})
+	}
+
+	table.Render()
+}
+
+// Output writes NetworksResults to the provided
+// path (if it is not empty).
+func (n *NetworksResults) Output(path string) {
+	if len(path) > 0 {
+		writeErr := utils.SerializeAndWrite(path, n)
+		if writeErr != nil {
+			log.Printf("%s: unable to save results\n", writeErr.Error())
+		}
+	}
+}
+
+// ExitNetworks prints and saves the *NetworksResults
+// of networks and returns an error if check:data failed
+// on any of them.
+func ExitNetworks(
+	networks []*NetworkResults,
+	start time.Time,
+	path string,
+) error {
+	results := ComputeNetworksResults(networks, start, time.Now())
+	results.Print()
+	results.Output(path)
+
+	return networksErr(networks)
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeNetworksResults(t *testing.T) {
+	start := time.Unix(1000, 0)
+	end := time.Unix(1060, 0)
+
+	passed := &NetworkResults{
+		Name: "mainnet",
+		Data: &CheckDataResults{Status: PassStatus},
+	}
+	results := ComputeNetworksResults([]*NetworkResults{passed}, start, end)
+	assert.Equal(t, &NetworksResults{
+		Status: PassStatus,
+		Timing: &Timing{
+			StartTime: 1000,
+			EndTime:   1060,
+			Elapsed:   60,
+		},
+		Networks: []*NetworkResults{passed},
+	}, results)
+	assert.NoError(t, networksErr(results.Networks))
+
+	networks := []*NetworkResults{
+		passed,
+		{
+			Name: "testnet",
+			Data: &CheckDataResults{Status: FailStatus, Error: "reconciliation failure"},
+		},
+		{
+			Name:  "regtest",
+			Error: "exit status 1",
+		},
+		{
+			Name: "signet",
+		},
+	}
+	results = ComputeNetworksResults(networks, start, end)
+	assert.Equal(t, FailStatus, results.Status)
+	assert.Equal(
+		t,
+		"check:data failed on networks: testnet, regtest, signet",
+		results.Error,
+	)
+	assert.True(t, errors.Is(networksErr(networks), ErrNetworksFailed))
+	assert.Equal(t, "", networks[0].failure())
+	assert.Equal(t, "reconciliation failure", networks[1].failure())
+	assert.Equal(t, "exit status 1", networks[2].failure())
+	assert.Equal(t, "no results", networks[3].failure())
+}
@@ -0,0 +1,239 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/olekukonko/tablewriter"
+)
+
+// OperationImpact is the balance impact of a single operation.
+type OperationImpact struct {
+	Type string
+
+	// Account is the hash of the *types.AccountIdentifier
+	// of the operation (empty if it has no account).
+	Account string
+
+	// Currency and Amount are only populated if the operation
+	// was successful and has an amount.
+	Currency *types.Currency
+	Amount   *big.Int
+}
+
+// CurrencyImpact is the value of a single currency
+// moved by the operations of an operation type.
+type CurrencyImpact struct {
+	Currency *types.Currency `json:"currency"`
+
+	// Volume is the sum of the absolute value of all
+	// amounts and Net is the sum of all amounts.
+	Volume string `json:"volume"`
+	Net    string `json:"net"`
+}
+
+// OperationImpactStats are the number of operations of an
+// operation type, the distinct accounts they touched, and
+// the value they moved over the synced range.
+type OperationImpactStats struct {
+	Type       string            `json:"type"`
+	Operations int64             `json:"operations"`
+	Accounts   int64             `json:"accounts"`
+	Currencies []*CurrencyImpact `json:"currencies,omitempty"`
+}
+
+// currencyTotals are the totals of a
+// single currency of an operation type.
+type currencyTotals struct {
+	currency *types.Currency
+	volume   *big.Int
+	net      *big.Int
+}
+
+// operationTypeImpact is the impact of all operations
+// of a single operation type.
+type operationTypeImpact struct {
+	operations int64
+
+	// accounts is the number of operations
+	// touching each account (so that accounts
+	// are no longer counted once all of their
+	// operations are orphaned).
+	accounts map[string]int64
+
+	currencies map[string]*currencyTotals
+}
+
+var (
+	operationImpactLock sync.Mutex
+
+	operationImpacts = map[string]*operationTypeImpact{}
+)
+
+// recordOperationImpacts adds the impact of each
+// operation multiplied by sign (1 or -1).
+func recordOperationImpacts(impacts []*OperationImpact, sign int64) {
+	operationImpactLock.Lock()
+	defer operationImpactLock.Unlock()
+
+	for _, impact := range impacts {
+		typeImpact, ok := operationImpacts[impact.Type]
+		if !ok {
+			typeImpact = &operationTypeImpact{
+				accounts:   map[string]int64{},
+				currencies: map[string]*currencyTotals{},
+			}
+			operationImpacts[impact.Type] = typeImpact
+		}
+		typeImpact.operations += sign
+
+		if len(impact.Account) > 0 {
+			typeImpact.accounts[impact.Account] += sign
+			if typeImpact.accounts[impact.Account] <= 0 {
+				delete(typeImpact.accounts, impact.Account)
+			}
+		}
+
+		if impact.Amount == nil {
+			continue
+		}
+
+		key := types.Hash(impact.Currency)
+		totals, ok := typeImpact.currencies[key]
+		if !ok {
+			totals = &currencyTotals{
+				currency: impact.Currency,
+				volume:   new(big.Int),
+				net:      new(big.Int),
+			}
+			typeImpact.currencies[key] = totals
+		}
+
+		signed := new(big.Int).Mul(impact.Amount, big.NewInt(sign))
+		totals.net.Add(totals.net, signed)
+		volume := new(big.Int).Abs(impact.Amount)
+		totals.volume.Add(totals.volume, volume.Mul(volume, big.NewInt(sign)))
+	}
+}
+
+// RecordOperationImpacts records the impact of the
+// operations in a synced block.
+func RecordOperationImpacts(impacts []*OperationImpact) {
+	recordOperationImpacts(impacts, 1)
+}
+
+// RemoveOperationImpacts removes the impact of the operations
+// in an orphaned block so that they are not counted twice
+// after a reorg.
+func RemoveOperationImpacts(impacts []*OperationImpact) {
+	recordOperationImpacts(impacts, -1)
+}
+
+// OperationImpacts returns the *OperationImpactStats of each
+// operation type observed in this invocation, ordered by
+// operation type (nil if no operations were observed).
+func OperationImpacts() []*OperationImpactStats {
+	operationImpactLock.Lock()
+	defer operationImpactLock.Unlock()
+
+	operationTypes := []string{}
+	for operationType, impact := range operationImpacts {
+		if impact.operations > 0 {
+			operationTypes = append(operationTypes, operationType)
+		}
+	}
+	if len(operationTypes) == 0 {
+		return nil
+	}
+	sort.Strings(operationTypes)
+
+	stats := make([]*OperationImpactStats, len(operationTypes))
+	for i, operationType := range operationTypes {
+		impact := operationImpacts[operationType]
+		currencies := []*CurrencyImpact{}
+		for _, totals := range impact.currencies {
+			if totals.volume.Sign() == 0 {
+				continue
+			}
+
+			currencies = append(currencies, &CurrencyImpact{
+				Currency: totals.currency,
+				Volume:   totals.volume.String(),
+				Net:      totals.net.String(),
+			})
+		}
+		sort.Slice(currencies, func(i, j int) bool {
+			return currencies[i].Currency.Symbol < currencies[j].Currency.Symbol
+		})
+		if len(currencies) == 0 {
+			currencies = nil
+		}
+
+		stats[i] = &OperationImpactStats{
+			Type:       operationType,
+			Operations: impact.operations,
+			Accounts:   int64(len(impact.accounts)),
+			Currencies: currencies,
+		}
+	}
+
+	return stats
+}
+
+// prettyImpact returns value (a decimal
+// string) in the native format of currency.
+func prettyImpact(value string, currency *types.Currency) string {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return value
+	}
+
+	return utils.PrettyAmount(amount, currency)
+}
+
+// printOperationImpacts logs the impact of each
+// operation type to the console.
+func printOperationImpacts(stats []*OperationImpactStats) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Operation Type", "Operations", "Accounts", "Volume", "Net"})
+	for _, s := range stats {
+		volumes := []string{}
+		nets := []string{}
+		for _, currency := range s.Currencies {
+			volumes = append(volumes, prettyImpact(currency.Volume, currency.Currency))
+			nets = append(nets, prettyImpact(currency.Net, currency.Currency))
+		}
+
+		table.Append([]string{
+			s.Type,
+			strconv.FormatInt(s.Operations, 10),
+			strconv.FormatInt(s.Accounts, 10),
+			strings.Join(volumes, "\n"),
+			strings.Join(nets, "\n"),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationImpacts(t *testing.T) {
+	operationImpacts = map[string]*operationTypeImpact{}
+	defer func() {
+		operationImpacts = map[string]*operationTypeImpact{}
+	}()
+	assert.Nil(t, OperationImpacts())
+
+	btc := &types.Currency{Symbol: "BTC", Decimals: 8}
+	eth := &types.Currency{Symbol: "ETH", Decimals: 18}
+	block := []*OperationImpact{
+		{Type: "Transfer", Account: "a", Currency: btc, Amount: big.NewInt(-10)},
+		{Type: "Transfer", Account: "b", Currency: btc, Amount: big.NewInt(10)},
+		{Type: "Transfer", Account: "a", Currency: eth, Amount: big.NewInt(-3)},
+		{Type: "Transfer", Account: "c"},
+		{Type: "Fee", Account: "a", Currency: btc, Amount: big.NewInt(-1)},
+	}
+	orphaned := []*OperationImpact{
+		{Type: "Transfer", Account: "c", Currency: btc, Amount: big.NewInt(5)},
+		{Type: "Reward", Account: "d", Currency: btc, Amount: big.NewInt(50)},
+	}
+	RecordOperationImpacts(block)
+	RecordOperationImpacts(orphaned)
+	RemoveOperationImpacts(orphaned)
+
+	assert.Equal(t, []*OperationImpactStats{
+		{
+			Type:       "Fee",
+			Operations: 1,
+			Accounts:   1,
+			Currencies: []*CurrencyImpact{
+				{Currency: btc, Volume: "1", Net: "-1"},
+			},
+		},
+		{
+			Type:       "Transfer",
+			Operations: 4,
+			Accounts:   3,
+			Currencies: []*CurrencyImpact{
+				{Currency: btc, Volume: "20", Net: "0"},
+				{Currency: eth, Volume: "3", Net: "-3"},
+			},
+		},
+	}, OperationImpacts())
+}
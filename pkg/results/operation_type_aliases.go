@@ -0,0 +1,83 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+var (
+	aliasUsageLock sync.Mutex
+
+	// aliasUsage is the number of operations of each
+	// aliased operation type observed in this invocation.
+	aliasUsage = map[string]int64{}
+)
+
+// RecordOperationTypeAlias records an operation with
+// an aliased (old) operation type.
+func RecordOperationTypeAlias(operationType string) {
+	aliasUsageLock.Lock()
+	defer aliasUsageLock.Unlock()
+
+	aliasUsage[operationType]++
+}
+
+// OperationTypeAliasUsage returns the number of operations of
+// each aliased operation type that were observed. If no aliased
+// operation types were observed, nil is returned.
+func OperationTypeAliasUsage() map[string]int64 {
+	aliasUsageLock.Lock()
+	defer aliasUsageLock.Unlock()
+
+	if len(aliasUsage) == 0 {
+		return nil
+	}
+
+	usage := map[string]int64{}
+	for operationType, count := range aliasUsage {
+		usage[operationType] = count
+	}
+
+	return usage
+}
+
+// printOperationTypeAliases logs operation type alias
+// usage to the console.
+func printOperationTypeAliases(usage map[string]int64) {
+	operationTypes := make([]string, 0, len(usage))
+	for operationType := range usage {
+		operationTypes = append(operationTypes, operationType)
+	}
+	sort.Strings(operationTypes)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Aliased Operation Type", "Count"})
+	for _, operationType := range operationTypes {
+		table.Append([]string{
+			operationType,
+			strconv.FormatInt(usage[operationType], 10),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ReconciliationSkip is a triaged reconciliation failure that
+// should not fail check:data until it expires. If BlockIndex
+// is not populated, failures at any block are skipped.
+type ReconciliationSkip struct {
+	Account    *types.AccountIdentifier `json:"account_identifier"`
+	Currency   *types.Currency          `json:"currency"`
+	BlockIndex *int64                   `json:"block_index,omitempty"`
+	Reason     string                   `json:"reason"`
+	Expires    time.Time                `json:"expires"`
+}
+
+// ReconciliationSkipStats is an active *ReconciliationSkip
+// and the number of reconciliation failures it suppressed.
+type ReconciliationSkipStats struct {
+	Skip       *ReconciliationSkip `json:"skip"`
+	Suppressed int64               `json:"suppressed"`
+}
+
+var (
+	reconciliationSkipsLock sync.Mutex
+
+	// reconciliationSkips are the skips active
+	// in this invocation.
+	reconciliationSkips []*ReconciliationSkipStats
+)
+
+// SetActiveReconciliationSkips records the skips that are
+// active in this invocation (resetting any suppressed counts).
+func SetActiveReconciliationSkips(skips []*ReconciliationSkip) {
+	reconciliationSkipsLock.Lock()
+	defer reconciliationSkipsLock.Unlock()
+
+	reconciliationSkips = make([]*ReconciliationSkipStats, len(skips))
+	for i, skip := range skips {
+		reconciliationSkips[i] = &ReconciliationSkipStats{Skip: skip}
+	}
+}
+
+// RecordReconciliationSkip records a reconciliation
+// failure suppressed by skip.
+func RecordReconciliationSkip(skip *ReconciliationSkip) {
+	reconciliationSkipsLock.Lock()
+	defer reconciliationSkipsLock.Unlock()
+
+	for _, stats := range reconciliationSkips {
+		if stats.Skip == skip {
+			stats.Suppressed++
+			return
+		}
+	}
+}
+
+// ActiveReconciliationSkips returns the skips active in this
+// invocation and the number of failures each suppressed. If
+// no skips are active, nil is returned.
+func ActiveReconciliationSkips() []*ReconciliationSkipStats {
+	reconciliationSkipsLock.Lock()
+	defer reconciliationSkipsLock.Unlock()
+
+	if len(reconciliationSkips) == 0 {
+		return nil
+	}
+
+	skips := make([]*ReconciliationSkipStats, len(reconciliationSkips))
+	for i, stats := range reconciliationSkips {
+		skips[i] = &ReconciliationSkipStats{
+			Skip:       stats.Skip,
+			Suppressed: stats.Suppressed,
+		}
+	}
+
+	return skips
+}
+
+// printReconciliationSkips logs active
+// reconciliation skips to the console.
+func printReconciliationSkips(skips []*ReconciliationSkipStats) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{
+		"Reconciliation Skip",
+		"Currency",
+		"Block",
+		"Reason",
+		"Expires",
+		"Suppressed",
+	})
+	for _, stats := range skips {
+		block := "any"
+		if stats.Skip.BlockIndex != nil {
+			block = strconv.FormatInt(*stats.Skip.BlockIndex, 10)
+		}
+
+		table.Append([]string{
+			types.AccountString(stats.Skip.Account),
+			stats.Skip.Currency.Symbol,
+			block,
+			stats.Skip.Reason,
+			stats.Skip.Expires.Format(time.RFC3339),
+			strconv.FormatInt(stats.Suppressed, 10),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ReorgInconsistency is an account whose balance after a
+// simulated reorg differs from its balance when the fork
+// is applied without a reorg. Balances are relative to the
+// balance of the account before the fork.
+type ReorgInconsistency struct {
+	Account  *types.AccountIdentifier `json:"account_identifier"`
+	Currency *types.Currency          `json:"currency"`
+	Reorged  string                   `json:"reorged_balance_change"`
+	Expected string                   `json:"expected_balance_change"`
+}
+
+// PrintReorgInconsistencies logs inconsistencies to the console.
+func PrintReorgInconsistencies(inconsistencies []*ReorgInconsistency) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Account", "Currency", "After Reorg", "Expected"})
+	for _, inconsistency := range inconsistencies {
+		table.Append([]string{
+			types.AccountString(inconsistency.Account),
+			inconsistency.Currency.Symbol,
+			inconsistency.Reorged,
+			inconsistency.Expected,
+		})
+	}
+
+	table.Render()
+}
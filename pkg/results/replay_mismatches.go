@@ -0,0 +1,118 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// ReplayMismatchStats counts the inconsistent answers to a
+// replayed request (see configuration.ParanoidConfiguration)
+// on a particular path.
+type ReplayMismatchStats struct {
+	Path  string `json:"path"`
+	Count int64  `json:"count"`
+
+	// Example is the reason of the first mismatch on Path.
+	Example string `json:"example"`
+}
+
+var (
+	replayMismatchesLock sync.Mutex
+
+	// replayMismatches are the *ReplayMismatchStats of
+	// this invocation (keyed by path).
+	replayMismatches = map[string]*ReplayMismatchStats{}
+)
+
+// RecordReplayMismatch records that a request to path
+// returned inconsistent answers over distinct connections.
+func RecordReplayMismatch(path string, reason string) {
+	replayMismatchesLock.Lock()
+	defer replayMismatchesLock.Unlock()
+
+	stats, ok := replayMismatches[path]
+	if !ok {
+		stats = &ReplayMismatchStats{
+			Path:    path,
+			Example: reason,
+		}
+		replayMismatches[path] = stats
+	}
+
+	stats.Count++
+}
+
+// ReplayMismatches returns the *ReplayMismatchStats of all
+// paths with inconsistent answers (sorted by path). If there
+// were no mismatches, nil is returned.
+func ReplayMismatches() []*ReplayMismatchStats {
+	replayMismatchesLock.Lock()
+	defer replayMismatchesLock.Unlock()
+
+	var mismatches []*ReplayMismatchStats
+	for _, stats := range replayMismatches {
+		copied := *stats
+		mismatches = append(mismatches, &copied)
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].Path < mismatches[j].Path
+	})
+
+	return mismatches
+}
+
+// replayMismatchError returns an error if any replayed
+// request returned inconsistent answers.
+func replayMismatchError() error {
+	var count int64
+	for _, stats := range ReplayMismatches() {
+		count += stats.Count
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: %d replayed requests returned inconsistent answers",
+		ErrInconsistentReplicas,
+		count,
+	)
+}
+
+// printReplayMismatches logs *ReplayMismatchStats to the console.
+func printReplayMismatches(mismatches []*ReplayMismatchStats) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Replay Mismatches", "Count", "Example"})
+	for _, stats := range mismatches {
+		table.Append([]string{
+			stats.Path,
+			strconv.FormatInt(stats.Count, 10),
+			stats.Example,
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayMismatches(t *testing.T) {
+	defer func() {
+		replayMismatches = map[string]*ReplayMismatchStats{}
+	}()
+
+	assert.Nil(t, ReplayMismatches())
+	assert.NoError(t, replayMismatchError())
+
+	RecordReplayMismatch("/network/status", "current block 10 has hash a != b")
+	RecordReplayMismatch("/account/balance", "balances at block 9 differ")
+	RecordReplayMismatch("/network/status", "current block 11 has hash c != d")
+
+	assert.Equal(t, []*ReplayMismatchStats{
+		{Path: "/account/balance", Count: 1, Example: "balances at block 9 differ"},
+		{Path: "/network/status", Count: 2, Example: "current block 10 has hash a != b"},
+	}, ReplayMismatches())
+
+	err := replayMismatchError()
+	assert.True(t, errors.Is(err, ErrInconsistentReplicas))
+	assert.Contains(t, err.Error(), "3 replayed requests")
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/olekukonko/tablewriter"
+)
+
+// RosettaErrorStats counts the *types.Error with a
+// particular code returned by the implementation. The
+// fetcher retries (with backoff) errors marked retriable
+// and fails fast on all others (unless force_retry is set).
+type RosettaErrorStats struct {
+	Code      int32  `json:"code"`
+	Message   string `json:"message"`
+	Retriable bool   `json:"retriable"`
+	Count     int64  `json:"count"`
+}
+
+var (
+	rosettaErrorsLock sync.Mutex
+
+	// rosettaErrors are the *RosettaErrorStats of this
+	// invocation (keyed by error code).
+	rosettaErrors = map[int32]*RosettaErrorStats{}
+)
+
+// RecordRosettaError records a *types.Error returned
+// by the implementation.
+func RecordRosettaError(err *types.Error) {
+	rosettaErrorsLock.Lock()
+	defer rosettaErrorsLock.Unlock()
+
+	stats, ok := rosettaErrors[err.Code]
+	if !ok {
+		stats = &RosettaErrorStats{
+			Code:    err.Code,
+			Message: err.Message,
+		}
+		rosettaErrors[err.Code] = stats
+	}
+
+	// An implementation may mark the same code retriable
+	// in some responses and not others, so we report the
+	// code as retriable if any response marked it so.
+	stats.Retriable = stats.Retriable || err.Retriable
+	stats.Count++
+}
+
+// RosettaErrors returns the *RosettaErrorStats of all
+// errors returned by the implementation (sorted by code).
+// If no errors were returned, nil is returned.
+func RosettaErrors() []*RosettaErrorStats {
+	rosettaErrorsLock.Lock()
+	defer rosettaErrorsLock.Unlock()
+
+	var errs []*RosettaErrorStats
+	for _, stats := range rosettaErrors {
+		copied := *stats
+		errs = append(errs, &copied)
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Code < errs[j].Code
+	})
+
+	return errs
+}
+
+// printRosettaErrors logs *RosettaErrorStats to the console.
+func printRosettaErrors(errs []*RosettaErrorStats) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Rosetta Errors", "Message", "Retriable", "Count"})
+	for _, stats := range errs {
+		table.Append([]string{
+			strconv.FormatInt(int64(stats.Code), 10),
+			stats.Message,
+			strconv.FormatBool(stats.Retriable),
+			strconv.FormatInt(stats.Count, 10),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRosettaErrors(t *testing.T) {
+	defer func() {
+		rosettaErrors = map[int32]*RosettaErrorStats{}
+	}()
+
+	assert.Nil(t, RosettaErrors())
+
+	RecordRosettaError(&types.Error{Code: 12, Message: "node busy", Retriable: true})
+	RecordRosettaError(&types.Error{Code: 3, Message: "invalid request"})
+	RecordRosettaError(&types.Error{Code: 12, Message: "node busy"})
+
+	assert.Equal(t, []*RosettaErrorStats{
+		{Code: 3, Message: "invalid request", Count: 1},
+		{Code: 12, Message: "node busy", Retriable: true, Count: 2},
+	}, RosettaErrors())
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+const (
+	// runIDBytes is the number of random bytes
+	// used to generate a run ID.
+	runIDBytes = 16
+)
+
+// RunMetadata identifies the invocation of the cli
+// that produced an artifact so that artifacts from
+// multiple runs and environments can't be confused.
+type RunMetadata struct {
+	RunID             string `json:"run_id"`
+	ConfigFingerprint string `json:"config_fingerprint"`
+	CLIVersion        string `json:"cli_version"`
+	NodeVersion       string `json:"node_version,omitempty"`
+	StartTime         int64  `json:"start_time"`
+}
+
+// run is the *RunMetadata of the current invocation.
+// It is stamped on all results and status responses.
+var run *RunMetadata
+
+// SetRunMetadata sets the *RunMetadata stamped on
+// all artifacts produced by this invocation.
+func SetRunMetadata(metadata *RunMetadata) {
+	run = metadata
+}
+
+// CurrentRunMetadata returns the *RunMetadata of this
+// invocation (nil if it has not been set).
+func CurrentRunMetadata() *RunMetadata {
+	return run
+}
+
+// ConfigFingerprint returns the hex-encoded sha256 hash
+// of the JSON encoding of a configuration.
+func ConfigFingerprint(config *configuration.Configuration) (string, error) {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to marshal configuration", err)
+	}
+
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// NewRunMetadata returns a *RunMetadata with a newly
+// generated run ID.
+func NewRunMetadata(
+	configFingerprint string,
+	cliVersion string,
+	nodeVersion string,
+) (*RunMetadata, error) {
+	id := make([]byte, runIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("%w: unable to generate run id", err)
+	}
+
+	return &RunMetadata{
+		RunID:             hex.EncodeToString(id),
+		ConfigFingerprint: configFingerprint,
+		CLIVersion:        cliVersion,
+		NodeVersion:       nodeVersion,
+		StartTime:         time.Now().Unix(),
+	}, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunMetadata(t *testing.T) {
+	cfg := configuration.DefaultConfiguration()
+	fingerprint, err := ConfigFingerprint(cfg)
+	assert.NoError(t, err)
+	assert.Len(t, fingerprint, 64)
+
+	// Fingerprint is stable for the same configuration
+	sameFingerprint, err := ConfigFingerprint(configuration.DefaultConfiguration())
+	assert.NoError(t, err)
+	assert.Equal(t, fingerprint, sameFingerprint)
+
+	// Fingerprint changes when the configuration changes
+	cfg.OnlineURL = "http://localhost:9999"
+	otherFingerprint, err := ConfigFingerprint(cfg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, fingerprint, otherFingerprint)
+
+	run1, err := NewRunMetadata(fingerprint, "v0.7.3", "1.0.0")
+	assert.NoError(t, err)
+	run2, err := NewRunMetadata(fingerprint, "v0.7.3", "1.0.0")
+	assert.NoError(t, err)
+	assert.NotEqual(t, run1.RunID, run2.RunID)
+	assert.Equal(t, fingerprint, run1.ConfigFingerprint)
+	assert.Equal(t, "1.0.0", run1.NodeVersion)
+
+	SetRunMetadata(run1)
+	defer SetRunMetadata(nil)
+	assert.Equal(t, run1, ComputeCheckDataResults(cfg, nil, nil, nil, "", "").Run)
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/olekukonko/tablewriter"
+)
+
+// PhaseResults are the results of a phase of check:schedule.
+type PhaseResults struct {
+	Name  string                   `json:"name"`
+	Check configuration.PhaseCheck `json:"check"`
+
+	// Elapsed is the length of the phase in seconds.
+	Elapsed int64 `json:"elapsed"`
+
+	Error string `json:"error,omitempty"`
+
+	Data         *CheckDataResults         `json:"data,omitempty"`
+	Construction *CheckConstructionResults `json:"construction,omitempty"`
+}
+
+// ScheduleResults are the results of all phases
+// run by check:schedule.
+type ScheduleResults struct {
+	Run    *RunMetadata    `json:"run,omitempty"`
+	Status CheckStatus     `json:"status"`
+	Timing *Timing         `json:"timing,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Phases []*PhaseResults `json:"phases"`
+}
+
+var (
+	scheduleLock sync.Mutex
+
+	phases     []*PhaseResults
+	phaseStart time.Time
+)
+
+// StartPhase records the start of a phase of check:schedule.
+// The results of the check run during the phase are recorded
+// until EndPhase is called.
+func StartPhase(name string, check configuration.PhaseCheck) {
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	phases = append(phases, &PhaseResults{
+		Name:  name,
+		Check: check,
+	})
+	phaseStart = time.Now()
+}
+
+// currentPhase returns the *PhaseResults of the phase
+// being run (nil if no phase is being run). scheduleLock
+// must be held.
+func currentPhase() *PhaseResults {
+	if len(phases) == 0 || phaseStart.IsZero() {
+		return nil
+	}
+
+	return phases[len(phases)-1]
+}
+
+// EndPhase records the end of the phase started with StartPhase.
+func EndPhase(err error) {
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	phase := currentPhase()
+	if phase == nil {
+		return
+	}
+
+	phase.Elapsed = int64(time.Since(phaseStart).Seconds())
+	if err != nil {
+		phase.Error = err.Error()
+	}
+	phaseStart = time.Time{}
+}
+
+// recordPhaseData records the results of check:data
+// for the phase being run (if any).
+func recordPhaseData(results *CheckDataResults) {
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	if phase := currentPhase(); phase != nil {
+		phase.Data = results
+	}
+}
+
+// recordPhaseConstruction records the results of check:construction
+// for the phase being run (if any).
+func recordPhaseConstruction(results *CheckConstructionResults) {
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	if phase := currentPhase(); phase != nil {
+		phase.Construction = results
+	}
+}
+
+// ComputeScheduleResults returns the *ScheduleResults
+// of all phases run by this invocation, given the error
+// check:schedule exited with.
+func ComputeScheduleResults(err error) *ScheduleResults {
+	timing := computeTiming()
+
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	results := &ScheduleResults{
+		Run:    run,
+		Status: errStatus(err),
+		Timing: timing,
+		Phases: make([]*PhaseResults, len(phases)),
+	}
+	if err != nil {
+		results.Error = err.Error()
+	}
+	copy(results.Phases, phases)
+
+	return results
+}
+
+// Print logs a summary of ScheduleResults to the console
+// (the results of each phase are logged when it ends).
+func (s *ScheduleResults) Print() {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Phase", "Check", "Elapsed", "Status"})
+	for _, phase := range s.Phases {
+		status := "Succeeded"
+		if len(phase.Error) > 0 {
+			status = "Failed: " + phase.Error
+		}
+
+		table.Append([]string{
+			phase.Name,
+			string(phase.Check),
+			(time.Duration(phase.Elapsed) * time.Second).String(),
+			status,
+		})
+	}
+
+	table.Render()
+}
+
+// Output writes ScheduleResults to the provided
+// path (if it is not empty).
+func (s *ScheduleResults) Output(path string) {
+	if len(path) > 0 {
+		writeErr := utils.SerializeAndWrite(path, s)
+		if writeErr != nil {
+			log.Printf("%s: unable to save results\n", writeErr.Error())
+		}
+	}
+}
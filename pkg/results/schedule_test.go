@@ -0,0 +1,65 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleResults(t *testing.T) {
+	defer func() {
+		phases = nil
+	}()
+
+	// Results recorded outside of a phase are ignored
+	recordPhaseData(&CheckDataResults{})
+	assert.Len(t, ComputeScheduleResults(nil).Phases, 0)
+
+	dataResults := &CheckDataResults{}
+	StartPhase("sync", configuration.DataPhaseCheck)
+	recordPhaseData(dataResults)
+	EndPhase(nil)
+
+	constructionResults := &CheckConstructionResults{}
+	StartPhase("load", configuration.ConstructionPhaseCheck)
+	recordPhaseConstruction(constructionResults)
+	EndPhase(errors.New("broadcast failed"))
+
+	// Results recorded after a phase ends are ignored
+	recordPhaseData(&CheckDataResults{})
+
+	assert.Equal(t, &ScheduleResults{
+		Status: FailStatus,
+		Error:  "broadcast failed: phase load failed",
+		Phases: []*PhaseResults{
+			{
+				Name:  "sync",
+				Check: configuration.DataPhaseCheck,
+				Data:  dataResults,
+			},
+			{
+				Name:         "load",
+				Check:        configuration.ConstructionPhaseCheck,
+				Error:        "broadcast failed",
+				Construction: constructionResults,
+			},
+		},
+	}, ComputeScheduleResults(errors.New("broadcast failed: phase load failed")))
+}
@@ -0,0 +1,213 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// SchemaDriftStats are the responses of a single construction
+// endpoint whose structure drifted from the first response
+// (its canonical schema).
+type SchemaDriftStats struct {
+	Endpoint string `json:"endpoint"`
+
+	// Fingerprint is the hash of the fields of the
+	// first response of the endpoint.
+	Fingerprint string `json:"fingerprint"`
+
+	Responses int64 `json:"responses"`
+	Drifted   int64 `json:"drifted"`
+
+	// Appeared are the fields of later responses that were
+	// missing from the first response and Disappeared are
+	// the fields of the first response that were missing
+	// from later responses.
+	Appeared    []string `json:"appeared,omitempty"`
+	Disappeared []string `json:"disappeared,omitempty"`
+}
+
+// endpointSchema is the canonical schema of an
+// endpoint and the drift observed from it.
+type endpointSchema struct {
+	fields      map[string]struct{}
+	fingerprint string
+	responses   int64
+	drifted     int64
+	appeared    map[string]struct{}
+	disappeared map[string]struct{}
+}
+
+var (
+	schemaDriftLock sync.Mutex
+
+	endpointSchemas = map[string]*endpointSchema{}
+)
+
+// SchemaFingerprint returns the hash of fields
+// (regardless of their order).
+func SchemaFingerprint(fields []string) string {
+	sorted := make([]string, len(fields))
+	copy(sorted, fields)
+	sort.Strings(sorted)
+
+	hash := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// RecordResponseSchema records the fields of a response from
+// endpoint. The fields of the first response are the canonical
+// schema of the endpoint. The fields of any later response that
+// differ from it are returned (both are empty if the response
+// did not drift).
+func RecordResponseSchema(
+	endpoint string,
+	fields []string,
+) (appeared []string, disappeared []string) {
+	schemaDriftLock.Lock()
+	defer schemaDriftLock.Unlock()
+
+	schema, ok := endpointSchemas[endpoint]
+	if !ok {
+		schema = &endpointSchema{
+			fields:      map[string]struct{}{},
+			fingerprint: SchemaFingerprint(fields),
+			appeared:    map[string]struct{}{},
+			disappeared: map[string]struct{}{},
+		}
+		for _, field := range fields {
+			schema.fields[field] = struct{}{}
+		}
+		endpointSchemas[endpoint] = schema
+	}
+	schema.responses++
+
+	seen := map[string]struct{}{}
+	for _, field := range fields {
+		seen[field] = struct{}{}
+		if _, ok := schema.fields[field]; !ok {
+			appeared = append(appeared, field)
+			schema.appeared[field] = struct{}{}
+		}
+	}
+
+	for field := range schema.fields {
+		if _, ok := seen[field]; !ok {
+			disappeared = append(disappeared, field)
+			schema.disappeared[field] = struct{}{}
+		}
+	}
+
+	if len(appeared) > 0 || len(disappeared) > 0 {
+		schema.drifted++
+	}
+
+	sort.Strings(appeared)
+	sort.Strings(disappeared)
+	return appeared, disappeared
+}
+
+// sortedFields returns the fields in s in lexical order.
+func sortedFields(s map[string]struct{}) []string {
+	if len(s) == 0 {
+		return nil
+	}
+
+	fields := []string{}
+	for field := range s {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+// SchemaDrift returns the *SchemaDriftStats of each
+// construction endpoint whose responses were recorded
+// in this invocation (nil if none were recorded).
+func SchemaDrift() []*SchemaDriftStats {
+	schemaDriftLock.Lock()
+	defer schemaDriftLock.Unlock()
+
+	if len(endpointSchemas) == 0 {
+		return nil
+	}
+
+	endpoints := []string{}
+	for endpoint := range endpointSchemas {
+		endpoints = append(endpoints, endpoint)
+	}
+
+	stats := []*SchemaDriftStats{}
+	for _, endpoint := range sortedEndpoints(endpoints) {
+		schema := endpointSchemas[endpoint]
+		stats = append(stats, &SchemaDriftStats{
+			Endpoint:    endpoint,
+			Fingerprint: schema.fingerprint,
+			Responses:   schema.responses,
+			Drifted:     schema.drifted,
+			Appeared:    sortedFields(schema.appeared),
+			Disappeared: sortedFields(schema.disappeared),
+		})
+	}
+
+	return stats
+}
+
+// printSchemaDrift logs the construction endpoints
+// whose responses drifted to the console and returns
+// whether any responses drifted.
+func printSchemaDrift(stats []*SchemaDriftStats) bool {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{
+		"Schema Drift",
+		"Responses",
+		"Drifted",
+		"Fields Appeared",
+		"Fields Disappeared",
+	})
+
+	drifted := false
+	for _, s := range stats {
+		if s.Drifted == 0 {
+			continue
+		}
+
+		drifted = true
+		table.Append([]string{
+			s.Endpoint,
+			strconv.FormatInt(s.Responses, 10),
+			strconv.FormatInt(s.Drifted, 10),
+			strings.Join(s.Appeared, "\n"),
+			strings.Join(s.Disappeared, "\n"),
+		})
+	}
+
+	if drifted {
+		table.Render()
+	}
+
+	return drifted
+}
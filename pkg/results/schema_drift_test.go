@@ -0,0 +1,70 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaDrift(t *testing.T) {
+	defer func() { endpointSchemas = map[string]*endpointSchema{} }()
+
+	assert.Nil(t, SchemaDrift())
+
+	canonical := []string{"transaction_identifier", "transaction_identifier.hash"}
+	appeared, disappeared := RecordResponseSchema("/construction/hash", canonical)
+	assert.Empty(t, appeared)
+	assert.Empty(t, disappeared)
+
+	// Field order does not matter
+	appeared, disappeared = RecordResponseSchema(
+		"/construction/hash",
+		[]string{"transaction_identifier.hash", "transaction_identifier"},
+	)
+	assert.Empty(t, appeared)
+	assert.Empty(t, disappeared)
+
+	appeared, disappeared = RecordResponseSchema(
+		"/construction/hash",
+		[]string{"transaction_identifier", "metadata", "metadata.index"},
+	)
+	assert.Equal(t, []string{"metadata", "metadata.index"}, appeared)
+	assert.Equal(t, []string{"transaction_identifier.hash"}, disappeared)
+
+	RecordResponseSchema("/construction/derive", []string{"address"})
+
+	assert.Equal(t, []*SchemaDriftStats{
+		{
+			Endpoint:    "/construction/derive",
+			Fingerprint: SchemaFingerprint([]string{"address"}),
+			Responses:   1,
+		},
+		{
+			Endpoint:    "/construction/hash",
+			Fingerprint: SchemaFingerprint(canonical),
+			Responses:   3,
+			Drifted:     1,
+			Appeared:    []string{"metadata", "metadata.index"},
+			Disappeared: []string{"transaction_identifier.hash"},
+		},
+	}, SchemaDrift())
+	assert.Equal(
+		t,
+		SchemaFingerprint([]string{"b", "a"}),
+		SchemaFingerprint([]string{"a", "b"}),
+	)
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"time"
+)
+
+// CheckStatus is the outcome of a check.
+type CheckStatus string
+
+const (
+	// PassStatus is the status of a check that
+	// completed without any error or failed test.
+	PassStatus CheckStatus = "pass"
+
+	// FailStatus is the status of a check that
+	// errored or had a failed test.
+	FailStatus CheckStatus = "fail"
+)
+
+// Timing is when a check started and ended (in seconds
+// since the Unix epoch) and its length in seconds.
+type Timing struct {
+	StartTime int64 `json:"start_time"`
+	EndTime   int64 `json:"end_time"`
+	Elapsed   int64 `json:"elapsed"`
+}
+
+// computeTiming returns the *Timing of the check that is
+// ending. Within check:schedule, the check started at the
+// start of the current phase. Otherwise, it started at the
+// start of this invocation. If the start of the check is
+// unknown, nil is returned.
+func computeTiming() *Timing {
+	scheduleLock.Lock()
+	start := phaseStart
+	scheduleLock.Unlock()
+
+	if start.IsZero() {
+		if run == nil {
+			return nil
+		}
+
+		start = time.Unix(run.StartTime, 0)
+	}
+
+	end := time.Now()
+	return &Timing{
+		StartTime: start.Unix(),
+		EndTime:   end.Unix(),
+		Elapsed:   int64(end.Sub(start).Seconds()),
+	}
+}
+
+// dataStatus returns the *CheckStatus of check:data
+// given its error and tests.
+func dataStatus(err error, tests *CheckDataTests) CheckStatus {
+	if err != nil || tests == nil {
+		return FailStatus
+	}
+
+	for _, test := range []*bool{
+		&tests.RequestResponse,
+		&tests.ResponseAssertion,
+		tests.BlockSyncing,
+		tests.BalanceTracking,
+		tests.Reconciliation,
+	} {
+		if test != nil && !*test {
+			return FailStatus
+		}
+	}
+
+	return PassStatus
+}
+
+// errStatus returns the *CheckStatus of a
+// check that only fails on error.
+func errStatus(err error) CheckStatus {
+	if err != nil {
+		return FailStatus
+	}
+
+	return PassStatus
+}
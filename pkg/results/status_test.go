@@ -0,0 +1,81 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataStatus(t *testing.T) {
+	tr := true
+	f := false
+
+	var tests = map[string]struct {
+		err   error
+		tests *CheckDataTests
+
+		status CheckStatus
+	}{
+		"passing tests": {
+			tests: &CheckDataTests{
+				RequestResponse:   true,
+				ResponseAssertion: true,
+				BlockSyncing:      &tr,
+			},
+			status: PassStatus,
+		},
+		"error": {
+			err: errors.New("bad"),
+			tests: &CheckDataTests{
+				RequestResponse:   true,
+				ResponseAssertion: true,
+			},
+			status: FailStatus,
+		},
+		"missing tests": {
+			status: FailStatus,
+		},
+		"failed test": {
+			tests: &CheckDataTests{
+				RequestResponse:   true,
+				ResponseAssertion: false,
+			},
+			status: FailStatus,
+		},
+		"failed optional test": {
+			tests: &CheckDataTests{
+				RequestResponse:   true,
+				ResponseAssertion: true,
+				BlockSyncing:      &tr,
+				Reconciliation:    &f,
+			},
+			status: FailStatus,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.status, dataStatus(test.err, test.tests))
+		})
+	}
+}
+
+func TestErrStatus(t *testing.T) {
+	assert.Equal(t, PassStatus, errStatus(nil))
+	assert.Equal(t, FailStatus, errStatus(errors.New("bad")))
+}
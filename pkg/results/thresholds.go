@@ -0,0 +1,130 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+)
+
+// ThresholdMonitor evaluates a collection of
+// *configuration.CounterThreshold against
+// the values in *modules.CounterStorage.
+type ThresholdMonitor struct {
+	thresholds []*configuration.CounterThreshold
+
+	// warned tracks which thresholds have already
+	// logged a warning so that we don't warn on
+	// every evaluation.
+	warned map[*configuration.CounterThreshold]struct{}
+}
+
+// NewThresholdMonitor returns a new *ThresholdMonitor.
+func NewThresholdMonitor(thresholds []*configuration.CounterThreshold) *ThresholdMonitor {
+	return &ThresholdMonitor{
+		thresholds: thresholds,
+		warned:     map[*configuration.CounterThreshold]struct{}{},
+	}
+}
+
+// thresholdValue returns the value of the counter (or percentage)
+// that a threshold is compared against. If the denominator is
+// zero, the threshold can't be evaluated and ok is false.
+func thresholdValue(
+	ctx context.Context,
+	counters *modules.CounterStorage,
+	threshold *configuration.CounterThreshold,
+) (float64, bool, error) {
+	count, err := counters.Get(ctx, threshold.Counter)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: unable to get counter %s", err, threshold.Counter)
+	}
+
+	value, _ := new(big.Float).SetInt(count).Float64()
+	if len(threshold.Denominator) == 0 {
+		return value, true, nil
+	}
+
+	denominator, err := counters.Get(ctx, threshold.Denominator)
+	if err != nil {
+		return -1, false, fmt.Errorf(
+			"%w: unable to get counter %s",
+			err,
+			threshold.Denominator,
+		)
+	}
+
+	if denominator.Sign() == 0 {
+		return -1, false, nil
+	}
+
+	denominatorValue, _ := new(big.Float).SetInt(denominator).Float64()
+	return value / denominatorValue * utils.OneHundred, true, nil
+}
+
+// Check evaluates all thresholds. If a threshold with the
+// "fail" action is exceeded, an error is returned. Thresholds
+// with the "warn" action only log a warning the first time
+// they are exceeded.
+func (m *ThresholdMonitor) Check(
+	ctx context.Context,
+	counters *modules.CounterStorage,
+) error {
+	for _, threshold := range m.thresholds {
+		value, ok, err := thresholdValue(ctx, counters, threshold)
+		if err != nil {
+			return err
+		}
+
+		if !ok || value <= threshold.Max {
+			continue
+		}
+
+		description := thresholdDescription(threshold, value)
+		if threshold.Action == configuration.FailThresholdAction {
+			return fmt.Errorf("%w: %s", ErrCounterThresholdExceeded, description)
+		}
+
+		if _, ok := m.warned[threshold]; ok {
+			continue
+		}
+
+		m.warned[threshold] = struct{}{}
+		color.Yellow("counter threshold exceeded: %s", description)
+	}
+
+	return nil
+}
+
+func thresholdDescription(threshold *configuration.CounterThreshold, value float64) string {
+	if len(threshold.Denominator) == 0 {
+		return fmt.Sprintf("%s (%f) > %f", threshold.Counter, value, threshold.Max)
+	}
+
+	return fmt.Sprintf(
+		"%s/%s (%f%%) > %f%%",
+		threshold.Counter,
+		threshold.Denominator,
+		value,
+		threshold.Max,
+	)
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdMonitor(t *testing.T) {
+	var tests = map[string]struct {
+		threshold *configuration.CounterThreshold
+
+		err bool
+	}{
+		"count below max": {
+			threshold: &configuration.CounterThreshold{
+				Counter: modules.OrphanCounter,
+				Max:     50,
+				Action:  configuration.FailThresholdAction,
+			},
+		},
+		"count above max": {
+			threshold: &configuration.CounterThreshold{
+				Counter: modules.OrphanCounter,
+				Max:     5,
+				Action:  configuration.FailThresholdAction,
+			},
+			err: true,
+		},
+		"count above max (warn)": {
+			threshold: &configuration.CounterThreshold{
+				Counter: modules.OrphanCounter,
+				Max:     5,
+				Action:  configuration.WarnThresholdAction,
+			},
+		},
+		"percentage below max": {
+			threshold: &configuration.CounterThreshold{
+				Counter:     modules.SkippedReconciliationsCounter,
+				Denominator: modules.ActiveReconciliationCounter,
+				Max:         1,
+				Action:      configuration.FailThresholdAction,
+			},
+		},
+		"percentage above max": {
+			threshold: &configuration.CounterThreshold{
+				Counter:     modules.OrphanCounter,
+				Denominator: modules.BlockCounter,
+				Max:         1,
+				Action:      configuration.FailThresholdAction,
+			},
+			err: true,
+		},
+		"zero denominator": {
+			threshold: &configuration.CounterThreshold{
+				Counter:     modules.OrphanCounter,
+				Denominator: modules.FailedBroadcastsCounter,
+				Max:         1,
+				Action:      configuration.FailThresholdAction,
+			},
+		},
+	}
+
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	localStore, err := database.NewBadgerDatabase(
+		ctx,
+		dir,
+		database.WithIndexCacheSize(database.TinyIndexCacheSize),
+	)
+	assert.NoError(t, err)
+	defer localStore.Close(ctx)
+
+	counterStorage := modules.NewCounterStorage(localStore)
+	for counter, value := range map[string]int64{
+		modules.BlockCounter:                  100,
+		modules.OrphanCounter:                 10,
+		modules.ActiveReconciliationCounter:   1000,
+		modules.SkippedReconciliationsCounter: 5,
+	} {
+		_, err := counterStorage.Update(ctx, counter, big.NewInt(value))
+		assert.NoError(t, err)
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			monitor := NewThresholdMonitor([]*configuration.CounterThreshold{test.threshold})
+			err := monitor.Check(ctx, counterStorage)
+			if test.err {
+				assert.ErrorIs(t, err, ErrCounterThresholdExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
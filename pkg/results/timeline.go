@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	// ReconciliationPausedEvent is recorded when a type of
+	// reconciliation is paused using the status server.
+	ReconciliationPausedEvent = "reconciliation_paused"
+
+	// ReconciliationResumedEvent is recorded when a type of
+	// reconciliation is resumed using the status server (or
+	// before the reconciler backlog is drained).
+	ReconciliationResumedEvent = "reconciliation_resumed"
+)
+
+var (
+	timelineLock sync.Mutex
+
+	// timeline is the events recorded in
+	// this invocation (in order).
+	timeline []*TimelineEvent
+)
+
+// TimelineEvent is a change made to a run while
+// it was in progress (ex: pausing reconciliation).
+type TimelineEvent struct {
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// RecordTimelineEvent appends event (with detail)
+// to the timeline of this invocation.
+func RecordTimelineEvent(event string, detail string) {
+	timelineLock.Lock()
+	defer timelineLock.Unlock()
+
+	timeline = append(timeline, &TimelineEvent{
+		Time:   time.Now(),
+		Event:  event,
+		Detail: detail,
+	})
+}
+
+// Timeline returns the events recorded in this
+// invocation (in order). If no events were recorded,
+// nil is returned.
+func Timeline() []*TimelineEvent {
+	timelineLock.Lock()
+	defer timelineLock.Unlock()
+
+	if len(timeline) == 0 {
+		return nil
+	}
+
+	events := make([]*TimelineEvent, len(timeline))
+	copy(events, timeline)
+	return events
+}
+
+// printTimeline logs timeline
+// events to the console.
+func printTimeline(events []*TimelineEvent) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{"Timeline Time", "Event", "Detail"})
+	for _, event := range events {
+		table.Append([]string{
+			event.Time.Format(time.RFC3339),
+			event.Event,
+			event.Detail,
+		})
+	}
+
+	table.Render()
+}
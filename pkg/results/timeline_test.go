@@ -0,0 +1,39 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeline(t *testing.T) {
+	assert.Nil(t, Timeline())
+
+	RecordTimelineEvent(ReconciliationPausedEvent, "active")
+	RecordTimelineEvent(ReconciliationResumedEvent, "active")
+
+	events := Timeline()
+	assert.Len(t, events, 2)
+	assert.Equal(t, ReconciliationPausedEvent, events[0].Event)
+	assert.Equal(t, "active", events[0].Detail)
+	assert.Equal(t, ReconciliationResumedEvent, events[1].Event)
+	assert.False(t, events[1].Time.Before(events[0].Time))
+
+	// The returned events are a copy
+	events[0] = nil
+	assert.NotNil(t, Timeline()[0])
+}
@@ -16,16 +16,223 @@ package results
 
 import (
 	"errors"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
 )
 
 const (
 	// TimeElapsedCounter tracks the total time elapsed in seconds.
 	TimeElapsedCounter = "time_elapsed"
+
+	// UnstructuredSubmitErrorsCounter tracks the number of
+	// rejected /construction/submit requests that did not
+	// return a *types.Error.
+	UnstructuredSubmitErrorsCounter = "unstructured_submit_errors"
+
+	// ExternalDepositsCounter tracks the number of inbound
+	// transfers to generated addresses that were not
+	// broadcast by the rosetta-cli (ex: faucet deposits).
+	ExternalDepositsCounter = "external_deposits"
+
+	// AmbiguousConditionsCounter tracks the number of conditions
+	// the Rosetta specification is ambiguous about (ex: empty
+	// metadata objects) observed in synced blocks.
+	AmbiguousConditionsCounter = "ambiguous_conditions"
+
+	// DustConsolidationsCounter tracks the number of confirmed
+	// transactions that consolidated dust coins.
+	DustConsolidationsCounter = "dust_consolidations"
+
+	// MultiSenderSpendsCounter tracks the number of confirmed
+	// transactions that spent coins held by several accounts.
+	MultiSenderSpendsCounter = "multi_sender_spends"
+
+	// CPFPTransactionsCounter tracks the number of confirmed
+	// parents and children of child-pays-for-parent scenarios.
+	CPFPTransactionsCounter = "cpfp_transactions"
+
+	// CPFPScenariosCounter tracks the number of child-pays-for-parent
+	// scenarios where both transactions confirmed and the child spent
+	// the change of the parent in the mempool and in blocks.
+	CPFPScenariosCounter = "cpfp_scenarios"
+
+	// ExcludedAddressesCounter tracks the number of addresses
+	// excluded from account selection after being used in
+	// construction.max_address_reuse broadcasts.
+	ExcludedAddressesCounter = "excluded_addresses"
+
+	// ReplayedTransactionsCounter tracks the number of confirmed
+	// transactions modeled on transactions observed by check:data.
+	ReplayedTransactionsCounter = "replayed_transactions"
+
+	// PipelineTransfersCounter tracks the number of confirmed
+	// transfers constructed by sender pipelines.
+	PipelineTransfersCounter = "pipeline_transfers"
+
+	// ExplorerChecksCounter tracks the number of transactions
+	// and balances successfully cross-checked against an explorer.
+	ExplorerChecksCounter = "explorer_checks"
+
+	// HistoricalReconciliationsCounter tracks the number of
+	// successful reconciliations at historical blocks.
+	HistoricalReconciliationsCounter = "historical_reconciliations"
+
+	// StateCommitmentsCounter tracks the number of checkpoints
+	// where balances matched the chain's state commitment.
+	StateCommitmentsCounter = "state_commitments"
+
+	// signaturesCounterPrefix is the prefix of the counters
+	// that track the number of signatures of each curve type.
+	signaturesCounterPrefix = "signatures_"
 )
 
+// curveTypes are all curve types signatures
+// are counted for.
+var curveTypes = []types.CurveType{
+	types.Secp256k1,
+	types.Secp256r1,
+	types.Edwards25519,
+	types.Tweedle,
+	types.Pallas,
+}
+
+// SignaturesCounter returns the counter that tracks the
+// number of signatures of curveType.
+func SignaturesCounter(curveType types.CurveType) string {
+	return signaturesCounterPrefix + string(curveType)
+}
+
 var (
 	// ErrReconciliationFailure is returned if reconciliation fails.
 	// TODO: Move to reconciler package (had to remove from processor
 	// to prevent circular dependency)
 	ErrReconciliationFailure = errors.New("reconciliation failure")
+
+	// ErrCurrencyFilterIgnored is returned when /account/balance
+	// returns currencies that were not requested in the
+	// currencies filter.
+	ErrCurrencyFilterIgnored = errors.New("account balance currency filter ignored")
+
+	// ErrCurrencyFilterMismatch is returned when the balance returned
+	// for a filtered /account/balance request does not match
+	// the balance returned when no filter is provided.
+	ErrCurrencyFilterMismatch = errors.New("filtered account balance does not match unfiltered balance")
+
+	// ErrBlockTransactionMismatch is returned when a transaction fetched
+	// with /block/transaction does not match the transaction in /block.
+	ErrBlockTransactionMismatch = errors.New("block transaction mismatch")
+
+	// ErrCounterThresholdExceeded is returned when a counter
+	// exceeds a configured threshold with the "fail" action.
+	ErrCounterThresholdExceeded = errors.New("counter threshold exceeded")
+
+	// ErrMultisigThresholdNotMet is returned when /construction/payloads
+	// requests signatures from (or /construction/parse returns) fewer
+	// accounts than the configured multisig threshold.
+	ErrMultisigThresholdNotMet = errors.New("multisig threshold not met")
+
+	// ErrInconsistentReplicas is returned when a request replayed
+	// over a distinct connection returns an answer that is
+	// inconsistent with the original answer.
+	ErrInconsistentReplicas = errors.New("inconsistent answers over distinct connections")
+
+	// ErrAddressCollision is returned when /construction/derive
+	// returns an address already associated with a different
+	// public key.
+	ErrAddressCollision = errors.New("derived address collision")
+
+	// ErrOperationOrdering is returned when the operations in a
+	// block can't be applied in a single, deterministic order.
+	ErrOperationOrdering = errors.New("invalid operation ordering")
+
+	// ErrCurrencyDecimalsMismatch is returned when amounts in
+	// recent blocks are inconsistent with currency decimals.
+	ErrCurrencyDecimalsMismatch = errors.New("currency decimals mismatch")
+
+	// ErrAmbiguousCondition is returned when a block contains
+	// a condition the Rosetta specification is ambiguous about
+	// and strictness is "strict".
+	ErrAmbiguousCondition = errors.New("ambiguous condition")
+
+	// ErrTransactionAssertion is returned when a transaction
+	// constructed during check:construction fails a configured
+	// transaction assertion.
+	ErrTransactionAssertion = errors.New("transaction assertion failed")
+
+	// ErrMempoolVerification is returned when a broadcast transaction
+	// does not appear in the mempool in time or appears with
+	// operations that don't match its intent.
+	ErrMempoolVerification = errors.New("mempool verification failed")
+
+	// ErrCPFPVerification is returned when the child of a
+	// child-pays-for-parent scenario does not spend the change of
+	// the parent (in the mempool or in blocks), is confirmed before
+	// the parent, or the scenario does not complete in time.
+	ErrCPFPVerification = errors.New("cpfp verification failed")
+
+	// ErrBalanceChangeMismatch is returned when the balance changes
+	// of a confirmed transaction don't match its intent.
+	ErrBalanceChangeMismatch = errors.New("balance change mismatch")
+
+	// ErrNodeBroadcast is returned when a node rejects a transaction
+	// broadcast with a JSON-RPC request (or returns an invalid hash).
+	ErrNodeBroadcast = errors.New("node broadcast failed")
+
+	// ErrAccountMetadata is returned when an implementation drops
+	// or changes the metadata of an account identifier.
+	ErrAccountMetadata = errors.New("account metadata not preserved")
+
+	// ErrExplorerMismatch is returned when a transaction or balance
+	// returned by the implementation differs from an explorer.
+	ErrExplorerMismatch = errors.New("explorer mismatch")
+
+	// ErrStateCommitmentMismatch is returned when the balances
+	// computed by check:data do not match the state commitment
+	// in block metadata.
+	ErrStateCommitmentMismatch = errors.New("state commitment mismatch")
+
+	// ErrOfflineNotIsolated is returned when the implementation
+	// at the offline URL serves an endpoint that requires
+	// network state.
+	ErrOfflineNotIsolated = errors.New("offline endpoint serves network state")
+
+	// ErrCheckHalted is returned when a check is stopped by a
+	// signal before its end conditions are reached.
+	ErrCheckHalted = errors.New("check halted")
+
+	// ErrHistoricalReconciliation is returned when the computed
+	// balance of an account at a historical block differs from
+	// the live balance at that block.
+	ErrHistoricalReconciliation = errors.New("historical reconciliation failure")
+
+	// ErrReorgInconsistent is returned when account balances
+	// after a simulated reorg differ from the balances expected
+	// when the fork is applied without a reorg.
+	ErrReorgInconsistent = errors.New("balances inconsistent after reorg")
+
+	// ErrBlockIntegrity is returned when a stored block
+	// violates a structural invariant.
+	ErrBlockIntegrity = errors.New("block integrity violation")
+
+	// ErrCheckpointMismatch is returned when the blocks fetched
+	// while fast-forwarding through checkpoints do not form a hash
+	// chain through every checkpoint.
+	ErrCheckpointMismatch = errors.New("checkpoint mismatch")
+
+	// ErrNetworksFailed is returned when check:data
+	// --all-networks fails on any network.
+	ErrNetworksFailed = errors.New("check:data failed on networks")
+
+	// ErrDataDirectoryLocked is returned when a data directory
+	// is in use by another rosetta-cli process.
+	ErrDataDirectoryLocked = errors.New("data directory locked")
+
+	// ErrAirGap is returned when the offline-agent does not respond
+	// to a request in time or responds with an error.
+	ErrAirGap = errors.New("offline-agent request failed")
+
+	// ErrCannotResume is returned when check:construction or
+	// check:data is run with --resume but there is no unfinished
+	// run (started with the same configuration) to continue.
+	ErrCannotResume = errors.New("unable to resume run")
 )
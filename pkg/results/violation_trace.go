@@ -0,0 +1,93 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// TraceStep is a single step of re-processing
+// the block that caused a violation.
+type TraceStep struct {
+	Step       string `json:"step"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ViolationTrace is the trace of re-fetching and re-processing
+// the block that caused the violation that halted check:data.
+type ViolationTrace struct {
+	// Error is the violation that was traced.
+	Error string `json:"error"`
+
+	BlockIndex int64        `json:"block_index"`
+	Steps      []*TraceStep `json:"steps"`
+}
+
+var (
+	violationTraceLock sync.Mutex
+
+	violationTrace *ViolationTrace
+)
+
+// RecordViolationTrace records trace as the trace of the first
+// violation. Traces of any later violations are ignored.
+func RecordViolationTrace(trace *ViolationTrace) {
+	violationTraceLock.Lock()
+	defer violationTraceLock.Unlock()
+
+	if violationTrace != nil {
+		return
+	}
+
+	violationTrace = trace
+}
+
+// ViolationTraceResults returns the *ViolationTrace of the first
+// violation (nil if no violation was traced).
+func ViolationTraceResults() *ViolationTrace {
+	violationTraceLock.Lock()
+	defer violationTraceLock.Unlock()
+
+	return violationTrace
+}
+
+// Print logs each step of the trace to the console.
+func (v *ViolationTrace) Print() {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	table.SetHeader([]string{
+		"Trace of Block " + strconv.FormatInt(v.BlockIndex, 10),
+		"Detail",
+		"Error",
+		"Duration (ms)",
+	})
+	for _, step := range v.Steps {
+		table.Append([]string{
+			step.Step,
+			step.Detail,
+			step.Error,
+			strconv.FormatInt(step.DurationMS, 10),
+		})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package results
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordViolationTrace(t *testing.T) {
+	violationTrace = nil
+	defer func() {
+		violationTrace = nil
+	}()
+	assert.Nil(t, ViolationTraceResults())
+
+	first := &ViolationTrace{
+		Error:      "reconciliation failure",
+		BlockIndex: 10,
+		Steps: []*TraceStep{
+			{Step: "Fetch Block", Detail: "block 10"},
+		},
+	}
+	RecordViolationTrace(first)
+	RecordViolationTrace(&ViolationTrace{Error: "later failure", BlockIndex: 11})
+	assert.Equal(t, first, ViolationTraceResults())
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/coinbase/rosetta-sdk-go/constructor/coordinator"
 	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/keys"
 	"github.com/coinbase/rosetta-sdk-go/parser"
 	"github.com/coinbase/rosetta-sdk-go/statefulsyncer"
 	"github.com/coinbase/rosetta-sdk-go/storage/database"
@@ -47,8 +48,10 @@ const (
 	// for all data saved using this command.
 	constructionCmdName = "check-construction"
 
-	endConditionsCheckInterval = 10 * time.Second
-	tipWaitInterval            = 10 * time.Second
+	// ReturnFundsAddressEnv is the environment variable a
+	// return_funds workflow can load (with load_env) to find
+	// the address provided with --end-return-funds.
+	ReturnFundsAddressEnv = "RETURN_FUNDS_ADDRESS"
 )
 
 var _ http.Handler = (*ConstructionTester)(nil)
@@ -66,12 +69,39 @@ type ConstructionTester struct {
 	jobStorage       *modules.JobStorage
 	counterStorage   *modules.CounterStorage
 	coordinator      *coordinator.Coordinator
+	helper           *processor.CoordinatorHelper
+	mempoolVerifier  *processor.MempoolVerifier
+	dustConsolidator *processor.DustConsolidator
+	multiSender      *processor.MultiSenderSpender
+	cpfpRunner       *processor.CPFPRunner
+	replayer         *processor.Replayer
+	senderPipelines  *processor.SenderPipelines
 	cancel           context.CancelFunc
 	signalReceived   *bool
+	thresholdMonitor *results.ThresholdMonitor
+	dataPath         string
+	lock             *DataDirectoryLock
+	progress         *ConstructionProgress
+
+	// returnFundsOnExit indicates if the return_funds
+	// workflow should also be run when the check is
+	// interrupted (instead of only when end conditions
+	// are reached).
+	returnFundsOnExit bool
 
 	reachedEndConditions bool
 }
 
+// ConstructionDataPath returns the path of the
+// check:construction database (creating it if it
+// does not exist).
+func ConstructionDataPath(
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+) (string, error) {
+	return utils.CreateCommandPath(config.DataDirectory, constructionCmdName, network)
+}
+
 // InitializeConstruction initiates the construction API tester.
 func InitializeConstruction(
 	ctx context.Context,
@@ -80,12 +110,21 @@ func InitializeConstruction(
 	onlineFetcher *fetcher.Fetcher,
 	cancel context.CancelFunc,
 	signalReceived *bool,
+	returnFundsOnExit bool,
+	resume bool,
+	fresh bool,
+	forceTakeover bool,
 ) (*ConstructionTester, error) {
-	dataPath, err := utils.CreateCommandPath(config.DataDirectory, constructionCmdName, network)
+	dataPath, err := ConstructionDataPath(config, network)
 	if err != nil {
 		log.Fatalf("%s: cannot create command path", err.Error())
 	}
 
+	lock, err := AcquireDataDirectoryLock(dataPath, forceTakeover)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to lock data directory", err)
+	}
+
 	opts := []database.BadgerOption{}
 	if config.CompressionDisabled {
 		opts = append(opts, database.WithoutCompression())
@@ -101,6 +140,7 @@ func InitializeConstruction(
 	if err != nil {
 		log.Fatalf("%s: unable to initialize database", err.Error())
 	}
+	databaseOpened()
 
 	networkOptions, fetchErr := onlineFetcher.NetworkOptionsRetry(ctx, network, nil)
 	if err != nil {
@@ -112,6 +152,11 @@ func InitializeConstruction(
 		log.Fatal("found balance exemptions but initial balance fetch disabled")
 	}
 
+	balanceRules, err := processor.NewBalanceRules(config.Data.BalanceRules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to compile balance rules", err)
+	}
+
 	counterStorage := modules.NewCounterStorage(localStore)
 	logger := logger.NewLogger(
 		dataPath,
@@ -123,6 +168,7 @@ func InitializeConstruction(
 
 	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
 	keyStorage := modules.NewKeyStorage(localStore)
+	addressBookStorage := processor.NewAddressBookStorage(localStore)
 	coinStorageHelper := processor.NewCoinStorageHelper(blockStorage)
 	coinStorage := modules.NewCoinStorage(localStore, coinStorageHelper, onlineFetcher.Asserter)
 	balanceStorage := modules.NewBalanceStorage(localStore)
@@ -148,20 +194,61 @@ func InitializeConstruction(
 
 	balanceStorage.Initialize(balanceStorageHelper, balanceStorageHandler)
 
+	// Otherwise, at most BlockBroadcastLimit transactions
+	// could be pending during a load test.
+	blockBroadcastLimit := config.Construction.BlockBroadcastLimit
+	if loadTest := config.Construction.LoadTest; loadTest != nil &&
+		loadTest.Concurrency > blockBroadcastLimit {
+		blockBroadcastLimit = loadTest.Concurrency
+	}
+
 	broadcastStorage := modules.NewBroadcastStorage(
 		localStore,
 		config.Construction.StaleDepth,
 		config.Construction.BroadcastLimit,
 		config.TipDelay,
 		config.Construction.BroadcastBehindTip,
-		config.Construction.BlockBroadcastLimit,
+		blockBroadcastLimit,
 	)
 
 	parser := parser.New(onlineFetcher.Asserter, nil, networkOptions.Allow.BalanceExemptions)
+
+	var mempoolVerifier *processor.MempoolVerifier
+	if config.Construction.MempoolVerification != nil {
+		mempoolVerifier = processor.NewMempoolVerifier(
+			network,
+			onlineFetcher,
+			localStore,
+			blockStorage,
+			broadcastStorage,
+			processor.NewOperationMatcher(parser, config.Construction.OperationMatching),
+			time.Duration(config.Construction.MempoolVerification.Timeout)*time.Second,
+		)
+	}
+
+	clientOptions, err := processor.NewClientOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var broadcaster processor.Broadcaster
+	if backend := config.Construction.BroadcastBackend; backend != nil &&
+		backend.Type == configuration.JSONRPCBroadcastBackend {
+		broadcaster = processor.NewJSONRPCBroadcaster(
+			backend.URL,
+			backend.Method,
+			time.Duration(backend.Timeout)*time.Second,
+			clientOptions.Proxy,
+		)
+	}
+
 	broadcastHelper := processor.NewBroadcastStorageHelper(
 		network,
 		blockStorage,
 		onlineFetcher,
+		counterStorage,
+		mempoolVerifier,
+		broadcaster,
 	)
 
 	fetcherOpts := []fetcher.Option{
@@ -173,18 +260,37 @@ func InitializeConstruction(
 	if config.Construction.ForceRetry {
 		fetcherOpts = append(fetcherOpts, fetcher.WithForceRetry())
 	}
+	fetcherOpts = append(fetcherOpts, processor.RetryFetcherOptions(config)...)
+	fetcherOpts = append(fetcherOpts, fetcher.WithClient(processor.NewOfflineAPIClient(
+		config.Construction.OfflineURL,
+		time.Duration(config.HTTPTimeout)*time.Second,
+		config.Construction.MaxOfflineConnections,
+		clientOptions,
+	)))
 
 	offlineFetcher := fetcher.New(
 		config.Construction.OfflineURL,
 		fetcherOpts...,
 	)
 
+	if config.Construction.VerifyOfflineIsolation {
+		if err := processor.VerifyOfflineIsolation(ctx, offlineFetcher, network); err != nil {
+			return nil, fmt.Errorf("%w: unable to verify offline isolation", err)
+		}
+	}
+
 	// Import prefunded account and save to database
 	err = keyStorage.ImportAccounts(ctx, config.Construction.PrefundedAccounts)
 	if err != nil {
 		return nil, err
 	}
 
+	if remoteSigner := config.Construction.RemoteSigner; remoteSigner != nil {
+		if err := importRemoteSignerAccounts(ctx, keyStorage, remoteSigner.Accounts); err != nil {
+			return nil, fmt.Errorf("%w: unable to import remote signer accounts", err)
+		}
+	}
+
 	// Load all accounts for network
 	accounts, err := keyStorage.GetAllAccounts(ctx)
 	if err != nil {
@@ -209,6 +315,15 @@ func InitializeConstruction(
 
 		accountBalanceRequests = append(accountBalanceRequests, accountBalance)
 	}
+	if remoteSigner := config.Construction.RemoteSigner; remoteSigner != nil {
+		for _, remoteAcc := range remoteSigner.Accounts {
+			accountBalanceRequests = append(accountBalanceRequests, &utils.AccountBalanceRequest{
+				Account:  remoteAcc.AccountIdentifier,
+				Network:  network,
+				Currency: remoteAcc.Currency,
+			})
+		}
+	}
 
 	accBalances, err := utils.GetAccountBalances(ctx, onlineFetcher, accountBalanceRequests)
 	if err != nil {
@@ -225,9 +340,88 @@ func InitializeConstruction(
 		return nil, fmt.Errorf("%w: unable to set coin balances", err)
 	}
 
+	var metadataCache *processor.MetadataCache
+	if config.Construction.MetadataCacheTTL > 0 {
+		metadataCache = processor.NewMetadataCache(
+			time.Duration(config.Construction.MetadataCacheTTL) * time.Second,
+		)
+	}
+
+	var feeEstimator *processor.FeeEstimator
+	if feeEstimation := config.Construction.FeeEstimation; feeEstimation != nil {
+		maxFee, err := types.BigInt(feeEstimation.MaxFee)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid fee estimation max fee", err)
+		}
+
+		feeEstimator = processor.NewFeeEstimator(
+			feeEstimation.Window,
+			feeEstimation.Percentile,
+			maxFee,
+		)
+	}
+
+	var nonceTracker *processor.NonceTracker
+	if nonceTracking := config.Construction.NonceTracking; nonceTracking != nil {
+		nonceTracker = processor.NewNonceTracker(
+			nonceTracking.MetadataKey,
+			nonceTracking.MaxPending,
+			keyStorage,
+		)
+	}
+
+	var signer processor.Signer = keyStorage
+	if remoteSigner := config.Construction.RemoteSigner; remoteSigner != nil {
+		signer = processor.NewRemoteSigner(
+			remoteSigner.URL,
+			time.Duration(remoteSigner.Timeout)*time.Second,
+			clientOptions.Proxy,
+			keyStorage,
+		)
+	}
+
+	// When using an air gap, all offline construction
+	// and signing is performed by the offline-agent.
+	var offlineConstructor processor.OfflineConstructor = offlineFetcher
+	var airGapClient *processor.AirGapClient
+	if airGap := config.Construction.AirGap; airGap != nil {
+		airGapClient, err = processor.NewAirGapClient(airGap)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize air gap", err)
+		}
+
+		offlineConstructor = airGapClient
+		signer = airGapClient
+	}
+
+	var hdWallet *processor.HDWallet
+	if config.Construction.HDWallet != nil {
+		hdWallet, err = processor.NewHDWallet(ctx, config.Construction.HDWallet, localStore, signer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize hd wallet", err)
+		}
+
+		signer = hdWallet
+	}
+
+	skipParse := false
+	for _, step := range config.Construction.SkippedSteps {
+		if step == configuration.ParseConstructionStep {
+			skipParse = true
+		}
+	}
+
+	var failureRecorder *processor.FailureRecorder
+	if len(config.Construction.FailureDirectory) > 0 {
+		failureRecorder, err = processor.NewFailureRecorder(config.Construction.FailureDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to initialize failure recorder", err)
+		}
+	}
+
 	jobStorage := modules.NewJobStorage(localStore)
 	coordinatorHelper := processor.NewCoordinatorHelper(
-		offlineFetcher,
+		offlineConstructor,
 		onlineFetcher,
 		localStore,
 		blockStorage,
@@ -238,8 +432,118 @@ func InitializeConstruction(
 		balanceStorageHelper,
 		counterStorage,
 		config.Construction.Quiet,
+		&processor.CoordinatorHelperOptions{
+			Signer:                signer,
+			AirGap:                airGapClient,
+			MetadataCache:         metadataCache,
+			MetadataDelay:         time.Duration(config.Construction.MetadataDelay) * time.Second,
+			StepDelayer:           processor.NewStepDelayer(config.Construction.StepDelays),
+			FeeEstimator:          feeEstimator,
+			NonceTracker:          nonceTracker,
+			CoinSelection:         config.Construction.CoinSelection,
+			SkipParse:             skipParse,
+			LoadTest:              config.Construction.LoadTest,
+			TransactionAssertions: config.Construction.TransactionAssertions,
+			MultisigThreshold:     config.Construction.MultisigThreshold,
+			InjectedOperations:    config.Construction.InjectedOperations,
+			FailureRecorder:       failureRecorder,
+			AddressBook:           addressBookStorage,
+			AddressReuse:          processor.NewAddressReuseTracker(config.Construction.MaxAddressReuse),
+			HDWallet:              hdWallet,
+		},
 	)
 
+	if pool := config.Construction.AddressPool; pool != nil {
+		curveTypes := pool.CurveTypes
+		if len(curveTypes) == 0 {
+			curveTypes = []*configuration.WeightedCurveType{
+				{CurveType: pool.CurveType, Weight: 1},
+			}
+		}
+
+		generated, err := coordinatorHelper.PregenerateAddresses(
+			ctx,
+			network,
+			curveTypes,
+			pool.Size,
+			pool.Metadata,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to pre-generate address pool", err)
+		}
+
+		log.Printf("pre-generated %d addresses for address pool\n", generated)
+	}
+
+	var dustConsolidator *processor.DustConsolidator
+	if config.Construction.DustConsolidation != nil {
+		dustConsolidator = processor.NewDustConsolidator(
+			network,
+			coordinatorHelper,
+			config.Construction.DustConsolidation,
+			configuration.DefaultConfirmationDepth,
+		)
+	}
+
+	var multiSender *processor.MultiSenderSpender
+	if config.Construction.MultiSenderSpend != nil {
+		multiSender = processor.NewMultiSenderSpender(
+			network,
+			coordinatorHelper,
+			config.Construction.MultiSenderSpend,
+			configuration.DefaultConfirmationDepth,
+		)
+	}
+
+	var cpfpRunner *processor.CPFPRunner
+	if config.Construction.CPFP != nil {
+		cpfpRunner = processor.NewCPFPRunner(
+			network,
+			coordinatorHelper,
+			config.Construction.CPFP,
+			configuration.DefaultConfirmationDepth,
+		)
+	}
+
+	var replayer *processor.Replayer
+	if config.Construction.Replay != nil {
+		samples, err := sampleReplayTransactions(ctx, config)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to sample transactions to replay", err)
+		}
+
+		log.Printf("sampled %d transactions to replay\n", len(samples))
+		replayer = processor.NewReplayer(
+			network,
+			coordinatorHelper,
+			config.Construction.Replay,
+			samples,
+			configuration.DefaultConfirmationDepth,
+		)
+	}
+
+	var senderPipelines *processor.SenderPipelines
+	if pipelines := config.Construction.SenderPipelines; pipelines != nil {
+		addressBook := []*processor.AddressBookEntry{}
+		if len(pipelines.AddressBook) > 0 {
+			addressBook, err = processor.LoadAddressBook(pipelines.AddressBook)
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to load address book", err)
+			}
+
+			log.Printf("loaded %d addresses from address book\n", len(addressBook))
+		}
+
+		senderPipelines = processor.NewSenderPipelines(
+			network,
+			coordinatorHelper,
+			processor.NewKeyTagStorage(localStore),
+			pipelines,
+			addressBook,
+			configuration.DefaultConfirmationDepth,
+		)
+	}
+
 	coordinatorHandler := processor.NewCoordinatorHandler(
 		counterStorage,
 	)
@@ -259,10 +563,28 @@ func InitializeConstruction(
 		counterStorage,
 		coordinator,
 		parser,
+		nonceTracker,
 	)
 
 	broadcastStorage.Initialize(broadcastHelper, broadcastHandler)
 
+	progress, err := prepareProgress(
+		ctx,
+		dataPath,
+		config,
+		localStore,
+		jobStorage,
+		broadcastStorage,
+		counterStorage,
+		resume,
+		fresh,
+	)
+	if err != nil {
+		_ = localStore.Close(ctx)
+		databaseClosed()
+		return nil, err
+	}
+
 	syncer := statefulsyncer.New(
 		ctx,
 		network,
@@ -271,7 +593,27 @@ func InitializeConstruction(
 		counterStorage,
 		logger,
 		cancel,
-		[]modules.BlockWorker{counterStorage, balanceStorage, coinStorage, broadcastStorage},
+		[]modules.BlockWorker{
+			counterStorage,
+			processor.NewExternalDepositWorker(
+				onlineFetcher.Asserter,
+				keyStorage,
+				broadcastStorage,
+				counterStorage,
+			),
+			processor.NewAddressBookWorker(
+				onlineFetcher.Asserter,
+				keyStorage,
+				addressBookStorage,
+			),
+			processor.NewTransferGraphWorker(
+				onlineFetcher.Asserter,
+				broadcastStorage,
+			),
+			balanceWorker(balanceStorage, balanceRules),
+			coinStorage,
+			broadcastStorage,
+		},
 		statefulsyncer.WithCacheSize(syncer.DefaultCacheSize),
 		statefulsyncer.WithMaxConcurrency(config.MaxSyncConcurrency),
 		statefulsyncer.WithPastBlockLimit(config.MaxReorgDepth),
@@ -279,19 +621,31 @@ func InitializeConstruction(
 	)
 
 	return &ConstructionTester{
-		network:          network,
-		database:         localStore,
-		config:           config,
-		syncer:           syncer,
-		logger:           logger,
-		coordinator:      coordinator,
-		broadcastStorage: broadcastStorage,
-		blockStorage:     blockStorage,
-		jobStorage:       jobStorage,
-		counterStorage:   counterStorage,
-		onlineFetcher:    onlineFetcher,
-		cancel:           cancel,
-		signalReceived:   signalReceived,
+		network:           network,
+		database:          localStore,
+		config:            config,
+		syncer:            syncer,
+		logger:            logger,
+		coordinator:       coordinator,
+		helper:            coordinatorHelper,
+		mempoolVerifier:   mempoolVerifier,
+		dustConsolidator:  dustConsolidator,
+		multiSender:       multiSender,
+		cpfpRunner:        cpfpRunner,
+		replayer:          replayer,
+		senderPipelines:   senderPipelines,
+		broadcastStorage:  broadcastStorage,
+		blockStorage:      blockStorage,
+		jobStorage:        jobStorage,
+		counterStorage:    counterStorage,
+		onlineFetcher:     onlineFetcher,
+		cancel:            cancel,
+		signalReceived:    signalReceived,
+		returnFundsOnExit: returnFundsOnExit,
+		thresholdMonitor:  results.NewThresholdMonitor(config.CounterThresholds),
+		dataPath:          dataPath,
+		lock:              lock,
+		progress:          progress,
 	}, nil
 }
 
@@ -300,6 +654,148 @@ func (t *ConstructionTester) CloseDatabase(ctx context.Context) {
 	if err := t.database.Close(ctx); err != nil {
 		log.Fatalf("%s: error closing database", err.Error())
 	}
+	databaseClosed()
+	t.lock.Release()
+}
+
+// StartMetricsServer serves Prometheus metrics
+// at addr if it is populated.
+func (t *ConstructionTester) StartMetricsServer(ctx context.Context, addr string) error {
+	return StartMetricsServer(
+		ctx,
+		addr,
+		results.MetricsHandler(t.counterStorage, t.dataPath),
+	)
+}
+
+// StartMempoolVerifier checks that broadcast transactions appear
+// in the mempool (if mempool verification is enabled).
+func (t *ConstructionTester) StartMempoolVerifier(ctx context.Context) error {
+	if t.mempoolVerifier == nil {
+		return nil
+	}
+
+	return t.mempoolVerifier.Start(ctx)
+}
+
+// StartDustConsolidator periodically consolidates dust
+// coins (if dust consolidation is enabled).
+func (t *ConstructionTester) StartDustConsolidator(ctx context.Context) error {
+	if t.dustConsolidator == nil {
+		return nil
+	}
+
+	return t.dustConsolidator.Start(ctx)
+}
+
+// StartMultiSenderSpender periodically spends coins of several
+// accounts in one transaction (if multi-sender spends are enabled).
+func (t *ConstructionTester) StartMultiSenderSpender(ctx context.Context) error {
+	if t.multiSender == nil {
+		return nil
+	}
+
+	return t.multiSender.Start(ctx)
+}
+
+// StartCPFPRunner periodically runs child-pays-for-parent
+// scenarios (if cpfp is enabled).
+func (t *ConstructionTester) StartCPFPRunner(ctx context.Context) error {
+	if t.cpfpRunner == nil {
+		return nil
+	}
+
+	return t.cpfpRunner.Start(ctx)
+}
+
+// StartReplayer periodically replays transactions observed
+// by check:data (if replay is enabled).
+func (t *ConstructionTester) StartReplayer(ctx context.Context) error {
+	if t.replayer == nil {
+		return nil
+	}
+
+	return t.replayer.Start(ctx)
+}
+
+// StartSenderPipelines constructs transfers in parallel
+// (if sender pipelines are enabled).
+func (t *ConstructionTester) StartSenderPipelines(ctx context.Context) error {
+	if t.senderPipelines == nil {
+		return nil
+	}
+
+	return t.senderPipelines.Start(ctx)
+}
+
+// importRemoteSignerAccounts stores the public key of each
+// account whose private key is only held by the remote signer
+// (skipping accounts that are already stored with the same key).
+func importRemoteSignerAccounts(
+	ctx context.Context,
+	keyStorage *modules.KeyStorage,
+	accounts []*configuration.RemoteSignerAccount,
+) error {
+	for _, account := range accounts {
+		existing, err := keyStorage.Get(ctx, account.AccountIdentifier)
+		if err == nil {
+			if types.Hash(existing.PublicKey) != types.Hash(account.PublicKey) {
+				return fmt.Errorf(
+					"%w: %s already stored for a different public key",
+					results.ErrAddressCollision,
+					types.AccountString(account.AccountIdentifier),
+				)
+			}
+
+			continue
+		}
+		if !errors.Is(err, storageErrs.ErrAddrNotFound) {
+			return err
+		}
+
+		if err := keyStorage.Store(
+			ctx,
+			account.AccountIdentifier,
+			&keys.KeyPair{PublicKey: account.PublicKey},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sampleReplayTransactions samples the transactions to replay
+// from the check:data database at config.Construction.Replay.
+func sampleReplayTransactions(
+	ctx context.Context,
+	config *configuration.Configuration,
+) ([]*processor.ReplaySample, error) {
+	opts := []database.BadgerOption{}
+	if config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	replay := config.Construction.Replay
+	lock, err := AcquireDataDirectoryLock(replay.DatabasePath, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to lock check:data database", err)
+	}
+	defer lock.Release()
+
+	localStore, err := database.NewBadgerDatabase(ctx, replay.DatabasePath, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open check:data database", err)
+	}
+	defer localStore.Close(ctx)
+
+	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
+	return processor.SampleTransactions(
+		ctx,
+		blockStorage,
+		replay.Blocks,
+		replay.OperationTypes,
+	)
 }
 
 // StartPeriodicLogger prints out periodic
@@ -323,11 +819,15 @@ func (t *ConstructionTester) StartPeriodicLogger(
 				t.jobStorage,
 			)
 			t.logger.LogConstructionStatus(ctx, status)
+
+			if err := t.thresholdMonitor.Check(ctx, t.counterStorage); err != nil {
+				return err
+			}
 		}
 	}
 }
 
-func (t *ConstructionTester) checkTip(ctx context.Context) (int64, error) {
+func (t *ConstructionTester) checkTip(ctx context.Context) (bool, int64, error) {
 	atTip, blockIdentifier, err := utils.CheckNetworkTip(
 		ctx,
 		t.network,
@@ -335,39 +835,31 @@ func (t *ConstructionTester) checkTip(ctx context.Context) (int64, error) {
 		t.onlineFetcher,
 	)
 	if err != nil {
-		return -1, err
-	}
-
-	if atTip {
-		return blockIdentifier.Index, nil
+		return false, -1, err
 	}
 
-	return -1, nil
+	return atTip, blockIdentifier.Index, nil
 }
 
 // waitForTip loops until the Rosetta implementation is at tip.
 func (t *ConstructionTester) waitForTip(ctx context.Context) (int64, error) {
-	tc := time.NewTicker(tipWaitInterval)
-	defer tc.Stop()
-
+	p := newPoller(t.config.Polling)
 	for {
 		// Don't wait any time before first tick if at tip.
-		tipIndex, err := t.checkTip(ctx)
+		atTip, blockIndex, err := t.checkTip(ctx)
 		if err != nil {
 			return -1, err
 		}
 
-		if tipIndex != -1 {
-			return tipIndex, nil
+		if atTip {
+			return blockIndex, nil
 		}
 
 		log.Println("waiting for implementation to reach tip before testing...")
 
-		select {
-		case <-ctx.Done():
-			return -1, ctx.Err()
-		case <-tc.C:
-			continue
+		p.Observe(blockIndex)
+		if err := p.Wait(ctx); err != nil {
+			return -1, err
 		}
 	}
 }
@@ -410,11 +902,36 @@ func (t *ConstructionTester) StartConstructor(
 		log.Printf("cleared %d broadcasts\n", len(broadcasts))
 	}
 
+	if loadTest := t.config.Construction.LoadTest; loadTest != nil {
+		results.StartLoadTest(loadTest.TargetTPS)
+	}
+
 	return t.coordinator.Process(ctx)
 }
 
-// ServeHTTP serves a CheckDataStatus response on all paths.
+const (
+	// pausePath is the path of the status server
+	// used to pause transaction creation.
+	pausePath = "/pause"
+
+	// resumePath is the path of the status server
+	// used to resume transaction creation.
+	resumePath = "/resume"
+)
+
+// ServeHTTP serves a CheckConstructionStatus response on all paths.
+// A POST to /pause or /resume pauses or resumes transaction creation
+// before the status is returned.
 func (t *ConstructionTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		switch r.URL.Path {
+		case pausePath:
+			t.Pause()
+		case resumePath:
+			t.Resume()
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
 
@@ -425,12 +942,41 @@ func (t *ConstructionTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		t.broadcastStorage,
 		t.jobStorage,
 	)
+	status.Paused = t.helper.Paused()
 
 	if err := json.NewEncoder(w).Encode(status); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+// Pause stops the creation of new transactions. Pending
+// broadcasts continue to be tracked so that activity can be
+// drained (ex: before node maintenance) without abandoning
+// the state of the run.
+func (t *ConstructionTester) Pause() {
+	if t.helper.Pause() {
+		color.Yellow("Transaction creation paused (pending broadcasts are still tracked)")
+	}
+}
+
+// Resume restarts the creation of new transactions.
+func (t *ConstructionTester) Resume() {
+	if t.helper.Resume() {
+		color.Yellow("Transaction creation resumed")
+	}
+}
+
+// TogglePause pauses transaction creation if it is
+// running and resumes it if it is paused.
+func (t *ConstructionTester) TogglePause() {
+	if t.helper.Paused() {
+		t.Resume()
+		return
+	}
+
+	t.Pause()
+}
+
 // PerformBroadcasts attempts to rebroadcast all pending transactions
 // if the RebroadcastAll configuration is set to true.
 func (t *ConstructionTester) PerformBroadcasts(ctx context.Context) error {
@@ -447,43 +993,98 @@ func (t *ConstructionTester) PerformBroadcasts(ctx context.Context) error {
 	return nil
 }
 
+// endConditionOccurences returns the number of times an end
+// condition has occurred during the current run.
+func (t *ConstructionTester) endConditionOccurences(
+	ctx context.Context,
+	condition string,
+) (int, error) {
+	if condition == configuration.TransactionsConfirmedEndCondition {
+		confirmed, err := t.counterStorage.Get(ctx, modules.TransactionsConfirmedCounter)
+		if err != nil {
+			return -1, fmt.Errorf("%w: unable to fetch %s", err, condition)
+		}
+
+		return int(confirmed.Int64() - t.progress.ConfirmedBaseline), nil
+	}
+
+	completed, err := t.jobStorage.Completed(ctx, condition)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to fetch completed %s", err, condition)
+	}
+
+	return len(completed) - t.progress.CompletedBaseline[condition], nil
+}
+
 // WatchEndConditions cancels check:construction once
 // all end conditions are met (provided workflows
-// are executed at least minOccurences).
+// are executed and transactions are confirmed at
+// least minOccurences) or once
+// EndDuration has elapsed.
 func (t *ConstructionTester) WatchEndConditions(
 	ctx context.Context,
 ) error {
 	endConditions := t.config.Construction.EndConditions
-	if endConditions == nil {
+	loadTest := t.config.Construction.LoadTest
+	endDuration := t.config.Construction.EndDuration
+	if endConditions == nil && loadTest == nil && endDuration == 0 {
 		return nil
 	}
 
-	tc := time.NewTicker(endConditionsCheckInterval)
-	defer tc.Stop()
+	var deadline time.Time
+	if loadTest != nil {
+		deadline = time.Now().Add(time.Duration(loadTest.Duration) * time.Second)
+	}
 
+	var endDeadline time.Time
+	if endDuration > 0 {
+		endDeadline = time.Now().Add(time.Duration(endDuration) * time.Second)
+	}
+
+	p := newPoller(t.config.Polling)
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-tc.C:
-			conditionsMet := true
-			for workflow, minOccurences := range endConditions {
-				completed, err := t.jobStorage.Completed(ctx, workflow)
-				if err != nil {
-					return fmt.Errorf("%w: unable to fetch completed %s", err, workflow)
-				}
-
-				if len(completed) < minOccurences {
-					conditionsMet = false
-					break
-				}
+		if err := p.Wait(ctx); err != nil {
+			return err
+		}
+
+		// Only back off polling while no transactions are confirmed.
+		confirmed, err := t.counterStorage.Get(ctx, modules.TransactionsConfirmedCounter)
+		if err != nil {
+			return fmt.Errorf("%w: unable to fetch confirmed transactions", err)
+		}
+		p.Observe(confirmed.Int64())
+
+		if (loadTest != nil && time.Now().After(deadline)) ||
+			(endDuration > 0 && time.Now().After(endDeadline)) {
+			if loadTest != nil {
+				results.StopLoadTest()
 			}
+			t.reachedEndConditions = true
+			t.cancel()
+			return nil
+		}
+
+		if endConditions == nil {
+			continue
+		}
 
-			if conditionsMet {
-				t.reachedEndConditions = true
-				t.cancel()
-				return nil
+		conditionsMet := true
+		for condition, minOccurences := range endConditions {
+			occurences, err := t.endConditionOccurences(ctx, condition)
+			if err != nil {
+				return err
 			}
+
+			if occurences < minOccurences {
+				conditionsMet = false
+				break
+			}
+		}
+
+		if conditionsMet {
+			t.reachedEndConditions = true
+			t.cancel()
+			return nil
 		}
 	}
 }
@@ -493,11 +1094,11 @@ func (t *ConstructionTester) returnFunds(
 	sigListeners *[]context.CancelFunc,
 ) {
 	// To cancel all execution, need to call multiple cancel functions.
-	ctx, cancel := context.WithCancel(ctx)
+	returnCtx, cancel := context.WithCancel(ctx)
 	*sigListeners = append(*sigListeners, cancel)
 
 	var returnFundsSuccess bool
-	g, ctx := errgroup.WithContext(ctx)
+	g, ctx := errgroup.WithContext(returnCtx)
 	g.Go(func() error {
 		return t.StartSyncer(ctx, cancel)
 	})
@@ -519,14 +1120,19 @@ func (t *ConstructionTester) returnFunds(
 	})
 
 	err := g.Wait()
-	if *t.signalReceived {
-		color.Red("Fund return halted")
+	if returnFundsSuccess {
 		return
 	}
 
-	if !returnFundsSuccess {
-		log.Printf("unable to return funds %v\n", err)
+	// If returnFunds was invoked after an interrupt, the
+	// signal has already been received so we can only
+	// determine if we were halted by checking returnCtx.
+	if *t.signalReceived && returnCtx.Err() != nil {
+		color.Red("Fund return halted")
+		return
 	}
+
+	log.Printf("unable to return funds %v\n", err)
 }
 
 // HandleErr is called when `check:construction` returns an error.
@@ -535,11 +1141,22 @@ func (t *ConstructionTester) HandleErr(
 	sigListeners *[]context.CancelFunc,
 ) error {
 	if *t.signalReceived {
+		// When requested, we sweep funds from all generated
+		// addresses before exiting so they aren't stranded. Sending
+		// another signal halts the sweep.
+		if t.returnFundsOnExit {
+			color.Cyan("Returning funds before exit (send another signal to halt)")
+			t.returnFunds(
+				context.Background(),
+				sigListeners,
+			)
+		}
+
 		return results.ExitConstruction(
 			t.config,
 			t.counterStorage,
 			t.jobStorage,
-			errors.New("check halted"),
+			results.ErrCheckHalted,
 		)
 	}
 
@@ -547,6 +1164,11 @@ func (t *ConstructionTester) HandleErr(
 		return results.ExitConstruction(t.config, t.counterStorage, t.jobStorage, err)
 	}
 
+	t.progress.Finished = true
+	if err := t.progress.persist(t.dataPath); err != nil {
+		log.Printf("%s: unable to mark run finished\n", err.Error())
+	}
+
 	// We optimistically run the ReturnFunds function on the coordinator
 	// and only log if it fails. If there is no ReturnFunds workflow defined,
 	// this will just return nil.
@@ -0,0 +1,258 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/constructor/job"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+)
+
+const (
+	// progressFile is the name of the file in the
+	// check:construction data directory that the
+	// progress of a run is persisted to.
+	progressFile = "progress.json"
+)
+
+// ConstructionProgress is the progress of a check:construction run.
+// All in-flight state (jobs, broadcasts, and counters) is stored
+// in the check:construction database, so this only records which
+// run that state belongs to and where the run started.
+type ConstructionProgress struct {
+	// RunID is the ID of the invocation that started the run
+	// (resuming a run does not change it).
+	RunID string `json:"run_id"`
+
+	// ConfigFingerprint is the fingerprint of the configuration
+	// the run was started with. A run can only be resumed with
+	// the same configuration.
+	ConfigFingerprint string `json:"config_fingerprint"`
+
+	// CompletedBaseline is the number of jobs of each workflow
+	// completed before the run was started with --fresh. End
+	// conditions only count jobs completed after it.
+	CompletedBaseline map[string]int `json:"completed_baseline"`
+
+	// ConfirmedBaseline is the number of transactions confirmed
+	// before the run was started with --fresh.
+	ConfirmedBaseline int64 `json:"confirmed_baseline"`
+
+	// Resumes is the number of times the run has been resumed.
+	Resumes int `json:"resumes"`
+
+	// Finished indicates that the run reached its end
+	// conditions (so it can't be resumed).
+	Finished bool `json:"finished"`
+}
+
+// LoadConstructionProgress loads the *ConstructionProgress persisted
+// in a check:construction data directory. If no progress has been
+// persisted, nil is returned.
+func LoadConstructionProgress(dataPath string) (*ConstructionProgress, error) {
+	progressPath := path.Join(dataPath, progressFile)
+	if _, err := os.Stat(progressPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var progress ConstructionProgress
+	if err := utils.LoadAndParse(progressPath, &progress); err != nil {
+		return nil, fmt.Errorf("%w: unable to load progress", err)
+	}
+
+	return &progress, nil
+}
+
+// persist writes progress to a check:construction data directory.
+func (p *ConstructionProgress) persist(dataPath string) error {
+	if err := utils.SerializeAndWrite(path.Join(dataPath, progressFile), p); err != nil {
+		return fmt.Errorf("%w: unable to persist progress", err)
+	}
+
+	return nil
+}
+
+// prepareProgress returns the *ConstructionProgress of the run
+// being started.
+//
+// If resume is true, the progress persisted by a previous
+// (unfinished) run is continued (which requires the same
+// configuration).
+//
+// If fresh is true, a new run is started from scratch: the jobs
+// and broadcasts left in-flight by previous runs are discarded
+// (unlocking their accounts) and jobs completed by previous runs are
+// not counted towards end conditions (nor are transactions they
+// confirmed). Keys and synced balances are always kept so that
+// funds held by previous runs can be spent.
+//
+// Otherwise, a new run is started on top of the state in the data
+// directory: in-flight jobs and broadcasts are kept (unless
+// clear_broadcasts is populated) and the end condition baselines of
+// the previous run (if any) are carried over.
+func prepareProgress(
+	ctx context.Context,
+	dataPath string,
+	config *configuration.Configuration,
+	db database.Database,
+	jobStorage *modules.JobStorage,
+	broadcastStorage *modules.BroadcastStorage,
+	counterStorage *modules.CounterStorage,
+	resume bool,
+	fresh bool,
+) (*ConstructionProgress, error) {
+	runID := ""
+	fingerprint := ""
+	if run := results.CurrentRunMetadata(); run != nil {
+		runID = run.RunID
+		fingerprint = run.ConfigFingerprint
+	}
+
+	previous, err := LoadConstructionProgress(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if resume {
+		if previous == nil {
+			return nil, fmt.Errorf("%w: no progress found in %s", results.ErrCannotResume, dataPath)
+		}
+
+		if previous.Finished {
+			return nil, fmt.Errorf(
+				"%w: run %s already finished",
+				results.ErrCannotResume,
+				previous.RunID,
+			)
+		}
+
+		if previous.ConfigFingerprint != fingerprint {
+			return nil, fmt.Errorf(
+				"%w: configuration has changed since run %s started",
+				results.ErrCannotResume,
+				previous.RunID,
+			)
+		}
+
+		previous.Resumes++
+		if err := previous.persist(dataPath); err != nil {
+			return nil, err
+		}
+
+		log.Printf("resuming run %s (resumed %d times)\n", previous.RunID, previous.Resumes)
+		return previous, nil
+	}
+
+	if !fresh {
+		progress := &ConstructionProgress{
+			RunID:             runID,
+			ConfigFingerprint: fingerprint,
+			CompletedBaseline: map[string]int{},
+		}
+		if previous != nil {
+			progress.CompletedBaseline = previous.CompletedBaseline
+			progress.ConfirmedBaseline = previous.ConfirmedBaseline
+		}
+
+		if err := progress.persist(dataPath); err != nil {
+			return nil, err
+		}
+
+		return progress, nil
+	}
+
+	if err := discardInFlight(ctx, db, jobStorage, broadcastStorage); err != nil {
+		return nil, err
+	}
+
+	baseline := map[string]int{}
+	for _, workflow := range config.Construction.Workflows {
+		completed, err := jobStorage.Completed(ctx, workflow.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to fetch completed %s", err, workflow.Name)
+		}
+
+		baseline[workflow.Name] = len(completed)
+	}
+
+	confirmed, err := counterStorage.Get(ctx, modules.TransactionsConfirmedCounter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch confirmed transactions", err)
+	}
+
+	progress := &ConstructionProgress{
+		RunID:             runID,
+		ConfigFingerprint: fingerprint,
+		CompletedBaseline: baseline,
+		ConfirmedBaseline: confirmed.Int64(),
+	}
+	if err := progress.persist(dataPath); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// discardInFlight clears all pending broadcasts (failing
+// their jobs) and marks all other processing jobs as failed.
+// broadcastStorage must be initialized.
+func discardInFlight(
+	ctx context.Context,
+	db database.Database,
+	jobStorage *modules.JobStorage,
+	broadcastStorage *modules.BroadcastStorage,
+) error {
+	broadcasts, err := broadcastStorage.ClearBroadcasts(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to clear broadcasts", err)
+	}
+
+	processing, err := jobStorage.AllProcessing(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to fetch processing jobs", err)
+	}
+
+	dbTx := db.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	for _, j := range processing {
+		j.Status = job.Failed
+		if _, err := jobStorage.Update(ctx, dbTx, j); err != nil {
+			return fmt.Errorf("%w: unable to fail job %s", err, j.Identifier)
+		}
+	}
+
+	if err := dbTx.Commit(ctx); err != nil {
+		return fmt.Errorf("%w: unable to commit failed jobs", err)
+	}
+
+	log.Printf(
+		"discarded %d broadcasts and %d jobs left in-flight by a previous run\n",
+		len(broadcasts),
+		len(processing),
+	)
+
+	return nil
+}
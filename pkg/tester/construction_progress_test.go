@@ -0,0 +1,200 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/constructor/job"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func addJob(
+	ctx context.Context,
+	t *testing.T,
+	db database.Database,
+	jobStorage *modules.JobStorage,
+	status job.Status,
+) string {
+	dbTx := db.Transaction(ctx)
+	defer dbTx.Discard(ctx)
+
+	identifier, err := jobStorage.Update(ctx, dbTx, &job.Job{
+		Workflow: "transfer",
+		Status:   status,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbTx.Commit(ctx))
+
+	return identifier
+}
+
+func TestPrepareProgress(t *testing.T) {
+	ctx := context.Background()
+	defer results.SetRunMetadata(nil)
+
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	jobStorage := modules.NewJobStorage(db)
+	broadcastStorage := modules.NewBroadcastStorage(db, 10, 10, 1, false, 10)
+	counterStorage := modules.NewCounterStorage(db)
+	config := &configuration.Configuration{
+		Construction: &configuration.ConstructionConfiguration{
+			Workflows: []*job.Workflow{{Name: "transfer"}},
+		},
+	}
+	prepare := func(resume bool, fresh bool) (*ConstructionProgress, error) {
+		return prepareProgress(
+			ctx,
+			dir,
+			config,
+			db,
+			jobStorage,
+			broadcastStorage,
+			counterStorage,
+			resume,
+			fresh,
+		)
+	}
+	processing := func() int {
+		jobs, err := jobStorage.AllProcessing(ctx)
+		assert.NoError(t, err)
+
+		return len(jobs)
+	}
+
+	// Nothing to resume
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 1", ConfigFingerprint: "config"})
+	progress, err := prepare(true, false)
+	assert.True(t, errors.Is(err, results.ErrCannotResume))
+	assert.Nil(t, progress)
+
+	// Restart with a job completed and a job in-flight
+	// (in-flight state is kept and all completed jobs count)
+	addJob(ctx, t, db, jobStorage, job.Completed)
+	inFlight := addJob(ctx, t, db, jobStorage, job.Ready)
+	_, err = counterStorage.Update(ctx, modules.TransactionsConfirmedCounter, big.NewInt(2))
+	assert.NoError(t, err)
+	progress, err = prepare(false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &ConstructionProgress{
+		RunID:             "run 1",
+		ConfigFingerprint: "config",
+		CompletedBaseline: map[string]int{},
+	}, progress)
+	assert.Equal(t, 1, processing())
+
+	// Resume the run
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 2", ConfigFingerprint: "config"})
+	progress, err = prepare(true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "run 1", progress.RunID)
+	assert.Equal(t, 1, progress.Resumes)
+	assert.Equal(t, 1, processing())
+
+	// Resume with a different configuration
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 3", ConfigFingerprint: "other"})
+	_, err = prepare(true, false)
+	assert.True(t, errors.Is(err, results.ErrCannotResume))
+
+	// Start from scratch, discarding the in-flight job
+	progress, err = prepare(false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &ConstructionProgress{
+		RunID:             "run 3",
+		ConfigFingerprint: "other",
+		CompletedBaseline: map[string]int{"transfer": 1},
+		ConfirmedBaseline: 2,
+	}, progress)
+	assert.Equal(t, 0, processing())
+
+	dbTx := db.ReadTransaction(ctx)
+	discarded, err := jobStorage.Get(ctx, dbTx, inFlight)
+	dbTx.Discard(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, job.Failed, discarded.Status)
+
+	// Restarting keeps the baselines of the fresh run
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 4", ConfigFingerprint: "other"})
+	progress, err = prepare(false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "run 4", progress.RunID)
+	assert.Equal(t, map[string]int{"transfer": 1}, progress.CompletedBaseline)
+	assert.Equal(t, int64(2), progress.ConfirmedBaseline)
+
+	// A finished run can't be resumed
+	progress.Finished = true
+	assert.NoError(t, progress.persist(dir))
+	_, err = prepare(true, false)
+	assert.True(t, errors.Is(err, results.ErrCannotResume))
+
+	loaded, err := LoadConstructionProgress(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, progress, loaded)
+}
+
+func TestEndConditionOccurences(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	db, err := database.NewBadgerDatabase(ctx, dir)
+	assert.NoError(t, err)
+	defer db.Close(ctx)
+
+	jobStorage := modules.NewJobStorage(db)
+	counterStorage := modules.NewCounterStorage(db)
+	tester := &ConstructionTester{
+		jobStorage:     jobStorage,
+		counterStorage: counterStorage,
+		progress: &ConstructionProgress{
+			CompletedBaseline: map[string]int{"transfer": 1},
+			ConfirmedBaseline: 2,
+		},
+	}
+
+	addJob(ctx, t, db, jobStorage, job.Completed)
+	addJob(ctx, t, db, jobStorage, job.Completed)
+	_, err = counterStorage.Update(ctx, modules.TransactionsConfirmedCounter, big.NewInt(5))
+	assert.NoError(t, err)
+
+	transfers, err := tester.endConditionOccurences(ctx, "transfer")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, transfers)
+
+	confirmed, err := tester.endConditionOccurences(
+		ctx,
+		configuration.TransactionsConfirmedEndCondition,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, confirmed)
+}
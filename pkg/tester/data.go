@@ -22,6 +22,7 @@ import (
 	"log"
 	"math/big"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/coinbase/rosetta-cli/configuration"
@@ -63,8 +64,9 @@ const (
 	PeriodicLoggingFrequency = periodicLoggingSeconds * time.Second
 
 	// EndAtTipCheckInterval is the frequency that EndAtTip condition
-	// is evaludated
-	EndAtTipCheckInterval = 10 * time.Second
+	// is evaludated (and any other condition is polled) when polling
+	// is not configured.
+	EndAtTipCheckInterval = configuration.DefaultPollingIntervalMS * time.Millisecond
 )
 
 var _ http.Handler = (*DataTester)(nil)
@@ -74,11 +76,14 @@ var _ statefulsyncer.PruneHelper = (*DataTester)(nil)
 type DataTester struct {
 	network                     *types.NetworkIdentifier
 	database                    database.Database
+	dataPath                    string
+	lock                        *DataDirectoryLock
 	config                      *configuration.Configuration
 	syncer                      *statefulsyncer.StatefulSyncer
 	reconciler                  *reconciler.Reconciler
 	logger                      *logger.Logger
 	balanceStorage              *modules.BalanceStorage
+	balanceRules                *processor.BalanceRules
 	blockStorage                *modules.BlockStorage
 	counterStorage              *modules.CounterStorage
 	reconcilerHandler           *processor.ReconcilerHandler
@@ -89,6 +94,14 @@ type DataTester struct {
 	historicalBalanceEnabled    bool
 	parser                      *parser.Parser
 	forceInactiveReconciliation *bool
+	thresholdMonitor            *results.ThresholdMonitor
+	historicalReconciler        *processor.HistoricalReconciler
+
+	// syncProgress is the progress of the run (checkpointed
+	// periodically) and resumed indicates that syncing should
+	// continue from the last processed block.
+	syncProgress *SyncProgress
+	resumed      bool
 
 	endCondition       configuration.CheckDataEndCondition
 	endConditionDetail string
@@ -128,11 +141,45 @@ func loadAccounts(filePath string) ([]*types.AccountCurrency, error) {
 	return accounts, nil
 }
 
+// loadReconciliationSkips loads the reconciliation skips at
+// filePath (if populated), logs the active and expired skips,
+// and returns the active skips.
+func loadReconciliationSkips(filePath string) ([]*results.ReconciliationSkip, error) {
+	if len(filePath) == 0 {
+		return nil, nil
+	}
+
+	skips, err := processor.LoadReconciliationSkips(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	active, expired := processor.PartitionReconciliationSkips(skips, time.Now())
+	for _, skip := range active {
+		color.Yellow(
+			"Skipping reconciliation failures for %s %s until %s: %s",
+			types.AccountString(skip.Account),
+			skip.Currency.Symbol,
+			skip.Expires.Format(time.RFC3339),
+			skip.Reason,
+		)
+	}
+
+	if len(expired) > 0 {
+		color.Yellow("Ignoring %d expired reconciliation skips at %s", len(expired), filePath)
+	}
+
+	results.SetActiveReconciliationSkips(active)
+	return active, nil
+}
+
 // CloseDatabase closes the database used by DataTester.
 func (t *DataTester) CloseDatabase(ctx context.Context) {
 	if err := t.database.Close(ctx); err != nil {
 		log.Fatalf("%s: error closing database", err.Error())
 	}
+	databaseClosed()
+	t.lock.Release()
 }
 
 // InitializeData returns a new *DataTester.
@@ -145,12 +192,18 @@ func InitializeData(
 	genesisBlock *types.BlockIdentifier,
 	interestingAccount *types.AccountCurrency,
 	signalReceived *bool,
+	forceTakeover bool,
 ) *DataTester {
 	dataPath, err := utils.CreateCommandPath(config.DataDirectory, dataCmdName, network)
 	if err != nil {
 		log.Fatalf("%s: cannot create command path", err.Error())
 	}
 
+	lock, err := AcquireDataDirectoryLock(dataPath, forceTakeover)
+	if err != nil {
+		log.Fatalf("%s: unable to lock data directory", err.Error())
+	}
+
 	opts := []database.BadgerOption{}
 	if config.CompressionDisabled {
 		opts = append(opts, database.WithoutCompression())
@@ -166,6 +219,7 @@ func InitializeData(
 	if err != nil {
 		log.Fatalf("%s: unable to initialize database", err.Error())
 	}
+	databaseOpened()
 
 	exemptAccounts, err := loadAccounts(config.Data.ExemptAccounts)
 	if err != nil {
@@ -177,6 +231,11 @@ func InitializeData(
 		log.Fatalf("%s: unable to load interesting accounts", err.Error())
 	}
 
+	balanceRules, err := processor.NewBalanceRules(config.Data.BalanceRules)
+	if err != nil {
+		log.Fatalf("%s: unable to compile balance rules", err.Error())
+	}
+
 	counterStorage := modules.NewCounterStorage(localStore)
 	blockStorage := modules.NewBlockStorage(localStore, config.SerialBlockWorkers)
 	balanceStorage := modules.NewBalanceStorage(localStore)
@@ -189,6 +248,11 @@ func InitializeData(
 		config.Data.LogReconciliations,
 	)
 
+	reconciliationSkips, err := loadReconciliationSkips(config.Data.ReconciliationSkipsFile)
+	if err != nil {
+		log.Fatalf("%s: unable to load reconciliation skips", err.Error())
+	}
+
 	var forceInactiveReconciliation bool
 	reconcilerHelper := processor.NewReconcilerHelper(
 		config,
@@ -205,6 +269,10 @@ func InitializeData(
 		counterStorage,
 		balanceStorage,
 		!config.Data.IgnoreReconciliationError,
+		config.Data.EscalationThreshold,
+		config.Data.ActiveReconciliationRateLimit,
+		config.Data.InactiveReconciliationRateLimit,
+		reconciliationSkips,
 	)
 
 	// Get all previously seen accounts
@@ -222,6 +290,15 @@ func InitializeData(
 		log.Fatal("found balance exemptions but initial balance fetch disabled")
 	}
 
+	// When syncing a bounded range, any previously seen account
+	// could have balance changes from outside of the range.
+	if config.Data.PartialSync != nil && len(seenAccounts) > 0 {
+		log.Fatalf(
+			"found %d accounts seen outside of the synced range: partial sync requires an empty data directory",
+			len(seenAccounts),
+		)
+	}
+
 	parser := parser.New(
 		fetcher.Asserter,
 		nil,
@@ -236,6 +313,29 @@ func InitializeData(
 		historicalBalanceEnabled = networkOptions.Allow.HistoricalBalanceLookup
 	}
 
+	if config.Data.PartialSync != nil && !historicalBalanceEnabled {
+		log.Fatal("partial sync requires historical balance lookup")
+	}
+
+	var historicalReconciler *processor.HistoricalReconciler
+	if historicalConfig := config.Data.HistoricalReconciliation; historicalConfig != nil {
+		if !historicalBalanceEnabled {
+			log.Fatal("historical reconciliation requires historical balance lookup")
+		}
+
+		historicalReconciler = processor.NewHistoricalReconciler(
+			network,
+			fetcher,
+			blockStorage,
+			balanceStorage,
+			counterStorage,
+			parser,
+			exemptAccounts,
+			time.Duration(historicalConfig.Interval)*time.Second,
+			historicalConfig.MinDepth,
+		)
+	}
+
 	rOpts := []reconciler.Option{
 		reconciler.WithActiveConcurrency(int(config.Data.ActiveReconciliationConcurrency)),
 		reconciler.WithInactiveConcurrency(int(config.Data.InactiveReconciliationConcurrency)),
@@ -261,7 +361,39 @@ func InitializeData(
 		rOpts...,
 	)
 
-	blockWorkers := []modules.BlockWorker{counterStorage}
+	blockWorkers := []modules.BlockWorker{
+		counterStorage,
+		processor.NewBlockStatsWorker(),
+		processor.NewOperationImpactWorker(fetcher.Asserter),
+	}
+
+	// The ordering worker runs before any storage worker so that
+	// ordering violations are reported with full context instead of
+	// surfacing as opaque storage errors.
+	if config.Data.ValidateOperationOrdering {
+		blockWorkers = append(blockWorkers, processor.NewOperationOrderingWorker())
+	}
+	if config.Data.Strictness != configuration.LenientStrictness {
+		blockWorkers = append(
+			blockWorkers,
+			processor.NewStrictnessWorker(
+				config.Data.Strictness,
+				config.Data.EscalationThreshold,
+				counterStorage,
+			),
+		)
+	}
+	if len(config.Data.CustomCounters) > 0 {
+		customCounterWorker, err := processor.NewCustomCounterWorker(
+			counterStorage,
+			config.Data.CustomCounters,
+		)
+		if err != nil {
+			log.Fatalf("%s: unable to initialize custom counters", err.Error())
+		}
+
+		blockWorkers = append(blockWorkers, customCounterWorker)
+	}
 	if !config.Data.BalanceTrackingDisabled {
 		balanceStorageHelper := processor.NewBalanceStorageHelper(
 			network,
@@ -282,9 +414,25 @@ func InitializeData(
 			interestingAccount,
 		)
 
+		if config.Data.PartialSync != nil &&
+			config.Data.PartialSync.BaselineAtStart &&
+			*config.Data.StartIndex > 0 {
+			baselineIndex := *config.Data.StartIndex - 1
+			baselineBlock, fetchErr := fetcher.BlockRetry(
+				ctx,
+				network,
+				&types.PartialBlockIdentifier{Index: &baselineIndex},
+			)
+			if fetchErr != nil {
+				log.Fatalf("%s: unable to get baseline block", fetchErr.Err.Error())
+			}
+
+			balanceStorageHelper.SetBaselineBlock(baselineBlock.BlockIdentifier)
+		}
+
 		balanceStorage.Initialize(balanceStorageHelper, balanceStorageHandler)
 
-		blockWorkers = append(blockWorkers, balanceStorage)
+		blockWorkers = append(blockWorkers, balanceWorker(balanceStorage, balanceRules))
 
 		// Bootstrap balances, if provided. We need to do before initializing
 		// the reconciler otherwise we won't reconcile bootstrapped accounts
@@ -319,6 +467,23 @@ func InitializeData(
 		blockWorkers = append(blockWorkers, coinStorage)
 	}
 
+	// The lag worker must come after balanceStorage so that
+	// reconciliations for a block are queued before we wait on them.
+	if config.Data.ReconciliationMaxHeadLag != nil && shouldReconcile(config) {
+		blockWorkers = append(blockWorkers, processor.NewReconciliationLagWorker(
+			r,
+			*config.Data.ReconciliationMaxHeadLag,
+		))
+	}
+
+	if config.Data.BlockTransactionComparisonFrequency != nil {
+		blockWorkers = append(blockWorkers, processor.NewBlockTransactionWorker(
+			network,
+			fetcher,
+			*config.Data.BlockTransactionComparisonFrequency,
+		))
+	}
+
 	statefulSyncerOptions := []statefulsyncer.Option{
 		statefulsyncer.WithCacheSize(syncer.DefaultCacheSize),
 		statefulsyncer.WithMaxConcurrency(config.MaxSyncConcurrency),
@@ -347,12 +512,15 @@ func InitializeData(
 	return &DataTester{
 		network:                     network,
 		database:                    localStore,
+		dataPath:                    dataPath,
+		lock:                        lock,
 		config:                      config,
 		syncer:                      syncer,
 		cancel:                      cancel,
 		reconciler:                  r,
 		logger:                      logger,
 		balanceStorage:              balanceStorage,
+		balanceRules:                balanceRules,
 		blockStorage:                blockStorage,
 		counterStorage:              counterStorage,
 		reconcilerHandler:           reconcilerHandler,
@@ -362,9 +530,24 @@ func InitializeData(
 		historicalBalanceEnabled:    historicalBalanceEnabled,
 		parser:                      parser,
 		forceInactiveReconciliation: &forceInactiveReconciliation,
+		thresholdMonitor:            results.NewThresholdMonitor(config.CounterThresholds),
+		historicalReconciler:        historicalReconciler,
 	}
 }
 
+// balanceWorker returns the block worker that computes
+// balances with balanceStorage (applying rules, if any).
+func balanceWorker(
+	balanceStorage *modules.BalanceStorage,
+	rules *processor.BalanceRules,
+) modules.BlockWorker {
+	if rules == nil {
+		return balanceStorage
+	}
+
+	return processor.NewBalanceRuleWorker(balanceStorage, rules)
+}
+
 // StartSyncing syncs from startIndex to endIndex.
 // If startIndex is -1, it will start from the last
 // saved block. If endIndex is -1, it will sync
@@ -373,10 +556,19 @@ func (t *DataTester) StartSyncing(
 	ctx context.Context,
 ) error {
 	startIndex := int64(-1)
-	if t.config.Data.StartIndex != nil {
+	if t.config.Data.StartIndex != nil && !t.resumed {
 		startIndex = *t.config.Data.StartIndex
 	}
 
+	if t.config.Data.CheckpointSync != nil {
+		checkpointIndex, err := t.fastForward(ctx)
+		if err != nil {
+			return err
+		}
+
+		startIndex = checkpointIndex
+	}
+
 	endIndex := int64(-1)
 	if t.config.Data.EndConditions != nil && t.config.Data.EndConditions.Index != nil {
 		endIndex = *t.config.Data.EndConditions.Index
@@ -385,6 +577,54 @@ func (t *DataTester) StartSyncing(
 	return t.syncer.Sync(ctx, startIndex, endIndex)
 }
 
+// fastForward fetches every block through the last checkpoint
+// (only checking their structure and that they form a hash chain
+// through every checkpoint) and returns the index of the last
+// checkpoint. If syncing already started, -1 is returned so that
+// syncing resumes from the last saved block.
+func (t *DataTester) fastForward(ctx context.Context) (int64, error) {
+	_, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	switch {
+	case err == nil:
+		log.Println("Skipping checkpoint fast-forward because already started syncing")
+		return -1, nil
+	case !errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+		return -1, fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	checkpoints, err := processor.LoadCheckpoints(t.config.Data.CheckpointSync.File)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to load checkpoints", err)
+	}
+
+	color.Cyan(
+		"fast-forwarding through %d checkpoints from block %d to block %d",
+		len(checkpoints),
+		checkpoints[0].Index,
+		checkpoints[len(checkpoints)-1].Index,
+	)
+
+	checkpointSync, err := processor.FastForwardCheckpoints(
+		ctx,
+		t.fetcher,
+		t.network,
+		checkpoints,
+		t.config.MaxSyncConcurrency,
+	)
+	if err != nil {
+		return -1, fmt.Errorf("%w: unable to fast-forward through checkpoints", err)
+	}
+
+	results.RecordCheckpointSync(checkpointSync)
+	color.Cyan(
+		"fast-forwarded through %d blocks, starting full validation at block %d",
+		checkpointSync.Blocks,
+		checkpointSync.EndIndex,
+	)
+
+	return checkpointSync.EndIndex, nil
+}
+
 // StartPruning attempts to prune block storage
 // every 10 seconds.
 func (t *DataTester) StartPruning(
@@ -405,6 +645,81 @@ func (t *DataTester) StartReconcilerCountUpdater(
 	return t.reconcilerHandler.Updater(ctx)
 }
 
+// StartExplorerComparison periodically cross-checks
+// synced data against an explorer if one is configured.
+func (t *DataTester) StartExplorerComparison(
+	ctx context.Context,
+) error {
+	explorerConfig := t.config.Data.Explorer
+	if explorerConfig == nil {
+		return nil
+	}
+
+	proxy, err := processor.NewProxy(t.config.Proxy)
+	if err != nil {
+		return fmt.Errorf("%w: invalid proxy configuration", err)
+	}
+
+	comparer := processor.NewExplorerComparer(
+		t.network,
+		t.fetcher,
+		t.blockStorage,
+		t.counterStorage,
+		processor.NewEsploraExplorer(
+			explorerConfig.URL,
+			time.Duration(explorerConfig.Timeout)*time.Second,
+			proxy,
+		),
+		explorerConfig.Currency,
+		time.Duration(explorerConfig.Interval)*time.Second,
+	)
+
+	return comparer.Start(ctx)
+}
+
+// StartHistoricalReconciliation periodically reconciles
+// balances at sampled historical blocks if configured.
+func (t *DataTester) StartHistoricalReconciliation(
+	ctx context.Context,
+) error {
+	if t.historicalReconciler == nil {
+		return nil
+	}
+
+	return t.historicalReconciler.Start(ctx)
+}
+
+// StartStateCommitmentVerification periodically verifies
+// balances against the chain's state commitment if configured.
+func (t *DataTester) StartStateCommitmentVerification(
+	ctx context.Context,
+) error {
+	commitmentConfig := t.config.Data.StateCommitment
+	if commitmentConfig == nil {
+		return nil
+	}
+
+	checker := processor.NewStateCommitmentChecker(
+		t.blockStorage,
+		t.balanceStorage,
+		t.counterStorage,
+		processor.NewSparseMerkleVerifier(commitmentConfig.MetadataKey),
+		commitmentConfig.Interval,
+	)
+
+	return checker.Start(ctx)
+}
+
+// StartMetricsServer serves Prometheus metrics
+// at addr if it is populated.
+func (t *DataTester) StartMetricsServer(ctx context.Context, addr string) error {
+	return StartMetricsServer(
+		ctx,
+		addr,
+		results.MetricsHandler(t.counterStorage, t.dataPath),
+	)
+}
+
 // PruneableIndex is the index that is
 // safe for pruning.
 func (t *DataTester) PruneableIndex(
@@ -459,14 +774,55 @@ func (t *DataTester) StartPeriodicLogger(
 				t.fetcher,
 				t.config.Network,
 				t.reconciler,
+				t.config.Data.CustomCounters,
 			)
 			t.logger.LogDataStatus(ctx, status)
+
+			if err := t.thresholdMonitor.Check(ctx, t.counterStorage); err != nil {
+				return err
+			}
 		}
 	}
 }
 
+const (
+	// reconciliationPathPrefix is the prefix of the status server
+	// paths used to pause or resume a type of reconciliation
+	// (ex: /reconciliation/active/pause).
+	reconciliationPathPrefix = "/reconciliation/"
+
+	// pauseAction and resumeAction are the actions
+	// of reconciliation control paths.
+	pauseAction  = "pause"
+	resumeAction = "resume"
+)
+
 // ServeHTTP serves a CheckDataStatus response on all paths.
+// A POST to /reconciliation/<active|inactive>/<pause|resume>
+// pauses or resumes that type of reconciliation before the
+// status is returned.
 func (t *DataTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, reconciliationPathPrefix) {
+		var toggled bool
+		switch strings.TrimPrefix(r.URL.Path, reconciliationPathPrefix) {
+		case reconciler.ActiveReconciliation + "/" + pauseAction:
+			toggled = t.PauseReconciliation(reconciler.ActiveReconciliation)
+		case reconciler.ActiveReconciliation + "/" + resumeAction:
+			toggled = t.ResumeReconciliation(reconciler.ActiveReconciliation)
+		case reconciler.InactiveReconciliation + "/" + pauseAction:
+			toggled = t.PauseReconciliation(reconciler.InactiveReconciliation)
+		case reconciler.InactiveReconciliation + "/" + resumeAction:
+			toggled = t.ResumeReconciliation(reconciler.InactiveReconciliation)
+		default:
+			http.Error(w, fmt.Sprintf("unknown path %s", r.URL.Path), http.StatusNotFound)
+			return
+		}
+
+		if !toggled {
+			log.Printf("%s is a no-op\n", r.URL.Path)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
 
@@ -478,13 +834,45 @@ func (t *DataTester) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		t.fetcher,
 		t.network,
 		t.reconciler,
+		t.config.Data.CustomCounters,
 	)
+	status.Reconciliation = &results.ReconciliationStatus{
+		ActivePaused:   t.reconcilerHandler.Paused(reconciler.ActiveReconciliation),
+		InactivePaused: t.reconcilerHandler.Paused(reconciler.InactiveReconciliation),
+	}
 
 	if err := json.NewEncoder(w).Encode(status); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+// PauseReconciliation pauses reconciliationType reconciliation
+// (without pausing syncing) so that load on the node can be
+// relieved during an incident. PauseReconciliation returns false
+// if reconciliationType was already paused.
+func (t *DataTester) PauseReconciliation(reconciliationType string) bool {
+	if !t.reconcilerHandler.Pause(reconciliationType) {
+		return false
+	}
+
+	color.Yellow("%s reconciliation paused (syncing continues)", reconciliationType)
+	results.RecordTimelineEvent(results.ReconciliationPausedEvent, reconciliationType)
+	return true
+}
+
+// ResumeReconciliation resumes reconciliationType reconciliation.
+// ResumeReconciliation returns false if reconciliationType was
+// not paused.
+func (t *DataTester) ResumeReconciliation(reconciliationType string) bool {
+	if !t.reconcilerHandler.Resume(reconciliationType) {
+		return false
+	}
+
+	color.Yellow("%s reconciliation resumed", reconciliationType)
+	results.RecordTimelineEvent(results.ReconciliationResumedEvent, reconciliationType)
+	return true
+}
+
 // syncedStatus returns a boolean indicating if we are synced to tip and
 // the last synced block.
 func (t *DataTester) syncedStatus(ctx context.Context) (bool, int64, error) {
@@ -512,15 +900,14 @@ func (t *DataTester) syncedStatus(ctx context.Context) (bool, int64, error) {
 func (t *DataTester) EndAtTipLoop(
 	ctx context.Context,
 ) {
-	tc := time.NewTicker(EndAtTipCheckInterval)
-	defer tc.Stop()
+	p := newPoller(t.config.Polling)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 
-		case <-tc.C:
+		case <-p.After():
 			atTip, blockIndex, err := t.syncedStatus(ctx)
 			if err != nil {
 				log.Printf(
@@ -529,6 +916,7 @@ func (t *DataTester) EndAtTipLoop(
 				)
 				continue
 			}
+			p.Observe(blockIndex)
 
 			if atTip {
 				t.endCondition = configuration.TipEndCondition
@@ -548,8 +936,7 @@ func (t *DataTester) EndReconciliationCoverage( // nolint:gocognit
 	ctx context.Context,
 	reconciliationCoverage *configuration.ReconciliationCoverage,
 ) {
-	tc := time.NewTicker(EndAtTipCheckInterval)
-	defer tc.Stop()
+	p := newPoller(t.config.Polling)
 
 	firstTipIndex := int64(-1)
 
@@ -558,7 +945,7 @@ func (t *DataTester) EndReconciliationCoverage( // nolint:gocognit
 		case <-ctx.Done():
 			return
 
-		case <-tc.C:
+		case <-p.After():
 			atTip, blockIndex, err := t.syncedStatus(ctx)
 			if err != nil {
 				log.Printf(
@@ -567,6 +954,7 @@ func (t *DataTester) EndReconciliationCoverage( // nolint:gocognit
 				)
 				continue
 			}
+			p.Observe(blockIndex)
 
 			// Check if we are at tip and set tip height if fromTip is true.
 			if reconciliationCoverage.Tip || reconciliationCoverage.FromTip {
@@ -763,20 +1151,20 @@ func (t *DataTester) WaitForEmptyQueue(
 	}
 	startingRemaining := t.reconciler.QueueSize()
 
-	tc := time.NewTicker(EndAtTipCheckInterval)
-	defer tc.Stop()
+	p := newPoller(t.config.Polling)
 
 	color.Cyan(
 		"[PROGRESS] remaining reconciliations: %d",
 		startingRemaining,
 	)
 
+	p.Observe(0)
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 
-		case <-tc.C:
+		case <-p.After():
 			// We force cached counts to be written before
 			// determining if we should exit.
 			if err := t.reconcilerHandler.UpdateCounts(ctx); err != nil {
@@ -795,6 +1183,9 @@ func (t *DataTester) WaitForEmptyQueue(
 				return nil
 			}
 
+			// Only back off polling while no progress is made.
+			p.Observe(completed)
+
 			color.Cyan(
 				"[PROGRESS] remaining reconciliations: %d",
 				remaining,
@@ -819,6 +1210,10 @@ func (t *DataTester) DrainReconcilerQueue(
 	// Disable inactive lookups
 	t.reconciler.InactiveConcurrency = 0
 
+	// The backlog can't be drained while active
+	// reconciliation is paused.
+	t.ResumeReconciliation(reconciler.ActiveReconciliation)
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		return t.StartReconciler(ctx)
@@ -830,7 +1225,7 @@ func (t *DataTester) DrainReconcilerQueue(
 	err := g.Wait()
 
 	if *t.signalReceived {
-		return errors.New("reconcilier queue drain halted")
+		return fmt.Errorf("%w: reconciler queue drain halted", results.ErrCheckHalted)
 	}
 
 	if errors.Is(err, context.Canceled) {
@@ -854,7 +1249,7 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 			t.config,
 			t.counterStorage,
 			t.balanceStorage,
-			errors.New("check halted"),
+			results.ErrCheckHalted,
 			"",
 			"",
 		)
@@ -906,6 +1301,12 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 	}
 
 	fmt.Printf("\n")
+	if index, account, ok := t.violationBlock(ctx, err); err != nil && ok {
+		if errors.Is(err, syncer.ErrFetchBlockFailed) {
+			t.reportMissingOtherTransactions(ctx, index)
+		}
+		t.traceViolation(ctx, err, index, account)
+	}
 	if t.reconcilerHandler.InactiveFailure == nil {
 		return results.ExitData(
 			t.config,
@@ -946,6 +1347,150 @@ func (t *DataTester) HandleErr(err error, sigListeners *[]context.CancelFunc) er
 	return t.FindMissingOps(ctx, err, sigListeners)
 }
 
+// reportMissingOtherTransactions logs any transactions listed in
+// the other_transactions of the block at index (which could
+// not be fetched).
+func (t *DataTester) reportMissingOtherTransactions(ctx context.Context, index int64) {
+	clientOptions, optionsErr := processor.NewClientOptions(t.config)
+	if optionsErr != nil {
+		color.Yellow(
+			"unable to inspect other_transactions of block %d: %s",
+			index,
+			optionsErr.Error(),
+		)
+		return
+	}
+
+	rosettaClient := processor.NewClient(
+		t.config.OnlineURL,
+		time.Duration(t.config.HTTPTimeout)*time.Second,
+		1,
+		clientOptions,
+	)
+	missing, lookupErr := processor.FindMissingOtherTransactions(
+		ctx,
+		rosettaClient,
+		t.network,
+		index,
+	)
+	if lookupErr != nil {
+		color.Yellow("unable to inspect other_transactions of block %d: %s", index, lookupErr.Error())
+		return
+	}
+
+	if len(missing) == 0 {
+		return
+	}
+
+	color.Red(
+		"block %d lists %d other_transactions that could not be fetched from /block/transaction",
+		index,
+		len(missing),
+	)
+	for _, m := range missing {
+		color.Red("%s: %s", m.TransactionIdentifier.Hash, m.Err.Error())
+	}
+}
+
+// violationBlock returns the index of the block that caused err
+// and, if err was a reconciliation failure, the failed account.
+func (t *DataTester) violationBlock(
+	ctx context.Context,
+	err error,
+) (int64, *types.AccountIdentifier, bool) {
+	if errors.Is(err, syncer.ErrFetchBlockFailed) {
+		index, ok := t.fetchFailureIndex(ctx)
+		return index, nil, ok
+	}
+
+	if t.reconcilerHandler.ActiveFailureBlock != nil {
+		return t.reconcilerHandler.ActiveFailureBlock.Index,
+			t.reconcilerHandler.ActiveFailure.Account,
+			true
+	}
+
+	if t.reconcilerHandler.InactiveFailureBlock != nil {
+		return t.reconcilerHandler.InactiveFailureBlock.Index,
+			t.reconcilerHandler.InactiveFailure.Account,
+			true
+	}
+
+	if errors.Is(err, syncer.ErrBlockProcessFailed) {
+		index, ok := t.processFailureIndex(ctx)
+		return index, nil, ok
+	}
+
+	return -1, nil, false
+}
+
+// processFailureIndex returns the index of the block after
+// the head. Blocks are processed sequentially and a block that
+// fails to process is not stored, so this is the block that
+// caused a process failure.
+func (t *DataTester) processFailureIndex(ctx context.Context) (int64, bool) {
+	head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return -1, false
+	}
+
+	return head.Index + 1, true
+}
+
+// fetchFailureIndex returns the index of the block that caused
+// a fetch failure. Blocks are fetched up to max_sync_concurrency
+// blocks ahead of the head (but not past the tip), so each of
+// them is fetched again and the first that fails is returned.
+func (t *DataTester) fetchFailureIndex(ctx context.Context) (int64, bool) {
+	head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return -1, false
+	}
+
+	end := head.Index + t.config.MaxSyncConcurrency
+	status, fetchErr := t.fetcher.NetworkStatusRetry(ctx, t.network, nil)
+	if fetchErr == nil && status.CurrentBlockIdentifier.Index < end {
+		end = status.CurrentBlockIdentifier.Index
+	}
+
+	return processor.FindFailedBlock(ctx, t.fetcher, t.network, head.Index+1, end)
+}
+
+// traceViolation re-fetches and re-processes the block at index
+// (that caused err) one step at a time and records the
+// trace in the results, so that a single unexpected block can
+// be diagnosed without rerunning check:data.
+func (t *DataTester) traceViolation(
+	ctx context.Context,
+	err error,
+	index int64,
+	account *types.AccountIdentifier,
+) {
+	if t.config.Data.ViolationTracingDisabled {
+		return
+	}
+
+	clientOptions, optionsErr := processor.NewClientOptions(t.config)
+	if optionsErr != nil {
+		color.Yellow("unable to trace block %d: %s", index, optionsErr.Error())
+		return
+	}
+
+	rosettaClient := processor.NewClient(
+		t.config.OnlineURL,
+		time.Duration(t.config.HTTPTimeout)*time.Second,
+		1,
+		clientOptions,
+	)
+	color.Cyan("Re-processing block %d with tracing...", index)
+	trace := processor.NewBlockTracer(rosettaClient, t.network, t.parser).Trace(
+		ctx,
+		index,
+		account,
+	)
+	trace.Error = err.Error()
+	results.RecordViolationTrace(trace)
+}
+
 // FindMissingOps logs the types.BlockIdentifier of a block
 // that is missing balance-changing operations for a
 // *types.AccountCurrency.
@@ -1013,6 +1558,7 @@ func (t *DataTester) recursiveOpSearch(
 	if err != nil {
 		return nil, fmt.Errorf("%w: unable to initialize database", err)
 	}
+	databaseOpened()
 
 	counterStorage := modules.NewCounterStorage(localStore)
 	blockStorage := modules.NewBlockStorage(localStore, t.config.SerialBlockWorkers)
@@ -1042,6 +1588,10 @@ func (t *DataTester) recursiveOpSearch(
 		counterStorage,
 		balanceStorage,
 		true, // halt on reconciliation error
+		0,    // no escalation
+		0,    // no active reconciliation rate limit
+		0,    // no inactive reconciliation rate limit
+		nil,  // find missing ops should not skip known failures
 	)
 
 	r := reconciler.New(
@@ -1090,7 +1640,7 @@ func (t *DataTester) recursiveOpSearch(
 		counterStorage,
 		logger,
 		cancel,
-		[]modules.BlockWorker{balanceStorage},
+		[]modules.BlockWorker{balanceWorker(balanceStorage, t.balanceRules)},
 		statefulsyncer.WithCacheSize(syncer.DefaultCacheSize),
 		statefulsyncer.WithMaxConcurrency(t.config.MaxSyncConcurrency),
 		statefulsyncer.WithPastBlockLimit(t.config.MaxReorgDepth),
@@ -1117,6 +1667,7 @@ func (t *DataTester) recursiveOpSearch(
 	if storageErr := localStore.Close(ctx); storageErr != nil {
 		return nil, fmt.Errorf("%w: unable to close database", storageErr)
 	}
+	databaseClosed()
 
 	if *t.signalReceived {
 		return nil, errors.New("search for block with missing ops halted")
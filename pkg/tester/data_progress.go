@@ -0,0 +1,308 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/fatih/color"
+)
+
+const (
+	// syncProgressFile is the name of the file in the
+	// check:data data directory that the progress of
+	// a run is persisted to.
+	syncProgressFile = "sync_progress.json"
+
+	// syncCheckpointInterval is how often the last
+	// processed block is checkpointed.
+	syncCheckpointInterval = 10 * time.Second
+)
+
+// SyncProgress is the progress of a check:data run. Blocks,
+// balances, coins, and counters are committed to the check:data
+// database atomically with each processed block (so the head of
+// BlockStorage is always a consistent checkpoint), so this only
+// records which run that state belongs to and the last checkpoint.
+type SyncProgress struct {
+	// RunID is the ID of the invocation that started the run
+	// (resuming a run does not change it).
+	RunID string `json:"run_id"`
+
+	// ConfigFingerprint is the fingerprint of the configuration
+	// the run was started with. A run can only be resumed with
+	// the same configuration.
+	ConfigFingerprint string `json:"config_fingerprint"`
+
+	// LastBlock is the last processed block when the
+	// progress was last checkpointed.
+	LastBlock *types.BlockIdentifier `json:"last_block,omitempty"`
+
+	// Resumes is the number of times the run has been resumed.
+	Resumes int `json:"resumes"`
+}
+
+// LoadSyncProgress loads the *SyncProgress persisted in a
+// check:data data directory. If no progress has been
+// persisted, nil is returned.
+func LoadSyncProgress(dataPath string) (*SyncProgress, error) {
+	progressPath := path.Join(dataPath, syncProgressFile)
+	if _, err := os.Stat(progressPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var progress SyncProgress
+	if err := utils.LoadAndParse(progressPath, &progress); err != nil {
+		return nil, fmt.Errorf("%w: unable to load sync progress", err)
+	}
+
+	return &progress, nil
+}
+
+// persist writes progress to a check:data data directory.
+func (p *SyncProgress) persist(dataPath string) error {
+	if err := utils.SerializeAndWrite(path.Join(dataPath, syncProgressFile), p); err != nil {
+		return fmt.Errorf("%w: unable to persist sync progress", err)
+	}
+
+	return nil
+}
+
+// prepareSyncProgress returns the *SyncProgress of the run being
+// started. If resume is true, the progress persisted by a previous
+// run is continued (and head must be the last processed block).
+// Otherwise, a new run is started.
+func prepareSyncProgress(
+	dataPath string,
+	head *types.BlockIdentifier,
+	resume bool,
+) (*SyncProgress, error) {
+	runID := ""
+	fingerprint := ""
+	if run := results.CurrentRunMetadata(); run != nil {
+		runID = run.RunID
+		fingerprint = run.ConfigFingerprint
+	}
+
+	if !resume {
+		progress := &SyncProgress{
+			RunID:             runID,
+			ConfigFingerprint: fingerprint,
+			LastBlock:         head,
+		}
+		if err := progress.persist(dataPath); err != nil {
+			return nil, err
+		}
+
+		return progress, nil
+	}
+
+	previous, err := LoadSyncProgress(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous == nil {
+		return nil, fmt.Errorf("%w: no sync progress found in %s", results.ErrCannotResume, dataPath)
+	}
+
+	if previous.ConfigFingerprint != fingerprint {
+		return nil, fmt.Errorf(
+			"%w: configuration has changed since run %s started",
+			results.ErrCannotResume,
+			previous.RunID,
+		)
+	}
+
+	if head == nil {
+		return nil, fmt.Errorf(
+			"%w: run %s has not processed any blocks",
+			results.ErrCannotResume,
+			previous.RunID,
+		)
+	}
+
+	previous.Resumes++
+	previous.LastBlock = head
+	if err := previous.persist(dataPath); err != nil {
+		return nil, err
+	}
+
+	return previous, nil
+}
+
+// FindCommonBlock returns the number of blocks at the tip of
+// blockStorage that are no longer on the chain of the node
+// (checking at most maxDepth blocks below head). An error is
+// returned if no stored block within maxDepth of head (or the
+// oldest stored block, if pruned) is on the chain of the node.
+func FindCommonBlock(
+	ctx context.Context,
+	f *fetcher.Fetcher,
+	network *types.NetworkIdentifier,
+	blockStorage *modules.BlockStorage,
+	head *types.BlockIdentifier,
+	maxDepth int64,
+) (int64, error) {
+	status, fetchErr := f.NetworkStatusRetry(ctx, network, nil)
+	if fetchErr != nil {
+		return -1, fmt.Errorf("%w: unable to get network status", fetchErr.Err)
+	}
+
+	// Blocks above the tip of the node can't be compared
+	// (ex: if it is still catching up after a restart).
+	index := head.Index
+	if status.CurrentBlockIdentifier.Index < index {
+		index = status.CurrentBlockIdentifier.Index
+	}
+
+	for ; index >= 0 && head.Index-index <= maxDepth; index-- {
+		i := index
+		stored, err := blockStorage.GetBlock(ctx, &types.PartialBlockIdentifier{Index: &i})
+		if errors.Is(err, storageErrs.ErrBlockNotFound) {
+			break
+		}
+		if err != nil {
+			return -1, fmt.Errorf("%w: unable to get stored block %d", err, i)
+		}
+
+		remote, fetchErr := f.BlockRetry(ctx, network, &types.PartialBlockIdentifier{Index: &i})
+		if fetchErr != nil {
+			return -1, fmt.Errorf("%w: unable to fetch block %d", fetchErr.Err, i)
+		}
+
+		if types.Hash(remote.BlockIdentifier) == types.Hash(stored.BlockIdentifier) {
+			return head.Index - index, nil
+		}
+	}
+
+	return -1, fmt.Errorf(
+		"no stored block within %d blocks of %s is on the chain of the node",
+		maxDepth,
+		types.PrintStruct(head),
+	)
+}
+
+// PrepareResume records the progress of the run being started.
+// If resume is true, the run persisted in the data directory is
+// continued from its last processed block (ignoring start_index)
+// after verifying that the stored tip is still on the chain of the
+// node. Orphaned blocks (within max_reorg_depth) are removed by the
+// syncer once syncing starts.
+func (t *DataTester) PrepareResume(ctx context.Context, resume bool) error {
+	head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	switch {
+	case errors.Is(err, storageErrs.ErrHeadBlockNotFound):
+		head = nil
+	case err != nil:
+		return fmt.Errorf("%w: unable to get head block identifier", err)
+	}
+
+	progress, err := prepareSyncProgress(t.dataPath, head, resume)
+	if err != nil {
+		return err
+	}
+	t.syncProgress = progress
+
+	if !resume {
+		return nil
+	}
+
+	orphaned, err := FindCommonBlock(
+		ctx,
+		t.fetcher,
+		t.network,
+		t.blockStorage,
+		head,
+		int64(t.config.MaxReorgDepth),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %s", results.ErrCannotResume, err.Error())
+	}
+
+	if orphaned > 0 {
+		color.Yellow(
+			"%d stored blocks were orphaned since the last checkpoint and will be removed",
+			orphaned,
+		)
+	}
+
+	if t.config.Data.StartIndex != nil {
+		log.Printf("ignoring start_index %d while resuming\n", *t.config.Data.StartIndex)
+	}
+
+	t.resumed = true
+	color.Cyan(
+		"resuming run %s from block %d (resumed %d times)",
+		progress.RunID,
+		head.Index,
+		progress.Resumes,
+	)
+
+	return nil
+}
+
+// StartCheckpointing periodically persists the
+// last processed block of the run.
+func (t *DataTester) StartCheckpointing(ctx context.Context) error {
+	if t.syncProgress == nil {
+		return nil
+	}
+
+	tc := time.NewTicker(syncCheckpointInterval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.checkpoint(context.Background())
+			return ctx.Err()
+		case <-tc.C:
+			t.checkpoint(ctx)
+		}
+	}
+}
+
+// checkpoint persists the last processed block
+// (if it has changed).
+func (t *DataTester) checkpoint(ctx context.Context) {
+	head, err := t.blockStorage.GetHeadBlockIdentifier(ctx)
+	if err != nil {
+		return
+	}
+
+	if t.syncProgress.LastBlock != nil &&
+		types.Hash(t.syncProgress.LastBlock) == types.Hash(head) {
+		return
+	}
+
+	t.syncProgress.LastBlock = head
+	if err := t.syncProgress.persist(t.dataPath); err != nil {
+		log.Printf("%s: unable to checkpoint sync progress\n", err.Error())
+	}
+}
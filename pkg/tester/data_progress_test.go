@@ -0,0 +1,163 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareSyncProgress(t *testing.T) {
+	defer results.SetRunMetadata(nil)
+
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	head := &types.BlockIdentifier{Index: 10, Hash: "block 10"}
+
+	// Nothing to resume
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 1", ConfigFingerprint: "config"})
+	progress, err := prepareSyncProgress(dir, head, true)
+	assert.True(t, errors.Is(err, results.ErrCannotResume))
+	assert.Nil(t, progress)
+
+	// Start a run
+	progress, err = prepareSyncProgress(dir, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, &SyncProgress{RunID: "run 1", ConfigFingerprint: "config"}, progress)
+
+	// A run that hasn't processed any blocks can't be resumed
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 2", ConfigFingerprint: "config"})
+	_, err = prepareSyncProgress(dir, nil, true)
+	assert.True(t, errors.Is(err, results.ErrCannotResume))
+
+	// Resume the run
+	progress, err = prepareSyncProgress(dir, head, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &SyncProgress{
+		RunID:             "run 1",
+		ConfigFingerprint: "config",
+		LastBlock:         head,
+		Resumes:           1,
+	}, progress)
+
+	loaded, err := LoadSyncProgress(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, progress, loaded)
+
+	// Resume with a different configuration
+	results.SetRunMetadata(&results.RunMetadata{RunID: "run 3", ConfigFingerprint: "other"})
+	_, err = prepareSyncProgress(dir, head, true)
+	assert.True(t, errors.Is(err, results.ErrCannotResume))
+}
+
+func TestFindCommonBlock(t *testing.T) {
+	ctx := context.Background()
+	handler, err := NewReferenceServer(10, 1)
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	f := fetcher.New(srv.URL)
+	_, _, fetchErr := f.InitializeAsserter(ctx, ReferenceNetwork, "")
+	assert.Nil(t, fetchErr)
+
+	chain := newReferenceChain(10, 1)
+	fork := func(block *types.Block) *types.Block {
+		forked := *block
+		forked.BlockIdentifier = &types.BlockIdentifier{
+			Index: block.BlockIdentifier.Index,
+			Hash:  fmt.Sprintf("fork %d", block.BlockIdentifier.Index),
+		}
+
+		return &forked
+	}
+
+	var tests = map[string]struct {
+		stored   []*types.Block
+		maxDepth int64
+
+		orphaned int64
+		err      bool
+	}{
+		"tip matches": {
+			stored:   chain.blocks,
+			maxDepth: 5,
+		},
+		"stored ahead of node": {
+			stored:   append(append([]*types.Block{}, chain.blocks...), fork(&types.Block{BlockIdentifier: &types.BlockIdentifier{Index: 10}})),
+			maxDepth: 5,
+			orphaned: 1,
+		},
+		"orphaned tip": {
+			stored:   append(append([]*types.Block{}, chain.blocks[:8]...), fork(chain.blocks[8]), fork(chain.blocks[9])),
+			maxDepth: 5,
+			orphaned: 2,
+		},
+		"orphaned beyond max depth": {
+			stored:   append(append([]*types.Block{}, chain.blocks[:8]...), fork(chain.blocks[8]), fork(chain.blocks[9])),
+			maxDepth: 1,
+			err:      true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := utils.CreateTempDir()
+			assert.NoError(t, err)
+			defer utils.RemoveTempDir(dir)
+
+			db, err := database.NewBadgerDatabase(ctx, dir)
+			assert.NoError(t, err)
+			defer db.Close(ctx)
+
+			blockStorage := modules.NewBlockStorage(db, 1)
+			for _, block := range test.stored {
+				assert.NoError(t, blockStorage.SeeBlock(ctx, block))
+				assert.NoError(t, blockStorage.AddBlock(ctx, block))
+			}
+
+			orphaned, err := FindCommonBlock(
+				ctx,
+				f,
+				ReferenceNetwork,
+				blockStorage,
+				test.stored[len(test.stored)-1].BlockIdentifier,
+				test.maxDepth,
+			)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.orphaned, orphaned)
+		})
+	}
+}
@@ -16,8 +16,10 @@ package tester
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
@@ -50,25 +52,29 @@ func LogMemoryLoop(
 
 // StartServer stats a server at a port with a particular handler.
 // This is often used to support a status endpoint for a particular test.
+// If the port can't be bound (ex: because it is already in use), an
+// error is returned.
 func StartServer(
 	ctx context.Context,
 	name string,
 	handler http.Handler,
 	port uint,
 ) error {
-	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: handler,
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return fmt.Errorf("%w: unable to start %s server on port %d", err, name, port)
 	}
 
+	server := &http.Server{Handler: handler}
+
 	go func() {
 		log.Printf("%s server running on port %d\n", name, port)
-		_ = server.ListenAndServe()
+		_ = server.Serve(listener)
 	}()
 
 	go func() {
 		// If we don't shutdown server, it will
-		// never stop because server.ListenAndServe doesn't
+		// never stop because server.Serve doesn't
 		// take any context.
 		<-ctx.Done()
 		log.Printf("%s server shutting down", name)
@@ -78,3 +84,39 @@ func StartServer(
 
 	return ctx.Err()
 }
+
+// StartMetricsServer serves the metrics returned by handler
+// at /metrics on addr until ctx is done. If addr is not
+// populated, no server is started.
+func StartMetricsServer(
+	ctx context.Context,
+	addr string,
+	handler http.Handler,
+) error {
+	if len(addr) == 0 {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("%w: unable to start metrics server on %s", err, addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", handler)
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		log.Printf("metrics server shutting down")
+
+		_ = server.Shutdown(context.Background())
+	}()
+
+	log.Printf("metrics server running on %s\n", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%w: metrics server failed", err)
+	}
+
+	return ctx.Err()
+}
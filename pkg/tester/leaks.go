@@ -0,0 +1,244 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// fdDirectory lists the open file descriptors of
+	// the current process (only available on Linux).
+	fdDirectory = "/proc/self/fd"
+
+	// leakCheckInterval is how often goroutines are
+	// inspected while waiting for them to exit.
+	leakCheckInterval = 100 * time.Millisecond
+)
+
+var (
+	// openDatabases is the number of databases opened by
+	// a tester that have not yet been closed. It is
+	// accessed atomically.
+	openDatabases int32
+
+	// goroutineHeader matches the first line of each
+	// goroutine in a stack dump (ex: "goroutine 1 [running]:").
+	goroutineHeader = regexp.MustCompile(`^goroutine (\d+) \[`)
+
+	// expectedGoroutines are functions that run until the
+	// process exits by design and are not reported as leaks.
+	expectedGoroutines = []string{
+		"cmd.handleSignals",
+		"os/signal.loop",
+	}
+)
+
+// databaseOpened records that a tester opened a database.
+func databaseOpened() {
+	atomic.AddInt32(&openDatabases, 1)
+}
+
+// databaseClosed records that a tester closed a database.
+func databaseClosed() {
+	atomic.AddInt32(&openDatabases, -1)
+}
+
+// ResourceSnapshot is the set of goroutines and file
+// descriptors in use at some point in time.
+type ResourceSnapshot struct {
+	goroutines map[string]string
+	files      map[string]string
+}
+
+// TakeResourceSnapshot returns a *ResourceSnapshot of the
+// goroutines and file descriptors currently in use.
+func TakeResourceSnapshot() *ResourceSnapshot {
+	return &ResourceSnapshot{
+		goroutines: goroutineStacks(),
+		files:      openFiles(),
+	}
+}
+
+// LeakReport describes the resources still in use on
+// shutdown that were not in use when the baseline
+// *ResourceSnapshot was taken.
+type LeakReport struct {
+	// Goroutines are the stacks of leaked goroutines.
+	Goroutines []string
+
+	// Files are the leaked file descriptors and the
+	// files (or sockets) they refer to.
+	Files []string
+
+	// Databases is the number of databases opened by
+	// a tester that were never closed.
+	Databases int
+}
+
+// Empty returns true if no leaks were found.
+func (r *LeakReport) Empty() bool {
+	return len(r.Goroutines) == 0 && len(r.Files) == 0 && r.Databases == 0
+}
+
+// String returns a human-readable description
+// of all leaked resources.
+func (r *LeakReport) String() string {
+	var b strings.Builder
+	if r.Databases > 0 {
+		fmt.Fprintf(&b, "%d database(s) were not closed\n", r.Databases)
+	}
+
+	if len(r.Files) > 0 {
+		fmt.Fprintf(&b, "%d file descriptor(s) were not closed:\n", len(r.Files))
+		for _, file := range r.Files {
+			fmt.Fprintf(&b, "  %s\n", file)
+		}
+	}
+
+	if len(r.Goroutines) > 0 {
+		fmt.Fprintf(&b, "%d goroutine(s) did not exit:\n", len(r.Goroutines))
+		for _, stack := range r.Goroutines {
+			fmt.Fprintf(&b, "\n%s\n", stack)
+		}
+	}
+
+	return b.String()
+}
+
+// CheckLeaks waits up to timeout for all goroutines started
+// after baseline was taken to exit and then returns a
+// *LeakReport of any goroutines, file descriptors, and
+// databases still in use.
+func CheckLeaks(baseline *ResourceSnapshot, timeout time.Duration) *LeakReport {
+	deadline := time.Now().Add(timeout)
+	leaked := newGoroutines(baseline)
+	for len(leaked) > 0 && time.Now().Before(deadline) {
+		time.Sleep(leakCheckInterval)
+		leaked = newGoroutines(baseline)
+	}
+
+	files := []string{}
+	for fd, target := range openFiles() {
+		if baseline.files[fd] == target {
+			continue
+		}
+
+		files = append(files, fmt.Sprintf("%s -> %s", fd, target))
+	}
+	sort.Strings(files)
+
+	return &LeakReport{
+		Goroutines: leaked,
+		Files:      files,
+		Databases:  int(atomic.LoadInt32(&openDatabases)),
+	}
+}
+
+// newGoroutines returns the stacks of all running goroutines
+// that were not running when baseline was taken.
+func newGoroutines(baseline *ResourceSnapshot) []string {
+	leaked := []string{}
+	for id, stack := range goroutineStacks() {
+		if _, ok := baseline.goroutines[id]; ok {
+			continue
+		}
+
+		if expectedGoroutine(stack) {
+			continue
+		}
+
+		leaked = append(leaked, stack)
+	}
+	sort.Strings(leaked)
+
+	return leaked
+}
+
+func expectedGoroutine(stack string) bool {
+	for _, function := range expectedGoroutines {
+		if strings.Contains(stack, function) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goroutineStacks returns the stack of each running goroutine
+// (other than the caller) keyed by goroutine ID.
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := map[string]string{}
+	for i, stack := range bytes.Split(buf, []byte("\n\n")) {
+		// The first stack is always the calling goroutine.
+		if i == 0 {
+			continue
+		}
+
+		match := goroutineHeader.FindSubmatch(stack)
+		if match == nil {
+			continue
+		}
+
+		stacks[string(match[1])] = strings.TrimSpace(string(stack))
+	}
+
+	return stacks
+}
+
+// openFiles returns the target of each open file descriptor
+// keyed by descriptor. If file descriptors can't be listed
+// (ex: not running on Linux), an empty map is returned.
+func openFiles() map[string]string {
+	files := map[string]string{}
+	entries, err := ioutil.ReadDir(fdDirectory)
+	if err != nil {
+		return files
+	}
+
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdDirectory, entry.Name()))
+		if err != nil {
+			// The descriptor used to read fdDirectory is
+			// closed by the time we read its link.
+			continue
+		}
+
+		files[entry.Name()] = target
+	}
+
+	return files
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func leakyWorker(done chan struct{}) {
+	<-done
+}
+
+func TestCheckLeaks(t *testing.T) {
+	baseline := TakeResourceSnapshot()
+
+	done := make(chan struct{})
+	go leakyWorker(done)
+
+	f, err := ioutil.TempFile("", "leak")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	databaseOpened()
+
+	report := CheckLeaks(baseline, 200*time.Millisecond)
+	assert.False(t, report.Empty())
+	assert.Len(t, report.Goroutines, 1)
+	assert.Contains(t, report.Goroutines[0], "tester.leakyWorker")
+	assert.Equal(t, 1, report.Databases)
+	if runtime.GOOS == "linux" {
+		assert.Len(t, report.Files, 1)
+		assert.Contains(t, report.Files[0], f.Name())
+	}
+	assert.Contains(t, report.String(), "1 goroutine(s) did not exit")
+
+	close(done)
+	assert.NoError(t, f.Close())
+	databaseClosed()
+
+	report = CheckLeaks(baseline, time.Second)
+	assert.True(t, report.Empty())
+}
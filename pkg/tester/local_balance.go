@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/storage/database"
+	storageErrs "github.com/coinbase/rosetta-sdk-go/storage/errors"
+	"github.com/coinbase/rosetta-sdk-go/storage/modules"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// DataPathExists returns a boolean indicating if a previous
+// check:data run on network saved its database in the
+// configured data directory.
+func DataPathExists(
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+) bool {
+	if len(config.DataDirectory) == 0 {
+		return false
+	}
+
+	info, err := os.Stat(path.Join(config.DataDirectory, dataCmdName, types.Hash(network)))
+	return err == nil && info.IsDir()
+}
+
+// LocalBalances returns the balance of account in each of currencies
+// at index computed by a previous check:data run on network (in the
+// same order as currencies). The balance of a currency the account
+// was never seen with is nil.
+func LocalBalances(
+	ctx context.Context,
+	config *configuration.Configuration,
+	network *types.NetworkIdentifier,
+	account *types.AccountIdentifier,
+	currencies []*types.Currency,
+	index int64,
+) ([]*types.Amount, error) {
+	dataPath := path.Join(config.DataDirectory, dataCmdName, types.Hash(network))
+	lock, err := AcquireDataDirectoryLock(dataPath, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to lock data directory", err)
+	}
+	defer lock.Release()
+
+	opts := []database.BadgerOption{}
+	if config.CompressionDisabled {
+		opts = append(opts, database.WithoutCompression())
+	}
+
+	localStore, err := database.NewBadgerDatabase(ctx, dataPath, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to open database", err)
+	}
+	defer localStore.Close(ctx)
+
+	balanceStorage := modules.NewBalanceStorage(localStore)
+	amounts := make([]*types.Amount, len(currencies))
+	for i, currency := range currencies {
+		amount, err := balanceStorage.GetBalance(ctx, account, currency, index)
+		if errors.Is(err, storageErrs.ErrAccountMissing) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: unable to get %s balance at block %d",
+				err,
+				currency.Symbol,
+				index,
+			)
+		}
+
+		amounts[i] = amount
+	}
+
+	return amounts, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBalances(t *testing.T) {
+	ctx := context.Background()
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	config := configuration.DefaultConfiguration()
+	network := config.Network
+	account := &types.AccountIdentifier{Address: "addr1"}
+	currencies := []*types.Currency{{Symbol: "BTC", Decimals: 8}}
+
+	// No data directory
+	config.DataDirectory = ""
+	assert.False(t, DataPathExists(config, network))
+
+	// No check:data database in the data directory
+	config.DataDirectory = dir
+	assert.False(t, DataPathExists(config, network))
+
+	dataPath, err := utils.CreateCommandPath(dir, dataCmdName, network)
+	assert.NoError(t, err)
+	assert.True(t, DataPathExists(config, network))
+
+	// Accounts never seen by check:data have no balance
+	amounts, err := LocalBalances(ctx, config, network, account, currencies, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Amount{nil}, amounts)
+	assert.Equal(t, 0, lockOwner(path.Join(dataPath, lockFile)))
+
+	// Databases in use by a check are not opened
+	lock, err := AcquireDataDirectoryLock(dataPath, false)
+	assert.NoError(t, err)
+	defer lock.Release()
+
+	_, err = LocalBalances(ctx, config, network, account, currencies, 10)
+	assert.True(t, errors.Is(err, results.ErrDataDirectoryLocked))
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// lockFile is the name of the file in a data directory
+	// that is locked by the process using it (and holds
+	// its PID).
+	lockFile = "rosetta-cli.lock"
+)
+
+// errLockHeld is returned by tryLock when the lock file
+// is locked by another process.
+var errLockHeld = errors.New("lock file is locked")
+
+// DataDirectoryLock is an advisory lock on a data directory
+// that prevents multiple rosetta-cli processes from using
+// it at the same time. The lock is held by the operating
+// system (on an open lock file), so it is released when
+// the process holding it exits.
+type DataDirectoryLock struct {
+	// file is nil if the lock was not acquired
+	// (see AcquireDataDirectoryLock).
+	file *os.File
+}
+
+// AcquireDataDirectoryLock locks dataPath for this process. If
+// it is locked by another process, an error naming that process
+// is returned unless forceTakeover is set (ex: when the lock is
+// held by a process that can't be stopped), in which case
+// dataPath is used without the lock.
+func AcquireDataDirectoryLock(
+	dataPath string,
+	forceTakeover bool,
+) (*DataDirectoryLock, error) {
+	lockPath := path.Join(dataPath, lockFile)
+	file, err := tryLock(lockPath)
+	switch {
+	case err == nil:
+	case errors.Is(err, errLockHeld) && forceTakeover:
+		color.Yellow("Using %s without its lock (held by process %d)", dataPath, lockOwner(lockPath))
+		return &DataDirectoryLock{}, nil
+	case errors.Is(err, errLockHeld):
+		return nil, fmt.Errorf(
+			"%w: %s is in use by process %d (stop it or run with --force-takeover)",
+			results.ErrDataDirectoryLocked,
+			dataPath,
+			lockOwner(lockPath),
+		)
+	default:
+		return nil, fmt.Errorf("%w: unable to lock %s", err, lockPath)
+	}
+
+	pid := []byte(strconv.Itoa(os.Getpid()))
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w: unable to truncate lock file %s", err, lockPath)
+	}
+
+	if _, err := file.WriteAt(pid, 0); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w: unable to write lock file %s", err, lockPath)
+	}
+
+	return &DataDirectoryLock{file: file}, nil
+}
+
+// lockOwner returns the PID in the lock file at lockPath
+// (0 if it can't be read or is malformed).
+func lockOwner(lockPath string) int {
+	contents, err := ioutil.ReadFile(lockPath) // #nosec G304
+	if err != nil {
+		return 0
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return 0
+	}
+
+	return pid
+}
+
+// Release releases the lock (if it was acquired).
+func (l *DataDirectoryLock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+
+	// The lock file is not removed because another
+	// process may have already opened it to lock it.
+	if err := l.file.Truncate(0); err != nil {
+		log.Printf("%s: unable to truncate lock file %s\n", err.Error(), l.file.Name())
+	}
+
+	if err := l.file.Close(); err != nil {
+		log.Printf("%s: unable to close lock file %s\n", err.Error(), l.file.Name())
+	}
+
+	l.file = nil
+}
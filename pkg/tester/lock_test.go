@@ -0,0 +1,72 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/coinbase/rosetta-cli/pkg/results"
+
+	"github.com/coinbase/rosetta-sdk-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataDirectoryLock(t *testing.T) {
+	dir, err := utils.CreateTempDir()
+	assert.NoError(t, err)
+	defer utils.RemoveTempDir(dir)
+
+	lockPath := path.Join(dir, lockFile)
+
+	// Lock an unused directory
+	lock, err := AcquireDataDirectoryLock(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), lockOwner(lockPath))
+
+	// Lock a directory in use by a running process
+	_, err = AcquireDataDirectoryLock(dir, false)
+	assert.True(t, errors.Is(err, results.ErrDataDirectoryLocked))
+	assert.Contains(t, err.Error(), strconv.Itoa(os.Getpid()))
+
+	// Use a directory in use by a running process
+	// without its lock
+	forced, err := AcquireDataDirectoryLock(dir, true)
+	assert.NoError(t, err)
+	forced.Release()
+	assert.Equal(t, os.Getpid(), lockOwner(lockPath))
+
+	// Release the lock
+	lock.Release()
+	assert.Equal(t, 0, lockOwner(lockPath))
+	lock.Release()
+
+	// Lock a directory with a lock file left by a process
+	// that exited (it is not locked)
+	assert.NoError(t, ioutil.WriteFile(lockPath, []byte("99999999"), 0600))
+	lock, err = AcquireDataDirectoryLock(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), lockOwner(lockPath))
+	lock.Release()
+
+	// Only the lock file remains in the directory
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
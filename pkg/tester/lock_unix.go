@@ -0,0 +1,46 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package tester
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLock opens (or creates) the lock file at lockPath and
+// takes an exclusive flock on it without blocking. flock is
+// released by the kernel when the process exits, so locks
+// are never left behind.
+func tryLock(lockPath string) (*os.File, error) {
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLockHeld
+		}
+
+		return nil, err
+	}
+
+	return file, nil
+}
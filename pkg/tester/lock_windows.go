@@ -0,0 +1,59 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package tester
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errorSharingViolation is ERROR_SHARING_VIOLATION, which
+// is returned when a file is opened with access another
+// handle does not share.
+const errorSharingViolation syscall.Errno = 32
+
+// tryLock opens (or creates) the lock file at lockPath for
+// writing without sharing write access, so no other process
+// can open it for writing until it is closed (which windows
+// does when the process exits). Other processes can still
+// read the PID in it.
+func tryLock(lockPath string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(
+		name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		syscall.FILE_SHARE_READ,
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0,
+	)
+	if errors.Is(err, errorSharingViolation) {
+		return nil, errLockHeld
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(handle), lockPath), nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+)
+
+// poller waits between polls according to a
+// *configuration.PollingConfiguration.
+type poller struct {
+	config *configuration.PollingConfiguration
+	next   time.Duration
+
+	progress    int64
+	hasProgress bool
+}
+
+// newPoller returns a new *poller. If config is nil,
+// polls are performed every EndAtTipCheckInterval.
+func newPoller(config *configuration.PollingConfiguration) *poller {
+	if config == nil {
+		config = &configuration.PollingConfiguration{
+			IntervalMS: uint64(EndAtTipCheckInterval / time.Millisecond),
+		}
+	}
+
+	p := &poller{config: config}
+	p.Reset()
+
+	return p
+}
+
+// Reset sets the next wait back to the initial interval.
+// This should be called whenever progress is made so that
+// backoff only applies while nothing is changing.
+func (p *poller) Reset() {
+	p.next = time.Duration(p.config.IntervalMS) * time.Millisecond
+}
+
+// Observe records a measure of progress (ex: the index of
+// the last synced block) and resets the backoff if it has
+// increased since it was last observed.
+func (p *poller) Observe(progress int64) {
+	if p.hasProgress && progress > p.progress {
+		p.Reset()
+	}
+
+	p.progress = progress
+	p.hasProgress = true
+}
+
+// interval returns the duration of the next wait (with jitter
+// applied) and advances the backoff.
+func (p *poller) interval() time.Duration {
+	wait := p.next
+	if p.config.Jitter > 0 {
+		// Randomize wait in [wait * (1 - jitter), wait * (1 + jitter)).
+		delta := p.config.Jitter * float64(wait)
+		wait = time.Duration(float64(wait) - delta + rand.Float64()*2*delta) // #nosec G404
+	}
+
+	if p.config.Multiplier > 1 {
+		p.next = time.Duration(float64(p.next) * p.config.Multiplier)
+		maxInterval := time.Duration(p.config.MaxIntervalMS) * time.Millisecond
+		if maxInterval > 0 && p.next > maxInterval {
+			p.next = maxInterval
+		}
+	}
+
+	return wait
+}
+
+// After returns a channel that receives the current time
+// when the next poll should be performed.
+func (p *poller) After() <-chan time.Time {
+	return time.After(p.interval())
+}
+
+// Wait blocks until the next poll should be performed
+// or the context is canceled.
+func (p *poller) Wait(ctx context.Context) error {
+	timer := time.NewTimer(p.interval())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase/rosetta-cli/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoller(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		p := newPoller(nil)
+		assert.Equal(t, 10*time.Second, p.interval())
+		assert.Equal(t, 10*time.Second, p.interval())
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		p := newPoller(&configuration.PollingConfiguration{
+			IntervalMS:    100,
+			Multiplier:    2,
+			MaxIntervalMS: 300,
+		})
+		assert.Equal(t, 100*time.Millisecond, p.interval())
+		assert.Equal(t, 200*time.Millisecond, p.interval())
+		assert.Equal(t, 300*time.Millisecond, p.interval())
+		assert.Equal(t, 300*time.Millisecond, p.interval())
+
+		p.Reset()
+		assert.Equal(t, 100*time.Millisecond, p.interval())
+	})
+
+	t.Run("reset on progress", func(t *testing.T) {
+		p := newPoller(&configuration.PollingConfiguration{
+			IntervalMS:    100,
+			Multiplier:    2,
+			MaxIntervalMS: 1000,
+		})
+		p.Observe(5)
+		assert.Equal(t, 100*time.Millisecond, p.interval())
+		assert.Equal(t, 200*time.Millisecond, p.interval())
+
+		p.Observe(5)
+		assert.Equal(t, 400*time.Millisecond, p.interval())
+
+		p.Observe(6)
+		assert.Equal(t, 100*time.Millisecond, p.interval())
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		p := newPoller(&configuration.PollingConfiguration{
+			IntervalMS: 1000,
+			Jitter:     0.5,
+		})
+		for i := 0; i < 100; i++ {
+			wait := p.interval()
+			assert.True(t, wait >= 500*time.Millisecond)
+			assert.True(t, wait < 1500*time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,371 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net/http"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/server"
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+const (
+	// referenceAccounts is the number of accounts
+	// on the synthetic chain.
+	referenceAccounts = 8
+
+	// referenceMaxTransfers is the maximum number of
+	// transfers in each block.
+	referenceMaxTransfers = 3
+
+	// referenceGenesisTimestamp is the timestamp (in ms)
+	// of the genesis block. Each block is 1 second after
+	// its parent.
+	referenceGenesisTimestamp = 1600000000000
+
+	// referenceReward is the amount credited to the miner
+	// of each block (in atomic units).
+	referenceReward = 5000000000
+
+	// referenceSuccessStatus is the status of all operations.
+	referenceSuccessStatus = "SUCCESS"
+
+	// referenceRewardType and referenceTransferType are the
+	// types of operations on the synthetic chain.
+	referenceRewardType   = "REWARD"
+	referenceTransferType = "TRANSFER"
+)
+
+var (
+	// ReferenceNetwork is the network served by
+	// the reference server.
+	ReferenceNetwork = &types.NetworkIdentifier{
+		Blockchain: "Selftest",
+		Network:    "Synthetic",
+	}
+
+	// referenceCurrency is the only currency on
+	// the synthetic chain.
+	referenceCurrency = &types.Currency{
+		Symbol:   "SELF",
+		Decimals: 8,
+	}
+
+	// errReferenceNotFound is returned by the reference server
+	// when a block, transaction, or endpoint does not exist.
+	errReferenceNotFound = &types.Error{
+		Code:    1,
+		Message: "not found",
+	}
+)
+
+var (
+	_ server.NetworkAPIServicer = (*referenceChain)(nil)
+	_ server.BlockAPIServicer   = (*referenceChain)(nil)
+	_ server.AccountAPIServicer = (*referenceChain)(nil)
+)
+
+// referenceChain is a deterministic synthetic chain in which
+// each block rewards a random account and contains a random
+// number of transfers between accounts.
+type referenceChain struct {
+	blocks []*types.Block
+
+	// balances are the balances of each account
+	// after each block.
+	balances []map[string]*big.Int
+}
+
+// referenceAccount returns the *types.AccountIdentifier
+// of the ith account on the synthetic chain.
+func referenceAccount(i int) *types.AccountIdentifier {
+	return &types.AccountIdentifier{Address: fmt.Sprintf("account_%d", i)}
+}
+
+// referenceOperation returns an operation of opType
+// crediting value to account.
+func referenceOperation(
+	index int64,
+	opType string,
+	account *types.AccountIdentifier,
+	value *big.Int,
+) *types.Operation {
+	return &types.Operation{
+		OperationIdentifier: &types.OperationIdentifier{Index: index},
+		Type:                opType,
+		Status:              types.String(referenceSuccessStatus),
+		Account:             account,
+		Amount: &types.Amount{
+			Value:    value.String(),
+			Currency: referenceCurrency,
+		},
+	}
+}
+
+// newReferenceChain generates a chain of blocks
+// (including genesis) from seed.
+func newReferenceChain(blocks int64, seed int64) *referenceChain {
+	r := rand.New(rand.NewSource(seed)) // #nosec G404
+	chain := &referenceChain{}
+
+	balances := map[string]*big.Int{}
+	for i := 0; i < referenceAccounts; i++ {
+		balances[referenceAccount(i).Address] = big.NewInt(0)
+	}
+
+	for index := int64(0); index < blocks; index++ {
+		block := &types.Block{
+			BlockIdentifier: &types.BlockIdentifier{
+				Index: index,
+				Hash:  fmt.Sprintf("block_%d_%d", seed, index),
+			},
+			Timestamp:    referenceGenesisTimestamp + index*1000,
+			Transactions: []*types.Transaction{},
+		}
+
+		transfers := 0
+		block.ParentBlockIdentifier = block.BlockIdentifier
+		if index > 0 {
+			block.ParentBlockIdentifier = chain.blocks[index-1].BlockIdentifier
+
+			miner := referenceAccount(r.Intn(referenceAccounts))
+			reward := big.NewInt(referenceReward)
+			block.Transactions = append(block.Transactions, &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{
+					Hash: fmt.Sprintf("tx_%d_%d_reward", seed, index),
+				},
+				Operations: []*types.Operation{
+					referenceOperation(0, referenceRewardType, miner, reward),
+				},
+			})
+			balances[miner.Address] = new(big.Int).Add(balances[miner.Address], reward)
+			transfers = r.Intn(referenceMaxTransfers + 1)
+		}
+
+		for t := 0; t < transfers; t++ {
+			senderIndex := r.Intn(referenceAccounts)
+			sender := referenceAccount(senderIndex)
+			if balances[sender.Address].Sign() == 0 {
+				continue
+			}
+
+			recipient := referenceAccount(
+				(senderIndex + 1 + r.Intn(referenceAccounts-1)) % referenceAccounts,
+			)
+			amount := new(big.Int).Add(
+				new(big.Int).Rand(r, balances[sender.Address]),
+				big.NewInt(1),
+			)
+
+			debit := referenceOperation(0, referenceTransferType, sender, new(big.Int).Neg(amount))
+			credit := referenceOperation(1, referenceTransferType, recipient, amount)
+			credit.RelatedOperations = []*types.OperationIdentifier{debit.OperationIdentifier}
+			block.Transactions = append(block.Transactions, &types.Transaction{
+				TransactionIdentifier: &types.TransactionIdentifier{
+					Hash: fmt.Sprintf("tx_%d_%d_%d", seed, index, t),
+				},
+				Operations: []*types.Operation{debit, credit},
+			})
+
+			balances[sender.Address] = new(big.Int).Sub(balances[sender.Address], amount)
+			balances[recipient.Address] = new(big.Int).Add(balances[recipient.Address], amount)
+		}
+
+		snapshot := make(map[string]*big.Int, len(balances))
+		for address, balance := range balances {
+			snapshot[address] = balance
+		}
+
+		chain.blocks = append(chain.blocks, block)
+		chain.balances = append(chain.balances, snapshot)
+	}
+
+	return chain
+}
+
+// lookup returns the index of the block identified by
+// identifier (the tip if identifier is nil).
+func (c *referenceChain) lookup(identifier *types.PartialBlockIdentifier) (int64, *types.Error) {
+	tip := int64(len(c.blocks) - 1)
+	if identifier == nil || (identifier.Index == nil && identifier.Hash == nil) {
+		return tip, nil
+	}
+
+	if identifier.Index != nil {
+		if *identifier.Index < 0 || *identifier.Index > tip {
+			return -1, errReferenceNotFound
+		}
+
+		if identifier.Hash != nil && *identifier.Hash != c.blocks[*identifier.Index].BlockIdentifier.Hash {
+			return -1, errReferenceNotFound
+		}
+
+		return *identifier.Index, nil
+	}
+
+	for _, block := range c.blocks {
+		if block.BlockIdentifier.Hash == *identifier.Hash {
+			return block.BlockIdentifier.Index, nil
+		}
+	}
+
+	return -1, errReferenceNotFound
+}
+
+// NetworkList implements the /network/list endpoint.
+func (c *referenceChain) NetworkList(
+	context.Context,
+	*types.MetadataRequest,
+) (*types.NetworkListResponse, *types.Error) {
+	return &types.NetworkListResponse{
+		NetworkIdentifiers: []*types.NetworkIdentifier{ReferenceNetwork},
+	}, nil
+}
+
+// NetworkOptions implements the /network/options endpoint.
+func (c *referenceChain) NetworkOptions(
+	context.Context,
+	*types.NetworkRequest,
+) (*types.NetworkOptionsResponse, *types.Error) {
+	return &types.NetworkOptionsResponse{
+		Version: &types.Version{
+			RosettaVersion: types.RosettaAPIVersion,
+			NodeVersion:    "selftest",
+		},
+		Allow: &types.Allow{
+			OperationStatuses: []*types.OperationStatus{
+				{Status: referenceSuccessStatus, Successful: true},
+			},
+			OperationTypes:          []string{referenceRewardType, referenceTransferType},
+			Errors:                  []*types.Error{errReferenceNotFound},
+			HistoricalBalanceLookup: true,
+		},
+	}, nil
+}
+
+// NetworkStatus implements the /network/status endpoint.
+func (c *referenceChain) NetworkStatus(
+	context.Context,
+	*types.NetworkRequest,
+) (*types.NetworkStatusResponse, *types.Error) {
+	tip := c.blocks[len(c.blocks)-1]
+	return &types.NetworkStatusResponse{
+		CurrentBlockIdentifier: tip.BlockIdentifier,
+		CurrentBlockTimestamp:  tip.Timestamp,
+		GenesisBlockIdentifier: c.blocks[0].BlockIdentifier,
+		SyncStatus: &types.SyncStatus{
+			CurrentIndex: types.Int64(tip.BlockIdentifier.Index),
+			Synced:       types.Bool(true),
+		},
+		Peers: []*types.Peer{},
+	}, nil
+}
+
+// Block implements the /block endpoint.
+func (c *referenceChain) Block(
+	ctx context.Context,
+	request *types.BlockRequest,
+) (*types.BlockResponse, *types.Error) {
+	index, err := c.lookup(request.BlockIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.BlockResponse{Block: c.blocks[index]}, nil
+}
+
+// BlockTransaction implements the /block/transaction endpoint.
+func (c *referenceChain) BlockTransaction(
+	ctx context.Context,
+	request *types.BlockTransactionRequest,
+) (*types.BlockTransactionResponse, *types.Error) {
+	index, err := c.lookup(types.ConstructPartialBlockIdentifier(request.BlockIdentifier))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range c.blocks[index].Transactions {
+		if tx.TransactionIdentifier.Hash == request.TransactionIdentifier.Hash {
+			return &types.BlockTransactionResponse{Transaction: tx}, nil
+		}
+	}
+
+	return nil, errReferenceNotFound
+}
+
+// AccountBalance implements the /account/balance endpoint.
+func (c *referenceChain) AccountBalance(
+	ctx context.Context,
+	request *types.AccountBalanceRequest,
+) (*types.AccountBalanceResponse, *types.Error) {
+	index, err := c.lookup(request.BlockIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, ok := c.balances[index][request.AccountIdentifier.Address]
+	if !ok || request.AccountIdentifier.SubAccount != nil {
+		balance = big.NewInt(0)
+	}
+
+	return &types.AccountBalanceResponse{
+		BlockIdentifier: c.blocks[index].BlockIdentifier,
+		Balances: []*types.Amount{
+			{Value: balance.String(), Currency: referenceCurrency},
+		},
+	}, nil
+}
+
+// AccountCoins implements the /account/coins endpoint
+// (the synthetic chain is account-based).
+func (c *referenceChain) AccountCoins(
+	context.Context,
+	*types.AccountCoinsRequest,
+) (*types.AccountCoinsResponse, *types.Error) {
+	return nil, errReferenceNotFound
+}
+
+// NewReferenceServer returns an http.Handler serving the Data API
+// of a deterministic synthetic chain of blocks (including genesis)
+// generated from seed on ReferenceNetwork.
+func NewReferenceServer(blocks int64, seed int64) (http.Handler, error) {
+	if blocks < 1 {
+		return nil, fmt.Errorf("blocks %d must be >= 1", blocks)
+	}
+
+	asserter, err := asserter.NewServer(
+		[]string{referenceRewardType, referenceTransferType},
+		true,
+		[]*types.NetworkIdentifier{ReferenceNetwork},
+		nil,
+		false,
+		"",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to initialize server asserter", err)
+	}
+
+	chain := newReferenceChain(blocks, seed)
+	return server.NewRouter(
+		server.NewNetworkAPIController(chain, asserter),
+		server.NewBlockAPIController(chain, asserter),
+		server.NewAccountAPIController(chain, asserter),
+	), nil
+}
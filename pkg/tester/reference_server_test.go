@@ -0,0 +1,83 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tester
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coinbase/rosetta-sdk-go/fetcher"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceChain(t *testing.T) {
+	chain := newReferenceChain(50, 1)
+	assert.Len(t, chain.blocks, 50)
+	assert.Equal(t, newReferenceChain(50, 1), chain)
+
+	for i, block := range chain.blocks {
+		// Balances after each block must be derivable
+		// from the operations in the chain.
+		total := big.NewInt(0)
+		for _, balance := range chain.balances[i] {
+			assert.True(t, balance.Sign() >= 0)
+			total.Add(total, balance)
+		}
+		assert.Equal(t, big.NewInt(referenceReward*int64(i)), total)
+
+		if i > 0 {
+			assert.Equal(t, chain.blocks[i-1].BlockIdentifier, block.ParentBlockIdentifier)
+		}
+	}
+}
+
+func TestReferenceServer(t *testing.T) {
+	ctx := context.Background()
+	handler, err := NewReferenceServer(10, 1)
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	f := fetcher.New(srv.URL)
+	_, _, fetchErr := f.InitializeAsserter(ctx, ReferenceNetwork, "")
+	assert.Nil(t, fetchErr)
+
+	status, fetchErr := f.NetworkStatusRetry(ctx, ReferenceNetwork, nil)
+	assert.Nil(t, fetchErr)
+	assert.Equal(t, int64(9), status.CurrentBlockIdentifier.Index)
+
+	block, fetchErr := f.BlockRetry(ctx, ReferenceNetwork, &types.PartialBlockIdentifier{
+		Index: types.Int64(5),
+	})
+	assert.Nil(t, fetchErr)
+	assert.Equal(t, int64(5), block.BlockIdentifier.Index)
+
+	account := block.Transactions[0].Operations[0].Account
+	blockIdentifier, amounts, _, fetchErr := f.AccountBalanceRetry(
+		ctx,
+		ReferenceNetwork,
+		account,
+		types.ConstructPartialBlockIdentifier(block.BlockIdentifier),
+		nil,
+	)
+	assert.Nil(t, fetchErr)
+	assert.Equal(t, block.BlockIdentifier, blockIdentifier)
+	assert.Len(t, amounts, 1)
+	assert.NotEqual(t, "0", amounts[0].Value)
+}